@@ -0,0 +1,248 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/logging"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+// Setup states reported by GET /admin/setup.
+const (
+	setupRunning = "running"
+	setupDone    = "done"
+	setupFailed  = "failed"
+)
+
+// setupRun tracks the dataset setup started by POST /admin/setup. One runs
+// at a time.
+type setupRun struct {
+	mu     sync.Mutex
+	latest *setupStatus // nil until the first setup
+	wg     sync.WaitGroup
+	// writing is held while a setup or an update from WatchUpdates writes
+	// the data directory, so the two never share a working copy.
+	writing sync.Mutex
+
+	downloadTimeout time.Duration
+}
+
+// setupStatus describes a setup's progress.
+type setupStatus struct {
+	Year       string              `json:"year"`
+	State      string              `json:"state"`
+	Progress   ofcom.SetupProgress `json:"progress"`
+	StartedAt  time.Time           `json:"started_at"`
+	FinishedAt *time.Time          `json:"finished_at,omitempty"`
+	Error      string              `json:"error,omitempty"`
+}
+
+// WithDownloadTimeout bounds each dataset download started by POST
+// /admin/setup or WatchUpdates; 0 means ofcom.DefaultDownloadTimeout.
+func WithDownloadTimeout(d time.Duration) Option {
+	return func(s *Server) { s.setup.downloadTimeout = d }
+}
+
+// start records a new setup for year, or returns false if one is running.
+func (sr *setupRun) start(year string) (setupStatus, bool) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if sr.latest != nil && sr.latest.State == setupRunning {
+		return *sr.latest, false
+	}
+	sr.latest = &setupStatus{Year: year, State: setupRunning, StartedAt: time.Now().UTC()}
+	sr.wg.Add(1)
+	return *sr.latest, true
+}
+
+func (sr *setupRun) progress(p ofcom.SetupProgress) {
+	sr.mu.Lock()
+	sr.latest.Progress = p
+	sr.mu.Unlock()
+}
+
+func (sr *setupRun) finish(err error) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	now := time.Now().UTC()
+	sr.latest.State, sr.latest.FinishedAt = setupDone, &now
+	if err != nil {
+		sr.latest.State, sr.latest.Error = setupFailed, err.Error()
+	}
+}
+
+// status returns the latest setup's status, if any.
+func (sr *setupRun) status() (setupStatus, bool) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if sr.latest == nil {
+		return setupStatus{}, false
+	}
+	return *sr.latest, true
+}
+
+// POST /admin/setup?year=2023 — download and build a dataset year in the
+// background, as 'mobile-checker setup --year' does; ?force=true rebuilds
+// an installed year. Answers 202; poll GET /admin/setup for its progress.
+// Checks carry on against the current database meanwhile and switch to the
+// new one when it is in place. Requires the admin token.
+func (s *Server) handleAdminSetup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "GET or POST required")
+		return
+	}
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+	if r.Method == http.MethodGet {
+		st, ok := s.setup.status()
+		if !ok {
+			writeError(w, http.StatusNotFound, "no setup has been started")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"status": "ok", "schema_version": checker.SchemaVersion, "setup": st})
+		return
+	}
+
+	year := r.URL.Query().Get("year")
+	if year != ofcom.LatestYear && !ofcom.ValidYear(year) {
+		writeError(w, http.StatusBadRequest, `year must be a dataset year, e.g. ?year=2023, or "latest"`)
+		return
+	}
+	opts := ofcom.SetupOptions{DownloadTimeout: s.setup.downloadTimeout}
+	if v := r.URL.Query().Get("force"); v != "" {
+		force, err := strconv.ParseBool(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "force must be true or false")
+			return
+		}
+		opts.Force = force
+	}
+	st, ok := s.setup.start(year)
+	if !ok {
+		writeError(w, http.StatusConflict, fmt.Sprintf("setup of %s is already running", st.Year))
+		return
+	}
+	logger := logging.FromContext(r.Context(), s.logger)
+	go s.runSetup(logger, year, opts)
+
+	w.Header().Set("Location", "/admin/setup")
+	writeJSON(w, http.StatusAccepted, map[string]any{"status": "ok", "schema_version": checker.SchemaVersion, "setup": st})
+}
+
+// runSetup runs a setup started by handleAdminSetup.
+func (s *Server) runSetup(logger *slog.Logger, year string, opts ofcom.SetupOptions) {
+	defer s.setup.wg.Done()
+	logger.Info("dataset setup started", "year", year, "force", opts.Force)
+	opts.Progress = s.setup.progress
+	s.setup.writing.Lock()
+	defer s.setup.writing.Unlock()
+	err := s.checker.UsingLogger(logger).Setup(year, opts)
+	if err == nil && s.indexed {
+		err = s.checker.LoadIndex()
+	}
+	s.setup.finish(err)
+	if err != nil {
+		logger.Error("dataset setup failed", "year", year, "error", err)
+		return
+	}
+	logger.Info("dataset setup finished", "year", year)
+}
+
+// WatchUpdates installs new Ofcom datasets every interval until ctx is
+// cancelled, as ofcom.Manager.WatchUpdates does, but through the server's
+// own database: an update waits for a setup started by POST /admin/setup,
+// and the server is reloaded after it as by POST /admin/reload, rebuilding
+// the in-memory index if there is one. A zero opts.DownloadTimeout means
+// the one set by WithDownloadTimeout.
+func (s *Server) WatchUpdates(ctx context.Context, interval time.Duration, opts ofcom.SetupOptions) error {
+	if opts.DownloadTimeout == 0 {
+		opts.DownloadTimeout = s.setup.downloadTimeout
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.update(opts)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// update runs one check of WatchUpdates.
+func (s *Server) update(opts ofcom.SetupOptions) {
+	s.setup.writing.Lock()
+	defer s.setup.writing.Unlock()
+	st, err := s.checker.Update(opts)
+	switch {
+	case err != nil:
+		s.logger.Error("dataset update failed", "err", err)
+	case !st.Available:
+		s.logger.Debug("dataset is up to date", "year", st.InstalledYear, "revision", st.InstalledRevision)
+	default:
+		if err := s.reload(); err != nil {
+			s.logger.Error("reloading the updated dataset failed", "err", err)
+			return
+		}
+		s.logger.Info("database reloaded", "dataset_year", st.Latest.Year, "revision", st.Latest.Revision)
+	}
+}
+
+// GET /admin/datasets — the installed dataset years with their sizes and
+// row counts. DELETE /admin/datasets/{year} removes a year other than the
+// current one. Both require the admin token.
+func (s *Server) handleAdminDatasets(w http.ResponseWriter, r *http.Request) {
+	year := strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/datasets"), "/")
+	switch {
+	case year == "" && r.Method == http.MethodGet:
+	case year != "" && r.Method == http.MethodDelete:
+	case year == "":
+		writeError(w, http.StatusMethodNotAllowed, "GET required")
+		return
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "DELETE required")
+		return
+	}
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+
+	if year == "" {
+		datasets, err := s.checker.Datasets()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if datasets == nil {
+			datasets = []ofcom.Dataset{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"status": "ok", "schema_version": checker.SchemaVersion, "datasets": datasets})
+		return
+	}
+	if st, ok := s.setup.status(); ok && st.State == setupRunning {
+		writeError(w, http.StatusConflict, "a setup is running; try again once it has finished")
+		return
+	}
+	switch err := s.checker.RemoveYear(year); {
+	case errors.Is(err, ofcom.ErrYearNotInstalled):
+		writeError(w, http.StatusNotFound, fmt.Sprintf("dataset year %q is not installed", year))
+	case errors.Is(err, ofcom.ErrCurrentYear):
+		writeError(w, http.StatusConflict, err.Error())
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	default:
+		logging.FromContext(r.Context(), s.logger).Info("dataset year removed", "year", year)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
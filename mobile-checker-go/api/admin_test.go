@@ -0,0 +1,168 @@
+package api_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/mobile-checker/api"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func TestAdmin_SetupListAndRemoveDatasets(t *testing.T) {
+	dir := newDataDir(t, nil)
+	// A second year, installed alongside the current one from a CSV
+	// already in place rather than downloaded.
+	yearDir := filepath.Join(dir, "years", "2022")
+	if err := os.MkdirAll(yearDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(yearDir, "ofcom_mobile_2022.csv"), []byte(testCSV), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := api.NewServer(dir, quietLogger(), api.WithAdminToken("s3cret"))
+	defer s.Close()
+	h := s.Handler()
+	admin := func(method, target string, token string) *http.Response {
+		req := httptest.NewRequest(method, target, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Result()
+	}
+
+	if resp := admin(http.MethodPost, "/admin/setup?year=2022", "guess"); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 without the admin token, got %d", resp.StatusCode)
+	}
+	if resp := admin(http.MethodPost, "/admin/setup?year=../../etc", "s3cret"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid year, got %d", resp.StatusCode)
+	}
+	if resp := admin(http.MethodGet, "/admin/setup", "s3cret"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 before any setup, got %d", resp.StatusCode)
+	}
+	resp := admin(http.MethodPost, "/admin/setup?year=2022", "s3cret")
+	if resp.StatusCode != http.StatusAccepted || resp.Header.Get("Location") != "/admin/setup" {
+		t.Fatalf("expected 202 pointing at /admin/setup, got %d %v", resp.StatusCode, resp.Header)
+	}
+	var setup map[string]any
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		setup = decode(t, admin(http.MethodGet, "/admin/setup", "s3cret"))["setup"].(map[string]any)
+		if setup["state"] != "running" || time.Now().After(deadline) {
+			break
+		}
+	}
+	if setup["state"] != "done" || setup["year"] != "2022" || setup["finished_at"] == nil {
+		t.Fatalf("expected setup of 2022 done, got %v", setup)
+	}
+	if p := setup["progress"].(map[string]any); p["stage"] != "ranks" {
+		t.Errorf("expected progress through to ranks, got %v", p)
+	}
+
+	datasets := decode(t, admin(http.MethodGet, "/admin/datasets", "s3cret"))["datasets"].([]any)
+	if len(datasets) != 2 {
+		t.Fatalf("expected 2 datasets, got %v", datasets)
+	}
+	for i, want := range []struct {
+		year    string
+		current bool
+	}{{"2022", false}, {"2023", true}} {
+		d := datasets[i].(map[string]any)
+		if d["year"] != want.year || d["current"] != want.current || d["rows"] != float64(2) || d["size_bytes"].(float64) <= 0 {
+			t.Errorf("dataset %d: expected %s (current %v) with 2 rows, got %v", i, want.year, want.current, d)
+		}
+	}
+
+	// The CSV of 2022 downloaded before 2023 replaced it, and a download
+	// that never finished, go with it.
+	downloads := []string{"ofcom_mobile_2022.csv", "ofcom_mobile_2022.csv.partial", "ofcom_mobile_2022.zip.partial"}
+	for _, name := range downloads {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(testCSV), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, tc := range []struct {
+		year string
+		want int
+	}{{"2023", http.StatusConflict}, {"2022", http.StatusNoContent}, {"2022", http.StatusNotFound}} {
+		if resp := admin(http.MethodDelete, "/admin/datasets/"+tc.year, "s3cret"); resp.StatusCode != tc.want {
+			t.Errorf("DELETE %s: expected %d, got %d", tc.year, tc.want, resp.StatusCode)
+		}
+	}
+	if _, err := os.Stat(yearDir); !os.IsNotExist(err) {
+		t.Errorf("expected the 2022 dataset removed from disk, got %v", err)
+	}
+	for _, name := range downloads {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("expected %s removed, got %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ofcom_mobile_2023.csv")); err != nil {
+		t.Errorf("expected the current year's CSV kept, got %v", err)
+	}
+}
+
+func TestWatchUpdates_InstallsAndReloads(t *testing.T) {
+	zipOf := func(csv string) []byte {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		fw, _ := zw.Create("mobile_pc.csv")
+		fw.Write([]byte(csv))
+		zw.Close()
+		return buf.Bytes()
+	}
+	r01 := zipOf("postcode,ee_4g,o2_4g,three_4g,vodafone_4g\nLS11AA,0.0,0.0,0.0,0.0\n")
+	r02 := zipOf(testCSV)
+	links := `<a href="/2024_mobile_pc_r01.zip">r01</a>`
+	mux := http.NewServeMux()
+	mux.HandleFunc("/index", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(links)) })
+	mux.HandleFunc("/2024_mobile_pc_r01.zip", func(w http.ResponseWriter, r *http.Request) { w.Write(r01) })
+	mux.HandleFunc("/2024_mobile_pc_r02.zip", func(w http.ResponseWriter, r *http.Request) { w.Write(r02) })
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	opts := ofcom.SetupOptions{IndexURL: srv.URL + "/index"}
+
+	dir := t.TempDir()
+	if err := ofcom.NewManager(dir).Setup(ofcom.LatestYear, opts); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	var logs bytes.Buffer
+	s := api.NewServer(dir, api.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))), api.WithOffline())
+	defer s.Close()
+	if err := s.LoadIndex(); err != nil {
+		t.Fatal(err)
+	}
+	h := s.Handler()
+	score := func() any {
+		body := decode(t, get(t, h, "/api/mobile/LS11AA"))
+		mobile, _ := body["result"].(map[string]any)["mobile"].(map[string]any)
+		return mobile["CoverageScore"]
+	}
+	before := score()
+
+	links += ` <a href="/2024_mobile_pc_r02.zip">r02</a>`
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.WatchUpdates(ctx, time.Hour, opts) }()
+	var after any
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if after = score(); after != before {
+			break
+		}
+	}
+	cancel()
+	<-done
+	if after == before {
+		t.Errorf("expected the updated dataset to be served, still got score %v", after)
+	}
+	if !strings.Contains(logs.String(), "database reloaded") {
+		t.Errorf("expected the server reloaded after the update, got %q", logs.String())
+	}
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+const (
+	// suggestTTL is how long autocomplete answers are reused by default.
+	suggestTTL = 10 * time.Minute
+	// suggestCacheSize bounds the number of cached queries.
+	suggestCacheSize = 5000
+	// defaultSuggestLimit is the number of suggestions returned by default.
+	defaultSuggestLimit = 10
+)
+
+// suggestCache holds recent postcodes.io autocomplete answers. Type-ahead
+// UIs send a request per keystroke, so most prefixes repeat.
+type suggestCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]suggestEntry
+}
+
+type suggestEntry struct {
+	postcodes []string
+	expires   time.Time
+}
+
+func (c *suggestCache) get(key string) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || time.Now().After(e.expires) {
+		return nil, false
+	}
+	return e.postcodes, true
+}
+
+func (c *suggestCache) put(key string, pcs []string) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]suggestEntry)
+	}
+	if len(c.entries) >= suggestCacheSize {
+		now := time.Now()
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= suggestCacheSize {
+			c.entries = make(map[string]suggestEntry)
+		}
+	}
+	c.entries[key] = suggestEntry{postcodes: pcs, expires: time.Now().Add(c.ttl)}
+}
+
+// WithSuggestTTL sets how long autocomplete answers are cached, and how
+// long clients are told they may cache them; 0 disables caching.
+func WithSuggestTTL(d time.Duration) Option {
+	return func(s *Server) { s.suggest.ttl = d }
+}
+
+// GET /api/postcodes/autocomplete?q=SW1A&limit=10
+func (s *Server) handleAutocomplete(w http.ResponseWriter, r *http.Request) {
+	q := postcode.Normalise(r.URL.Query().Get("q"))
+	if len(q) < 2 {
+		writeError(w, http.StatusBadRequest, "q must be at least 2 characters")
+		return
+	}
+	limit := defaultSuggestLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > postcode.MaxAutocomplete {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(postcode.MaxAutocomplete))
+			return
+		}
+		limit = n
+	}
+
+	key := q + "|" + strconv.Itoa(limit)
+	pcs, ok := s.suggest.get(key)
+	if !ok {
+		var err error
+		pcs, err = s.checkerFor(r).Suggest(q, limit)
+		switch {
+		case errors.Is(err, postcode.ErrInvalid):
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		case err != nil:
+			writeError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+		s.suggest.put(key, pcs)
+	}
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(s.suggest.ttl.Seconds())))
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ok", "schema_version": checker.SchemaVersion, "query": q, "postcodes": pcs})
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+// DefaultCacheMaxAge is how long clients and shared caches may reuse a
+// coverage check before revalidating it.
+const DefaultCacheMaxAge = time.Hour
+
+// WithCacheMaxAge sets the max-age of coverage checks' Cache-Control
+// header. Zero makes caches revalidate every time, which costs only a 304
+// while the dataset is unchanged.
+func WithCacheMaxAge(d time.Duration) Option {
+	return func(s *Server) { s.cacheMaxAge = d }
+}
+
+// cacheTag is the validator for one coverage check response.
+type cacheTag struct {
+	etag    string
+	builtAt time.Time
+}
+
+// cacheTagFor returns the validator for a check of pc: a hash of the
+// dataset's metadata (year, revision, build time), the postcode, the query
+// string, the negotiated format, the server's scoring settings and, for a
+// trend, the installed years. It is nil when the dataset is unavailable,
+// so nothing is cached.
+func (s *Server) cacheTagFor(r *http.Request, pc string, opts checker.CheckOptions) *cacheTag {
+	return s.cacheTagForYears(r, pc, opts.Trend, opts.Year)
+}
+
+// cacheTagForYears is cacheTagFor for a response read from each of years,
+// "" being the current dataset, e.g. both years of a diff. Its
+// Last-Modified is the latest of their build times.
+func (s *Server) cacheTagForYears(r *http.Request, pc string, trend bool, years ...string) *cacheTag {
+	format, err := negotiate(r)
+	if err != nil {
+		return nil
+	}
+	h := sha256.New()
+	tag := &cacheTag{}
+	for _, year := range years {
+		c := s.checker
+		if year != "" {
+			yc, err := c.ForYear(year)
+			if err != nil {
+				return nil
+			}
+			c = yc
+		}
+		meta, err := c.DatasetMeta()
+		if err != nil || meta["built_at"] == "" {
+			return nil
+		}
+		keys := make([]string, 0, len(meta))
+		for k := range meta {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(h, "%s=%s\n", k, meta[k])
+		}
+		if builtAt, err := time.Parse(time.RFC3339Nano, meta["built_at"]); err == nil && builtAt.After(tag.builtAt) {
+			tag.builtAt = builtAt
+		}
+	}
+	fmt.Fprintf(h, "%s\n%s\n%s\n%v %v %v\n", postcode.Normalise(pc), r.URL.Query().Encode(), format, s.weights, s.threshold, s.offline)
+	if trend {
+		// A trend reads every installed year, not just the one checked.
+		fmt.Fprintf(h, "%s\n", strings.Join(s.checker.InstalledYears(), ","))
+	}
+	tag.etag = `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+	return tag
+}
+
+// notModified reports whether r's conditional headers show the client
+// already holds the response tagged t. If-None-Match takes precedence over
+// If-Modified-Since.
+func (t *cacheTag) notModified(r *http.Request) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == t.etag {
+				return true
+			}
+		}
+		return false
+	}
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	return err == nil && !t.builtAt.IsZero() && !t.builtAt.Truncate(time.Second).After(ims)
+}
+
+// write sets the validator and caching headers. Only successful checks
+// carry them, so failures such as an unreachable postcodes.io are never
+// cached.
+func (t *cacheTag) write(w http.ResponseWriter, maxAge time.Duration) {
+	w.Header().Set("ETag", t.etag)
+	if !t.builtAt.IsZero() {
+		w.Header().Set("Last-Modified", t.builtAt.UTC().Format(http.TimeFormat))
+	}
+	if maxAge > 0 {
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+}
+
+// writeNotModified answers 304 with the validator and caching headers.
+// respond, which is skipped, would have added Vary: Accept; caches need it
+// on the 304 too, as the tag depends on the negotiated format.
+func (t *cacheTag) writeNotModified(w http.ResponseWriter, maxAge time.Duration) {
+	w.Header().Add("Vary", "Accept")
+	t.write(w, maxAge)
+	w.WriteHeader(http.StatusNotModified)
+}
@@ -0,0 +1,116 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/mobile-checker/api"
+	"github.com/yourusername/mobile-checker/internal/history"
+)
+
+// newPostcodesIO fakes postcodes.io, knowing only LS1 1AA.
+func newPostcodesIO(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/postcodes/LS11AA" {
+			w.Write([]byte(`{"status":200,"result":{"postcode":"LS1 1AA","country":"England","admin_district":"Leeds","latitude":53.797,"longitude":-1.548}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"status":404,"error":"Postcode not found"}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCheck_ConditionalRequests(t *testing.T) {
+	postcodes := newPostcodesIO(t)
+	h := api.NewServer(newDataDir(t, nil), quietLogger(), api.WithPostcodesURL(postcodes.URL)).Handler()
+
+	first := get(t, h, "/api/mobile/LS11AA")
+	if first.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", first.StatusCode)
+	}
+	etag, lastModified := first.Header.Get("ETag"), first.Header.Get("Last-Modified")
+	if etag == "" || lastModified == "" {
+		t.Fatalf("expected validators, got ETag %q, Last-Modified %q", etag, lastModified)
+	}
+	if got := first.Header.Get("Cache-Control"); got != "public, max-age=3600" {
+		t.Errorf("expected the default max-age, got %q", got)
+	}
+	modified, _ := http.ParseTime(lastModified)
+
+	for _, tc := range []struct {
+		name   string
+		header []string
+		status int
+	}{
+		{"matching etag", []string{"If-None-Match", etag}, http.StatusNotModified},
+		{"weak etag", []string{"If-None-Match", "W/" + etag}, http.StatusNotModified},
+		{"etag in list", []string{"If-None-Match", `"other", ` + etag}, http.StatusNotModified},
+		{"any etag", []string{"If-None-Match", "*"}, http.StatusNotModified},
+		{"other etag", []string{"If-None-Match", `"other"`}, http.StatusOK},
+		{"etag beats date", []string{"If-None-Match", `"other"`, "If-Modified-Since", lastModified}, http.StatusOK},
+		{"not modified since", []string{"If-Modified-Since", lastModified}, http.StatusNotModified},
+		{"modified since", []string{"If-Modified-Since", modified.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusOK},
+		{"other format", []string{"If-None-Match", etag, "Accept", "text/csv"}, http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := get(t, h, "/api/mobile/LS11AA", tc.header...)
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected %d, got %d", tc.status, resp.StatusCode)
+			}
+			vary := strings.Join(resp.Header.Values("Vary"), ", ")
+			if !strings.Contains(vary, "Accept-Encoding") || !strings.Contains(strings.ReplaceAll(vary, "Accept-Encoding", ""), "Accept") {
+				t.Errorf("expected Vary: Accept and Accept-Encoding, got %q", vary)
+			}
+			if resp.Header.Get("Cache-Control") == "" {
+				t.Error("expected Cache-Control")
+			}
+			if tc.status == http.StatusNotModified && resp.Header.Get("ETag") != etag {
+				t.Errorf("expected the ETag on the 304, got %q", resp.Header.Get("ETag"))
+			}
+		})
+	}
+
+	if csvTag := get(t, h, "/api/mobile/LS11AA", "Accept", "text/csv").Header.Get("ETag"); csvTag == "" || csvTag == etag {
+		t.Errorf("expected a different ETag per format, got %q", csvTag)
+	}
+	if resp := get(t, h, "/api/mobile/LS99ZZ"); resp.Header.Get("ETag") != "" || resp.Header.Get("Cache-Control") != "" {
+		t.Errorf("expected errors to be uncached, got ETag %q", resp.Header.Get("ETag"))
+	}
+}
+
+func TestCheck_CacheMaxAge(t *testing.T) {
+	postcodes := newPostcodesIO(t)
+	h := api.NewServer(newDataDir(t, nil), quietLogger(), api.WithPostcodesURL(postcodes.URL), api.WithCacheMaxAge(0)).Handler()
+	if got := get(t, h, "/api/mobile/LS11AA").Header.Get("Cache-Control"); got != "no-cache" {
+		t.Errorf("expected no-cache, got %q", got)
+	}
+}
+
+func TestCheck_NotModifiedIsRecorded(t *testing.T) {
+	postcodes := newPostcodesIO(t)
+	dir := newDataDir(t, nil)
+	srv := api.NewServer(dir, quietLogger(), api.WithPostcodesURL(postcodes.URL), api.WithHistory())
+	defer srv.Close()
+	h := srv.Handler()
+
+	etag := get(t, h, "/api/mobile/LS11AA").Header.Get("ETag")
+	if resp := get(t, h, "/api/mobile/LS11AA", "If-None-Match", etag); resp.StatusCode != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d", resp.StatusCode)
+	}
+
+	store := history.New(dir)
+	defer store.Close()
+	entries, err := store.List("LS11AA", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected both checks recorded, got %d", len(entries))
+	}
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// compressMinSize is the smallest response worth compressing; smaller
+// bodies grow or barely shrink once encoded.
+const compressMinSize = 1024
+
+// WithoutCompression disables gzip and brotli response compression, e.g.
+// behind a proxy that compresses already.
+func WithoutCompression() Option {
+	return func(s *Server) { s.noCompress = true }
+}
+
+// encoder is implemented by gzip.Writer and brotli.Writer.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(io.Writer)
+}
+
+// encoders pools writers per Content-Encoding, as each holds large
+// buffers. Brotli level 5 compresses about as fast as gzip, and better.
+var encoders = map[string]*sync.Pool{
+	"br":   {New: func() any { return brotli.NewWriterLevel(nil, 5) }},
+	"gzip": {New: func() any { return gzip.NewWriter(nil) }},
+}
+
+// acceptEncoding picks the response encoding from r's Accept-Encoding:
+// the most preferred of br and gzip, with br winning ties. It returns ""
+// when neither is acceptable.
+func acceptEncoding(r *http.Request) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if name == "*" {
+			name = "br"
+		}
+		if _, ok := encoders[name]; ok && (q > bestQ || q == bestQ && q > 0 && name == "br") {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
+
+// compressible reports whether responses of contentType shrink when
+// compressed: text, JSON, XML and JavaScript, but not images.
+func compressible(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(mt, "text/") || strings.HasSuffix(mt, "json") || strings.HasSuffix(mt, "xml") ||
+		mt == "application/javascript" || mt == "image/svg+xml"
+}
+
+// compress encodes responses with gzip or brotli when the client accepts
+// it. The decision waits for the first compressMinSize bytes, or a flush,
+// so small bodies go out as they are while NDJSON streams are compressed
+// and still flushed line by line.
+func compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := acceptEncoding(r)
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding, status: http.StatusOK}
+		defer cw.Close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// compressWriter buffers the start of a response until it knows whether to
+// compress it.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	status   int
+	buf      []byte
+	started  bool
+	enc      encoder // nil when the response is sent as it is
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.started || code < http.StatusOK {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+	if code == http.StatusNoContent || code == http.StatusNotModified {
+		w.start(false)
+	}
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if !w.started {
+		w.buf = append(w.buf, b...)
+		if len(w.buf) < compressMinSize {
+			return len(b), nil
+		}
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if w.enc != nil {
+		return w.enc.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// start sends the header, choosing the encoding, then anything buffered.
+func (w *compressWriter) start(worthIt bool) error {
+	w.started = true
+	h := w.Header()
+	if worthIt && w.status != http.StatusPartialContent && h.Get("Content-Encoding") == "" && compressible(h.Get("Content-Type")) {
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+		// The encoded body differs byte for byte; see cacheTag.notModified.
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set("ETag", "W/"+etag)
+		}
+		w.enc = encoders[w.encoding].Get().(encoder)
+		w.enc.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if len(w.buf) == 0 {
+		return nil
+	}
+	buf := w.buf
+	w.buf = nil
+	if w.enc != nil {
+		_, err := w.enc.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// Flush sends what has been written so far, compressed if the response
+// could be, so streamed lines reach the client promptly.
+func (w *compressWriter) Flush() {
+	if !w.started {
+		w.start(len(w.buf) > 0)
+	}
+	if w.enc != nil {
+		w.enc.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close finishes the response once the handler returns.
+func (w *compressWriter) Close() error {
+	if !w.started {
+		if len(w.buf) == 0 && w.status == http.StatusOK {
+			return nil // nothing written; let net/http send its default
+		}
+		w.start(false)
+	}
+	if w.enc == nil {
+		return nil
+	}
+	err := w.enc.Close()
+	encoders[w.encoding].Put(w.enc)
+	w.enc = nil
+	return err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
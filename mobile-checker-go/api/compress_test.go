@@ -0,0 +1,161 @@
+package api_test
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/yourusername/mobile-checker/api"
+)
+
+func TestAcceptEncoding(t *testing.T) {
+	for header, want := range map[string]string{
+		"":                        "",
+		"gzip":                    "gzip",
+		"br":                      "br",
+		"GZIP":                    "gzip",
+		"gzip, br":                "br",
+		"gzip, deflate, br":       "br",
+		"gzip;q=1.0, br;q=0.5":    "gzip",
+		"br;q=0.8, gzip;q=0.9":    "gzip",
+		"gzip; q=0.5, br; q=0.5":  "br",
+		"br;q=0":                  "",
+		"br;q=0, gzip":            "gzip",
+		"*":                       "br",
+		"*;q=0":                   "",
+		"identity":                "",
+		"deflate":                 "",
+		"gzip;q=nonsense, br;q=1": "br",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if header != "" {
+			req.Header.Set("Accept-Encoding", header)
+		}
+		if got := api.AcceptEncoding(req); got != want {
+			t.Errorf("%q: expected %q, got %q", header, want, got)
+		}
+	}
+}
+
+// serveCompressed serves body through the compression middleware and
+// returns the response with its body decoded.
+func serveCompressed(t *testing.T, h http.Handler, acceptEncoding string) (*http.Response, string) {
+	t.Helper()
+	resp := get(t, api.Compress(h), "/", "Accept-Encoding", acceptEncoding)
+	var r io.Reader = resp.Body
+	switch resp.Header.Get("Content-Encoding") {
+	case "gzip":
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			t.Fatalf("invalid gzip: %v", err)
+		}
+		r = gz
+	case "br":
+		r = brotli.NewReader(resp.Body)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("decoding %q body: %v", resp.Header.Get("Content-Encoding"), err)
+	}
+	return resp, string(b)
+}
+
+func textHandler(contentType, body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("ETag", `"v1"`)
+		io.WriteString(w, body)
+	})
+}
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat(`{"postcode":"LS11AA","ee":"4G"}`+"\n", api.CompressMinSize/16)
+	small := `{"status":"ok"}`
+
+	for _, tc := range []struct {
+		name           string
+		acceptEncoding string
+		contentType    string
+		body           string
+		encoding       string
+	}{
+		{"brotli", "gzip, br", "application/json", large, "br"},
+		{"gzip", "gzip", "application/json", large, "gzip"},
+		{"identity", "identity", "application/json", large, ""},
+		{"refused", "br;q=0, gzip;q=0", "application/json", large, ""},
+		{"small body", "br", "application/json", small, ""},
+		{"image", "br", "image/png", large, ""},
+		{"svg", "gzip", "image/svg+xml", large, "gzip"},
+		{"csv", "gzip", "text/csv; charset=utf-8", large, "gzip"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, body := serveCompressed(t, textHandler(tc.contentType, tc.body), tc.acceptEncoding)
+			if got := resp.Header.Get("Content-Encoding"); got != tc.encoding {
+				t.Fatalf("expected Content-Encoding %q, got %q", tc.encoding, got)
+			}
+			if body != tc.body {
+				t.Errorf("body changed in transit: got %d bytes, want %d", len(body), len(tc.body))
+			}
+			if got := resp.Header.Get("Vary"); got != "Accept-Encoding" {
+				t.Errorf("expected Vary: Accept-Encoding, got %q", got)
+			}
+			wantETag := `"v1"`
+			if tc.encoding != "" {
+				wantETag = `W/"v1"`
+			}
+			if got := resp.Header.Get("ETag"); got != wantETag {
+				t.Errorf("expected ETag %s, got %s", wantETag, got)
+			}
+		})
+	}
+}
+
+func TestCompress_StatusWithoutBody(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotModified)
+	})
+	resp, body := serveCompressed(t, h, "br")
+	if resp.StatusCode != http.StatusNotModified || body != "" || resp.Header.Get("Content-Encoding") != "" {
+		t.Errorf("expected a bare 304, got %d %q with encoding %q", resp.StatusCode, body, resp.Header.Get("Content-Encoding"))
+	}
+}
+
+func TestCompress_FlushCompressesStreams(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for i := 0; i < 3; i++ {
+			fmt.Fprintf(w, "{\"line\":%d}\n", i)
+			w.(http.Flusher).Flush()
+		}
+	})
+	resp, body := serveCompressed(t, h, "gzip")
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected a flushed stream to be compressed, got %q", resp.Header.Get("Content-Encoding"))
+	}
+	if want := "{\"line\":0}\n{\"line\":1}\n{\"line\":2}\n"; body != want {
+		t.Errorf("expected %q, got %q", want, body)
+	}
+}
+
+// Pooled encoders are reset for each response, so nothing leaks from one
+// response, or encoding, into the next.
+func TestCompress_ReusesEncoders(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		encoding := []string{"br", "gzip"}[i%2]
+		body := strings.Repeat(fmt.Sprintf("response %d\n", i), api.CompressMinSize/8)
+		resp, got := serveCompressed(t, textHandler("text/plain", body), encoding)
+		if resp.Header.Get("Content-Encoding") != encoding {
+			t.Fatalf("response %d: expected %s, got %q", i, encoding, resp.Header.Get("Content-Encoding"))
+		}
+		if got != body {
+			t.Fatalf("response %d: body corrupted by a reused %s encoder", i, encoding)
+		}
+	}
+}
@@ -0,0 +1,1001 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.33.0
+// 	protoc        (unknown)
+// source: coverage/v1/coverage.proto
+
+package coveragepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CheckRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Postcode string `protobuf:"bytes,1,opt,name=postcode,proto3" json:"postcode,omitempty"`
+	// Report indoor rather than outdoor coverage.
+	Indoor bool `protobuf:"varint,2,opt,name=indoor,proto3" json:"indoor,omitempty"`
+}
+
+func (x *CheckRequest) Reset() {
+	*x = CheckRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_coverage_v1_coverage_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckRequest) ProtoMessage() {}
+
+func (x *CheckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coverage_v1_coverage_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckRequest.ProtoReflect.Descriptor instead.
+func (*CheckRequest) Descriptor() ([]byte, []int) {
+	return file_coverage_v1_coverage_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CheckRequest) GetPostcode() string {
+	if x != nil {
+		return x.Postcode
+	}
+	return ""
+}
+
+func (x *CheckRequest) GetIndoor() bool {
+	if x != nil {
+		return x.Indoor
+	}
+	return false
+}
+
+type CheckResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Result *Result `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+}
+
+func (x *CheckResponse) Reset() {
+	*x = CheckResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_coverage_v1_coverage_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckResponse) ProtoMessage() {}
+
+func (x *CheckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coverage_v1_coverage_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckResponse.ProtoReflect.Descriptor instead.
+func (*CheckResponse) Descriptor() ([]byte, []int) {
+	return file_coverage_v1_coverage_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CheckResponse) GetResult() *Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type CheckBulkRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Postcodes []string `protobuf:"bytes,1,rep,name=postcodes,proto3" json:"postcodes,omitempty"`
+	Indoor    bool     `protobuf:"varint,2,opt,name=indoor,proto3" json:"indoor,omitempty"`
+}
+
+func (x *CheckBulkRequest) Reset() {
+	*x = CheckBulkRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_coverage_v1_coverage_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckBulkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckBulkRequest) ProtoMessage() {}
+
+func (x *CheckBulkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coverage_v1_coverage_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckBulkRequest.ProtoReflect.Descriptor instead.
+func (*CheckBulkRequest) Descriptor() ([]byte, []int) {
+	return file_coverage_v1_coverage_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CheckBulkRequest) GetPostcodes() []string {
+	if x != nil {
+		return x.Postcodes
+	}
+	return nil
+}
+
+func (x *CheckBulkRequest) GetIndoor() bool {
+	if x != nil {
+		return x.Indoor
+	}
+	return false
+}
+
+type CheckBulkResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Position of the postcode in the request.
+	Index  int32   `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Result *Result `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+}
+
+func (x *CheckBulkResponse) Reset() {
+	*x = CheckBulkResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_coverage_v1_coverage_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckBulkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckBulkResponse) ProtoMessage() {}
+
+func (x *CheckBulkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coverage_v1_coverage_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckBulkResponse.ProtoReflect.Descriptor instead.
+func (*CheckBulkResponse) Descriptor() ([]byte, []int) {
+	return file_coverage_v1_coverage_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CheckBulkResponse) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *CheckBulkResponse) GetResult() *Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type HealthRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *HealthRequest) Reset() {
+	*x = HealthRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_coverage_v1_coverage_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HealthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthRequest) ProtoMessage() {}
+
+func (x *HealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coverage_v1_coverage_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
+func (*HealthRequest) Descriptor() ([]byte, []int) {
+	return file_coverage_v1_coverage_proto_rawDescGZIP(), []int{4}
+}
+
+type HealthResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Status  string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Service string `protobuf:"bytes,2,opt,name=service,proto3" json:"service,omitempty"`
+}
+
+func (x *HealthResponse) Reset() {
+	*x = HealthResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_coverage_v1_coverage_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthResponse) ProtoMessage() {}
+
+func (x *HealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coverage_v1_coverage_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
+func (*HealthResponse) Descriptor() ([]byte, []int) {
+	return file_coverage_v1_coverage_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *HealthResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *HealthResponse) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+type Result struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Postcode   string         `protobuf:"bytes,1,opt,name=postcode,proto3" json:"postcode,omitempty"`
+	Valid      bool           `protobuf:"varint,2,opt,name=valid,proto3" json:"valid,omitempty"`
+	Geographic *Geographic    `protobuf:"bytes,3,opt,name=geographic,proto3" json:"geographic,omitempty"`
+	Mobile     *MobileSummary `protobuf:"bytes,4,opt,name=mobile,proto3" json:"mobile,omitempty"`
+	Error      string         `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	Note       string         `protobuf:"bytes,6,opt,name=note,proto3" json:"note,omitempty"`
+	// Machine-readable failure code, e.g. POSTCODE_NOT_FOUND; empty on success.
+	Code string `protobuf:"bytes,7,opt,name=code,proto3" json:"code,omitempty"`
+}
+
+func (x *Result) Reset() {
+	*x = Result{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_coverage_v1_coverage_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Result) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Result) ProtoMessage() {}
+
+func (x *Result) ProtoReflect() protoreflect.Message {
+	mi := &file_coverage_v1_coverage_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Result.ProtoReflect.Descriptor instead.
+func (*Result) Descriptor() ([]byte, []int) {
+	return file_coverage_v1_coverage_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Result) GetPostcode() string {
+	if x != nil {
+		return x.Postcode
+	}
+	return ""
+}
+
+func (x *Result) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *Result) GetGeographic() *Geographic {
+	if x != nil {
+		return x.Geographic
+	}
+	return nil
+}
+
+func (x *Result) GetMobile() *MobileSummary {
+	if x != nil {
+		return x.Mobile
+	}
+	return nil
+}
+
+func (x *Result) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Result) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+func (x *Result) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type Geographic struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Country                   string  `protobuf:"bytes,1,opt,name=country,proto3" json:"country,omitempty"`
+	Region                    string  `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"`
+	AdminDistrict             string  `protobuf:"bytes,3,opt,name=admin_district,json=adminDistrict,proto3" json:"admin_district,omitempty"`
+	ParliamentaryConstituency string  `protobuf:"bytes,4,opt,name=parliamentary_constituency,json=parliamentaryConstituency,proto3" json:"parliamentary_constituency,omitempty"`
+	Latitude                  float64 `protobuf:"fixed64,5,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude                 float64 `protobuf:"fixed64,6,opt,name=longitude,proto3" json:"longitude,omitempty"`
+}
+
+func (x *Geographic) Reset() {
+	*x = Geographic{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_coverage_v1_coverage_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Geographic) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Geographic) ProtoMessage() {}
+
+func (x *Geographic) ProtoReflect() protoreflect.Message {
+	mi := &file_coverage_v1_coverage_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Geographic.ProtoReflect.Descriptor instead.
+func (*Geographic) Descriptor() ([]byte, []int) {
+	return file_coverage_v1_coverage_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Geographic) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *Geographic) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *Geographic) GetAdminDistrict() string {
+	if x != nil {
+		return x.AdminDistrict
+	}
+	return ""
+}
+
+func (x *Geographic) GetParliamentaryConstituency() string {
+	if x != nil {
+		return x.ParliamentaryConstituency
+	}
+	return ""
+}
+
+func (x *Geographic) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *Geographic) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+type MobileSummary struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Indoor      bool                `protobuf:"varint,1,opt,name=indoor,proto3" json:"indoor,omitempty"`
+	Operators   []*OperatorCoverage `protobuf:"bytes,2,rep,name=operators,proto3" json:"operators,omitempty"`
+	AnyOperator string              `protobuf:"bytes,3,opt,name=any_operator,json=anyOperator,proto3" json:"any_operator,omitempty"`
+	FourGCount  int32               `protobuf:"varint,4,opt,name=four_g_count,json=fourGCount,proto3" json:"four_g_count,omitempty"`
+	FiveGCount  int32               `protobuf:"varint,5,opt,name=five_g_count,json=fiveGCount,proto3" json:"five_g_count,omitempty"`
+	// Weighted 0-100 score across operators and its letter grade (A-E).
+	CoverageScore int32  `protobuf:"varint,6,opt,name=coverage_score,json=coverageScore,proto3" json:"coverage_score,omitempty"`
+	Grade         string `protobuf:"bytes,7,opt,name=grade,proto3" json:"grade,omitempty"`
+}
+
+func (x *MobileSummary) Reset() {
+	*x = MobileSummary{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_coverage_v1_coverage_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MobileSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MobileSummary) ProtoMessage() {}
+
+func (x *MobileSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_coverage_v1_coverage_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MobileSummary.ProtoReflect.Descriptor instead.
+func (*MobileSummary) Descriptor() ([]byte, []int) {
+	return file_coverage_v1_coverage_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *MobileSummary) GetIndoor() bool {
+	if x != nil {
+		return x.Indoor
+	}
+	return false
+}
+
+func (x *MobileSummary) GetOperators() []*OperatorCoverage {
+	if x != nil {
+		return x.Operators
+	}
+	return nil
+}
+
+func (x *MobileSummary) GetAnyOperator() string {
+	if x != nil {
+		return x.AnyOperator
+	}
+	return ""
+}
+
+func (x *MobileSummary) GetFourGCount() int32 {
+	if x != nil {
+		return x.FourGCount
+	}
+	return 0
+}
+
+func (x *MobileSummary) GetFiveGCount() int32 {
+	if x != nil {
+		return x.FiveGCount
+	}
+	return 0
+}
+
+func (x *MobileSummary) GetCoverageScore() int32 {
+	if x != nil {
+		return x.CoverageScore
+	}
+	return 0
+}
+
+func (x *MobileSummary) GetGrade() string {
+	if x != nil {
+		return x.Grade
+	}
+	return ""
+}
+
+type OperatorCoverage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name     string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Voice    string `protobuf:"bytes,2,opt,name=voice,proto3" json:"voice,omitempty"`
+	FourG    string `protobuf:"bytes,3,opt,name=four_g,json=fourG,proto3" json:"four_g,omitempty"`
+	FiveG    string `protobuf:"bytes,4,opt,name=five_g,json=fiveG,proto3" json:"five_g,omitempty"`
+	HasVoice bool   `protobuf:"varint,5,opt,name=has_voice,json=hasVoice,proto3" json:"has_voice,omitempty"`
+	HasFourG bool   `protobuf:"varint,6,opt,name=has_four_g,json=hasFourG,proto3" json:"has_four_g,omitempty"`
+	HasFiveG bool   `protobuf:"varint,7,opt,name=has_five_g,json=hasFiveG,proto3" json:"has_five_g,omitempty"`
+}
+
+func (x *OperatorCoverage) Reset() {
+	*x = OperatorCoverage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_coverage_v1_coverage_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OperatorCoverage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OperatorCoverage) ProtoMessage() {}
+
+func (x *OperatorCoverage) ProtoReflect() protoreflect.Message {
+	mi := &file_coverage_v1_coverage_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OperatorCoverage.ProtoReflect.Descriptor instead.
+func (*OperatorCoverage) Descriptor() ([]byte, []int) {
+	return file_coverage_v1_coverage_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *OperatorCoverage) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *OperatorCoverage) GetVoice() string {
+	if x != nil {
+		return x.Voice
+	}
+	return ""
+}
+
+func (x *OperatorCoverage) GetFourG() string {
+	if x != nil {
+		return x.FourG
+	}
+	return ""
+}
+
+func (x *OperatorCoverage) GetFiveG() string {
+	if x != nil {
+		return x.FiveG
+	}
+	return ""
+}
+
+func (x *OperatorCoverage) GetHasVoice() bool {
+	if x != nil {
+		return x.HasVoice
+	}
+	return false
+}
+
+func (x *OperatorCoverage) GetHasFourG() bool {
+	if x != nil {
+		return x.HasFourG
+	}
+	return false
+}
+
+func (x *OperatorCoverage) GetHasFiveG() bool {
+	if x != nil {
+		return x.HasFiveG
+	}
+	return false
+}
+
+var File_coverage_v1_coverage_proto protoreflect.FileDescriptor
+
+var file_coverage_v1_coverage_proto_rawDesc = []byte{
+	0x0a, 0x1a, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x2f, 0x76, 0x31, 0x2f, 0x63, 0x6f,
+	0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0b, 0x63, 0x6f,
+	0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x22, 0x42, 0x0a, 0x0c, 0x43, 0x68, 0x65,
+	0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x6f, 0x73,
+	0x74, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x6f, 0x73,
+	0x74, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x69, 0x6e, 0x64, 0x6f, 0x6f, 0x72, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x69, 0x6e, 0x64, 0x6f, 0x6f, 0x72, 0x22, 0x3c, 0x0a,
+	0x0d, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2b,
+	0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13,
+	0x2e, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x48, 0x0a, 0x10, 0x43,
+	0x68, 0x65, 0x63, 0x6b, 0x42, 0x75, 0x6c, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x1c, 0x0a, 0x09, 0x70, 0x6f, 0x73, 0x74, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x09, 0x70, 0x6f, 0x73, 0x74, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x16, 0x0a,
+	0x06, 0x69, 0x6e, 0x64, 0x6f, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x69,
+	0x6e, 0x64, 0x6f, 0x6f, 0x72, 0x22, 0x56, 0x0a, 0x11, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x42, 0x75,
+	0x6c, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e,
+	0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78,
+	0x12, 0x2b, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x13, 0x2e, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x0f, 0x0a,
+	0x0d, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x42,
+	0x0a, 0x0e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x22, 0xe5, 0x01, 0x0a, 0x06, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1a, 0x0a,
+	0x08, 0x70, 0x6f, 0x73, 0x74, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x70, 0x6f, 0x73, 0x74, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x12,
+	0x37, 0x0a, 0x0a, 0x67, 0x65, 0x6f, 0x67, 0x72, 0x61, 0x70, 0x68, 0x69, 0x63, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x2e, 0x76,
+	0x31, 0x2e, 0x47, 0x65, 0x6f, 0x67, 0x72, 0x61, 0x70, 0x68, 0x69, 0x63, 0x52, 0x0a, 0x67, 0x65,
+	0x6f, 0x67, 0x72, 0x61, 0x70, 0x68, 0x69, 0x63, 0x12, 0x32, 0x0a, 0x06, 0x6d, 0x6f, 0x62, 0x69,
+	0x6c, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x63, 0x6f, 0x76, 0x65, 0x72,
+	0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x62, 0x69, 0x6c, 0x65, 0x53, 0x75, 0x6d,
+	0x6d, 0x61, 0x72, 0x79, 0x52, 0x06, 0x6d, 0x6f, 0x62, 0x69, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x6f, 0x74, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x6f, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x22, 0xde, 0x01, 0x0a, 0x0a, 0x47,
+	0x65, 0x6f, 0x67, 0x72, 0x61, 0x70, 0x68, 0x69, 0x63, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x75,
+	0x6e, 0x74, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x72, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x12, 0x25, 0x0a, 0x0e, 0x61,
+	0x64, 0x6d, 0x69, 0x6e, 0x5f, 0x64, 0x69, 0x73, 0x74, 0x72, 0x69, 0x63, 0x74, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0d, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x44, 0x69, 0x73, 0x74, 0x72, 0x69,
+	0x63, 0x74, 0x12, 0x3d, 0x0a, 0x1a, 0x70, 0x61, 0x72, 0x6c, 0x69, 0x61, 0x6d, 0x65, 0x6e, 0x74,
+	0x61, 0x72, 0x79, 0x5f, 0x63, 0x6f, 0x6e, 0x73, 0x74, 0x69, 0x74, 0x75, 0x65, 0x6e, 0x63, 0x79,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x19, 0x70, 0x61, 0x72, 0x6c, 0x69, 0x61, 0x6d, 0x65,
+	0x6e, 0x74, 0x61, 0x72, 0x79, 0x43, 0x6f, 0x6e, 0x73, 0x74, 0x69, 0x74, 0x75, 0x65, 0x6e, 0x63,
+	0x79, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x08, 0x6c, 0x61, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x12, 0x1c, 0x0a,
+	0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74, 0x75, 0x64, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74, 0x75, 0x64, 0x65, 0x22, 0x88, 0x02, 0x0a, 0x0d,
+	0x4d, 0x6f, 0x62, 0x69, 0x6c, 0x65, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x16, 0x0a,
+	0x06, 0x69, 0x6e, 0x64, 0x6f, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x69,
+	0x6e, 0x64, 0x6f, 0x6f, 0x72, 0x12, 0x3b, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x6f,
+	0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x63, 0x6f, 0x76, 0x65, 0x72,
+	0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x43,
+	0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x52, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x6f,
+	0x72, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x61, 0x6e, 0x79, 0x5f, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74,
+	0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x61, 0x6e, 0x79, 0x4f, 0x70, 0x65,
+	0x72, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x20, 0x0a, 0x0c, 0x66, 0x6f, 0x75, 0x72, 0x5f, 0x67, 0x5f,
+	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x66, 0x6f, 0x75,
+	0x72, 0x47, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x20, 0x0a, 0x0c, 0x66, 0x69, 0x76, 0x65, 0x5f,
+	0x67, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x66,
+	0x69, 0x76, 0x65, 0x47, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x25, 0x0a, 0x0e, 0x63, 0x6f, 0x76,
+	0x65, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0d, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x53, 0x63, 0x6f, 0x72, 0x65,
+	0x12, 0x14, 0x0a, 0x05, 0x67, 0x72, 0x61, 0x64, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x67, 0x72, 0x61, 0x64, 0x65, 0x22, 0xc3, 0x01, 0x0a, 0x10, 0x4f, 0x70, 0x65, 0x72, 0x61,
+	0x74, 0x6f, 0x72, 0x43, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x14, 0x0a, 0x05, 0x76, 0x6f, 0x69, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x76, 0x6f, 0x69, 0x63, 0x65, 0x12, 0x15, 0x0a, 0x06, 0x66, 0x6f, 0x75, 0x72, 0x5f, 0x67, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x66, 0x6f, 0x75, 0x72, 0x47, 0x12, 0x15, 0x0a, 0x06,
+	0x66, 0x69, 0x76, 0x65, 0x5f, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x66, 0x69,
+	0x76, 0x65, 0x47, 0x12, 0x1b, 0x0a, 0x09, 0x68, 0x61, 0x73, 0x5f, 0x76, 0x6f, 0x69, 0x63, 0x65,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x68, 0x61, 0x73, 0x56, 0x6f, 0x69, 0x63, 0x65,
+	0x12, 0x1c, 0x0a, 0x0a, 0x68, 0x61, 0x73, 0x5f, 0x66, 0x6f, 0x75, 0x72, 0x5f, 0x67, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x68, 0x61, 0x73, 0x46, 0x6f, 0x75, 0x72, 0x47, 0x12, 0x1c,
+	0x0a, 0x0a, 0x68, 0x61, 0x73, 0x5f, 0x66, 0x69, 0x76, 0x65, 0x5f, 0x67, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x08, 0x68, 0x61, 0x73, 0x46, 0x69, 0x76, 0x65, 0x47, 0x32, 0xe2, 0x01, 0x0a,
+	0x0f, 0x43, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
+	0x12, 0x3e, 0x0a, 0x05, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x19, 0x2e, 0x63, 0x6f, 0x76, 0x65,
+	0x72, 0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x2e,
+	0x76, 0x31, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x4c, 0x0a, 0x09, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x42, 0x75, 0x6c, 0x6b, 0x12, 0x1d, 0x2e,
+	0x63, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68, 0x65, 0x63,
+	0x6b, 0x42, 0x75, 0x6c, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x63,
+	0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x42, 0x75, 0x6c, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x41,
+	0x0a, 0x06, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x12, 0x1a, 0x2e, 0x63, 0x6f, 0x76, 0x65, 0x72,
+	0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x2e,
+	0x76, 0x31, 0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x42, 0x37, 0x5a, 0x35, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
+	0x79, 0x6f, 0x75, 0x72, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x2f, 0x6d, 0x6f, 0x62,
+	0x69, 0x6c, 0x65, 0x2d, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x65, 0x72, 0x2f, 0x61, 0x70, 0x69, 0x2f,
+	0x63, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_coverage_v1_coverage_proto_rawDescOnce sync.Once
+	file_coverage_v1_coverage_proto_rawDescData = file_coverage_v1_coverage_proto_rawDesc
+)
+
+func file_coverage_v1_coverage_proto_rawDescGZIP() []byte {
+	file_coverage_v1_coverage_proto_rawDescOnce.Do(func() {
+		file_coverage_v1_coverage_proto_rawDescData = protoimpl.X.CompressGZIP(file_coverage_v1_coverage_proto_rawDescData)
+	})
+	return file_coverage_v1_coverage_proto_rawDescData
+}
+
+var file_coverage_v1_coverage_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_coverage_v1_coverage_proto_goTypes = []interface{}{
+	(*CheckRequest)(nil),      // 0: coverage.v1.CheckRequest
+	(*CheckResponse)(nil),     // 1: coverage.v1.CheckResponse
+	(*CheckBulkRequest)(nil),  // 2: coverage.v1.CheckBulkRequest
+	(*CheckBulkResponse)(nil), // 3: coverage.v1.CheckBulkResponse
+	(*HealthRequest)(nil),     // 4: coverage.v1.HealthRequest
+	(*HealthResponse)(nil),    // 5: coverage.v1.HealthResponse
+	(*Result)(nil),            // 6: coverage.v1.Result
+	(*Geographic)(nil),        // 7: coverage.v1.Geographic
+	(*MobileSummary)(nil),     // 8: coverage.v1.MobileSummary
+	(*OperatorCoverage)(nil),  // 9: coverage.v1.OperatorCoverage
+}
+var file_coverage_v1_coverage_proto_depIdxs = []int32{
+	6, // 0: coverage.v1.CheckResponse.result:type_name -> coverage.v1.Result
+	6, // 1: coverage.v1.CheckBulkResponse.result:type_name -> coverage.v1.Result
+	7, // 2: coverage.v1.Result.geographic:type_name -> coverage.v1.Geographic
+	8, // 3: coverage.v1.Result.mobile:type_name -> coverage.v1.MobileSummary
+	9, // 4: coverage.v1.MobileSummary.operators:type_name -> coverage.v1.OperatorCoverage
+	0, // 5: coverage.v1.CoverageService.Check:input_type -> coverage.v1.CheckRequest
+	2, // 6: coverage.v1.CoverageService.CheckBulk:input_type -> coverage.v1.CheckBulkRequest
+	4, // 7: coverage.v1.CoverageService.Health:input_type -> coverage.v1.HealthRequest
+	1, // 8: coverage.v1.CoverageService.Check:output_type -> coverage.v1.CheckResponse
+	3, // 9: coverage.v1.CoverageService.CheckBulk:output_type -> coverage.v1.CheckBulkResponse
+	5, // 10: coverage.v1.CoverageService.Health:output_type -> coverage.v1.HealthResponse
+	8, // [8:11] is the sub-list for method output_type
+	5, // [5:8] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_coverage_v1_coverage_proto_init() }
+func file_coverage_v1_coverage_proto_init() {
+	if File_coverage_v1_coverage_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_coverage_v1_coverage_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CheckRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_coverage_v1_coverage_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CheckResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_coverage_v1_coverage_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CheckBulkRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_coverage_v1_coverage_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CheckBulkResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_coverage_v1_coverage_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HealthRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_coverage_v1_coverage_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HealthResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_coverage_v1_coverage_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Result); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_coverage_v1_coverage_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Geographic); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_coverage_v1_coverage_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MobileSummary); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_coverage_v1_coverage_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OperatorCoverage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_coverage_v1_coverage_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_coverage_v1_coverage_proto_goTypes,
+		DependencyIndexes: file_coverage_v1_coverage_proto_depIdxs,
+		MessageInfos:      file_coverage_v1_coverage_proto_msgTypes,
+	}.Build()
+	File_coverage_v1_coverage_proto = out.File
+	file_coverage_v1_coverage_proto_rawDesc = nil
+	file_coverage_v1_coverage_proto_goTypes = nil
+	file_coverage_v1_coverage_proto_depIdxs = nil
+}
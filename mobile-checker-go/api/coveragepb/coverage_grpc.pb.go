@@ -0,0 +1,217 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: coverage/v1/coverage.proto
+
+package coveragepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	CoverageService_Check_FullMethodName     = "/coverage.v1.CoverageService/Check"
+	CoverageService_CheckBulk_FullMethodName = "/coverage.v1.CoverageService/CheckBulk"
+	CoverageService_Health_FullMethodName    = "/coverage.v1.CoverageService/Health"
+)
+
+// CoverageServiceClient is the client API for CoverageService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CoverageServiceClient interface {
+	// Check looks up coverage for a single postcode.
+	Check(ctx context.Context, in *CheckRequest, opts ...grpc.CallOption) (*CheckResponse, error)
+	// CheckBulk streams one response per postcode in completion order.
+	CheckBulk(ctx context.Context, in *CheckBulkRequest, opts ...grpc.CallOption) (CoverageService_CheckBulkClient, error)
+	// Health reports whether the service is serving.
+	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
+}
+
+type coverageServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCoverageServiceClient(cc grpc.ClientConnInterface) CoverageServiceClient {
+	return &coverageServiceClient{cc}
+}
+
+func (c *coverageServiceClient) Check(ctx context.Context, in *CheckRequest, opts ...grpc.CallOption) (*CheckResponse, error) {
+	out := new(CheckResponse)
+	err := c.cc.Invoke(ctx, CoverageService_Check_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coverageServiceClient) CheckBulk(ctx context.Context, in *CheckBulkRequest, opts ...grpc.CallOption) (CoverageService_CheckBulkClient, error) {
+	stream, err := c.cc.NewStream(ctx, &CoverageService_ServiceDesc.Streams[0], CoverageService_CheckBulk_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &coverageServiceCheckBulkClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CoverageService_CheckBulkClient interface {
+	Recv() (*CheckBulkResponse, error)
+	grpc.ClientStream
+}
+
+type coverageServiceCheckBulkClient struct {
+	grpc.ClientStream
+}
+
+func (x *coverageServiceCheckBulkClient) Recv() (*CheckBulkResponse, error) {
+	m := new(CheckBulkResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *coverageServiceClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
+	out := new(HealthResponse)
+	err := c.cc.Invoke(ctx, CoverageService_Health_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CoverageServiceServer is the server API for CoverageService service.
+// All implementations must embed UnimplementedCoverageServiceServer
+// for forward compatibility
+type CoverageServiceServer interface {
+	// Check looks up coverage for a single postcode.
+	Check(context.Context, *CheckRequest) (*CheckResponse, error)
+	// CheckBulk streams one response per postcode in completion order.
+	CheckBulk(*CheckBulkRequest, CoverageService_CheckBulkServer) error
+	// Health reports whether the service is serving.
+	Health(context.Context, *HealthRequest) (*HealthResponse, error)
+	mustEmbedUnimplementedCoverageServiceServer()
+}
+
+// UnimplementedCoverageServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedCoverageServiceServer struct {
+}
+
+func (UnimplementedCoverageServiceServer) Check(context.Context, *CheckRequest) (*CheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Check not implemented")
+}
+func (UnimplementedCoverageServiceServer) CheckBulk(*CheckBulkRequest, CoverageService_CheckBulkServer) error {
+	return status.Errorf(codes.Unimplemented, "method CheckBulk not implemented")
+}
+func (UnimplementedCoverageServiceServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Health not implemented")
+}
+func (UnimplementedCoverageServiceServer) mustEmbedUnimplementedCoverageServiceServer() {}
+
+// UnsafeCoverageServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CoverageServiceServer will
+// result in compilation errors.
+type UnsafeCoverageServiceServer interface {
+	mustEmbedUnimplementedCoverageServiceServer()
+}
+
+func RegisterCoverageServiceServer(s grpc.ServiceRegistrar, srv CoverageServiceServer) {
+	s.RegisterService(&CoverageService_ServiceDesc, srv)
+}
+
+func _CoverageService_Check_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoverageServiceServer).Check(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoverageService_Check_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoverageServiceServer).Check(ctx, req.(*CheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoverageService_CheckBulk_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CheckBulkRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CoverageServiceServer).CheckBulk(m, &coverageServiceCheckBulkServer{stream})
+}
+
+type CoverageService_CheckBulkServer interface {
+	Send(*CheckBulkResponse) error
+	grpc.ServerStream
+}
+
+type coverageServiceCheckBulkServer struct {
+	grpc.ServerStream
+}
+
+func (x *coverageServiceCheckBulkServer) Send(m *CheckBulkResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _CoverageService_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoverageServiceServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoverageService_Health_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoverageServiceServer).Health(ctx, req.(*HealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CoverageService_ServiceDesc is the grpc.ServiceDesc for CoverageService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CoverageService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "coverage.v1.CoverageService",
+	HandlerType: (*CoverageServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Check",
+			Handler:    _CoverageService_Check_Handler,
+		},
+		{
+			MethodName: "Health",
+			Handler:    _CoverageService_Health_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "CheckBulk",
+			Handler:       _CoverageService_CheckBulk_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "coverage/v1/coverage.proto",
+}
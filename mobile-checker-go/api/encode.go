@@ -0,0 +1,292 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/logging"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+// Response formats for /api/mobile/* endpoints, chosen by negotiate.
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+	formatXML  = "xml"
+)
+
+var formatContentTypes = map[string]string{
+	formatJSON: "application/json",
+	formatCSV:  "text/csv; charset=utf-8",
+	formatXML:  "application/xml; charset=utf-8",
+}
+
+// acceptFormats maps Accept media types to formats. Wildcards get JSON.
+var acceptFormats = map[string]string{
+	"application/json": formatJSON,
+	"application/*":    formatJSON,
+	"*/*":              formatJSON,
+	"text/csv":         formatCSV,
+	"application/xml":  formatXML,
+	"text/xml":         formatXML,
+}
+
+var errNotAcceptable = errors.New("no acceptable format: use application/json, text/csv or application/xml")
+
+// envelope is the body of every negotiated response. In XML the status,
+// code and schema version are attributes of <response>; nested elements are
+// named as in JSON.
+type envelope struct {
+	XMLName xml.Name `json:"-" xml:"response"`
+	Status  string   `json:"status" xml:"status,attr"`
+	Code    string   `json:"code,omitempty" xml:"code,attr,omitempty"`
+	// SchemaVersion is checker.SchemaVersion, set by respond.
+	SchemaVersion int `json:"schema_version" xml:"schema_version,attr"`
+	// Dataset is the release the request was answered from, set by
+	// respond; absent when no dataset is installed.
+	Dataset *ofcom.Release   `json:"dataset,omitempty" xml:"dataset,omitempty"`
+	Message string           `json:"message,omitempty" xml:"message,omitempty"`
+	Result  any              `json:"result,omitempty" xml:"result,omitempty"`
+	Results []checker.Result `json:"results,omitempty" xml:"results>result,omitempty"`
+	// Summary counts bulk Results by status.
+	Summary *checker.Summary `json:"summary,omitempty" xml:"summary,omitempty"`
+}
+
+// negotiate picks the response format: ?format= if given, otherwise the
+// most preferred supported type in the Accept header, otherwise JSON.
+func negotiate(r *http.Request) (string, error) {
+	if f := strings.ToLower(r.URL.Query().Get("format")); f != "" {
+		if _, ok := formatContentTypes[f]; !ok {
+			return "", errors.New("format must be json, csv or xml")
+		}
+		return f, nil
+	}
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return formatJSON, nil
+	}
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		// Earlier types win ties, as clients list them in preference order.
+		if f, ok := acceptFormats[mt]; ok && q > bestQ {
+			best, bestQ = f, q
+		}
+	}
+	if best == "" {
+		return "", errNotAcceptable
+	}
+	return best, nil
+}
+
+// respond writes body in the format negotiated for r, stamped with the
+// schema version and the dataset release. JSON results are cut down to
+// the ?fields= given.
+func (s *Server) respond(w http.ResponseWriter, r *http.Request, status int, body envelope) {
+	w.Header().Add("Vary", "Accept")
+	format, err := negotiate(r)
+	if err != nil {
+		code := http.StatusNotAcceptable
+		if r.URL.Query().Get("format") != "" {
+			code = http.StatusBadRequest
+		}
+		writeError(w, code, err.Error())
+		return
+	}
+	fields, err := parseFields(r)
+	if err == nil && fields != nil && format != formatJSON {
+		err = errors.New("fields is only supported for JSON responses")
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	body.SchemaVersion = checker.SchemaVersion
+	if body.Dataset == nil {
+		body.Dataset = s.datasetFor(r)
+	}
+	var sparse any
+	if fields != nil {
+		if sparse, err = fields.envelope(body); err != nil {
+			logging.FromContext(r.Context(), slog.Default()).Error("selecting fields failed", "error", err)
+			writeError(w, http.StatusInternalServerError, "selecting fields failed")
+			return
+		}
+	}
+	w.Header().Set("Content-Type", formatContentTypes[format])
+	switch format {
+	case formatCSV:
+		w.WriteHeader(status)
+		err = csv.NewWriter(w).WriteAll(csvRows(body))
+	case formatXML:
+		w.WriteHeader(status)
+		w.Write([]byte(xml.Header))
+		enc := xml.NewEncoder(w)
+		enc.Indent("", "  ")
+		if err = enc.Encode(body); err == nil {
+			w.Write([]byte("\n"))
+		}
+	default:
+		if sparse != nil {
+			writeJSON(w, status, sparse)
+		} else {
+			writeJSON(w, status, body)
+		}
+	}
+	// The status line has gone, so all that is left is to log the failure.
+	if err != nil {
+		logging.FromContext(r.Context(), slog.Default()).Warn("writing response failed", "format", format, "error", err)
+	}
+}
+
+// datasetFor returns the release answering r: that of ?year= when given,
+// otherwise the current one. It is nil when that dataset is not installed.
+func (s *Server) datasetFor(r *http.Request) *ofcom.Release {
+	c := s.checker
+	if year := r.URL.Query().Get("year"); year != "" {
+		yc, err := c.ForYear(year)
+		if err != nil {
+			return nil
+		}
+		c = yc
+	}
+	rel, err := c.Release()
+	if err != nil {
+		return nil
+	}
+	return rel
+}
+
+// respondError is writeError in the negotiated format.
+func (s *Server) respondError(w http.ResponseWriter, r *http.Request, status int, msg string) {
+	code := strings.ToUpper(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+	s.respond(w, r, status, envelope{Status: "error", Code: code, Message: msg})
+}
+
+// respondCodedError is writeCodedError in the negotiated format.
+func (s *Server) respondCodedError(w http.ResponseWriter, r *http.Request, code checker.ErrorCode, msg string) {
+	s.respond(w, r, httpStatus(code), envelope{Status: "error", Code: string(code), Message: msg})
+}
+
+// csvRows flattens body into a header row and one row per operator (per
+// operator and technology for diffs). Bodies without a tabular result
+// become a status, code and message row.
+func csvRows(body envelope) [][]string {
+	switch v := body.Result.(type) {
+	case checker.Result:
+		return checkRows([]checker.Result{v})
+	case *ofcom.CoverageDiff:
+		return diffRows(v)
+	case *ofcom.AreaSummary:
+		return areaRows(v)
+	case *ofcom.WithinResult:
+		return withinRows(v)
+	}
+	if body.Results != nil {
+		return checkRows(body.Results)
+	}
+	return [][]string{{"status", "code", "message"}, {body.Status, body.Code, body.Message}}
+}
+
+// checkRows has a row per result and operator, led by a site column when
+// the results are saved sites.
+func checkRows(results []checker.Result) [][]string {
+	header := []string{"postcode", "valid", "country", "region", "district", "constituency", "latitude", "longitude",
+		"operator", "voice", "4g", "5g", "tier", "score", "grade", "code", "message"}
+	sites, statuses := false, false
+	for _, res := range results {
+		sites = sites || res.Site != ""
+		statuses = statuses || res.Status != ""
+	}
+	if statuses {
+		header = append(header[:len(header)-2:len(header)-2], "status", "code", "message")
+	}
+	if sites {
+		header = append([]string{"site"}, header...)
+	}
+	rows := [][]string{header}
+	for _, res := range results {
+		place := make([]string, 6)
+		if g := res.Geographic; g != nil {
+			place = []string{g.Country, g.Region, g.AdminDistrict, g.ParliamentaryConstituency, ftoa(g.Latitude), ftoa(g.Longitude)}
+		}
+		msg := res.Error
+		if msg == "" {
+			msg = res.Note
+		}
+		row := func(op []string, score []string) []string {
+			r := []string{res.Postcode, strconv.FormatBool(res.Valid)}
+			if sites {
+				r = append([]string{res.Site}, r...)
+			}
+			r = append(r, place...)
+			r = append(r, op...)
+			r = append(r, score...)
+			if statuses {
+				r = append(r, string(res.Status))
+			}
+			return append(r, string(res.Code), msg)
+		}
+		if res.Mobile == nil {
+			rows = append(rows, row(make([]string, 5), make([]string, 2)))
+			continue
+		}
+		score := []string{strconv.Itoa(res.Mobile.CoverageScore), res.Mobile.Grade}
+		for _, op := range res.Mobile.Operators {
+			rows = append(rows, row([]string{op.Label(), op.Voice, op.FourG, op.FiveG, string(op.Tier)}, score))
+		}
+	}
+	return rows
+}
+
+func diffRows(d *ofcom.CoverageDiff) [][]string {
+	rows := [][]string{{"postcode", "from", "to", "indoor", "operator", "technology", "from_pct", "to_pct", "change_pp", "gained", "lost"}}
+	for _, op := range d.Operators {
+		for _, t := range op.Technologies {
+			rows = append(rows, []string{d.Postcode, d.From, d.To, strconv.FormatBool(d.Indoor), op.Name, t.Technology,
+				ftoa(t.FromPct), ftoa(t.ToPct), ftoa(t.ChangePP), strconv.FormatBool(t.Gained), strconv.FormatBool(t.Lost)})
+		}
+	}
+	return rows
+}
+
+func withinRows(res *ofcom.WithinResult) [][]string {
+	rows := [][]string{{"postcode", "distance_km", "latitude", "longitude", "column", "coverage_pct", "covered"}}
+	for _, p := range res.Postcodes {
+		rows = append(rows, []string{p.Postcode, ftoa(p.DistanceKm), ftoa(p.Latitude), ftoa(p.Longitude),
+			res.Column, ftoa(p.CoveragePct), strconv.FormatBool(p.Covered)})
+	}
+	return rows
+}
+
+func areaRows(a *ofcom.AreaSummary) [][]string {
+	rows := [][]string{{"level", "name", "postcodes", "operator", "mean_voice_pct", "mean_4g_pct", "mean_5g_pct",
+		"voice_covered_pct", "4g_covered_pct", "5g_covered_pct", "all_operators_4g_pct", "any_operator_5g_pct"}}
+	for _, op := range a.Operators {
+		rows = append(rows, []string{a.Level, a.Name, strconv.Itoa(a.Postcodes), op.Name,
+			ftoa(op.MeanVoice), ftoa(op.MeanFourG), ftoa(op.MeanFiveG), ftoa(op.PctVoice), ftoa(op.PctFourG), ftoa(op.PctFiveG),
+			ftoa(a.AllFourG), ftoa(a.AnyFiveG)})
+	}
+	return rows
+}
+
+func ftoa(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,199 @@
+package api_test
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/api"
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func newAreaHandler(t *testing.T) http.Handler {
+	t.Helper()
+	places := []ofcom.Place{
+		{Postcode: "LS11AA", AdminDistrict: "Leeds"},
+		{Postcode: "LS11AB", AdminDistrict: "Leeds"},
+	}
+	return api.NewServer(newDataDir(t, places), quietLogger()).Handler()
+}
+
+func TestNegotiate(t *testing.T) {
+	h := newAreaHandler(t)
+	for _, tc := range []struct {
+		name        string
+		target      string
+		accept      string
+		status      int
+		contentType string
+	}{
+		{"default", "/api/mobile/district/Leeds", "", http.StatusOK, "application/json"},
+		{"wildcard", "/api/mobile/district/Leeds", "*/*", http.StatusOK, "application/json"},
+		{"csv", "/api/mobile/district/Leeds", "text/csv", http.StatusOK, "text/csv; charset=utf-8"},
+		{"xml", "/api/mobile/district/Leeds", "text/xml", http.StatusOK, "application/xml; charset=utf-8"},
+		{"q-values", "/api/mobile/district/Leeds", "application/json;q=0.5, text/csv;q=0.9", http.StatusOK, "text/csv; charset=utf-8"},
+		{"unsupported skipped", "/api/mobile/district/Leeds", "text/html, application/xml;q=0.1", http.StatusOK, "application/xml; charset=utf-8"},
+		{"q=0 refused", "/api/mobile/district/Leeds", "text/csv;q=0", http.StatusNotAcceptable, "application/json"},
+		{"not acceptable", "/api/mobile/district/Leeds", "text/html", http.StatusNotAcceptable, "application/json"},
+		{"format beats accept", "/api/mobile/district/Leeds?format=xml", "text/csv", http.StatusOK, "application/xml; charset=utf-8"},
+		{"bad format", "/api/mobile/district/Leeds?format=yaml", "", http.StatusBadRequest, "application/json"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var header []string
+			if tc.accept != "" {
+				header = []string{"Accept", tc.accept}
+			}
+			resp := get(t, h, tc.target, header...)
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected %d, got %d", tc.status, resp.StatusCode)
+			}
+			if got := resp.Header.Get("Content-Type"); got != tc.contentType {
+				t.Errorf("expected Content-Type %q, got %q", tc.contentType, got)
+			}
+			if !strings.Contains(strings.Join(resp.Header.Values("Vary"), ","), "Accept") {
+				t.Error("expected Vary: Accept")
+			}
+		})
+	}
+}
+
+func TestRespond_CSV(t *testing.T) {
+	resp := get(t, newAreaHandler(t), "/api/mobile/district/Leeds?format=csv")
+	rows, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(rows) != 5 {
+		t.Fatalf("expected a header and four operator rows, got %d rows", len(rows))
+	}
+	if want := "level,name,postcodes,operator,mean_voice_pct,mean_4g_pct,mean_5g_pct,voice_covered_pct,4g_covered_pct,5g_covered_pct,all_operators_4g_pct,any_operator_5g_pct"; strings.Join(rows[0], ",") != want {
+		t.Errorf("unexpected header %q", strings.Join(rows[0], ","))
+	}
+	if ee := rows[1]; ee[0] != "district" || ee[1] != "Leeds" || ee[2] != "2" || ee[3] != "EE" || ee[5] != "60" || ee[8] != "50" {
+		t.Errorf("unexpected EE row %q", ee)
+	}
+
+	// Errors without a tabular result become a status, code and message row.
+	resp = get(t, newAreaHandler(t), "/api/mobile/district/Atlantis", "Accept", "text/csv")
+	rows, err = csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(rows) != 2 || rows[1][0] != "error" || rows[1][1] != "NOT_FOUND" {
+		t.Errorf("expected an error row, got %q", rows)
+	}
+}
+
+func TestRespond_XML(t *testing.T) {
+	resp := get(t, newAreaHandler(t), "/api/mobile/district/Leeds", "Accept", "application/xml")
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(raw), xml.Header) {
+		t.Errorf("expected an XML declaration, got %.40q", raw)
+	}
+	var body struct {
+		Status string `xml:"status,attr"`
+		Result struct {
+			Name      string `xml:"name"`
+			Postcodes int    `xml:"postcodes"`
+			Operators []struct {
+				Name      string  `xml:"name"`
+				MeanFourG float64 `xml:"mean_4g_pct"`
+				PctFourG  float64 `xml:"covered_4g_pct"`
+			} `xml:"operators>operator"`
+		} `xml:"result"`
+	}
+	if err := xml.Unmarshal(raw, &body); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, raw)
+	}
+	if body.Status != "ok" || body.Result.Name != "Leeds" || body.Result.Postcodes != 2 {
+		t.Errorf("unexpected response %+v", body)
+	}
+	if ops := body.Result.Operators; len(ops) != 4 || ops[0].Name != "EE" || ops[0].MeanFourG != 60 || ops[0].PctFourG != 50 {
+		t.Errorf("unexpected operators %+v", ops)
+	}
+
+	resp = get(t, newAreaHandler(t), "/api/mobile/district/Atlantis?format=xml")
+	var errBody struct {
+		XMLName xml.Name `xml:"response"`
+		Status  string   `xml:"status,attr"`
+		Code    string   `xml:"code,attr"`
+		Message string   `xml:"message"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&errBody); err != nil {
+		t.Fatalf("invalid XML: %v", err)
+	}
+	if errBody.Status != "error" || errBody.Code != "NOT_FOUND" || errBody.Message == "" {
+		t.Errorf("unexpected error response %+v", errBody)
+	}
+}
+
+func TestRespond_SchemaVersionAndDataset(t *testing.T) {
+	h := newAreaHandler(t)
+	body := decode(t, get(t, h, "/api/mobile/district/Leeds"))
+	if body["schema_version"] != float64(checker.SchemaVersion) {
+		t.Errorf("expected schema_version %d, got %v", checker.SchemaVersion, body["schema_version"])
+	}
+	ds, _ := body["dataset"].(map[string]any)
+	if ds["year"] != "2023" || ds["built_at"] == nil {
+		t.Errorf("expected the 2023 dataset and its build time, got %v", body["dataset"])
+	}
+
+	// Errors carry the version too, so clients can tell layouts apart
+	// before looking any further.
+	body = decode(t, get(t, h, "/api/mobile/district/Atlantis"))
+	if body["status"] != "error" || body["schema_version"] != float64(checker.SchemaVersion) {
+		t.Errorf("expected a versioned error, got %v", body)
+	}
+	body = decode(t, get(t, h, "/healthz"))
+	if body["schema_version"] != float64(checker.SchemaVersion) {
+		t.Errorf("expected a versioned health check, got %v", body)
+	}
+
+	resp := get(t, h, "/api/mobile/district/Leeds?format=xml")
+	var x struct {
+		SchemaVersion int `xml:"schema_version,attr"`
+		Dataset       struct {
+			Year string `xml:"year"`
+		} `xml:"dataset"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&x); err != nil {
+		t.Fatalf("invalid XML: %v", err)
+	}
+	if x.SchemaVersion != checker.SchemaVersion || x.Dataset.Year != "2023" {
+		t.Errorf("unexpected XML version and dataset %+v", x)
+	}
+}
+
+func TestRespond_Fields(t *testing.T) {
+	fixtures := checker.Fixtures{"SW1A1AA": {Postcode: "SW1A1AA", Valid: true, Mobile: &ofcom.MobileSummary{
+		CoverageScore: 90,
+		Operators:     []ofcom.OperatorCoverage{{Name: "EE", FiveG: "80%"}, {Name: "O2", FiveG: "0%"}},
+	}}}
+	h := api.NewServer(t.TempDir(), quietLogger(), api.WithFixtures(fixtures)).Handler()
+
+	body := decode(t, get(t, h, "/api/mobile/SW1A1AA?fields=postcode,mobile.operators.name,mobile.operators.five_g"))
+	if body["status"] != "ok" || body["schema_version"] == nil {
+		t.Errorf("expected the envelope to be kept, got %v", body)
+	}
+	result := body["result"].(map[string]any)
+	mobile := result["mobile"].(map[string]any)
+	want := `map[mobile:map[Operators:[map[FiveG:80% Name:EE] map[FiveG:0% Name:O2]]] postcode:SW1A1AA]`
+	if got := fmt.Sprint(result); got != want || mobile["CoverageScore"] != nil {
+		t.Errorf("expected only the selected fields, got %s", got)
+	}
+
+	if resp := get(t, h, "/api/mobile/SW1A1AA?fields=mobile..name"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for an empty path segment, got %d", resp.StatusCode)
+	}
+	if resp := get(t, h, "/api/mobile/SW1A1AA?fields=postcode&format=csv"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for fields with CSV, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,16 @@
+package api
+
+import "crypto/tls"
+
+// Exported for tests in package api_test.
+var (
+	Compress        = compress
+	AcceptEncoding  = acceptEncoding
+	CompressMinSize = compressMinSize
+)
+
+// CertificateLoader returns the GetCertificate function used for
+// TLSConfig.CertFile and KeyFile.
+func CertificateLoader(certFile, keyFile string) func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return (&certReloader{certFile: certFile, keyFile: keyFile}).get
+}
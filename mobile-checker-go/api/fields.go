@@ -0,0 +1,138 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// maxFields caps the paths in a ?fields= list.
+const maxFields = 64
+
+// fieldSet is a parsed ?fields= list of dotted paths into a result, e.g.
+// mobile.operators.five_g. Names match JSON fields without regard to case
+// or underscores, so five_g selects FiveG; arrays are passed through, so
+// mobile.operators.name selects every operator's name.
+type fieldSet [][]string
+
+// parseFields parses r's ?fields=, returning nil when it is absent.
+func parseFields(r *http.Request) (fieldSet, error) {
+	q := r.URL.Query().Get("fields")
+	if q == "" {
+		return nil, nil
+	}
+	var f fieldSet
+	for _, p := range strings.Split(q, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		path := strings.Split(p, ".")
+		for i, name := range path {
+			if path[i] = fieldKey(name); path[i] == "" {
+				return nil, fmt.Errorf("invalid field %q", p)
+			}
+		}
+		f = append(f, path)
+	}
+	if len(f) == 0 || len(f) > maxFields {
+		return nil, fmt.Errorf("fields must list between 1 and %d comma-separated paths", maxFields)
+	}
+	return f, nil
+}
+
+// fieldKey normalises a field name for matching.
+func fieldKey(name string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "_", ""))
+}
+
+// envelope returns body as JSON with only the selected fields of its
+// results: each of Results when it has them, as bulk and job pages do,
+// otherwise Result. The status, schema version, dataset and paging
+// fields are always kept.
+func (f fieldSet) envelope(body envelope) (any, error) {
+	v, err := toJSONValue(body)
+	if err != nil {
+		return nil, err
+	}
+	m := v.(map[string]any)
+	if results, ok := m["results"]; ok {
+		m["results"] = f.prune(results)
+	} else if result, ok := m["result"]; ok {
+		m["result"] = f.prune(result)
+	}
+	return m, nil
+}
+
+// apply returns v as JSON with only the selected fields and the
+// top-level keys in keep, e.g. an NDJSON line's index.
+func (f fieldSet) apply(v any, keep ...string) (any, error) {
+	j, err := toJSONValue(v)
+	if err != nil {
+		return nil, err
+	}
+	out := f.prune(j)
+	if m, ok := j.(map[string]any); ok {
+		pruned, _ := out.(map[string]any)
+		for _, k := range keep {
+			if val, ok := m[k]; ok {
+				pruned[k] = val
+			}
+		}
+	}
+	return out, nil
+}
+
+// prune keeps the parts of v on f's paths.
+func (f fieldSet) prune(v any) any {
+	switch v := v.(type) {
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = f.prune(e)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any)
+		for k, val := range v {
+			var rest fieldSet
+			whole := false
+			for _, p := range f {
+				if p[0] != fieldKey(k) {
+					continue
+				}
+				if len(p) == 1 {
+					whole = true
+					break
+				}
+				rest = append(rest, p[1:])
+			}
+			switch {
+			case whole:
+				out[k] = val
+			case rest != nil:
+				out[k] = rest.prune(val)
+			}
+		}
+		return out
+	}
+	// A path reaching past a plain value selects the value.
+	return v
+}
+
+// toJSONValue round-trips v through JSON, keeping numbers exact.
+func toJSONValue(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var out any
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/yourusername/mobile-checker/api/coveragepb"
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+// grpcService implements coveragepb.CoverageServiceServer on top of a Server's
+// checker.
+type grpcService struct {
+	coveragepb.UnimplementedCoverageServiceServer
+	s *Server
+}
+
+// GRPCServer returns a gRPC server with CoverageService registered.
+func (s *Server) GRPCServer() *grpc.Server {
+	g := grpc.NewServer()
+	coveragepb.RegisterCoverageServiceServer(g, &grpcService{s: s})
+	return g
+}
+
+// ServeGRPC starts the gRPC server on addr.
+func (s *Server) ServeGRPC(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.logger.Info("UK Mobile Coverage gRPC API listening", "addr", addr, "service", "coverage.v1.CoverageService")
+	gs := s.GRPCServer()
+	s.mu.Lock()
+	s.grpcServer = gs
+	s.mu.Unlock()
+	if err := gs.Serve(lis); !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+func (g *grpcService) Check(ctx context.Context, req *coveragepb.CheckRequest) (*coveragepb.CheckResponse, error) {
+	if req.GetPostcode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "postcode required")
+	}
+	result := g.s.checker.CheckContext(ctx, req.GetPostcode(), checker.CheckOptions{Indoor: req.GetIndoor(), Weights: g.s.weights, Threshold: g.s.threshold})
+	if result.Error != "" {
+		return nil, status.Error(grpcCode(result.Code), result.Error)
+	}
+	return &coveragepb.CheckResponse{Result: toProto(result)}, nil
+}
+
+func (g *grpcService) CheckBulk(req *coveragepb.CheckBulkRequest, stream coveragepb.CoverageService_CheckBulkServer) error {
+	pcs := req.GetPostcodes()
+	if len(pcs) == 0 || len(pcs) > maxStreamPostcodes {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("provide between 1 and %d postcodes", maxStreamPostcodes))
+	}
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	opts := checker.CheckOptions{Indoor: req.GetIndoor(), Weights: g.s.weights, Threshold: g.s.threshold}
+	for res := range g.s.checker.StreamBulk(ctx, pcs, opts, g.s.bulk) {
+		if err := stream.Send(&coveragepb.CheckBulkResponse{Index: int32(res.Index), Result: toProto(res.Result)}); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+func (g *grpcService) Health(context.Context, *coveragepb.HealthRequest) (*coveragepb.HealthResponse, error) {
+	return &coveragepb.HealthResponse{Status: "ok", Service: "UK Mobile Coverage API"}, nil
+}
+
+// grpcCode maps a check failure to a gRPC status code.
+func grpcCode(code checker.ErrorCode) codes.Code {
+	switch code {
+	case checker.CodeInvalidPostcode:
+		return codes.InvalidArgument
+	case checker.CodePostcodeNotFound, checker.CodeNotInDataset, checker.CodePostcodeTerminated, checker.CodeNationNotInstalled:
+		return codes.NotFound
+	case checker.CodeDatasetMissing, checker.CodeDatasetOutdated:
+		return codes.FailedPrecondition
+	case checker.CodeUpstreamTimeout:
+		return codes.DeadlineExceeded
+	case checker.CodeUpstreamUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
+
+func toProto(r checker.Result) *coveragepb.Result {
+	out := &coveragepb.Result{
+		Postcode: r.Postcode,
+		Valid:    r.Valid,
+		Error:    r.Error,
+		Note:     r.Note,
+		Code:     string(r.Code),
+	}
+	if g := r.Geographic; g != nil {
+		out.Geographic = &coveragepb.Geographic{
+			Country:                   g.Country,
+			Region:                    g.Region,
+			AdminDistrict:             g.AdminDistrict,
+			ParliamentaryConstituency: g.ParliamentaryConstituency,
+			Latitude:                  g.Latitude,
+			Longitude:                 g.Longitude,
+		}
+	}
+	if m := r.Mobile; m != nil {
+		out.Mobile = mobileToProto(*m)
+	}
+	return out
+}
+
+func mobileToProto(m ofcom.MobileSummary) *coveragepb.MobileSummary {
+	out := &coveragepb.MobileSummary{
+		Indoor:        m.Indoor,
+		AnyOperator:   m.Overall.AnyOperator,
+		FourGCount:    int32(m.Overall.FourGCount),
+		FiveGCount:    int32(m.Overall.FiveGCount),
+		CoverageScore: int32(m.CoverageScore),
+		Grade:         m.Grade,
+	}
+	for _, op := range m.Operators {
+		out.Operators = append(out.Operators, &coveragepb.OperatorCoverage{
+			Name:     op.Name,
+			Voice:    op.Voice,
+			FourG:    op.FourG,
+			FiveG:    op.FiveG,
+			HasVoice: op.HasVoice,
+			HasFourG: op.HasFourG,
+			HasFiveG: op.HasFiveG,
+		})
+	}
+	return out
+}
@@ -0,0 +1,105 @@
+package api_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/yourusername/mobile-checker/api"
+	"github.com/yourusername/mobile-checker/api/coveragepb"
+)
+
+// dialGRPC serves s's gRPC API over an in-memory connection and returns a
+// client for it.
+func dialGRPC(t *testing.T, s *api.Server) coveragepb.CoverageServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	g := s.GRPCServer()
+	go g.Serve(lis)
+	t.Cleanup(g.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return coveragepb.NewCoverageServiceClient(conn)
+}
+
+func TestGRPC_Check(t *testing.T) {
+	// Offline without geographic data, checks use the dataset alone.
+	client := dialGRPC(t, api.NewServer(newDataDir(t, nil), api.WithOffline(), quietLogger()))
+
+	resp, err := client.Check(context.Background(), &coveragepb.CheckRequest{Postcode: "ls1 1aa"})
+	if err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+	r := resp.GetResult()
+	if r.GetPostcode() != "LS11AA" || r.GetMobile() == nil {
+		t.Fatalf("expected coverage for LS11AA, got %+v", r)
+	}
+	if got := r.GetMobile().GetFourGCount(); got != 4 {
+		t.Errorf("expected 4 operators with 4G, got %d", got)
+	}
+	if ee := r.GetMobile().GetOperators()[0]; ee.GetName() != "EE" || !ee.GetHasFiveG() {
+		t.Errorf("expected EE with 5G first, got %+v", ee)
+	}
+}
+
+func TestGRPC_ErrorStatus(t *testing.T) {
+	client := dialGRPC(t, api.NewServer(newDataDir(t, nil), api.WithOffline(), quietLogger()))
+	missing := dialGRPC(t, api.NewServer(t.TempDir(), api.WithOffline(), quietLogger()))
+
+	for _, tc := range []struct {
+		name     string
+		client   coveragepb.CoverageServiceClient
+		postcode string
+		want     codes.Code
+	}{
+		{"empty", client, "", codes.InvalidArgument},
+		{"invalid postcode", client, "HELLO", codes.InvalidArgument},
+		{"offline without data", missing, "LS11AA", codes.Unavailable},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.client.Check(context.Background(), &coveragepb.CheckRequest{Postcode: tc.postcode})
+			if got := status.Code(err); got != tc.want {
+				t.Errorf("expected %v, got %v (%v)", tc.want, got, err)
+			}
+		})
+	}
+}
+
+func TestGRPC_CheckBulkStreamsEachPostcode(t *testing.T) {
+	client := dialGRPC(t, api.NewServer(newDataDir(t, nil), api.WithOffline(), quietLogger()))
+
+	stream, err := client.CheckBulk(context.Background(), &coveragepb.CheckBulkRequest{Postcodes: []string{"LS11AA", "HELLO", "LS11AB"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[int32]*coveragepb.Result)
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("stream failed: %v", err)
+		}
+		got[resp.GetIndex()] = resp.GetResult()
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(got))
+	}
+	if got[1].GetCode() != "INVALID_POSTCODE" || got[0].GetMobile() == nil || got[2].GetMobile() == nil {
+		t.Errorf("unexpected results: %v", got)
+	}
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/yourusername/mobile-checker/internal/logging"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+const (
+	defaultHeatmapCells = 32
+	maxHeatmapCells     = 256
+	heatmapTileSize     = 256
+)
+
+// GET /api/mobile/heatmap?bbox=minLon,minLat,maxLon,maxLat&operator=ee&tech=4g
+// Optional: indoor=true, cells=N (grid is N×N), format=geojson|png.
+func (s *Server) handleHeatmap(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	box, err := ofcom.ParseBBox(q.Get("bbox"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	col, err := ofcom.CoverageColumn(q.Get("operator"), q.Get("tech"), q.Get("indoor") == "true")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	cells := defaultHeatmapCells
+	if v := q.Get("cells"); v != "" {
+		if cells, err = strconv.Atoi(v); err != nil || cells < 1 || cells > maxHeatmapCells {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("cells must be between 1 and %d", maxHeatmapCells))
+			return
+		}
+	}
+	format := q.Get("format")
+	if format == "" {
+		format = "geojson"
+	}
+	if format != "geojson" && format != "png" {
+		writeError(w, http.StatusBadRequest, "format must be geojson or png")
+		return
+	}
+
+	grid, err := s.checkerFor(r).Heatmap(box, col, cells, cells)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	// The status line has gone by the time encoding fails, usually because
+	// the client hung up, so the error can only be logged.
+	if format == "png" {
+		w.Header().Set("Content-Type", "image/png")
+		err = png.Encode(w, renderHeatmap(grid, heatmapTileSize))
+	} else {
+		w.Header().Set("Content-Type", "application/geo+json")
+		err = json.NewEncoder(w).Encode(heatmapGeoJSON(grid))
+	}
+	if err != nil {
+		logging.FromContext(r.Context(), s.logger).Warn("writing heatmap failed", "format", format, "error", err)
+	}
+}
+
+// heatmapGeoJSON returns a FeatureCollection with one polygon per non-empty
+// grid cell, carrying its mean coverage (0–1) and postcode count.
+func heatmapGeoJSON(g *ofcom.Grid) map[string]any {
+	dx := (g.BBox.MaxLon - g.BBox.MinLon) / float64(g.Cols)
+	dy := (g.BBox.MaxLat - g.BBox.MinLat) / float64(g.Rows)
+	features := []any{}
+	for y := 0; y < g.Rows; y++ {
+		for x := 0; x < g.Cols; x++ {
+			c := g.Cell(x, y)
+			if c.Postcodes == 0 {
+				continue
+			}
+			w, s := g.BBox.MinLon+float64(x)*dx, g.BBox.MinLat+float64(y)*dy
+			e, n := w+dx, s+dy
+			features = append(features, map[string]any{
+				"type": "Feature",
+				"geometry": map[string]any{
+					"type":        "Polygon",
+					"coordinates": [][][2]float64{{{w, s}, {e, s}, {e, n}, {w, n}, {w, s}}},
+				},
+				"properties": map[string]any{
+					"coverage":  math.Round(c.Mean*1000) / 1000,
+					"postcodes": c.Postcodes,
+				},
+			})
+		}
+	}
+	return map[string]any{
+		"type":     "FeatureCollection",
+		"bbox":     []float64{g.BBox.MinLon, g.BBox.MinLat, g.BBox.MaxLon, g.BBox.MaxLat},
+		"column":   g.Column,
+		"features": features,
+	}
+}
+
+// renderHeatmap draws the grid as a size × size image, north up, shading
+// each cell from red (no coverage) to green (full coverage). Empty cells
+// are transparent.
+func renderHeatmap(g *ofcom.Grid, size int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for py := 0; py < size; py++ {
+		y := (size - 1 - py) * g.Rows / size
+		for px := 0; px < size; px++ {
+			c := g.Cell(px*g.Cols/size, y)
+			if c.Postcodes == 0 {
+				continue
+			}
+			v := math.Max(0, math.Min(1, c.Mean))
+			img.SetNRGBA(px, py, color.NRGBA{
+				R: uint8(255 * (1 - v)),
+				G: uint8(200 * v),
+				B: 0,
+				A: 170,
+			})
+		}
+	}
+	return img
+}
@@ -0,0 +1,521 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/jobqueue"
+	"github.com/yourusername/mobile-checker/internal/logging"
+)
+
+const (
+	// maxJobPostcodes caps a single bulk job.
+	maxJobPostcodes = 100000
+	// maxPendingJobs caps the jobs queued or running at once; more are
+	// refused until one finishes.
+	maxPendingJobs = 10
+	// jobSlots is the number of jobs run at once, each with the server's
+	// bulk worker pool.
+	jobSlots = 2
+	// DefaultJobRetention is how long a finished job's results are kept.
+	DefaultJobRetention = time.Hour
+	defaultJobPageSize  = 1000
+	maxJobPageSize      = 10000
+	// maxListedJobs caps GET /api/jobs.
+	maxListedJobs = 100
+	// jobFlushSize and jobFlushInterval bound the results a running job
+	// holds before saving them, and so how far its progress lags.
+	jobFlushSize     = 500
+	jobFlushInterval = time.Second
+	// jobPruneInterval is how often expired jobs are deleted from jobs.db.
+	jobPruneInterval = 5 * time.Minute
+)
+
+// WithJobRetention sets how long finished bulk jobs and their results are
+// kept; 0 means DefaultJobRetention.
+func WithJobRetention(d time.Duration) Option {
+	return func(s *Server) { s.jobs.retention = d }
+}
+
+// jobStore runs the server's bulk jobs, which are kept in jobs.db so that
+// jobs in progress are resumed after a restart.
+type jobStore struct {
+	retention time.Duration
+	db        *jobqueue.Store
+	logger    *slog.Logger
+
+	mu sync.Mutex
+	// running cancels the jobs queued or running in this process.
+	running map[string]context.CancelFunc
+	slots   chan struct{}
+	wg      sync.WaitGroup
+	// ctx is cancelled by Server.Close, stopping every job where it is so
+	// that it resumes on the next start.
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (st *jobStore) init(dataDir string, logger *slog.Logger) {
+	if st.retention <= 0 {
+		st.retention = DefaultJobRetention
+	}
+	st.db = jobqueue.New(dataDir)
+	st.logger = logger
+	st.running = make(map[string]context.CancelFunc)
+	st.slots = make(chan struct{}, jobSlots)
+	st.ctx, st.cancel = context.WithCancel(context.Background())
+	st.wg.Add(1)
+	go st.pruneLoop()
+}
+
+// close stops every job, waits for them to save their progress and closes
+// jobs.db.
+func (st *jobStore) close() error {
+	st.cancel()
+	st.wg.Wait()
+	return st.db.Close()
+}
+
+// pruneLoop deletes expired jobs until the store is closed.
+func (st *jobStore) pruneLoop() {
+	defer st.wg.Done()
+	t := time.NewTicker(jobPruneInterval)
+	defer t.Stop()
+	for {
+		if n, err := st.db.Prune(time.Now().Add(-st.retention)); err != nil {
+			st.logger.Warn("failed to prune bulk jobs", "err", err)
+		} else if n > 0 {
+			st.logger.Debug("pruned expired bulk jobs", "jobs", n)
+		}
+		select {
+		case <-t.C:
+		case <-st.ctx.Done():
+			return
+		}
+	}
+}
+
+// expired reports whether j finished longer than the retention ago.
+func (st *jobStore) expired(j *jobqueue.Job) bool {
+	return !j.FinishedAt.IsZero() && time.Since(j.FinishedAt) > st.retention
+}
+
+// add stores a new job for postcodes. It returns nil when maxPendingJobs
+// are already queued or running.
+func (st *jobStore) add(postcodes []string, opts checker.CheckOptions, bulk checker.BulkOptions) (*jobqueue.Job, error) {
+	// Jobs are only added by handleJobs, so the count cannot go stale
+	// between checking and storing.
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	pending, err := st.db.Pending()
+	if err != nil {
+		return nil, err
+	}
+	if pending >= maxPendingJobs {
+		return nil, nil
+	}
+	b := make([]byte, 16)
+	rand.Read(b)
+	j := &jobqueue.Job{
+		ID:        hex.EncodeToString(b),
+		State:     jobqueue.Queued,
+		Postcodes: postcodes,
+		Total:     len(postcodes),
+		Options:   opts,
+		Bulk:      bulk,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := st.db.Create(j); err != nil {
+		return nil, fmt.Errorf("failed to save job: %w", err)
+	}
+	return j, nil
+}
+
+// get returns a job that has not expired, or nil.
+func (st *jobStore) get(id string) (*jobqueue.Job, error) {
+	j, err := st.db.Get(id)
+	if errors.Is(err, jobqueue.ErrNotFound) || (err == nil && st.expired(j)) {
+		return nil, nil
+	}
+	return j, err
+}
+
+// remove cancels a job and deletes it with its results.
+func (st *jobStore) remove(id string) error {
+	st.mu.Lock()
+	cancel := st.running[id]
+	st.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	return st.db.Delete(id)
+}
+
+// startJob runs j in the background once a job slot is free.
+func (s *Server) startJob(j *jobqueue.Job, logger *slog.Logger) {
+	ctx, cancel := context.WithCancel(s.jobs.ctx)
+	s.jobs.mu.Lock()
+	s.jobs.running[j.ID] = cancel
+	s.jobs.mu.Unlock()
+	s.jobs.wg.Add(1)
+	go func() {
+		defer s.jobs.wg.Done()
+		defer func() {
+			s.jobs.mu.Lock()
+			delete(s.jobs.running, j.ID)
+			s.jobs.mu.Unlock()
+			cancel()
+		}()
+		if err := s.runJob(ctx, j, logger); err != nil {
+			logger.Error("bulk job failed", "err", err)
+		}
+	}()
+}
+
+// resumeJobs restarts the jobs left queued or running by the last run of
+// the server, skipping the postcodes whose results were saved.
+func (s *Server) resumeJobs() {
+	jobs, err := s.jobs.db.Unfinished()
+	if err != nil {
+		s.logger.Error("failed to resume bulk jobs", "err", err)
+		return
+	}
+	for _, j := range jobs {
+		logger := s.logger.With("job", j.ID)
+		logger.Info("resuming bulk job", "postcodes", j.Total, "completed", j.Completed)
+		s.startJob(j, logger)
+	}
+}
+
+// runJob checks the job's postcodes not yet checked, saving the results
+// as they arrive. A job stopped by Server.Close stays running in jobs.db
+// to be resumed; one stopped by DELETE is gone.
+func (s *Server) runJob(ctx context.Context, j *jobqueue.Job, logger *slog.Logger) error {
+	select {
+	case s.jobs.slots <- struct{}{}:
+		defer func() { <-s.jobs.slots }()
+	case <-ctx.Done():
+		return nil
+	}
+
+	checked, err := s.jobs.db.Checked(j.ID)
+	if err != nil {
+		return err
+	}
+	var postcodes []string
+	var indexes []int
+	for i, pc := range j.Postcodes {
+		if !checked[i] {
+			postcodes = append(postcodes, pc)
+			indexes = append(indexes, i)
+		}
+	}
+	j.State = jobqueue.Running
+	if j.StartedAt.IsZero() {
+		j.StartedAt = time.Now().UTC()
+	}
+	if err := s.jobs.db.Save(j, nil); err != nil {
+		return ignoreDeleted(err)
+	}
+	logger.Info("bulk job started", "postcodes", len(postcodes))
+
+	var buf []checker.Indexed
+	flush := func() error {
+		if err := s.jobs.db.Save(j, buf); err != nil {
+			return err
+		}
+		buf = buf[:0]
+		return nil
+	}
+	tick := time.NewTicker(jobFlushInterval)
+	defer tick.Stop()
+	results := s.checker.UsingLogger(logger).StreamBulk(ctx, postcodes, j.Options, j.Bulk)
+	for results != nil {
+		select {
+		case res, ok := <-results:
+			if !ok {
+				results = nil
+				continue
+			}
+			if ctx.Err() != nil {
+				// The check was interrupted; it is redone on resuming.
+				continue
+			}
+			res.Index = indexes[res.Index]
+			buf = append(buf, res)
+			j.Completed++
+			j.Summary.Add(res.Result)
+			if res.Error != "" {
+				j.Failed++
+			}
+			if len(buf) < jobFlushSize {
+				continue
+			}
+		case <-tick.C:
+			if len(buf) == 0 {
+				continue
+			}
+		}
+		if err := flush(); errors.Is(err, jobqueue.ErrNotFound) {
+			return nil
+		} else if err != nil {
+			logger.Warn("failed to save bulk job results", "err", err)
+		}
+	}
+	if ctx.Err() != nil {
+		if s.jobs.ctx.Err() == nil {
+			// Deleted.
+			return nil
+		}
+		logger.Info("bulk job interrupted; it resumes on restart", "completed", j.Completed)
+		return ignoreDeleted(flush())
+	}
+	j.State, j.FinishedAt = jobqueue.Done, time.Now().UTC()
+	if err := flush(); err != nil {
+		return ignoreDeleted(err)
+	}
+	logger.Info("bulk job finished", "postcodes", j.Total, "failed", j.Failed)
+	return nil
+}
+
+// ignoreDeleted drops the error saving a job deleted while it ran.
+func ignoreDeleted(err error) error {
+	if errors.Is(err, jobqueue.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// jobStatus describes a job's progress.
+type jobStatus struct {
+	ID         string     `json:"id" xml:"id"`
+	State      string     `json:"state" xml:"state"`
+	Total      int        `json:"total" xml:"total"`
+	Completed  int        `json:"completed" xml:"completed"`
+	Failed     int        `json:"failed" xml:"failed"`
+	CreatedAt  time.Time  `json:"created_at" xml:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty" xml:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" xml:"finished_at,omitempty"`
+	// ExpiresAt is when a finished job's results are discarded.
+	ExpiresAt *time.Time `json:"expires_at,omitempty" xml:"expires_at,omitempty"`
+	// Summary counts the results so far by status.
+	Summary checker.Summary `json:"summary" xml:"summary"`
+	// ResultsURL is set once the job is done.
+	ResultsURL string `json:"results_url,omitempty" xml:"results_url,omitempty"`
+}
+
+// jobStatus is j's status with its expiry under the server's retention.
+func (s *Server) jobStatus(j *jobqueue.Job) jobStatus {
+	st := jobStatus{ID: j.ID, State: j.State, Total: j.Total, Completed: j.Completed, Failed: j.Failed, Summary: j.Summary, CreatedAt: j.CreatedAt}
+	if !j.StartedAt.IsZero() {
+		started := j.StartedAt
+		st.StartedAt = &started
+	}
+	if !j.FinishedAt.IsZero() {
+		finished := j.FinishedAt
+		expires := finished.Add(s.jobs.retention)
+		st.FinishedAt, st.ExpiresAt = &finished, &expires
+	}
+	if j.State == jobqueue.Done {
+		st.ResultsURL = s.apiRoot + "/jobs/" + j.ID + "/results"
+	}
+	return st
+}
+
+// POST /api/jobs — body {"postcodes": [...]} with up to 100,000 postcodes
+// and the query options of /api/mobile/bulk. Answers 202 with the job's
+// status; poll GET /api/jobs/{id} until it is done, then fetch
+// GET /api/jobs/{id}/results. DELETE /api/jobs/{id} cancels a job.
+// GET /api/jobs?status= lists jobs, newest first.
+func (s *Server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.handleJobList(w, r)
+		return
+	case http.MethodPost:
+	default:
+		s.respondError(w, r, http.StatusMethodNotAllowed, "GET or POST required")
+		return
+	}
+	s.limitBody(w, r)
+	var body struct {
+		Postcodes []string `json:"postcodes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if len(body.Postcodes) == 0 || len(body.Postcodes) > maxJobPostcodes {
+		s.respondError(w, r, http.StatusBadRequest, fmt.Sprintf("provide between 1 and %d postcodes", maxJobPostcodes))
+		return
+	}
+	opts, err := s.checkOptions(r)
+	if err != nil {
+		s.respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	j, err := s.jobs.add(body.Postcodes, opts, s.bulkOptions(r))
+	if err != nil {
+		s.logger.Error("failed to add bulk job", "err", err)
+		s.respondError(w, r, http.StatusInternalServerError, "failed to save job")
+		return
+	}
+	if j == nil {
+		s.respondError(w, r, http.StatusTooManyRequests, fmt.Sprintf("%d jobs are already pending; try again later", maxPendingJobs))
+		return
+	}
+	s.startJob(j, logging.FromContext(r.Context(), s.logger).With("job", j.ID))
+
+	w.Header().Set("Location", s.apiRoot+"/jobs/"+j.ID)
+	s.respond(w, r, http.StatusAccepted, envelope{Status: "ok", Result: s.jobStatus(j)})
+}
+
+// handleJobList lists up to 100 jobs that have not expired, newest first,
+// optionally only those in ?status=queued, running or done.
+func (s *Server) handleJobList(w http.ResponseWriter, r *http.Request) {
+	state := strings.ToLower(r.URL.Query().Get("status"))
+	if state != "" && !jobqueue.ValidState(state) {
+		s.respondError(w, r, http.StatusBadRequest, "status must be one of "+strings.Join(jobqueue.States, ", "))
+		return
+	}
+	jobs, err := s.jobs.db.List(state, maxListedJobs)
+	if err != nil {
+		s.logger.Error("failed to list bulk jobs", "err", err)
+		s.respondError(w, r, http.StatusInternalServerError, "failed to list jobs")
+		return
+	}
+	list := []jobStatus{}
+	for _, j := range jobs {
+		if !s.jobs.expired(j) {
+			list = append(list, s.jobStatus(j))
+		}
+	}
+	s.respond(w, r, http.StatusOK, envelope{Status: "ok", Result: list})
+}
+
+// GET or DELETE /api/jobs/{id}, GET /api/jobs/{id}/results
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	id, sub, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/jobs/"), "/")
+	j, err := s.jobs.get(id)
+	if err != nil {
+		s.logger.Error("failed to read bulk job", "job", id, "err", err)
+		s.respondError(w, r, http.StatusInternalServerError, "failed to read job")
+		return
+	}
+	if j == nil {
+		s.respondError(w, r, http.StatusNotFound, fmt.Sprintf("no job %q", id))
+		return
+	}
+	switch {
+	case sub == "" && r.Method == http.MethodGet:
+		s.respond(w, r, http.StatusOK, envelope{Status: "ok", Result: s.jobStatus(j)})
+	case sub == "" && r.Method == http.MethodDelete:
+		if err := s.jobs.remove(id); err != nil && !errors.Is(err, jobqueue.ErrNotFound) {
+			s.logger.Error("failed to delete bulk job", "job", id, "err", err)
+			s.respondError(w, r, http.StatusInternalServerError, "failed to delete job")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case sub == "results" && r.Method == http.MethodGet:
+		s.handleJobResults(w, r, j)
+	case sub == "" || sub == "results":
+		s.respondError(w, r, http.StatusMethodNotAllowed, "GET or DELETE required")
+	default:
+		s.respondError(w, r, http.StatusNotFound, "not found")
+	}
+}
+
+// jobPage describes one page of a job's results.
+type jobPage struct {
+	ID     string `json:"id" xml:"id"`
+	Offset int    `json:"offset" xml:"offset"`
+	Limit  int    `json:"limit" xml:"limit"`
+	Total  int    `json:"total" xml:"total"`
+	// Next is the URL of the following page, if any.
+	Next string `json:"next,omitempty" xml:"next,omitempty"`
+}
+
+// handleJobResults serves a finished job's results in input order, a page
+// at a time (?offset=, ?limit= up to 10,000) or, with ?format=ndjson or
+// Accept: application/x-ndjson, all at once as NDJSON.
+func (s *Server) handleJobResults(w http.ResponseWriter, r *http.Request, j *jobqueue.Job) {
+	if j.State != jobqueue.Done {
+		s.respondError(w, r, http.StatusConflict, fmt.Sprintf("job is %s; results are available once it is done", j.State))
+		return
+	}
+
+	q := r.URL.Query()
+	if q.Get("format") == "ndjson" || (q.Get("format") == "" && strings.Contains(r.Header.Get("Accept"), "application/x-ndjson")) {
+		fields, err := parseFields(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		enc := json.NewEncoder(w)
+		for offset := 0; offset < j.Total; offset += maxJobPageSize {
+			results, err := s.jobs.db.Results(j.ID, offset, maxJobPageSize)
+			if err != nil {
+				s.logger.Error("failed to read bulk job results", "job", j.ID, "err", err)
+				return
+			}
+			for i, res := range results {
+				var line any = checker.Indexed{Index: offset + i, Result: res}
+				if fields != nil {
+					if line, err = fields.apply(line, "index"); err != nil {
+						return
+					}
+				}
+				if err := enc.Encode(line); err != nil {
+					return
+				}
+			}
+		}
+		return
+	}
+
+	offset, limit := 0, defaultJobPageSize
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			s.respondError(w, r, http.StatusBadRequest, "offset must be a non-negative integer")
+			return
+		}
+		offset = min(n, j.Total)
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxJobPageSize {
+			s.respondError(w, r, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxJobPageSize))
+			return
+		}
+		limit = n
+	}
+	results, err := s.jobs.db.Results(j.ID, offset, limit)
+	if err != nil {
+		s.logger.Error("failed to read bulk job results", "job", j.ID, "err", err)
+		s.respondError(w, r, http.StatusInternalServerError, "failed to read results")
+		return
+	}
+	end := min(offset+limit, j.Total)
+	page := jobPage{ID: j.ID, Offset: offset, Limit: limit, Total: j.Total}
+	if end < j.Total {
+		next := r.URL.Query()
+		next.Set("offset", strconv.Itoa(end))
+		next.Set("limit", strconv.Itoa(limit))
+		page.Next = s.apiRoot + strings.TrimPrefix(r.URL.Path, "/api") + "?" + next.Encode()
+	}
+	s.respond(w, r, http.StatusOK, envelope{Status: "ok", Result: page, Results: results, Summary: &j.Summary})
+}
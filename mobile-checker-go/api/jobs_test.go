@@ -0,0 +1,225 @@
+package api_test
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/mobile-checker/api"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func TestJobs(t *testing.T) {
+	// Postcodes without stored geographic data wait on postcodes.io until
+	// release is closed, holding the job in progress.
+	release := make(chan struct{})
+	postcodes := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"status":404,"error":"Postcode not found"}`))
+	}))
+	defer postcodes.Close()
+	places := []ofcom.Place{
+		{Postcode: "LS11AA", Country: "England", Latitude: 53.797, Longitude: -1.548},
+		{Postcode: "LS11AB", Country: "England", Latitude: 53.797, Longitude: -1.548},
+	}
+	srv := api.NewServer(newDataDir(t, places), quietLogger(), api.WithPostcodesURL(postcodes.URL))
+	h := srv.Handler()
+	closed := false
+	defer func() {
+		if !closed {
+			close(release)
+		}
+		srv.Close()
+	}()
+
+	post := func(body string) *http.Response {
+		req := httptest.NewRequest(http.MethodPost, "/api/jobs", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Result()
+	}
+	if resp := post(`{"postcodes":[]}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for no postcodes, got %d", resp.StatusCode)
+	}
+
+	resp := post(`{"postcodes":["LS11AA","LS11AB","LS11AC"]}`)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", resp.StatusCode)
+	}
+	job := decode(t, resp)["result"].(map[string]any)
+	id, _ := job["id"].(string)
+	if id == "" || resp.Header.Get("Location") != "/api/jobs/"+id || job["total"] != float64(3) {
+		t.Fatalf("unexpected job %v (Location %q)", job, resp.Header.Get("Location"))
+	}
+
+	status := decode(t, get(t, h, "/api/jobs/"+id))["result"].(map[string]any)
+	if status["state"] == "done" || status["results_url"] != nil {
+		t.Errorf("expected the job to be in progress, got %v", status)
+	}
+	if resp := get(t, h, "/api/jobs/"+id+"/results"); resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected 409 before the job is done, got %d", resp.StatusCode)
+	}
+
+	close(release)
+	closed = true
+	deadline := time.Now().Add(5 * time.Second)
+	for status["state"] != "done" {
+		if time.Now().After(deadline) {
+			t.Fatalf("job did not finish: %v", status)
+		}
+		time.Sleep(10 * time.Millisecond)
+		status = decode(t, get(t, h, "/api/jobs/"+id))["result"].(map[string]any)
+	}
+	if status["completed"] != float64(3) || status["failed"] != float64(1) || status["results_url"] != "/api/jobs/"+id+"/results" || status["expires_at"] == nil {
+		t.Errorf("unexpected finished job %v", status)
+	}
+
+	body := decode(t, get(t, h, "/api/jobs/"+id+"/results?limit=2"))
+	page := body["result"].(map[string]any)
+	results := body["results"].([]any)
+	if len(results) != 2 || results[0].(map[string]any)["postcode"] != "LS11AA" || page["total"] != float64(3) {
+		t.Fatalf("unexpected first page %v", body)
+	}
+	next, _ := page["next"].(string)
+	if !strings.Contains(next, "offset=2") {
+		t.Fatalf("expected a next link at offset 2, got %q", next)
+	}
+	body = decode(t, get(t, h, next))
+	results = body["results"].([]any)
+	if len(results) != 1 || results[0].(map[string]any)["postcode"] != "LS11AC" || body["result"].(map[string]any)["next"] != nil {
+		t.Errorf("unexpected last page %v", body)
+	}
+	if resp := get(t, h, "/api/jobs/"+id+"/results?limit=0"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for limit=0, got %d", resp.StatusCode)
+	}
+
+	resp = get(t, h, "/api/jobs/"+id+"/results", "Accept", "application/x-ndjson")
+	if ct := resp.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("expected NDJSON, got %q", ct)
+	}
+	var lines []string
+	for sc := bufio.NewScanner(resp.Body); sc.Scan(); {
+		lines = append(lines, sc.Text())
+	}
+	if len(lines) != 3 || !strings.HasPrefix(lines[2], `{"index":2,"postcode":"LS11AC"`) {
+		t.Errorf("unexpected NDJSON %q", lines)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/jobs/"+id, nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("expected 204 from DELETE, got %d", rec.Code)
+	}
+	if resp := get(t, h, "/api/jobs/"+id); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected a deleted job to be gone, got %d", resp.StatusCode)
+	}
+}
+
+func TestJobs_CloseCancelsRunningJobs(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	postcodes := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer postcodes.Close()
+	srv := api.NewServer(newDataDir(t, nil), quietLogger(), api.WithPostcodesURL(postcodes.URL))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/jobs", strings.NewReader(`{"postcodes":["LS11AA"]}`))
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rec.Code)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		srv.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not stop the running job")
+	}
+}
+
+func TestJobs_ResumeAfterRestart(t *testing.T) {
+	// LS11AD has no stored geographic data, so its check waits on
+	// postcodes.io, which answers only once release is closed.
+	release := make(chan struct{})
+	postcodes := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"status":404,"error":"Postcode not found"}`))
+		case <-r.Context().Done():
+		}
+	}))
+	defer postcodes.Close()
+	places := []ofcom.Place{
+		{Postcode: "LS11AA", Country: "England", Latitude: 53.797, Longitude: -1.548},
+		{Postcode: "LS11AB", Country: "England", Latitude: 53.797, Longitude: -1.548},
+	}
+	dataDir := newDataDir(t, places)
+
+	srv := api.NewServer(dataDir, quietLogger(), api.WithPostcodesURL(postcodes.URL))
+	req := httptest.NewRequest(http.MethodPost, "/api/jobs", strings.NewReader(`{"postcodes":["LS11AA","LS11AB","LS11AD"]}`))
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rec.Code)
+	}
+	id := decode(t, rec.Result())["result"].(map[string]any)["id"].(string)
+	waitForJob(t, srv.Handler(), id, func(status map[string]any) bool { return status["completed"] == float64(2) })
+	if err := srv.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	close(release)
+	srv = api.NewServer(dataDir, quietLogger(), api.WithPostcodesURL(postcodes.URL))
+	defer srv.Close()
+	h := srv.Handler()
+	status := waitForJob(t, h, id, func(status map[string]any) bool { return status["state"] == "done" })
+	if status["completed"] != float64(3) || status["failed"] != float64(1) {
+		t.Errorf("expected the resumed job to check only LS11AD, got %v", status)
+	}
+	results := decode(t, get(t, h, "/api/jobs/"+id+"/results"))["results"].([]any)
+	if len(results) != 3 || results[2].(map[string]any)["postcode"] != "LS11AD" {
+		t.Errorf("unexpected results %v", results)
+	}
+
+	list := decode(t, get(t, h, "/api/jobs?status=done"))["result"].([]any)
+	if len(list) != 1 || list[0].(map[string]any)["id"] != id {
+		t.Errorf("expected the job listed as done, got %v", list)
+	}
+	if list := decode(t, get(t, h, "/api/jobs?status=running"))["result"].([]any); len(list) != 0 {
+		t.Errorf("expected no running jobs, got %v", list)
+	}
+	if resp := get(t, h, "/api/jobs?status=lost"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown status, got %d", resp.StatusCode)
+	}
+}
+
+// waitForJob polls a job's status until done reports true.
+func waitForJob(t *testing.T, h http.Handler, id string, done func(map[string]any) bool) map[string]any {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		status := decode(t, get(t, h, "/api/jobs/"+id))["result"].(map[string]any)
+		if done(status) {
+			return status
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job did not progress: %v", status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/logging"
+)
+
+// CORSConfig controls which browser origins may call the API. An empty
+// AllowedOrigins disables CORS; "*" allows any origin.
+type CORSConfig struct {
+	AllowedOrigins []string
+	// AllowedMethods defaults to GET, POST and OPTIONS.
+	AllowedMethods []string
+	// AllowedHeaders defaults to Content-Type.
+	AllowedHeaders []string
+	// MaxAge is how long, in seconds, browsers may cache a preflight
+	// response; 0 leaves it to the browser.
+	MaxAge int
+}
+
+// WithCORS enables CORS for the given origins.
+func WithCORS(cfg CORSConfig) Option {
+	return func(s *Server) { s.cors = cfg }
+}
+
+// WithMaxBodyBytes limits the size of bulk request bodies; 0 means no limit.
+func WithMaxBodyBytes(n int64) Option {
+	return func(s *Server) { s.maxBody = n }
+}
+
+// Handler returns the API routes wrapped with security headers, request
+// logging, response compression unless disabled and, if configured, CORS.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	s.Routes(mux)
+	var h http.Handler = mux
+	if len(s.cors.AllowedOrigins) > 0 {
+		h = corsMiddleware(s.cors, h)
+	}
+	if !s.noCompress {
+		h = compress(h)
+	}
+	return s.requestLog(securityHeaders(h))
+}
+
+// requestIDHeader carries a request's correlation ID, taken from the
+// client when valid and generated otherwise.
+const requestIDHeader = "X-Request-ID"
+
+// requestLog gives each request an ID, echoed in the X-Request-ID response
+// header and attached to every log line written for the request, and logs
+// one access line when it completes.
+func (s *Server) requestLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+		logger := s.logger.With("request_id", id)
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r.WithContext(logging.NewContext(r.Context(), logger)))
+
+		attrs := []any{
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"bytes", rec.bytes,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"client_ip", clientIP(r),
+		}
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			attrs = append(attrs, "forwarded_for", fwd)
+		}
+		logger.Info("request", attrs...)
+	})
+}
+
+// validRequestID accepts client IDs of up to 128 printable ASCII
+// characters, so they cannot forge log lines.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// statusRecorder captures the status and size of a response for the
+// access log.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+	wrote  bool
+}
+
+func (w *statusRecorder) WriteHeader(code int) {
+	if !w.wrote {
+		w.status, w.wrote = code, true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	w.wrote = true
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush keeps NDJSON streaming working through the recorder.
+func (w *statusRecorder) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// securityHeaders sets headers suited to a JSON API that is never framed or
+// rendered as a document, and HSTS on requests that arrived over HTTPS.
+func securityHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("X-Frame-Options", "DENY")
+		h.Set("Referrer-Policy", "no-referrer")
+		h.Set("Content-Security-Policy", "default-src 'none'; frame-ancestors 'none'")
+		if r.TLS != nil {
+			h.Set("Strict-Transport-Security", "max-age=31536000")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func corsMiddleware(cfg CORSConfig, next http.Handler) http.Handler {
+	methods := cfg.AllowedMethods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodPost, http.MethodOptions}
+	}
+	headers := cfg.AllowedHeaders
+	if len(headers) == 0 {
+		headers = []string{"Content-Type"}
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(headers, ", ")
+	anyOrigin := false
+	allowed := make(map[string]bool, len(cfg.AllowedOrigins))
+	for _, o := range cfg.AllowedOrigins {
+		if o == "*" {
+			anyOrigin = true
+		}
+		allowed[strings.TrimSuffix(o, "/")] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		h := w.Header()
+		h.Add("Vary", "Origin")
+		if !anyOrigin && !allowed[origin] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if anyOrigin {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			h.Set("Access-Control-Allow-Methods", allowMethods)
+			h.Set("Access-Control-Allow-Headers", allowHeaders)
+			if cfg.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", strconv.Itoa(cfg.MaxAge))
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limitBody applies the configured body size limit to r.
+func (s *Server) limitBody(w http.ResponseWriter, r *http.Request) {
+	if s.maxBody > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, s.maxBody)
+	}
+}
+
+// writeDecodeError reports a request body that could not be decoded,
+// distinguishing bodies over the size limit.
+func writeDecodeError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(w, http.StatusRequestEntityTooLarge, "request body exceeds "+strconv.FormatInt(tooLarge.Limit, 10)+" bytes")
+		return
+	}
+	writeError(w, http.StatusBadRequest, "invalid JSON body")
+}
@@ -0,0 +1,185 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/api"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	h := api.NewServer(newDataDir(t, nil), quietLogger()).Handler()
+	resp := get(t, h, "/healthz")
+	for name, want := range map[string]string{
+		"X-Content-Type-Options":  "nosniff",
+		"X-Frame-Options":         "DENY",
+		"Referrer-Policy":         "no-referrer",
+		"Content-Security-Policy": "default-src 'none'; frame-ancestors 'none'",
+	} {
+		if got := resp.Header.Get(name); got != want {
+			t.Errorf("%s: expected %q, got %q", name, want, got)
+		}
+	}
+}
+
+func TestCORS(t *testing.T) {
+	dir := newDataDir(t, nil)
+	h := api.NewServer(dir, quietLogger(), api.WithCORS(api.CORSConfig{
+		AllowedOrigins: []string{"https://example.com/"},
+		AllowedHeaders: []string{"Content-Type", "X-Request-ID"},
+		MaxAge:         600,
+	})).Handler()
+
+	t.Run("preflight", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/api/mobile/bulk", nil)
+		req.Header.Set("Origin", "https://example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("expected 204, got %d", rec.Code)
+		}
+		for name, want := range map[string]string{
+			"Access-Control-Allow-Origin":  "https://example.com",
+			"Access-Control-Allow-Methods": "GET, POST, OPTIONS",
+			"Access-Control-Allow-Headers": "Content-Type, X-Request-ID",
+			"Access-Control-Max-Age":       "600",
+		} {
+			if got := rec.Header().Get(name); got != want {
+				t.Errorf("%s: expected %q, got %q", name, want, got)
+			}
+		}
+	})
+
+	t.Run("allowed origin", func(t *testing.T) {
+		resp := get(t, h, "/healthz", "Origin", "https://example.com")
+		if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "https://example.com" {
+			t.Errorf("expected the origin to be allowed, got %q", got)
+		}
+		if !strings.Contains(strings.Join(resp.Header.Values("Vary"), ","), "Origin") {
+			t.Error("expected Vary: Origin")
+		}
+	})
+
+	t.Run("disallowed origin", func(t *testing.T) {
+		resp := get(t, h, "/healthz", "Origin", "https://evil.example")
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("expected the request to be served, got %d", resp.StatusCode)
+		}
+		if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("expected no Access-Control-Allow-Origin, got %q", got)
+		}
+	})
+
+	t.Run("any origin", func(t *testing.T) {
+		h := api.NewServer(dir, quietLogger(), api.WithCORS(api.CORSConfig{AllowedOrigins: []string{"*"}})).Handler()
+		resp := get(t, h, "/healthz", "Origin", "https://anywhere.example")
+		if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "*" {
+			t.Errorf("expected *, got %q", got)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		h := api.NewServer(dir, quietLogger()).Handler()
+		resp := get(t, h, "/healthz", "Origin", "https://example.com")
+		if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("expected CORS to be off by default, got %q", got)
+		}
+	})
+}
+
+func TestLimitBody(t *testing.T) {
+	h := api.NewServer(newDataDir(t, nil), quietLogger(), api.WithOffline(), api.WithMaxBodyBytes(64)).Handler()
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/mobile/bulk", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := post(`{"postcodes":["LS11AA"]}`); rec.Code != http.StatusOK {
+		t.Errorf("expected a small body to be accepted, got %d: %s", rec.Code, rec.Body)
+	}
+	rec := post(`{"postcodes":["LS11AA","LS11AB","LS11AA","LS11AB","LS11AA","LS11AB"]}`)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d: %s", rec.Code, rec.Body)
+	}
+	if !strings.Contains(rec.Body.String(), "64 bytes") {
+		t.Errorf("expected the limit in the message, got %s", rec.Body)
+	}
+}
+
+func TestRequestLog(t *testing.T) {
+	var logs bytes.Buffer
+	places := []ofcom.Place{{Postcode: "LS11AA", AdminDistrict: "Leeds"}}
+	h := api.NewServer(newDataDir(t, places), api.WithLogger(slog.New(slog.NewJSONHandler(&logs, nil)))).Handler()
+
+	// lastLine decodes the access line the previous request wrote.
+	lastLine := func(t *testing.T) map[string]any {
+		t.Helper()
+		lines := bytes.Split(bytes.TrimSpace(logs.Bytes()), []byte("\n"))
+		var line map[string]any
+		if err := json.Unmarshal(lines[len(lines)-1], &line); err != nil {
+			t.Fatalf("decoding log line: %v", err)
+		}
+		return line
+	}
+
+	for _, tc := range []struct {
+		name     string
+		target   string
+		clientID string
+		keep     bool
+		status   int
+	}{
+		{"generated", "/healthz", "", false, http.StatusOK},
+		{"propagated", "/healthz", "abc-123_XYZ", true, http.StatusOK},
+		{"control characters", "/healthz", "abc\tdef", false, http.StatusOK},
+		{"spaces", "/healthz", "abc def", false, http.StatusOK},
+		{"too long", "/healthz", strings.Repeat("a", 129), false, http.StatusOK},
+		{"longest", "/healthz", strings.Repeat("a", 128), true, http.StatusOK},
+		{"error status", "/api/mobile/district/Atlantis", "", false, http.StatusNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			logs.Reset()
+			var header []string
+			if tc.clientID != "" {
+				header = []string{"X-Request-ID", tc.clientID}
+			}
+			resp := get(t, h, tc.target, header...)
+			id := resp.Header.Get("X-Request-ID")
+			switch {
+			case tc.keep && id != tc.clientID:
+				t.Errorf("expected the client's ID %q, got %q", tc.clientID, id)
+			case !tc.keep && (id == tc.clientID || len(id) != 16):
+				t.Errorf("expected a generated 16-character ID, got %q", id)
+			}
+
+			line := lastLine(t)
+			if line["msg"] != "request" || line["request_id"] != id || line["path"] != strings.SplitN(tc.target, "?", 2)[0] {
+				t.Errorf("unexpected access line %v", line)
+			}
+			if status, _ := line["status"].(float64); int(status) != tc.status || resp.StatusCode != tc.status {
+				t.Errorf("expected status %d logged, got %v (response %d)", tc.status, line["status"], resp.StatusCode)
+			}
+			if n, _ := line["bytes"].(float64); n <= 0 {
+				t.Errorf("expected the body size logged, got %v", line["bytes"])
+			}
+		})
+	}
+
+	t.Run("unique", func(t *testing.T) {
+		a := get(t, h, "/healthz").Header.Get("X-Request-ID")
+		b := get(t, h, "/healthz").Header.Get("X-Request-ID")
+		if a == b {
+			t.Errorf("expected a new ID per request, got %q twice", a)
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+// Namespace is a dataset served under /api/{name}/ beside the server's
+// own, e.g. staging data next to production.
+type Namespace struct {
+	Name string
+	// Source is where the dataset is read from: a data directory, a
+	// database file, a fixture file ending in .json, or the http(s) URL of
+	// a remote mobile.db.
+	Source string
+
+	fixtures checker.Fixtures
+}
+
+// namespaceName is the form of a namespace name, which is a path segment.
+var namespaceName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// reservedNames are /api/ paths a namespace would hide.
+var reservedNames = map[string]bool{"mobile": true, "jobs": true, "sites": true, "postcodes": true}
+
+// LoadRegistry reads a YAML file mapping namespace names to sources:
+//
+//	production: /srv/coverage/production
+//	staging: /srv/coverage/staging/mobile.db
+//	demo: fixtures.json
+//	shared: https://coverage-data.s3.amazonaws.com/mobile.db
+//
+// Relative paths are resolved against the file's directory. Fixture files
+// are loaded now, so mistakes in them stop the server from starting.
+func LoadRegistry(path string) ([]Namespace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries map[string]string
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out []Namespace
+	for _, name := range names {
+		src := strings.TrimSpace(entries[name])
+		switch {
+		case !namespaceName.MatchString(name):
+			return nil, fmt.Errorf("%s: namespace %q must be lower-case letters, digits, - and _", path, name)
+		case reservedNames[name]:
+			return nil, fmt.Errorf("%s: namespace %q would hide /api/%s/", path, name, name)
+		case src == "":
+			return nil, fmt.Errorf("%s: namespace %q has no source", path, name)
+		}
+		ns := Namespace{Name: name, Source: src}
+		if !remoteSource(src) {
+			if !filepath.IsAbs(src) {
+				ns.Source = filepath.Join(filepath.Dir(path), src)
+			}
+			if strings.EqualFold(filepath.Ext(src), ".json") {
+				if ns.fixtures, err = checker.LoadFixtures(ns.Source); err != nil {
+					return nil, fmt.Errorf("%s: namespace %q: %w", path, name, err)
+				}
+			} else if _, err := os.Stat(ns.Source); err != nil {
+				return nil, fmt.Errorf("%s: namespace %q: %w", path, name, err)
+			}
+		}
+		out = append(out, ns)
+	}
+	return out, nil
+}
+
+// remoteSource reports whether src is a URL rather than a path.
+func remoteSource(src string) bool {
+	return strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://")
+}
+
+// open returns the data directory to open ns with, given stateDir for
+// namespaces without one of their own, and the options reading its
+// source.
+func (ns Namespace) open(stateDir string) (string, []checker.Option) {
+	switch {
+	case remoteSource(ns.Source):
+		return stateDir, []checker.Option{checker.WithRemoteDataset(ns.Source, ofcom.RemoteOptions{})}
+	case ns.fixtures != nil:
+		return stateDir, []checker.Option{checker.WithFixtures(ns.fixtures)}
+	}
+	if info, err := os.Stat(ns.Source); err == nil && !info.IsDir() {
+		return filepath.Dir(ns.Source), []checker.Option{checker.WithDatabasePath(ns.Source)}
+	}
+	return ns.Source, nil
+}
+
+// WithNamespaces serves each namespace's dataset under /api/{name}/, e.g.
+// /api/staging/mobile/{postcode}, with the server's other settings. Each
+// keeps its bulk jobs, saved sites and history apart, in
+// namespaces/{name} in the data directory. POST /admin/reload reloads
+// them all.
+func WithNamespaces(ns []Namespace) Option {
+	return func(s *Server) { s.namespaces = ns }
+}
+
+// withNamespace makes a server answer for ns alone.
+func withNamespace(ns Namespace) Option {
+	return func(s *Server) {
+		s.namespace = &ns
+		s.namespaces = nil
+		s.apiRoot = "/api/" + ns.Name
+	}
+}
+
+// namespaceHandler serves child, the server of namespace name, under
+// /api/{name}/ by routing its requests as if they were to /api/.
+func namespaceHandler(name string, child *Server) http.Handler {
+	mux := http.NewServeMux()
+	child.Routes(mux)
+	prefix := "/api/" + name
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u := *r.URL
+		u.Path = "/api" + strings.TrimPrefix(r.URL.Path, prefix)
+		u.RawPath = ""
+		r2 := r.Clone(r.Context())
+		r2.URL = &u
+		mux.ServeHTTP(w, r2)
+	})
+}
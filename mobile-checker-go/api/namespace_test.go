@@ -0,0 +1,66 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/api"
+)
+
+func TestNamespaces_ServeEachDataset(t *testing.T) {
+	dir := t.TempDir()
+	staging := newDataDir(t, nil)
+	fixtures := `{"SW1A1AA": {"valid": true, "mobile": {"CoverageScore": 42}}}`
+	if err := os.WriteFile(filepath.Join(dir, "demo.json"), []byte(fixtures), 0644); err != nil {
+		t.Fatal(err)
+	}
+	registry := filepath.Join(dir, "datasets.yaml")
+	if err := os.WriteFile(registry, []byte("staging: "+filepath.Join(staging, "mobile.db")+"\ndemo: demo.json\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	namespaces, err := api.LoadRegistry(registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := api.NewServer(t.TempDir(), quietLogger(), api.WithOffline(), api.WithNamespaces(namespaces))
+	defer s.Close()
+	h := s.Handler()
+
+	// The server's own data directory has no dataset.
+	if resp := get(t, h, "/api/mobile/LS11AA"); resp.StatusCode == http.StatusOK {
+		t.Errorf("expected the default dataset to be missing, got %d", resp.StatusCode)
+	}
+	body := decode(t, get(t, h, "/api/staging/mobile/LS11AA"))
+	if body["status"] != "ok" || body["dataset"].(map[string]any)["year"] != "2023" {
+		t.Errorf("expected staging to answer from its database, got %v", body)
+	}
+	body = decode(t, get(t, h, "/api/demo/mobile/SW1A1AA"))
+	if mobile, _ := body["result"].(map[string]any)["mobile"].(map[string]any); mobile["CoverageScore"] != float64(42) {
+		t.Errorf("expected the demo fixture, got %v", body)
+	}
+
+	// Jobs belong to their namespace and link within it.
+	req := httptest.NewRequest(http.MethodPost, "/api/staging/jobs", strings.NewReader(`{"postcodes":["LS11AA"]}`))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if loc := rec.Header().Get("Location"); !strings.HasPrefix(loc, "/api/staging/jobs/") {
+		t.Errorf("expected a job under /api/staging/, got %q", loc)
+	}
+	if resp := get(t, h, strings.Replace(rec.Header().Get("Location"), "/staging", "", 1)); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected the job to be unknown outside its namespace, got %d", resp.StatusCode)
+	}
+}
+
+func TestLoadRegistry_RejectsReservedNames(t *testing.T) {
+	registry := filepath.Join(t.TempDir(), "datasets.yaml")
+	if err := os.WriteFile(registry, []byte("mobile: /srv/coverage\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.LoadRegistry(registry); err == nil || !strings.Contains(err.Error(), "hide") {
+		t.Errorf("expected a reserved name error, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+// maxNearbyPostcodes caps the postcodes listed by one nearby request.
+const maxNearbyPostcodes = 10000
+
+// GET /api/mobile/nearby?lat=53.8&lon=-1.55&radius=2km&operator=vodafone&tech=5g
+// Optional: indoor=true, limit=N (default 1000). Lists the postcodes within
+// the radius, nearest first, with their coverage.
+func (s *Server) handleNearby(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	lat, err1 := strconv.ParseFloat(q.Get("lat"), 64)
+	lon, err2 := strconv.ParseFloat(q.Get("lon"), 64)
+	if err1 != nil || err2 != nil {
+		s.respondError(w, r, http.StatusBadRequest, "lat and lon are required")
+		return
+	}
+	eastings, northings, err := ofcom.ToGrid(lat, lon)
+	if err != nil {
+		s.respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	radius, err := parseRadius(q.Get("radius"))
+	if err != nil {
+		s.respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	opts := ofcom.WithinOptions{
+		Operator:  q.Get("operator"),
+		Tech:      q.Get("tech"),
+		Indoor:    q.Get("indoor") == "true",
+		RadiusKm:  radius,
+		Threshold: s.threshold,
+	}
+	if _, err := ofcom.CoverageColumn(opts.Operator, opts.Tech, opts.Indoor); err != nil {
+		s.respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if v := q.Get("limit"); v != "" {
+		if opts.Limit, err = strconv.Atoi(v); err != nil || opts.Limit < 1 || opts.Limit > maxNearbyPostcodes {
+			s.respondError(w, r, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxNearbyPostcodes))
+			return
+		}
+	}
+
+	res, err := s.checkerFor(r).Within(eastings, northings, opts)
+	if err != nil {
+		s.respondCodedError(w, r, checker.CodeOf(err), err.Error())
+		return
+	}
+	s.respond(w, r, http.StatusOK, envelope{Status: "ok", Result: res})
+}
+
+// parseRadius reads a radius such as "2km", "500m" or "1.5" (kilometres)
+// as kilometres, up to ofcom.MaxWithinKm.
+func parseRadius(v string) (float64, error) {
+	if v == "" {
+		return 0, fmt.Errorf("radius is required, e.g. 2km or 500m")
+	}
+	scale := 1.0
+	v = strings.ToLower(strings.TrimSpace(v))
+	if n, ok := strings.CutSuffix(v, "km"); ok {
+		v = n
+	} else if n, ok := strings.CutSuffix(v, "m"); ok {
+		v, scale = n, 0.001
+	}
+	km, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil || km*scale <= 0 || km*scale > ofcom.MaxWithinKm {
+		return 0, fmt.Errorf("radius must be more than 0 and at most %dkm, e.g. 2km or 500m", ofcom.MaxWithinKm)
+	}
+	return km * scale, nil
+}
@@ -0,0 +1,64 @@
+package api_test
+
+import (
+	"encoding/csv"
+	"net/http"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/api"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func TestNearby(t *testing.T) {
+	e, n, err := ofcom.ToGrid(53.797, -1.548)
+	if err != nil {
+		t.Fatal(err)
+	}
+	places := []ofcom.Place{
+		{Postcode: "LS11AA", Latitude: 53.797, Longitude: -1.548, Eastings: e, Northings: n},
+		{Postcode: "LS11AB", Latitude: 53.8, Longitude: -1.55, Eastings: e + 300, Northings: n + 400},
+	}
+	h := api.NewServer(newDataDir(t, places), quietLogger()).Handler()
+
+	for _, tc := range []struct {
+		name   string
+		target string
+		status int
+	}{
+		{"ok", "/api/mobile/nearby?lat=53.797&lon=-1.548&radius=1km&operator=ee&tech=4g", http.StatusOK},
+		{"metres", "/api/mobile/nearby?lat=53.797&lon=-1.548&radius=600m&operator=EE&tech=4G", http.StatusOK},
+		{"no point", "/api/mobile/nearby?radius=1km&operator=ee&tech=4g", http.StatusBadRequest},
+		{"outside GB", "/api/mobile/nearby?lat=48.86&lon=2.35&radius=1km&operator=ee&tech=4g", http.StatusBadRequest},
+		{"no radius", "/api/mobile/nearby?lat=53.797&lon=-1.548&operator=ee&tech=4g", http.StatusBadRequest},
+		{"radius too big", "/api/mobile/nearby?lat=53.797&lon=-1.548&radius=11km&operator=ee&tech=4g", http.StatusBadRequest},
+		{"unknown operator", "/api/mobile/nearby?lat=53.797&lon=-1.548&radius=1km&operator=orange&tech=4g", http.StatusBadRequest},
+		{"bad limit", "/api/mobile/nearby?lat=53.797&lon=-1.548&radius=1km&operator=ee&tech=4g&limit=0", http.StatusBadRequest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if resp := get(t, h, tc.target); resp.StatusCode != tc.status {
+				t.Errorf("expected %d, got %d", tc.status, resp.StatusCode)
+			}
+		})
+	}
+
+	res := decode(t, get(t, h, "/api/mobile/nearby?lat=53.797&lon=-1.548&radius=1km&operator=ee&tech=4g"))["result"].(map[string]any)
+	pcs := res["postcodes"].([]any)
+	if len(pcs) != 2 || res["covered"] != float64(1) || res["uncovered"] != float64(1) {
+		t.Fatalf("unexpected result %v", res)
+	}
+	if first := pcs[0].(map[string]any); first["postcode"] != "LS11AA" || first["coverage_pct"] != float64(100) || first["covered"] != true {
+		t.Errorf("unexpected nearest postcode %v", first)
+	}
+	if second := pcs[1].(map[string]any); second["distance_km"] != 0.5 || second["covered"] != false {
+		t.Errorf("unexpected second postcode %v", second)
+	}
+
+	resp := get(t, h, "/api/mobile/nearby?lat=53.797&lon=-1.548&radius=1km&operator=ee&tech=4g", "Accept", "text/csv")
+	rows, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(rows) != 3 || rows[0][0] != "postcode" || rows[2][0] != "LS11AB" || rows[2][5] != "20" {
+		t.Errorf("unexpected CSV %q", rows)
+	}
+}
@@ -2,69 +2,686 @@
 package api
 
 import (
+	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
 
 	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/history"
+	"github.com/yourusername/mobile-checker/internal/logging"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+	"github.com/yourusername/mobile-checker/internal/sites"
 )
 
+// maxStreamPostcodes caps a single streaming bulk request.
+const maxStreamPostcodes = 10000
+
+// maxChangedPostcodes caps a single /api/mobile/changed request, whose
+// changed postcodes are all checked before it answers.
+const maxChangedPostcodes = 1000
+
 // Server is the HTTP API server.
 type Server struct {
 	checker *checker.Checker
+	logger  *slog.Logger
+	cors    CORSConfig
+	maxBody int64
+	suggest suggestCache
+	weights ofcom.ScoreWeights
+	// threshold is the coverage fraction counted as available; 0 means
+	// ofcom.CoverageThreshold.
+	threshold float64
+	indexed   bool // reload the in-memory index after /admin/reload
+	offline   bool
+	history   bool
+	// historyRetention is how long recorded checks are kept; 0 is forever.
+	historyRetention time.Duration
+	// fallbackURL is the server checks go to while the dataset is missing.
+	fallbackURL string
+	// remoteDB is the URL the Ofcom database is read from, if not local.
+	remoteDB string
+	// encryptKey is the passphrase the database and history are encrypted
+	// with, if any.
+	encryptKey string
+	// postcodeOpts configure the postcodes.io client, e.g. its URL.
+	postcodeOpts []postcode.Option
+	// fixtures, when set, answer every check; see checker.WithFixtures.
+	fixtures checker.Fixtures
+	// recommenders add tariff recommendations to checks.
+	recommenders []checker.Recommender
+	// readyUpstream makes /readyz also require postcodes.io.
+	readyUpstream bool
+	bulk          checker.BulkOptions
+	// cacheMaxAge is the max-age of cacheable check responses.
+	cacheMaxAge time.Duration
+	noCompress  bool
+	// adminToken authorises /admin endpoints; they are disabled when empty.
+	adminToken string
+	jobs       jobStore
+	setup      setupRun
+	sites      *sites.Store
+	tls        TLSConfig
+	// namespaces are served under /api/{name}/ by the servers in children.
+	namespaces []Namespace
+	children   map[string]*Server
+	// namespace is set on a child server, whose API is under apiRoot
+	// rather than /api.
+	namespace *Namespace
+	apiRoot   string
+
+	// mu guards the running servers, which Shutdown stops.
+	mu         sync.Mutex
+	httpServer *http.Server
+	grpcServer *grpc.Server
+	// challengeServer answers ACME challenges over plain HTTP; see
+	// TLSConfig.HTTPAddr.
+	challengeServer *http.Server
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithLogger sets the logger used by the server and its checker.
+func WithLogger(l *slog.Logger) Option {
+	return func(s *Server) { s.logger = l }
 }
 
 // NewServer creates a new API Server.
-func NewServer(dataDir string) *Server {
-	return &Server{checker: checker.New(dataDir)}
+func NewServer(dataDir string, opts ...Option) *Server {
+	s := &Server{logger: slog.Default(), suggest: suggestCache{ttl: suggestTTL}, cacheMaxAge: DefaultCacheMaxAge, apiRoot: "/api"}
+	for _, opt := range opts {
+		opt(s)
+	}
+	checkDir := dataDir
+	copts := []checker.Option{checker.WithLogger(s.logger), checker.WithEncryptionKey(s.encryptKey)}
+	if s.offline {
+		copts = append(copts, checker.WithOffline())
+	}
+	if s.history {
+		copts = append(copts, checker.WithHistory(history.New(dataDir, history.WithRetention(s.historyRetention), history.WithEncryptionKey(s.encryptKey))))
+	}
+	if s.namespace != nil {
+		// The namespace's source replaces the server's own.
+		var nopts []checker.Option
+		checkDir, nopts = s.namespace.open(dataDir)
+		copts = append(copts, nopts...)
+	} else {
+		if s.remoteDB != "" {
+			copts = append(copts, checker.WithRemoteDataset(s.remoteDB, ofcom.RemoteOptions{}))
+		}
+		if s.fallbackURL != "" {
+			copts = append(copts, checker.WithFallback(s.fallbackURL))
+		}
+		if s.fixtures != nil {
+			copts = append(copts, checker.WithFixtures(s.fixtures))
+		}
+	}
+	if len(s.postcodeOpts) > 0 {
+		copts = append(copts, checker.WithPostcodeClient(postcode.NewClient(s.postcodeOpts...)))
+	}
+	for _, r := range s.recommenders {
+		copts = append(copts, checker.WithRecommender(r))
+	}
+	s.checker = checker.New(checkDir, copts...)
+	s.sites = sites.New(dataDir)
+	s.jobs.init(dataDir, s.logger)
+	s.resumeJobs()
+	for _, ns := range s.namespaces {
+		if s.children == nil {
+			s.children = make(map[string]*Server)
+		}
+		child := append(slices.Clip(opts), withNamespace(ns), WithLogger(s.logger.With("namespace", ns.Name)))
+		s.children[ns.Name] = NewServer(filepath.Join(dataDir, "namespaces", ns.Name), child...)
+	}
+	return s
+}
+
+// Close stops any bulk jobs, which resume when the server next starts,
+// waits for a setup started by POST /admin/setup to finish and releases
+// the server's databases once it has stopped serving.
+func (s *Server) Close() error {
+	err := s.jobs.close()
+	s.setup.wg.Wait()
+	err = errors.Join(err, s.checker.Close())
+	for _, child := range s.children {
+		err = errors.Join(err, child.Close())
+	}
+	return err
+}
+
+// LoadIndex holds the dataset in memory so that checks, particularly bulk
+// requests, skip SQLite. The index is rebuilt by POST /admin/reload.
+func (s *Server) LoadIndex() error {
+	if err := s.checker.LoadIndex(); err != nil {
+		return err
+	}
+	s.indexed = true
+	for name, child := range s.children {
+		if err := child.LoadIndex(); err != nil {
+			return fmt.Errorf("namespace %s: %w", name, err)
+		}
+	}
+	return nil
 }
 
 // Routes registers all API routes.
 func (s *Server) Routes(mux *http.ServeMux) {
 	mux.HandleFunc("/health", s.handleHealth)
+	mux.HandleFunc("/healthz", s.handleHealth)
+	mux.HandleFunc("/readyz", s.handleReady)
+	mux.HandleFunc("/admin/reload", s.handleReload)
+	mux.HandleFunc("/admin/setup", s.handleAdminSetup)
+	mux.HandleFunc("/admin/datasets", s.handleAdminDatasets)
+	mux.HandleFunc("/admin/datasets/", s.handleAdminDatasets)
+	mux.HandleFunc("/api/postcodes/autocomplete", s.handleAutocomplete)
 	mux.HandleFunc("/api/mobile/bulk", s.handleBulk)
+	mux.HandleFunc("/api/mobile/bulk/stream", s.handleBulkStream)
+	mux.HandleFunc("/api/mobile/changed", s.handleChanged)
+	mux.HandleFunc("/api/sites", s.handleSites)
+	mux.HandleFunc("/api/jobs", s.handleJobs)
+	mux.HandleFunc("/api/jobs/", s.handleJob)
+	mux.HandleFunc("/api/mobile/heatmap", s.handleHeatmap)
+	mux.HandleFunc("/api/mobile/nearby", s.handleNearby)
+	mux.HandleFunc("/api/mobile/outcode/", s.handleOutcode)
+	mux.HandleFunc("/api/mobile/district/", s.handleArea("district"))
+	mux.HandleFunc("/api/mobile/region/", s.handleArea("region"))
+	mux.HandleFunc("/api/mobile/constituency/", s.handleArea("constituency"))
 	mux.HandleFunc("/api/mobile/", s.handleMobile)
+	for _, ns := range s.namespaces {
+		mux.Handle("/api/"+ns.Name+"/", namespaceHandler(ns.Name, s.children[ns.Name]))
+	}
+	mux.Handle("/", s.handleUI())
 }
 
+// GET /healthz (and /health) — liveness: the process is serving requests.
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
-	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "service": "UK Mobile Coverage API"})
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ok", "schema_version": checker.SchemaVersion, "service": "UK Mobile Coverage API"})
+}
+
+// GET /readyz — readiness: the dataset is usable and, with
+// WithReadyUpstream, postcodes.io answers. 503 when any component fails.
+// The body also counts postcodes.io requests held back by the rate limit.
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	ready := s.checker.Ready(s.readyUpstream)
+	status, code := "ok", http.StatusOK
+	if !ready.Ready {
+		status, code = "unavailable", http.StatusServiceUnavailable
+	}
+	st := s.checker.PostcodeStats()
+	writeJSON(w, code, map[string]any{"status": status, "schema_version": checker.SchemaVersion, "components": ready.Components, "postcodes_io": map[string]any{
+		"requests":     st.Requests,
+		"throttled":    st.Throttled,
+		"throttled_ms": st.Waited.Milliseconds(),
+		"rate_limited": st.RateLimited,
+	}})
+}
+
+// POST /admin/reload — switch to the database currently on disk, e.g. after
+// running setup against the server's data directory. Requires the admin
+// token.
+func (s *Server) handleReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "POST required")
+		return
+	}
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+	if err := s.reload(); err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	meta, err := s.checker.DatasetMeta()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	logging.FromContext(r.Context(), s.logger).Info("database reloaded", "dataset_year", meta["dataset_year"], "built_at", meta["built_at"])
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ok", "schema_version": checker.SchemaVersion, "dataset": meta})
+}
+
+// reload switches the server and its namespaces to the databases currently
+// on disk, rebuilding their in-memory indexes if they hold one.
+func (s *Server) reload() error {
+	if err := s.checker.Reload(); err != nil {
+		return err
+	}
+	if s.indexed {
+		if err := s.checker.LoadIndex(); err != nil {
+			return err
+		}
+	}
+	for _, ns := range s.namespaces {
+		child := s.children[ns.Name]
+		err := child.checker.Reload()
+		if err == nil && child.indexed {
+			err = child.checker.LoadIndex()
+		}
+		if err != nil {
+			return fmt.Errorf("namespace %s: %w", ns.Name, err)
+		}
+	}
+	return nil
+}
+
+// authorizeAdmin reports whether r carries the admin token, writing a 403
+// when none is configured and a 401 when it is missing or wrong.
+func (s *Server) authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if s.adminToken == "" {
+		writeError(w, http.StatusForbidden, "admin endpoints are disabled: start the server with --admin-token")
+		return false
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+		writeError(w, http.StatusUnauthorized, "a valid admin token is required")
+		return false
+	}
+	return true
 }
 
 // GET /api/mobile/{postcode}
 func (s *Server) handleMobile(w http.ResponseWriter, r *http.Request) {
 	pc := strings.TrimPrefix(r.URL.Path, "/api/mobile/")
+	if p, ok := strings.CutSuffix(pc, "/diff"); ok {
+		s.handleDiff(w, r, p)
+		return
+	}
 	if pc == "" {
-		writeError(w, http.StatusBadRequest, "postcode required")
+		s.respondError(w, r, http.StatusBadRequest, "postcode required")
+		return
+	}
+	opts, err := s.checkOptions(r)
+	if err != nil {
+		s.respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	tag := s.cacheTagFor(r, pc, opts)
+	notModified := tag != nil && tag.notModified(r)
+	// Checks answered with 304 are still run when history is on, so the
+	// history sees every check served.
+	if notModified && !s.history {
+		tag.writeNotModified(w, s.cacheMaxAge)
+		return
+	}
+	result := s.checkerFor(r).CheckContext(r.Context(), pc, opts)
+	if result.Terminated != nil {
+		s.respond(w, r, http.StatusGone, envelope{Status: "error", Code: string(result.Code), Message: result.Error, Result: result})
 		return
 	}
-	result := s.checker.Check(pc)
 	if result.Error != "" {
-		writeError(w, http.StatusNotFound, result.Error)
+		s.respondCodedError(w, r, result.Code, result.Error)
+		return
+	}
+	if result.Mobile == nil && result.Code != "" && result.Code != checker.CodeNotInDataset {
+		s.respondCodedError(w, r, result.Code, result.Note)
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]any{"status": "ok", "result": result})
+	// Degraded results, e.g. without geographic data, are not cached.
+	if tag != nil && (result.Err == nil || result.Code == checker.CodeNotInDataset) {
+		if notModified {
+			tag.writeNotModified(w, s.cacheMaxAge)
+			return
+		}
+		tag.write(w, s.cacheMaxAge)
+	}
+	s.respond(w, r, http.StatusOK, envelope{Status: "ok", Result: result})
 }
 
-// POST /api/mobile/bulk — {"postcodes": ["SW1A1AA", "EC1A1BB"]}
+// GET /api/mobile/{postcode}/diff?from=2022&to=2023
+func (s *Server) handleDiff(w http.ResponseWriter, r *http.Request, pc string) {
+	from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+	if pc == "" || from == "" || to == "" {
+		s.respondError(w, r, http.StatusBadRequest, "postcode, from and to are required")
+		return
+	}
+	opts, err := s.checkOptions(r)
+	if err != nil {
+		s.respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	tag := s.cacheTagForYears(r, pc, false, from, to)
+	if tag != nil && tag.notModified(r) {
+		tag.writeNotModified(w, s.cacheMaxAge)
+		return
+	}
+	diff, err := s.checkerFor(r).Diff(pc, from, to, opts)
+	if err != nil {
+		s.respondCodedError(w, r, checker.CodeOf(err), err.Error())
+		return
+	}
+	if tag != nil {
+		tag.write(w, s.cacheMaxAge)
+	}
+	s.respond(w, r, http.StatusOK, envelope{Status: "ok", Result: diff})
+}
+
+// POST /api/mobile/bulk — {"postcodes": ["SW1A1AA", "EC1A1BB"]}, optionally
+// ?fail_fast=true
 func (s *Server) handleBulk(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		writeError(w, http.StatusMethodNotAllowed, "POST required")
+		s.respondError(w, r, http.StatusMethodNotAllowed, "POST required")
 		return
 	}
+	s.limitBody(w, r)
 	var body struct {
 		Postcodes []string `json:"postcodes"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		writeDecodeError(w, err)
 		return
 	}
 	if len(body.Postcodes) == 0 || len(body.Postcodes) > 50 {
-		writeError(w, http.StatusBadRequest, "provide between 1 and 50 postcodes")
+		s.respondError(w, r, http.StatusBadRequest, "provide between 1 and 50 postcodes")
 		return
 	}
-	results := s.checker.CheckMultiple(body.Postcodes)
-	writeJSON(w, http.StatusOK, map[string]any{"status": "ok", "results": results})
+	opts, err := s.checkOptions(r)
+	if err != nil {
+		s.respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	results := s.checkerFor(r).CheckBulk(r.Context(), body.Postcodes, opts, s.bulkOptions(r))
+	s.respond(w, r, http.StatusOK, envelope{Status: "ok", Results: results, Summary: checker.Summarise(results)})
+}
+
+// POST /api/mobile/changed — {"postcodes": ["SW1A1AA", "LS11AA"], "version":
+// "2022@2023-01-10T09:00:00Z"}, the dataset version of the caller's results;
+// answers with current results for only the postcodes whose coverage has
+// changed since.
+func (s *Server) handleChanged(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.respondError(w, r, http.StatusMethodNotAllowed, "POST required")
+		return
+	}
+	s.limitBody(w, r)
+	var body struct {
+		Postcodes []string `json:"postcodes"`
+		Version   string   `json:"version"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if len(body.Postcodes) == 0 || len(body.Postcodes) > maxChangedPostcodes {
+		s.respondError(w, r, http.StatusBadRequest, fmt.Sprintf("provide between 1 and %d postcodes", maxChangedPostcodes))
+		return
+	}
+	if body.Version == "" {
+		s.respondError(w, r, http.StatusBadRequest, "version required: the dataset version of the results held")
+		return
+	}
+	opts, err := s.checkOptions(r)
+	if err != nil {
+		s.respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	results, err := s.checkerFor(r).Changed(r.Context(), body.Postcodes, body.Version, opts, s.bulkOptions(r))
+	if err != nil {
+		s.respondCodedError(w, r, checker.CodeOf(err), err.Error())
+		return
+	}
+	s.respond(w, r, http.StatusOK, envelope{Status: "ok", Results: results, Summary: checker.Summarise(results)})
+}
+
+// POST /api/mobile/bulk/stream — same body as /bulk, NDJSON response with one
+// result per line in completion order, each tagged with its input index and
+// the schema version.
+func (s *Server) handleBulkStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "POST required")
+		return
+	}
+	s.limitBody(w, r)
+	var body struct {
+		Postcodes []string `json:"postcodes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if len(body.Postcodes) == 0 || len(body.Postcodes) > maxStreamPostcodes {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("provide between 1 and %d postcodes", maxStreamPostcodes))
+		return
+	}
+	opts, err := s.checkOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	fields, err := parseFields(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	for res := range s.checkerFor(r).StreamBulk(r.Context(), body.Postcodes, opts, s.bulkOptions(r)) {
+		var line any = struct {
+			SchemaVersion int `json:"schema_version"`
+			checker.Indexed
+		}{checker.SchemaVersion, res}
+		if fields != nil {
+			if line, err = fields.apply(line, "schema_version", "index"); err != nil {
+				return
+			}
+		}
+		if err := enc.Encode(line); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// GET /api/mobile/district/{name}, /api/mobile/region/{name} and
+// /api/mobile/constituency/{name}
+func (s *Server) handleArea(level string) http.HandlerFunc {
+	prefix := "/api/mobile/" + level + "/"
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, prefix)
+		if name == "" {
+			s.respondError(w, r, http.StatusBadRequest, level+" name required")
+			return
+		}
+		if !ofcom.ValidAreaName(name) {
+			s.respondError(w, r, http.StatusBadRequest, fmt.Sprintf("invalid %s name %q", level, name))
+			return
+		}
+		summary, err := s.checkerFor(r).Aggregate(level, name)
+		if err != nil {
+			s.respondCodedError(w, r, checker.CodeOf(err), err.Error())
+			return
+		}
+		if summary == nil {
+			s.respondError(w, r, http.StatusNotFound, fmt.Sprintf("no postcodes found for %s %q", level, name))
+			return
+		}
+		s.respond(w, r, http.StatusOK, envelope{Status: "ok", Result: summary})
+	}
+}
+
+// GET /api/mobile/outcode/{outcode} — coverage statistics for an outward
+// code, e.g. LS1, read from the summary precomputed by setup.
+func (s *Server) handleOutcode(w http.ResponseWriter, r *http.Request) {
+	oc := strings.TrimPrefix(r.URL.Path, "/api/mobile/outcode/")
+	if oc == "" {
+		s.respondError(w, r, http.StatusBadRequest, "outcode required")
+		return
+	}
+	if err := postcode.ValidateOutcode(oc); err != nil {
+		s.respondCodedError(w, r, checker.CodeInvalidPostcode, err.Error())
+		return
+	}
+	summary, err := s.checkerFor(r).OutcodeSummary(oc)
+	if err != nil {
+		s.respondCodedError(w, r, checker.CodeOf(err), err.Error())
+		return
+	}
+	if summary == nil {
+		s.respondError(w, r, http.StatusNotFound, fmt.Sprintf("no postcodes found for outcode %q", postcode.Normalise(oc)))
+		return
+	}
+	s.respond(w, r, http.StatusOK, envelope{Status: "ok", Result: summary})
+}
+
+// WithBulkOptions sets the concurrency and per-check timeout of bulk
+// requests, REST and gRPC. Clients can ask for fail-fast per request.
+func WithBulkOptions(b checker.BulkOptions) Option {
+	return func(s *Server) { s.bulk = b }
+}
+
+// WithOffline serves checks without calling postcodes.io; see
+// checker.WithOffline.
+func WithOffline() Option {
+	return func(s *Server) { s.offline = true }
+}
+
+// WithReadyUpstream makes /readyz fail while postcodes.io is unreachable,
+// not only when the dataset is unusable.
+func WithReadyUpstream() Option {
+	return func(s *Server) { s.readyUpstream = true }
+}
+
+// WithHistory records every check served in history.db in the data
+// directory; see checker.WithHistory. Conditional requests still run the
+// check, so it is recorded, before being answered with 304.
+func WithHistory() Option {
+	return func(s *Server) { s.history = true }
+}
+
+// WithHistoryRetention prunes recorded checks older than d; see
+// history.WithRetention.
+func WithHistoryRetention(d time.Duration) Option {
+	return func(s *Server) { s.historyRetention = d }
+}
+
+// WithFallbackURL forwards checks to another mobile-checker server while
+// the local dataset has not been built; see checker.WithFallback.
+func WithFallbackURL(url string) Option {
+	return func(s *Server) { s.fallbackURL = url }
+}
+
+// WithRemoteDataset reads the Ofcom database from url with HTTP range
+// requests instead of from the data directory; see ofcom.WithRemote.
+// POST /admin/reload picks up a new file published at url.
+func WithRemoteDataset(url string) Option {
+	return func(s *Server) { s.remoteDB = url }
+}
+
+// WithEncryptionKey keeps the Ofcom database, and the history of
+// WithHistory, encrypted at rest with a key derived from passphrase; see
+// ofcom.WithEncryptionKey and history.WithEncryptionKey.
+func WithEncryptionKey(passphrase string) Option {
+	return func(s *Server) { s.encryptKey = passphrase }
+}
+
+// WithRecommender adds tariff recommendations to every check that found
+// coverage; see checker.WithRecommender.
+func WithRecommender(r checker.Recommender) Option {
+	return func(s *Server) { s.recommenders = append(s.recommenders, r) }
+}
+
+// WithFixtures serves canned results instead of checking postcodes.io and
+// the Ofcom database, so clients can test against the API without either;
+// see checker.WithFixtures. Area, heatmap and nearby endpoints still need
+// the dataset.
+func WithFixtures(f checker.Fixtures) Option {
+	return func(s *Server) { s.fixtures = f }
+}
+
+// WithPostcodesURL sends postcode lookups to a postcodes.io-compatible
+// service at url, e.g. a self-hosted mirror, instead of the public API.
+func WithPostcodesURL(url string) Option {
+	return WithPostcodeOptions(postcode.WithBaseURL(url))
+}
+
+// WithPostcodesRateLimit replaces postcode.DefaultRateLimit on postcode
+// lookups; a PerSecond of 0 removes the limit, e.g. for a self-hosted
+// mirror.
+func WithPostcodesRateLimit(l postcode.RateLimit) Option {
+	return WithPostcodeOptions(postcode.WithRateLimit(l))
+}
+
+// WithPostcodeOptions configures the postcodes.io client, e.g. with
+// postcode.WithHeader for a self-hosted instance that needs credentials.
+func WithPostcodeOptions(opts ...postcode.Option) Option {
+	return func(s *Server) { s.postcodeOpts = append(s.postcodeOpts, opts...) }
+}
+
+// WithAdminToken enables the /admin endpoints — reload, setup and
+// datasets — for requests carrying "Authorization: Bearer <token>".
+// Without it they answer 403.
+func WithAdminToken(token string) Option {
+	return func(s *Server) { s.adminToken = token }
+}
+
+// WithScoreWeights sets the coverage score weighting used for every check.
+func WithScoreWeights(w ofcom.ScoreWeights) Option {
+	return func(s *Server) { s.weights = w }
+}
+
+// WithThreshold sets the coverage fraction that counts as available for
+// every check; 0 means ofcom.CoverageThreshold.
+func WithThreshold(t float64) Option {
+	return func(s *Server) { s.threshold = t }
+}
+
+// checkerFor returns the checker for a request, logging with its request ID.
+func (s *Server) checkerFor(r *http.Request) *checker.Checker {
+	return s.checker.UsingLogger(logging.FromContext(r.Context(), s.logger))
+}
+
+// checkOptions reads check options from the query string:
+// ?operators=ee,three limits results to those operators,
+// ?estimate=false leaves postcodes missing from the dataset unestimated, and
+// ?year=2022 checks an installed dataset year instead of the current one.
+func (s *Server) checkOptions(r *http.Request) (checker.CheckOptions, error) {
+	ops, brands, err := ofcom.ParseOperatorList(r.URL.Query().Get("operators"))
+	if err != nil {
+		return checker.CheckOptions{}, err
+	}
+	opts := checker.CheckOptions{Operators: ops, Brands: brands, Weights: s.weights, Threshold: s.threshold}
+	if v := r.URL.Query().Get("estimate"); v != "" {
+		estimate, err := strconv.ParseBool(v)
+		if err != nil {
+			return checker.CheckOptions{}, fmt.Errorf("estimate must be true or false")
+		}
+		opts.NoEstimate = !estimate
+	}
+	opts.Year = r.URL.Query().Get("year")
+	if v := r.URL.Query().Get("trend"); v != "" {
+		if opts.Trend, err = strconv.ParseBool(v); err != nil {
+			return checker.CheckOptions{}, fmt.Errorf("trend must be true or false")
+		}
+	}
+	return opts, nil
+}
+
+// bulkOptions returns the server's bulk options, with fail-fast enabled by
+// ?fail_fast=true.
+func (s *Server) bulkOptions(r *http.Request) checker.BulkOptions {
+	b := s.bulk
+	if v, err := strconv.ParseBool(r.URL.Query().Get("fail_fast")); err == nil {
+		b.FailFast = v
+	}
+	return b
 }
 
 func writeJSON(w http.ResponseWriter, status int, v any) {
@@ -75,17 +692,128 @@ func writeJSON(w http.ResponseWriter, status int, v any) {
 	enc.Encode(v)
 }
 
+// writeError writes an error body whose code is derived from the HTTP
+// status, e.g. BAD_REQUEST.
 func writeError(w http.ResponseWriter, status int, msg string) {
-	writeJSON(w, status, map[string]string{"status": "error", "message": msg})
+	code := strings.ToUpper(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+	writeJSON(w, status, map[string]any{"status": "error", "code": code, "schema_version": checker.SchemaVersion, "message": msg})
+}
+
+// writeCodedError writes a failed check with its checker.ErrorCode.
+func writeCodedError(w http.ResponseWriter, code checker.ErrorCode, msg string) {
+	writeJSON(w, httpStatus(code), map[string]any{"status": "error", "code": string(code), "schema_version": checker.SchemaVersion, "message": msg})
+}
+
+// httpStatus maps a check failure to an HTTP status.
+func httpStatus(code checker.ErrorCode) int {
+	switch code {
+	case checker.CodeInvalidPostcode:
+		return http.StatusBadRequest
+	case checker.CodePostcodeNotFound, checker.CodeNotInDataset, checker.CodeYearNotInstalled, checker.CodeNationNotInstalled,
+		checker.CodeAddressNotFound:
+		return http.StatusNotFound
+	case checker.CodePostcodeTerminated:
+		return http.StatusGone
+	case checker.CodeVersionNotInstalled:
+		return http.StatusConflict
+	case checker.CodeDatasetMissing, checker.CodeDatasetOutdated:
+		return http.StatusServiceUnavailable
+	case checker.CodeUpstreamTimeout:
+		return http.StatusGatewayTimeout
+	case checker.CodeUpstreamUnavailable:
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
 }
 
-// ListenAndServe starts the HTTP server.
+// ListenAndServe starts the HTTP server, or the HTTPS server when WithTLS
+// was given.
 func (s *Server) ListenAndServe(addr string) error {
-	mux := http.NewServeMux()
-	s.Routes(mux)
-	fmt.Printf("UK Mobile Coverage API listening on http://%s\n", addr)
-	fmt.Println("  GET  /health")
-	fmt.Println("  GET  /api/mobile/{postcode}")
-	fmt.Println("  POST /api/mobile/bulk")
-	return http.ListenAndServe(addr, mux)
+	hs := &http.Server{Addr: addr, Handler: s.Handler()}
+	var challenge *http.Server
+	if s.tls.Enabled() {
+		var err error
+		if challenge, err = s.tlsServer(hs); err != nil {
+			return err
+		}
+	}
+	s.logger.Info("UK Mobile Coverage API listening", "addr", addr, "tls", s.tls.Enabled(), "cors_origins", s.cors.AllowedOrigins, "routes", []string{
+		"GET /health",
+		"GET /healthz",
+		"GET /readyz",
+		"POST /admin/reload",
+		"POST /admin/setup?year=...",
+		"GET /admin/setup",
+		"GET /admin/datasets",
+		"DELETE /admin/datasets/{year}",
+		"GET /api/mobile/{postcode}?operators=...",
+		"GET /api/mobile/{postcode}/diff?from=...&to=...",
+		"GET /api/postcodes/autocomplete?q=...",
+		"POST /api/mobile/bulk",
+		"POST /api/mobile/bulk/stream",
+		"POST /api/mobile/changed",
+		"GET /api/sites",
+		"POST /api/jobs",
+		"GET /api/jobs?status=...",
+		"GET /api/jobs/{id}",
+		"GET /api/mobile/heatmap?bbox=...&operator=...&tech=...",
+		"GET /api/mobile/nearby?lat=...&lon=...&radius=...&operator=...&tech=...",
+		"GET /api/mobile/outcode/{outcode}",
+		"GET /api/mobile/district/{name}",
+		"GET /api/mobile/region/{name}",
+		"GET /api/mobile/constituency/{name}",
+	})
+	for _, ns := range s.namespaces {
+		s.logger.Info("serving namespace", "namespace", ns.Name, "path", "/api/"+ns.Name+"/", "source", ns.Source)
+	}
+	s.mu.Lock()
+	s.httpServer, s.challengeServer = hs, challenge
+	s.mu.Unlock()
+
+	var err error
+	switch {
+	case challenge != nil:
+		s.logger.Info("answering ACME challenges", "addr", challenge.Addr)
+		errs := make(chan error, 1)
+		go func() { errs <- challenge.ListenAndServe() }()
+		go func() { errs <- hs.ListenAndServeTLS("", "") }()
+		err = <-errs
+	case hs.TLSConfig != nil:
+		err = hs.ListenAndServeTLS("", "")
+	default:
+		err = hs.ListenAndServe()
+	}
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops the servers started by ListenAndServe and ServeGRPC, which
+// then return nil, letting requests in progress finish until ctx is done.
+// Call Close afterwards to release the databases.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	hs, gs, challenge := s.httpServer, s.grpcServer, s.challengeServer
+	s.mu.Unlock()
+	if gs != nil {
+		stopped := make(chan struct{})
+		go func() {
+			gs.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			gs.Stop()
+		}
+	}
+	if challenge != nil {
+		challenge.Shutdown(ctx)
+	}
+	if hs == nil {
+		return nil
+	}
+	return hs.Shutdown(ctx)
 }
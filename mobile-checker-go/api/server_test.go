@@ -0,0 +1,331 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/mobile-checker/api"
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/sites"
+)
+
+// testCSV is a small Ofcom-style dataset for the tests.
+const testCSV = "postcode,ee_4g,o2_4g,three_4g,vodafone_4g,ee_5g\nLS11AA,1.0,1.0,1.0,1.0,0.8\nLS11AB,0.2,1.0,1.0,1.0,0.0\n"
+
+// newDataDir builds the test dataset, with geographic data for places when
+// given, and returns its data directory.
+func newDataDir(t *testing.T, places []ofcom.Place) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(testCSV), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir)
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	if places != nil {
+		if err := m.StoreGeo(places); err != nil {
+			t.Fatalf("store geo failed: %v", err)
+		}
+	}
+	return dir
+}
+
+// quietLogger discards the server's logs.
+func quietLogger() api.Option {
+	return api.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// get serves a GET of target and returns the response.
+func get(t *testing.T, h http.Handler, target string, header ...string) *http.Response {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+// decode reads a JSON response body into a map.
+func decode(t *testing.T, resp *http.Response) map[string]any {
+	t.Helper()
+	defer resp.Body.Close()
+	var body map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	return body
+}
+
+func TestArea_StatusCodes(t *testing.T) {
+	places := []ofcom.Place{
+		{Postcode: "LS11AA", AdminDistrict: "Leeds"},
+		{Postcode: "LS11AB", AdminDistrict: "Leeds"},
+	}
+	h := api.NewServer(newDataDir(t, places), quietLogger()).Handler()
+	noGeo := api.NewServer(newDataDir(t, nil), quietLogger()).Handler()
+	missing := api.NewServer(t.TempDir(), quietLogger()).Handler()
+
+	for _, tc := range []struct {
+		name    string
+		handler http.Handler
+		target  string
+		status  int
+		code    string
+	}{
+		{"found", h, "/api/mobile/district/Leeds", http.StatusOK, ""},
+		{"unknown area", h, "/api/mobile/district/Atlantis", http.StatusNotFound, "NOT_FOUND"},
+		{"invalid name", h, "/api/mobile/district/%3Cscript%3E", http.StatusBadRequest, "BAD_REQUEST"},
+		{"no geo data", noGeo, "/api/mobile/district/Leeds", http.StatusServiceUnavailable, "DATASET_MISSING"},
+		{"no dataset", missing, "/api/mobile/district/Leeds", http.StatusServiceUnavailable, "DATASET_MISSING"},
+		{"outcode without geo data", noGeo, "/api/mobile/outcode/ls1", http.StatusOK, ""},
+		{"unknown outcode", h, "/api/mobile/outcode/M1", http.StatusNotFound, "NOT_FOUND"},
+		{"invalid outcode", h, "/api/mobile/outcode/QQ1", http.StatusBadRequest, "INVALID_POSTCODE"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := get(t, tc.handler, tc.target)
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected %d, got %d", tc.status, resp.StatusCode)
+			}
+			body := decode(t, resp)
+			if code, _ := body["code"].(string); code != tc.code {
+				t.Errorf("expected code %q, got %q", tc.code, code)
+			}
+		})
+	}
+}
+
+func TestReload_RequiresAdminToken(t *testing.T) {
+	dir := newDataDir(t, nil)
+	disabled := api.NewServer(dir, quietLogger()).Handler()
+	h := api.NewServer(dir, quietLogger(), api.WithAdminToken("s3cret")).Handler()
+
+	for _, tc := range []struct {
+		name    string
+		handler http.Handler
+		auth    string
+		want    int
+	}{
+		{"no token configured", disabled, "Bearer s3cret", http.StatusForbidden},
+		{"missing", h, "", http.StatusUnauthorized},
+		{"wrong", h, "Bearer guess", http.StatusUnauthorized},
+		{"not bearer", h, "Basic s3cret", http.StatusUnauthorized},
+		{"valid", h, "Bearer s3cret", http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/admin/reload", nil)
+			if tc.auth != "" {
+				req.Header.Set("Authorization", tc.auth)
+			}
+			rec := httptest.NewRecorder()
+			tc.handler.ServeHTTP(rec, req)
+			if rec.Code != tc.want {
+				t.Fatalf("expected %d, got %d: %s", tc.want, rec.Code, rec.Body)
+			}
+			if tc.want == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected a WWW-Authenticate challenge")
+			}
+		})
+	}
+}
+
+func TestFixtures_ServeWithoutDataset(t *testing.T) {
+	fixtures := checker.Fixtures{"SW1A1AA": {Postcode: "SW1A1AA", Valid: true, Mobile: &ofcom.MobileSummary{CoverageScore: 90}}}
+	h := api.NewServer(t.TempDir(), quietLogger(), api.WithFixtures(fixtures)).Handler()
+
+	if resp := get(t, h, "/readyz"); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected fixtures to be ready without a dataset, got %d", resp.StatusCode)
+	}
+	body := decode(t, get(t, h, "/api/mobile/SW1A1AA"))
+	if mobile, _ := body["result"].(map[string]any)["mobile"].(map[string]any); mobile["CoverageScore"] != float64(90) {
+		t.Errorf("expected the fixture, got %v", body)
+	}
+	if resp := get(t, h, "/api/mobile/LS11AA"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for a postcode without a fixture, got %d", resp.StatusCode)
+	}
+}
+
+func TestDiff_Years(t *testing.T) {
+	dir := newDataDir(t, nil)
+	older := filepath.Join(dir, "years", "2022", "ofcom_mobile_2022.csv")
+	if err := os.MkdirAll(filepath.Dir(older), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(older, []byte("postcode,ee_4g\nLS11AA,0.1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ofcom.NewManager(dir).Setup("2022", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup 2022 failed: %v", err)
+	}
+	h := api.NewServer(dir, quietLogger(), api.WithOffline()).Handler()
+
+	resp := get(t, h, "/api/mobile/LS11AA/diff?from=2022&to=2023")
+	if body := decode(t, resp); resp.StatusCode != http.StatusOK || body["status"] != "ok" {
+		t.Fatalf("expected a diff, got %d %v", resp.StatusCode, body)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" || resp.Header.Get("Last-Modified") == "" {
+		t.Fatal("expected validators on a diff")
+	}
+	if resp := get(t, h, "/api/mobile/LS11AA/diff?from=2022&to=2023", "If-Modified-Since", resp.Header.Get("Last-Modified")); resp.StatusCode != http.StatusNotModified {
+		t.Errorf("expected 304 for an unchanged diff, got %d", resp.StatusCode)
+	}
+	if other := get(t, h, "/api/mobile/LS11AA/diff?from=2023&to=2022").Header.Get("ETag"); other == etag {
+		t.Error("expected a different ETag with the years swapped")
+	}
+	// Years name directories, so anything but an installed year is refused
+	// before it reaches the filesystem.
+	for _, target := range []string{
+		"/api/mobile/LS11AA/diff?from=../2022&to=2023",
+		"/api/mobile/LS11AA/diff?from=2022&to=years",
+		"/api/mobile/LS11AA/diff?from=2019&to=2023",
+	} {
+		resp := get(t, h, target)
+		if body := decode(t, resp); resp.StatusCode != http.StatusNotFound || body["code"] != "YEAR_NOT_INSTALLED" {
+			t.Errorf("%s: expected 404 YEAR_NOT_INSTALLED, got %d %v", target, resp.StatusCode, body)
+		}
+	}
+}
+
+func TestChanged_ReturnsPostcodesChangedSinceVersion(t *testing.T) {
+	dir := newDataDir(t, nil)
+	older := filepath.Join(dir, "years", "2022", "ofcom_mobile_2022.csv")
+	if err := os.MkdirAll(filepath.Dir(older), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(older, []byte("postcode,ee_4g,o2_4g,three_4g,vodafone_4g,ee_5g\nLS11AA,1.0,1.0,1.0,1.0,0.1\nLS11AB,0.2,1.0,1.0,1.0,0.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ofcom.NewManager(dir).Setup("2022", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup 2022 failed: %v", err)
+	}
+	h := api.NewServer(dir, quietLogger(), api.WithOffline()).Handler()
+	dataset := decode(t, get(t, h, "/api/mobile/LS11AA?year=2022"))["dataset"].(map[string]any)
+	version, _ := dataset["version"].(string)
+	if !strings.HasPrefix(version, "2022@") {
+		t.Fatalf("expected a 2022 version, got %v", dataset)
+	}
+
+	post := func(body string) (*http.Response, map[string]any) {
+		req := httptest.NewRequest(http.MethodPost, "/api/mobile/changed", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Result(), decode(t, rec.Result())
+	}
+	resp, body := post(`{"postcodes":["LS11AA","LS11AB"],"version":"` + version + `"}`)
+	results, _ := body["results"].([]any)
+	if resp.StatusCode != http.StatusOK || len(results) != 1 || results[0].(map[string]any)["postcode"] != "LS11AA" {
+		t.Fatalf("expected only LS11AA, got %d %v", resp.StatusCode, body)
+	}
+	if got := body["dataset"].(map[string]any)["version"]; got == version {
+		t.Error("expected the current version to compare against next time")
+	}
+
+	resp, body = post(`{"postcodes":["LS11AA"],"version":"2022@2001-01-01T00:00:00Z"}`)
+	if resp.StatusCode != http.StatusConflict || body["code"] != "VERSION_NOT_INSTALLED" {
+		t.Errorf("expected 409 VERSION_NOT_INSTALLED, got %d %v", resp.StatusCode, body)
+	}
+	if resp, _ := post(`{"postcodes":["LS11AA"]}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 without a version, got %d", resp.StatusCode)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	srv := api.NewServer(newDataDir(t, nil), quietLogger())
+	errs := make(chan error, 2)
+	go func() { errs <- srv.ListenAndServe("127.0.0.1:0") }()
+	go func() { errs <- srv.ServeGRPC("127.0.0.1:0") }()
+
+	// Shut down until both servers have started and returned.
+	tick := time.NewTicker(10 * time.Millisecond)
+	defer tick.Stop()
+	timeout := time.After(5 * time.Second)
+	for stopped := 0; stopped < 2; {
+		select {
+		case err := <-errs:
+			if err != nil {
+				t.Fatalf("expected the server to stop cleanly, got %v", err)
+			}
+			stopped++
+		case <-tick.C:
+			if err := srv.Shutdown(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+		case <-timeout:
+			t.Fatal("servers did not stop")
+		}
+	}
+	if err := srv.Close(); err != nil {
+		t.Errorf("close failed: %v", err)
+	}
+}
+
+func TestSites_ChecksSavedSites(t *testing.T) {
+	dir := newDataDir(t, nil)
+	h := api.NewServer(dir, quietLogger(), api.WithOffline()).Handler()
+	if body := decode(t, get(t, h, "/api/sites")); body["status"] != "ok" || body["message"] != "no sites saved" {
+		t.Errorf("expected an empty answer before any site is saved, got %v", body)
+	}
+
+	store := sites.New(dir)
+	for name, pc := range map[string]string{"home": "LS1 1AA", "office": "LS11AB"} {
+		if _, _, err := store.Add(name, pc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	body := decode(t, get(t, h, "/api/sites"))
+	results, _ := body["results"].([]any)
+	if len(results) != 2 {
+		t.Fatalf("expected two sites, got %v", body)
+	}
+	got := map[string]string{}
+	for _, r := range results {
+		r := r.(map[string]any)
+		got[r["site"].(string)] = r["postcode"].(string)
+	}
+	if got["home"] != "LS11AA" || got["office"] != "LS11AB" {
+		t.Errorf("expected results labelled by site, got %v", got)
+	}
+
+	resp := get(t, h, "/api/sites?format=csv")
+	b, _ := io.ReadAll(resp.Body)
+	if !strings.HasPrefix(string(b), "site,postcode,") {
+		t.Errorf("expected CSV led by a site column, got %q", b)
+	}
+}
+
+func TestBulk_SummarisesItemStatuses(t *testing.T) {
+	h := api.NewServer(newDataDir(t, nil), quietLogger(), api.WithOffline()).Handler()
+	req := httptest.NewRequest(http.MethodPost, "/api/mobile/bulk", strings.NewReader(`{"postcodes":["LS11AA","LS11AB","not a postcode","LS11AZ"]}`))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	body := decode(t, rec.Result())
+
+	var statuses []string
+	for _, r := range body["results"].([]any) {
+		statuses = append(statuses, r.(map[string]any)["status"].(string))
+	}
+	// Offline, a postcode missing from the dataset cannot be told apart
+	// from one postcodes.io would have to look up.
+	if strings.Join(statuses, ",") != "ok,ok,invalid,error" {
+		t.Errorf("unexpected item statuses %v", statuses)
+	}
+	want := map[string]any{"total": 4.0, "ok": 2.0, "invalid": 1.0, "not_found": 0.0, "error": 1.0}
+	if got := body["summary"]; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected summary %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+)
+
+// GET /api/sites — checks every site saved with 'mobile-checker sites add'
+// in the server's data directory, each result carrying its site's name.
+// Takes the same query parameters as /api/mobile/bulk.
+func (s *Server) handleSites(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, r, http.StatusMethodNotAllowed, "GET required")
+		return
+	}
+	list, err := s.sites.List()
+	if err != nil {
+		s.respondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(list) == 0 {
+		s.respond(w, r, http.StatusOK, envelope{Status: "ok", Message: "no sites saved", Results: []checker.Result{}})
+		return
+	}
+	opts, err := s.checkOptions(r)
+	if err != nil {
+		s.respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	results := s.checkerFor(r).CheckSites(r.Context(), list, opts, s.bulkOptions(r))
+	s.respond(w, r, http.StatusOK, envelope{Status: "ok", Results: results, Summary: checker.Summarise(results)})
+}
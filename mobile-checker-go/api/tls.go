@@ -0,0 +1,137 @@
+package api
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// TLSConfig serves the REST API over HTTPS, from certificate files or with
+// certificates obtained automatically from Let's Encrypt.
+type TLSConfig struct {
+	// CertFile and KeyFile are a PEM certificate chain and its private key.
+	// They are reloaded when either file changes, so a renewed certificate
+	// is picked up without a restart.
+	CertFile string
+	KeyFile  string
+	// AutocertDomains are the host names to obtain Let's Encrypt
+	// certificates for, instead of CertFile and KeyFile. The server must
+	// be reachable on port 443 under each of them.
+	AutocertDomains []string
+	// AutocertCacheDir stores obtained certificates across restarts.
+	AutocertCacheDir string
+	// AutocertEmail is given to Let's Encrypt for expiry notices.
+	AutocertEmail string
+	// HTTPAddr, with AutocertDomains, also serves plain HTTP there (usually
+	// ":80"): ACME http-01 challenges, and a redirect to HTTPS for everything
+	// else.
+	HTTPAddr string
+}
+
+// Enabled reports whether cfg turns HTTPS on.
+func (cfg TLSConfig) Enabled() bool {
+	return cfg.CertFile != "" || cfg.KeyFile != "" || len(cfg.AutocertDomains) > 0
+}
+
+// Validate reports a configuration that cannot be served.
+func (cfg TLSConfig) Validate() error {
+	files := cfg.CertFile != "" || cfg.KeyFile != ""
+	switch {
+	case files && len(cfg.AutocertDomains) > 0:
+		return errors.New("use either a TLS certificate and key or autocert domains, not both")
+	case files && (cfg.CertFile == "" || cfg.KeyFile == ""):
+		return errors.New("a TLS certificate and key must be given together")
+	case len(cfg.AutocertDomains) > 0 && cfg.AutocertCacheDir == "":
+		return errors.New("autocert needs a cache directory")
+	case cfg.HTTPAddr != "" && len(cfg.AutocertDomains) == 0:
+		return errors.New("the HTTP challenge address is only used with autocert domains")
+	}
+	return nil
+}
+
+// WithTLS serves the REST API over HTTPS; see TLSConfig. The gRPC API is
+// unaffected.
+func WithTLS(cfg TLSConfig) Option {
+	return func(s *Server) { s.tls = cfg }
+}
+
+// tlsServer configures hs for HTTPS and returns the plain HTTP server for
+// ACME challenges, if one is wanted.
+func (s *Server) tlsServer(hs *http.Server) (*http.Server, error) {
+	cfg := s.tls
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.CertFile != "" {
+		certs := &certReloader{certFile: cfg.CertFile, keyFile: cfg.KeyFile}
+		if _, err := certs.get(nil); err != nil {
+			return nil, err
+		}
+		hs.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12, GetCertificate: certs.get}
+		return nil, nil
+	}
+
+	m := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(cfg.AutocertDomains...),
+		Cache:      autocert.DirCache(cfg.AutocertCacheDir),
+		Email:      cfg.AutocertEmail,
+	}
+	hs.TLSConfig = m.TLSConfig()
+	hs.TLSConfig.MinVersion = tls.VersionTLS12
+	if cfg.HTTPAddr == "" {
+		return nil, nil
+	}
+	return &http.Server{Addr: cfg.HTTPAddr, Handler: m.HTTPHandler(nil), ReadHeaderTimeout: 10 * time.Second}, nil
+}
+
+// certReloader serves a certificate from files, reloading them when they
+// change.
+type certReloader struct {
+	certFile, keyFile string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+// get returns the current certificate, for tls.Config.GetCertificate. A
+// renewal that fails to load keeps the previous certificate in service.
+func (c *certReloader) get(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	modTime, err := latestModTime(c.certFile, c.keyFile)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cert != nil && (err != nil || !modTime.After(c.modTime)) {
+		return c.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		if c.cert != nil {
+			return c.cert, nil
+		}
+		return nil, fmt.Errorf("loading TLS certificate: %w", err)
+	}
+	c.cert, c.modTime = &cert, modTime
+	return c.cert, nil
+}
+
+// latestModTime returns the newest modification time of files.
+func latestModTime(files ...string) (time.Time, error) {
+	var latest time.Time
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,134 @@
+package api_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourusername/mobile-checker/api"
+)
+
+// writeCert writes a self-signed certificate for name and its key to dir,
+// dated modTime.
+func writeCert(t *testing.T, dir, name string, modTime time.Time) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	for path, block := range map[string]*pem.Block{
+		certFile: {Type: "CERTIFICATE", Bytes: der},
+		keyFile:  {Type: "EC PRIVATE KEY", Bytes: keyDER},
+	} {
+		if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return certFile, keyFile
+}
+
+func TestCertificateLoader_ReloadsRenewedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().Add(-time.Hour)
+	certFile, keyFile := writeCert(t, dir, "old.example.com", start)
+	get := api.CertificateLoader(certFile, keyFile)
+
+	subject := func() string {
+		t.Helper()
+		cert, err := get(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leaf.Subject.CommonName
+	}
+	if got := subject(); got != "old.example.com" {
+		t.Fatalf("expected the old certificate, got %q", got)
+	}
+
+	writeCert(t, dir, "new.example.com", start.Add(time.Minute))
+	if got := subject(); got != "new.example.com" {
+		t.Errorf("expected the renewed certificate, got %q", got)
+	}
+
+	// A half-written renewal keeps the working certificate in service.
+	if err := os.WriteFile(keyFile, []byte("not a key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got := subject(); got != "new.example.com" {
+		t.Errorf("expected the previous certificate to be kept, got %q", got)
+	}
+
+	if _, err := api.CertificateLoader(filepath.Join(dir, "missing.pem"), keyFile)(nil); err == nil {
+		t.Error("expected an error for a missing certificate")
+	}
+}
+
+func TestTLSConfig_Validate(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		cfg   api.TLSConfig
+		valid bool
+	}{
+		{"disabled", api.TLSConfig{}, true},
+		{"files", api.TLSConfig{CertFile: "c.pem", KeyFile: "k.pem"}, true},
+		{"cert without key", api.TLSConfig{CertFile: "c.pem"}, false},
+		{"autocert", api.TLSConfig{AutocertDomains: []string{"example.com"}, AutocertCacheDir: "/tmp/a", HTTPAddr: ":80"}, true},
+		{"autocert without cache", api.TLSConfig{AutocertDomains: []string{"example.com"}}, false},
+		{"files and autocert", api.TLSConfig{CertFile: "c.pem", KeyFile: "k.pem", AutocertDomains: []string{"example.com"}, AutocertCacheDir: "/tmp/a"}, false},
+		{"challenge address without autocert", api.TLSConfig{CertFile: "c.pem", KeyFile: "k.pem", HTTPAddr: ":80"}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.cfg.Validate(); (err == nil) != tc.valid {
+				t.Errorf("expected valid=%v, got %v", tc.valid, err)
+			}
+		})
+	}
+}
+
+func TestSecurityHeaders_HSTSOverHTTPS(t *testing.T) {
+	h := api.NewServer(newDataDir(t, nil), quietLogger()).Handler()
+	if got := get(t, h, "/healthz").Header.Get("Strict-Transport-Security"); got != "" {
+		t.Errorf("expected no HSTS over HTTP, got %q", got)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.TLS = &tls.ConnectionState{}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if got := rec.Header().Get("Strict-Transport-Security"); got == "" {
+		t.Error("expected HSTS over HTTPS")
+	}
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed ui
+var uiFiles embed.FS
+
+// uiCSP relaxes the API's Content-Security-Policy for the web UI: its own
+// script and styles, calls back to the API, and OpenStreetMap tiles.
+const uiCSP = "default-src 'none'; script-src 'self'; style-src 'self'; connect-src 'self'; " +
+	"img-src 'self' https://tile.openstreetmap.org; frame-ancestors 'none'"
+
+// handleUI serves the single-page web UI at / from files embedded in the
+// binary. Paths that match no file get a 404, as before.
+func (s *Server) handleUI() http.Handler {
+	sub, _ := fs.Sub(uiFiles, "ui")
+	files := http.FileServer(http.FS(sub))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		w.Header().Set("Content-Security-Policy", uiCSP)
+		// OpenStreetMap's tile policy asks for a Referer.
+		w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
+		files.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,153 @@
+// Single-page UI for the mobile checker API. It only calls the JSON
+// endpoints served alongside it.
+"use strict";
+
+const $ = (id) => document.getElementById(id);
+
+const ZOOM = 15;
+const TILE = 256;
+
+function setStatus(text, isError) {
+  $("status").textContent = text;
+  $("status").className = isError ? "error" : "";
+}
+
+function cell(tier, ok, pct) {
+  const td = document.createElement("td");
+  td.textContent = (ok ? "✓ " : "✗ ") + pct;
+  if (tier) {
+    td.className = tier;
+    td.title = tier;
+  }
+  return td;
+}
+
+function showResult(result) {
+  const geo = result.geographic;
+  $("place").textContent = geo
+    ? `${result.postcode} — ${geo.admin_district}, ${geo.region || geo.country}`
+    : result.postcode;
+  $("note").textContent = result.note || "";
+
+  const body = $("operators");
+  body.replaceChildren();
+  const mobile = result.mobile;
+  if (mobile) {
+    for (const op of mobile.Operators) {
+      const tr = document.createElement("tr");
+      const name = document.createElement("td");
+      if (op.DetailURL) {
+        // The operator's own checker, to cross-check Ofcom's figures.
+        const a = document.createElement("a");
+        a.href = op.DetailURL;
+        a.target = "_blank";
+        a.rel = "noopener";
+        a.textContent = op.Name;
+        name.append(a);
+      } else {
+        name.textContent = op.Name;
+      }
+      const tiers = op.Tiers || {};
+      tr.append(
+        name,
+        cell(tiers.voice, op.HasVoice, op.Voice),
+        cell(tiers["4g"], op.HasFourG, op.FourG),
+        cell(tiers["5g"], op.HasFiveG, op.FiveG),
+      );
+      body.append(tr);
+    }
+    $("score").textContent = `Coverage score: ${mobile.CoverageScore}/100 (grade ${mobile.Grade})`;
+  } else {
+    $("score").textContent = "";
+  }
+
+  // Unhide first so the map has a size to lay tiles out in.
+  $("result").hidden = false;
+  if (geo && (geo.latitude || geo.longitude)) {
+    showMap(geo.latitude, geo.longitude);
+  } else {
+    $("map").hidden = true;
+  }
+}
+
+// showMap lays out the OpenStreetMap tiles around lat/lon so that the
+// point sits under the pin at the centre of the map.
+function showMap(lat, lon) {
+  const map = $("map");
+  map.hidden = false;
+  const n = 2 ** ZOOM;
+  const rad = (lat * Math.PI) / 180;
+  const x = ((lon + 180) / 360) * n;
+  const y = ((1 - Math.log(Math.tan(rad) + 1 / Math.cos(rad)) / Math.PI) / 2) * n;
+
+  const tiles = $("tiles");
+  tiles.replaceChildren();
+  const w = map.clientWidth;
+  const h = map.clientHeight;
+  const cx = Math.floor(x);
+  const cy = Math.floor(y);
+  const span = Math.ceil(Math.max(w, h) / TILE / 2) + 1;
+  for (let dx = -span; dx <= span; dx++) {
+    for (let dy = -1; dy <= 1; dy++) {
+      const img = document.createElement("img");
+      img.alt = "";
+      img.src = `https://tile.openstreetmap.org/${ZOOM}/${cx + dx}/${cy + dy}.png`;
+      img.style.left = `${(cx + dx - x) * TILE + w / 2}px`;
+      img.style.top = `${(cy + dy - y) * TILE + h / 2}px`;
+      tiles.append(img);
+    }
+  }
+}
+
+async function check(pc) {
+  setStatus("Checking…");
+  $("result").hidden = true;
+  try {
+    const resp = await fetch(`/api/mobile/${encodeURIComponent(pc)}`);
+    const body = await resp.json();
+    if (body.status === "ok" || body.result) {
+      setStatus(body.message || "", body.status !== "ok");
+      showResult(body.result);
+    } else {
+      setStatus(body.message || `Request failed (${resp.status})`, true);
+    }
+  } catch (err) {
+    setStatus(`Request failed: ${err.message}`, true);
+  }
+}
+
+let suggestTimer;
+$("postcode").addEventListener("input", (e) => {
+  clearTimeout(suggestTimer);
+  const q = e.target.value.trim();
+  if (q.length < 2) {
+    return;
+  }
+  suggestTimer = setTimeout(async () => {
+    try {
+      const resp = await fetch(`/api/postcodes/autocomplete?q=${encodeURIComponent(q)}`);
+      const body = await resp.json();
+      const list = $("suggestions");
+      list.replaceChildren(...(body.postcodes || []).map((pc) => {
+        const opt = document.createElement("option");
+        opt.value = pc;
+        return opt;
+      }));
+    } catch {
+      // Suggestions are optional; checks still work without them.
+    }
+  }, 250);
+});
+
+$("search").addEventListener("submit", (e) => {
+  e.preventDefault();
+  const pc = $("postcode").value.trim();
+  history.replaceState(null, "", `?postcode=${encodeURIComponent(pc)}`);
+  check(pc);
+});
+
+const initial = new URLSearchParams(location.search).get("postcode");
+if (initial) {
+  $("postcode").value = initial;
+  check(initial);
+}
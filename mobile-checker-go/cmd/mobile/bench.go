@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/mobile-checker/internal/checker"
+)
+
+func newBenchCmd(dataDir *string) *cobra.Command {
+	var n, concurrency int
+	var online, jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "bench [postcode...]",
+		Short: "Measure check throughput and latency",
+		Long: "Check postcodes as fast as the checker allows and report checks per second\n" +
+			"and latency percentiles, overall and split between geocoding and the dataset\n" +
+			"query. Postcodes are sampled at random from the database unless given, and\n" +
+			"repeated to make up --postcodes. The JSON report is meant for tracking\n" +
+			"performance across releases; interrupt to report what has been measured.\n\n" +
+			"Postcodes are geocoded from data stored by setup --onspd or --geocode, so\n" +
+			"the figures measure the checker; --online uses postcodes.io instead, where\n" +
+			"--postcodes-rate rather than the checker bounds throughput.",
+		Example: "  mobile-checker bench --postcodes 10000 --concurrency 64\n" +
+			"  mobile-checker bench --json > bench.json\n" +
+			"  mobile-checker bench --online --postcodes 100 --concurrency 4\n" +
+			"  mobile-checker bench --source remote --remote-db https://example.com/mobile.db",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if n <= 0 || concurrency <= 0 {
+				return fmt.Errorf("--postcodes and --concurrency must be positive")
+			}
+			copts := []checker.Option{withPostcodes(), withEncryption(), withSource()}
+			if !online {
+				copts = append(copts, checker.WithOffline())
+			}
+			c := checker.New(*dataDir, copts...)
+			defer c.Close()
+
+			pool := args
+			if len(pool) == 0 {
+				var err error
+				if pool, err = c.SamplePostcodes(n); err != nil {
+					return err
+				}
+				if len(pool) == 0 {
+					return fmt.Errorf("the database has no postcodes to sample: give some as arguments")
+				}
+			}
+			postcodes := make([]string, n)
+			for i := range postcodes {
+				postcodes[i] = pool[i%len(pool)]
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			rep, err := c.Bench(ctx, postcodes, checker.BenchOptions{Concurrency: concurrency})
+			if err != nil {
+				return err
+			}
+			if online {
+				rep.RateLimit = postcodesRate
+			}
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(rep)
+			}
+			printBenchReport(rep)
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&n, "postcodes", 1000, "Number of checks to run")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 64, "Checks in flight at once")
+	cmd.Flags().BoolVar(&online, "online", false, "Geocode with postcodes.io, held to --postcodes-rate, instead of stored data")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the report as JSON")
+	return cmd
+}
+
+func printBenchReport(r *checker.BenchReport) {
+	sep := rule(52)
+	geocoding := "stored data (offline)"
+	switch {
+	case r.Online && r.RateLimit > 0:
+		geocoding = fmt.Sprintf("postcodes.io, at most %g requests/s", r.RateLimit)
+	case r.Online:
+		geocoding = "postcodes.io, no rate limit"
+	}
+	fmt.Printf("\n%s\n  Dataset:     %s\n  Driver:      %s\n  Geocoding:   %s\n  Checks:      %d (%d errors)\n  Concurrency: %d\n  Elapsed:     %.2fs\n  Throughput:  %.1f checks/s\n%s\n",
+		sep, orDash(r.Dataset), r.Driver, geocoding, r.Checks, r.Errors, r.Concurrency, r.ElapsedSeconds, r.ChecksPerSecond, sep)
+	fmt.Printf("  %-9s %9s %9s %9s %9s %9s\n", "ms", "p50", "p90", "p99", "max", "mean")
+	for _, row := range []struct {
+		name string
+		l    checker.Latency
+	}{{"Check", r.Latency}, {"Geocode", r.Geocode}, {"Query", r.Query}} {
+		fmt.Printf("  %-9s %9.2f %9.2f %9.2f %9.2f %9.2f\n", row.name, row.l.P50, row.l.P90, row.l.P99, row.l.Max, row.l.Mean)
+	}
+	fmt.Println(sep)
+}
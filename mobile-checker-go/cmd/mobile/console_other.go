@@ -0,0 +1,6 @@
+//go:build !windows
+
+package main
+
+// consoleUTF8 reports true: outside Windows the locale decides.
+func consoleUTF8() bool { return true }
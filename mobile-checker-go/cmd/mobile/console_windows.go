@@ -0,0 +1,15 @@
+//go:build windows
+
+package main
+
+import "golang.org/x/sys/windows"
+
+var getConsoleOutputCP = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetConsoleOutputCP")
+
+// consoleUTF8 reports whether the console uses the UTF-8 code page (65001).
+// Without a console, e.g. when output is redirected by a service, it
+// reports true.
+func consoleUTF8() bool {
+	cp, _, _ := getConsoleOutputCP.Call()
+	return cp == 0 || cp == 65001
+}
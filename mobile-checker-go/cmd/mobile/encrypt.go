@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/config"
+	"github.com/yourusername/mobile-checker/internal/history"
+	"github.com/yourusername/mobile-checker/internal/monitor"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+// encryptKeyFile is --encrypt-key-file, the file holding the passphrase
+// the database and check history are encrypted at rest with.
+var encryptKeyFile string
+
+// encryptKey is the passphrase, from --encrypt-key-file or else
+// $MOBILE_CHECKER_ENCRYPT_KEY; empty leaves them unencrypted. There is no
+// flag for the passphrase itself, as command lines are visible to other
+// users and kept in shell history.
+var encryptKey string
+
+// parseEncryptFlags reads the passphrase.
+func parseEncryptFlags() error {
+	if encryptKeyFile == "" {
+		encryptKey = os.Getenv(config.EnvName("encrypt-key"))
+		return nil
+	}
+	b, err := os.ReadFile(encryptKeyFile)
+	if err != nil {
+		return fmt.Errorf("reading encryption key: %w", err)
+	}
+	if encryptKey = strings.TrimSpace(string(b)); encryptKey == "" {
+		return fmt.Errorf("%s is empty", encryptKeyFile)
+	}
+	return nil
+}
+
+// withEncryption gives a Checker the encryption key.
+func withEncryption() checker.Option {
+	return checker.WithEncryptionKey(encryptKey)
+}
+
+// newManager is ofcom.NewManager with the encryption key.
+func newManager(dataDir string, opts ...ofcom.Option) *ofcom.Manager {
+	return ofcom.NewManager(dataDir, append(opts, ofcom.WithEncryptionKey(encryptKey))...)
+}
+
+// newHistory opens the check history with the encryption key.
+func newHistory(dataDir string) *history.Store {
+	return history.New(dataDir, history.WithEncryptionKey(encryptKey))
+}
+
+// newMonitor is monitor.New with the encryption key.
+func newMonitor(dataDir string) *monitor.Monitor {
+	return monitor.New(dataDir, monitor.WithEncryptionKey(encryptKey))
+}
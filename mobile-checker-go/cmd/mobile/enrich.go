@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func newEnrichCmd(dataDir *string) *cobra.Command {
+	var in, out, column, operators, fallbackURL string
+	var offline bool
+	var bulk checker.BulkOptions
+
+	cmd := &cobra.Command{
+		Use:   "enrich",
+		Short: "Append coverage columns to a CSV with a postcode column",
+		Long: "Check the postcode on every row of a CSV, e.g. a customer list, and write the\n" +
+			"CSV back out with coverage score, grade and per-operator voice, 4G and 5G\n" +
+			"columns appended. Rows keep their order; rows without a postcode get empty\n" +
+			"coverage columns.",
+		Args: cobra.NoArgs,
+		Example: "  mobile-checker enrich --in customers.csv --postcode-column Postcode --out enriched.csv\n" +
+			"  mobile-checker enrich --in - --operator ee,giffgaff < sites.csv > sites-coverage.csv",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ops, brands, err := ofcom.ParseOperatorList(operators)
+			if err != nil {
+				return err
+			}
+			if len(ops) == 0 {
+				ops = ofcom.Operators
+			}
+			if out != "" && out != "-" && out == in {
+				return fmt.Errorf("--out must differ from --in")
+			}
+
+			var r io.Reader = os.Stdin
+			if in != "-" {
+				f, err := os.Open(in)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				r = f
+			}
+			header, rows, err := readCSV(r)
+			if err != nil {
+				return fmt.Errorf("reading %s: %w", in, err)
+			}
+			col, err := findColumn(header, column)
+			if err != nil {
+				return err
+			}
+
+			var w io.Writer = os.Stdout
+			if out != "" && out != "-" {
+				f, err := os.Create(out)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+
+			copts := []checker.Option{withPostcodes(), withEncryption(), withSource()}
+			if offline {
+				copts = append(copts, checker.WithOffline())
+			}
+			if fallbackURL != "" {
+				copts = append(copts, checker.WithFallback(fallbackURL))
+			}
+			c := checker.New(*dataDir, copts...)
+			defer c.Close()
+
+			n, missing, err := enrich(context.Background(), c, w, header, rows, col, ops,
+				checker.CheckOptions{Operators: ops, Brands: brands}, bulk)
+			logThrottling(c)
+			if err != nil {
+				return err
+			}
+			if out != "" && out != "-" {
+				notef("%s Enriched %d rows (%d without coverage) to %s\n", glyphs.Yes, n, missing, out)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&in, "in", "", "CSV to enrich, with a header row (- for stdin)")
+	cmd.Flags().StringVar(&out, "out", "", "Where to write the enriched CSV (default stdout)")
+	cmd.Flags().StringVar(&column, "postcode-column", "postcode", "Header of the column holding postcodes (case-insensitive)")
+	cmd.Flags().StringVar(&operators, "operator", "", "Only add columns for these operators or MVNO brands, comma-separated, e.g. ee,three or giffgaff")
+	cmd.Flags().IntVar(&bulk.Workers, "workers", checker.DefaultWorkers, "Concurrent checks")
+	cmd.Flags().DurationVar(&bulk.Timeout, "timeout", 0, "Give up on a single postcode after this long, e.g. 10s (no limit when 0)")
+	cmd.Flags().BoolVar(&offline, "offline", false, "Never call postcodes.io; use geographic data stored by setup --onspd or --geocode")
+	cmd.Flags().StringVar(&fallbackURL, "fallback-url", "", "mobile-checker server to ask while the local dataset is missing")
+	cmd.MarkFlagRequired("in")
+	return cmd
+}
+
+// readCSV reads a header row and the rows after it. Rows may have more or
+// fewer fields than the header, as spreadsheet exports often do.
+func readCSV(r io.Reader) (header []string, rows [][]string, err error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err = cr.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("no header row")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff") // Excel's UTF-8 BOM
+	}
+	rows, err = cr.ReadAll()
+	return header, rows, err
+}
+
+// findColumn returns the index of the header named name, ignoring case
+// and surrounding space.
+func findColumn(header []string, name string) (int, error) {
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(h), strings.TrimSpace(name)) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("no %q column (columns: %s); set --postcode-column", name, strings.Join(header, ", "))
+}
+
+// coverageColumns are the headers enrich appends, with per-operator
+// columns for ops.
+func coverageColumns(ops []string) []string {
+	cols := []string{"coverage_score", "coverage_grade", "operators_4g", "operators_5g"}
+	for _, op := range ops {
+		cols = append(cols, op+"_voice", op+"_4g", op+"_5g")
+	}
+	return append(cols, "coverage_code", "coverage_note")
+}
+
+// coverageCells are the values of coverageColumns for r; nil, for a row
+// without a postcode, gives empty cells.
+func coverageCells(r *checker.Result, ops []string) []string {
+	cells := make([]string, len(coverageColumns(ops)))
+	if r == nil {
+		return cells
+	}
+	if m := r.Mobile; m != nil {
+		cells[0], cells[1] = strconv.Itoa(m.CoverageScore), m.Grade
+		cells[2], cells[3] = strconv.Itoa(m.Overall.FourGCount), strconv.Itoa(m.Overall.FiveGCount)
+		for i, op := range ops {
+			for _, cov := range m.Operators {
+				if strings.EqualFold(cov.Name, op) {
+					copy(cells[4+3*i:], []string{cov.Voice, cov.FourG, cov.FiveG})
+				}
+			}
+		}
+	}
+	msg := r.Error
+	if msg == "" {
+		msg = r.Note
+	}
+	cells[len(cells)-2], cells[len(cells)-1] = string(r.Code), msg
+	return cells
+}
+
+// enrich checks the postcode in column col of each row and writes the rows
+// to w, in their input order, with coverageColumns appended. It returns
+// the number of rows written and how many had no coverage data.
+func enrich(ctx context.Context, c *checker.Checker, w io.Writer, header []string, rows [][]string, col int, ops []string, opts checker.CheckOptions, bulk checker.BulkOptions) (n, missing int, err error) {
+	cw := csv.NewWriter(w)
+	cw.Write(append(header, coverageColumns(ops)...))
+
+	// Rows without a postcode are not checked; they are written as the
+	// rows around them are.
+	var pcs []string
+	var rowOf []int
+	for i, row := range rows {
+		if col < len(row) && strings.TrimSpace(row[col]) != "" {
+			pcs = append(pcs, row[col])
+			rowOf = append(rowOf, i)
+		}
+	}
+	next := 0
+	write := func(r *checker.Result) error {
+		if r == nil || r.Mobile == nil {
+			missing++
+		}
+		row := rows[next]
+		for len(row) < len(header) {
+			row = append(row, "") // keep the coverage columns aligned
+		}
+		cw.Write(append(row, coverageCells(r, ops)...))
+		next++
+		return cw.Error()
+	}
+	k := 0
+	err = c.CheckBulkFunc(ctx, pcs, opts, bulk, func(r checker.Result) error {
+		for next < rowOf[k] {
+			if err := write(nil); err != nil {
+				return err
+			}
+		}
+		k++
+		return write(&r)
+	})
+	for err == nil && next < len(rows) {
+		err = write(nil)
+	}
+	if err != nil {
+		return next, missing, err
+	}
+	cw.Flush()
+	return next, missing, cw.Error()
+}
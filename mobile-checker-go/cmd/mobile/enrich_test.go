@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func TestEnrich_AppendsCoverageInRowOrder(t *testing.T) {
+	dir := t.TempDir()
+	csv := "postcode,ee_4g,o2_4g\nLS11AA,1.0,0.2\nLS11AB,0.9,0.9\n"
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ofcom.NewManager(dir).Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	c := checker.New(dir, checker.WithOffline())
+	defer c.Close()
+
+	in := "\ufeffName,Post Code\nAda,LS1 1AB\nBob\nCy,LS1 1AA\n"
+	header, rows, err := readCSV(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	col, err := findColumn(header, "post code")
+	if err != nil || col != 1 {
+		t.Fatalf("expected column 1, got %d (err %v)", col, err)
+	}
+	ops := []string{"ee", "o2"}
+	var out strings.Builder
+	n, missing, err := enrich(context.Background(), c, &out, header, rows, col, ops, checker.CheckOptions{Operators: ops}, checker.BulkOptions{Workers: 2})
+	if err != nil || n != 3 || missing != 1 {
+		t.Fatalf("expected 3 rows with 1 missing, got %d, %d (err %v)", n, missing, err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	want := []string{
+		"Name,Post Code,coverage_score,coverage_grade,operators_4g,operators_5g,ee_voice,ee_4g,ee_5g,o2_voice,o2_4g,o2_5g,coverage_code,coverage_note",
+		"Ada,LS1 1AB,",
+		"Bob,,,,,,,,,,,,,",
+		"Cy,LS1 1AA,",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d lines, got %q", len(want), lines)
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(lines[i], w) {
+			t.Errorf("line %d: expected prefix %q, got %q", i, w, lines[i])
+		}
+	}
+	if !strings.Contains(lines[1], ",90%,") || !strings.Contains(lines[3], ",100%,") {
+		t.Errorf("expected each row's own EE 4G coverage, got %q and %q", lines[1], lines[3])
+	}
+
+	if _, err := findColumn(header, "postcode"); err == nil {
+		t.Error("expected an error for a missing column")
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+)
+
+// Exit codes, for scripts that branch on the outcome of a command.
+const (
+	exitOK              = 0
+	exitError           = 1 // anything else, e.g. a bad flag
+	exitInvalidPostcode = 2
+	exitNotFound        = 3 // postcode, address or dataset row not found
+	exitDatasetMissing  = 4 // no usable dataset: run setup
+	exitUpstream        = 5 // postcodes.io, the geocoder or a fallback server failed
+	exitNoCoverage      = 6 // --fail-on-no-coverage threshold not met
+)
+
+// exitStatus is returned by a command that has already reported its
+// outcome and only needs the process to exit with a code.
+type exitStatus int
+
+func (e exitStatus) Error() string { return fmt.Sprintf("exit status %d", int(e)) }
+
+// exitCode returns the process exit code for an error returned by a command.
+func exitCode(err error) int {
+	var st exitStatus
+	if errors.As(err, &st) {
+		return int(st)
+	}
+	if code := exitCodeFor(checker.CodeOf(err)); code != exitOK {
+		return code
+	}
+	return exitError
+}
+
+// exitCodeFor maps a check's error code to an exit code.
+func exitCodeFor(code checker.ErrorCode) int {
+	switch code {
+	case "", checker.CodeSkipped:
+		return exitOK
+	case checker.CodeInvalidPostcode:
+		return exitInvalidPostcode
+	case checker.CodePostcodeNotFound, checker.CodePostcodeTerminated, checker.CodeNotInDataset, checker.CodeAddressNotFound:
+		return exitNotFound
+	case checker.CodeDatasetMissing, checker.CodeDatasetOutdated, checker.CodeYearNotInstalled, checker.CodeNationNotInstalled,
+		checker.CodeVersionNotInstalled:
+		return exitDatasetMissing
+	case checker.CodeUpstreamTimeout, checker.CodeUpstreamUnavailable:
+		return exitUpstream
+	}
+	return exitError
+}
+
+// checkExitCode returns the exit code for the results of check: that of
+// the first result, in input order, without coverage, or exitNoCoverage if
+// a result has fewer than minFourG operators with 4G. A failed check
+// outranks a coverage shortfall even when it comes later, as it means the
+// coverage is unknown rather than low. Results with coverage count as
+// successes even when degraded, e.g. checked without postcodes.io or
+// estimated from nearby postcodes.
+func checkExitCode(results []checker.Result, minFourG int) int {
+	for _, r := range results {
+		// Workers run concurrently, so a skipped check may come before the
+		// failure that stopped the run; that failure decides.
+		if r.Code == checker.CodeSkipped {
+			continue
+		}
+		if r.Mobile == nil {
+			if code := exitCodeFor(r.Code); code != exitOK {
+				return code
+			}
+			if r.Error != "" {
+				return exitError
+			}
+		}
+	}
+	for _, r := range results {
+		if minFourG > 0 && (r.Mobile == nil || r.Mobile.Overall.FourGCount < minFourG) {
+			return exitNoCoverage
+		}
+	}
+	return exitOK
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+func TestExitCodeFor(t *testing.T) {
+	for code, want := range map[checker.ErrorCode]int{
+		"":                                 exitOK,
+		checker.CodeSkipped:                exitOK,
+		checker.CodeInvalidPostcode:        exitInvalidPostcode,
+		checker.CodePostcodeNotFound:       exitNotFound,
+		checker.CodePostcodeTerminated:     exitNotFound,
+		checker.CodeNotInDataset:           exitNotFound,
+		checker.CodeAddressNotFound:        exitNotFound,
+		checker.CodeDatasetMissing:         exitDatasetMissing,
+		checker.CodeDatasetOutdated:        exitDatasetMissing,
+		checker.CodeYearNotInstalled:       exitDatasetMissing,
+		checker.CodeNationNotInstalled:     exitDatasetMissing,
+		checker.CodeUpstreamTimeout:        exitUpstream,
+		checker.CodeUpstreamUnavailable:    exitUpstream,
+		checker.CodeInternal:               exitError,
+		checker.ErrorCode("SOMETHING_NEW"): exitError,
+	} {
+		if got := exitCodeFor(code); got != want {
+			t.Errorf("%q: expected %d, got %d", code, want, got)
+		}
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want int
+	}{
+		{exitStatus(exitNoCoverage), exitNoCoverage},
+		{fmt.Errorf("wrapped: %w", exitStatus(exitNotFound)), exitNotFound},
+		{fmt.Errorf("lookup: %w", postcode.ErrInvalid), exitInvalidPostcode},
+		{ofcom.ErrDatabaseNotFound, exitDatasetMissing},
+		{errors.New("unknown flag"), exitError},
+	} {
+		if got := exitCode(tc.err); got != tc.want {
+			t.Errorf("%v: expected %d, got %d", tc.err, tc.want, got)
+		}
+	}
+}
+
+func TestCheckExitCode(t *testing.T) {
+	covered := func(fourG int) checker.Result {
+		return checker.Result{Valid: true, Mobile: &ofcom.MobileSummary{Overall: ofcom.OverallCoverage{FourGCount: fourG}}}
+	}
+	failed := func(code checker.ErrorCode) checker.Result {
+		return checker.Result{Error: "failed", Code: code}
+	}
+	estimated := covered(2)
+	estimated.Mobile.Estimated = true
+	estimated.Code, estimated.Note = checker.CodeNotInDataset, "estimated"
+
+	for _, tc := range []struct {
+		name     string
+		results  []checker.Result
+		minFourG int
+		want     int
+	}{
+		{"covered", []checker.Result{covered(4)}, 0, exitOK},
+		{"invalid", []checker.Result{failed(checker.CodeInvalidPostcode)}, 0, exitInvalidPostcode},
+		{"not found", []checker.Result{failed(checker.CodePostcodeNotFound)}, 0, exitNotFound},
+		{"not in dataset", []checker.Result{{Valid: true, Code: checker.CodeNotInDataset, Note: "none"}}, 0, exitNotFound},
+		{"dataset missing", []checker.Result{failed(checker.CodeDatasetMissing)}, 0, exitDatasetMissing},
+		{"upstream", []checker.Result{failed(checker.CodeUpstreamTimeout)}, 0, exitUpstream},
+		{"uncoded error", []checker.Result{{Error: "boom"}}, 0, exitError},
+		{"skipped", []checker.Result{covered(4), failed(checker.CodeSkipped)}, 0, exitOK},
+		{"skipped before failure", []checker.Result{failed(checker.CodeSkipped), failed(checker.CodePostcodeNotFound)}, 0, exitNotFound},
+		{"first failure wins", []checker.Result{covered(4), failed(checker.CodeUpstreamUnavailable), failed(checker.CodeInvalidPostcode)}, 0, exitUpstream},
+		{"threshold met", []checker.Result{covered(3)}, 3, exitOK},
+		{"threshold missed", []checker.Result{covered(4), covered(2)}, 3, exitNoCoverage},
+		{"failure outranks shortfall", []checker.Result{covered(1), failed(checker.CodePostcodeNotFound)}, 3, exitNotFound},
+		{"skipped counts as no coverage", []checker.Result{covered(4), failed(checker.CodeSkipped)}, 1, exitNoCoverage},
+		{"estimated succeeds", []checker.Result{estimated}, 0, exitOK},
+		{"estimated meets threshold", []checker.Result{estimated}, 2, exitOK},
+		{"estimated misses threshold", []checker.Result{estimated}, 3, exitNoCoverage},
+		{"degraded succeeds", []checker.Result{{Valid: true, Mobile: covered(4).Mobile, Code: checker.CodeUpstreamUnavailable, Note: "no geo"}}, 0, exitOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := checkExitCode(tc.results, tc.minFourG); got != tc.want {
+				t.Errorf("expected %d, got %d", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func newExportCmd(dataDir *string) *cobra.Command {
+	var f ofcom.ExportFilter
+	var out, format, bbox string
+	var withGeo bool
+	var gridSize int
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export matching postcodes to SQLite, Parquet or GeoJSON",
+		Long: "Write a standalone SQLite database containing only the postcodes matching\n" +
+			"the given filters. --district and --region need 'setup --geocode'.\n\n" +
+			"With --format parquet, write the coverage table as a Parquet file for DuckDB\n" +
+			"or Spark instead; filters are optional and --geo adds geographic columns.\n\n" +
+			"With --format geojson, write a FeatureCollection of postcode centroids and\n" +
+			"their coverage for mapping, optionally within --bbox; --grid aggregates them\n" +
+			"into National Grid squares to keep the file small. Needs 'setup --geocode'.",
+		Args: cobra.NoArgs,
+		Example: "  mobile-checker export --district Leeds --out leeds.db\n  mobile-checker export --outcode LS1 --out ls1.db\n" +
+			"  mobile-checker export --format parquet --geo --out coverage.parquet\n" +
+			"  mobile-checker export --format geojson --bbox -1.7,53.7,-1.4,53.9 --out leeds.geojson\n" +
+			"  mobile-checker export --format geojson --grid 1000 --out uk-1km.geojson",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m := newManager(*dataDir)
+			if format != "geojson" && (bbox != "" || gridSize != 0) {
+				return fmt.Errorf("--bbox and --grid only apply to --format geojson")
+			}
+			var n int
+			var err error
+			what := "postcodes"
+			switch format {
+			case "sqlite":
+				if withGeo {
+					return fmt.Errorf("--geo only applies to --format parquet; SQLite exports always include geographic data")
+				}
+				n, err = m.Export(out, f)
+			case "parquet":
+				n, err = m.ExportParquet(out, f, withGeo)
+			case "geojson":
+				var opts ofcom.GeoJSONOptions
+				if bbox != "" {
+					box, err := ofcom.ParseBBox(bbox)
+					if err != nil {
+						return err
+					}
+					opts.BBox = &box
+				}
+				opts.GridSize = gridSize
+				if gridSize > 0 {
+					what = fmt.Sprintf("%dm grid squares", gridSize)
+				}
+				n, err = m.ExportGeoJSON(out, f, opts)
+			default:
+				return fmt.Errorf("unknown format %q: use sqlite, parquet or geojson", format)
+			}
+			if err != nil {
+				return err
+			}
+			if s := f.String(); s != "" {
+				notef("%s Exported %d %s (%s) to %s\n", glyphs.Yes, n, what, s, out)
+			} else {
+				notef("%s Exported %d %s to %s\n", glyphs.Yes, n, what, out)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&f.District, "district", "", "Admin district, e.g. Leeds")
+	cmd.Flags().StringVar(&f.Region, "region", "", "Region, e.g. \"North West\"")
+	cmd.Flags().StringVar(&f.Outcode, "outcode", "", "Postcode outcode, e.g. LS1")
+	cmd.Flags().StringVar(&format, "format", "sqlite", "Output format: sqlite, parquet or geojson")
+	cmd.Flags().BoolVar(&withGeo, "geo", false, "Include geographic columns (parquet only)")
+	cmd.Flags().StringVar(&bbox, "bbox", "", "Only postcodes within minLon,minLat,maxLon,maxLat (geojson only)")
+	cmd.Flags().IntVar(&gridSize, "grid", 0, "Aggregate to OSGB grid squares of this many metres, e.g. 1000 (geojson only)")
+	cmd.Flags().StringVarP(&out, "out", "o", "", "Output file")
+	cmd.MarkFlagRequired("out")
+	return cmd
+}
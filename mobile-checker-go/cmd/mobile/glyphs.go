@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/spf13/pflag"
+)
+
+// glyphSet holds the symbols drawn in text output.
+type glyphSet struct {
+	Yes, No  string // ticks and crosses in tables and status lines
+	Arrow    string
+	Dash     string // between clauses, e.g. "Not-spots — no operator with 4G"
+	Rule     string // repeated for horizontal rules
+	Ellipsis string
+	Range    string // between the ends of a range, e.g. 1.0–2.5
+	Pound    string
+	Banner   string
+}
+
+var unicodeGlyphs = glyphSet{
+	Yes: "✓", No: "✗", Arrow: "→", Dash: "—", Rule: "─", Ellipsis: "…", Range: "–", Pound: "£",
+	Banner: `
+╔══════════════════════════════════════════════╗
+║        UK Mobile Coverage Checker            ║
+║  Data: Ofcom Connected Nations + postcodes.io║
+╚══════════════════════════════════════════════╝
+`,
+}
+
+// asciiGlyphs keep every column the width it has with unicodeGlyphs.
+var asciiGlyphs = glyphSet{
+	Yes: "Y", No: "N", Arrow: "->", Dash: "-", Rule: "-", Ellipsis: "...", Range: "-", Pound: "GBP ",
+	Banner: `
++----------------------------------------------+
+|        UK Mobile Coverage Checker            |
+|  Data: Ofcom Connected Nations + postcodes.io|
++----------------------------------------------+
+`,
+}
+
+// glyphs is the set in use: asciiGlyphs with --ascii or when the terminal
+// cannot show UTF-8, otherwise unicodeGlyphs.
+var glyphs = unicodeGlyphs
+
+// asciiOutput and noColor are set by --ascii and --no-color.
+var asciiOutput, noColor bool
+
+// detectGlyphs picks the glyphs for the terminal, before flags are parsed.
+func detectGlyphs() {
+	if !terminalUTF8() {
+		glyphs = asciiGlyphs
+	}
+}
+
+// applyOutputFlags applies --ascii, which overrides detection either way,
+// and --no-color.
+func applyOutputFlags(fs *pflag.FlagSet) {
+	if fs.Changed("ascii") {
+		glyphs = unicodeGlyphs
+		if asciiOutput {
+			glyphs = asciiGlyphs
+		}
+	}
+	if noColor {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+}
+
+// terminalUTF8 reports whether output can be UTF-8: the locale names
+// UTF-8, or no locale is set and the console, on Windows, uses the UTF-8
+// code page.
+func terminalUTF8() bool {
+	for _, v := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := os.Getenv(v); locale != "" {
+			return localeUTF8(locale)
+		}
+	}
+	return consoleUTF8()
+}
+
+// localeUTF8 reports whether a POSIX locale such as en_GB.UTF-8 uses
+// UTF-8.
+func localeUTF8(locale string) bool {
+	_, charset, _ := strings.Cut(locale, ".")
+	charset, _, _ = strings.Cut(strings.ToLower(charset), "@")
+	return charset == "utf-8" || charset == "utf8"
+}
+
+// rule is a horizontal rule n glyphs wide.
+func rule(n int) string {
+	return strings.Repeat(glyphs.Rule, n)
+}
+
+func icon(b bool) string {
+	if b {
+		return glyphs.Yes
+	}
+	return glyphs.No
+}
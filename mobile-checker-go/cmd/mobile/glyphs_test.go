@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func TestLocaleUTF8(t *testing.T) {
+	for locale, want := range map[string]bool{
+		"en_GB.UTF-8":       true,
+		"en_US.utf8":        true,
+		"de_DE.UTF-8@euro":  true,
+		"C":                 false,
+		"POSIX":             false,
+		"en_GB.ISO-8859-1":  false,
+		"en_GB.ISO-8859-15": false,
+	} {
+		if got := localeUTF8(locale); got != want {
+			t.Errorf("localeUTF8(%q) = %v, want %v", locale, got, want)
+		}
+	}
+}
+
+func TestPrintResult_ASCII(t *testing.T) {
+	glyphs = asciiGlyphs
+	defer func() { glyphs = unicodeGlyphs }()
+	var buf bytes.Buffer
+	printResult(&buf, checker.Result{
+		Postcode: "SW1A1AA",
+		Mobile: &ofcom.MobileSummary{Operators: []ofcom.OperatorCoverage{
+			{Name: "EE", Voice: "100%", HasVoice: true, FourG: "100%", HasFourG: true, FiveG: "0%"},
+		}},
+	})
+	for i, r := range buf.String() {
+		if r > 127 {
+			t.Fatalf("expected ASCII only, got %q at byte %d in:\n%s", r, i, buf.String())
+		}
+	}
+	if !strings.Contains(buf.String(), "Y 100%") || !strings.Contains(buf.String(), "N 0%") {
+		t.Errorf("expected Y/N marks, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/geocoder"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func newHereCmd(dataDir *string) *cobra.Command {
+	var locatorName, locatorURL, locatorToken, operators string
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "here",
+		Short: "Check coverage at this machine's location, found from its public IP address",
+		Long: "Place this machine by its public IP address, find the nearest postcode and check\n" +
+			"its coverage. An IP address gives the town at best, and a VPN or a mobile\n" +
+			"connection's gateway can place it miles away, so treat the result as a rough guide\n" +
+			"and check the postcode itself when you know it.",
+		Args: cobra.NoArgs,
+		Example: "  mobile-checker here\n  mobile-checker here --operator ee --json\n" +
+			"  mobile-checker here --ip-locator ipinfo --ip-locator-token $IPINFO_TOKEN",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ops, brands, err := ofcom.ParseOperatorList(operators)
+			if err != nil {
+				return err
+			}
+			l, err := geocoder.NewIPLocator(locatorName, locatorURL, locatorToken)
+			if err != nil {
+				return err
+			}
+			c := checker.New(*dataDir, withPostcodes(), withEncryption(), withSource())
+			defer c.Close()
+			r := c.CheckHere(l, checker.CheckOptions{Operators: ops, Brands: brands, AddIndoor: wantIndoor()})
+			if !jsonOutput && r.Address != nil {
+				notef("Located by IP address %s, which is approximate: check the postcode itself when you know it.\n", r.Address.Query)
+			}
+			format := formatText
+			if jsonOutput {
+				format = formatJSON
+			}
+			out := newResultWriter(os.Stdout, format, nil)
+			if err := out.Write(r); err != nil {
+				return err
+			}
+			if err := out.Close(); err != nil {
+				return err
+			}
+			if code := checkExitCode([]checker.Result{r}, 0); code != exitOK {
+				cmd.SilenceErrors, cmd.SilenceUsage = true, true
+				return exitStatus(code)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&locatorName, "ip-locator", "ipapi", "IP geolocation service: ipapi or ipinfo")
+	cmd.Flags().StringVar(&locatorURL, "ip-locator-url", "", "IP geolocation service URL (default: the public service)")
+	cmd.Flags().StringVar(&locatorToken, "ip-locator-token", "", "Access token for --ip-locator ipinfo")
+	cmd.Flags().StringVar(&operators, "operator", "", "Only show these operators or MVNO brands, comma-separated, e.g. ee,three or giffgaff")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the result as JSON")
+	addTableFlags(cmd)
+	return cmd
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/mobile-checker/internal/history"
+)
+
+func newHistoryCmd(dataDir *string) *cobra.Command {
+	var limit int
+	var diff, jsonOutput bool
+	var prune time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "history [POSTCODE]",
+		Short: "List past checks recorded with check --history",
+		Args:  cobra.MaximumNArgs(1),
+		Example: "  mobile-checker history\n  mobile-checker history SW1A1AA\n" +
+			"  mobile-checker history SW1A1AA --diff\n  mobile-checker history --prune 2160h",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pc := ""
+			if len(args) == 1 {
+				pc = args[0]
+			}
+			store := newHistory(*dataDir)
+			defer store.Close()
+			if prune > 0 {
+				n, err := store.Prune(time.Now().Add(-prune))
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Removed %d checks recorded before %s.\n", n, time.Now().Add(-prune).UTC().Format(time.RFC3339))
+				return nil
+			}
+			if diff {
+				if pc == "" {
+					return errors.New("--diff needs a postcode")
+				}
+				return printHistoryDiff(store, pc, jsonOutput)
+			}
+
+			entries, err := store.List(pc, limit)
+			if err != nil {
+				return err
+			}
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(entries)
+			}
+			if len(entries) == 0 {
+				fmt.Println("No checks recorded. Record them with 'check --history' or the server's --history flag.")
+				return nil
+			}
+			fmt.Printf("  %-20s %-10s %-8s %s\n", "Checked", "Postcode", "Dataset", "Result")
+			for _, e := range entries {
+				fmt.Printf("  %-20s %-10s %-8s %s\n", e.CheckedAt, e.Postcode, orDash(e.Dataset), entrySummary(e))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&limit, "limit", 20, "Maximum number of checks to list (all when 0)")
+	cmd.Flags().BoolVar(&diff, "diff", false, "Compare the latest check of POSTCODE with the one before it")
+	cmd.Flags().DurationVar(&prune, "prune", 0, "Delete checks older than this instead of listing, e.g. 2160h for 90 days")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	return cmd
+}
+
+func printHistoryDiff(store *history.Store, pc string, jsonOutput bool) error {
+	latest, previous, changes, err := store.Diff(pc)
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(map[string]interface{}{
+			"latest":   latest,
+			"previous": previous,
+			"changes":  changes,
+		})
+	}
+	if previous == nil {
+		fmt.Printf("Fewer than two checks of %s recorded; nothing to compare.\n", pc)
+		return nil
+	}
+	fmt.Printf("  %s: %s (dataset %s) %s %s (dataset %s)\n", latest.Postcode,
+		previous.CheckedAt, orDash(previous.Dataset), glyphs.Arrow, latest.CheckedAt, orDash(latest.Dataset))
+	if len(changes) == 0 {
+		fmt.Println("  No coverage changes.")
+		return nil
+	}
+	for _, ch := range changes {
+		fmt.Printf("  %-10s %-6s %s %s %s\n", ch.Operator, ch.Technology, ch.From, glyphs.Arrow, ch.To)
+	}
+	return nil
+}
+
+// entrySummary is a one-line description of a recorded check.
+func entrySummary(e history.Entry) string {
+	if e.Summary == nil {
+		return e.Message
+	}
+	return fmt.Sprintf("score %d (%s), 4G %d/%d, 5G %d/%d", e.Summary.CoverageScore, e.Summary.Grade,
+		e.Summary.Overall.FourGCount, len(e.Summary.Operators), e.Summary.Overall.FiveGCount, len(e.Summary.Operators))
+}
@@ -2,142 +2,500 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
+	"text/template"
 
+	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
+	"github.com/yourusername/mobile-checker/internal/bundle"
 	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/config"
+	"github.com/yourusername/mobile-checker/internal/geocoder"
+	"github.com/yourusername/mobile-checker/internal/logging"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
 )
 
-const banner = `
-╔══════════════════════════════════════════════╗
-║        UK Mobile Coverage Checker            ║
-║  Data: Ofcom Connected Nations + postcodes.io║
-╚══════════════════════════════════════════════╝
-`
-
 func defaultDataDir() string {
 	home, _ := os.UserHomeDir()
 	return filepath.Join(home, ".mobile-checker", "data")
 }
 
 func main() {
+	detectGlyphs()
 	var dataDir string
 	var jsonOutput bool
 	var year string
-	var force bool
+	var setupOpts ofcom.SetupOptions
+	var geocode, offline, recordHistory, noEstimate, trend bool
+	var onspd, premises, nations string
+	var layers []string
+	var bundleOut, columnMap string
+	var operators, weights string
+	var address, geocoderName, geocoderURL string
+	var checkYear, fallbackURL, recommendCmd string
+	var logLevel, logFormat string
+	var configPath string
+	var templateSpec, postcodeFile, outputFormat string
+	var threshold float64
+	var minFourG int
+	var bulk checker.BulkOptions
 
 	c := checker.New(defaultDataDir())
 
 	root := &cobra.Command{
 		Use:   "mobile-checker",
 		Short: "UK Mobile Coverage Checker",
-		Long:  banner + "Check UK mobile coverage using free Ofcom open data and postcodes.io.",
+		Long:  glyphs.Banner + "Check UK mobile coverage using free Ofcom open data and postcodes.io.",
 	}
 	root.PersistentFlags().StringVar(&dataDir, "data-dir", defaultDataDir(), "Directory to store the Ofcom database")
+	root.PersistentFlags().StringVar(&logLevel, "log-level", "info", "Log level: debug, info, warn or error")
+	root.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Log format: text or json")
+	root.PersistentFlags().StringVar(&configPath, "config", "", "YAML config file (default $"+config.FileEnv+")")
+	root.PersistentFlags().StringVar(&postcodesURL, "postcodes-url", "", "postcodes.io-compatible service for postcode lookups, e.g. a self-hosted instance (default: the public API)")
+	root.PersistentFlags().StringArrayVar(&postcodesHeaders, "postcodes-header", nil, "Header sent with every postcodes.io request, as \"Name: value\"; repeatable")
+	root.PersistentFlags().DurationVar(&postcodesTimeout, "postcodes-timeout", postcodesTimeout, "Give up on a postcodes.io request attempt after this long")
+	root.PersistentFlags().Float64Var(&postcodesRate, "postcodes-rate", postcodesRate, "Most postcodes.io requests per second, bursting to twice that (no limit when 0)")
+	root.PersistentFlags().DurationVar(&postcodesCacheTTL, "postcodes-cache-ttl", 0, "Keep postcodes.io lookups in postcodes-cache.db in the data directory for this long, e.g. 720h (no cache when 0)")
+	root.PersistentFlags().StringVar(&source, "source", source, "Coverage source for check, matrix, enrich and route: local (the database built by setup), remote (a mobile.db at --remote-db) or api (Ofcom's coverage API)")
+	root.PersistentFlags().StringVar(&remoteDB, "remote-db", "", "URL of a mobile.db served over HTTP(S) with range requests, e.g. on S3, for --source remote")
+	root.PersistentFlags().StringVar(&ofcomAPIKey, "ofcom-api-key", "", "Subscription key for --source api, from https://api.ofcom.org.uk")
+	root.PersistentFlags().StringVar(&ofcomAPIKeyFile, "ofcom-api-key-file", "", "Read the --source api key from this file instead")
+	root.PersistentFlags().StringVar(&encryptKeyFile, "encrypt-key-file", "", "File holding the passphrase to keep the database and check history encrypted at rest with (default $"+config.EnvName("encrypt-key")+"); setup encrypts an existing database")
+	root.PersistentFlags().StringVar(&transportOpts.CACert, "ca-cert", "", "PEM file of certificate authorities to trust for HTTPS as well as the system's, e.g. a corporate proxy's")
+	root.PersistentFlags().BoolVar(&transportOpts.InsecureSkipVerify, "insecure-skip-verify", false, "Do not verify HTTPS certificates (for diagnosing only)")
+	root.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Print only results: no banner, status messages or progress logs")
+	root.PersistentFlags().BoolVar(&asciiOutput, "ascii", false, "Draw text output in plain ASCII: Y/N for ticks and crosses, no box drawing (default: when the locale is not UTF-8)")
+	root.PersistentFlags().BoolVar(&noColor, "no-color", false, "Do not colour text output (also set by NO_COLOR)")
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(config.Path(configPath))
+		if err != nil {
+			return err
+		}
+		if err := config.ApplyPFlags(cmd.Flags(), cfg); err != nil {
+			return err
+		}
+		applyOutputFlags(cmd.Flags())
+		if err := parseTableFlags(); err != nil {
+			return err
+		}
+		if err := parsePostcodesFlags(dataDir); err != nil {
+			return err
+		}
+		if err := parseSourceFlags(); err != nil {
+			return err
+		}
+		if err := parseEncryptFlags(); err != nil {
+			return err
+		}
+		if quiet && !cmd.Flags().Changed("log-level") {
+			logLevel = "warn"
+		}
+		logger, err := logging.New(os.Stderr, logLevel, logFormat)
+		if err != nil {
+			return err
+		}
+		slog.SetDefault(logger)
+		if err := installTransport(); err != nil {
+			return err
+		}
+		return bundle.Install(newManager(dataDir, ofcom.WithLogger(logger)))
+	}
 
 	setupCmd := &cobra.Command{
 		Use:   "setup",
 		Short: "Download and build the Ofcom mobile database (run once)",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c = checker.New(dataDir)
-			fmt.Println(banner)
-			fmt.Printf("Setting up Ofcom mobile %s dataset...\n", year)
-			if err := c.Setup(year, force); err != nil {
+			var err error
+			if setupOpts.Nations, err = ofcom.ParseNations(nations); err != nil {
+				return err
+			}
+			for _, s := range layers {
+				l, err := ofcom.ParseLayer(s)
+				if err != nil {
+					return err
+				}
+				setupOpts.Layers = append(setupOpts.Layers, l)
+			}
+			if columnMap != "" {
+				if setupOpts.ColumnMap, err = ofcom.LoadColumnMap(columnMap); err != nil {
+					return err
+				}
+			}
+			c = checker.New(dataDir, withPostcodes(), withEncryption())
+			notef("%s\n", glyphs.Banner)
+			notef("Setting up Ofcom mobile %s dataset...\n", year)
+			if err := c.Setup(year, setupOpts); err != nil {
 				return err
 			}
-			fmt.Println("\n✓ Setup complete.")
-			fmt.Println("  You can now run: mobile-checker check <POSTCODE>")
+			if onspd != "" {
+				n, err := c.ImportONSPD(onspd)
+				if err != nil {
+					return err
+				}
+				notef("  Imported geographic data for %d postcodes from %s\n", n, onspd)
+			}
+			if premises != "" {
+				n, err := c.ImportPremises(premises)
+				if err != nil {
+					return err
+				}
+				notef("  Imported premises coverage for %d postcodes from %s\n", n, premises)
+			}
+			if geocode {
+				if err := c.Geocode(); err != nil {
+					return err
+				}
+				logThrottling(c)
+			}
+			if bundleOut != "" {
+				if err := newManager(dataDir).Bundle(bundleOut); err != nil {
+					return err
+				}
+			}
+			notef("\n%s Setup complete.\n", glyphs.Yes)
+			notef("  You can now run: mobile-checker check <POSTCODE>\n")
 			return nil
 		},
 	}
-	setupCmd.Flags().StringVar(&year, "year", "2023", "Ofcom dataset year (2022 or 2023)")
-	setupCmd.Flags().BoolVar(&force, "force", false, "Force re-download even if data exists")
+	setupCmd.Flags().StringVar(&year, "year", "2023", "Ofcom dataset year, e.g. 2023, or \"latest\" to find the newest on ofcom.org.uk")
+	setupCmd.Flags().BoolVar(&setupOpts.Force, "force", false, "Force re-download even if data exists")
+	setupCmd.Flags().StringVar(&setupOpts.SHA256, "sha256", "", "Expected SHA-256 of the Ofcom ZIP")
+	setupCmd.Flags().StringVar(&setupOpts.ManifestURL, "manifest-url", "", "URL of a signed JSON {year: sha256} checksum manifest; the signature is fetched from URL.sig")
+	setupCmd.Flags().StringVar(&setupOpts.ManifestKey, "manifest-key", "", "Base64 Ed25519 public key the --manifest-url manifest is signed with")
+	setupCmd.Flags().BoolVar(&setupOpts.RequireChecksum, "require-checksum", false, "Refuse to download a dataset without a known checksum")
+	setupCmd.Flags().DurationVar(&setupOpts.DownloadTimeout, "download-timeout", ofcom.DefaultDownloadTimeout, "Give up on a dataset download after this long")
+	setupCmd.Flags().StringVar(&setupOpts.IndexURL, "index-url", "", "Page searched for datasets without a known URL (default: Ofcom Connected Nations)")
+	setupCmd.Flags().BoolVar(&geocode, "geocode", false, "Geocode every postcode via postcodes.io (enables area statistics)")
+	setupCmd.Flags().StringVar(&onspd, "onspd", "", "Import geographic data from an ONSPD or NSPL ZIP or CSV (enables offline checks)")
+	setupCmd.Flags().StringVar(&premises, "premises", "", "Import Ofcom premises coverage from its ZIP or CSV (adds premises counts to checks)")
+	setupCmd.Flags().StringVar(&setupOpts.Columns, "columns", "", "Coverage columns to store: full, or minimal for outdoor voice, 4G and 5G only (default: as before, else full)")
+	setupCmd.Flags().StringVar(&nations, "nations", "", "Only store postcodes in these nations, comma-separated, e.g. england,wales (default: all of the UK)")
+	setupCmd.Flags().StringArrayVar(&layers, "layer", nil, "Store a nation's own edition over the UK-wide file: NATION=ZIP|CSV|URL, repeatable")
+	setupCmd.Flags().StringVar(&bundleOut, "bundle", "", "Also write a compacted copy of the database to this path for embedding")
+	setupCmd.Flags().StringVar(&columnMap, "column-map", "", "YAML file mapping the CSV's headers to canonical columns, for an edition with an unrecognised layout")
 
 	checkCmd := &cobra.Command{
-		Use:     "check [POSTCODE...]",
-		Short:   "Check mobile coverage for one or more postcodes",
-		Args:    cobra.MinimumNArgs(1),
-		Example: "  mobile-checker check SW1A1AA\n  mobile-checker check SW1A1AA EC1A1BB --json",
+		Use:   "check [POSTCODE...]",
+		Short: "Check mobile coverage for one or more postcodes",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if address != "" {
+				if len(args) > 0 {
+					return fmt.Errorf("give either --address or postcodes, not both")
+				}
+				return nil
+			}
+			if postcodeFile != "" {
+				return nil
+			}
+			return cobra.MinimumNArgs(1)(cmd, args)
+		},
+		Example: "  mobile-checker check SW1A1AA\n  mobile-checker check SW1A1AA EC1A1BB --json\n  mobile-checker check SW1A1AA --operator ee,three\n" +
+			"  mobile-checker check --address \"10 Downing Street, London\"\n" +
+			"  mobile-checker check SW1A1AA EC1A1BB --template table\n  mobile-checker check SW1A1AA --template '{{.Postcode}}: grade {{.Mobile.Grade}}'\n" +
+			"  mobile-checker check --file postcodes.txt --json | head\n" +
+			"  jq -c '{postcode}' sites.ndjson | mobile-checker check --file - --format ndjson | jq -r .mobile.Grade",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c = checker.New(dataDir)
+			ops, brands, err := ofcom.ParseOperatorList(operators)
+			if err != nil {
+				return err
+			}
+			format := outputFormat
+			switch {
+			case jsonOutput && format != formatText && format != formatJSON:
+				return fmt.Errorf("give either --json or --format, not both")
+			case jsonOutput:
+				format = formatJSON
+			case format != formatText && format != formatJSON && format != formatNDJSON:
+				return fmt.Errorf("--format must be text, json or ndjson")
+			}
+			var tmpl *template.Template
+			if templateSpec != "" {
+				if format != formatText {
+					return fmt.Errorf("give either --%s or --template, not both", format)
+				}
+				if tmpl, err = parseTemplate(templateSpec); err != nil {
+					return err
+				}
+			}
+			if err := ofcom.CheckThreshold(threshold); err != nil {
+				return err
+			}
+			opts := checker.CheckOptions{Operators: ops, Brands: brands, Threshold: threshold, NoEstimate: noEstimate, Year: checkYear, Trend: trend, AddIndoor: wantIndoor()}
+			if weights != "" {
+				if opts.Weights, err = ofcom.ParseScoreWeights(weights); err != nil {
+					return err
+				}
+			}
+			copts := []checker.Option{withPostcodes(), withEncryption(), withSource()}
+			if offline {
+				copts = append(copts, checker.WithOffline())
+			}
+			if recordHistory {
+				copts = append(copts, checker.WithHistory(newHistory(dataDir)))
+			}
+			if fallbackURL != "" {
+				copts = append(copts, checker.WithFallback(fallbackURL))
+			}
+			if recommendCmd != "" {
+				rec, err := checker.NewCommandRecommender(recommendCmd)
+				if err != nil {
+					return err
+				}
+				copts = append(copts, checker.WithRecommender(rec))
+			}
+			if address != "" {
+				g, err := geocoder.New(geocoderName, geocoderURL)
+				if err != nil {
+					return err
+				}
+				copts = append(copts, checker.WithGeocoder(g))
+			}
+			if postcodeFile != "" {
+				pcs, err := readPostcodes(postcodeFile)
+				if err != nil {
+					return err
+				}
+				args = append(args, pcs...)
+			}
+			c = checker.New(dataDir, copts...)
+			defer c.Close()
+
+			// A closed pipe, e.g. into head, is reported as a write error
+			// rather than killing the process, so checks stop cleanly.
+			signal.Ignore(syscall.SIGPIPE)
+			out := newResultWriter(os.Stdout, format, tmpl)
+			// Only results that can set the exit code are kept, in input
+			// order, so a long --file is not held in memory.
 			var results []checker.Result
-			if len(args) == 1 {
-				results = []checker.Result{c.Check(args[0])}
-			} else {
-				results = c.CheckMultiple(args)
-			}
-			if jsonOutput {
-				enc := json.NewEncoder(os.Stdout)
-				enc.SetIndent("", "  ")
-				return enc.Encode(results)
-			}
-			for i, r := range results {
-				printResult(r)
-				if i < len(results)-1 {
-					fmt.Println()
+			var summary checker.Summary
+			write := func(r checker.Result) error {
+				summary.Add(r)
+				if r.Mobile == nil || (minFourG > 0 && r.Mobile.Overall.FourGCount < minFourG) {
+					results = append(results, r)
 				}
+				return out.Write(r)
+			}
+			switch {
+			case address != "":
+				err = write(c.CheckAddress(address, opts))
+			case len(args) == 1:
+				err = write(c.CheckWith(args[0], opts))
+			default:
+				err = c.CheckBulkFunc(context.Background(), args, opts, bulk, write)
+				logThrottling(c)
+			}
+			if err == nil {
+				err = out.Close()
+			}
+			if isBrokenPipe(err) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if summary.Total > 1 {
+				notef("\nChecked %d postcodes: %s.\n", summary.Total, summary.String())
+			}
+			if code := checkExitCode(results, minFourG); code != exitOK {
+				// The results say what went wrong; only the code is left.
+				cmd.SilenceErrors, cmd.SilenceUsage = true, true
+				return exitStatus(code)
 			}
 			return nil
 		},
 	}
-	checkCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output results as JSON")
+	checkCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output results as JSON (the same as --format json)")
+	checkCmd.Flags().StringVar(&outputFormat, "format", formatText, "Output format: text, json, or ndjson for one JSON object per line")
+	checkCmd.Flags().StringVar(&postcodeFile, "file", "", "Also check the postcodes in this file, one per line or as NDJSON objects with a postcode field (- for stdin)")
+	checkCmd.Flags().StringVar(&templateSpec, "template", "", "Render each result with a Go template: short, table, markdown, template text or a template file")
+	checkCmd.Flags().StringVar(&weights, "score-weights", "", "Coverage score weights, e.g. voice=0.3,4g=0.5,5g=0.2 (the default)")
+	checkCmd.Flags().StringVar(&operators, "operator", "", "Only show these operators or MVNO brands, comma-separated, e.g. ee,three or giffgaff")
+	checkCmd.Flags().IntVar(&bulk.Workers, "workers", checker.DefaultWorkers, "Concurrent checks when several postcodes are given")
+	checkCmd.Flags().DurationVar(&bulk.Timeout, "timeout", 0, "Give up on a single postcode after this long, e.g. 10s (no limit when 0)")
+	checkCmd.Flags().BoolVar(&bulk.FailFast, "fail-fast", false, "Stop checking further postcodes after the first failure")
+	checkCmd.Flags().BoolVar(&offline, "offline", false, "Never call postcodes.io; use geographic data stored by setup --onspd or --geocode")
+	checkCmd.Flags().BoolVar(&noEstimate, "no-estimate", false, "Don't estimate coverage from nearby postcodes for postcodes missing from the dataset")
+	checkCmd.Flags().BoolVar(&recordHistory, "history", false, "Record the check for 'mobile-checker history'")
+	checkCmd.Flags().Float64Var(&threshold, "threshold", ofcom.CoverageThreshold, "Coverage fraction that counts as available, above 0 and at most 1")
+	checkCmd.Flags().StringVar(&checkYear, "year", "", "Check an installed dataset year instead of the current one, e.g. 2022")
+	checkCmd.Flags().BoolVar(&trend, "trend", false, "Add each operator's change across installed dataset years and an indicative forecast")
+	checkCmd.Flags().StringVar(&recommendCmd, "recommend-cmd", "", "Program to ask for tariff recommendations, given each result's coverage as JSON on stdin, e.g. ./tariffs --region uk")
+	checkCmd.Flags().StringVar(&fallbackURL, "fallback-url", "", "mobile-checker server to ask while the local dataset is missing, e.g. https://coverage.example.com")
+	checkCmd.Flags().IntVar(&minFourG, "fail-on-no-coverage", 0, "Exit with status 6 unless at least this many operators have 4G at each postcode")
+	checkCmd.Flags().StringVar(&address, "address", "", "Check the postcode of this address instead, e.g. \"10 Downing Street, London\"")
+	checkCmd.Flags().StringVar(&geocoderName, "geocoder", "nominatim", "Geocoder for --address: nominatim or postcodesio")
+	checkCmd.Flags().StringVar(&geocoderURL, "geocoder-url", "", "Geocoder server URL (default: the public service)")
+	addTableFlags(checkCmd)
 
-	root.AddCommand(setupCmd, checkCmd)
+	root.AddCommand(setupCmd, checkCmd, newRouteCmd(&dataDir), newMonitorCmd(&dataDir), newTUICmd(&dataDir), newStatusCmd(&dataDir), newNearestCmd(&dataDir), newReportCmd(&dataDir), newStatsCmd(&dataDir), newUpdateCmd(&dataDir), newExportCmd(&dataDir), newSuggestCmd(&dataDir), newHistoryCmd(&dataDir), newMaintainCmd(&dataDir), newMatrixCmd(&dataDir), newEnrichCmd(&dataDir), newVerifyCmd(&dataDir), newNotSpotsCmd(&dataDir), newHereCmd(&dataDir), newSitesCmd(&dataDir), newBenchCmd(&dataDir))
 	if err := root.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
-func printResult(r checker.Result) {
-	sep := strings.Repeat("─", 52)
-	fmt.Printf("\n%s\n", sep)
-	fmt.Printf("  Postcode: %s\n", r.Postcode)
-	fmt.Printf("%s\n", sep)
+// printDetailURLs lists the operators' own coverage checkers.
+func printDetailURLs(w io.Writer, ops []ofcom.OperatorCoverage) {
+	first := true
+	for _, op := range ops {
+		if op.DetailURL == "" {
+			continue
+		}
+		if first {
+			fmt.Fprintln(w, "  Cross-check with the operators:")
+			first = false
+		}
+		fmt.Fprintf(w, "    %-9s %s\n", op.Name, op.DetailURL)
+	}
+}
+
+func printResult(w io.Writer, r checker.Result) {
+	sep := rule(52)
+	fmt.Fprintf(w, "\n%s\n", sep)
+	fmt.Fprintf(w, "  Postcode: %s\n", r.Postcode)
+	if a := r.Address; a != nil {
+		fmt.Fprintf(w, "  Address:  %s\n", a.Query)
+		if a.Label != "" {
+			fmt.Fprintf(w, "  Matched:  %s (%s)\n", a.Label, a.Source)
+		}
+	}
+	if r.Fallback != "" {
+		fmt.Fprintf(w, "  Via:      %s (no local dataset)\n", r.Fallback)
+	}
+	if r.Dataset != nil {
+		fmt.Fprintf(w, "  Dataset:  Ofcom %s\n", r.Dataset)
+	}
+	fmt.Fprintf(w, "%s\n", sep)
 
 	if r.Error != "" {
-		fmt.Printf("  ✗ %s\n", r.Error)
+		fmt.Fprintf(w, "  %s %s\n", glyphs.No, r.Error)
+		if t := r.Terminated; t != nil && t.Nearest != nil {
+			printResult(w, *t.Nearest)
+		}
 		return
 	}
 
 	if g := r.Geographic; g != nil {
-		fmt.Printf("  Region:   %s\n", g.Region)
-		fmt.Printf("  District: %s\n", g.AdminDistrict)
-		fmt.Printf("  Country:  %s\n", g.Country)
-		fmt.Printf("  Lat/Lon:  %.6f, %.6f\n", g.Latitude, g.Longitude)
+		fmt.Fprintf(w, "  Region:   %s\n", g.Region)
+		fmt.Fprintf(w, "  District: %s\n", g.AdminDistrict)
+		fmt.Fprintf(w, "  Country:  %s\n", g.Country)
+		fmt.Fprintf(w, "  Lat/Lon:  %.6f, %.6f\n", g.Latitude, g.Longitude)
 	}
 
-	if r.Note != "" {
-		fmt.Printf("\n  Note: %s\n", r.Note)
+	if r.Note != "" && r.Mobile == nil {
+		fmt.Fprintf(w, "\n  Note: %s\n", r.Note)
 		return
 	}
 
 	if r.Mobile == nil {
-		fmt.Println("\n  Mobile data: Not available")
+		fmt.Fprintln(w, "\n  Mobile data: Not available")
 		return
 	}
 
 	mob := r.Mobile
-	fmt.Printf("\n  %-12s %-10s %-10s %-10s\n", "Operator", "Voice", "4G", "5G")
-	fmt.Printf("  %s\n", strings.Repeat("─", 44))
-	for _, op := range mob.Operators {
-		voice := icon(op.HasVoice) + " " + op.Voice
-		fg := icon(op.HasFourG) + " " + op.FourG
-		ffg := icon(op.HasFiveG) + " " + op.FiveG
-		fmt.Printf("  %-12s %-10s %-10s %-10s\n", op.Name, voice, fg, ffg)
-	}
-	fmt.Printf("  %s\n", strings.Repeat("─", 44))
-	fmt.Printf("  4G operators: %d/4   5G operators: %d/4\n",
-		mob.Overall.FourGCount, mob.Overall.FiveGCount)
-	fmt.Println("\n  Source: Ofcom Connected Nations (open data)")
+	fmt.Fprintln(w)
+	printOperatorTable(w, mob.Operators)
+	fmt.Fprintf(w, "  4G operators: %d/%d   5G operators: %d/%d\n",
+		mob.Overall.FourGCount, len(mob.Operators), mob.Overall.FiveGCount, len(mob.Operators))
+	fmt.Fprintf(w, "  Coverage score: %d/100 (grade %s)\n", mob.CoverageScore, mob.Grade)
+	if rk := mob.Rank; rk != nil {
+		line := fmt.Sprintf("better than %.0f%% of postcodes nationally", rk.National.BetterThan)
+		for _, p := range []*ofcom.Percentile{rk.Region, rk.District} {
+			if p != nil {
+				line += fmt.Sprintf(", %.0f%% in %s", p.BetterThan, p.Area)
+			}
+		}
+		fmt.Fprintf(w, "  Rank: %s\n", line)
+	}
+	if mob.Premises > 0 {
+		var covered []string
+		for _, op := range mob.Operators {
+			if p := op.Premises; p != nil {
+				covered = append(covered, fmt.Sprintf("%s %d", op.Name, p.FourG))
+			}
+		}
+		fmt.Fprintf(w, "  Premises with 4G: %s (of %d)\n", strings.Join(covered, ", "), mob.Premises)
+	}
+	if mob.Estimated {
+		from := make([]string, len(mob.EstimatedFrom))
+		for i, n := range mob.EstimatedFrom {
+			from[i] = fmt.Sprintf("%s (%.1f km)", n.Postcode, n.DistanceKm)
+		}
+		fmt.Fprintf(w, "  Estimated from: %s\n", strings.Join(from, ", "))
+	}
+	fmt.Fprintln(w, "\n  Source: Ofcom Connected Nations (open data)")
+	printDetailURLs(w, mob.Operators)
+	if r.Note != "" {
+		// e.g. estimated, or checked without postcodes.io
+		fmt.Fprintf(w, "  Note: %s\n", r.Note)
+	}
+	if r.Trend != nil {
+		printTrend(w, r.Trend)
+	}
+
+	for _, rec := range r.Recommendations {
+		printRecommendations(w, rec)
+	}
+
+	for _, src := range r.Sources {
+		fmt.Fprintf(w, "\n  Source: %s\n", src.Source)
+		if src.Mobile == nil {
+			fmt.Fprintf(w, "  %s\n", src.Note)
+			continue
+		}
+		printOperatorTable(w, src.Mobile.Operators)
+	}
 }
 
-func icon(b bool) string {
-	if b {
-		return "✓"
+// printRecommendations lists a recommender's tariffs, or why it has none.
+func printRecommendations(w io.Writer, rec checker.Recommendations) {
+	fmt.Fprintf(w, "\n  Recommended tariffs (%s):\n", rec.Recommender)
+	if rec.Note != "" {
+		fmt.Fprintf(w, "  %s\n", rec.Note)
+		return
 	}
-	return "✗"
+	if len(rec.Tariffs) == 0 {
+		fmt.Fprintln(w, "  None")
+		return
+	}
+	for _, t := range rec.Tariffs {
+		price := "-"
+		if t.MonthlyPrice > 0 {
+			price = fmt.Sprintf("%s%.2f/mo", glyphs.Pound, t.MonthlyPrice)
+		}
+		fmt.Fprintf(w, "  %-12s %-24s %-11s %-10s %s\n", t.Operator, t.Name, price, orDash(t.Data), t.Reason)
+	}
+}
+
+// printTrend shows each operator's change a year across the installed
+// dataset years and any forecasts.
+func printTrend(w io.Writer, t *ofcom.Trend) {
+	fmt.Fprintf(w, "\n  Trend %s%s%s (percentage points a year):\n", t.Years[0], glyphs.Range, t.Years[len(t.Years)-1])
+	for _, op := range t.Operators {
+		fmt.Fprintf(w, "  %-12s", op.Name)
+		for _, tech := range op.Technologies {
+			fmt.Fprintf(w, " %-10s", fmt.Sprintf("%+.1f", tech.PerYearPP))
+		}
+		fmt.Fprintln(w)
+	}
+	for _, f := range t.Forecasts() {
+		fmt.Fprintf(w, "  %s %s\n", glyphs.Arrow, f)
+	}
+	fmt.Fprintf(w, "  %s\n", t.Note)
+}
+
+// tierStyles colour table cells by coverage tier. lipgloss drops the
+// colour when stdout is not a terminal or NO_COLOR is set.
+var tierStyles = map[ofcom.Tier]lipgloss.Style{
+	ofcom.TierNone:      lipgloss.NewStyle().Foreground(lipgloss.Color("9")),
+	ofcom.TierPartial:   lipgloss.NewStyle().Foreground(lipgloss.Color("11")),
+	ofcom.TierGood:      lipgloss.NewStyle().Foreground(lipgloss.Color("2")),
+	ofcom.TierExcellent: lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true),
 }
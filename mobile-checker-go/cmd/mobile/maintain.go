@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func newMaintainCmd(dataDir *string) *cobra.Command {
+	var opts ofcom.MaintainOptions
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "maintain",
+		Short: "VACUUM and ANALYZE the database",
+		Long: "Reclaim free pages with VACUUM and refresh query statistics with ANALYZE,\n" +
+			"first checkpointing the write-ahead log of a database still in WAL mode.\n" +
+			"Safe to run from cron while the server is running; it waits for queries\n" +
+			"in progress. --report opens the database read-only.",
+		Args:    cobra.NoArgs,
+		Example: "  mobile-checker maintain\n  mobile-checker maintain --reindex\n  mobile-checker maintain --report",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rep, err := newManager(*dataDir).Maintain(opts)
+			if err != nil {
+				return err
+			}
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(rep)
+			}
+			fmt.Printf("  Database:       %s\n", rep.Path)
+			fmt.Printf("  Fragmentation:  %.1f%% (%d of %d pages free)\n", rep.Fragmentation, rep.FreePages, rep.Pages)
+			fmt.Printf("  WAL:            %.1f MB\n", float64(rep.WALBefore)/(1<<20))
+			if opts.ReportOnly {
+				return nil
+			}
+			fmt.Printf("  Size:           %.1f MB %s %.1f MB\n", float64(rep.SizeBefore)/(1<<20), glyphs.Arrow, float64(rep.SizeAfter)/(1<<20))
+			var done []string
+			if rep.Checkpointed {
+				done = append(done, "checkpoint")
+			}
+			if rep.Reindexed {
+				done = append(done, "REINDEX")
+			}
+			done = append(done, "VACUUM", "ANALYZE")
+			fmt.Printf("\n%s Maintenance complete (%s) in %d ms.\n", glyphs.Yes, strings.Join(done, ", "), rep.DurationMs)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&opts.Reindex, "reindex", false, "Also rebuild every index")
+	cmd.Flags().BoolVar(&opts.ReportOnly, "report", false, "Only report fragmentation and WAL size")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the report as JSON")
+	return cmd
+}
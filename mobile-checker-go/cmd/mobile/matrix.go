@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+// matrixTechs are the technologies compared, with their column labels.
+var matrixTechs = []struct{ key, label string }{{"voice", "Voice"}, {"4g", "4G"}, {"5g", "5G"}}
+
+func newMatrixCmd(dataDir *string) *cobra.Command {
+	var operators, format, fallbackURL string
+	var offline bool
+
+	cmd := &cobra.Command{
+		Use:   "matrix POSTCODE POSTCODE...",
+		Short: "Compare coverage across candidate postcodes side by side",
+		Long: "Check several postcodes and print a matrix of coverage by operator and\n" +
+			"technology, one column per postcode, followed by a ranking of the postcodes\n" +
+			"by coverage score, then by the number of operators with 4G and 5G.",
+		Args:    cobra.MinimumNArgs(2),
+		Example: "  mobile-checker matrix LS11AA LS12AB LS27HY\n  mobile-checker matrix LS11AA LS12AB --operator ee,o2 --format csv",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ops, brands, err := ofcom.ParseOperatorList(operators)
+			if err != nil {
+				return err
+			}
+			copts := []checker.Option{withPostcodes(), withEncryption(), withSource()}
+			if offline {
+				copts = append(copts, checker.WithOffline())
+			}
+			if fallbackURL != "" {
+				copts = append(copts, checker.WithFallback(fallbackURL))
+			}
+			c := checker.New(*dataDir, copts...)
+			results := c.CheckBulk(context.Background(), args, checker.CheckOptions{Operators: ops, Brands: brands}, checker.BulkOptions{})
+			ranking := checker.Rank(results)
+
+			switch format {
+			case "table":
+				printMatrix(results, ranking)
+				return nil
+			case "json":
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(struct {
+					SchemaVersion int               `json:"schema_version"`
+					Dataset       *ofcom.Release    `json:"dataset,omitempty"`
+					Results       []checker.Result  `json:"results"`
+					Ranking       []checker.Ranking `json:"ranking"`
+				}{checker.SchemaVersion, datasetOf(c), results, ranking})
+			case "csv":
+				w := csv.NewWriter(os.Stdout)
+				w.WriteAll(matrixRows(results))
+				return w.Error()
+			default:
+				return fmt.Errorf("--format must be table, json or csv")
+			}
+		},
+	}
+	cmd.Flags().StringVar(&operators, "operator", "", "Only compare these operators or MVNO brands, comma-separated, e.g. ee,three or giffgaff")
+	cmd.Flags().StringVar(&format, "format", "table", "Output format: table, json or csv")
+	cmd.Flags().BoolVar(&offline, "offline", false, "Never call postcodes.io; use geographic data stored by setup --onspd or --geocode")
+	cmd.Flags().StringVar(&fallbackURL, "fallback-url", "", "mobile-checker server to ask while the local dataset is missing")
+	return cmd
+}
+
+// matrixRows returns the matrix as a header of postcodes, or site names
+// for saved sites, and one row per operator and technology. Postcodes
+// without coverage data get empty cells.
+func matrixRows(results []checker.Result) [][]string {
+	header := []string{"operator", "technology"}
+	var names []string
+	cells := make([]map[string]string, len(results))
+	for i, r := range results {
+		if r.Site != "" {
+			header = append(header, r.Site)
+		} else {
+			header = append(header, r.Postcode)
+		}
+		cells[i] = make(map[string]string)
+		if r.Mobile == nil {
+			continue
+		}
+		for _, op := range r.Mobile.Operators {
+			if len(names) < len(r.Mobile.Operators) {
+				names = append(names, op.Name) // the same operators for every result
+			}
+			cells[i][op.Name+"/voice"] = op.Voice
+			cells[i][op.Name+"/4g"] = op.FourG
+			cells[i][op.Name+"/5g"] = op.FiveG
+		}
+	}
+	rows := [][]string{header}
+	for _, name := range names {
+		for _, t := range matrixTechs {
+			row := []string{name, t.label}
+			for i := range results {
+				row = append(row, cells[i][name+"/"+t.key])
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+func printMatrix(results []checker.Result, ranking []checker.Ranking) {
+	rows := matrixRows(results)
+	width := 10
+	for _, pc := range rows[0][2:] {
+		width = max(width, len(pc)+2)
+	}
+	line := rule(20 + width*len(results))
+
+	fmt.Printf("\n  %-12s %-6s", "Operator", "")
+	for _, pc := range rows[0][2:] {
+		fmt.Printf(" %-*s", width-1, pc)
+	}
+	fmt.Printf("\n  %s\n", line)
+	for i, row := range rows[1:] {
+		if i > 0 && row[0] != rows[i][0] {
+			fmt.Println()
+		}
+		name := row[0]
+		if row[1] != matrixTechs[0].label {
+			name = ""
+		}
+		fmt.Printf("  %-12s %-6s", name, row[1])
+		for _, v := range row[2:] {
+			if v == "" {
+				v = "-"
+			}
+			fmt.Printf(" %-*s", width-1, v)
+		}
+		fmt.Println()
+	}
+	fmt.Printf("  %s\n", line)
+
+	fmt.Println("\n  Ranking")
+	for _, r := range ranking {
+		name := r.Postcode
+		if r.Site != "" {
+			name = fmt.Sprintf("%s (%s)", r.Site, r.Postcode)
+		}
+		if r.Rank == 0 {
+			fmt.Printf("   -  %-10s %s\n", name, r.Note)
+			continue
+		}
+		fmt.Printf("  %2d. %-10s score %3d (grade %s)   4G operators: %d   5G operators: %d\n",
+			r.Rank, name, r.Score, r.Grade, r.FourG, r.FiveG)
+	}
+}
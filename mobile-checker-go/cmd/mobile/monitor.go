@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/mobile-checker/internal/monitor"
+)
+
+func newMonitorCmd(dataDir *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "monitor",
+		Short: "Watch postcodes and notify a webhook when Ofcom coverage changes",
+	}
+
+	var webhook, format string
+	addCmd := &cobra.Command{
+		Use:   "add <POSTCODE>",
+		Short: "Watch a postcode",
+		Args:  cobra.ExactArgs(1),
+		Example: "  mobile-checker monitor add SW1A1AA --webhook https://example.com/hooks/coverage\n" +
+			"  mobile-checker monitor add SW1A1AA --webhook https://hooks.slack.com/services/T000/B000/XXXX\n" +
+			"  mobile-checker monitor add EC1A1BB --webhook https://example.com/teams-workflow --format teams",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := monitor.ParseFormat(format, webhook)
+			if err != nil {
+				return err
+			}
+			w, err := newMonitor(*dataDir).Add(args[0], webhook, f)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s Watching %s %s %s (%s)\n", glyphs.Yes, w.Postcode, glyphs.Arrow, w.Webhook, w.Format)
+			if w.Summary == nil {
+				fmt.Printf("  No baseline yet %s it will be recorded on the next 'monitor run'.\n", glyphs.Dash)
+			}
+			return nil
+		},
+	}
+	addCmd.Flags().StringVar(&webhook, "webhook", "", "URL to POST coverage change notifications to")
+	addCmd.MarkFlagRequired("webhook")
+	addCmd.Flags().StringVar(&format, "format", "", "Notification format: json, slack (Block Kit) or teams (Adaptive Card) (default: detected from the webhook URL)")
+
+	removeCmd := &cobra.Command{
+		Use:   "remove <POSTCODE>",
+		Short: "Stop watching a postcode",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n, err := newMonitor(*dataDir).Remove(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Removed %d watch(es).\n", n)
+			return nil
+		},
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List watched postcodes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			watches, err := newMonitor(*dataDir).List()
+			if err != nil {
+				return err
+			}
+			if len(watches) == 0 {
+				fmt.Println("No postcodes are being watched.")
+				return nil
+			}
+			fmt.Printf("  %-4s %-10s %-24s %-6s %s\n", "ID", "Postcode", "Dataset", "Format", "Webhook")
+			for _, w := range watches {
+				dataset := w.Dataset
+				if dataset == "" {
+					dataset = "-"
+				}
+				fmt.Printf("  %-4d %-10s %-24s %-6s %s\n", w.ID, w.Postcode, dataset, w.Format, w.Webhook)
+			}
+			return nil
+		},
+	}
+
+	var interval time.Duration
+	var once bool
+	runCmd := &cobra.Command{
+		Use:   "run",
+		Short: "Re-check watched postcodes whenever a new dataset is ingested",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m := newMonitor(*dataDir)
+			if once {
+				sent, err := m.RunOnce()
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Sent %d coverage change notification(s).\n", sent)
+				return nil
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			notef("Monitoring watched postcodes every %s (Ctrl+C to stop)...\n", interval)
+			if err := m.Run(ctx, interval); err != context.Canceled {
+				return err
+			}
+			return nil
+		},
+	}
+	runCmd.Flags().DurationVar(&interval, "interval", time.Hour, "How often to check for a new dataset")
+	runCmd.Flags().BoolVar(&once, "once", false, "Check once and exit")
+
+	cmd.AddCommand(addCmd, removeCmd, listCmd, runCmd)
+	return cmd
+}
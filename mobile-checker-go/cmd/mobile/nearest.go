@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func newNearestCmd(dataDir *string) *cobra.Command {
+	opts := ofcom.NearestOptions{}
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:     "nearest <POSTCODE>",
+		Short:   "Find the closest postcodes with coverage for an operator",
+		Long:    "Find the closest postcodes with coverage for an operator.\n\nNeeds geographic data from 'mobile-checker setup --geocode'.",
+		Args:    cobra.ExactArgs(1),
+		Example: "  mobile-checker nearest LD71AA --operator Three --tech 5g\n  mobile-checker nearest PH415RA --operator EE --tech 4g --indoor --limit 10",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c := checker.New(*dataDir, withPostcodes(), withEncryption())
+			res, err := c.Nearest(args[0], opts)
+			if err != nil {
+				return err
+			}
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(struct {
+					SchemaVersion int            `json:"schema_version"`
+					Dataset       *ofcom.Release `json:"dataset,omitempty"`
+					*checker.NearestResult
+				}{checker.SchemaVersion, datasetOf(c), res})
+			}
+			printNearest(res, opts)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&opts.Operator, "operator", "", "Operator: EE, O2, Three or Vodafone")
+	cmd.Flags().StringVar(&opts.Tech, "tech", "4g", "Technology: voice, 4g or 5g")
+	cmd.Flags().BoolVar(&opts.Indoor, "indoor", false, "Search indoor rather than outdoor coverage")
+	cmd.Flags().IntVar(&opts.Limit, "limit", 5, "Maximum number of postcodes to list")
+	cmd.Flags().Float64Var(&opts.MaxKm, "max-km", 20, "Search radius in km")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output results as JSON")
+	cmd.MarkFlagRequired("operator")
+	return cmd
+}
+
+func printNearest(r *checker.NearestResult, opts ofcom.NearestOptions) {
+	sep := rule(52)
+	want := fmt.Sprintf("%s %s", opts.Operator, strings.ToUpper(opts.Tech))
+	if opts.Indoor {
+		want += " (indoor)"
+	}
+	fmt.Printf("\n%s\n", sep)
+	fmt.Printf("  %s %s %s\n", r.Postcode, glyphs.Dash, want)
+	fmt.Printf("%s\n", sep)
+
+	fmt.Printf("\n  %s %s coverage here: %s\n", icon(r.Covered), want, r.Coverage)
+	switch {
+	case r.Covered:
+		fmt.Println("\n  No need to look further.")
+	case len(r.Nearest) == 0:
+		fmt.Printf("\n  No covered postcodes within %.0f km.\n", opts.MaxKm)
+	default:
+		fmt.Printf("\n  %-10s %-10s %s\n", "Postcode", "Distance", "Coverage")
+		fmt.Printf("  %s\n", rule(32))
+		for _, n := range r.Nearest {
+			fmt.Printf("  %-10s %-10s %s\n", n.Postcode, fmt.Sprintf("%.1f km", n.DistanceKm), n.Coverage)
+		}
+	}
+	fmt.Println("\n  Source: Ofcom Connected Nations (open data)")
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func newNotSpotsCmd(dataDir *string) *cobra.Command {
+	var opts ofcom.NotSpotOptions
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "notspots",
+		Short: "List postcodes where too few operators have coverage",
+		Long: "List postcodes where fewer than --operators networks cover the technology,\n" +
+			"by default those with no coverage from any network. With premises counts from\n" +
+			"'setup --premises' the postcodes with the most premises come first.\n\n" +
+			"--district and --region need geographic data from 'setup --geocode'.",
+		Args: cobra.NoArgs,
+		Example: "  mobile-checker notspots --district \"Westmorland and Furness\" --tech 4g\n" +
+			"  mobile-checker notspots --outcode LA22 --tech 5g --operators 2 --format csv > la22.csv",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spots, err := newManager(*dataDir).NotSpots(opts)
+			if err != nil {
+				return err
+			}
+			switch format {
+			case "table":
+				printNotSpots(opts, spots)
+				return nil
+			case "json":
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(spots)
+			case "csv":
+				w := csv.NewWriter(os.Stdout)
+				w.Write([]string{"postcode", "premises", "operators"})
+				for _, s := range spots {
+					w.Write([]string{s.Postcode, premisesCell(s.Premises, ""), strings.Join(s.Operators, ";")})
+				}
+				w.Flush()
+				return w.Error()
+			default:
+				return fmt.Errorf("--format must be table, json or csv")
+			}
+		},
+	}
+	cmd.Flags().StringVar(&opts.Area.District, "district", "", "Admin district, e.g. Leeds")
+	cmd.Flags().StringVar(&opts.Area.Region, "region", "", "Region, e.g. \"North West\"")
+	cmd.Flags().StringVar(&opts.Area.Outcode, "outcode", "", "Postcode outcode, e.g. LA22")
+	cmd.Flags().StringVar(&opts.Tech, "tech", "4g", "Technology: voice, 4g or 5g")
+	cmd.Flags().BoolVar(&opts.Indoor, "indoor", false, "Judge indoor rather than outdoor coverage")
+	cmd.Flags().IntVar(&opts.MinOperators, "operators", 1, "List postcodes covered by fewer than this many operators")
+	cmd.Flags().Float64Var(&opts.Threshold, "threshold", ofcom.CoverageThreshold, "Fraction of a postcode an operator must cover")
+	cmd.Flags().StringVar(&opts.Sort, "sort", "premises", "Order: premises (most first) or postcode")
+	cmd.Flags().IntVar(&opts.Limit, "limit", 0, "Most postcodes to list (0 for all)")
+	cmd.Flags().StringVar(&format, "format", "table", "Output format: table, json or csv")
+	return cmd
+}
+
+func printNotSpots(opts ofcom.NotSpotOptions, spots []ofcom.NotSpot) {
+	sep := rule(52)
+	tech := strings.ToUpper(opts.Tech)
+	if opts.Indoor {
+		tech += " indoor"
+	}
+	fmt.Printf("\n%s\n", sep)
+	if opts.MinOperators <= 1 {
+		fmt.Printf("  Not-spots %s no operator with %s\n", glyphs.Dash, tech)
+	} else {
+		fmt.Printf("  Not-spots %s fewer than %d operators with %s\n", glyphs.Dash, opts.MinOperators, tech)
+	}
+	if s := opts.Area.String(); s != "" {
+		fmt.Printf("  %s\n", s)
+	}
+	fmt.Printf("%s\n", sep)
+	if len(spots) == 0 {
+		fmt.Println("\n  None found.")
+		return
+	}
+	fmt.Printf("\n  %-9s %9s  %s\n", "Postcode", "Premises", "Covered by")
+	for _, s := range spots {
+		fmt.Printf("  %-9s %9s  %s\n", s.Postcode, premisesCell(s.Premises, "-"), orDash(strings.Join(s.Operators, ", ")))
+	}
+	fmt.Printf("\n  %d postcodes\n", len(spots))
+	fmt.Println("\n  Source: Ofcom Connected Nations (open data)")
+}
+
+// premisesCell formats a premises count, or none when it is unknown.
+func premisesCell(n *int, none string) string {
+	if n == nil {
+		return none
+	}
+	return strconv.Itoa(*n)
+}
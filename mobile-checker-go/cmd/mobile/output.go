@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"text/template"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+// datasetOf returns the release of c's dataset for the dataset field of a
+// JSON document, or nil when none is installed.
+func datasetOf(c *checker.Checker) *ofcom.Release {
+	rel, err := c.Release()
+	if err != nil {
+		return nil
+	}
+	return rel
+}
+
+// quiet is set by --quiet: no banner, status messages or progress logs,
+// only a command's output.
+var quiet bool
+
+// notef writes a status message, such as setup's progress, to stderr so
+// that stdout carries only a command's output. --quiet silences it.
+func notef(format string, args ...any) {
+	if !quiet {
+		fmt.Fprintf(os.Stderr, format, args...)
+	}
+}
+
+// isBrokenPipe reports whether err is from writing to a pipe whose reader
+// has gone, e.g. output piped into head.
+func isBrokenPipe(err error) bool {
+	return errors.Is(err, syscall.EPIPE)
+}
+
+// readPostcodes returns the postcodes in a --file: one per line, skipping
+// blank lines and # comments. A line that is a JSON object, as from jq -c,
+// gives its "postcode" field, so NDJSON can be piped in. "-" reads stdin.
+func readPostcodes(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	var pcs []string
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "{") {
+			var obj struct {
+				Postcode string `json:"postcode"`
+			}
+			if err := json.Unmarshal([]byte(line), &obj); err != nil {
+				return nil, fmt.Errorf("%s line %d: invalid JSON: %w", path, n, err)
+			}
+			if obj.Postcode == "" {
+				return nil, fmt.Errorf("%s line %d: JSON object has no postcode field", path, n)
+			}
+			line = obj.Postcode
+		}
+		pcs = append(pcs, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return pcs, nil
+}
+
+// resultWriter writes check results as they arrive, flushing each so a
+// reader downstream sees it at once and a closed pipe is noticed early.
+type resultWriter interface {
+	Write(r checker.Result) error
+	// Close ends the output, e.g. the closing bracket of a JSON array.
+	Close() error
+}
+
+// Result output formats for newResultWriter.
+const (
+	formatText   = "text"
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+)
+
+// newResultWriter returns a writer for format, or for tmpl when it is set
+// and format is text.
+func newResultWriter(w io.Writer, format string, tmpl *template.Template) resultWriter {
+	bw := bufio.NewWriter(w)
+	switch {
+	case format == formatJSON:
+		return &jsonResultWriter{w: bw}
+	case format == formatNDJSON:
+		return &ndjsonResultWriter{w: bw, enc: json.NewEncoder(bw)}
+	case tmpl != nil:
+		return &templateResultWriter{w: bw, tmpl: newTemplateWriter(bw, tmpl)}
+	}
+	return &textResultWriter{w: bw}
+}
+
+// jsonResultWriter writes a JSON array, indented as json.Encoder would, one
+// element at a time. Each element carries the schema version beside the
+// result's own dataset.
+type jsonResultWriter struct {
+	w *bufio.Writer
+	n int
+}
+
+// versionedResult is an element of the --json array.
+type versionedResult struct {
+	SchemaVersion int `json:"schema_version"`
+	checker.Result
+}
+
+func (j *jsonResultWriter) Write(r checker.Result) error {
+	b, err := json.MarshalIndent(versionedResult{checker.SchemaVersion, r}, "  ", "  ")
+	if err != nil {
+		return err
+	}
+	sep := ",\n  "
+	if j.n == 0 {
+		sep = "[\n  "
+	}
+	j.n++
+	j.w.WriteString(sep)
+	j.w.Write(b)
+	return j.w.Flush()
+}
+
+func (j *jsonResultWriter) Close() error {
+	if j.n == 0 {
+		j.w.WriteString("[]\n")
+	} else {
+		j.w.WriteString("\n]\n")
+	}
+	return j.w.Flush()
+}
+
+// ndjsonResultWriter writes one compact JSON object per line, as the
+// elements of the --json array, so each can be read, e.g. by jq, as soon as
+// it is checked.
+type ndjsonResultWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (j *ndjsonResultWriter) Write(r checker.Result) error {
+	if err := j.enc.Encode(versionedResult{checker.SchemaVersion, r}); err != nil {
+		return err
+	}
+	return j.w.Flush()
+}
+
+func (j *ndjsonResultWriter) Close() error { return j.w.Flush() }
+
+// templateResultWriter writes results through a --template.
+type templateResultWriter struct {
+	w    *bufio.Writer
+	tmpl *templateWriter
+}
+
+func (t *templateResultWriter) Write(r checker.Result) error {
+	if err := t.tmpl.Write(r); err != nil {
+		return err
+	}
+	return t.w.Flush()
+}
+
+func (t *templateResultWriter) Close() error {
+	if err := t.tmpl.Close(); err != nil {
+		return err
+	}
+	return t.w.Flush()
+}
+
+// textResultWriter writes the human-readable report of printResult.
+type textResultWriter struct {
+	w *bufio.Writer
+	n int
+}
+
+func (t *textResultWriter) Write(r checker.Result) error {
+	if t.n > 0 {
+		fmt.Fprintln(t.w)
+	}
+	t.n++
+	printResult(t.w, r)
+	return t.w.Flush()
+}
+
+func (t *textResultWriter) Close() error { return t.w.Flush() }
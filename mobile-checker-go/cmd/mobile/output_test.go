@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+)
+
+func TestJSONResultWriter_MatchesEncoder(t *testing.T) {
+	for _, results := range [][]checker.Result{nil, templateResults()} {
+		var got bytes.Buffer
+		out := newResultWriter(&got, formatJSON, nil)
+		for _, r := range results {
+			if err := out.Write(r); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := out.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		var want bytes.Buffer
+		enc := json.NewEncoder(&want)
+		enc.SetIndent("", "  ")
+		versioned := []versionedResult{}
+		for _, r := range results {
+			versioned = append(versioned, versionedResult{checker.SchemaVersion, r})
+		}
+		if err := enc.Encode(versioned); err != nil {
+			t.Fatal(err)
+		}
+		if got.String() != want.String() {
+			t.Errorf("streamed JSON differs from json.Encoder:\n%s\nwant:\n%s", got.String(), want.String())
+		}
+	}
+}
+
+func TestReadPostcodes_SkipsBlankLinesAndComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "postcodes.txt")
+	if err := os.WriteFile(path, []byte("# sites\nSW1A 1AA\n\n  LS11AA  \r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pcs, err := readPostcodes(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(pcs, ",") != "SW1A 1AA,LS11AA" {
+		t.Errorf("unexpected postcodes %q", pcs)
+	}
+}
+
+func TestNDJSONResultWriter_WritesOneObjectPerLine(t *testing.T) {
+	var got bytes.Buffer
+	out := newResultWriter(&got, formatNDJSON, nil)
+	results := templateResults()
+	for _, r := range results {
+		if err := out.Write(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(got.String(), "\n"), "\n")
+	if len(lines) != len(results) {
+		t.Fatalf("expected %d lines, got %q", len(results), got.String())
+	}
+	for i, line := range lines {
+		var v versionedResult
+		if err := json.Unmarshal([]byte(line), &v); err != nil {
+			t.Fatalf("line %d is not JSON: %v", i+1, err)
+		}
+		if v.SchemaVersion != checker.SchemaVersion || v.Postcode != results[i].Postcode {
+			t.Errorf("line %d: got %+v", i+1, v)
+		}
+	}
+}
+
+func TestReadPostcodes_AcceptsNDJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "postcodes.ndjson")
+	if err := os.WriteFile(path, []byte("{\"postcode\":\"SW1A 1AA\",\"site\":\"office\"}\nLS11AA\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pcs, err := readPostcodes(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(pcs, ",") != "SW1A 1AA,LS11AA" {
+		t.Errorf("unexpected postcodes %q", pcs)
+	}
+
+	if err := os.WriteFile(path, []byte("{\"name\":\"office\"}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readPostcodes(path); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("expected an object without a postcode to be refused by line, got %v", err)
+	}
+}
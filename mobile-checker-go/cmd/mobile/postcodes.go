@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/pccache"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+// The --postcodes-* flags configure every postcodes.io client the CLI
+// makes, e.g. to use a self-hosted instance.
+var (
+	// postcodesRate is postcodes.io requests per second, bursting to
+	// twice that.
+	postcodesRate    = postcode.DefaultRateLimit.PerSecond
+	postcodesURL     string
+	postcodesHeaders []string
+	postcodesTimeout = 10 * time.Second
+	// postcodesCacheTTL keeps lookups in postcodes-cache.db for this
+	// long; 0 disables the cache.
+	postcodesCacheTTL time.Duration
+
+	// postcodeOpts are the flags as client options, set by
+	// parsePostcodesFlags.
+	postcodeOpts []postcode.Option
+)
+
+// parsePostcodesFlags checks the --postcodes-* flags and turns them into
+// postcodeOpts. The lookup cache lives in dataDir.
+func parsePostcodesFlags(dataDir string) error {
+	l := postcode.RateLimit{PerSecond: postcodesRate, Burst: max(1, int(2*postcodesRate))}
+	opts := []postcode.Option{postcode.WithRateLimit(l), postcode.WithTimeout(postcodesTimeout)}
+	if postcodesURL != "" {
+		opts = append(opts, postcode.WithBaseURL(postcodesURL))
+	}
+	for _, h := range postcodesHeaders {
+		k, v, err := postcode.ParseHeader(h)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, postcode.WithHeader(k, v))
+	}
+	if postcodesCacheTTL < 0 {
+		return fmt.Errorf("--postcodes-cache-ttl must not be negative")
+	}
+	if postcodesCacheTTL > 0 {
+		opts = append(opts, postcode.WithCache(pccache.New(dataDir, postcodesCacheTTL)))
+	}
+	postcodeOpts = opts
+	return nil
+}
+
+// withPostcodes gives a Checker a postcodes.io client configured by the
+// --postcodes-* flags.
+func withPostcodes() checker.Option {
+	return checker.WithPostcodeClient(postcode.NewClient(postcodeOpts...))
+}
+
+// logThrottling reports how long c's postcodes.io requests were held back
+// by the rate limit, if at all, so a slow bulk run is explained.
+func logThrottling(c *checker.Checker) {
+	st := c.PostcodeStats()
+	if st.CacheHits > 0 {
+		slog.Info("postcodes.io lookups answered from cache", "hits", st.CacheHits, "requests", st.Requests)
+	}
+	if st.Throttled == 0 && st.RateLimited == 0 {
+		return
+	}
+	slog.Info("postcodes.io requests throttled", "requests", st.Requests, "throttled", st.Throttled,
+		"waited", st.Waited.Round(100*time.Millisecond).String(), "rate_limited", st.RateLimited)
+}
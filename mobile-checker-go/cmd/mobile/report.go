@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/report"
+)
+
+func newReportCmd(dataDir *string) *cobra.Command {
+	var out, title string
+	var opts checker.CheckOptions
+
+	cmd := &cobra.Command{
+		Use:     "report <POSTCODE...>",
+		Short:   "Write a self-contained HTML coverage report",
+		Args:    cobra.MinimumNArgs(1),
+		Example: "  mobile-checker report SW1A1AA --out report.html\n  mobile-checker report LS11AA LS11AB --title \"12 Park Row\" --indoor",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c := checker.New(*dataDir, withPostcodes(), withEncryption())
+			r := report.Report{Title: title, Entries: make([]report.Entry, len(args))}
+			if meta, err := c.DatasetMeta(); err == nil {
+				r.Dataset = meta["dataset_year"]
+			}
+			compare := len(c.InstalledYears()) > 1
+			for res := range c.StreamWith(context.Background(), args, 4, opts) {
+				e := report.Entry{Result: res.Result}
+				if compare && res.Valid {
+					years, err := c.CoverageByYear(res.Postcode, opts)
+					if err != nil {
+						return err
+					}
+					e.Years = years
+				}
+				r.Entries[res.Index] = e
+			}
+
+			f, err := os.Create(out)
+			if err != nil {
+				return err
+			}
+			if err := report.Write(f, r); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			notef("%s Report written to %s\n", glyphs.Yes, out)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&out, "out", "o", "report.html", "Output HTML file")
+	cmd.Flags().StringVar(&title, "title", "", "Report title (default \"UK Mobile Coverage Report\")")
+	cmd.Flags().BoolVar(&opts.Indoor, "indoor", false, "Report indoor rather than outdoor coverage")
+	return cmd
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func newRouteCmd(dataDir *string) *cobra.Command {
+	var opts checker.RouteOptions
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:     "route <FROM> <TO>",
+		Short:   "Check mobile coverage along a route between two postcodes",
+		Args:    cobra.ExactArgs(2),
+		Example: "  mobile-checker route SW1A1AA EC1A1BB\n  mobile-checker route LS11AA YO17HH --samples 40 --osrm https://router.project-osrm.org",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c := checker.New(*dataDir, withPostcodes(), withEncryption(), withSource())
+			res, err := c.Route(args[0], args[1], opts)
+			if err != nil {
+				return err
+			}
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(struct {
+					SchemaVersion int            `json:"schema_version"`
+					Dataset       *ofcom.Release `json:"dataset,omitempty"`
+					*checker.RouteResult
+				}{checker.SchemaVersion, datasetOf(c), res})
+			}
+			printRoute(res)
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&opts.Samples, "samples", 20, "Number of points to sample along the route")
+	cmd.Flags().IntVar(&opts.Workers, "workers", checker.DefaultWorkers, "Concurrent postcode lookups along the route")
+	cmd.Flags().StringVar(&opts.OSRMURL, "osrm", "", "OSRM server URL for a road route (default: straight line)")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output results as JSON")
+	return cmd
+}
+
+func printRoute(r *checker.RouteResult) {
+	sep := rule(52)
+	fmt.Printf("\n%s\n", sep)
+	fmt.Printf("  Route: %s %s %s (%.1f km, %s)\n", r.From, glyphs.Arrow, r.To, r.DistanceKm, r.Method)
+	fmt.Printf("%s\n", sep)
+
+	fmt.Printf("\n  %-8s %-9s %-8s %-8s %-8s %-8s\n", "km", "Postcode", "EE", "O2", "Three", "Vodafone")
+	fmt.Printf("  %s\n", rule(50))
+	for _, pt := range r.Points {
+		if pt.Mobile == nil {
+			fmt.Printf("  %-8.1f %-9s %s\n", pt.DistanceKm, pt.Postcode, "no data")
+			continue
+		}
+		cells := make([]string, 0, len(pt.Mobile.Operators))
+		for _, op := range pt.Mobile.Operators {
+			cells = append(cells, fmt.Sprintf("%s4G %s5G", icon(op.HasFourG), icon(op.HasFiveG)))
+		}
+		fmt.Printf("  %-8.1f %-9s %s\n", pt.DistanceKm, pt.Postcode, strings.Join(cells, "  "))
+	}
+	fmt.Printf("  %s\n", rule(50))
+
+	if len(r.Gaps) == 0 {
+		fmt.Println("\n  No 4G/5G gaps found along the route.")
+	} else {
+		fmt.Println("\n  Coverage gaps:")
+		for _, g := range r.Gaps {
+			fmt.Printf("  %-9s %-3s km %.1f%s%.1f (%s %s %s)\n",
+				g.Operator, g.Technology, g.StartKm, glyphs.Range, g.EndKm, g.FromPostcode, glyphs.Arrow, g.ToPostcode)
+		}
+	}
+	fmt.Println("\n  Source: Ofcom Connected Nations (open data)")
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/sites"
+)
+
+func newSitesCmd(dataDir *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sites",
+		Short: "Save named postcodes and compare coverage across them",
+		Long: "Save the postcodes you check again and again under names such as home and\n" +
+			"office, then check them all at once with 'sites check'. Sites are kept in\n" +
+			"sites.db in the data directory and served by the server at /api/sites.",
+	}
+
+	addCmd := &cobra.Command{
+		Use:     "add <NAME> <POSTCODE>",
+		Short:   "Save a postcode under a name, replacing any site of that name",
+		Args:    cobra.ExactArgs(2),
+		Example: "  mobile-checker sites add home SW1A1AA\n  mobile-checker sites add office \"EC1A 1BB\"",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			site, replaced, err := sites.New(*dataDir).Add(args[0], args[1])
+			if err != nil {
+				return err
+			}
+			if replaced {
+				fmt.Printf("%s Updated %s %s %s\n", glyphs.Yes, site.Name, glyphs.Arrow, site.Postcode)
+			} else {
+				fmt.Printf("%s Saved %s %s %s\n", glyphs.Yes, site.Name, glyphs.Arrow, site.Postcode)
+			}
+			return nil
+		},
+	}
+
+	removeCmd := &cobra.Command{
+		Use:   "remove <NAME>",
+		Short: "Forget a saved site",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := sites.New(*dataDir).Remove(args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Removed %s.\n", args[0])
+			return nil
+		},
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List saved sites",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			list, err := sites.New(*dataDir).List()
+			if err != nil {
+				return err
+			}
+			if len(list) == 0 {
+				fmt.Println("No sites saved: add one with 'mobile-checker sites add <name> <postcode>'.")
+				return nil
+			}
+			fmt.Printf("  %-16s %s\n", "Site", "Postcode")
+			for _, s := range list {
+				fmt.Printf("  %-16s %s\n", s.Name, s.Postcode)
+			}
+			return nil
+		},
+	}
+
+	var operators, format string
+	checkCmd := &cobra.Command{
+		Use:   "check",
+		Short: "Compare coverage across every saved site",
+		Long: "Check every saved site and print a matrix of coverage by operator and\n" +
+			"technology, one column per site, followed by a ranking of the sites.",
+		Args:    cobra.NoArgs,
+		Example: "  mobile-checker sites check\n  mobile-checker sites check --operator ee,three --format json",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ops, brands, err := ofcom.ParseOperatorList(operators)
+			if err != nil {
+				return err
+			}
+			list, err := sites.New(*dataDir).List()
+			if err != nil {
+				return err
+			}
+			if len(list) == 0 {
+				return fmt.Errorf("no sites saved: add one with 'mobile-checker sites add <name> <postcode>'")
+			}
+			c := checker.New(*dataDir, withPostcodes(), withEncryption(), withSource())
+			defer c.Close()
+			results := c.CheckSites(context.Background(), list, checker.CheckOptions{Operators: ops, Brands: brands}, checker.BulkOptions{})
+			ranking := checker.Rank(results)
+
+			switch format {
+			case "table":
+				printMatrix(results, ranking)
+				return nil
+			case "json":
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(struct {
+					SchemaVersion int               `json:"schema_version"`
+					Dataset       *ofcom.Release    `json:"dataset,omitempty"`
+					Results       []checker.Result  `json:"results"`
+					Ranking       []checker.Ranking `json:"ranking"`
+				}{checker.SchemaVersion, datasetOf(c), results, ranking})
+			case "csv":
+				w := csv.NewWriter(os.Stdout)
+				w.WriteAll(matrixRows(results))
+				return w.Error()
+			default:
+				return fmt.Errorf("--format must be table, json or csv")
+			}
+		},
+	}
+	checkCmd.Flags().StringVar(&operators, "operator", "", "Only compare these operators or MVNO brands, comma-separated, e.g. ee,three or giffgaff")
+	checkCmd.Flags().StringVar(&format, "format", "table", "Output format: table, json or csv")
+
+	cmd.AddCommand(addCmd, removeCmd, listCmd, checkCmd)
+	return cmd
+}
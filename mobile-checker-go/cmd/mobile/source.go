@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/ofcomapi"
+)
+
+// The --source flags choose where checks get coverage from: the local
+// database built by setup, a database served over HTTP, or Ofcom's
+// coverage API.
+var (
+	source          = "local"
+	ofcomAPIKey     string
+	ofcomAPIKeyFile string
+	remoteDB        string
+
+	// sourceOpt is the flags as a checker option, set by parseSourceFlags;
+	// nil for the local database.
+	sourceOpt checker.Option
+)
+
+// parseSourceFlags checks the --source flags and turns them into
+// sourceOpt. The key may come from --ofcom-api-key (or its environment
+// variable or config key) or from a file, e.g. a container secret.
+func parseSourceFlags() error {
+	switch source {
+	case "local":
+		sourceOpt = nil
+		return nil
+	case "remote":
+		if remoteDB == "" {
+			return fmt.Errorf("--source remote needs --remote-db, the URL of a mobile.db")
+		}
+		sourceOpt = checker.WithRemoteDataset(remoteDB, ofcom.RemoteOptions{})
+		return nil
+	case "api":
+	default:
+		return fmt.Errorf("--source must be local, remote or api")
+	}
+	key := ofcomAPIKey
+	if ofcomAPIKeyFile != "" {
+		b, err := os.ReadFile(ofcomAPIKeyFile)
+		if err != nil {
+			return fmt.Errorf("reading Ofcom API key: %w", err)
+		}
+		key = strings.TrimSpace(string(b))
+	}
+	if key == "" {
+		return fmt.Errorf("--source api needs --ofcom-api-key or --ofcom-api-key-file: %w", ofcomapi.ErrInvalidKey)
+	}
+	sourceOpt = checker.WithPrimarySource(ofcomapi.New(key))
+	return nil
+}
+
+// withSource gives a Checker the coverage source chosen by --source.
+func withSource() checker.Option {
+	if sourceOpt == nil {
+		return func(*checker.Checker) {}
+	}
+	return sourceOpt
+}
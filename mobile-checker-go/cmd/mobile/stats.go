@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func newStatsCmd(dataDir *string) *cobra.Command {
+	var by, format, constituency string
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Aggregate coverage statistics by country, region, district or constituency",
+		Long: "Aggregate coverage statistics across the whole installed dataset.\n\n" +
+			"Needs geographic data from 'mobile-checker setup --geocode'.",
+		Args: cobra.NoArgs,
+		Example: "  mobile-checker stats --by country\n  mobile-checker stats --by district --format csv > districts.csv\n" +
+			"  mobile-checker stats --constituency \"Cities of London and Westminster\"",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, ok := ofcom.AreaLevels[by]; !ok {
+				return fmt.Errorf("--by must be one of %s", strings.Join(areaLevels(), ", "))
+			}
+			c := checker.New(*dataDir, withPostcodes(), withEncryption())
+			var stats []ofcom.AreaSummary
+			if constituency != "" {
+				summary, err := c.Aggregate("constituency", constituency)
+				if err != nil {
+					return err
+				}
+				if summary == nil {
+					return fmt.Errorf("no geocoded postcodes found for constituency %q", constituency)
+				}
+				stats = []ofcom.AreaSummary{*summary}
+			} else {
+				var err error
+				if stats, err = c.Stats(by); err != nil {
+					return err
+				}
+			}
+			switch format {
+			case "table":
+				if constituency != "" {
+					printArea(stats[0])
+					return nil
+				}
+				printStats(by, stats)
+				return nil
+			case "json":
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(stats)
+			case "csv":
+				return writeStatsCSV(stats)
+			default:
+				return fmt.Errorf("--format must be table, json or csv")
+			}
+		},
+	}
+	cmd.Flags().StringVar(&by, "by", "country", "Area level: "+strings.Join(areaLevels(), ", "))
+	cmd.Flags().StringVar(&format, "format", "table", "Output format: table, json or csv")
+	cmd.Flags().StringVar(&constituency, "constituency", "", "Report a single parliamentary constituency by name")
+	return cmd
+}
+
+func areaLevels() []string {
+	levels := make([]string, 0, len(ofcom.AreaLevels))
+	for l := range ofcom.AreaLevels {
+		levels = append(levels, l)
+	}
+	sort.Strings(levels)
+	return levels
+}
+
+func printStats(level string, stats []ofcom.AreaSummary) {
+	sep := rule(52)
+	fmt.Printf("\n%s\n", sep)
+	fmt.Printf("  Coverage by %s %s %% of postcodes with outdoor 4G\n", level, glyphs.Dash)
+	fmt.Printf("%s\n", sep)
+	if len(stats) == 0 {
+		fmt.Printf("\n  No geocoded postcodes %s run: mobile-checker setup --geocode\n", glyphs.Dash)
+		return
+	}
+
+	fmt.Printf("\n  %-28s %9s %6s %6s %6s %9s %7s %7s\n", "Area", "Postcodes", "EE", "O2", "Three", "Vodafone", "All 4G", "Any 5G")
+	for _, s := range stats {
+		name := s.Name
+		if len([]rune(name)) > 28 {
+			name = string([]rune(name)[:27]) + glyphs.Ellipsis
+		}
+		fmt.Printf("  %-28s %9d", name, s.Postcodes)
+		for i, op := range s.Operators {
+			width := 6
+			if i == 3 {
+				width = 9
+			}
+			fmt.Printf(" %*.1f", width, op.PctFourG)
+		}
+		fmt.Printf(" %7.1f %7.1f\n", s.AllFourG, s.AnyFiveG)
+	}
+	fmt.Println("\n  Source: Ofcom Connected Nations (open data)")
+}
+
+// printArea prints the full per-operator breakdown for one area.
+func printArea(s ofcom.AreaSummary) {
+	sep := rule(52)
+	fmt.Printf("\n%s\n", sep)
+	fmt.Printf("  %s (%s)\n", s.Name, s.Level)
+	fmt.Printf("%s\n", sep)
+	fmt.Printf("  Postcodes: %d\n", s.Postcodes)
+	fmt.Printf("\n  %% of postcodes covered (mean coverage)\n")
+	fmt.Printf("  %-12s %-14s %-14s %-14s\n", "Operator", "Voice", "4G", "5G")
+	fmt.Printf("  %s\n", rule(54))
+	cell := func(pct, mean float64) string { return fmt.Sprintf("%5.1f (%5.1f)", pct, mean) }
+	for _, op := range s.Operators {
+		fmt.Printf("  %-12s %-14s %-14s %-14s\n", op.Name,
+			cell(op.PctVoice, op.MeanVoice), cell(op.PctFourG, op.MeanFourG), cell(op.PctFiveG, op.MeanFiveG))
+	}
+	fmt.Printf("  %s\n", rule(54))
+	fmt.Printf("  All operators 4G: %.1f%%   Any operator 5G: %.1f%%\n", s.AllFourG, s.AnyFiveG)
+	fmt.Println("\n  Source: Ofcom Connected Nations (open data)")
+}
+
+func writeStatsCSV(stats []ofcom.AreaSummary) error {
+	w := csv.NewWriter(os.Stdout)
+	header := []string{"level", "name", "postcodes"}
+	for _, op := range ofcom.Operators {
+		for _, col := range []string{"mean_voice_pct", "voice_covered_pct", "mean_4g_pct", "4g_covered_pct", "mean_5g_pct", "5g_covered_pct"} {
+			header = append(header, op+"_"+col)
+		}
+	}
+	header = append(header, "all_operators_4g_pct", "any_operator_5g_pct")
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 1, 64) }
+	for _, s := range stats {
+		rec := []string{s.Level, s.Name, strconv.Itoa(s.Postcodes)}
+		for _, op := range s.Operators {
+			rec = append(rec, f(op.MeanVoice), f(op.PctVoice), f(op.MeanFourG), f(op.PctFourG), f(op.MeanFiveG), f(op.PctFiveG))
+		}
+		rec = append(rec, f(s.AllFourG), f(s.AnyFiveG))
+		if err := w.Write(rec); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func newStatusCmd(dataDir *string) *cobra.Command {
+	var jsonOutput, offline bool
+
+	cmd := &cobra.Command{
+		Use:     "status",
+		Aliases: []string{"doctor"},
+		Short:   "Diagnose the installation: dataset, schema, manifest and postcodes.io",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report, err := checker.New(*dataDir, withPostcodes(), withEncryption()).Doctor(offline)
+			if err != nil {
+				return err
+			}
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(report)
+			}
+			printReport(report)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the report as JSON")
+	cmd.Flags().BoolVar(&offline, "offline", false, "Skip the postcodes.io reachability check")
+	return cmd
+}
+
+func printReport(r *checker.Report) {
+	sep := rule(52)
+	fmt.Printf("\n%s\n  Data directory: %s\n%s\n", sep, r.DataDir, sep)
+	fmt.Printf("  SQLite driver:  %s\n", r.Driver)
+
+	st := r.Dataset
+	if !st.Exists {
+		fmt.Printf("  Database:       %s not found (%s)\n", glyphs.No, st.Path)
+	} else if st.Error != "" {
+		fmt.Printf("  Database:       %s (%.1f MB)\n", st.Path, float64(st.SizeBytes)/(1<<20))
+		fmt.Printf("                  %s unreadable: %s\n", glyphs.No, st.Error)
+	} else {
+		fmt.Printf("  Database:       %s (%.1f MB)\n", st.Path, float64(st.SizeBytes)/(1<<20))
+		fmt.Printf("  Schema:         v%d (current v%d)\n", st.SchemaVersion, r.SchemaVersion)
+		fmt.Printf("  Year:           %s\n", orDash(ofcom.Release{Year: st.Year, Revision: st.Revision}.String()))
+		fmt.Printf("  Built:          %s\n", orDash(st.BuiltAt))
+		fmt.Printf("  Rows:           %d (%d geocoded)\n", st.Rows, st.GeocodedRows)
+		fmt.Printf("  Columns:        %s, %d coverage columns\n", st.ColumnSet, len(st.Columns))
+		for _, line := range wrapList(st.Columns, 50) {
+			fmt.Printf("                  %s\n", line)
+		}
+		if len(st.Nations) > 0 {
+			fmt.Printf("  Nations:        %s\n", strings.Join(st.Nations, ", "))
+		}
+		if len(st.Layers) > 0 {
+			fmt.Printf("  Layers:         %s editions over the UK-wide file\n", strings.Join(st.Layers, ", "))
+		}
+	}
+
+	if mf := r.Manifest; mf != nil {
+		verified := glyphs.No + " not verified (no known checksum)"
+		if mf.Verified {
+			verified = glyphs.Yes + " verified"
+		}
+		fmt.Printf("\n  Source:         %s\n", mf.SourceURL)
+		fmt.Printf("  Downloaded:     %s\n", mf.DownloadedAt.Format("2006-01-02 15:04:05 MST"))
+		fmt.Printf("  SHA-256:        %s\n", mf.SHA256)
+		fmt.Printf("  Checksum:       %s\n", verified)
+	}
+
+	if p := r.PostcodesIO; p.Checked {
+		if p.Reachable {
+			fmt.Printf("\n  postcodes.io:   %s reachable (%d ms)\n", glyphs.Yes, p.LatencyMs)
+		} else {
+			fmt.Printf("\n  postcodes.io:   %s unreachable\n", glyphs.No)
+		}
+	}
+
+	if len(r.Problems) == 0 {
+		fmt.Printf("\n  %s No problems found.\n", glyphs.Yes)
+		return
+	}
+	fmt.Println("\n  Problems:")
+	for _, p := range r.Problems {
+		fmt.Printf("  %s %s\n    %s %s\n", glyphs.No, p.Message, glyphs.Arrow, p.Fix)
+	}
+}
+
+// wrapList joins items with commas into lines of about width characters.
+func wrapList(items []string, width int) []string {
+	var lines []string
+	line := ""
+	for i, it := range items {
+		if i < len(items)-1 {
+			it += ","
+		}
+		if line != "" && len(line)+1+len(it) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += it
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
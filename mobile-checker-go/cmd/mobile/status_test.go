@@ -0,0 +1,28 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWrapList(t *testing.T) {
+	for _, tc := range []struct {
+		items []string
+		width int
+		want  []string
+	}{
+		{nil, 20, nil},
+		{[]string{"ee_4g"}, 20, []string{"ee_4g"}},
+		{[]string{"ee_voice", "ee_4g", "ee_5g"}, 50, []string{"ee_voice, ee_4g, ee_5g"}},
+		// Lines break before an item that would overflow; commas stay at
+		// the end of the line.
+		{[]string{"ee_voice", "ee_4g", "ee_5g", "o2_voice"}, 16, []string{"ee_voice, ee_4g,", "ee_5g, o2_voice"}},
+		// An item wider than the line gets a line of its own.
+		{[]string{"a", "any_coverage_indoor", "b"}, 8, []string{"a,", "any_coverage_indoor,", "b"}},
+	} {
+		got := wrapList(tc.items, tc.width)
+		if strings.Join(got, "|") != strings.Join(tc.want, "|") || len(got) != len(tc.want) {
+			t.Errorf("wrapList(%q, %d): expected %q, got %q", tc.items, tc.width, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/mobile-checker/internal/checker"
+)
+
+func newSuggestCmd(dataDir *string) *cobra.Command {
+	var limit int
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:     "suggest <PARTIAL POSTCODE>",
+		Short:   "List postcodes starting with a partial postcode",
+		Args:    cobra.ExactArgs(1),
+		Example: "  mobile-checker suggest SW1\n  mobile-checker suggest \"SW1A 1\" --limit 20",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pcs, err := checker.New(*dataDir, withPostcodes(), withEncryption()).Suggest(args[0], limit)
+			if err != nil {
+				return err
+			}
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(pcs)
+			}
+			if len(pcs) == 0 {
+				fmt.Printf("No postcodes start with %q.\n", args[0])
+				return nil
+			}
+			for _, pc := range pcs {
+				fmt.Println(pc)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&limit, "limit", 10, "Maximum number of suggestions (up to 100)")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output suggestions as JSON")
+	return cmd
+}
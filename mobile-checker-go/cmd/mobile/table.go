@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+// column is a field of the operator table in text output.
+type column struct {
+	key    string // as given to --columns
+	header string
+	// value returns the cell for op, or ok false when op has no such data.
+	value func(op ofcom.OperatorCoverage) (c cell, ok bool)
+}
+
+// cell is a table cell, coloured by its tier.
+type cell struct {
+	text string
+	tier ofcom.Tier
+}
+
+// coverageCell is a tick or cross and a percentage, coloured by tier.
+func coverageCell(t ofcom.Tier, has bool, v string) (cell, bool) {
+	if v == "" {
+		return cell{text: "  -"}, false
+	}
+	return cell{text: icon(has) + " " + v, tier: t}, true
+}
+
+// indoorCell is coverageCell for indoor coverage reported alongside
+// outdoor.
+func indoorCell(op ofcom.OperatorCoverage, tech string) (cell, bool) {
+	in := op.Indoor
+	if in == nil {
+		return cell{text: "  -"}, false
+	}
+	switch tech {
+	case "voice":
+		return coverageCell(in.Tiers[tech], in.HasVoice, in.Voice)
+	case "4g":
+		return coverageCell(in.Tiers[tech], in.HasFourG, in.FourG)
+	}
+	return coverageCell(in.Tiers[tech], in.HasFiveG, in.FiveG)
+}
+
+// columns lists every column --columns accepts, in --wide order.
+var columns = []column{
+	{"voice", "Voice", func(op ofcom.OperatorCoverage) (cell, bool) {
+		return coverageCell(op.Tiers["voice"], op.HasVoice, op.Voice)
+	}},
+	{"4g", "4G", func(op ofcom.OperatorCoverage) (cell, bool) {
+		return coverageCell(op.Tiers["4g"], op.HasFourG, op.FourG)
+	}},
+	{"5g", "5G", func(op ofcom.OperatorCoverage) (cell, bool) {
+		return coverageCell(op.Tiers["5g"], op.HasFiveG, op.FiveG)
+	}},
+	{"3g", "3G", func(op ofcom.OperatorCoverage) (cell, bool) {
+		return coverageCell(op.Tiers["3g"], op.HasThreeG, op.ThreeG)
+	}},
+	{"2g", "2G", func(op ofcom.OperatorCoverage) (cell, bool) {
+		return coverageCell(op.Tiers["2g"], op.HasTwoG, op.TwoG)
+	}},
+	{"indoorvoice", "Indoor voice", func(op ofcom.OperatorCoverage) (cell, bool) {
+		return indoorCell(op, "voice")
+	}},
+	{"indoor4g", "Indoor 4G", func(op ofcom.OperatorCoverage) (cell, bool) {
+		return indoorCell(op, "4g")
+	}},
+	{"indoor5g", "Indoor 5G", func(op ofcom.OperatorCoverage) (cell, bool) {
+		return indoorCell(op, "5g")
+	}},
+	{"tier", "Tier", func(op ofcom.OperatorCoverage) (cell, bool) {
+		if op.Tier == "" {
+			return cell{text: "-"}, false
+		}
+		return cell{text: string(op.Tier), tier: op.Tier}, true
+	}},
+	{"premises", "Premises 4G", func(op ofcom.OperatorCoverage) (cell, bool) {
+		if op.Premises == nil {
+			return cell{text: "-"}, false
+		}
+		return cell{text: fmt.Sprint(op.Premises.FourG)}, true
+	}},
+}
+
+// columnKeys are the keys of columns, for help and errors.
+func columnKeys() string {
+	keys := make([]string, len(columns))
+	for i, c := range columns {
+		keys[i] = c.key
+	}
+	return strings.Join(keys, ",")
+}
+
+// The --columns and --wide flags choose the operator table's columns.
+var (
+	columnList string
+	wideOutput bool
+	// tableColumns is columnList parsed; nil means the default columns.
+	tableColumns []column
+)
+
+// addTableFlags adds --columns and --wide to cmd, a command printing the
+// operator table.
+func addTableFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&columnList, "columns", "", "Comma-separated operator table columns for text output: "+columnKeys()+" (default voice,4g,5g, and 3g,2g where published)")
+	cmd.Flags().BoolVar(&wideOutput, "wide", false, "Show every operator table column the results have data for, including indoor coverage")
+}
+
+// parseColumns parses a comma-separated list of column keys, e.g.
+// "voice,4g,indoor4g".
+func parseColumns(s string) ([]column, error) {
+	var out []column
+	for _, key := range strings.Split(s, ",") {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "" {
+			continue
+		}
+		i := slices.IndexFunc(columns, func(c column) bool { return c.key == key })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown column %q in --columns: use %s", key, columnKeys())
+		}
+		out = append(out, columns[i])
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("--columns needs at least one of %s", columnKeys())
+	}
+	return out, nil
+}
+
+// parseTableFlags applies --columns and --wide.
+func parseTableFlags() error {
+	if columnList != "" && wideOutput {
+		return fmt.Errorf("--columns and --wide cannot be used together")
+	}
+	tableColumns = nil
+	if columnList == "" {
+		return nil
+	}
+	var err error
+	tableColumns, err = parseColumns(columnList)
+	return err
+}
+
+// wantIndoor reports whether the table shows indoor coverage, which
+// checks then report alongside outdoor.
+func wantIndoor() bool {
+	if wideOutput {
+		return true
+	}
+	return slices.ContainsFunc(tableColumns, func(c column) bool { return strings.HasPrefix(c.key, "indoor") })
+}
+
+// columnsFor returns the columns to show for ops: those chosen with
+// --columns; with --wide, every column any operator has data for;
+// otherwise voice, 4G and 5G, and 3G and 2G where published.
+func columnsFor(ops []ofcom.OperatorCoverage) []column {
+	if tableColumns != nil {
+		return tableColumns
+	}
+	var out []column
+	for _, c := range columns {
+		switch c.key {
+		case "voice", "4g", "5g":
+			out = append(out, c)
+			continue
+		case "3g", "2g":
+		default:
+			if !wideOutput {
+				continue
+			}
+		}
+		for _, op := range ops {
+			if _, ok := c.value(op); ok {
+				out = append(out, c)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// columnGap separates table columns.
+const columnGap = "  "
+
+// printOperatorTable writes a table of ops with one row per operator,
+// each column as wide as its widest cell.
+func printOperatorTable(w io.Writer, ops []ofcom.OperatorCoverage) {
+	cols := columnsFor(ops)
+	rows := make([][]cell, len(ops))
+	widths := make([]int, len(cols)+1)
+	widths[0] = lipgloss.Width("Operator")
+	for i, op := range ops {
+		widths[0] = max(widths[0], lipgloss.Width(op.Label()))
+		rows[i] = make([]cell, len(cols))
+		for j, c := range cols {
+			rows[i][j], _ = c.value(op)
+		}
+	}
+	for j, c := range cols {
+		widths[j+1] = lipgloss.Width(c.header)
+		for _, row := range rows {
+			widths[j+1] = max(widths[j+1], lipgloss.Width(row[j].text))
+		}
+	}
+	total := (len(widths) - 1) * len(columnGap)
+	for _, n := range widths {
+		total += n
+	}
+
+	fmt.Fprintf(w, "  %s", pad("Operator", widths[0]))
+	for j, c := range cols {
+		fmt.Fprintf(w, "%s%s", columnGap, pad(c.header, widths[j+1]))
+	}
+	fmt.Fprintf(w, "\n  %s\n", rule(total))
+	for i, op := range ops {
+		fmt.Fprintf(w, "  %s", pad(op.Label(), widths[0]))
+		for j, c := range rows[i] {
+			s := pad(c.text, widths[j+1])
+			if style, ok := tierStyles[c.tier]; ok {
+				s = style.Render(s)
+			}
+			fmt.Fprintf(w, "%s%s", columnGap, s)
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "  %s\n", rule(total))
+}
+
+// pad right-pads s with spaces to n display columns.
+func pad(s string, n int) string {
+	return s + strings.Repeat(" ", max(0, n-lipgloss.Width(s)))
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func TestPrintOperatorTable_Columns(t *testing.T) {
+	defer func() { columnList, wideOutput, tableColumns = "", false, nil }()
+	ops := []ofcom.OperatorCoverage{
+		{Name: "O2", Brands: []string{"giffgaff", "Tesco Mobile"}, Voice: "100%", HasVoice: true, FourG: "98%", HasFourG: true, FiveG: "0%", Tier: ofcom.TierGood,
+			Indoor: &ofcom.IndoorCoverage{Voice: "90%", HasVoice: true, FourG: "71%", HasFourG: true, FiveG: "0%"}},
+		{Name: "EE", Voice: "100%", HasVoice: true, FourG: "100%", HasFourG: true, FiveG: "80%", HasFiveG: true},
+	}
+	table := func() string {
+		t.Helper()
+		if err := parseTableFlags(); err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		printOperatorTable(&buf, ops)
+		return buf.String()
+	}
+
+	got := table()
+	if strings.Contains(got, "Indoor") {
+		t.Errorf("expected no indoor columns by default, got:\n%s", got)
+	}
+	// Columns widen to fit the longest label rather than truncating it.
+	lines := strings.Split(got, "\n")
+	if !strings.HasPrefix(lines[2], "  O2 (giffgaff, Tesco Mobile) ") || !strings.HasPrefix(lines[3], "  EE                          ") {
+		t.Errorf("expected aligned operator names, got:\n%s", got)
+	}
+
+	columnList = "4g,indoor4g"
+	got = table()
+	if !strings.Contains(got, "Indoor 4G") || strings.Contains(got, "Voice") || !strings.Contains(got, "71%") {
+		t.Errorf("expected 4G and indoor 4G only, got:\n%s", got)
+	}
+	if !wantIndoor() {
+		t.Error("expected indoor4g to ask for indoor coverage")
+	}
+
+	columnList, wideOutput = "", true
+	got = table()
+	if !strings.Contains(got, "Indoor voice") || !strings.Contains(got, "Tier") || strings.Contains(got, "3G") {
+		t.Errorf("expected every column with data, got:\n%s", got)
+	}
+
+	columnList, wideOutput = "4g,lte", false
+	if err := parseTableFlags(); err == nil || !strings.Contains(err.Error(), "lte") {
+		t.Errorf("expected an unknown column error, got %v", err)
+	}
+}
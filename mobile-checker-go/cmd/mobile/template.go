@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+)
+
+// builtinTemplates are the named --template formats. Each is run once per
+// result; a "header" template, if defined, is run once before them.
+var builtinTemplates = map[string]string{
+	"short": `{{.Postcode}}: ` +
+		`{{- if .Error}} error: {{.Error}}` +
+		`{{- else if .Mobile}} {{.Mobile.CoverageScore}}/100 ({{.Mobile.Grade}})` +
+		`{{- range .Mobile.Operators}}, {{.Label}} 4G {{.FourG}} 5G {{.FiveG}}{{end}}` +
+		`{{- else}} {{.Note}}{{end}}`,
+
+	"table": `{{define "header"}}POSTCODE	OPERATOR	VOICE	4G	5G	SCORE{{end}}` +
+		`{{- $r := .}}{{if .Mobile}}{{range .Mobile.Operators}}` +
+		`{{$r.Postcode}}	{{.Label}}	{{.Voice}}	{{.FourG}}	{{.FiveG}}	{{$r.Mobile.CoverageScore}} ({{$r.Mobile.Grade}})` + "\n" +
+		`{{end}}{{else}}{{.Postcode}}	{{or .Error .Note}}{{end}}`,
+
+	"markdown": `{{define "header"}}| Postcode | Operator | Voice | 4G | 5G | Score |` + "\n" +
+		`|---|---|---|---|---|---|{{end}}` +
+		`{{- $r := .}}{{if .Mobile}}{{range .Mobile.Operators}}` +
+		`| {{$r.Postcode}} | {{.Label}} | {{icon .HasVoice}} {{.Voice}} | {{icon .HasFourG}} {{.FourG}} | {{icon .HasFiveG}} {{.FiveG}} | {{$r.Mobile.CoverageScore}} ({{$r.Mobile.Grade}}) |` + "\n" +
+		`{{end}}{{else}}| {{.Postcode}} | {{or .Error .Note}} | | | | |{{end}}`,
+}
+
+// templateFuncs are available to every --template.
+var templateFuncs = template.FuncMap{
+	"icon":  icon,
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// parseTemplate resolves a --template value: a built-in name, template
+// text containing "{{", or the path of a template file.
+func parseTemplate(spec string) (*template.Template, error) {
+	text, ok := builtinTemplates[spec]
+	switch {
+	case ok:
+	case strings.Contains(spec, "{{"):
+		text = spec
+	default:
+		b, err := os.ReadFile(spec)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("--template %q is not a file or one of %s", spec, strings.Join(templateNames(), ", "))
+			}
+			return nil, err
+		}
+		text = string(b)
+	}
+	return template.New("result").Funcs(templateFuncs).Parse(text)
+}
+
+func templateNames() []string {
+	names := make([]string, 0, len(builtinTemplates))
+	for name := range builtinTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// renderTemplate writes results through tmpl, each ending in a newline.
+// Tabs align columns across every result.
+func renderTemplate(w io.Writer, tmpl *template.Template, results []checker.Result) error {
+	tw := newTemplateWriter(w, tmpl)
+	for _, r := range results {
+		if err := tw.Write(r); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// templateWriter renders results through a template one at a time, for
+// output that streams. Tab-aligned lines are held back until a line
+// without tabs or Close, as alignment depends on the lines that follow.
+type templateWriter struct {
+	tmpl   *template.Template
+	tw     *tabwriter.Writer
+	buf    bytes.Buffer
+	header bool
+}
+
+func newTemplateWriter(w io.Writer, tmpl *template.Template) *templateWriter {
+	return &templateWriter{tmpl: tmpl, tw: tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)}
+}
+
+func (t *templateWriter) line(name string, data any) error {
+	t.buf.Reset()
+	if err := t.tmpl.ExecuteTemplate(&t.buf, name, data); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(t.tw, strings.TrimRight(t.buf.String(), "\n"))
+	return err
+}
+
+// Write renders r, preceded on the first call by the "header" template
+// if there is one.
+func (t *templateWriter) Write(r checker.Result) error {
+	if err := t.writeHeader(); err != nil {
+		return err
+	}
+	return t.line(t.tmpl.Name(), r)
+}
+
+func (t *templateWriter) writeHeader() error {
+	if t.header {
+		return nil
+	}
+	t.header = true
+	if t.tmpl.Lookup("header") == nil {
+		return nil
+	}
+	return t.line("header", nil)
+}
+
+// Close writes the header if no result was written, and anything held
+// back for alignment.
+func (t *templateWriter) Close() error {
+	if err := t.writeHeader(); err != nil {
+		return err
+	}
+	return t.tw.Flush()
+}
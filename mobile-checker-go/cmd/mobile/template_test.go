@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func templateResults() []checker.Result {
+	return []checker.Result{
+		{Postcode: "SW1A1AA", Valid: true, Mobile: &ofcom.MobileSummary{
+			CoverageScore: 92,
+			Grade:         "A",
+			Operators: []ofcom.OperatorCoverage{
+				{Name: "EE", Voice: "100%", FourG: "100%", FiveG: "80%", HasVoice: true, HasFourG: true, HasFiveG: true},
+				{Name: "O2", Brands: []string{"giffgaff"}, Voice: "95%", FourG: "90%", FiveG: "0%", HasVoice: true, HasFourG: true},
+			},
+		}},
+		{Postcode: "ZZ99ZZ", Error: "postcode not found"},
+	}
+}
+
+func TestRenderTemplate_Builtins(t *testing.T) {
+	for name, want := range map[string]string{
+		"short": "SW1A1AA: 92/100 (A), EE 4G 100% 5G 80%, O2 (giffgaff) 4G 90% 5G 0%\n" +
+			"ZZ99ZZ: error: postcode not found\n",
+		"table": "POSTCODE  OPERATOR       VOICE  4G    5G   SCORE\n" +
+			"SW1A1AA   EE             100%   100%  80%  92 (A)\n" +
+			"SW1A1AA   O2 (giffgaff)  95%    90%   0%   92 (A)\n" +
+			"ZZ99ZZ    postcode not found\n",
+		"markdown": "| Postcode | Operator | Voice | 4G | 5G | Score |\n" +
+			"|---|---|---|---|---|---|\n" +
+			"| SW1A1AA | EE | ✓ 100% | ✓ 100% | ✓ 80% | 92 (A) |\n" +
+			"| SW1A1AA | O2 (giffgaff) | ✓ 95% | ✓ 90% | ✗ 0% | 92 (A) |\n" +
+			"| ZZ99ZZ | postcode not found | | | | |\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := parseTemplate(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := renderTemplate(&buf, tmpl, templateResults()); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != want {
+				t.Errorf("expected\n%s\ngot\n%s", want, buf.String())
+			}
+		})
+	}
+}
+
+func TestParseTemplate_TextAndFiles(t *testing.T) {
+	tmpl, err := parseTemplate(`{{.Postcode | lower}} {{.Valid}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := renderTemplate(&buf, tmpl, templateResults()); err != nil {
+		t.Fatal(err)
+	}
+	if want := "sw1a1aa true\nzz99zz false\n"; buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+
+	path := filepath.Join(t.TempDir(), "slack.tmpl")
+	if err := os.WriteFile(path, []byte("{{define \"header\"}}*Coverage*{{end}}:signal_strength: {{.Postcode}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if tmpl, err = parseTemplate(path); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := renderTemplate(&buf, tmpl, templateResults()[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if want := "*Coverage*\n:signal_strength: SW1A1AA\n"; buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+
+	if _, err := parseTemplate("fancy"); err == nil || !strings.Contains(err.Error(), "markdown, short, table") {
+		t.Errorf("expected the built-ins to be listed, got %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"log/slog"
+
+	"github.com/yourusername/mobile-checker/internal/transport"
+)
+
+// The --ca-cert and --insecure-skip-verify flags configure every outbound
+// HTTPS request, for networks behind a TLS-inspecting proxy. The proxy
+// itself comes from HTTPS_PROXY, HTTP_PROXY and NO_PROXY.
+var transportOpts transport.Options
+
+// installTransport applies the transport flags to every HTTP client.
+func installTransport() error {
+	if err := transport.Install(transportOpts); err != nil {
+		return err
+	}
+	if transportOpts.InsecureSkipVerify {
+		slog.Warn("TLS certificate verification is disabled by --insecure-skip-verify")
+	}
+	return nil
+}
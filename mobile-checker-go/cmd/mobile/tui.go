@@ -0,0 +1,18 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/tui"
+)
+
+func newTUICmd(dataDir *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "tui",
+		Short: "Explore coverage interactively in the terminal",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return tui.Run(checker.New(*dataDir, withPostcodes(), withEncryption()))
+		},
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func newUpdateCmd(dataDir *string) *cobra.Command {
+	var opts ofcom.SetupOptions
+	var check, watch, jsonOutput bool
+	var interval time.Duration
+	var columnMap string
+
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Install a newer Ofcom edition or revision if one is published",
+		Args:  cobra.NoArgs,
+		Example: "  mobile-checker update --check\n  mobile-checker update\n" +
+			"  mobile-checker update --watch --interval 24h",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if columnMap != "" {
+				var err error
+				if opts.ColumnMap, err = ofcom.LoadColumnMap(columnMap); err != nil {
+					return err
+				}
+			}
+			m := newManager(*dataDir)
+			switch {
+			case watch:
+				ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer stop()
+				notef("Checking for dataset updates every %s (Ctrl+C to stop)...\n", interval)
+				if err := m.WatchUpdates(ctx, interval, opts); err != context.Canceled {
+					return err
+				}
+				return nil
+			case check:
+				st, err := m.CheckForUpdate(opts.IndexURL)
+				if err != nil {
+					return err
+				}
+				if jsonOutput {
+					enc := json.NewEncoder(os.Stdout)
+					enc.SetIndent("", "  ")
+					return enc.Encode(st)
+				}
+				printUpdate(st, false)
+				return nil
+			default:
+				st, err := m.Update(opts)
+				if err != nil {
+					return err
+				}
+				printUpdate(st, true)
+				return nil
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&check, "check", false, "Only report whether an update is available")
+	cmd.Flags().BoolVar(&watch, "watch", false, "Keep running and install updates as they are published")
+	cmd.Flags().DurationVar(&interval, "interval", 24*time.Hour, "How often to check in --watch mode")
+	cmd.Flags().DurationVar(&opts.DownloadTimeout, "download-timeout", ofcom.DefaultDownloadTimeout, "Give up on a dataset download after this long")
+	cmd.Flags().StringVar(&opts.IndexURL, "index-url", "", "Page searched for datasets (default: Ofcom Connected Nations)")
+	cmd.Flags().StringVar(&opts.ManifestURL, "manifest-url", "", "URL of a signed JSON {year: sha256} checksum manifest; the signature is fetched from URL.sig")
+	cmd.Flags().StringVar(&opts.ManifestKey, "manifest-key", "", "Base64 Ed25519 public key the --manifest-url manifest is signed with")
+	cmd.Flags().BoolVar(&opts.RequireChecksum, "require-checksum", false, "Refuse to download a dataset without a known checksum")
+	cmd.Flags().StringVar(&columnMap, "column-map", "", "YAML file mapping the new edition's headers to canonical columns, if its layout is unrecognised")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output --check result as JSON")
+	return cmd
+}
+
+func printUpdate(st *ofcom.UpdateStatus, applied bool) {
+	installed := st.InstalledYear
+	if installed == "" {
+		installed = "none"
+	} else if st.InstalledRevision > 0 {
+		installed += fmt.Sprintf(" r%02d", st.InstalledRevision)
+	}
+	latest := fmt.Sprintf("%s r%02d", st.Latest.Year, st.Latest.Revision)
+
+	fmt.Printf("  Installed: %s\n", installed)
+	fmt.Printf("  Latest:    %s\n", latest)
+	switch {
+	case !st.Available:
+		fmt.Printf("\n%s Dataset is up to date.\n", glyphs.Yes)
+	case applied:
+		fmt.Printf("\n%s Updated to %s.\n", glyphs.Yes, latest)
+	default:
+		fmt.Printf("\n  Update available %s run: mobile-checker update\n", glyphs.Dash)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func newVerifyCmd(dataDir *string) *cobra.Command {
+	var opts ofcom.VerifyOptions
+	var fixturePath string
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check that the database is complete and uncorrupted",
+		Long: "Run SQLite's integrity check, compare the row count with the range expected\n" +
+			"of the edition, check that the edition's key coverage columns exist and hold\n" +
+			"values, compare known postcodes with fixtures and check a random sample of\n" +
+			"rows. A half-built database otherwise answers \"no coverage\" without error.\n" +
+			"Exits with status 4 if any check fails.",
+		Args: cobra.NoArgs,
+		Example: "  mobile-checker verify\n  mobile-checker verify --fixtures known-good.json --json\n" +
+			"  mobile-checker verify --min-rows 100000 --sample 10000",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if fixturePath != "" {
+				b, err := os.ReadFile(fixturePath)
+				if err != nil {
+					return err
+				}
+				opts.Fixtures = []ofcom.Fixture{}
+				if err := json.Unmarshal(b, &opts.Fixtures); err != nil {
+					return fmt.Errorf("invalid fixtures %s: %w", fixturePath, err)
+				}
+			}
+			rep, err := newManager(*dataDir).Verify(opts)
+			if err != nil {
+				return err
+			}
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(rep); err != nil {
+					return err
+				}
+			} else {
+				printVerifyReport(rep)
+			}
+			if !rep.OK {
+				cmd.SilenceErrors, cmd.SilenceUsage = true, true
+				return exitStatus(exitDatasetMissing)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&fixturePath, "fixtures", "", `JSON file of known-good postcodes, e.g. [{"postcode": "LS11AA", "expect": {"ee_4g": 1}}]`)
+	cmd.Flags().IntVar(&opts.MinRows, "min-rows", 0, "Fewest rows expected (default: the edition's expected range)")
+	cmd.Flags().IntVar(&opts.MaxRows, "max-rows", 0, "Most rows expected (default: the edition's expected range)")
+	cmd.Flags().IntVar(&opts.Sample, "sample", 1000, "Random rows to check for well-formed postcodes and values")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the report as JSON")
+	return cmd
+}
+
+func printVerifyReport(r *ofcom.VerifyReport) {
+	sep := rule(52)
+	fmt.Printf("\n%s\n  Database: %s\n  Year:     %s\n%s\n", sep, r.Path, orDash(r.Year), sep)
+	for _, c := range r.Checks {
+		mark := glyphs.Yes
+		if !c.OK {
+			mark = glyphs.No
+		}
+		fmt.Printf("  %s %-10s %s\n", mark, c.Name, c.Detail)
+	}
+	if r.OK {
+		fmt.Printf("\n%s Database verified.\n", glyphs.Yes)
+	} else {
+		fmt.Printf("\n%s Database failed verification: rebuild it with 'mobile-checker setup --force'.\n", glyphs.No)
+	}
+}
@@ -1,26 +1,287 @@
 package main
 
 import (
+	"context"
 	"flag"
-	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/yourusername/mobile-checker/api"
+	"github.com/yourusername/mobile-checker/internal/bundle"
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/config"
+	"github.com/yourusername/mobile-checker/internal/logging"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/pccache"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+	"github.com/yourusername/mobile-checker/internal/transport"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "install-service" {
+		if err := installService(os.Args[2:]); err != nil {
+			if err == flag.ErrHelp {
+				return
+			}
+			log.Fatal(err)
+		}
+		return
+	}
+
 	addr := flag.String("addr", ":5001", "HTTP server address")
+	grpcAddr := flag.String("grpc-addr", "", "gRPC server address, e.g. :5002 (disabled when empty)")
 	dataDir := flag.String("data-dir", defaultDataDir(), "Ofcom database directory")
+	logLevel := flag.String("log-level", "info", "Log level: debug, info, warn or error")
+	logFormat := flag.String("log-format", "text", "Log format: text or json")
+	autoUpdate := flag.Duration("auto-update", 0, "Check for and install new Ofcom datasets at this interval, e.g. 24h (disabled when 0)")
+	corsOrigins := flag.String("cors-origins", "", "Comma-separated origins allowed to call the API from a browser, or * for any (CORS disabled when empty)")
+	corsMethods := flag.String("cors-methods", "GET,POST,OPTIONS", "Comma-separated methods allowed in CORS requests")
+	corsHeaders := flag.String("cors-headers", "Content-Type", "Comma-separated request headers allowed in CORS requests")
+	maxBody := flag.Int64("max-body", 0, "Maximum bulk request body size in bytes (unlimited when 0)")
+	scoreWeights := flag.String("score-weights", "", "Coverage score weights, e.g. voice=0.3,4g=0.5,5g=0.2 (the default)")
+	loadIndex := flag.Bool("load-index", false, "Hold the dataset in memory for faster bulk checks (a few hundred MB for the full UK)")
+	threshold := flag.Float64("threshold", ofcom.CoverageThreshold, "Coverage fraction that counts as available, above 0 and at most 1")
+	suggestTTL := flag.Duration("suggest-ttl", 10*time.Minute, "How long autocomplete answers are cached (disabled when 0)")
+	workers := flag.Int("workers", checker.DefaultWorkers, "Concurrent checks per bulk request")
+	checkTimeout := flag.Duration("check-timeout", 0, "Give up on a single check in a bulk request after this long, e.g. 10s (no limit when 0)")
+	offline := flag.Bool("offline", false, "Never call postcodes.io; use geographic data stored by setup --onspd or --geocode")
+	postcodesURL := flag.String("postcodes-url", "", "postcodes.io-compatible service for postcode lookups, e.g. a self-hosted instance (default: the public API)")
+	var postcodesHeaders [][2]string
+	flag.Func("postcodes-header", "Header sent with every postcodes.io request, as \"Name: value\"; repeatable", func(s string) error {
+		k, v, err := postcode.ParseHeader(s)
+		postcodesHeaders = append(postcodesHeaders, [2]string{k, v})
+		return err
+	})
+	postcodesTimeout := flag.Duration("postcodes-timeout", 10*time.Second, "Give up on a postcodes.io request attempt after this long")
+	postcodesRate := flag.Float64("postcodes-rate", postcode.DefaultRateLimit.PerSecond, "Most postcodes.io requests per second, bursting to twice that (no limit when 0)")
+	postcodesCacheTTL := flag.Duration("postcodes-cache-ttl", 0, "Keep postcodes.io lookups in postcodes-cache.db in the data directory for this long, e.g. 720h, sharing them with the CLI (no cache when 0)")
+	readyUpstream := flag.Bool("ready-upstream", false, "Report not ready on /readyz while postcodes.io is unreachable")
+	cacheMaxAge := flag.Duration("cache-max-age", api.DefaultCacheMaxAge, "How long clients may cache a coverage check before revalidating (always revalidate when 0)")
+	remoteDB := flag.String("remote-db", "", "Read the Ofcom database from this URL with HTTP range requests, e.g. a mobile.db on S3, instead of from --data-dir")
+	encryptKeyFile := flag.String("encrypt-key-file", "", "File holding the passphrase the database and check history are encrypted at rest with, e.g. a container secret (default $"+config.EnvName("encrypt-key")+")")
+	fallbackURL := flag.String("fallback-url", "", "mobile-checker server to forward checks to while the local dataset is missing, e.g. https://coverage.example.com")
+	compress := flag.Bool("compress", true, "Compress responses with gzip or brotli for clients that accept it")
+	recordHistory := flag.Bool("history", false, "Record every check in history.db for 'mobile-checker history'")
+	historyRetention := flag.Duration("history-retention", 0, "Delete recorded checks older than this, e.g. 2160h for 90 days (kept forever when 0)")
+	jobRetention := flag.Duration("job-retention", api.DefaultJobRetention, "How long the results of a finished /api/jobs bulk job are kept")
+	adminToken := flag.String("admin-token", "", "Bearer token required by the /admin endpoints: reload, setup and datasets (disabled when empty)")
+	tlsCert := flag.String("tls-cert", "", "PEM certificate chain to serve HTTPS with (needs --tls-key; reloaded when it changes)")
+	tlsKey := flag.String("tls-key", "", "PEM private key for --tls-cert")
+	autocertDomains := flag.String("autocert-domain", "", "Comma-separated host names to serve HTTPS for with Let's Encrypt certificates (listen on :443)")
+	autocertCache := flag.String("autocert-cache", "", "Directory for Let's Encrypt certificates (default <data-dir>/autocert)")
+	autocertEmail := flag.String("autocert-email", "", "Contact address given to Let's Encrypt for expiry notices")
+	autocertHTTP := flag.String("autocert-http-addr", "", "Also answer ACME challenges and redirect to HTTPS on this address, e.g. :80")
+	caCert := flag.String("ca-cert", "", "PEM file of certificate authorities to trust for outbound HTTPS as well as the system's, e.g. a corporate proxy's")
+	insecureSkipVerify := flag.Bool("insecure-skip-verify", false, "Do not verify outbound HTTPS certificates (for diagnosing only)")
+	downloadTimeout := flag.Duration("download-timeout", ofcom.DefaultDownloadTimeout, "Give up on a dataset download by --auto-update or /admin/setup after this long")
+	recommendCmd := flag.String("recommend-cmd", "", "Program to ask for tariff recommendations, given each check's coverage as JSON on stdin, e.g. /opt/tariffs/recommend --region uk")
+	registry := flag.String("registry", "", "YAML file mapping namespace names to data directories, database files, fixture files or URLs, each served under /api/{name}/")
+	fixturePath := flag.String("fixture", "", "Serve canned results from this JSON file instead of postcodes.io and the Ofcom database, for integration tests")
+	configPath := flag.String("config", "", "YAML config file (default $"+config.FileEnv+")")
 	flag.Parse()
 
-	fmt.Println("Note: Run 'mobile-checker setup' first if you haven't already.")
-	srv := api.NewServer(*dataDir)
-	log.Fatal(srv.ListenAndServe(*addr))
+	cfg, err := config.Load(config.Path(*configPath))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := config.ApplyFlags(flag.CommandLine, cfg); err != nil {
+		log.Fatal(err)
+	}
+	if err := ofcom.CheckThreshold(*threshold); err != nil {
+		log.Fatal(err)
+	}
+
+	logger, err := logging.New(os.Stderr, *logLevel, *logFormat)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Outbound requests go through HTTPS_PROXY or HTTP_PROXY when set.
+	if err := transport.Install(transport.Options{CACert: *caCert, InsecureSkipVerify: *insecureSkipVerify}); err != nil {
+		log.Fatal(err)
+	}
+	if *insecureSkipVerify {
+		logger.Warn("TLS certificate verification is disabled by --insecure-skip-verify")
+	}
+
+	var weights ofcom.ScoreWeights
+	if *scoreWeights != "" {
+		if weights, err = ofcom.ParseScoreWeights(*scoreWeights); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	encryptKey := os.Getenv(config.EnvName("encrypt-key"))
+	if *encryptKeyFile != "" {
+		b, err := os.ReadFile(*encryptKeyFile)
+		if err != nil {
+			log.Fatalf("reading encryption key: %v", err)
+		}
+		if encryptKey = strings.TrimSpace(string(b)); encryptKey == "" {
+			log.Fatalf("%s is empty", *encryptKeyFile)
+		}
+	}
+
+	var fixtures checker.Fixtures
+	if *fixturePath != "" {
+		if fixtures, err = checker.LoadFixtures(*fixturePath); err != nil {
+			log.Fatal(err)
+		}
+		logger.Info("serving canned results", "fixture", *fixturePath, "postcodes", len(fixtures))
+	} else {
+		if err := bundle.Install(ofcom.NewManager(*dataDir, ofcom.WithLogger(logger), ofcom.WithEncryptionKey(encryptKey))); err != nil {
+			logger.Error("failed to install embedded dataset", "err", err)
+			os.Exit(1)
+		}
+		logger.Info("run 'mobile-checker setup' first if you haven't already", "data_dir", *dataDir)
+	}
+	opts := []api.Option{
+		api.WithLogger(logger),
+		api.WithCORS(api.CORSConfig{
+			AllowedOrigins: splitList(*corsOrigins),
+			AllowedMethods: splitList(*corsMethods),
+			AllowedHeaders: splitList(*corsHeaders),
+		}),
+		api.WithMaxBodyBytes(*maxBody),
+		api.WithScoreWeights(weights),
+		api.WithThreshold(*threshold),
+		api.WithSuggestTTL(*suggestTTL),
+		api.WithBulkOptions(checker.BulkOptions{Workers: *workers, Timeout: *checkTimeout}),
+		api.WithCacheMaxAge(*cacheMaxAge),
+		api.WithJobRetention(*jobRetention),
+		api.WithDownloadTimeout(*downloadTimeout),
+		api.WithPostcodesRateLimit(postcode.RateLimit{PerSecond: *postcodesRate, Burst: max(1, int(2**postcodesRate))}),
+		api.WithPostcodeOptions(postcode.WithTimeout(*postcodesTimeout)),
+	}
+	if *postcodesURL != "" {
+		opts = append(opts, api.WithPostcodesURL(*postcodesURL))
+	}
+	for _, h := range postcodesHeaders {
+		opts = append(opts, api.WithPostcodeOptions(postcode.WithHeader(h[0], h[1])))
+	}
+	if *postcodesCacheTTL > 0 {
+		opts = append(opts, api.WithPostcodeOptions(postcode.WithCache(pccache.New(*dataDir, *postcodesCacheTTL, pccache.WithLogger(logger)))))
+	}
+	if *offline {
+		opts = append(opts, api.WithOffline())
+	}
+	if *readyUpstream {
+		opts = append(opts, api.WithReadyUpstream())
+	}
+	if *recordHistory {
+		opts = append(opts, api.WithHistory(), api.WithHistoryRetention(*historyRetention))
+	}
+	if !*compress {
+		opts = append(opts, api.WithoutCompression())
+	}
+	if *remoteDB != "" {
+		opts = append(opts, api.WithRemoteDataset(*remoteDB))
+	}
+	if encryptKey != "" {
+		opts = append(opts, api.WithEncryptionKey(encryptKey))
+	}
+	if *fallbackURL != "" {
+		opts = append(opts, api.WithFallbackURL(*fallbackURL))
+	}
+	if fixtures != nil {
+		opts = append(opts, api.WithFixtures(fixtures))
+	}
+	if *registry != "" {
+		namespaces, err := api.LoadRegistry(*registry)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts = append(opts, api.WithNamespaces(namespaces))
+	}
+	if *recommendCmd != "" {
+		rec, err := checker.NewCommandRecommender(*recommendCmd)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts = append(opts, api.WithRecommender(rec))
+	}
+	if *adminToken != "" {
+		opts = append(opts, api.WithAdminToken(*adminToken))
+	}
+	tlsCfg := api.TLSConfig{
+		CertFile:         *tlsCert,
+		KeyFile:          *tlsKey,
+		AutocertDomains:  splitList(*autocertDomains),
+		AutocertCacheDir: *autocertCache,
+		AutocertEmail:    *autocertEmail,
+		HTTPAddr:         *autocertHTTP,
+	}
+	if len(tlsCfg.AutocertDomains) > 0 && tlsCfg.AutocertCacheDir == "" {
+		tlsCfg.AutocertCacheDir = filepath.Join(*dataDir, "autocert")
+	}
+	if err := tlsCfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if tlsCfg.Enabled() {
+		opts = append(opts, api.WithTLS(tlsCfg))
+	}
+	srv := api.NewServer(*dataDir, opts...)
+	if *loadIndex {
+		if err := srv.LoadIndex(); err != nil {
+			logger.Error("failed to load dataset index", "err", err)
+			os.Exit(1)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *autoUpdate > 0 {
+		go srv.WatchUpdates(ctx, *autoUpdate, ofcom.SetupOptions{})
+	}
+
+	stopped := runServiceHandler(logger, stop)
+	errs := make(chan error, 2)
+	go func() { errs <- srv.ListenAndServe(*addr) }()
+	if *grpcAddr != "" {
+		go func() { errs <- srv.ServeGRPC(*grpcAddr) }()
+	}
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+		logger.Info("shutting down")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("requests still running at shutdown", "err", err)
+	}
+	if err := srv.Close(); err != nil {
+		logger.Warn("closing databases failed", "err", err)
+	}
+	stopped()
+	if err != nil {
+		logger.Error("server stopped", "err", err)
+		os.Exit(1)
+	}
 }
 
+// shutdownTimeout is how long requests in progress get to finish after a
+// stop signal.
+const shutdownTimeout = 30 * time.Second
+
 func defaultDataDir() string {
 	home, _ := os.UserHomeDir()
 	return filepath.Join(home, ".mobile-checker", "data")
 }
+
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
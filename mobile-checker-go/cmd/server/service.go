@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+)
+
+// serviceUsage is printed for install-service -h.
+const serviceUsage = `Usage: mobile-checker-server install-service [flags] [-- server flags]
+
+Registers the API server as a system service: a systemd unit on Linux, a
+launchd daemon on macOS or a Windows service. Flags after -- are passed to
+the server, e.g. -- --offline --history. Run as root or Administrator.
+
+Flags:
+`
+
+// serviceConfig describes the service to install.
+type serviceConfig struct {
+	Name       string
+	Executable string
+	Args       []string // server flags
+	User       string
+	DataDir    string
+	LogDir     string // macOS only
+}
+
+// installService implements the install-service subcommand.
+func installService(args []string) error {
+	fs := flag.NewFlagSet("install-service", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), serviceUsage)
+		fs.PrintDefaults()
+	}
+	name := fs.String("name", "mobile-checker", "Service name")
+	addr := fs.String("addr", ":5001", "HTTP server address")
+	dataDir := fs.String("data-dir", "", "Ofcom database directory (default: a system directory for the platform)")
+	configPath := fs.String("config", "", "YAML config file passed to the server")
+	user := fs.String("user", "", "Account to run the server as (systemd and launchd; default root)")
+	goos := fs.String("os", runtime.GOOS, "Platform to generate the service for with --print: linux, darwin or windows")
+	print := fs.Bool("print", false, "Print the service definition instead of installing it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *goos != runtime.GOOS && !*print {
+		return fmt.Errorf("--os %s needs --print: services can only be installed on this platform", *goos)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return err
+	}
+	cfg := serviceConfig{Name: *name, Executable: exe, User: *user, DataDir: *dataDir, LogDir: "/usr/local/var/log"}
+	if cfg.DataDir == "" {
+		cfg.DataDir = systemDataDir(*goos)
+	}
+	cfg.Args = []string{"--addr", *addr, "--data-dir", cfg.DataDir}
+	if *configPath != "" {
+		abs, err := filepath.Abs(*configPath)
+		if err != nil {
+			return err
+		}
+		cfg.Args = append(cfg.Args, "--config", abs)
+	}
+	cfg.Args = append(cfg.Args, fs.Args()...)
+
+	switch *goos {
+	case "linux":
+		unit, err := render(systemdUnit, cfg)
+		if err != nil || *print {
+			fmt.Print(unit)
+			return err
+		}
+		path := filepath.Join("/etc/systemd/system", cfg.Name+".service")
+		return install(cfg, path, unit,
+			[]string{"systemctl", "daemon-reload"},
+			[]string{"systemctl", "enable", "--now", cfg.Name})
+	case "darwin":
+		plist, err := render(launchdPlist, cfg)
+		if err != nil || *print {
+			fmt.Print(plist)
+			return err
+		}
+		if err := os.MkdirAll(cfg.LogDir, 0755); err != nil {
+			return err
+		}
+		path := filepath.Join("/Library/LaunchDaemons", cfg.Name+".plist")
+		return install(cfg, path, plist, []string{"launchctl", "load", "-w", path})
+	case "windows":
+		create := windowsCreateCommand(cfg)
+		if *print {
+			fmt.Println(strings.Join(quoteAll(create), " "))
+			return nil
+		}
+		return install(cfg, "", "", create,
+			[]string{"sc.exe", "description", cfg.Name, "UK mobile coverage API (Ofcom Connected Nations)"},
+			[]string{"sc.exe", "start", cfg.Name})
+	}
+	return fmt.Errorf("unsupported platform %q: use linux, darwin or windows", *goos)
+}
+
+// install creates the data directory, writes the service definition to
+// path (if any) and runs the commands that register and start the service.
+func install(cfg serviceConfig, path, definition string, commands ...[]string) error {
+	if err := os.MkdirAll(cfg.DataDir, 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	if path != "" {
+		if err := os.WriteFile(path, []byte(definition), 0644); err != nil {
+			return fmt.Errorf("failed to write service definition (run as root?): %w", err)
+		}
+		fmt.Printf("✓ Wrote %s\n", path)
+	}
+	for _, args := range commands {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s failed: %w", strings.Join(args, " "), err)
+		}
+	}
+	fmt.Printf("✓ Service %s installed and started; data directory %s\n", cfg.Name, cfg.DataDir)
+	fmt.Println("  Build the dataset there with: mobile-checker --data-dir " + cfg.DataDir + " setup")
+	return nil
+}
+
+// systemDataDir is the default data directory for a system service.
+func systemDataDir(goos string) string {
+	switch goos {
+	case "windows":
+		root := os.Getenv("ProgramData")
+		if root == "" {
+			root = `C:\ProgramData`
+		}
+		return root + `\mobile-checker`
+	case "darwin":
+		return "/usr/local/var/mobile-checker"
+	default:
+		return "/var/lib/mobile-checker"
+	}
+}
+
+func render(tmpl *template.Template, cfg serviceConfig) (string, error) {
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, cfg)
+	return buf.String(), err
+}
+
+// windowsCreateCommand registers the service with the service control
+// manager; sc.exe wants each option and its value as separate arguments.
+func windowsCreateCommand(cfg serviceConfig) []string {
+	bin := strings.Join(quoteAll(append([]string{cfg.Executable}, cfg.Args...)), " ")
+	return []string{"sc.exe", "create", cfg.Name, "binPath=", bin, "start=", "auto", "DisplayName=", "UK Mobile Coverage API"}
+}
+
+// quoteAll double-quotes arguments containing spaces, escaping quotes
+// inside them, for Windows command lines and for printing.
+func quoteAll(args []string) []string {
+	out := make([]string, len(args))
+	for i, a := range args {
+		if strings.ContainsAny(a, " \t") {
+			a = `"` + strings.ReplaceAll(a, `"`, `\"`) + `"`
+		}
+		out[i] = a
+	}
+	return out
+}
+
+var funcs = template.FuncMap{
+	"list": func(s ...string) []string { return s },
+	// systemdArgs quotes arguments for ExecStart.
+	"systemdArgs": func(args []string) string {
+		out := make([]string, len(args))
+		for i, a := range args {
+			if strings.ContainsAny(a, " \t\"\\") {
+				a = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(a) + `"`
+			}
+			out[i] = a
+		}
+		return strings.Join(out, " ")
+	},
+	"xml": func(s string) string {
+		var buf bytes.Buffer
+		for _, r := range s {
+			switch r {
+			case '&':
+				buf.WriteString("&amp;")
+			case '<':
+				buf.WriteString("&lt;")
+			case '>':
+				buf.WriteString("&gt;")
+			default:
+				buf.WriteRune(r)
+			}
+		}
+		return buf.String()
+	},
+}
+
+var systemdUnit = template.Must(template.New("systemd").Funcs(funcs).Parse(`[Unit]
+Description=UK Mobile Coverage API
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+ExecStart={{systemdArgs (list .Executable)}} {{systemdArgs .Args}}
+{{- if .User}}
+User={{.User}}
+{{- end}}
+Restart=on-failure
+RestartSec=5
+# The server writes only to its data directory (--auto-update, --history).
+ReadWritePaths={{.DataDir}}
+ProtectSystem=strict
+ProtectHome=true
+NoNewPrivileges=true
+PrivateTmp=true
+
+[Install]
+WantedBy=multi-user.target
+`))
+
+var launchdPlist = template.Must(template.New("launchd").Funcs(funcs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>{{xml .Name}}</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>{{xml .Executable}}</string>
+		{{- range .Args}}
+		<string>{{xml .}}</string>
+		{{- end}}
+	</array>
+	{{- if .User}}
+	<key>UserName</key>
+	<string>{{xml .User}}</string>
+	{{- end}}
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>
+	<key>StandardErrorPath</key>
+	<string>{{xml .LogDir}}/{{xml .Name}}.log</string>
+</dict>
+</plist>
+`))
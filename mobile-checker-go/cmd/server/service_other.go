@@ -0,0 +1,11 @@
+//go:build !windows
+
+package main
+
+import "log/slog"
+
+// runServiceHandler is only needed on Windows; systemd and launchd stop
+// the server with a signal.
+func runServiceHandler(*slog.Logger, func()) (stopped func()) {
+	return func() {}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSystemdUnit(t *testing.T) {
+	cfg := serviceConfig{
+		Name:       "mobile-checker",
+		Executable: "/opt/mobile checker/server",
+		Args:       []string{"--addr", ":5001", "--data-dir", "/var/lib/mobile-checker", "--config", `/etc/a "b"\c.yaml`},
+		User:       "coverage",
+		DataDir:    "/var/lib/mobile-checker",
+	}
+	unit, err := render(systemdUnit, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`ExecStart="/opt/mobile checker/server" --addr :5001 --data-dir /var/lib/mobile-checker --config "/etc/a \"b\"\\c.yaml"` + "\n",
+		"\nUser=coverage\n",
+		"\nReadWritePaths=/var/lib/mobile-checker\n",
+		"\nWantedBy=multi-user.target\n",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("expected %q in unit:\n%s", want, unit)
+		}
+	}
+
+	cfg.User = ""
+	if unit, _ := render(systemdUnit, cfg); strings.Contains(unit, "User=") {
+		t.Errorf("expected no User= without --user:\n%s", unit)
+	}
+}
+
+func TestLaunchdPlist(t *testing.T) {
+	cfg := serviceConfig{
+		Name:       "mobile-checker",
+		Executable: "/usr/local/bin/mobile-checker-server",
+		Args:       []string{"--data-dir", "/data/<R&D>"},
+		User:       "_coverage",
+		LogDir:     "/usr/local/var/log",
+	}
+	plist, err := render(launchdPlist, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<string>mobile-checker</string>",
+		"<string>/usr/local/bin/mobile-checker-server</string>\n\t\t<string>--data-dir</string>\n\t\t<string>/data/&lt;R&amp;D&gt;</string>\n\t</array>",
+		"<key>UserName</key>\n\t<string>_coverage</string>",
+		"<string>/usr/local/var/log/mobile-checker.log</string>",
+	} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("expected %q in plist:\n%s", want, plist)
+		}
+	}
+
+	cfg.User = ""
+	if plist, _ := render(launchdPlist, cfg); strings.Contains(plist, "UserName") {
+		t.Errorf("expected no UserName without --user:\n%s", plist)
+	}
+}
+
+func TestWindowsCreateCommand(t *testing.T) {
+	cfg := serviceConfig{
+		Name:       "mobile-checker",
+		Executable: `C:\Program Files\mobile-checker\server.exe`,
+		Args:       []string{"--data-dir", `C:\ProgramData\mobile-checker`, "--config", `C:\My "Config".yaml`},
+	}
+	got := windowsCreateCommand(cfg)
+	want := []string{"sc.exe", "create", "mobile-checker",
+		"binPath=", `"C:\Program Files\mobile-checker\server.exe" --data-dir C:\ProgramData\mobile-checker --config "C:\My \"Config\".yaml"`,
+		"start=", "auto", "DisplayName=", "UK Mobile Coverage API"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("expected\n%q\ngot\n%q", want, got)
+	}
+}
+
+func TestInstallService_OtherPlatformNeedsPrint(t *testing.T) {
+	goos := "windows"
+	if runtime.GOOS == "windows" {
+		goos = "linux"
+	}
+	err := installService([]string{"--os", goos})
+	if err == nil || !strings.Contains(err.Error(), "needs --print") {
+		t.Errorf("expected --os without --print to be refused, got %v", err)
+	}
+}
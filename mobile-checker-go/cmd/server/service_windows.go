@@ -0,0 +1,59 @@
+//go:build windows
+
+package main
+
+import (
+	"log/slog"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+// runServiceHandler answers the Windows service control manager when the
+// server was started as a service: Stop and Shutdown call stop, which
+// begins the server's graceful shutdown, instead of timing out. main calls
+// the returned stopped once the server has shut down, so the service is
+// reported stopped only after its databases are closed. Otherwise it does
+// nothing.
+func runServiceHandler(logger *slog.Logger, stop func()) (stopped func()) {
+	if ok, err := svc.IsWindowsService(); err != nil || !ok {
+		return func() {}
+	}
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		if err := svc.Run("", serviceHandler{stop: stop, done: done}); err != nil {
+			logger.Error("service control manager", "err", err)
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+type serviceHandler struct {
+	stop func()
+	done <-chan struct{} // closed when the server has shut down
+}
+
+func (h serviceHandler) Execute(_ []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	for {
+		select {
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				status <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				h.stop()
+				<-h.done
+				return false, 0
+			}
+		case <-h.done:
+			// The server stopped by itself, e.g. its address was in use.
+			return false, 0
+		}
+	}
+}
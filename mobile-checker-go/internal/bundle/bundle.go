@@ -0,0 +1,20 @@
+// Package bundle holds a prebuilt Ofcom database embedded at compile time.
+//
+// Build with the "bundle" tag after placing a database written by
+// 'mobile-checker setup --bundle' at internal/bundle/mobile.db:
+//
+//	mobile-checker setup --bundle internal/bundle/mobile.db
+//	go build -tags bundle ./cmd/mobile
+//
+// The resulting binary installs the database into its data directory on
+// first run, so it works without network access to ofcom.org.uk.
+package bundle
+
+import "github.com/yourusername/mobile-checker/internal/ofcom"
+
+// Install writes the embedded database into m's data directory if needed;
+// see ofcom.Manager.InstallBundle. It is a no-op in builds without a bundle.
+func Install(m *ofcom.Manager) error {
+	_, err := m.InstallBundle(DB)
+	return err
+}
@@ -0,0 +1,10 @@
+//go:build bundle
+
+package bundle
+
+import _ "embed"
+
+// DB is the embedded SQLite database.
+//
+//go:embed mobile.db
+var DB []byte
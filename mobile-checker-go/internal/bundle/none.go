@@ -0,0 +1,6 @@
+//go:build !bundle
+
+package bundle
+
+// DB is empty in builds without the "bundle" tag.
+var DB []byte
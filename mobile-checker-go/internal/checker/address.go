@@ -0,0 +1,80 @@
+package checker
+
+import (
+	"fmt"
+
+	"github.com/yourusername/mobile-checker/internal/geocoder"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+// CheckAddress resolves a free-text address to a postcode and checks it.
+// A postcode written in the address is used as it is; otherwise the
+// address is geocoded (see WithGeocoder) and, if the geocoder gives no
+// postcode, the one nearest its position is looked up on postcodes.io.
+// The Result's Address records how the postcode was found.
+func (c *Checker) CheckAddress(address string, opts CheckOptions) Result {
+	match, err := c.resolveAddress(address)
+	if err != nil {
+		result := Result{Error: fmt.Sprintf("Address lookup failed: %v", err), Err: err}
+		result.Code = CodeOf(err)
+		return result
+	}
+	c.logger.Debug("address resolved", "address", address, "postcode", match.Postcode, "source", match.Source)
+	result := c.CheckWith(match.Postcode, opts)
+	result.Address = match
+	return result
+}
+
+func (c *Checker) resolveAddress(address string) (*geocoder.Match, error) {
+	if pc := geocoder.PostcodeIn(address); pc != "" {
+		return &geocoder.Match{Query: address, Postcode: pc, Source: "address"}, nil
+	}
+	if c.offline {
+		return nil, fmt.Errorf("address %q has no postcode and geocoding is disabled offline: %w", address, geocoder.ErrUnavailable)
+	}
+	match, err := c.geocoder.Geocode(address)
+	if err != nil {
+		return nil, err
+	}
+	if match.Postcode == "" {
+		near, err := c.postcodeClient.Reverse(match.Latitude, match.Longitude)
+		if err != nil {
+			return nil, fmt.Errorf("no postcode for %q: %w", match.Label, err)
+		}
+		match.Postcode = postcode.Normalise(near.Postcode)
+	}
+	return match, nil
+}
+
+// CheckHere checks the postcode nearest the machine's position as placed
+// by its public IP address. The Result's Address holds the IP address as
+// its query and the located town as its label. Positions from an IP
+// address are only as good as the town, so the check is a rough guide.
+func (c *Checker) CheckHere(l geocoder.IPLocator, opts CheckOptions) Result {
+	match, err := c.locate(l)
+	if err != nil {
+		result := Result{Error: fmt.Sprintf("Location lookup failed: %v", err), Err: err}
+		result.Code = CodeOf(err)
+		return result
+	}
+	c.logger.Debug("IP address located", "ip", match.Query, "postcode", match.Postcode, "source", match.Source)
+	result := c.CheckWith(match.Postcode, opts)
+	result.Address = match
+	return result
+}
+
+func (c *Checker) locate(l geocoder.IPLocator) (*geocoder.Match, error) {
+	if c.offline {
+		return nil, fmt.Errorf("locating by IP address is disabled offline: %w", geocoder.ErrUnavailable)
+	}
+	match, err := l.Locate()
+	if err != nil {
+		return nil, err
+	}
+	near, err := c.postcodeClient.Reverse(match.Latitude, match.Longitude)
+	if err != nil {
+		return nil, fmt.Errorf("no postcode near %s: %w", match.Label, err)
+	}
+	match.Postcode = postcode.Normalise(near.Postcode)
+	return match, nil
+}
@@ -0,0 +1,171 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Timing is how long a check spent in each stage.
+type Timing struct {
+	// Geocode is the postcodes.io lookup, or the read of stored
+	// geographic data offline.
+	Geocode time.Duration
+	// Query is everything after it: the Ofcom database query, estimates,
+	// ranks and any extra sources.
+	Query time.Duration
+}
+
+type timingKey struct{}
+
+// WithTiming returns a context whose checks record their stage timings
+// in t. Fixture and fallback checks leave t unset.
+func WithTiming(ctx context.Context, t *Timing) context.Context {
+	return context.WithValue(ctx, timingKey{}, t)
+}
+
+func timingFrom(ctx context.Context) *Timing {
+	t, _ := ctx.Value(timingKey{}).(*Timing)
+	return t
+}
+
+// BenchOptions configure Bench.
+type BenchOptions struct {
+	// Concurrency is the number of checks in flight; DefaultWorkers when 0.
+	Concurrency int
+	Options     CheckOptions
+}
+
+// Latency summarises a set of durations in milliseconds.
+type Latency struct {
+	P50  float64 `json:"p50_ms"`
+	P90  float64 `json:"p90_ms"`
+	P99  float64 `json:"p99_ms"`
+	Max  float64 `json:"max_ms"`
+	Mean float64 `json:"mean_ms"`
+}
+
+// BenchReport is the output of Bench, stable enough to track across
+// releases.
+type BenchReport struct {
+	Checks          int     `json:"checks"`
+	Concurrency     int     `json:"concurrency"`
+	ElapsedSeconds  float64 `json:"elapsed_seconds"`
+	ChecksPerSecond float64 `json:"checks_per_second"`
+	// Errors counts checks that failed outright; postcodes missing from
+	// the dataset are not errors.
+	Errors int `json:"errors"`
+	// Latency is whole checks; Geocode and Query split them by stage.
+	Latency Latency `json:"latency"`
+	Geocode Latency `json:"geocode"`
+	Query   Latency `json:"query"`
+	// Online is whether checks geocoded with postcodes.io, whose rate
+	// limit then bounds throughput rather than the checker.
+	Online bool `json:"online"`
+	// RateLimit is the postcodes.io requests per second online checks
+	// were held to, set by the caller; 0 means none.
+	RateLimit float64 `json:"postcodes_rate_limit,omitempty"`
+	Dataset   string  `json:"dataset,omitempty"`
+	Driver    string  `json:"driver"`
+	Go        string  `json:"go"`
+	CPUs      int     `json:"cpus"`
+}
+
+// SamplePostcodes returns up to n postcodes of the dataset, chosen at
+// random, to benchmark with.
+func (c *Checker) SamplePostcodes(n int) ([]string, error) {
+	return c.ofcomManager.SamplePostcodes(n)
+}
+
+// Bench checks every postcode, opts.Concurrency at a time, and reports
+// throughput and latency. It stops early, with what it measured, when ctx
+// is done.
+func (c *Checker) Bench(ctx context.Context, postcodes []string, opts BenchOptions) (*BenchReport, error) {
+	if len(postcodes) == 0 {
+		return nil, errors.New("no postcodes to benchmark")
+	}
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	type sample struct {
+		total  time.Duration
+		timing Timing
+		failed bool
+	}
+	samples := make([]sample, len(postcodes))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				s := &samples[i]
+				began := time.Now()
+				r := c.CheckContext(WithTiming(ctx, &s.timing), postcodes[i], opts.Options)
+				s.total = time.Since(began)
+				s.failed = r.Err != nil && !errors.Is(r.Err, ErrNotInDataset)
+			}
+		}()
+	}
+	done := 0
+feed:
+	for i := range postcodes {
+		select {
+		case next <- i:
+			done++
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	rep := &BenchReport{
+		Checks:         done,
+		Concurrency:    workers,
+		ElapsedSeconds: elapsed.Seconds(),
+		Online:         !c.offline,
+		Driver:         c.ofcomManager.Driver.Name,
+		Go:             runtime.Version(),
+		CPUs:           runtime.NumCPU(),
+	}
+	if elapsed > 0 {
+		rep.ChecksPerSecond = float64(done) / elapsed.Seconds()
+	}
+	if rel, err := c.ofcomManager.Release(); err == nil && rel != nil {
+		rep.Dataset = rel.String()
+	}
+	var total, geocode, query []time.Duration
+	for _, s := range samples[:done] {
+		if s.failed {
+			rep.Errors++
+		}
+		total = append(total, s.total)
+		geocode = append(geocode, s.timing.Geocode)
+		query = append(query, s.timing.Query)
+	}
+	rep.Latency, rep.Geocode, rep.Query = latencyOf(total), latencyOf(geocode), latencyOf(query)
+	return rep, nil
+}
+
+// latencyOf summarises d, which it sorts.
+func latencyOf(d []time.Duration) Latency {
+	if len(d) == 0 {
+		return Latency{}
+	}
+	slices.Sort(d)
+	ms := func(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
+	pct := func(p float64) float64 { return ms(d[min(len(d)-1, int(p*float64(len(d))))]) }
+	var sum time.Duration
+	for _, v := range d {
+		sum += v
+	}
+	return Latency{P50: pct(0.50), P90: pct(0.90), P99: pct(0.99), Max: ms(d[len(d)-1]), Mean: ms(sum / time.Duration(len(d)))}
+}
@@ -0,0 +1,64 @@
+package checker_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+func TestBench_SamplesAndSplitsLatency(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":200,"result":{"postcode":"LS1 1AA","country":"England","latitude":53.797,"longitude":-1.548}}`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte("postcode,ee_4g\nLS11AA,1\nLS11AB,0\nLS11AD,1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ofcom.NewManager(dir).Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	pc := postcode.NewClient(postcode.WithBaseURL(srv.URL), postcode.WithRetry(postcode.RetryPolicy{MaxAttempts: 1}))
+	c := checker.New(dir, checker.WithPostcodeClient(pc))
+	defer c.Close()
+
+	sample, err := c.SamplePostcodes(10)
+	if err != nil {
+		t.Fatalf("sampling failed: %v", err)
+	}
+	slices.Sort(sample)
+	if want := []string{"LS11AA", "LS11AB", "LS11AD"}; !slices.Equal(sample, want) {
+		t.Fatalf("expected every postcode sampled, got %v", sample)
+	}
+
+	postcodes := append(sample, sample...)
+	rep, err := c.Bench(context.Background(), postcodes, checker.BenchOptions{Concurrency: 4})
+	if err != nil {
+		t.Fatalf("bench failed: %v", err)
+	}
+	if rep.Checks != len(postcodes) || rep.Errors != 0 || rep.Concurrency != 4 {
+		t.Errorf("expected %d checks without errors at concurrency 4, got %+v", len(postcodes), rep)
+	}
+	if rep.ChecksPerSecond <= 0 || rep.Latency.Max <= 0 || rep.Geocode.Max <= 0 {
+		t.Errorf("expected throughput and latencies to be measured, got %+v", rep)
+	}
+	if rep.Latency.P50 > rep.Latency.P99 || rep.Latency.P99 > rep.Latency.Max {
+		t.Errorf("expected ordered percentiles, got %+v", rep.Latency)
+	}
+	if rep.Dataset == "" || !rep.Online {
+		t.Errorf("expected the dataset and online geocoding to be recorded, got %+v", rep)
+	}
+
+	if _, err := c.Bench(context.Background(), nil, checker.BenchOptions{}); err == nil {
+		t.Error("expected an error benchmarking no postcodes")
+	}
+}
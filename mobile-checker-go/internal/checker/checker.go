@@ -2,89 +2,505 @@
 package checker
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"time"
 
+	"github.com/yourusername/mobile-checker/internal/geocoder"
+	"github.com/yourusername/mobile-checker/internal/history"
 	"github.com/yourusername/mobile-checker/internal/ofcom"
 	"github.com/yourusername/mobile-checker/internal/postcode"
 )
 
+// SchemaVersion is the version of the layout of Result, and of the API and
+// CLI JSON documents carrying results, reported as schema_version. It is
+// bumped whenever a field is removed, renamed or changes meaning, so
+// pipelines can refuse output they do not understand; new fields alone
+// leave it unchanged.
+const SchemaVersion = 1
+
 // Result is the unified output of a mobile coverage check.
 type Result struct {
-	Postcode   string                `json:"postcode"`
-	Valid      bool                  `json:"valid"`
-	Geographic *postcode.Result      `json:"geographic,omitempty"`
-	Mobile     *ofcom.MobileSummary  `json:"mobile,omitempty"`
-	Error      string                `json:"error,omitempty"`
-	Note       string                `json:"note,omitempty"`
+	Postcode   string                `json:"postcode" xml:"postcode"`
+	Valid      bool                  `json:"valid" xml:"valid"`
+	Geographic *postcode.Result      `json:"geographic,omitempty" xml:"geographic,omitempty"`
+	Mobile     *ofcom.MobileSummary  `json:"mobile,omitempty" xml:"mobile,omitempty"`
+	Error      string                `json:"error,omitempty" xml:"error,omitempty"`
+	Note       string                `json:"note,omitempty" xml:"note,omitempty"`
+	// Code classifies Err; see ErrorCode.
+	Code ErrorCode `json:"code,omitempty" xml:"code,omitempty"`
+	// Terminated is set when the postcode has been retired.
+	Terminated *Termination `json:"terminated,omitempty" xml:"terminated,omitempty"`
+	// Sources holds coverage from additional sources added with WithSource.
+	Sources []SourceResult `json:"sources,omitempty" xml:"sources>source,omitempty"`
+	// Address is set by CheckAddress to the address the postcode came from.
+	Address *geocoder.Match `json:"address,omitempty" xml:"address,omitempty"`
+	// Status is the check's outcome, set on results of bulk checks; see
+	// StatusOf.
+	Status ItemStatus `json:"status,omitempty" xml:"status,omitempty"`
+	// Site is the saved site's name when checked by CheckSites.
+	Site string `json:"site,omitempty" xml:"site,omitempty"`
+	// Year is the dataset year checked, when CheckOptions.Year chose one.
+	Year string `json:"year,omitempty" xml:"year,omitempty"`
+	// Dataset is the Ofcom release that answered, including its revision.
+	Dataset *ofcom.Release `json:"dataset,omitempty" xml:"dataset,omitempty"`
+	// Trend is set when CheckOptions.Trend asked for it and at least two
+	// installed years hold the postcode.
+	Trend *ofcom.Trend `json:"trend,omitempty" xml:"trend,omitempty"`
+	// Recommendations holds the tariffs suggested by recommenders added
+	// with WithRecommender.
+	Recommendations []Recommendations `json:"recommendations,omitempty" xml:"recommendations>recommendation,omitempty"`
+	// Fallback is the server that answered while the local dataset is
+	// missing; see WithFallback.
+	Fallback string `json:"fallback,omitempty" xml:"fallback,omitempty"`
+	// Err is the typed cause behind Error or Note, for errors.Is checks.
+	Err error `json:"-" xml:"-"`
 }
 
+// ErrOffline is the lookup error for a postcode with no stored geographic
+// data when the Checker is offline. It wraps postcode.ErrUnavailable, so
+// such checks fall back to Ofcom data alone.
+var ErrOffline = fmt.Errorf("no local geographic data and postcodes.io is disabled: %w", postcode.ErrUnavailable)
+
+// ErrNotInDataset is set on a Result whose valid postcode has no row in the
+// Ofcom dataset.
+var ErrNotInDataset = errors.New("postcode not found in Ofcom mobile dataset")
+
 // Checker performs mobile coverage checks.
 type Checker struct {
 	postcodeClient *postcode.Client
 	ofcomManager   *ofcom.Manager
+	primary        CoverageSource
+	customPrimary  CoverageSource // set by WithPrimarySource
+	sources        []CoverageSource
+	recommenders   []Recommender
+	logger         *slog.Logger
+	offline        bool
+	history        *history.Store
+	geocoder       geocoder.Geocoder
+	fallback       *fallbackClient
+	fixtures       Fixtures
+	// ofcomOpts configure the Ofcom manager, e.g. a remote database.
+	ofcomOpts []ofcom.Option
+}
+
+// Option configures a Checker.
+type Option func(*Checker)
+
+// WithLogger sets the logger used by the Checker and its Ofcom manager.
+func WithLogger(l *slog.Logger) Option {
+	return func(c *Checker) { c.logger = l }
+}
+
+// WithPostcodeClient sets the postcodes.io client, e.g. one built with
+// custom retry, circuit breaker or rate limit options.
+func WithPostcodeClient(pc *postcode.Client) Option {
+	return func(c *Checker) { c.postcodeClient = pc }
+}
+
+// WithOffline stops the Checker calling postcodes.io: geographic data comes
+// only from the database (see ImportONSPD and Geocode), and terminated
+// postcodes are not recognised.
+func WithOffline() Option {
+	return func(c *Checker) { c.offline = true }
+}
+
+// WithHistory records every check made through CheckWith in h.
+func WithHistory(h *history.Store) Option {
+	return func(c *Checker) { c.history = h }
+}
+
+// WithGeocoder sets the geocoder CheckAddress uses; by default it is
+// Nominatim.
+func WithGeocoder(g geocoder.Geocoder) Option {
+	return func(c *Checker) { c.geocoder = g }
+}
+
+// WithRemoteDataset reads the Ofcom database from url with HTTP range
+// requests instead of from the data directory; see ofcom.WithRemote.
+func WithRemoteDataset(url string, opts ofcom.RemoteOptions) Option {
+	return func(c *Checker) { c.ofcomOpts = append(c.ofcomOpts, ofcom.WithRemote(url, opts)) }
+}
+
+// WithEncryptionKey keeps the Ofcom database encrypted at rest with a key
+// derived from passphrase; see ofcom.WithEncryptionKey.
+func WithEncryptionKey(passphrase string) Option {
+	return func(c *Checker) { c.ofcomOpts = append(c.ofcomOpts, ofcom.WithEncryptionKey(passphrase)) }
+}
+
+// WithDatabasePath reads the current dataset from the database file at
+// path instead of mobile.db in the data directory; see ofcom.WithDBPath.
+func WithDatabasePath(path string) Option {
+	return func(c *Checker) { c.ofcomOpts = append(c.ofcomOpts, ofcom.WithDBPath(path)) }
 }
 
 // New creates a new Checker.
-func New(dataDir string) *Checker {
-	return &Checker{
+func New(dataDir string, opts ...Option) *Checker {
+	c := &Checker{
 		postcodeClient: postcode.NewClient(),
-		ofcomManager:   ofcom.NewManager(dataDir),
+		geocoder:       geocoder.NewNominatim(geocoder.DefaultNominatimURL),
+		logger:         slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.ofcomManager = ofcom.NewManager(dataDir, append([]ofcom.Option{ofcom.WithLogger(c.logger)}, c.ofcomOpts...)...)
+	c.primary = OfcomSource(c.ofcomManager)
+	if c.customPrimary != nil {
+		c.primary = c.customPrimary
+	}
+	return c
+}
+
+// UsingLogger returns a copy of c that logs to l, e.g. a logger tagged with
+// a request ID. The copy shares c's database and clients.
+func (c *Checker) UsingLogger(l *slog.Logger) *Checker {
+	cp := *c
+	cp.logger = l
+	return &cp
+}
+
+// ForYear returns a copy of c that checks against an installed Ofcom
+// dataset year rather than the current one; see ofcom.Manager.ForYear.
+// Additional sources are unchanged.
+func (c *Checker) ForYear(year string) (*Checker, error) {
+	m, err := c.ofcomManager.ForYear(year)
+	if err != nil {
+		return nil, err
 	}
+	cp := *c
+	cp.ofcomManager = m
+	cp.primary = OfcomSource(m)
+	return &cp, nil
 }
 
-// Setup downloads and builds the Ofcom mobile database.
-func (c *Checker) Setup(year string, force bool) error {
-	return c.ofcomManager.Setup(year, force)
+// Setup downloads and builds the Ofcom mobile database, then sets up any
+// additional sources.
+func (c *Checker) Setup(year string, opts ofcom.SetupOptions) error {
+	if err := c.primary.Setup(year, opts); err != nil {
+		return err
+	}
+	for _, src := range c.sources {
+		if err := src.Setup(year, opts); err != nil {
+			return fmt.Errorf("%s setup failed: %w", src.Name(), err)
+		}
+	}
+	return nil
+}
+
+// Update installs the newest Ofcom dataset if there is one; see
+// ofcom.Manager.Update.
+func (c *Checker) Update(opts ofcom.SetupOptions) (*ofcom.UpdateStatus, error) {
+	return c.ofcomManager.Update(opts)
+}
+
+// CheckOptions controls how a coverage check is interpreted.
+type CheckOptions struct {
+	Indoor bool // report indoor rather than outdoor coverage
+	// AddIndoor also reports indoor coverage alongside outdoor; see
+	// ofcom.InterpretOptions.
+	AddIndoor bool
+	// Operators restricts results to these operators; see
+	// ofcom.ParseOperators. Empty means all four.
+	Operators []string
+	// Brands labels operators with the MVNO brands asked for; see
+	// ofcom.ParseOperatorList.
+	Brands map[string][]string
+	// Weights sets the coverage score weighting; zero means
+	// ofcom.DefaultScoreWeights.
+	Weights ofcom.ScoreWeights
+	// Threshold is the coverage fraction that counts as available; zero
+	// means ofcom.CoverageThreshold.
+	Threshold float64
+	// NoEstimate leaves postcodes missing from the dataset without
+	// coverage instead of estimating it from nearby postcodes.
+	NoEstimate bool
+	// Year checks an installed dataset year instead of the current one,
+	// e.g. "2022"; see ForYear.
+	Year string
+	// Trend adds Result.Trend, extrapolating coverage from every installed
+	// dataset year; see Checker.Trend.
+	Trend bool
 }
 
 // Check performs a full mobile coverage check for a UK postcode.
 func (c *Checker) Check(pc string) Result {
+	return c.CheckWith(pc, CheckOptions{})
+}
+
+// CheckWith performs a mobile coverage check with the given options.
+func (c *Checker) CheckWith(pc string, opts CheckOptions) Result {
+	return c.CheckContext(context.Background(), pc, opts)
+}
+
+// CheckContext is CheckWith, abandoning requests to postcodes.io and the
+// fallback server when ctx is done. Database queries run to completion.
+func (c *Checker) CheckContext(ctx context.Context, pc string, opts CheckOptions) Result {
+	if c.fixtures != nil {
+		return c.fixtures.check(pc)
+	}
+	if postcode.Valid(pc) && c.useFallback() {
+		result := c.fallback.check(ctx, pc, opts)
+		if c.history != nil {
+			c.record(result)
+		}
+		return result
+	}
+	all := c
+	if opts.Year != "" && postcode.Valid(pc) {
+		yc, err := c.ForYear(opts.Year)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", opts.Year, err)
+			return Result{Postcode: postcode.Normalise(pc), Error: fmt.Sprintf("Dataset unavailable: %v", err), Err: err, Code: CodeOf(err)}
+		}
+		c = yc
+	}
+	result := c.check(ctx, pc, opts)
+	result.Code = CodeOf(result.Err)
+	result.Year = opts.Year
+	if result.Mobile != nil || errors.Is(result.Err, ErrNotInDataset) {
+		result.Dataset, _ = c.ofcomManager.Release()
+	}
+	if opts.Trend && result.Mobile != nil && !result.Mobile.Estimated {
+		t, err := all.Trend(pc, opts)
+		if err != nil && !errors.Is(err, ErrTrendNeedsYears) {
+			c.logger.Warn("coverage trend failed", "postcode", result.Postcode, "err", err)
+		}
+		result.Trend = t
+	}
+	if len(c.recommenders) > 0 && result.Mobile != nil {
+		c.recommend(ctx, &result)
+	}
+	if c.history != nil {
+		c.record(result)
+	}
+	return result
+}
+
+// record adds result to the check history. Failures are logged rather
+// than returned, so history never costs a check.
+func (c *Checker) record(result Result) {
+	e := history.Entry{Postcode: result.Postcode, Summary: result.Mobile, Message: result.Error}
+	if e.Message == "" {
+		e.Message = result.Note
+	}
+	if meta, err := c.ofcomManager.Meta(); err == nil {
+		e.Dataset = meta["dataset_year"]
+	}
+	if err := c.history.Record(e); err != nil {
+		c.logger.Warn("failed to record check history", "postcode", result.Postcode, "err", err)
+	}
+}
+
+func (c *Checker) check(ctx context.Context, pc string, opts CheckOptions) Result {
 	normalised := postcode.Normalise(pc)
 	result := Result{Postcode: normalised}
+	// Rejected here, before any database query or request.
+	if err := postcode.Validate(normalised); err != nil {
+		result.Error = fmt.Sprintf("Invalid postcode: %v", err)
+		result.Err = err
+		return result
+	}
 
-	geo, err := c.postcodeClient.Lookup(pc)
-	if err != nil {
+	timing := timingFrom(ctx)
+	start := time.Now()
+	geo, err := c.lookup(ctx, normalised)
+	if timing != nil {
+		timing.Geocode = time.Since(start)
+		start = time.Now()
+		defer func() { timing.Query = time.Since(start) }()
+	}
+	switch {
+	case errors.Is(err, postcode.ErrUnavailable):
+		// Degrade to an Ofcom-only result: a postcode in the dataset is real
+		// even if postcodes.io cannot describe it right now.
+		return c.checkWithoutGeo(result, err, opts)
+	case errors.Is(err, postcode.ErrNotFound):
+		return c.checkTerminated(ctx, result, err, opts)
+	case err != nil:
 		result.Error = fmt.Sprintf("Postcode lookup failed: %v", err)
+		result.Err = err
 		return result
 	}
 	result.Valid = true
 	result.Geographic = geo
+	result.Sources = c.querySources(normalised, opts)
 
-	row, err := c.ofcomManager.QueryPostcode(normalised)
+	row, err := c.primary.Query(normalised)
 	if err != nil {
 		result.Note = fmt.Sprintf("Mobile data unavailable: %v", err)
+		result.Err = err
 		return result
 	}
 	if row == nil {
+		// Not missing from Ofcom's data, only from this trimmed copy of it;
+		// neighbouring postcodes are left out too, so nothing is estimated.
+		if nation, ok := c.ofcomManager.ExcludedNation(normalised); ok {
+			if nation == "" {
+				nation = "Channel Islands or Isle of Man"
+			}
+			err := fmt.Errorf("%s: %w", nation, ofcom.ErrNationNotStored)
+			result.Note = fmt.Sprintf("Mobile data unavailable: %v", err)
+			result.Err = err
+			return result
+		}
 		result.Note = "Postcode not found in Ofcom mobile dataset."
+		result.Err = ErrNotInDataset
+		if !opts.NoEstimate {
+			c.estimate(&result, geo, opts)
+		}
 		return result
 	}
 
-	summary := ofcom.Interpret(row)
+	summary := c.primary.Interpret(row, opts)
+	c.addPremises(&summary)
+	c.addRank(&summary, row, geo)
 	result.Mobile = &summary
 	return result
 }
 
-// CheckMultiple checks multiple postcodes concurrently.
+// addPremises adds any premises coverage imported by setup --premises to
+// summary.
+func (c *Checker) addPremises(summary *ofcom.MobileSummary) {
+	p, err := c.ofcomManager.QueryPremises(summary.Postcode)
+	if err != nil {
+		c.logger.Debug("no premises coverage", "postcode", summary.Postcode, "err", err)
+		return
+	}
+	summary.AddPremises(p)
+}
+
+// addRank ranks the postcode's standard score nationally and, with geo,
+// within its region (country outside England) and district.
+func (c *Checker) addRank(summary *ofcom.MobileSummary, row map[string]string, geo *postcode.Result) {
+	var region, district string
+	if geo != nil {
+		region, district = geo.Region, geo.AdminDistrict
+		if region == "" {
+			region = geo.Country
+		}
+	}
+	rank, err := c.ofcomManager.RankOf(ofcom.Interpret(row).CoverageScore, region, district)
+	if err != nil {
+		c.logger.Debug("no coverage rank", "postcode", summary.Postcode, "err", err)
+		return
+	}
+	summary.Rank = rank
+}
+
+// estimate fills in result's coverage from the postcodes around geo, for a
+// postcode missing from the dataset. Without nearby geocoded postcodes the
+// result is left as it is.
+func (c *Checker) estimate(result *Result, geo *postcode.Result, opts CheckOptions) {
+	if geo.Eastings <= 0 || geo.Northings <= 0 {
+		return
+	}
+	row, used, err := c.ofcomManager.Estimate(result.Postcode, geo.Eastings, geo.Northings)
+	if err != nil || row == nil {
+		c.logger.Debug("no coverage estimate", "postcode", result.Postcode, "err", err)
+		return
+	}
+	summary := c.primary.Interpret(row, opts)
+	summary.Estimated = true
+	summary.EstimatedFrom = used
+	result.Mobile = &summary
+	result.Note = fmt.Sprintf("Postcode not found in Ofcom mobile dataset; coverage estimated from %d nearby postcodes.", len(used))
+}
+
+// checkWithoutGeo completes a check using only the Ofcom dataset after the
+// postcodes.io lookup failed with lookupErr.
+func (c *Checker) checkWithoutGeo(result Result, lookupErr error, opts CheckOptions) Result {
+	row, err := c.primary.Query(result.Postcode)
+	if err != nil || row == nil {
+		result.Error = fmt.Sprintf("Postcode lookup failed: %v", lookupErr)
+		result.Err = lookupErr
+		return result
+	}
+	result.Sources = c.querySources(result.Postcode, opts)
+	summary := c.primary.Interpret(row, opts)
+	c.addPremises(&summary)
+	c.addRank(&summary, row, nil)
+	result.Valid = true
+	result.Mobile = &summary
+	result.Note = "Geographic data unavailable: postcodes.io could not be reached."
+	if errors.Is(lookupErr, ErrOffline) {
+		result.Note = "Geographic data unavailable offline: import it with 'setup --onspd'."
+	}
+	result.Err = lookupErr
+	return result
+}
+
+// Suggest returns up to limit postcodes beginning with partial, using
+// postcodes.io autocomplete.
+func (c *Checker) Suggest(partial string, limit int) ([]string, error) {
+	return c.postcodeClient.Autocomplete(partial, limit)
+}
+
+// PostcodeStats returns the request counts of the postcodes.io client,
+// including requests held back by its rate limit.
+func (c *Checker) PostcodeStats() postcode.Stats {
+	return c.postcodeClient.Stats()
+}
+
+// Reload switches to the database file currently on disk; see
+// ofcom.Manager.Reload.
+func (c *Checker) Reload() error {
+	return c.ofcomManager.Reload()
+}
+
+// Close releases the Ofcom database and the check history, if any.
+func (c *Checker) Close() error {
+	err := c.ofcomManager.Close()
+	if c.history != nil {
+		err = errors.Join(err, c.history.Close())
+	}
+	return err
+}
+
+// LoadIndex holds the Ofcom dataset in memory for fast bulk checks; see
+// ofcom.Manager.LoadIndex.
+func (c *Checker) LoadIndex() error {
+	return c.ofcomManager.LoadIndex()
+}
+
+// DatasetMeta returns the installed dataset's metadata (dataset_year,
+// built_at).
+func (c *Checker) DatasetMeta() (map[string]string, error) {
+	return c.ofcomManager.Meta()
+}
+
+// Release returns the Ofcom release of the installed dataset, including
+// when it was built.
+func (c *Checker) Release() (*ofcom.Release, error) {
+	return c.ofcomManager.Release()
+}
+
+// InstalledYears returns the Ofcom dataset years available locally.
+func (c *Checker) InstalledYears() []string {
+	return c.ofcomManager.InstalledYears()
+}
+
+// Datasets describes the Ofcom dataset years available locally.
+func (c *Checker) Datasets() ([]ofcom.Dataset, error) {
+	return c.ofcomManager.Datasets()
+}
+
+// RemoveYear deletes an installed Ofcom dataset year other than the
+// current one.
+func (c *Checker) RemoveYear(year string) error {
+	return c.ofcomManager.RemoveYear(year)
+}
+
+// CheckMultiple checks multiple postcodes concurrently, DefaultWorkers at
+// a time.
 func (c *Checker) CheckMultiple(postcodes []string) []Result {
-	results := make([]Result, len(postcodes))
-	ch := make(chan struct {
-		idx int
-		res Result
-	}, len(postcodes))
-
-	for i, pc := range postcodes {
-		go func(idx int, p string) {
-			ch <- struct {
-				idx int
-				res Result
-			}{idx, c.Check(p)}
-		}(i, pc)
-	}
-
-	for range postcodes {
-		item := <-ch
-		results[item.idx] = item.res
-	}
-	return results
+	return c.CheckMultipleWith(postcodes, CheckOptions{})
+}
+
+// CheckMultipleWith checks multiple postcodes concurrently with the given
+// options. Use CheckBulk to set the concurrency or a timeout.
+func (c *Checker) CheckMultipleWith(postcodes []string, opts CheckOptions) []Result {
+	return c.CheckBulk(context.Background(), postcodes, opts, BulkOptions{})
 }
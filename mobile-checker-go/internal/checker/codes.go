@@ -0,0 +1,126 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/yourusername/mobile-checker/internal/geocoder"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+// ErrorCode classifies why a check failed or was incomplete, for callers
+// that handle failures programmatically rather than by message.
+type ErrorCode string
+
+const (
+	// CodeInvalidPostcode means the input is not shaped like a UK postcode.
+	CodeInvalidPostcode ErrorCode = "INVALID_POSTCODE"
+	// CodePostcodeNotFound means postcodes.io does not recognise the postcode.
+	CodePostcodeNotFound ErrorCode = "POSTCODE_NOT_FOUND"
+	// CodePostcodeTerminated means the postcode has been retired; see
+	// Result.Terminated.
+	CodePostcodeTerminated ErrorCode = "POSTCODE_TERMINATED"
+	// CodeNotInDataset means the postcode is valid but absent from the
+	// Ofcom dataset.
+	CodeNotInDataset ErrorCode = "NOT_IN_DATASET"
+	// CodeAddressNotFound means the geocoder could not find an address.
+	CodeAddressNotFound ErrorCode = "ADDRESS_NOT_FOUND"
+	// CodeDatasetMissing means the Ofcom database has not been built, or
+	// lacks the geographic data a query needs.
+	CodeDatasetMissing ErrorCode = "DATASET_MISSING"
+	// CodeDatasetOutdated means the database must be rebuilt with setup --force.
+	CodeDatasetOutdated ErrorCode = "DATASET_OUTDATED"
+	// CodeNationNotInstalled means the postcode's nation was left out when
+	// the dataset was built with setup --nations.
+	CodeNationNotInstalled ErrorCode = "NATION_NOT_INSTALLED"
+	// CodeYearNotInstalled means a requested dataset year has no local
+	// database.
+	CodeYearNotInstalled ErrorCode = "YEAR_NOT_INSTALLED"
+	// CodeVersionNotInstalled means the dataset build to compare against is
+	// no longer installed, so every postcode must be checked again.
+	CodeVersionNotInstalled ErrorCode = "VERSION_NOT_INSTALLED"
+	// CodeUpstreamTimeout means postcodes.io did not answer in time.
+	CodeUpstreamTimeout ErrorCode = "UPSTREAM_TIMEOUT"
+	// CodeUpstreamUnavailable means postcodes.io, the geocoder or the
+	// fallback server could not be reached or failed.
+	CodeUpstreamUnavailable ErrorCode = "UPSTREAM_UNAVAILABLE"
+	// CodeSkipped means the postcode was not checked because an earlier
+	// check in a fail-fast bulk run failed.
+	CodeSkipped ErrorCode = "SKIPPED"
+	// CodeInternal covers any other failure.
+	CodeInternal ErrorCode = "INTERNAL"
+)
+
+// CodeOf returns the ErrorCode for err, or "" if err is nil.
+func CodeOf(err error) ErrorCode {
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, postcode.ErrInvalid):
+		return CodeInvalidPostcode
+	case errors.Is(err, ErrTerminated):
+		return CodePostcodeTerminated
+	case errors.Is(err, postcode.ErrNotFound):
+		return CodePostcodeNotFound
+	case errors.Is(err, geocoder.ErrNoMatch):
+		return CodeAddressNotFound
+	case errors.Is(err, ErrNotInDataset):
+		return CodeNotInDataset
+	case errors.Is(err, ofcom.ErrDatabaseNotFound), errors.Is(err, ofcom.ErrNoGeoData):
+		return CodeDatasetMissing
+	case errors.Is(err, ofcom.ErrSchemaOutdated):
+		return CodeDatasetOutdated
+	case errors.Is(err, ErrSkipped):
+		return CodeSkipped
+	case errors.Is(err, ofcom.ErrYearNotInstalled):
+		return CodeYearNotInstalled
+	case errors.Is(err, ofcom.ErrVersionNotInstalled):
+		return CodeVersionNotInstalled
+	case errors.Is(err, ofcom.ErrNationNotStored):
+		return CodeNationNotInstalled
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return CodeUpstreamTimeout
+	case errors.Is(err, postcode.ErrUnavailable), errors.Is(err, geocoder.ErrUnavailable),
+		errors.Is(err, ErrFallbackUnavailable):
+		return CodeUpstreamUnavailable
+	default:
+		return CodeInternal
+	}
+}
+
+// ErrorFor returns the error that CodeOf maps to code, or nil for a code
+// without one such as CodeInternal, so errors.Is works on errors rebuilt from
+// a code in an API response.
+func ErrorFor(code ErrorCode) error {
+	switch code {
+	case CodeInvalidPostcode:
+		return postcode.ErrInvalid
+	case CodePostcodeNotFound:
+		return postcode.ErrNotFound
+	case CodePostcodeTerminated:
+		return ErrTerminated
+	case CodeNotInDataset:
+		return ErrNotInDataset
+	case CodeAddressNotFound:
+		return geocoder.ErrNoMatch
+	case CodeDatasetMissing:
+		return ofcom.ErrDatabaseNotFound
+	case CodeDatasetOutdated:
+		return ofcom.ErrSchemaOutdated
+	case CodeYearNotInstalled:
+		return ofcom.ErrYearNotInstalled
+	case CodeVersionNotInstalled:
+		return ofcom.ErrVersionNotInstalled
+	case CodeNationNotInstalled:
+		return ofcom.ErrNationNotStored
+	case CodeSkipped:
+		return ErrSkipped
+	case CodeUpstreamTimeout, CodeUpstreamUnavailable:
+		return postcode.ErrUnavailable
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+// Diff compares pc's coverage in two installed dataset years, e.g. to
+// track operator rollout. Both years must be installed (see
+// ofcom.Manager.ForYear) and hold a row for the postcode; otherwise the
+// error wraps ofcom.ErrYearNotInstalled or ErrNotInDataset.
+func (c *Checker) Diff(pc, from, to string, opts CheckOptions) (*ofcom.CoverageDiff, error) {
+	if err := postcode.Validate(pc); err != nil {
+		return nil, fmt.Errorf("postcode %q: %w", pc, err)
+	}
+	normalised := postcode.Normalise(pc)
+	rows := make([]map[string]string, 2)
+	for i, year := range []string{from, to} {
+		m, err := c.ofcomManager.ForYear(year)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", year, err)
+		}
+		row, err := m.QueryPostcode(normalised)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", year, err)
+		}
+		if row == nil {
+			return nil, fmt.Errorf("%s: %w", year, ErrNotInDataset)
+		}
+		rows[i] = row
+	}
+	return &ofcom.CoverageDiff{
+		Postcode: normalised,
+		From:     from,
+		To:       to,
+		Indoor:   opts.Indoor,
+		Operators: ofcom.DiffRows(rows[0], rows[1], ofcom.InterpretOptions{
+			Indoor: opts.Indoor, Operators: opts.Operators, Threshold: opts.Threshold,
+		}),
+	}, nil
+}
+
+// YearCoverage is a postcode's coverage in one installed dataset year.
+type YearCoverage struct {
+	Year   string
+	Mobile *ofcom.MobileSummary // nil when the year has no row for the postcode
+}
+
+// CoverageByYear returns pc's coverage in each installed dataset year,
+// oldest first. Only the datasets are read: there is no postcodes.io
+// lookup, estimate or additional source.
+func (c *Checker) CoverageByYear(pc string, opts CheckOptions) ([]YearCoverage, error) {
+	if err := postcode.Validate(pc); err != nil {
+		return nil, fmt.Errorf("postcode %q: %w", pc, err)
+	}
+	normalised := postcode.Normalise(pc)
+	var out []YearCoverage
+	for _, year := range c.InstalledYears() {
+		m, err := c.ofcomManager.ForYear(year)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", year, err)
+		}
+		row, err := m.QueryPostcode(normalised)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", year, err)
+		}
+		yc := YearCoverage{Year: year}
+		if row != nil {
+			summary := OfcomSource(m).Interpret(row, opts)
+			yc.Mobile = &summary
+		}
+		out = append(out, yc)
+	}
+	return out, nil
+}
+
+// ErrTrendNeedsYears is returned by Trend when fewer than two installed
+// dataset years hold a row for the postcode.
+var ErrTrendNeedsYears = errors.New("a trend needs at least two installed dataset years holding the postcode")
+
+// Trend extrapolates pc's coverage from every installed dataset year
+// holding it; see ofcom.TrendRows. The forecasts are indicative only.
+func (c *Checker) Trend(pc string, opts CheckOptions) (*ofcom.Trend, error) {
+	if err := postcode.Validate(pc); err != nil {
+		return nil, fmt.Errorf("postcode %q: %w", pc, err)
+	}
+	normalised := postcode.Normalise(pc)
+	years := c.InstalledYears()
+	rows := make([]map[string]string, len(years))
+	for i, year := range years {
+		m, err := c.ofcomManager.ForYear(year)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", year, err)
+		}
+		if rows[i], err = m.QueryPostcode(normalised); err != nil {
+			return nil, fmt.Errorf("%s: %w", year, err)
+		}
+	}
+	t := ofcom.TrendRows(years, rows, ofcom.InterpretOptions{
+		Indoor: opts.Indoor, Operators: opts.Operators, Threshold: opts.Threshold,
+	})
+	if t == nil {
+		return nil, ErrTrendNeedsYears
+	}
+	return t, nil
+}
+
+// Changed checks those of postcodes whose coverage differs from that in
+// the dataset build version, a Release.Version, e.g. one a caller's stored
+// results came from, and returns their results in input order. Coverage
+// differs when the postcode gained or lost its row, or any selected
+// operator's voice, 4G or 5G percentage moved, as Diff reports it.
+// Postcodes that fail validation are returned with their errors. It
+// compares against the dataset of opts.Year when set, and returns an
+// error wrapping ofcom.ErrVersionNotInstalled when version cannot be read,
+// in which case every postcode may have changed.
+func (c *Checker) Changed(ctx context.Context, postcodes []string, version string, opts CheckOptions, bulk BulkOptions) ([]Result, error) {
+	cur := c.ofcomManager
+	if opts.Year != "" {
+		m, err := cur.ForYear(opts.Year)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", opts.Year, err)
+		}
+		cur = m
+	}
+	prev, err := c.ofcomManager.ForVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", version, err)
+	}
+	rel, err := cur.Release()
+	if err != nil {
+		return nil, err
+	}
+
+	var valid []int
+	changed := make([]bool, len(postcodes))
+	for i, pc := range postcodes {
+		if postcode.Validate(pc) != nil {
+			changed[i] = true
+		} else {
+			valid = append(valid, i)
+		}
+	}
+	if rel.Version != version && len(valid) > 0 {
+		pcs := make([]string, len(valid))
+		for j, i := range valid {
+			pcs[j] = postcodes[i]
+		}
+		before, err := prev.QueryPostcodes(pcs)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", version, err)
+		}
+		after, err := cur.QueryPostcodes(pcs)
+		if err != nil {
+			return nil, err
+		}
+		for j, i := range valid {
+			changed[i] = rowsDiffer(before[j], after[j], opts)
+		}
+	}
+
+	var recheck []string
+	for i, pc := range postcodes {
+		if changed[i] {
+			recheck = append(recheck, pc)
+		}
+	}
+	if len(recheck) == 0 {
+		return nil, nil
+	}
+	return c.CheckBulk(ctx, recheck, opts, bulk), nil
+}
+
+// rowsDiffer reports whether two raw rows for a postcode give different
+// coverage under opts, indoor as well as outdoor when both are reported.
+func rowsDiffer(from, to map[string]string, opts CheckOptions) bool {
+	if from == nil || to == nil {
+		return (from == nil) != (to == nil)
+	}
+	modes := []bool{opts.Indoor}
+	if opts.AddIndoor && !opts.Indoor {
+		modes = append(modes, true)
+	}
+	for _, indoor := range modes {
+		for _, op := range ofcom.DiffRows(from, to, ofcom.InterpretOptions{Indoor: indoor, Operators: opts.Operators}) {
+			for _, t := range op.Technologies {
+				if t.ChangePP != 0 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
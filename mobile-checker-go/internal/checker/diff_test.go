@@ -0,0 +1,231 @@
+package checker_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func TestCoverageByYear_ReadsEachInstalledYear(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"ofcom_mobile_2023.csv":            "postcode,ee_4g,o2_4g\nLS11AA,0.9,0.9\n",
+		"years/2022/ofcom_mobile_2022.csv": "postcode,ee_4g,o2_4g\nLS11AA,0.9,0.1\nLS11AB,1,1\n",
+	}
+	for name, csv := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(csv), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := ofcom.NewManager(dir)
+	for _, year := range []string{"2023", "2022"} {
+		if err := m.Setup(year, ofcom.SetupOptions{}); err != nil {
+			t.Fatalf("setup %s failed: %v", year, err)
+		}
+	}
+	c := checker.New(dir)
+
+	years, err := c.CoverageByYear("LS1 1AA", checker.CheckOptions{})
+	if err != nil {
+		t.Fatalf("CoverageByYear failed: %v", err)
+	}
+	if len(years) != 2 || years[0].Year != "2022" || years[1].Year != "2023" {
+		t.Fatalf("expected 2022 then 2023, got %+v", years)
+	}
+	if got := years[0].Mobile.Overall.FourGCount; got != 1 {
+		t.Errorf("expected 1 operator with 4G in 2022, got %d", got)
+	}
+	if got := years[1].Mobile.Overall.FourGCount; got != 2 {
+		t.Errorf("expected 2 operators with 4G in 2023, got %d", got)
+	}
+
+	years, err = c.CoverageByYear("LS11AB", checker.CheckOptions{})
+	if err != nil || len(years) != 2 || years[0].Mobile == nil || years[1].Mobile != nil {
+		t.Errorf("expected LS11AB only in 2022, got %+v (err %v)", years, err)
+	}
+}
+
+func TestCheck_TrendForecastsFromInstalledYears(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"ofcom_mobile_2023.csv":            "postcode,three_4g,three_5g\nLS11AA,0.9,0.3\n",
+		"years/2022/ofcom_mobile_2022.csv": "postcode,three_4g,three_5g\nLS11AA,0.9,0.2\n",
+		"years/2021/ofcom_mobile_2021.csv": "postcode,three_4g,three_5g\nLS11AA,0.9,0.1\nLS11AB,1,1\n",
+	}
+	for name, csv := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(csv), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := ofcom.NewManager(dir)
+	for _, year := range []string{"2023", "2022", "2021"} {
+		if err := m.Setup(year, ofcom.SetupOptions{}); err != nil {
+			t.Fatalf("setup %s failed: %v", year, err)
+		}
+	}
+	c := checker.New(dir, checker.WithOffline())
+	defer c.Close()
+
+	r := c.CheckWith("LS1 1AA", checker.CheckOptions{Operators: []string{"three"}, Trend: true})
+	if r.Trend == nil {
+		t.Fatalf("expected a trend, got %+v", r)
+	}
+	if len(r.Trend.Years) != 3 || r.Trend.Note == "" {
+		t.Errorf("expected three years marked indicative, got %+v", r.Trend)
+	}
+	fiveG := r.Trend.Operators[0].Technologies[2]
+	if fiveG.PerYearPP != 10 || fiveG.ChangePP != 20 || fiveG.ReachesTargetBy != 2025 {
+		t.Errorf("expected 5G rising 10pp a year to 50%% by 2025, got %+v", fiveG)
+	}
+	if got := r.Trend.Forecasts(); len(got) != 1 || got[0] != "Three 5G likely ≥50% by 2025 at current rate" {
+		t.Errorf("unexpected forecasts %q", got)
+	}
+	if fourG := r.Trend.Operators[0].Technologies[1]; fourG.PerYearPP != 0 || fourG.Forecast != "" {
+		t.Errorf("expected flat 4G without a forecast, got %+v", fourG)
+	}
+
+	if r := c.CheckWith("LS1 1AA", checker.CheckOptions{}); r.Trend != nil {
+		t.Errorf("expected no trend unless asked for, got %+v", r.Trend)
+	}
+	if _, err := c.Trend("LS11AB", checker.CheckOptions{}); !errors.Is(err, checker.ErrTrendNeedsYears) {
+		t.Errorf("expected ErrTrendNeedsYears for a postcode in one year, got %v", err)
+	}
+}
+
+func TestChanged_ChecksOnlyPostcodesThatDiffer(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"ofcom_mobile_2023.csv":            "postcode,ee_4g,o2_4g\nLS11AA,0.9,0.9\nLS11AB,1,1\nLS11AD,1,1\n",
+		"years/2022/ofcom_mobile_2022.csv": "postcode,ee_4g,o2_4g\nLS11AA,0.9,0.1\nLS11AB,1,1\nLS11AC,1,1\n",
+	}
+	for name, csv := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(csv), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := ofcom.NewManager(dir)
+	for _, year := range []string{"2023", "2022"} {
+		if err := m.Setup(year, ofcom.SetupOptions{}); err != nil {
+			t.Fatalf("setup %s failed: %v", year, err)
+		}
+	}
+	c := checker.New(dir, checker.WithOffline())
+	older, err := c.ForYear("2022")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rel, err := older.Release()
+	if err != nil || rel.Version == "" {
+		t.Fatalf("expected a version for 2022, got %+v (err %v)", rel, err)
+	}
+
+	// LS11AA changed, LS11AB did not, LS11AC left the dataset and LS11AD
+	// joined it.
+	pcs := []string{"LS11AD", "LS11AB", "not a postcode", "LS11AC", "LS1 1AA"}
+	results, err := c.Changed(context.Background(), pcs, rel.Version, checker.CheckOptions{}, checker.BulkOptions{})
+	if err != nil {
+		t.Fatalf("Changed failed: %v", err)
+	}
+	var got []string
+	for _, r := range results {
+		got = append(got, r.Postcode)
+	}
+	if want := "LS11AD,NOTAPOSTCODE,LS11AC,LS11AA"; strings.Join(got, ",") != want {
+		t.Errorf("expected %s, got %v", want, got)
+	}
+
+	results, err = c.Changed(context.Background(), pcs, rel.Version, checker.CheckOptions{Operators: []string{"ee"}}, checker.BulkOptions{})
+	if err != nil || len(results) != 3 {
+		t.Errorf("expected LS11AA unchanged for EE alone, got %d results (err %v)", len(results), err)
+	}
+
+	current, _ := c.Release()
+	if results, err := c.Changed(context.Background(), []string{"LS11AA"}, current.Version, checker.CheckOptions{}, checker.BulkOptions{}); err != nil || len(results) != 0 {
+		t.Errorf("expected nothing changed since the current version, got %v (err %v)", results, err)
+	}
+	for _, version := range []string{"2022@2001-01-01T00:00:00Z", "2019@" + rel.BuiltAt, "2022"} {
+		if _, err := c.Changed(context.Background(), pcs, version, checker.CheckOptions{}, checker.BulkOptions{}); !errors.Is(err, ofcom.ErrVersionNotInstalled) {
+			t.Errorf("%s: expected ErrVersionNotInstalled, got %v", version, err)
+		}
+	}
+}
+
+func TestChanged_ComparesAgainstTheBuildARebuildReplaced(t *testing.T) {
+	var mu sync.Mutex
+	csv := "postcode,ee_4g,o2_4g\nLS11AA,0.9,0.9\nLS11AB,1,1\n"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		fw, _ := zw.Create("mobile_pc.csv")
+		fw.Write([]byte(csv))
+		zw.Close()
+		w.Write(buf.Bytes())
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	m := ofcom.NewManager(dir)
+	defer m.Close()
+	rebuild := func() string {
+		t.Helper()
+		if err := m.Setup("2023", ofcom.SetupOptions{URL: srv.URL, Force: true}); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+		rel, err := m.Release()
+		if err != nil || rel.Version == "" {
+			t.Fatalf("expected a version, got %+v (err %v)", rel, err)
+		}
+		return rel.Version
+	}
+	first := rebuild()
+
+	mu.Lock()
+	csv = "postcode,ee_4g,o2_4g\nLS11AA,0.9,0.2\nLS11AB,1,1\n"
+	mu.Unlock()
+	second := rebuild()
+	if second == first {
+		t.Fatalf("expected the rebuild to have a new version, got %s twice", first)
+	}
+
+	c := checker.New(dir, checker.WithOffline())
+	defer c.Close()
+	pcs := []string{"LS11AA", "LS11AB"}
+	results, err := c.Changed(context.Background(), pcs, first, checker.CheckOptions{}, checker.BulkOptions{})
+	if err != nil {
+		t.Fatalf("Changed since the replaced build failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Postcode != "LS11AA" {
+		t.Errorf("expected only LS11AA changed by the rebuild, got %v", results)
+	}
+
+	// Only the build the last rebuild replaced is kept.
+	third := rebuild()
+	if _, err := c.Changed(context.Background(), pcs, first, checker.CheckOptions{}, checker.BulkOptions{}); !errors.Is(err, ofcom.ErrVersionNotInstalled) {
+		t.Errorf("expected ErrVersionNotInstalled two rebuilds on, got %v", err)
+	}
+	if results, err := c.Changed(context.Background(), pcs, second, checker.CheckOptions{}, checker.BulkOptions{}); err != nil || len(results) != 0 {
+		t.Errorf("expected nothing changed since %s by an identical rebuild to %s, got %v (err %v)", second, third, results, err)
+	}
+	if years := m.InstalledYears(); strings.Join(years, ",") != "2023" {
+		t.Errorf("expected the superseded build not listed as a year, got %v", years)
+	}
+}
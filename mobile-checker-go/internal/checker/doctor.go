@@ -0,0 +1,90 @@
+package checker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+// Problem is an issue found by Doctor with a suggested fix.
+type Problem struct {
+	Message string `json:"message"`
+	Fix     string `json:"fix"`
+}
+
+// ServiceStatus reports whether an external dependency is reachable.
+type ServiceStatus struct {
+	Checked   bool   `json:"checked"`
+	Reachable bool   `json:"reachable"`
+	LatencyMs int64  `json:"latency_ms,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Report is the output of Doctor.
+type Report struct {
+	DataDir       string               `json:"data_dir"`
+	Driver        string               `json:"driver"`
+	SchemaVersion int                  `json:"expected_schema_version"`
+	Dataset       *ofcom.DatasetStatus `json:"dataset"`
+	Manifest      *ofcom.Manifest      `json:"manifest,omitempty"`
+	PostcodesIO   ServiceStatus        `json:"postcodes_io"`
+	Problems      []Problem            `json:"problems"`
+}
+
+// Doctor inspects the local installation and, unless offline, checks that
+// postcodes.io is reachable. Problems carry suggested remediation.
+func (c *Checker) Doctor(offline bool) (*Report, error) {
+	m := c.ofcomManager
+	r := &Report{
+		DataDir:       m.DataDir,
+		Driver:        m.Driver.Name,
+		SchemaVersion: ofcom.SchemaVersion,
+		Problems:      []Problem{},
+	}
+
+	st, err := m.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect database: %w", err)
+	}
+	r.Dataset = st
+	if r.Manifest, err = m.Manifest(); err != nil {
+		r.Problems = append(r.Problems, Problem{err.Error(), "mobile-checker setup --force"})
+	}
+
+	switch {
+	case !st.Exists:
+		r.Problems = append(r.Problems, Problem{"Ofcom database not found", "mobile-checker setup --year " + ofcom.LatestKnownYear()})
+	case st.Error != "":
+		r.Problems = append(r.Problems, Problem{"database cannot be read: " + st.Error, "mobile-checker setup --force"})
+	case st.SchemaVersion == 0:
+		r.Problems = append(r.Problems, Problem{"database was built by an older version", "mobile-checker setup --force"})
+	case st.Rows == 0:
+		r.Problems = append(r.Problems, Problem{"database contains no rows", "mobile-checker setup --force"})
+	default:
+		if st.SchemaVersion < ofcom.SchemaVersion {
+			r.Problems = append(r.Problems, Problem{
+				fmt.Sprintf("schema version %d is older than %d", st.SchemaVersion, ofcom.SchemaVersion),
+				"mobile-checker setup (migrates in place)"})
+		}
+		if st.GeocodedRows == 0 {
+			r.Problems = append(r.Problems, Problem{"postcodes are not geocoded; area statistics unavailable", "mobile-checker setup --geocode"})
+		}
+	}
+	if r.Manifest != nil && !r.Manifest.Verified {
+		r.Problems = append(r.Problems, Problem{"dataset checksum was not verified", "mobile-checker setup --force --sha256 <checksum>"})
+	}
+
+	if !offline {
+		r.PostcodesIO.Checked = true
+		start := time.Now()
+		if err := c.postcodeClient.Ping(); err != nil {
+			r.PostcodesIO.Error = err.Error()
+			r.Problems = append(r.Problems, Problem{"postcodes.io is unreachable: " + err.Error(), "check network access or proxy settings"})
+		} else {
+			r.PostcodesIO.Reachable = true
+			r.PostcodesIO.LatencyMs = time.Since(start).Milliseconds()
+		}
+	}
+	return r, nil
+}
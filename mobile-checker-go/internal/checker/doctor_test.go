@@ -0,0 +1,36 @@
+package checker_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func TestDoctor_SuggestsLatestKnownYear(t *testing.T) {
+	r, err := checker.New(t.TempDir()).Doctor(true)
+	if err != nil {
+		t.Fatalf("doctor failed: %v", err)
+	}
+	want := "setup --year " + ofcom.LatestKnownYear()
+	if len(r.Problems) != 1 || !strings.HasSuffix(r.Problems[0].Fix, want) {
+		t.Errorf("expected one problem fixed by %q, got %+v", want, r.Problems)
+	}
+}
+
+func TestDoctor_ReportsUnreadableDatabase(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mobile.db"), []byte("not a database, just some text for a header"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := checker.New(dir).Doctor(true)
+	if err != nil {
+		t.Fatalf("doctor failed: %v", err)
+	}
+	if r.Dataset.Error == "" || len(r.Problems) == 0 || !strings.HasPrefix(r.Problems[0].Message, "database cannot be read") {
+		t.Errorf("expected an unreadable database problem, got %+v", r.Problems)
+	}
+}
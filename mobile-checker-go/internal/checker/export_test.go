@@ -0,0 +1,7 @@
+package checker
+
+// Exported for tests in package checker_test.
+var (
+	SamplePath = samplePath
+	FindGaps   = findGaps
+)
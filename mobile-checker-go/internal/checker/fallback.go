@@ -0,0 +1,144 @@
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+// ErrFallbackUnavailable is set on a Result when the fallback server could
+// not be reached or gave an unusable answer.
+var ErrFallbackUnavailable = errors.New("fallback server unavailable")
+
+// WithFallback sends checks to the mobile-checker server at baseURL, e.g.
+// https://coverage.example.com, while the local Ofcom database has not been
+// built, instead of failing with "run setup first". The server applies its
+// own score weights and threshold; indoor coverage is not available.
+func WithFallback(baseURL string) Option {
+	return func(c *Checker) {
+		c.fallback = &fallbackClient{
+			baseURL: strings.TrimRight(baseURL, "/"),
+			http:    &http.Client{Timeout: 30 * time.Second},
+		}
+	}
+}
+
+// fallbackClient checks postcodes against a remote mobile-checker server's
+// GET /api/mobile/{postcode}.
+type fallbackClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+// useFallback reports whether checks should go to the fallback server:
+// one is configured and the database file does not exist.
+func (c *Checker) useFallback() bool {
+	if c.fallback == nil || c.ofcomManager.Remote() != "" {
+		return false
+	}
+	_, err := os.Stat(c.ofcomManager.DBPath)
+	return os.IsNotExist(err)
+}
+
+// check asks the server about pc. The Result's Code comes from the server
+// and Err wraps the matching sentinel error, so errors.Is works as for
+// local checks.
+func (f *fallbackClient) check(ctx context.Context, pc string, opts CheckOptions) Result {
+	result := Result{Postcode: postcode.Normalise(pc), Fallback: f.baseURL}
+	fail := func(err error) Result {
+		result.Error = fmt.Sprintf("Dataset unavailable: %v", err)
+		result.Err = err
+		result.Code = CodeOf(err)
+		return result
+	}
+	if opts.Indoor {
+		return fail(fmt.Errorf("%w: indoor coverage is not available from %s", ofcom.ErrDatabaseNotFound, f.baseURL))
+	}
+
+	q := url.Values{}
+	if len(opts.Operators) > 0 {
+		q.Set("operators", strings.Join(opts.Operators, ","))
+	}
+	if opts.NoEstimate {
+		q.Set("estimate", "false")
+	}
+	if opts.Year != "" {
+		q.Set("year", opts.Year)
+	}
+	u := f.baseURL + "/api/mobile/" + url.PathEscape(result.Postcode)
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return fail(fmt.Errorf("%w: %v", ErrFallbackUnavailable, err))
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := f.http.Do(req)
+	if err != nil {
+		return fail(fmt.Errorf("%w: %v", ErrFallbackUnavailable, err))
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Status  string  `json:"status"`
+		Code    string  `json:"code"`
+		Message string  `json:"message"`
+		Result  *Result `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fail(fmt.Errorf("%w: %s answered %s", ErrFallbackUnavailable, f.baseURL, resp.Status))
+	}
+	if body.Result != nil {
+		result = *body.Result
+		result.Fallback = f.baseURL
+	}
+	if body.Status != "ok" {
+		if body.Message == "" {
+			body.Message = resp.Status
+		}
+		result.Error = body.Message
+	}
+	if body.Code != "" {
+		result.Code = ErrorCode(body.Code)
+	}
+	if result.Code != "" {
+		msg := result.Error
+		if msg == "" {
+			msg = result.Note
+		}
+		result.Err = fmt.Errorf("%s: %w", msg, sentinelFor(result.Code))
+	}
+	return result
+}
+
+// ping checks the server's GET /healthz.
+func (f *fallbackClient) ping() error {
+	resp, err := f.http.Get(f.baseURL + "/healthz")
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrFallbackUnavailable, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s answered %s", ErrFallbackUnavailable, f.baseURL, resp.Status)
+	}
+	return nil
+}
+
+// sentinelFor returns the error that CodeOf maps to code, treating codes
+// without one as an unusable answer from the server.
+func sentinelFor(code ErrorCode) error {
+	if err := ErrorFor(code); err != nil {
+		return err
+	}
+	return ErrFallbackUnavailable
+}
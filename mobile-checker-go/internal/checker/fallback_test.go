@@ -0,0 +1,50 @@
+package checker
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckWith_FallsBackWhileDatasetMissing(t *testing.T) {
+	var gotPath, gotQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotQuery = r.URL.Path, r.URL.RawQuery
+		if r.URL.Path == "/api/mobile/AB12DE" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"status":"error","code":"POSTCODE_NOT_FOUND","message":"Postcode not found"}`))
+			return
+		}
+		w.Write([]byte(`{"status":"ok","result":{"postcode":"SW1A1AA","valid":true,"mobile":{"CoverageScore":90}}}`))
+	}))
+	defer srv.Close()
+
+	c := New(t.TempDir(), WithFallback(srv.URL+"/"))
+	r := c.CheckWith("sw1a 1aa", CheckOptions{Operators: []string{"ee"}, NoEstimate: true})
+	if r.Error != "" || r.Mobile == nil || r.Mobile.CoverageScore != 90 {
+		t.Fatalf("expected the fallback server's result, got %+v", r)
+	}
+	if r.Fallback != srv.URL || gotPath != "/api/mobile/SW1A1AA" || gotQuery != "estimate=false&operators=ee" {
+		t.Errorf("unexpected request %s?%s or fallback %q", gotPath, gotQuery, r.Fallback)
+	}
+
+	r = c.CheckWith("AB1 2DE", CheckOptions{})
+	if r.Code != CodePostcodeNotFound || r.Error != "Postcode not found" {
+		t.Errorf("expected the server's error, got %+v", r)
+	}
+
+	if r = c.CheckWith("not a postcode", CheckOptions{}); r.Code != CodeInvalidPostcode || r.Fallback != "" {
+		t.Errorf("expected invalid postcodes to be rejected locally, got %+v", r)
+	}
+}
+
+func TestCheckWith_FallbackUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	r := New(t.TempDir(), WithFallback(srv.URL)).CheckWith("SW1A1AA", CheckOptions{})
+	if r.Code != CodeUpstreamUnavailable || !errors.Is(r.Err, ErrFallbackUnavailable) {
+		t.Errorf("expected UPSTREAM_UNAVAILABLE, got %q (%v)", r.Code, r.Err)
+	}
+}
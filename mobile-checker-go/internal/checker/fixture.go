@@ -0,0 +1,93 @@
+package checker
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+// Fixtures are canned results keyed by normalised postcode; see
+// WithFixtures.
+type Fixtures map[string]Result
+
+// LoadFixtures reads a fixture file: a JSON array of results, as printed by
+// 'mobile-checker check --json', or an object mapping postcodes to results.
+// A result's code, e.g. "POSTCODE_NOT_FOUND", gives it the matching error.
+func LoadFixtures(path string) (Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var results []Result
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		var byPostcode map[string]Result
+		if err := json.Unmarshal(data, &byPostcode); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for pc, r := range byPostcode {
+			r.Postcode = pc
+			results = append(results, r)
+		}
+	} else if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	f := make(Fixtures, len(results))
+	for i, r := range results {
+		r.Postcode = postcode.Normalise(r.Postcode)
+		if err := postcode.Validate(r.Postcode); err != nil {
+			return nil, fmt.Errorf("%s: fixture %d: %w", path, i+1, err)
+		}
+		if r.Code != "" {
+			msg := r.Error
+			if msg == "" {
+				msg = r.Note
+			}
+			if sentinel := ErrorFor(r.Code); sentinel != nil {
+				r.Err = fmt.Errorf("%s: %w", msg, sentinel)
+			} else {
+				r.Err = errors.New(msg)
+			}
+		}
+		f[r.Postcode] = r
+	}
+	return f, nil
+}
+
+// WithFixtures answers every check from f instead of postcodes.io and the
+// Ofcom database, for integration tests against a predictable server.
+// Invalid postcodes are rejected as usual and valid ones missing from f are
+// not found. Check options are ignored: a fixture is returned as written.
+func WithFixtures(f Fixtures) Option {
+	return func(c *Checker) { c.fixtures = f }
+}
+
+// check returns the fixture for pc.
+func (f Fixtures) check(pc string) Result {
+	normalised := postcode.Normalise(pc)
+	if r, ok := f[normalised]; ok {
+		return r
+	}
+	result := Result{Postcode: normalised}
+	if err := postcode.Validate(normalised); err != nil {
+		result.Error = fmt.Sprintf("Invalid postcode: %v", err)
+		result.Err = err
+	} else {
+		result.Error = fmt.Sprintf("Postcode lookup failed: %v", postcode.ErrNotFound)
+		result.Err = postcode.ErrNotFound
+	}
+	result.Code = CodeOf(result.Err)
+	return result
+}
+
+// ready reports on the fixtures for Ready.
+func (f Fixtures) ready() error {
+	if len(f) == 0 {
+		return fmt.Errorf("no fixtures loaded")
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package checker
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+func TestWithFixtures(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	list, err := LoadFixtures(write("list.json", `[
+		{"postcode":"sw1a 1aa","valid":true,"mobile":{"CoverageScore":90,"Grade":"A"}},
+		{"postcode":"EC1A1BB","error":"Postcode lookup failed: postcode not found","code":"POSTCODE_NOT_FOUND"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	byPostcode, err := LoadFixtures(write("map.json", `{"SW1A 1AA":{"valid":true,"mobile":{"CoverageScore":90}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFixtures(write("bad.json", `[{"postcode":"nope"}]`)); err == nil {
+		t.Error("expected an invalid fixture postcode to be rejected")
+	}
+
+	for name, f := range map[string]Fixtures{"list": list, "map": byPostcode} {
+		c := New(dir, WithFixtures(f))
+		if r := c.CheckWith("SW1A1AA", CheckOptions{Indoor: true}); r.Mobile == nil || r.Mobile.CoverageScore != 90 || r.Postcode != "SW1A1AA" {
+			t.Errorf("%s: expected the fixture, got %+v", name, r)
+		}
+		if r := c.Check("LS1 1AA"); r.Code != CodePostcodeNotFound || !errors.Is(r.Err, postcode.ErrNotFound) {
+			t.Errorf("%s: expected a missing fixture to be not found, got %+v", name, r)
+		}
+		if r := c.Check("not a postcode"); r.Code != CodeInvalidPostcode {
+			t.Errorf("%s: expected an invalid postcode, got %+v", name, r)
+		}
+		if ready := c.Ready(true); !ready.Ready || !ready.Components["fixtures"].OK {
+			t.Errorf("%s: expected fixtures alone to be ready, got %+v", name, ready)
+		}
+	}
+
+	r := New(dir, WithFixtures(list)).Check("EC1A 1BB")
+	if r.Code != CodePostcodeNotFound || !errors.Is(r.Err, postcode.ErrNotFound) {
+		t.Errorf("expected the fixture's error, got %+v", r)
+	}
+}
@@ -0,0 +1,188 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+// geocodeWorkers is the number of concurrent postcodes.io bulk requests.
+const geocodeWorkers = 4
+
+// Geocode looks up every postcode in the Ofcom database that has no
+// geographic data yet via postcodes.io bulk lookups and stores the results,
+// enabling area-level aggregation. It can be re-run to resume.
+func (c *Checker) Geocode() error {
+	pcs, err := c.ofcomManager.UngeocodedPostcodes()
+	if err != nil {
+		return err
+	}
+	if len(pcs) == 0 {
+		c.logger.Info("all postcodes already geocoded")
+		return nil
+	}
+	c.logger.Info("geocoding postcodes via postcodes.io", "count", len(pcs))
+	// An encrypted database is decrypted once, not for every batch.
+	return c.ofcomManager.Unsealed(func(*ofcom.Manager) error { return c.geocode(pcs) })
+}
+
+func (c *Checker) geocode(pcs []string) error {
+	batches := make(chan []string)
+	results := make(chan []ofcom.Place)
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+	var firstErr error
+
+	var wg sync.WaitGroup
+	for i := 0; i < geocodeWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				found, err := c.postcodeClient.BulkLookup(batch)
+				if err != nil {
+					stopOnce.Do(func() {
+						firstErr = err
+						close(stop)
+					})
+					return
+				}
+				places := make([]ofcom.Place, len(batch))
+				for j, pc := range batch {
+					places[j] = placeFrom(pc, found[pc])
+				}
+				results <- places
+			}
+		}()
+	}
+
+	go func() {
+		defer close(batches)
+		for i := 0; i < len(pcs); i += postcode.MaxBulk {
+			end := i + postcode.MaxBulk
+			if end > len(pcs) {
+				end = len(pcs)
+			}
+			select {
+			case batches <- pcs[i:end]:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	done := 0
+	pending := make([]ofcom.Place, 0, 5000)
+	for places := range results {
+		pending = append(pending, places...)
+		done += len(places)
+		if len(pending) >= 5000 {
+			if err := c.ofcomManager.StoreGeo(pending); err != nil {
+				return err
+			}
+			pending = pending[:0]
+			c.logger.Info("geocoded postcodes", "done", done, "total", len(pcs))
+		}
+	}
+	if err := c.ofcomManager.StoreGeo(pending); err != nil {
+		return err
+	}
+	if firstErr != nil {
+		return fmt.Errorf("geocoding stopped after %d/%d postcodes (re-run to resume): %w", done, len(pcs), firstErr)
+	}
+	c.logger.Info("geocoding complete", "count", done)
+	return c.ofcomManager.BuildRanks()
+}
+
+// placeFrom converts a postcodes.io result to a geo row. A nil result
+// records the postcode as unlocatable.
+func placeFrom(pc string, r *postcode.Result) ofcom.Place {
+	if r == nil {
+		return ofcom.Place{Postcode: pc}
+	}
+	return ofcom.Place{
+		Postcode:      pc,
+		Country:       r.Country,
+		Region:        r.Region,
+		AdminDistrict: r.AdminDistrict,
+		Constituency:  r.ParliamentaryConstituency,
+		Latitude:      r.Latitude,
+		Longitude:     r.Longitude,
+		Eastings:      r.Eastings,
+		Northings:     r.Northings,
+	}
+}
+
+// lookup returns geographic data for a normalised postcode, from the geo
+// table when Geocode or ImportONSPD stored it and from postcodes.io
+// otherwise. In offline mode postcodes.io is never called and a postcode
+// without local data fails with ErrOffline.
+func (c *Checker) lookup(ctx context.Context, pc string) (*postcode.Result, error) {
+	if err := postcode.Validate(pc); err != nil {
+		return nil, fmt.Errorf("postcode %q: %w", pc, err)
+	}
+	if p, err := c.ofcomManager.Place(pc); err == nil && p != nil {
+		return &postcode.Result{
+			Postcode:                  postcode.Format(p.Postcode),
+			Country:                   p.Country,
+			Region:                    p.Region,
+			AdminDistrict:             p.AdminDistrict,
+			ParliamentaryConstituency: p.Constituency,
+			Latitude:                  p.Latitude,
+			Longitude:                 p.Longitude,
+			Eastings:                  p.Eastings,
+			Northings:                 p.Northings,
+		}, nil
+	}
+	if c.offline {
+		return nil, ErrOffline
+	}
+	return c.postcodeClient.LookupContext(ctx, pc)
+}
+
+// ImportONSPD stores geographic data from an ONSPD or NSPL extract; see
+// ofcom.Manager.ImportONSPD.
+func (c *Checker) ImportONSPD(path string) (int, error) {
+	return c.ofcomManager.ImportONSPD(path)
+}
+
+// ImportPremises stores Ofcom's premises coverage; see
+// ofcom.Manager.ImportPremises.
+func (c *Checker) ImportPremises(path string) (int, error) {
+	return c.ofcomManager.ImportPremises(path)
+}
+
+// Aggregate returns coverage statistics for an area; see ofcom.AreaLevels.
+func (c *Checker) Aggregate(level, name string) (*ofcom.AreaSummary, error) {
+	return c.ofcomManager.Aggregate(level, name)
+}
+
+// OutcodeSummary returns coverage statistics for an outward code, e.g.
+// "LS1"; see ofcom.Manager.OutcodeSummary. It needs no geographic data.
+func (c *Checker) OutcodeSummary(outcode string) (*ofcom.AreaSummary, error) {
+	return c.ofcomManager.OutcodeSummary(outcode)
+}
+
+// Within lists the postcodes around a British National Grid position with
+// their coverage; see ofcom.Manager.Within.
+func (c *Checker) Within(eastings, northings int, opts ofcom.WithinOptions) (*ofcom.WithinResult, error) {
+	return c.ofcomManager.Within(eastings, northings, opts)
+}
+
+// Heatmap returns coverage of column averaged over a grid covering box.
+func (c *Checker) Heatmap(box ofcom.BBox, column string, cols, rows int) (*ofcom.Grid, error) {
+	return c.ofcomManager.Heatmap(box, column, cols, rows)
+}
+
+// Stats returns coverage statistics for every area at a level; see
+// ofcom.AreaLevels.
+func (c *Checker) Stats(level string) ([]ofcom.AreaSummary, error) {
+	return c.ofcomManager.Stats(level)
+}
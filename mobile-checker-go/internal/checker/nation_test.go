@@ -0,0 +1,57 @@
+package checker_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+func TestCheckWith_NationNotStored(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/postcodes/EH11AA":
+			w.Write([]byte(`{"status":200,"result":{"postcode":"EH1 1AA","country":"Scotland","latitude":55.95,"longitude":-3.19}}`))
+		case "/postcodes/LS11AB":
+			w.Write([]byte(`{"status":200,"result":{"postcode":"LS1 1AB","country":"England","latitude":53.797,"longitude":-1.548}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"status":404,"error":"Postcode not found"}`))
+		}
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte("postcode,ee_4g\nLS11AA,1\nEH11AA,1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ofcom.NewManager(dir).Setup("2023", ofcom.SetupOptions{Nations: []string{"england"}}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	pc := postcode.NewClient(postcode.WithBaseURL(srv.URL), postcode.WithRetry(postcode.RetryPolicy{MaxAttempts: 1}))
+	c := checker.New(dir, checker.WithPostcodeClient(pc))
+	defer c.Close()
+
+	r := c.CheckWith("EH1 1AA", checker.CheckOptions{})
+	if r.Code != checker.CodeNationNotInstalled || !errors.Is(r.Err, ofcom.ErrNationNotStored) {
+		t.Fatalf("expected NATION_NOT_INSTALLED, got %q (%v)", r.Code, r.Err)
+	}
+	if r.Mobile != nil || !r.Valid {
+		t.Errorf("expected a valid postcode without coverage, got %+v", r)
+	}
+
+	// A postcode of a stored nation is simply not in the dataset.
+	r = c.CheckWith("LS1 1AB", checker.CheckOptions{NoEstimate: true})
+	if r.Code != checker.CodeNotInDataset {
+		t.Errorf("expected NOT_IN_DATASET, got %q (%v)", r.Code, r.Err)
+	}
+	if r.Dataset == nil || r.Dataset.Year != "2023" {
+		t.Errorf("expected the answering dataset to be recorded, got %+v", r.Dataset)
+	}
+}
@@ -0,0 +1,60 @@
+package checker
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+// NearestResult reports whether a postcode has the requested coverage and,
+// if not, the closest postcodes that do.
+type NearestResult struct {
+	Postcode string                 `json:"postcode"`
+	Column   string                 `json:"column"`
+	Coverage string                 `json:"coverage"`
+	Covered  bool                   `json:"covered"`
+	Nearest  []ofcom.NearbyPostcode `json:"nearest,omitempty"`
+}
+
+// Nearest checks pc for the coverage described by opts and, when it is
+// lacking, searches outward for the closest covered postcodes. Searching
+// needs the geographic data stored by Geocode.
+func (c *Checker) Nearest(pc string, opts ofcom.NearestOptions) (*NearestResult, error) {
+	col, err := opts.Column()
+	if err != nil {
+		return nil, err
+	}
+	res := &NearestResult{Postcode: postcode.Normalise(pc), Column: col, Coverage: "N/A"}
+	geo, err := c.lookup(context.Background(), res.Postcode)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := c.ofcomManager.QueryPostcode(res.Postcode)
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := row[col]; ok && v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			res.Coverage = strconv.FormatFloat(f*100, 'f', 0, 64) + "%"
+			res.Covered = f >= ofcom.CoverageThreshold
+		}
+	}
+	if res.Covered {
+		return res, nil
+	}
+
+	found, err := c.ofcomManager.Nearest(geo.Eastings, geo.Northings, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range found {
+		if !strings.EqualFold(n.Postcode, res.Postcode) {
+			res.Nearest = append(res.Nearest, n)
+		}
+	}
+	return res, nil
+}
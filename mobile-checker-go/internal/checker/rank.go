@@ -0,0 +1,62 @@
+package checker
+
+import "sort"
+
+// Ranking places one checked postcode among several candidates, e.g.
+// sites for an office or mast.
+type Ranking struct {
+	Rank     int    `json:"rank"`
+	Postcode string `json:"postcode"`
+	// Site is the result's saved site name, if any; see CheckSites.
+	Site  string `json:"site,omitempty"`
+	Score int    `json:"score"`
+	Grade string `json:"grade,omitempty"`
+	// FourG and FiveG count the operators with 4G and 5G coverage.
+	FourG int `json:"operators_4g"`
+	FiveG int `json:"operators_5g"`
+	// Note explains a postcode without coverage data, which ranks last.
+	Note string `json:"note,omitempty"`
+}
+
+// Rank orders results best first: by coverage score, then by the number
+// of operators with 4G and then 5G. Postcodes with equal coverage share a
+// rank, and those without coverage data come last, unranked (Rank 0).
+func Rank(results []Result) []Ranking {
+	ranked := make([]Ranking, 0, len(results))
+	var missing []Ranking
+	for _, r := range results {
+		if r.Mobile == nil {
+			note := r.Error
+			if note == "" {
+				note = r.Note
+			}
+			missing = append(missing, Ranking{Postcode: r.Postcode, Site: r.Site, Note: note})
+			continue
+		}
+		ranked = append(ranked, Ranking{
+			Postcode: r.Postcode,
+			Site:     r.Site,
+			Score:    r.Mobile.CoverageScore,
+			Grade:    r.Mobile.Grade,
+			FourG:    r.Mobile.Overall.FourGCount,
+			FiveG:    r.Mobile.Overall.FiveGCount,
+		})
+	}
+	better := func(a, b Ranking) bool {
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.FourG != b.FourG {
+			return a.FourG > b.FourG
+		}
+		return a.FiveG > b.FiveG
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return better(ranked[i], ranked[j]) })
+	for i := range ranked {
+		ranked[i].Rank = i + 1
+		if i > 0 && !better(ranked[i-1], ranked[i]) {
+			ranked[i].Rank = ranked[i-1].Rank
+		}
+	}
+	return append(ranked, missing...)
+}
@@ -0,0 +1,39 @@
+package checker
+
+import (
+	"testing"
+
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func TestRank_OrdersByScoreThenOperators(t *testing.T) {
+	result := func(pc string, score, fourG, fiveG int) Result {
+		return Result{Postcode: pc, Mobile: &ofcom.MobileSummary{
+			CoverageScore: score,
+			Overall:       ofcom.OverallCoverage{FourGCount: fourG, FiveGCount: fiveG},
+		}}
+	}
+	ranking := Rank([]Result{
+		result("AA11AA", 60, 3, 1),
+		{Postcode: "HELLO", Error: "Invalid postcode"},
+		result("AA11AB", 80, 2, 0),
+		result("AA11AC", 60, 4, 0),
+		result("AA11AD", 60, 4, 0),
+	})
+
+	want := []struct {
+		pc   string
+		rank int
+	}{{"AA11AB", 1}, {"AA11AC", 2}, {"AA11AD", 2}, {"AA11AA", 4}, {"HELLO", 0}}
+	if len(ranking) != len(want) {
+		t.Fatalf("expected %d rankings, got %+v", len(want), ranking)
+	}
+	for i, w := range want {
+		if ranking[i].Postcode != w.pc || ranking[i].Rank != w.rank {
+			t.Errorf("position %d: expected %s ranked %d, got %+v", i, w.pc, w.rank, ranking[i])
+		}
+	}
+	if ranking[4].Note != "Invalid postcode" {
+		t.Errorf("expected the error as the unranked postcode's note, got %q", ranking[4].Note)
+	}
+}
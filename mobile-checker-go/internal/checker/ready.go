@@ -0,0 +1,48 @@
+package checker
+
+import "time"
+
+// ComponentStatus reports one dependency checked by Ready.
+type ComponentStatus struct {
+	OK        bool   `json:"ok"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Readiness is the result of Ready.
+type Readiness struct {
+	Ready      bool                       `json:"ready"`
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+// Ready reports whether the Checker can answer checks: the Ofcom database
+// ("dataset") must open and hold rows or, while it is missing, the
+// fallback server ("fallback") must be healthy. With WithFixtures, only the
+// fixtures ("fixtures") are checked. If upstream is set and the Checker
+// is not offline, postcodes.io ("postcodes_io") must also answer a lookup.
+func (c *Checker) Ready(upstream bool) Readiness {
+	r := Readiness{Ready: true, Components: map[string]ComponentStatus{}}
+	probe := func(name string, fn func() error) {
+		start := time.Now()
+		err := fn()
+		st := ComponentStatus{OK: err == nil, LatencyMs: time.Since(start).Milliseconds()}
+		if err != nil {
+			st.Error = err.Error()
+			r.Ready = false
+		}
+		r.Components[name] = st
+	}
+	if c.fixtures != nil {
+		probe("fixtures", c.fixtures.ready)
+		return r
+	}
+	if c.useFallback() {
+		probe("fallback", c.fallback.ping)
+	} else {
+		probe("dataset", c.ofcomManager.Ready)
+	}
+	if upstream && !c.offline {
+		probe("postcodes_io", c.postcodeClient.Ping)
+	}
+	return r
+}
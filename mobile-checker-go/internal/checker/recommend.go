@@ -0,0 +1,148 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+// DefaultRecommendTimeout bounds a CommandRecommender run when its Timeout
+// is 0.
+const DefaultRecommendTimeout = 10 * time.Second
+
+// Recommender suggests SIMs or tariffs suited to a postcode's coverage,
+// e.g. from a comparison site's commercial data. Recommenders are added
+// with WithRecommender and consulted after every check that found
+// coverage; their answers appear in Result.Recommendations.
+type Recommender interface {
+	// Name identifies the recommender in results.
+	Name() string
+	// Recommend returns tariffs for the coverage m at the normalised
+	// postcode pc, best first.
+	Recommend(ctx context.Context, pc string, m ofcom.MobileSummary) ([]Tariff, error)
+}
+
+// Tariff is one recommended SIM or tariff. Only Operator and Name are
+// required.
+type Tariff struct {
+	// Operator is the network or MVNO brand, e.g. "Three" or "giffgaff".
+	Operator string `json:"operator" xml:"operator"`
+	Name     string `json:"name" xml:"name"`
+	// MonthlyPrice is in pounds.
+	MonthlyPrice   float64 `json:"monthly_price,omitempty" xml:"monthly_price,omitempty"`
+	Data           string  `json:"data,omitempty" xml:"data,omitempty"` // e.g. "Unlimited" or "30GB"
+	ContractMonths int     `json:"contract_months,omitempty" xml:"contract_months,omitempty"`
+	URL            string  `json:"url,omitempty" xml:"url,omitempty"`
+	// Reason says why the tariff suits the postcode, e.g. "Best 5G here".
+	Reason string `json:"reason,omitempty" xml:"reason,omitempty"`
+}
+
+// Recommendations are one recommender's tariffs for a checked postcode.
+type Recommendations struct {
+	Recommender string   `json:"recommender" xml:"recommender"`
+	Tariffs     []Tariff `json:"tariffs,omitempty" xml:"tariffs>tariff,omitempty"`
+	// Note says why there are no tariffs when the recommender failed.
+	Note string `json:"note,omitempty" xml:"note,omitempty"`
+}
+
+// WithRecommender adds a recommender consulted after every check that
+// found coverage. A failing recommender leaves a note rather than failing
+// the check.
+func WithRecommender(r Recommender) Option {
+	return func(c *Checker) { c.recommenders = append(c.recommenders, r) }
+}
+
+// recommend consults every recommender for result's coverage.
+func (c *Checker) recommend(ctx context.Context, result *Result) {
+	for _, r := range c.recommenders {
+		rec := Recommendations{Recommender: r.Name()}
+		tariffs, err := r.Recommend(ctx, result.Postcode, *result.Mobile)
+		if err != nil {
+			c.logger.Warn("recommender failed", "recommender", rec.Recommender, "postcode", result.Postcode, "err", err)
+			rec.Note = fmt.Sprintf("Recommendations unavailable: %v", err)
+		}
+		rec.Tariffs = tariffs
+		result.Recommendations = append(result.Recommendations, rec)
+	}
+}
+
+// CommandRecommender is a Recommender that runs an external program for
+// every check, so recommendations can come from any language without
+// building a custom binary. The program reads one JSON object on stdin:
+//
+//	{"schema_version": 1, "postcode": "SW1A1AA", "mobile": {...}}
+//
+// where mobile is the check's MobileSummary, and writes its tariffs on
+// stdout:
+//
+//	{"tariffs": [{"operator": "Three", "name": "Unlimited 5G", "monthly_price": 20}]}
+//
+// A non-zero exit status fails the recommendation, with stderr as the
+// reason.
+type CommandRecommender struct {
+	Path string
+	Args []string
+	// Timeout bounds each run; DefaultRecommendTimeout when 0.
+	Timeout time.Duration
+}
+
+// NewCommandRecommender returns a CommandRecommender for command, a
+// program and its arguments separated by spaces. No shell is involved, so
+// quoting is not supported.
+func NewCommandRecommender(command string) (*CommandRecommender, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil, errors.New("recommender command must not be empty")
+	}
+	return &CommandRecommender{Path: fields[0], Args: fields[1:]}, nil
+}
+
+// Name implements Recommender with the program's file name.
+func (r *CommandRecommender) Name() string { return filepath.Base(r.Path) }
+
+// Recommend implements Recommender.
+func (r *CommandRecommender) Recommend(ctx context.Context, pc string, m ofcom.MobileSummary) ([]Tariff, error) {
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = DefaultRecommendTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	in, err := json.Marshal(struct {
+		SchemaVersion int                 `json:"schema_version"`
+		Postcode      string              `json:"postcode"`
+		Mobile        ofcom.MobileSummary `json:"mobile"`
+	}{SchemaVersion, pc, m})
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, r.Path, r.Args...)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("%s timed out after %s", r.Name(), timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %w: %s", r.Name(), err, msg)
+		}
+		return nil, fmt.Errorf("%s: %w", r.Name(), err)
+	}
+	var out struct {
+		Tariffs []Tariff `json:"tariffs"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return nil, fmt.Errorf("%s wrote invalid JSON: %w", r.Name(), err)
+	}
+	return out.Tariffs, nil
+}
@@ -0,0 +1,88 @@
+package checker_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+// staticRecommender suggests one tariff on the first operator, or fails
+// with err.
+type staticRecommender struct{ err error }
+
+func (r staticRecommender) Name() string { return "static" }
+
+func (r staticRecommender) Recommend(ctx context.Context, pc string, m ofcom.MobileSummary) ([]checker.Tariff, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return []checker.Tariff{{Operator: m.Operators[0].Name, Name: "SIM only", MonthlyPrice: 10, Reason: "Covers " + pc}}, nil
+}
+
+func TestWithRecommender_AddsTariffsToChecks(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte("postcode,ee_4g\nLS11AA,0.9\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ofcom.NewManager(dir).Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	c := checker.New(dir, checker.WithOffline(),
+		checker.WithRecommender(staticRecommender{}),
+		checker.WithRecommender(staticRecommender{err: errors.New("price feed down")}))
+
+	r := c.Check("LS1 1AA")
+	if len(r.Recommendations) != 2 {
+		t.Fatalf("expected both recommenders' answers, got %+v", r.Recommendations)
+	}
+	if got := r.Recommendations[0].Tariffs; len(got) != 1 || got[0].Operator != "EE" || got[0].Reason != "Covers LS11AA" {
+		t.Errorf("unexpected tariffs %+v", got)
+	}
+	if note := r.Recommendations[1].Note; !strings.Contains(note, "price feed down") {
+		t.Errorf("expected the failure as a note, got %q", note)
+	}
+	if r := c.Check("not a postcode"); r.Recommendations != nil {
+		t.Errorf("expected no recommendations without coverage, got %+v", r.Recommendations)
+	}
+}
+
+func TestCommandRecommender_ExchangesJSON(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script")
+	}
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.json")
+	script := filepath.Join(dir, "tariffs")
+	body := "#!/bin/sh\ncat > " + input + "\necho '{\"tariffs\":[{\"operator\":\"Three\",\"name\":\"Unlimited\",\"monthly_price\":20}]}'\n"
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := checker.NewCommandRecommender(script + " --region uk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tariffs, err := rec.Recommend(context.Background(), "LS11AA", ofcom.MobileSummary{Postcode: "LS11AA"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tariffs) != 1 || tariffs[0].Operator != "Three" || tariffs[0].MonthlyPrice != 20 {
+		t.Errorf("unexpected tariffs %+v", tariffs)
+	}
+	if in, _ := os.ReadFile(input); !strings.Contains(string(in), `"postcode":"LS11AA"`) || !strings.Contains(string(in), `"mobile":{`) {
+		t.Errorf("unexpected input %s", in)
+	}
+
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho 'no tariff data' >&2\nexit 3\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rec.Recommend(context.Background(), "LS11AA", ofcom.MobileSummary{}); err == nil || !strings.Contains(err.Error(), "no tariff data") {
+		t.Errorf("expected the failure with stderr, got %v", err)
+	}
+}
@@ -0,0 +1,220 @@
+package checker
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/osrm"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+// RouteOptions controls how a route is sampled.
+type RouteOptions struct {
+	Samples int    // number of points to sample along the route (including both ends)
+	OSRMURL string // optional OSRM server; straight line when empty
+	Workers int    // concurrent point lookups; 0 means DefaultWorkers
+}
+
+// RoutePoint is a sampled point along a route and the coverage at its nearest postcode.
+type RoutePoint struct {
+	DistanceKm float64              `json:"distance_km"`
+	Latitude   float64              `json:"latitude"`
+	Longitude  float64              `json:"longitude"`
+	Postcode   string               `json:"postcode,omitempty"`
+	Mobile     *ofcom.MobileSummary `json:"mobile,omitempty"`
+	Note       string               `json:"note,omitempty"`
+}
+
+// CoverageGap is a stretch of the route where an operator lacks a technology.
+type CoverageGap struct {
+	Operator     string  `json:"operator"`
+	Technology   string  `json:"technology"`
+	StartKm      float64 `json:"start_km"`
+	EndKm        float64 `json:"end_km"`
+	FromPostcode string  `json:"from_postcode"`
+	ToPostcode   string  `json:"to_postcode"`
+}
+
+// RouteResult is the output of a route coverage check.
+type RouteResult struct {
+	From       string        `json:"from"`
+	To         string        `json:"to"`
+	Method     string        `json:"method"`
+	DistanceKm float64       `json:"distance_km"`
+	Points     []RoutePoint  `json:"points"`
+	Gaps       []CoverageGap `json:"gaps"`
+}
+
+// Route samples points between two postcodes and reports where each
+// operator loses 4G or 5G coverage along the way.
+func (c *Checker) Route(from, to string, opts RouteOptions) (*RouteResult, error) {
+	if opts.Samples < 2 {
+		opts.Samples = 20
+	}
+
+	start, err := c.postcodeClient.Lookup(from)
+	if err != nil {
+		return nil, fmt.Errorf("start postcode: %w", err)
+	}
+	end, err := c.postcodeClient.Lookup(to)
+	if err != nil {
+		return nil, fmt.Errorf("end postcode: %w", err)
+	}
+
+	path := []osrm.Point{
+		{Latitude: start.Latitude, Longitude: start.Longitude},
+		{Latitude: end.Latitude, Longitude: end.Longitude},
+	}
+	method := "straight-line"
+	if opts.OSRMURL != "" {
+		path, err = osrm.NewClient(opts.OSRMURL).Route(path[0], path[1])
+		if err != nil {
+			return nil, fmt.Errorf("routing failed: %w", err)
+		}
+		method = "osrm"
+	}
+
+	points, total := samplePath(path, opts.Samples)
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range indexes {
+				c.checkPoint(&points[idx])
+			}
+		}()
+	}
+	for i := range points {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return &RouteResult{
+		From:       start.Postcode,
+		To:         end.Postcode,
+		Method:     method,
+		DistanceKm: total,
+		Points:     points,
+		Gaps:       findGaps(points),
+	}, nil
+}
+
+// checkPoint reverse-geocodes a sample point and attaches its coverage.
+func (c *Checker) checkPoint(pt *RoutePoint) {
+	geo, err := c.postcodeClient.Reverse(pt.Latitude, pt.Longitude)
+	if err != nil {
+		pt.Note = err.Error()
+		return
+	}
+	pt.Postcode = postcode.Normalise(geo.Postcode)
+
+	row, err := c.ofcomManager.QueryPostcode(pt.Postcode)
+	if err != nil {
+		pt.Note = fmt.Sprintf("Mobile data unavailable: %v", err)
+		return
+	}
+	if row == nil {
+		pt.Note = "Postcode not found in Ofcom mobile dataset."
+		return
+	}
+	summary := ofcom.Interpret(row)
+	pt.Mobile = &summary
+}
+
+// samplePath returns n points spaced evenly by distance along path, and the
+// total path length in kilometres.
+func samplePath(path []osrm.Point, n int) ([]RoutePoint, float64) {
+	cum := make([]float64, len(path))
+	for i := 1; i < len(path); i++ {
+		cum[i] = cum[i-1] + haversineKm(path[i-1], path[i])
+	}
+	total := cum[len(cum)-1]
+
+	points := make([]RoutePoint, n)
+	seg := 1
+	for i := 0; i < n; i++ {
+		d := total * float64(i) / float64(n-1)
+		for seg < len(path)-1 && cum[seg] < d {
+			seg++
+		}
+		a, b := path[seg-1], path[seg]
+		frac := 0.0
+		if span := cum[seg] - cum[seg-1]; span > 0 {
+			frac = (d - cum[seg-1]) / span
+		}
+		points[i] = RoutePoint{
+			DistanceKm: math.Round(d*100) / 100,
+			Latitude:   a.Latitude + (b.Latitude-a.Latitude)*frac,
+			Longitude:  a.Longitude + (b.Longitude-a.Longitude)*frac,
+		}
+	}
+	return points, math.Round(total*100) / 100
+}
+
+// findGaps collects contiguous runs of sample points where an operator
+// lacks 4G or 5G. Points without coverage data do not break a run.
+func findGaps(points []RoutePoint) []CoverageGap {
+	var gaps []CoverageGap
+	techs := []struct {
+		name string
+		has  func(ofcom.OperatorCoverage) bool
+	}{
+		{"4G", func(op ofcom.OperatorCoverage) bool { return op.HasFourG }},
+		{"5G", func(op ofcom.OperatorCoverage) bool { return op.HasFiveG }},
+	}
+
+	for _, name := range []string{"EE", "O2", "Three", "Vodafone"} {
+		for _, tech := range techs {
+			var open *CoverageGap
+			for _, pt := range points {
+				if pt.Mobile == nil {
+					continue
+				}
+				var covered bool
+				for _, op := range pt.Mobile.Operators {
+					if op.Name == name {
+						covered = tech.has(op)
+					}
+				}
+				switch {
+				case !covered && open == nil:
+					open = &CoverageGap{
+						Operator: name, Technology: tech.name,
+						StartKm: pt.DistanceKm, EndKm: pt.DistanceKm,
+						FromPostcode: pt.Postcode, ToPostcode: pt.Postcode,
+					}
+				case !covered:
+					open.EndKm = pt.DistanceKm
+					open.ToPostcode = pt.Postcode
+				case open != nil:
+					gaps = append(gaps, *open)
+					open = nil
+				}
+			}
+			if open != nil {
+				gaps = append(gaps, *open)
+			}
+		}
+	}
+	return gaps
+}
+
+func haversineKm(a, b osrm.Point) float64 {
+	const earthRadiusKm = 6371.0
+	lat1 := a.Latitude * math.Pi / 180
+	lat2 := b.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
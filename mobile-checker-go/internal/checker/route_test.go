@@ -0,0 +1,81 @@
+package checker_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/osrm"
+)
+
+func TestSamplePath_SpacesPointsEvenlyByDistance(t *testing.T) {
+	// Two legs due north along the meridian: 0.1° then 0.3° of latitude,
+	// so a quarter of the way along is the end of the first leg.
+	path := []osrm.Point{{Latitude: 51.0}, {Latitude: 51.1}, {Latitude: 51.4}}
+	points, total := checker.SamplePath(path, 5)
+
+	if len(points) != 5 {
+		t.Fatalf("expected 5 points, got %d", len(points))
+	}
+	if math.Abs(total-44.48) > 0.05 {
+		t.Errorf("expected about 44.48 km, got %v", total)
+	}
+	want := []float64{51.0, 51.1, 51.2, 51.3, 51.4}
+	for i, pt := range points {
+		if math.Abs(pt.Latitude-want[i]) > 1e-9 || pt.Longitude != 0 {
+			t.Errorf("point %d at %v,%v, want %v,0", i, pt.Latitude, pt.Longitude, want[i])
+		}
+	}
+	if points[0].DistanceKm != 0 || points[4].DistanceKm != total {
+		t.Errorf("expected distances from 0 to %v, got %v to %v", total, points[0].DistanceKm, points[4].DistanceKm)
+	}
+}
+
+func TestSamplePath_SinglePointRoute(t *testing.T) {
+	path := []osrm.Point{{Latitude: 51.5, Longitude: -0.1}, {Latitude: 51.5, Longitude: -0.1}}
+	points, total := checker.SamplePath(path, 3)
+	if total != 0 {
+		t.Errorf("expected zero length, got %v", total)
+	}
+	for i, pt := range points {
+		if pt.Latitude != 51.5 || pt.Longitude != -0.1 {
+			t.Errorf("point %d moved to %v,%v", i, pt.Latitude, pt.Longitude)
+		}
+	}
+}
+
+func TestFindGaps(t *testing.T) {
+	point := func(km float64, pc string, eeFourG bool) checker.RoutePoint {
+		return checker.RoutePoint{DistanceKm: km, Postcode: pc, Mobile: &ofcom.MobileSummary{
+			Operators: []ofcom.OperatorCoverage{
+				{Name: "EE", HasFourG: eeFourG, HasFiveG: true},
+				{Name: "O2", HasFourG: true, HasFiveG: true},
+				{Name: "Three", HasFourG: true, HasFiveG: true},
+				{Name: "Vodafone", HasFourG: true, HasFiveG: true},
+			},
+		}}
+	}
+	points := []checker.RoutePoint{
+		point(0, "AA11AA", true),
+		point(1, "AA11AB", false),
+		{DistanceKm: 2, Note: "lookup failed"}, // no data: doesn't end the gap
+		point(3, "AA11AD", false),
+		point(4, "AA11AE", true),
+		point(5, "AA11AF", false),
+	}
+
+	gaps := checker.FindGaps(points)
+	want := []checker.CoverageGap{
+		{Operator: "EE", Technology: "4G", StartKm: 1, EndKm: 3, FromPostcode: "AA11AB", ToPostcode: "AA11AD"},
+		{Operator: "EE", Technology: "4G", StartKm: 5, EndKm: 5, FromPostcode: "AA11AF", ToPostcode: "AA11AF"},
+	}
+	if len(gaps) != len(want) {
+		t.Fatalf("expected %d gaps, got %+v", len(want), gaps)
+	}
+	for i := range want {
+		if gaps[i] != want[i] {
+			t.Errorf("gap %d: got %+v, want %+v", i, gaps[i], want[i])
+		}
+	}
+}
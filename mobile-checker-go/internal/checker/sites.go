@@ -0,0 +1,22 @@
+package checker
+
+import (
+	"context"
+
+	"github.com/yourusername/mobile-checker/internal/sites"
+)
+
+// CheckSites checks each saved site's postcode as CheckBulk does and
+// returns the results in the same order, each labelled with its site's
+// name.
+func (c *Checker) CheckSites(ctx context.Context, list []sites.Site, opts CheckOptions, bulk BulkOptions) []Result {
+	postcodes := make([]string, len(list))
+	for i, s := range list {
+		postcodes[i] = s.Postcode
+	}
+	results := c.CheckBulk(ctx, postcodes, opts, bulk)
+	for i := range results {
+		results[i].Site = list[i].Name
+	}
+	return results
+}
@@ -0,0 +1,89 @@
+package checker
+
+import (
+	"fmt"
+
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+// CoverageSource is a dataset that can report mobile coverage for a
+// postcode. The Ofcom Connected Nations CSV is the built-in primary source;
+// others can be added with WithSource and appear in Result.Sources.
+type CoverageSource interface {
+	// Name identifies the source in results, e.g. "ofcom".
+	Name() string
+	// Setup prepares the source's data. Sources with nothing to prepare
+	// return nil.
+	Setup(year string, opts ofcom.SetupOptions) error
+	// Query returns the raw data for a normalised postcode, or a nil row if
+	// the source has no data for it.
+	Query(postcode string) (map[string]string, error)
+	// Interpret turns a row returned by Query into a coverage summary.
+	Interpret(row map[string]string, opts CheckOptions) ofcom.MobileSummary
+}
+
+// SourceResult is the coverage reported by one additional source.
+type SourceResult struct {
+	Source string               `json:"source" xml:"source"`
+	Mobile *ofcom.MobileSummary `json:"mobile,omitempty" xml:"mobile,omitempty"`
+	Note   string               `json:"note,omitempty" xml:"note,omitempty"`
+}
+
+// WithSource adds a coverage source consulted on every check after the
+// primary Ofcom dataset.
+func WithSource(src CoverageSource) Option {
+	return func(c *Checker) { c.sources = append(c.sources, src) }
+}
+
+// WithPrimarySource replaces the local Ofcom dataset as the source checks
+// are answered from, e.g. with Ofcom's coverage API for users without a
+// local database. Estimates, ranks and premises counts still come from the
+// local database when there is one.
+func WithPrimarySource(src CoverageSource) Option {
+	return func(c *Checker) { c.customPrimary = src }
+}
+
+// ofcomSource adapts an ofcom.Manager to CoverageSource.
+type ofcomSource struct {
+	m *ofcom.Manager
+}
+
+// OfcomSource returns the Ofcom Connected Nations dataset managed by m as a
+// CoverageSource.
+func OfcomSource(m *ofcom.Manager) CoverageSource {
+	return ofcomSource{m: m}
+}
+
+func (s ofcomSource) Name() string { return "ofcom" }
+
+func (s ofcomSource) Setup(year string, opts ofcom.SetupOptions) error {
+	return s.m.Setup(year, opts)
+}
+
+func (s ofcomSource) Query(pc string) (map[string]string, error) {
+	return s.m.QueryPostcode(pc)
+}
+
+func (s ofcomSource) Interpret(row map[string]string, opts CheckOptions) ofcom.MobileSummary {
+	return ofcom.InterpretWith(row, ofcom.InterpretOptions{Indoor: opts.Indoor, AddIndoor: opts.AddIndoor, Operators: opts.Operators, Brands: opts.Brands, Weights: opts.Weights, Threshold: opts.Threshold})
+}
+
+// querySources consults every additional source for pc.
+func (c *Checker) querySources(pc string, opts CheckOptions) []SourceResult {
+	var results []SourceResult
+	for _, src := range c.sources {
+		sr := SourceResult{Source: src.Name()}
+		row, err := src.Query(pc)
+		switch {
+		case err != nil:
+			sr.Note = fmt.Sprintf("Mobile data unavailable: %v", err)
+		case row == nil:
+			sr.Note = "Postcode not found in " + src.Name() + " data."
+		default:
+			summary := src.Interpret(row, opts)
+			sr.Mobile = &summary
+		}
+		results = append(results, sr)
+	}
+	return results
+}
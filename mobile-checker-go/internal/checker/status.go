@@ -0,0 +1,93 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ItemStatus is the coarse outcome of one check in a bulk run, so callers
+// can count outcomes without interpreting every ErrorCode.
+type ItemStatus string
+
+const (
+	// StatusOK means coverage was found, possibly with a note.
+	StatusOK ItemStatus = "ok"
+	// StatusInvalid means the input is not shaped like a UK postcode.
+	StatusInvalid ItemStatus = "invalid"
+	// StatusNotFound means the postcode is unknown to postcodes.io, has
+	// been retired or is not in the Ofcom dataset.
+	StatusNotFound ItemStatus = "not_found"
+	// StatusError covers everything else: a missing dataset, an upstream
+	// failure, a timeout or a check skipped by FailFast.
+	StatusError ItemStatus = "error"
+)
+
+// StatusOf returns r's ItemStatus.
+func StatusOf(r Result) ItemStatus {
+	switch {
+	case r.Mobile != nil:
+		return StatusOK
+	case r.Code == CodeInvalidPostcode:
+		return StatusInvalid
+	case r.Code == CodePostcodeNotFound, r.Code == CodePostcodeTerminated, r.Code == CodeNotInDataset:
+		return StatusNotFound
+	case r.Error == "" && r.Code == "":
+		return StatusOK
+	}
+	return StatusError
+}
+
+// Summary counts the results of a bulk run by ItemStatus.
+type Summary struct {
+	Total    int `json:"total" xml:"total"`
+	OK       int `json:"ok" xml:"ok"`
+	Invalid  int `json:"invalid" xml:"invalid"`
+	NotFound int `json:"not_found" xml:"not_found"`
+	Error    int `json:"error" xml:"error"`
+}
+
+// Summarise counts results by ItemStatus.
+func Summarise(results []Result) *Summary {
+	s := &Summary{}
+	for _, r := range results {
+		s.Add(r)
+	}
+	return s
+}
+
+// Add counts r, using its Status when set.
+func (s *Summary) Add(r Result) {
+	st := r.Status
+	if st == "" {
+		st = StatusOf(r)
+	}
+	s.Total++
+	switch st {
+	case StatusOK:
+		s.OK++
+	case StatusInvalid:
+		s.Invalid++
+	case StatusNotFound:
+		s.NotFound++
+	default:
+		s.Error++
+	}
+}
+
+// String describes s for people, e.g. "182 ok, 3 invalid, 15 not found",
+// leaving out outcomes that did not occur.
+func (s *Summary) String() string {
+	var parts []string
+	for _, p := range []struct {
+		n    int
+		what string
+	}{{s.OK, "ok"}, {s.Invalid, "invalid"}, {s.NotFound, "not found"}, {s.Error, "failed"}} {
+		if p.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", p.n, p.what))
+		}
+	}
+	if len(parts) == 0 {
+		return "nothing checked"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,40 @@
+package checker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func TestSummary_CountsItemStatuses(t *testing.T) {
+	results := []Result{
+		{Postcode: "LS11AA", Mobile: &ofcom.MobileSummary{}},
+		{Postcode: "LS11AB", Mobile: &ofcom.MobileSummary{}, Note: "Geographic data unavailable", Code: CodeUpstreamUnavailable},
+		{Postcode: "NOPE", Error: "Invalid postcode", Code: CodeInvalidPostcode},
+		{Postcode: "LS11AZ", Error: "not in dataset", Code: CodeNotInDataset},
+		{Postcode: "SW1A0AA", Error: "retired", Code: CodePostcodeTerminated},
+		{Postcode: "EC1A1BB", Error: "timed out", Code: CodeUpstreamTimeout},
+	}
+	want := []ItemStatus{StatusOK, StatusOK, StatusInvalid, StatusNotFound, StatusNotFound, StatusError}
+	for i, r := range results {
+		if got := StatusOf(r); got != want[i] {
+			t.Errorf("%s: expected %s, got %s", r.Postcode, want[i], got)
+		}
+	}
+	s := Summarise(results)
+	if *s != (Summary{Total: 6, OK: 2, Invalid: 1, NotFound: 2, Error: 1}) {
+		t.Errorf("unexpected summary %+v", s)
+	}
+	if got := s.String(); got != "2 ok, 1 invalid, 2 not found, 1 failed" {
+		t.Errorf("unexpected description %q", got)
+	}
+}
+
+func TestCheckBulk_SetsItemStatus(t *testing.T) {
+	c := New(t.TempDir())
+	results := c.CheckBulk(context.Background(), []string{"bad one", "x", "y"}, CheckOptions{}, BulkOptions{Workers: 1, FailFast: true})
+	if results[0].Status != StatusInvalid || results[2].Status != StatusError {
+		t.Errorf("expected invalid then a skipped error, got %q and %q", results[0].Status, results[2].Status)
+	}
+}
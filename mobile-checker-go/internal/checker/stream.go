@@ -0,0 +1,214 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+// DefaultWorkers is the number of concurrent checks used by CheckMultiple
+// and when BulkOptions.Workers is 0.
+const DefaultWorkers = 8
+
+// ErrSkipped is set on Results for postcodes that were never checked
+// because BulkOptions.FailFast stopped the run.
+var ErrSkipped = errors.New("not checked: an earlier check failed")
+
+// BulkOptions controls how many postcodes are checked at once.
+type BulkOptions struct {
+	// Workers bounds the number of concurrent checks; 0 means
+	// DefaultWorkers.
+	Workers int
+	// Timeout bounds each check; 0 means no limit. A check that runs over
+	// is reported with CodeUpstreamTimeout and its requests to
+	// postcodes.io are cancelled.
+	Timeout time.Duration
+	// FailFast stops starting new checks once one fails with an error
+	// (not a note); the postcodes left unchecked get ErrSkipped.
+	FailFast bool
+}
+
+// Indexed pairs a Result with the position of its postcode in the input.
+type Indexed struct {
+	Index int `json:"index"`
+	Result
+}
+
+// Stream checks postcodes using at most workers concurrent checks and
+// delivers results in completion order. The channel is closed once every
+// postcode is checked or ctx is cancelled.
+func (c *Checker) Stream(ctx context.Context, postcodes []string, workers int) <-chan Indexed {
+	return c.StreamWith(ctx, postcodes, workers, CheckOptions{})
+}
+
+// StreamWith is Stream with check options applied to every postcode.
+func (c *Checker) StreamWith(ctx context.Context, postcodes []string, workers int, opts CheckOptions) <-chan Indexed {
+	return c.StreamBulk(ctx, postcodes, opts, BulkOptions{Workers: workers})
+}
+
+// StreamBulk is StreamWith with the concurrency, timeout and fail-fast
+// behaviour set by bulk. Each result carries its Status. Postcodes skipped
+// by FailFast are not delivered.
+func (c *Checker) StreamBulk(ctx context.Context, postcodes []string, opts CheckOptions, bulk BulkOptions) <-chan Indexed {
+	c = c.prefetch(postcodes, opts)
+	workers := bulk.Workers
+	if workers < 1 {
+		workers = DefaultWorkers
+	}
+	jobs := make(chan int)
+	out := make(chan Indexed)
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				res := Indexed{Index: i, Result: c.checkWithin(ctx, postcodes[i], opts, bulk.Timeout)}
+				res.Status = StatusOf(res.Result)
+				if bulk.FailFast && res.Error != "" {
+					stopOnce.Do(func() { close(stop) })
+				}
+				select {
+				case out <- res:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for i := range postcodes {
+			select {
+			case jobs <- i:
+			case <-stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// prefetch returns a copy of c whose Ofcom lookups for postcodes are
+// answered from one batched ofcom.Manager.QueryPostcodes call rather than a
+// query per check. Checks of another year, or a batch that cannot be read,
+// are left to query as they go and report any error themselves.
+func (c *Checker) prefetch(postcodes []string, opts CheckOptions) *Checker {
+	if opts.Year != "" || c.fixtures != nil || c.useFallback() {
+		return c
+	}
+	rows, err := c.ofcomManager.QueryPostcodes(postcodes)
+	if err != nil {
+		c.logger.Debug("bulk prefetch failed", "postcodes", len(postcodes), "err", err)
+		return c
+	}
+	batch := make(map[string]map[string]string, len(postcodes))
+	for i, pc := range postcodes {
+		batch[postcode.Normalise(pc)] = rows[i]
+	}
+	cp := *c
+	cp.primary = prefetched{CoverageSource: c.primary, rows: batch}
+	return &cp
+}
+
+// prefetched answers Query from rows fetched in advance, falling back to
+// the wrapped source for postcodes outside the batch.
+type prefetched struct {
+	CoverageSource
+	rows map[string]map[string]string
+}
+
+func (p prefetched) Query(pc string) (map[string]string, error) {
+	if row, ok := p.rows[pc]; ok {
+		return row, nil
+	}
+	return p.CoverageSource.Query(pc)
+}
+
+// CheckBulk checks postcodes as StreamBulk does and returns the results in
+// input order.
+func (c *Checker) CheckBulk(ctx context.Context, postcodes []string, opts CheckOptions, bulk BulkOptions) []Result {
+	results := make([]Result, 0, len(postcodes))
+	c.CheckBulkFunc(ctx, postcodes, opts, bulk, func(r Result) error {
+		results = append(results, r)
+		return nil
+	})
+	return results
+}
+
+// CheckBulkFunc checks postcodes as CheckBulk does, calling fn with each
+// result in input order as soon as it and those before it are ready, so
+// output can start before the last check finishes. If fn returns an
+// error, the remaining checks are cancelled and the error is returned.
+func (c *Checker) CheckBulkFunc(ctx context.Context, postcodes []string, opts CheckOptions, bulk BulkOptions, fn func(Result) error) error {
+	sctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	pending := map[int]Result{}
+	next := 0
+	var fnErr error
+	for res := range c.StreamBulk(sctx, postcodes, opts, bulk) {
+		if fnErr != nil {
+			continue // drain until the workers stop
+		}
+		pending[res.Index] = res.Result
+		for r, ok := pending[next]; ok; r, ok = pending[next] {
+			delete(pending, next)
+			next++
+			if fnErr = fn(r); fnErr != nil {
+				cancel()
+				break
+			}
+		}
+	}
+	if fnErr != nil {
+		return fnErr
+	}
+	for ; next < len(postcodes); next++ {
+		r, ok := pending[next]
+		if !ok {
+			err := ErrSkipped
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			r = Result{Postcode: postcode.Normalise(postcodes[next]), Error: err.Error(), Err: err, Code: CodeOf(err), Status: StatusError}
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkWithin runs CheckContext, giving up after timeout if it is
+// positive. The abandoned check's context is cancelled, so it stops
+// waiting on the network and exits once any database query returns.
+func (c *Checker) checkWithin(ctx context.Context, pc string, opts CheckOptions, timeout time.Duration) Result {
+	if timeout <= 0 {
+		return c.CheckContext(ctx, pc, opts)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ch := make(chan Result, 1)
+	go func() { ch <- c.CheckContext(ctx, pc, opts) }()
+	select {
+	case res := <-ch:
+		return res
+	case <-ctx.Done():
+		err := fmt.Errorf("check timed out after %s: %w", timeout, context.DeadlineExceeded)
+		return Result{Postcode: postcode.Normalise(pc), Error: err.Error(), Err: err, Code: CodeOf(err)}
+	}
+}
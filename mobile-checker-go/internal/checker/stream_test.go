@@ -0,0 +1,129 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+func TestCheckBulk_FailFastSkipsRemaining(t *testing.T) {
+	c := New(t.TempDir())
+	pcs := []string{"not-a-postcode", "also bad", "x", "y", "z", "nope", "still bad", "last"}
+	results := c.CheckBulk(context.Background(), pcs, CheckOptions{}, BulkOptions{Workers: 1, FailFast: true})
+
+	if len(results) != len(pcs) {
+		t.Fatalf("expected %d results, got %d", len(pcs), len(results))
+	}
+	if results[0].Code != CodeInvalidPostcode {
+		t.Errorf("expected the first check to run, got %+v", results[0])
+	}
+	if last := results[len(results)-1]; last.Code != CodeSkipped || last.Postcode != "LAST" {
+		t.Errorf("expected the last postcode to be skipped, got %+v", last)
+	}
+}
+
+func TestCheckBulk_ChecksEveryPostcodeByDefault(t *testing.T) {
+	c := New(t.TempDir())
+	pcs := []string{"bad one", "bad two", "bad three"}
+	for i, r := range c.CheckBulk(context.Background(), pcs, CheckOptions{}, BulkOptions{Workers: 2}) {
+		if r.Code != CodeInvalidPostcode {
+			t.Errorf("result %d: expected INVALID_POSTCODE, got %q", i, r.Code)
+		}
+	}
+}
+
+func TestCheckBulkFunc_DeliversInOrderAndStopsOnError(t *testing.T) {
+	c := New(t.TempDir())
+	pcs := []string{"bad one", "bad two", "bad three", "bad four"}
+	stop := errors.New("stop")
+	var got []string
+	err := c.CheckBulkFunc(context.Background(), pcs, CheckOptions{}, BulkOptions{Workers: 4}, func(r Result) error {
+		got = append(got, r.Postcode)
+		if len(got) == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Fatalf("expected fn's error, got %v", err)
+	}
+	if len(got) != 2 || got[0] != "BADONE" || got[1] != "BADTWO" {
+		t.Errorf("expected the first two postcodes in order, got %v", got)
+	}
+}
+
+func TestPrefetch_AnswersFromOneBatch(t *testing.T) {
+	build := func(value string) string {
+		dir := t.TempDir()
+		csv := "postcode,ee_4g\nLS11AA," + value + "\n"
+		if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := ofcom.NewManager(dir).Setup("2023", ofcom.SetupOptions{}); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+		return dir
+	}
+	dir, next := build("0.25"), build("0.75")
+	c := New(dir)
+	bc := c.prefetch([]string{"ls1 1aa", "LS11AB"}, CheckOptions{})
+	if bc == c {
+		t.Fatal("expected a prefetching copy")
+	}
+	// Replaced after the batch was read: the copy keeps answering from it.
+	if err := os.Rename(filepath.Join(next, "mobile.db"), filepath.Join(dir, "mobile.db")); err != nil {
+		t.Fatal(err)
+	}
+
+	if row, err := bc.primary.Query("LS11AA"); err != nil || row["ee_4g"] != "0.25" {
+		t.Errorf("expected the prefetched row, got %v (err %v)", row, err)
+	}
+	if row, err := bc.primary.Query("LS11AB"); err != nil || row != nil {
+		t.Errorf("expected a prefetched miss, got %v (err %v)", row, err)
+	}
+	if row, err := bc.primary.Query("LS11AD"); err != nil || row != nil {
+		t.Errorf("expected postcodes outside the batch to be queried, got %v (err %v)", row, err)
+	}
+	if row, err := c.primary.Query("LS11AA"); err != nil || row["ee_4g"] != "0.75" {
+		t.Errorf("expected the original checker to see the rebuilt data, got %v (err %v)", row, err)
+	}
+
+	if c.prefetch([]string{"LS11AA"}, CheckOptions{Year: "2022"}) != c {
+		t.Error("expected checks of another year not to be prefetched")
+	}
+	if missing := New(t.TempDir()); missing.prefetch([]string{"LS11AA"}, CheckOptions{}) != missing {
+		t.Error("expected no prefetch without a dataset")
+	}
+}
+
+func TestCheckBulk_TimeoutCancelsLookup(t *testing.T) {
+	cancelled := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			close(cancelled)
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	pc := postcode.NewClient(postcode.WithBaseURL(srv.URL), postcode.WithRetry(postcode.RetryPolicy{MaxAttempts: 1}))
+	c := New(t.TempDir(), WithPostcodeClient(pc))
+	results := c.CheckBulk(context.Background(), []string{"LS11AA"}, CheckOptions{}, BulkOptions{Timeout: 50 * time.Millisecond})
+	if results[0].Code != CodeUpstreamTimeout {
+		t.Errorf("expected UPSTREAM_TIMEOUT, got %+v", results[0])
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the timed-out lookup to be cancelled")
+	}
+}
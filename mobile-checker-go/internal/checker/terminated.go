@@ -0,0 +1,52 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTerminated is set on a Result whose postcode has been retired; see
+// Result.Terminated.
+var ErrTerminated = errors.New("postcode has been terminated")
+
+// Termination describes a retired postcode.
+type Termination struct {
+	Year  int `json:"year" xml:"year"`
+	Month int `json:"month,omitempty" xml:"month,omitempty"`
+	// Nearest is the check for the closest postcode still in use, when one
+	// could be found.
+	Nearest *Result `json:"nearest,omitempty" xml:"nearest,omitempty"`
+}
+
+// checkTerminated completes a check for a postcode postcodes.io did not
+// recognise, reporting its termination date and the coverage of the nearest
+// active postcode if it was retired. Otherwise the lookup failure stands.
+func (c *Checker) checkTerminated(ctx context.Context, result Result, lookupErr error, opts CheckOptions) Result {
+	t, err := c.postcodeClient.TerminatedContext(ctx, result.Postcode)
+	if err != nil {
+		result.Error = fmt.Sprintf("Postcode lookup failed: %v", lookupErr)
+		result.Err = lookupErr
+		return result
+	}
+
+	when := fmt.Sprint(t.YearTerminated)
+	if t.MonthTerminated >= 1 && t.MonthTerminated <= 12 {
+		when = time.Month(t.MonthTerminated).String() + " " + when
+	}
+	result.Terminated = &Termination{Year: t.YearTerminated, Month: t.MonthTerminated}
+	result.Error = "Postcode was terminated in " + when + "."
+	result.Err = fmt.Errorf("postcode %q: %w", result.Postcode, ErrTerminated)
+
+	geo, err := c.postcodeClient.ReverseContext(ctx, t.Latitude, t.Longitude)
+	if err != nil {
+		c.logger.Debug("no active postcode near terminated postcode", "postcode", result.Postcode, "err", err)
+		return result
+	}
+	nearest := c.check(ctx, geo.Postcode, opts) // not recorded in history on its own
+	nearest.Code = CodeOf(nearest.Err)
+	result.Terminated.Nearest = &nearest
+	result.Error += " Nearest active postcode: " + nearest.Postcode + "."
+	return result
+}
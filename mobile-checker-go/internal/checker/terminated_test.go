@@ -0,0 +1,71 @@
+package checker_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+func TestCheckWith_Terminated(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/postcodes/LS11AA":
+			w.Write([]byte(`{"status":200,"result":{"postcode":"LS1 1AA","country":"England","latitude":53.797,"longitude":-1.548}}`))
+		case "/terminated_postcodes/LS19ZZ":
+			w.Write([]byte(`{"status":200,"result":{"postcode":"LS1 9ZZ","year_terminated":2009,"month_terminated":3,"latitude":53.797,"longitude":-1.548}}`))
+		case "/postcodes": // reverse geocoding: only Leeds has postcodes
+			if r.URL.Query().Get("lat") != "53.797000" {
+				w.Write([]byte(`{"status":200,"result":null}`))
+				return
+			}
+			w.Write([]byte(`{"status":200,"result":[{"postcode":"LS1 1AA"}]}`))
+		case "/terminated_postcodes/LS19ZY":
+			w.Write([]byte(`{"status":200,"result":{"postcode":"LS1 9ZY","year_terminated":1996,"latitude":57.1,"longitude":-2.2}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"status":404,"error":"Postcode not found"}`))
+		}
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte("postcode,ee_4g\nLS11AA,1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ofcom.NewManager(dir).Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	pc := postcode.NewClient(postcode.WithBaseURL(srv.URL), postcode.WithRetry(postcode.RetryPolicy{MaxAttempts: 1}))
+	c := checker.New(dir, checker.WithPostcodeClient(pc))
+
+	r := c.CheckWith("LS1 9ZZ", checker.CheckOptions{})
+	if r.Code != checker.CodePostcodeTerminated || !errors.Is(r.Err, checker.ErrTerminated) {
+		t.Fatalf("expected a terminated postcode, got %q (%v)", r.Code, r.Err)
+	}
+	if want := "Postcode was terminated in March 2009. Nearest active postcode: LS11AA."; r.Error != want {
+		t.Errorf("expected %q, got %q", want, r.Error)
+	}
+	if term := r.Terminated; term == nil || term.Year != 2009 || term.Month != 3 || term.Nearest == nil ||
+		term.Nearest.Mobile == nil || term.Nearest.Mobile.Overall.FourGCount != 1 {
+		t.Errorf("expected the nearest postcode's coverage, got %+v", r.Terminated)
+	}
+
+	// No active postcode nearby: the termination is still reported.
+	r = c.CheckWith("LS1 9ZY", checker.CheckOptions{})
+	if r.Terminated == nil || r.Terminated.Nearest != nil || r.Error != "Postcode was terminated in 1996." {
+		t.Errorf("expected a termination without a nearest postcode, got %+v", r)
+	}
+
+	// Neither current nor terminated: the original lookup error stands.
+	r = c.CheckWith("LS1 9ZX", checker.CheckOptions{NoEstimate: true})
+	if r.Code != checker.CodePostcodeNotFound || r.Terminated != nil || !errors.Is(r.Err, postcode.ErrNotFound) {
+		t.Errorf("expected POSTCODE_NOT_FOUND, got %q (%v)", r.Code, r.Err)
+	}
+}
@@ -0,0 +1,129 @@
+// Package config lets every command-line flag of the CLI and server also be
+// set from a MOBILE_CHECKER_* environment variable or a YAML config file, so
+// containers can be configured without wrapper scripts.
+//
+// Precedence, highest first: command-line flag, environment variable, config
+// file, built-in default.
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix starts every environment variable read by Apply.
+const EnvPrefix = "MOBILE_CHECKER_"
+
+// FileEnv names the environment variable that points at a config file when
+// --config is not given.
+const FileEnv = EnvPrefix + "CONFIG"
+
+// EnvName returns the environment variable for a flag, e.g. "data-dir" →
+// MOBILE_CHECKER_DATA_DIR.
+func EnvName(flag string) string {
+	return EnvPrefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(flag))
+}
+
+// File holds flag values read from a config file, keyed by flag name.
+type File map[string]string
+
+// Load reads a YAML config file of flag names and values:
+//
+//	data-dir: /var/lib/mobile-checker
+//	log-format: json
+//	cors-origins: [https://example.com, http://localhost:3000]
+//
+// Lists are joined with commas, matching the flags that take several
+// values. An empty path returns an empty File.
+func Load(path string) (File, error) {
+	if path == "" {
+		return File{}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	f := make(File, len(raw))
+	for k, v := range raw {
+		switch v := v.(type) {
+		case nil:
+			f[k] = ""
+		case []any:
+			parts := make([]string, len(v))
+			for i, p := range v {
+				parts[i] = fmt.Sprint(p)
+			}
+			f[k] = strings.Join(parts, ",")
+		case map[string]any:
+			return nil, fmt.Errorf("config key %q: nested values are not supported", k)
+		default:
+			f[k] = fmt.Sprint(v)
+		}
+	}
+	return f, nil
+}
+
+// Path returns the config file to load: the --config flag value if set,
+// otherwise $MOBILE_CHECKER_CONFIG.
+func Path(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	return os.Getenv(FileEnv)
+}
+
+// lookup returns the value for a flag not given on the command line.
+func (f File) lookup(name string) (string, bool) {
+	if v, ok := os.LookupEnv(EnvName(name)); ok {
+		return v, true
+	}
+	v, ok := f[name]
+	return v, ok
+}
+
+// ApplyFlags sets each flag in fs that was not given on the command line
+// from the environment or f. Call it after fs.Parse. Keys in f that name no
+// flag in fs are ignored, so one file can serve several commands.
+func ApplyFlags(fs *flag.FlagSet, f File) error {
+	given := make(map[string]bool)
+	fs.Visit(func(fl *flag.Flag) { given[fl.Name] = true })
+
+	var err error
+	fs.VisitAll(func(fl *flag.Flag) {
+		if err != nil || given[fl.Name] {
+			return
+		}
+		if v, ok := f.lookup(fl.Name); ok {
+			if e := fs.Set(fl.Name, v); e != nil {
+				err = fmt.Errorf("invalid value %q for %s: %w", v, fl.Name, e)
+			}
+		}
+	})
+	return err
+}
+
+// ApplyPFlags is ApplyFlags for a cobra command's flag set.
+func ApplyPFlags(fs *pflag.FlagSet, f File) error {
+	var err error
+	fs.VisitAll(func(fl *pflag.Flag) {
+		if err != nil || fl.Changed {
+			return
+		}
+		if v, ok := f.lookup(fl.Name); ok {
+			if e := fs.Set(fl.Name, v); e != nil {
+				err = fmt.Errorf("invalid value %q for --%s: %w", v, fl.Name, e)
+			}
+		}
+	})
+	return err
+}
@@ -0,0 +1,91 @@
+package config_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/yourusername/mobile-checker/internal/config"
+)
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestEnvName(t *testing.T) {
+	if got := config.EnvName("data-dir"); got != "MOBILE_CHECKER_DATA_DIR" {
+		t.Errorf("expected MOBILE_CHECKER_DATA_DIR, got %s", got)
+	}
+}
+
+func TestLoad_ScalarsAndLists(t *testing.T) {
+	f, err := config.Load(writeFile(t, "data-dir: /data\nmax-body: 1024\nload-index: true\ncors-origins: [https://a.example, https://b.example]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := config.File{
+		"data-dir":     "/data",
+		"max-body":     "1024",
+		"load-index":   "true",
+		"cors-origins": "https://a.example,https://b.example",
+	}
+	for k, v := range want {
+		if f[k] != v {
+			t.Errorf("%s: expected %q, got %q", k, v, f[k])
+		}
+	}
+}
+
+func TestLoad_RejectsNestedValues(t *testing.T) {
+	if _, err := config.Load(writeFile(t, "server:\n  addr: :5001\n")); err == nil {
+		t.Error("expected an error for a nested key")
+	}
+}
+
+func TestApplyFlags_Precedence(t *testing.T) {
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	addr := fs.String("addr", ":5001", "")
+	dataDir := fs.String("data-dir", "/default", "")
+	level := fs.String("log-level", "info", "")
+	interval := fs.Duration("auto-update", 0, "")
+	if err := fs.Parse([]string{"--addr", ":8080"}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("MOBILE_CHECKER_ADDR", ":9999")
+	t.Setenv("MOBILE_CHECKER_DATA_DIR", "/env")
+	file := config.File{"data-dir": "/file", "log-level": "debug", "auto-update": "24h", "unknown": "x"}
+	if err := config.ApplyFlags(fs, file); err != nil {
+		t.Fatal(err)
+	}
+
+	if *addr != ":8080" {
+		t.Errorf("flag should beat env, got addr %s", *addr)
+	}
+	if *dataDir != "/env" {
+		t.Errorf("env should beat file, got data-dir %s", *dataDir)
+	}
+	if *level != "debug" || *interval != 24*time.Hour {
+		t.Errorf("file should beat default, got log-level %s, auto-update %s", *level, *interval)
+	}
+}
+
+func TestApplyPFlags_InvalidValue(t *testing.T) {
+	fs := pflag.NewFlagSet("check", pflag.ContinueOnError)
+	fs.Bool("json", false, "")
+	if err := fs.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MOBILE_CHECKER_JSON", "perhaps")
+	if err := config.ApplyPFlags(fs, config.File{}); err == nil {
+		t.Error("expected an error for a non-boolean value")
+	}
+}
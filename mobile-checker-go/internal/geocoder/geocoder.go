@@ -0,0 +1,238 @@
+// Package geocoder resolves free-text UK addresses, e.g. "10 Downing Street,
+// London", to a position and, where the service knows it, a postcode.
+package geocoder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+// Default service URLs.
+const (
+	DefaultNominatimURL = "https://nominatim.openstreetmap.org"
+	DefaultPlacesURL    = "https://api.postcodes.io"
+)
+
+// userAgent identifies requests, as Nominatim's usage policy requires.
+const userAgent = "mobile-checker (https://github.com/yourusername/mobile-checker)"
+
+var (
+	// ErrNoMatch is returned when the service finds nothing for an address.
+	ErrNoMatch = errors.New("address not found")
+	// ErrUnavailable is returned when the service cannot be reached or fails.
+	ErrUnavailable = errors.New("geocoder unavailable")
+)
+
+// Match is a geocoded address.
+type Match struct {
+	Query string `json:"query" xml:"query"`
+	// Label is the service's description of what it matched, which may be
+	// coarser than the query, e.g. only the town.
+	Label string `json:"label,omitempty" xml:"label,omitempty"`
+	// Postcode is the address's postcode, if the service gave a full one.
+	Postcode  string  `json:"postcode,omitempty" xml:"postcode,omitempty"`
+	Latitude  float64 `json:"latitude,omitempty" xml:"latitude,omitempty"`
+	Longitude float64 `json:"longitude,omitempty" xml:"longitude,omitempty"`
+	// Source names the geocoder, or "address" when the postcode was written
+	// in the address itself.
+	Source string `json:"source" xml:"source"`
+}
+
+// Geocoder resolves an address to a Match.
+type Geocoder interface {
+	Name() string
+	Geocode(address string) (*Match, error)
+}
+
+// Names lists the geocoders New accepts.
+var Names = []string{"nominatim", "postcodesio"}
+
+// New returns the named geocoder using the service at baseURL, or its
+// default URL when baseURL is empty.
+func New(name, baseURL string) (Geocoder, error) {
+	switch strings.ToLower(name) {
+	case "", "nominatim":
+		if baseURL == "" {
+			baseURL = DefaultNominatimURL
+		}
+		return NewNominatim(baseURL), nil
+	case "postcodesio", "postcodes.io":
+		if baseURL == "" {
+			baseURL = DefaultPlacesURL
+		}
+		return NewPlaces(baseURL), nil
+	}
+	return nil, fmt.Errorf("unknown geocoder %q: use %s", name, strings.Join(Names, " or "))
+}
+
+// postcodeInText matches a postcode written anywhere in an uppercased
+// address, with or without its space.
+var postcodeInText = regexp.MustCompile(`\b([A-Z]{1,2}[0-9][A-Z0-9]?) ?([0-9][A-Z]{2})\b`)
+
+// PostcodeIn returns the normalised postcode written in address, or "" if
+// there is none. Addresses that include one need no geocoding.
+func PostcodeIn(address string) string {
+	for _, m := range postcodeInText.FindAllStringSubmatch(strings.ToUpper(address), -1) {
+		if pc := m[1] + m[2]; postcode.Valid(pc) {
+			return pc
+		}
+	}
+	return ""
+}
+
+// client is the HTTP plumbing shared by the geocoders.
+type client struct {
+	baseURL string
+	http    *http.Client
+}
+
+func newClient(baseURL string) client {
+	return client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// getJSON fetches path with query q and decodes the response into v.
+func (c client) getJSON(path string, q url.Values, v any) error {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+path+"?"+q.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: returned status %d", ErrUnavailable, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// Nominatim geocodes with OpenStreetMap's Nominatim, which matches street
+// addresses and often returns their postcode. The public server allows one
+// request a second; run your own for volume.
+type Nominatim struct {
+	client
+}
+
+// NewNominatim returns a geocoder for the Nominatim server at baseURL.
+func NewNominatim(baseURL string) *Nominatim {
+	return &Nominatim{newClient(baseURL)}
+}
+
+// Name implements Geocoder.
+func (n *Nominatim) Name() string { return "nominatim" }
+
+type nominatimPlace struct {
+	Lat         string `json:"lat"`
+	Lon         string `json:"lon"`
+	DisplayName string `json:"display_name"`
+	Address     struct {
+		Postcode string `json:"postcode"`
+	} `json:"address"`
+}
+
+// Geocode implements Geocoder.
+func (n *Nominatim) Geocode(address string) (*Match, error) {
+	q := url.Values{
+		"q":              {address},
+		"format":         {"jsonv2"},
+		"addressdetails": {"1"},
+		"countrycodes":   {"gb"},
+		"limit":          {"1"},
+	}
+	var places []nominatimPlace
+	if err := n.getJSON("/search", q, &places); err != nil {
+		return nil, err
+	}
+	if len(places) == 0 {
+		return nil, fmt.Errorf("%q: %w", address, ErrNoMatch)
+	}
+	p := places[0]
+	m := &Match{Query: address, Label: p.DisplayName, Source: n.Name()}
+	m.Latitude, _ = strconv.ParseFloat(p.Lat, 64)
+	m.Longitude, _ = strconv.ParseFloat(p.Lon, 64)
+	// OSM sometimes holds only a postcode district, e.g. "SW1A".
+	if postcode.Valid(p.Address.Postcode) {
+		m.Postcode = postcode.Normalise(p.Address.Postcode)
+	}
+	return m, nil
+}
+
+// Places geocodes with postcodes.io's places search over OS Open Names.
+// It knows towns, villages and neighbourhoods rather than street
+// addresses, so matches are coarse and never carry a postcode.
+type Places struct {
+	client
+}
+
+// NewPlaces returns a geocoder for the postcodes.io server at baseURL.
+func NewPlaces(baseURL string) *Places {
+	return &Places{newClient(baseURL)}
+}
+
+// Name implements Geocoder.
+func (p *Places) Name() string { return "postcodesio" }
+
+type placesResponse struct {
+	Result []struct {
+		Name      string  `json:"name_1"`
+		County    string  `json:"county_unitary"`
+		District  string  `json:"district_borough"`
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+	} `json:"result"`
+}
+
+// Geocode implements Geocoder. It searches for the whole address, then for
+// each comma-separated part in turn, so "10 Downing Street, London" finds
+// London.
+func (p *Places) Geocode(address string) (*Match, error) {
+	queries := []string{address}
+	if parts := strings.Split(address, ","); len(parts) > 1 {
+		queries = append(queries, parts...)
+	}
+	for _, q := range queries {
+		q = strings.TrimSpace(q)
+		if q == "" {
+			continue
+		}
+		var resp placesResponse
+		if err := p.getJSON("/places", url.Values{"q": {q}, "limit": {"1"}}, &resp); err != nil {
+			return nil, err
+		}
+		if len(resp.Result) == 0 {
+			continue
+		}
+		r := resp.Result[0]
+		label := r.Name
+		for _, area := range []string{r.District, r.County} {
+			if area != "" && area != r.Name {
+				label += ", " + area
+				break
+			}
+		}
+		return &Match{Query: address, Label: label, Latitude: r.Latitude, Longitude: r.Longitude, Source: p.Name()}, nil
+	}
+	return nil, fmt.Errorf("%q: %w", address, ErrNoMatch)
+}
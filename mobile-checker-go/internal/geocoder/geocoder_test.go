@@ -0,0 +1,137 @@
+package geocoder
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPostcodeIn(t *testing.T) {
+	cases := map[string]string{
+		"10 Downing Street, London SW1A 2AA": "SW1A2AA",
+		"flat 2, 1 high st, m11ae":           "M11AE",
+		"10 Downing Street, London":          "",
+		"SW1A, London":                       "",
+	}
+	for address, want := range cases {
+		if got := PostcodeIn(address); got != want {
+			t.Errorf("PostcodeIn(%q) = %q, want %q", address, got, want)
+		}
+	}
+}
+
+func TestNominatim_UsesFullPostcodeOnly(t *testing.T) {
+	postcodes := []string{"SW1A 2AA", "SW1A"}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search" || r.URL.Query().Get("countrycodes") != "gb" || r.Header.Get("User-Agent") == "" {
+			t.Errorf("unexpected request %s (User-Agent %q)", r.URL, r.Header.Get("User-Agent"))
+		}
+		pc := postcodes[0]
+		postcodes = postcodes[1:]
+		w.Write([]byte(`[{"lat":"51.5034","lon":"-0.1276","display_name":"10 Downing Street","address":{"postcode":"` + pc + `"}}]`))
+	}))
+	defer srv.Close()
+
+	g := NewNominatim(srv.URL)
+	m, err := g.Geocode("10 Downing Street, London")
+	if err != nil {
+		t.Fatalf("geocode failed: %v", err)
+	}
+	if m.Postcode != "SW1A2AA" || m.Latitude != 51.5034 || m.Source != "nominatim" {
+		t.Errorf("unexpected match %+v", m)
+	}
+	if m, err = g.Geocode("Downing Street"); err != nil || m.Postcode != "" {
+		t.Errorf("expected a district-only postcode to be dropped, got %+v, %v", m, err)
+	}
+}
+
+func TestNominatim_NoMatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	if _, err := NewNominatim(srv.URL).Geocode("nowhere"); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("expected ErrNoMatch, got %v", err)
+	}
+}
+
+func TestPlaces_FallsBackToAddressParts(t *testing.T) {
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("q")
+		queries = append(queries, q)
+		if q != "London" {
+			w.Write([]byte(`{"status":200,"result":[]}`))
+			return
+		}
+		w.Write([]byte(`{"status":200,"result":[{"name_1":"London","county_unitary":"City of Westminster","latitude":51.5,"longitude":-0.12}]}`))
+	}))
+	defer srv.Close()
+
+	m, err := NewPlaces(srv.URL).Geocode("10 Downing Street, London")
+	if err != nil {
+		t.Fatalf("geocode failed: %v", err)
+	}
+	if m.Label != "London, City of Westminster" || m.Postcode != "" || len(queries) != 3 {
+		t.Errorf("unexpected match %+v after queries %q", m, queries)
+	}
+}
+
+func TestGeocode_Unavailable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	for _, g := range []Geocoder{NewNominatim(srv.URL), NewPlaces(srv.URL)} {
+		if _, err := g.Geocode("London"); !errors.Is(err, ErrUnavailable) {
+			t.Errorf("%s: expected ErrUnavailable, got %v", g.Name(), err)
+		}
+	}
+}
+
+func TestIPLocators_ReadPosition(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json/":
+			w.Write([]byte(`{"ip":"81.2.69.160","city":"Leeds","region":"England","country_code":"GB","latitude":53.7965,"longitude":-1.5478}`))
+		case "/json":
+			if r.URL.Query().Get("token") != "secret" {
+				t.Errorf("expected the token to be sent, got %s", r.URL)
+			}
+			w.Write([]byte(`{"ip":"81.2.69.160","city":"Leeds","region":"England","country":"GB","loc":"53.7965,-1.5478"}`))
+		}
+	}))
+	defer srv.Close()
+
+	for _, name := range IPLocators {
+		l, err := NewIPLocator(name, srv.URL, "secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := l.Locate()
+		if err != nil {
+			t.Fatalf("%s: locate failed: %v", name, err)
+		}
+		if m.Query != "81.2.69.160" || m.Label != "Leeds, England" || m.Latitude != 53.7965 || m.Longitude != -1.5478 || m.Source != name {
+			t.Errorf("%s: unexpected match %+v", name, m)
+		}
+	}
+}
+
+func TestIPLocator_RefusesOutsideUK(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ip":"203.0.113.9","city":"Amsterdam","country_code":"NL","latitude":52.37,"longitude":4.89}`))
+	}))
+	defer srv.Close()
+
+	_, err := NewIPAPI(srv.URL).Locate()
+	if !errors.Is(err, ErrOutsideUK) || !errors.Is(err, ErrNoMatch) {
+		t.Errorf("expected ErrOutsideUK wrapping ErrNoMatch, got %v", err)
+	}
+	if _, err := NewIPLocator("geoip", "", ""); err == nil {
+		t.Error("expected an unknown locator to be refused")
+	}
+}
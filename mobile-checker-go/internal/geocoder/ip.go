@@ -0,0 +1,144 @@
+package geocoder
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Default IP geolocation service URLs.
+const (
+	DefaultIPAPIURL  = "https://ipapi.co"
+	DefaultIPInfoURL = "https://ipinfo.io"
+)
+
+// ErrOutsideUK is returned when the public IP address is placed outside the
+// UK, e.g. behind a VPN or a mobile operator's foreign gateway. It wraps
+// ErrNoMatch.
+var ErrOutsideUK = fmt.Errorf("public IP address is not in the UK: %w", ErrNoMatch)
+
+// IPLocator places the machine by its public IP address. Positions are
+// coarse — the town or the operator's nearest exchange — and a VPN moves
+// them entirely.
+type IPLocator interface {
+	Name() string
+	// Locate returns the public IP address as the Match's Query, with its
+	// position and a label naming the town.
+	Locate() (*Match, error)
+}
+
+// IPLocators lists the IP locators NewIPLocator accepts.
+var IPLocators = []string{"ipapi", "ipinfo"}
+
+// NewIPLocator returns the named IP locator using the service at baseURL,
+// or its default URL when baseURL is empty. token is ipinfo.io's access
+// token, which raises its free limit; ipapi needs none.
+func NewIPLocator(name, baseURL, token string) (IPLocator, error) {
+	switch strings.ToLower(name) {
+	case "", "ipapi", "ipapi.co":
+		if baseURL == "" {
+			baseURL = DefaultIPAPIURL
+		}
+		return NewIPAPI(baseURL), nil
+	case "ipinfo", "ipinfo.io":
+		if baseURL == "" {
+			baseURL = DefaultIPInfoURL
+		}
+		return NewIPInfo(baseURL, token), nil
+	}
+	return nil, fmt.Errorf("unknown IP locator %q: use %s", name, strings.Join(IPLocators, " or "))
+}
+
+// IPAPI locates with ipapi.co, which allows about a thousand requests a day
+// without a key.
+type IPAPI struct {
+	client
+}
+
+// NewIPAPI returns an IP locator for the ipapi.co server at baseURL.
+func NewIPAPI(baseURL string) *IPAPI {
+	return &IPAPI{newClient(baseURL)}
+}
+
+// Name implements IPLocator.
+func (l *IPAPI) Name() string { return "ipapi" }
+
+// Locate implements IPLocator.
+func (l *IPAPI) Locate() (*Match, error) {
+	var r struct {
+		IP        string  `json:"ip"`
+		City      string  `json:"city"`
+		Region    string  `json:"region"`
+		Country   string  `json:"country_code"`
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+		Error     bool    `json:"error"`
+		Reason    string  `json:"reason"`
+	}
+	if err := l.getJSON("/json/", url.Values{}, &r); err != nil {
+		return nil, err
+	}
+	if r.Error {
+		return nil, fmt.Errorf("%w: %s", ErrUnavailable, r.Reason)
+	}
+	return ipMatch(l.Name(), r.IP, r.Country, r.Latitude, r.Longitude, r.City, r.Region)
+}
+
+// IPInfo locates with ipinfo.io.
+type IPInfo struct {
+	client
+	token string
+}
+
+// NewIPInfo returns an IP locator for the ipinfo.io server at baseURL,
+// authenticating with token when it is set.
+func NewIPInfo(baseURL, token string) *IPInfo {
+	return &IPInfo{newClient(baseURL), token}
+}
+
+// Name implements IPLocator.
+func (l *IPInfo) Name() string { return "ipinfo" }
+
+// Locate implements IPLocator.
+func (l *IPInfo) Locate() (*Match, error) {
+	q := url.Values{}
+	if l.token != "" {
+		q.Set("token", l.token)
+	}
+	var r struct {
+		IP      string `json:"ip"`
+		City    string `json:"city"`
+		Region  string `json:"region"`
+		Country string `json:"country"`
+		Loc     string `json:"loc"` // "53.7965,-1.5478"
+	}
+	if err := l.getJSON("/json", q, &r); err != nil {
+		return nil, err
+	}
+	lat, lon, ok := strings.Cut(r.Loc, ",")
+	if !ok {
+		return nil, fmt.Errorf("%w: no position for %s", ErrUnavailable, r.IP)
+	}
+	la, err1 := strconv.ParseFloat(lat, 64)
+	lo, err2 := strconv.ParseFloat(lon, 64)
+	if err1 != nil || err2 != nil {
+		return nil, fmt.Errorf("%w: invalid position %q", ErrUnavailable, r.Loc)
+	}
+	return ipMatch(l.Name(), r.IP, r.Country, la, lo, r.City, r.Region)
+}
+
+// ipMatch builds the Match for a located IP address, refusing places
+// outside the UK, which have no postcode to check.
+func ipMatch(source, ip, country string, lat, lon float64, areas ...string) (*Match, error) {
+	if !strings.EqualFold(country, "GB") {
+		return nil, fmt.Errorf("%s located in %q: %w", ip, country, ErrOutsideUK)
+	}
+	var label []string
+	for _, a := range areas {
+		if a != "" {
+			label = append(label, a)
+		}
+	}
+	return &Match{Query: ip, Label: strings.Join(label, ", "), Latitude: lat, Longitude: lon, Source: source}, nil
+}
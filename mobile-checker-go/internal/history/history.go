@@ -0,0 +1,425 @@
+// Package history keeps an audit trail of coverage checks — what was
+// reported for a postcode, when, and from which dataset — in history.db.
+package history
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+	"github.com/yourusername/mobile-checker/internal/seal"
+)
+
+// ErrEncrypted is returned reading or adding to encrypted history without
+// its key.
+var ErrEncrypted = errors.New("check history is encrypted: pass its key with --encrypt-key-file or $MOBILE_CHECKER_ENCRYPT_KEY")
+
+// Entry is one recorded check.
+type Entry struct {
+	ID        int64                `json:"id"`
+	Postcode  string               `json:"postcode"`
+	CheckedAt string               `json:"checked_at"`
+	Dataset   string               `json:"dataset,omitempty"` // dataset year the check was answered from
+	Summary   *ofcom.MobileSummary `json:"summary,omitempty"`
+	// Message is the error or note reported with the check, if any.
+	Message string `json:"message,omitempty"`
+}
+
+// pruneInterval is how often Record removes checks older than the
+// retention period.
+const pruneInterval = time.Hour
+
+// Store records checks in a SQLite database. The database is opened on
+// first use and held until Close.
+type Store struct {
+	DBPath string
+	// Retention is how long checks are kept; older ones are pruned as new
+	// checks are recorded. Zero keeps them forever.
+	Retention time.Duration
+
+	passphrase string // set by WithEncryptionKey
+
+	mu        sync.Mutex // serialises writes from concurrent checks
+	db        *sql.DB
+	key       *seal.Key // when the history is encrypted and the key given
+	encrypted bool
+	lastPrune time.Time
+}
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithRetention keeps recorded checks for d; see Store.Retention.
+func WithRetention(d time.Duration) Option {
+	return func(s *Store) { s.Retention = d }
+}
+
+// WithEncryptionKey encrypts each recorded check's postcode, summary and
+// message with a key derived from passphrase. Postcodes are stored as a
+// keyed hash, so checks can still be listed by postcode; times and dataset
+// years are not encrypted, so old checks can be pruned. Checks recorded
+// before a key was first given are encrypted then; from then on the
+// history cannot be read or added to without it.
+func WithEncryptionKey(passphrase string) Option {
+	return func(s *Store) { s.passphrase = passphrase }
+}
+
+// New creates a Store using history.db in dataDir.
+func New(dataDir string, opts ...Option) *Store {
+	s := &Store{DBPath: filepath.Join(dataDir, "history.db")}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Close closes the database. A later call reopens it.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.db == nil {
+		return nil
+	}
+	err := s.db.Close()
+	s.db, s.key = nil, nil
+	return err
+}
+
+// handle returns the open database, opening it if needed. s.mu must be
+// held.
+func (s *Store) handle() (*sql.DB, error) {
+	if s.db != nil {
+		return s.db, nil
+	}
+	db, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loadKey(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	s.db = db
+	return db, nil
+}
+
+// keyCheck is sealed with the key when the history is first encrypted,
+// so a wrong key is refused rather than recording unreadable checks.
+var keyCheck = []byte("mobile-checker history")
+
+// loadKey derives the key from the salt stored in db, storing a new salt
+// if a key is given and there is none.
+func (s *Store) loadKey(db *sql.DB) error {
+	var salt, check []byte
+	err := db.QueryRow(`SELECT value FROM meta WHERE key = 'salt'`).Scan(&salt)
+	if err == nil {
+		err = db.QueryRow(`SELECT value FROM meta WHERE key = 'check'`).Scan(&check)
+	}
+	switch {
+	case err == sql.ErrNoRows && s.passphrase != "":
+		if salt, err = seal.NewSalt(); err != nil {
+			return err
+		}
+		key, err := seal.Derive(s.passphrase, salt)
+		if err != nil {
+			return err
+		}
+		if check, err = key.Seal(nil, keyCheck, nil); err != nil {
+			return err
+		}
+		if _, err := db.Exec(`INSERT OR REPLACE INTO meta (key, value) VALUES ('salt', ?), ('check', ?)`, salt, check); err != nil {
+			return err
+		}
+	case err == sql.ErrNoRows:
+		s.encrypted = false
+		return nil
+	case err != nil:
+		return err
+	}
+	s.encrypted = true
+	if s.passphrase == "" {
+		return nil
+	}
+	key, err := seal.Derive(s.passphrase, salt)
+	if err != nil {
+		return err
+	}
+	if _, err := key.Open(nil, check, nil); err != nil {
+		return fmt.Errorf("check history: %w", err)
+	}
+	s.key = key
+	return s.sealPlain(db)
+}
+
+// sealPlain encrypts checks recorded without a key, then rewrites the
+// file so their plain text is not left in free pages or the WAL.
+func (s *Store) sealPlain(db *sql.DB) error {
+	type plain struct {
+		id                         int64
+		postcode, summary, message string
+	}
+	rows, err := db.Query(`SELECT id, postcode, COALESCE(summary, ''), COALESCE(message, '') FROM checks WHERE sealed IS NULL`)
+	if err != nil {
+		return err
+	}
+	var checks []plain
+	for rows.Next() {
+		var c plain
+		if err := rows.Scan(&c.id, &c.postcode, &c.summary, &c.message); err != nil {
+			rows.Close()
+			return err
+		}
+		checks = append(checks, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(checks) == 0 {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, c := range checks {
+		var summary *ofcom.MobileSummary
+		if c.summary != "" {
+			summary = &ofcom.MobileSummary{}
+			if err := json.Unmarshal([]byte(c.summary), summary); err != nil {
+				return fmt.Errorf("corrupt summary for check %d: %w", c.id, err)
+			}
+		}
+		box, err := s.seal(c.postcode, summary, c.message)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE checks SET postcode = ?, summary = NULL, message = NULL, sealed = ? WHERE id = ?`,
+			s.lookupKey(c.postcode), box, c.id); err != nil {
+			return fmt.Errorf("encrypting check %d: %w", c.id, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`VACUUM`); err != nil {
+		return err
+	}
+	_, err = db.Exec(`PRAGMA wal_checkpoint(TRUNCATE)`)
+	return err
+}
+
+// seal encrypts the parts of a check that sealed holds.
+func (s *Store) seal(pc string, summary *ofcom.MobileSummary, message string) ([]byte, error) {
+	b, err := json.Marshal(sealed{Postcode: postcode.Normalise(pc), Summary: summary, Message: message})
+	if err != nil {
+		return nil, err
+	}
+	return s.key.Seal(nil, b, nil)
+}
+
+// sealed is the encrypted part of a check.
+type sealed struct {
+	Postcode string               `json:"postcode"`
+	Summary  *ofcom.MobileSummary `json:"summary,omitempty"`
+	Message  string               `json:"message,omitempty"`
+}
+
+// lookupKey is pc as stored in the postcode column: as it is, or keyed
+// hashed when the history is encrypted.
+func (s *Store) lookupKey(pc string) string {
+	pc = postcode.Normalise(pc)
+	if s.key == nil || pc == "" {
+		return pc
+	}
+	return hex.EncodeToString(s.key.MAC([]byte(pc)))
+}
+
+func (s *Store) open() (*sql.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(s.DBPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+	d := ofcom.DefaultDriver
+	db, err := sql.Open(d.Name, d.DSN(s.DBPath, false))
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS checks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		postcode TEXT NOT NULL,
+		checked_at TEXT NOT NULL,
+		dataset TEXT,
+		summary TEXT,
+		message TEXT
+	);
+	CREATE INDEX IF NOT EXISTS checks_postcode ON checks (postcode, id);
+	CREATE TABLE IF NOT EXISTS meta (key TEXT PRIMARY KEY, value BLOB)`)
+	if err == nil {
+		err = addColumn(db, "checks", "sealed", "BLOB")
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// addColumn adds a column to a table created by an older release.
+func addColumn(db *sql.DB, table, column, decl string) error {
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&n); err != nil || n > 0 {
+		return err
+	}
+	_, err := db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, decl))
+	return err
+}
+
+// Record stores e, filling in CheckedAt if it is empty.
+func (s *Store) Record(e Entry) error {
+	if e.CheckedAt == "" {
+		e.CheckedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	db, err := s.handle()
+	if err != nil {
+		return err
+	}
+
+	var summary, message, box interface{}
+	switch {
+	case s.key != nil:
+		if box, err = s.seal(e.Postcode, e.Summary, e.Message); err != nil {
+			return err
+		}
+	case s.encrypted:
+		return ErrEncrypted
+	default:
+		if e.Summary != nil {
+			b, err := json.Marshal(e.Summary)
+			if err != nil {
+				return err
+			}
+			summary = string(b)
+		}
+		message = e.Message
+	}
+
+	_, err = db.Exec(`INSERT INTO checks (postcode, checked_at, dataset, summary, message, sealed) VALUES (?, ?, ?, ?, ?, ?)`,
+		s.lookupKey(e.Postcode), e.CheckedAt, e.Dataset, summary, message, box)
+	if err != nil {
+		return fmt.Errorf("failed to record check: %w", err)
+	}
+	if s.Retention > 0 && time.Since(s.lastPrune) >= pruneInterval {
+		s.lastPrune = time.Now()
+		if _, err := prune(db, time.Now().Add(-s.Retention)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Prune removes checks recorded before cutoff and returns how many were
+// removed.
+func (s *Store) Prune(cutoff time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	db, err := s.handle()
+	if err != nil {
+		return 0, err
+	}
+	return prune(db, cutoff)
+}
+
+func prune(db *sql.DB, cutoff time.Time) (int64, error) {
+	// checked_at is RFC 3339 in UTC, so it orders as text.
+	res, err := db.Exec(`DELETE FROM checks WHERE checked_at < ?`, cutoff.UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune history: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// List returns up to limit recorded checks, newest first, for pc or for
+// every postcode if pc is empty. A limit of 0 or less returns them all.
+func (s *Store) List(pc string, limit int) ([]Entry, error) {
+	s.mu.Lock()
+	db, err := s.handle()
+	key := s.key
+	lookup := s.lookupKey(pc)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = -1 // SQLite: no limit
+	}
+	rows, err := db.Query(`SELECT id, postcode, checked_at, COALESCE(dataset, ''), COALESCE(summary, ''), COALESCE(message, ''), sealed
+		FROM checks WHERE ? = '' OR postcode = ? ORDER BY id DESC LIMIT ?`,
+		lookup, lookup, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		var summary string
+		var box []byte
+		if err := rows.Scan(&e.ID, &e.Postcode, &e.CheckedAt, &e.Dataset, &summary, &e.Message, &box); err != nil {
+			return nil, err
+		}
+		if box != nil {
+			if key == nil {
+				return nil, ErrEncrypted
+			}
+			b, err := key.Open(nil, box, nil)
+			if err != nil {
+				return nil, fmt.Errorf("check %d: %w", e.ID, err)
+			}
+			var v sealed
+			if err := json.Unmarshal(b, &v); err != nil {
+				return nil, fmt.Errorf("corrupt check %d: %w", e.ID, err)
+			}
+			e.Postcode, e.Summary, e.Message = v.Postcode, v.Summary, v.Message
+		} else if summary != "" {
+			e.Summary = &ofcom.MobileSummary{}
+			if err := json.Unmarshal([]byte(summary), e.Summary); err != nil {
+				return nil, fmt.Errorf("corrupt summary for check %d: %w", e.ID, err)
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// Diff compares the most recent check of pc with the one before it. It
+// returns the two entries, newest first, and the coverage changes between
+// them; fewer than two checks, or a check without coverage, give no
+// changes.
+func (s *Store) Diff(pc string) (latest, previous *Entry, changes []ofcom.Change, err error) {
+	entries, err := s.List(pc, 2)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(entries) > 0 {
+		latest = &entries[0]
+	}
+	if len(entries) > 1 {
+		previous = &entries[1]
+		if latest.Summary != nil && previous.Summary != nil {
+			changes = ofcom.Changes(*previous.Summary, *latest.Summary)
+		}
+	}
+	return latest, previous, changes, nil
+}
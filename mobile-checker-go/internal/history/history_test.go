@@ -0,0 +1,133 @@
+package history_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/history"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/seal"
+)
+
+func TestStore_ListAndDiff(t *testing.T) {
+	s := history.New(t.TempDir())
+	defer s.Close()
+
+	before := ofcom.Interpret(map[string]string{"postcode": "SW1A1AA", "ee_4g": "1.0", "ee_5g": "0.0"})
+	after := ofcom.Interpret(map[string]string{"postcode": "SW1A1AA", "ee_4g": "1.0", "ee_5g": "0.8"})
+	for _, e := range []history.Entry{
+		{Postcode: "sw1a 1aa", Dataset: "2022", Summary: &before},
+		{Postcode: "EC1A1BB", Message: "Postcode not found in Ofcom mobile dataset."},
+		{Postcode: "SW1A1AA", Dataset: "2023", Summary: &after},
+	} {
+		if err := s.Record(e); err != nil {
+			t.Fatalf("record failed: %v", err)
+		}
+	}
+
+	all, err := s.List("", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || all[0].Dataset != "2023" || all[1].Message == "" {
+		t.Fatalf("expected 3 entries newest first, got %+v", all)
+	}
+	if one, _ := s.List("SW1A 1AA", 1); len(one) != 1 || one[0].Summary == nil {
+		t.Errorf("expected the latest SW1A1AA check with a summary, got %+v", one)
+	}
+
+	latest, previous, changes, err := s.Diff("SW1A1AA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest == nil || previous == nil || previous.Dataset != "2022" {
+		t.Fatalf("expected two checks to compare, got %+v / %+v", latest, previous)
+	}
+	if len(changes) != 1 || changes[0].Technology != "5g" || !changes[0].Gained {
+		t.Errorf("unexpected changes: %+v", changes)
+	}
+
+	if _, previous, _, _ := s.Diff("EC1A1BB"); previous != nil {
+		t.Error("expected nothing to compare for a single check")
+	}
+}
+
+func TestStore_Retention(t *testing.T) {
+	dir := t.TempDir()
+	s := history.New(dir, history.WithRetention(24*time.Hour))
+	old := time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339)
+	for _, e := range []history.Entry{
+		{Postcode: "SW1A1AA", CheckedAt: old},
+		{Postcode: "EC1A1BB", CheckedAt: old},
+	} {
+		if err := s.Record(e); err != nil {
+			t.Fatalf("record failed: %v", err)
+		}
+	}
+	// Record prunes at most once an interval, so the second old check
+	// outlives the first Record's prune; a new Store starts afresh.
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	s = history.New(dir, history.WithRetention(24*time.Hour))
+	defer s.Close()
+	if err := s.Record(history.Entry{Postcode: "LS11AA"}); err != nil {
+		t.Fatalf("record failed: %v", err)
+	}
+	if all, err := s.List("", 0); err != nil || len(all) != 1 || all[0].Postcode != "LS11AA" {
+		t.Fatalf("expected only the recent check to be kept, got %+v (err %v)", all, err)
+	}
+
+	if err := s.Record(history.Entry{Postcode: "SW1A1AA", CheckedAt: old}); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := s.Prune(time.Now().Add(-time.Hour)); err != nil || n != 1 {
+		t.Errorf("expected Prune to remove 1 check, removed %d (err %v)", n, err)
+	}
+}
+
+func TestStore_EncryptionKey(t *testing.T) {
+	dir := t.TempDir()
+	plain := history.New(dir)
+	if err := plain.Record(history.Entry{Postcode: "EC1A1BB", Message: "recorded before encryption"}); err != nil {
+		t.Fatal(err)
+	}
+	plain.Close()
+
+	s := history.New(dir, history.WithEncryptionKey("correct horse"))
+	summary := ofcom.Interpret(map[string]string{"postcode": "SW1A1AA", "ee_4g": "1.0"})
+	if err := s.Record(history.Entry{Postcode: "sw1a 1aa", Dataset: "2023", Summary: &summary}); err != nil {
+		t.Fatalf("record failed: %v", err)
+	}
+	got, err := s.List("SW1A 1AA", 0)
+	if err != nil || len(got) != 1 || got[0].Postcode != "SW1A1AA" || got[0].Summary == nil || got[0].Dataset != "2023" {
+		t.Fatalf("expected the encrypted check back, got %+v (err %v)", got, err)
+	}
+	if got, err := s.List("EC1A 1BB", 0); err != nil || len(got) != 1 || got[0].Message != "recorded before encryption" {
+		t.Errorf("expected the check recorded before encryption, got %+v (err %v)", got, err)
+	}
+	s.Close()
+
+	b, err := os.ReadFile(s.DBPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, plain := range []string{"SW1A1AA", "EC1A1BB", "recorded before encryption"} {
+		if bytes.Contains(b, []byte(plain)) {
+			t.Errorf("expected %q not to be stored in plain text", plain)
+		}
+	}
+
+	if _, err := history.New(dir).List("", 0); !errors.Is(err, history.ErrEncrypted) {
+		t.Errorf("expected ErrEncrypted listing without a key, got %v", err)
+	}
+	if err := history.New(dir).Record(history.Entry{Postcode: "SW1A1AA"}); !errors.Is(err, history.ErrEncrypted) {
+		t.Errorf("expected ErrEncrypted recording without a key, got %v", err)
+	}
+	if _, err := history.New(dir, history.WithEncryptionKey("wrong")).List("", 0); !errors.Is(err, seal.ErrDecrypt) {
+		t.Errorf("expected a wrong key to be refused, got %v", err)
+	}
+}
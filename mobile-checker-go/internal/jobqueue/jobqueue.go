@@ -0,0 +1,420 @@
+// Package jobqueue keeps the server's background bulk jobs, and their
+// results as they are checked, in jobs.db in the data directory, so jobs
+// in progress survive a restart and finished results can be fetched until
+// they expire.
+package jobqueue
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+// Job states.
+const (
+	Queued  = "queued"
+	Running = "running"
+	Done    = "done"
+)
+
+// States lists the job states, in the order a job passes through them.
+var States = []string{Queued, Running, Done}
+
+// ErrNotFound is returned for a job that does not exist.
+var ErrNotFound = errors.New("no such job")
+
+// Job is a bulk check of Postcodes run in the background.
+type Job struct {
+	ID    string
+	State string
+	// Postcodes are the job's input. They are left out by List.
+	Postcodes []string
+	Total     int
+	// Options and Bulk are the check options the job was submitted with,
+	// reused when it is resumed.
+	Options checker.CheckOptions
+	Bulk    checker.BulkOptions
+	// Completed, Failed and Summary count the results saved so far.
+	Completed int
+	Failed    int
+	Summary   checker.Summary
+	CreatedAt time.Time
+	// StartedAt and FinishedAt are zero until the job starts and is done.
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// Store manages the jobs in jobs.db. The database is opened on first use
+// and held until Close.
+type Store struct {
+	DBPath string
+
+	mu sync.Mutex
+	db *sql.DB
+}
+
+// New creates a Store using jobs.db in dataDir.
+func New(dataDir string) *Store {
+	return &Store{DBPath: filepath.Join(dataDir, "jobs.db")}
+}
+
+// Close closes the database. A later call reopens it.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.db == nil {
+		return nil
+	}
+	err := s.db.Close()
+	s.db = nil
+	return err
+}
+
+// handle returns the open database, opening it if needed.
+func (s *Store) handle() (*sql.DB, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.db != nil {
+		return s.db, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.DBPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+	d := ofcom.DefaultDriver
+	db, err := sql.Open(d.Name, d.DSN(s.DBPath, false))
+	if err != nil {
+		return nil, err
+	}
+	// Results are saved by several jobs at once while others are read.
+	_, err = db.Exec(`PRAGMA journal_mode = WAL;
+	CREATE TABLE IF NOT EXISTS jobs (
+		id TEXT PRIMARY KEY,
+		state TEXT NOT NULL,
+		postcodes TEXT NOT NULL,
+		total INTEGER NOT NULL,
+		options TEXT NOT NULL,
+		completed INTEGER NOT NULL DEFAULT 0,
+		failed INTEGER NOT NULL DEFAULT 0,
+		summary TEXT NOT NULL DEFAULT '{}',
+		created_at INTEGER NOT NULL,
+		started_at INTEGER NOT NULL DEFAULT 0,
+		finished_at INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX IF NOT EXISTS jobs_state ON jobs (state, created_at);
+	CREATE TABLE IF NOT EXISTS results (
+		job_id TEXT NOT NULL,
+		idx INTEGER NOT NULL,
+		result TEXT NOT NULL,
+		PRIMARY KEY (job_id, idx)
+	) WITHOUT ROWID`)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	s.db = db
+	return db, nil
+}
+
+// options is the JSON stored in jobs.options.
+type options struct {
+	Check checker.CheckOptions `json:"check"`
+	Bulk  checker.BulkOptions  `json:"bulk"`
+}
+
+// Create stores a new job.
+func (s *Store) Create(j *Job) error {
+	db, err := s.handle()
+	if err != nil {
+		return err
+	}
+	pcs, err := json.Marshal(j.Postcodes)
+	if err != nil {
+		return err
+	}
+	opts, err := json.Marshal(options{j.Options, j.Bulk})
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO jobs (id, state, postcodes, total, options, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		j.ID, j.State, string(pcs), len(j.Postcodes), string(opts), j.CreatedAt.UnixNano())
+	return err
+}
+
+// jobColumns are the columns read by scanJob, without postcodes.
+const jobColumns = `id, state, total, options, completed, failed, summary, created_at, started_at, finished_at`
+
+func scanJob(row interface{ Scan(...any) error }, extra ...any) (*Job, error) {
+	var j Job
+	var opts, summary string
+	var created, started, finished int64
+	dest := append([]any{&j.ID, &j.State, &j.Total, &opts, &j.Completed, &j.Failed, &summary, &created, &started, &finished}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	var o options
+	if err := json.Unmarshal([]byte(opts), &o); err != nil {
+		return nil, fmt.Errorf("job %s: invalid options: %w", j.ID, err)
+	}
+	j.Options, j.Bulk = o.Check, o.Bulk
+	json.Unmarshal([]byte(summary), &j.Summary)
+	j.CreatedAt = unixTime(created)
+	j.StartedAt = unixTime(started)
+	j.FinishedAt = unixTime(finished)
+	return &j, nil
+}
+
+func unixTime(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns).UTC()
+}
+
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// Get returns the job with its postcodes, or ErrNotFound.
+func (s *Store) Get(id string) (*Job, error) {
+	db, err := s.handle()
+	if err != nil {
+		return nil, err
+	}
+	var pcs string
+	j, err := scanJob(db.QueryRow(`SELECT `+jobColumns+`, postcodes FROM jobs WHERE id = ?`, id), &pcs)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%q: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(pcs), &j.Postcodes); err != nil {
+		return nil, fmt.Errorf("job %s: invalid postcodes: %w", id, err)
+	}
+	return j, nil
+}
+
+// List returns up to limit jobs in state, or in any state when it is
+// empty, newest first and without their postcodes.
+func (s *Store) List(state string, limit int) ([]*Job, error) {
+	db, err := s.handle()
+	if err != nil {
+		return nil, err
+	}
+	q, args := `SELECT `+jobColumns+` FROM jobs`, []any{}
+	if state != "" {
+		q, args = q+` WHERE state = ?`, append(args, state)
+	}
+	rows, err := db.Query(q+` ORDER BY created_at DESC LIMIT ?`, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	jobs := []*Job{}
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+// Unfinished returns the jobs queued or running, oldest first, with their
+// postcodes, for resuming after a restart.
+func (s *Store) Unfinished() ([]*Job, error) {
+	db, err := s.handle()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(`SELECT id FROM jobs WHERE state IN (?, ?) ORDER BY created_at`, Queued, Running)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	var jobs []*Job
+	for _, id := range ids {
+		j, err := s.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, nil
+}
+
+// Pending counts the jobs queued or running.
+func (s *Store) Pending() (int, error) {
+	db, err := s.handle()
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	err = db.QueryRow(`SELECT COUNT(*) FROM jobs WHERE state IN (?, ?)`, Queued, Running).Scan(&n)
+	return n, err
+}
+
+// Save stores results for j and then j's state, counts and times, in one
+// transaction. It returns ErrNotFound if j has been deleted, storing
+// nothing.
+func (s *Store) Save(j *Job, results []checker.Indexed) error {
+	db, err := s.handle()
+	if err != nil {
+		return err
+	}
+	summary, err := json.Marshal(j.Summary)
+	if err != nil {
+		return err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	res, err := tx.Exec(`UPDATE jobs SET state = ?, completed = ?, failed = ?, summary = ?, started_at = ?, finished_at = ? WHERE id = ?`,
+		j.State, j.Completed, j.Failed, string(summary), unixNano(j.StartedAt), unixNano(j.FinishedAt), j.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%q: %w", j.ID, ErrNotFound)
+	}
+	if len(results) > 0 {
+		stmt, err := tx.Prepare(`INSERT OR REPLACE INTO results (job_id, idx, result) VALUES (?, ?, ?)`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for _, r := range results {
+			body, err := json.Marshal(r.Result)
+			if err != nil {
+				return err
+			}
+			if _, err := stmt.Exec(j.ID, r.Index, string(body)); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// Checked returns the input indexes of j's saved results.
+func (s *Store) Checked(id string) (map[int]bool, error) {
+	db, err := s.handle()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(`SELECT idx FROM results WHERE job_id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	checked := map[int]bool{}
+	for rows.Next() {
+		var i int
+		if err := rows.Scan(&i); err != nil {
+			return nil, err
+		}
+		checked[i] = true
+	}
+	return checked, rows.Err()
+}
+
+// Results returns up to limit of the job's results in input order,
+// starting at offset.
+func (s *Store) Results(id string, offset, limit int) ([]checker.Result, error) {
+	db, err := s.handle()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(`SELECT result FROM results WHERE job_id = ? AND idx >= ? ORDER BY idx LIMIT ?`, id, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	results := []checker.Result{}
+	for rows.Next() {
+		var body string
+		if err := rows.Scan(&body); err != nil {
+			return nil, err
+		}
+		var r checker.Result
+		if err := json.Unmarshal([]byte(body), &r); err != nil {
+			return nil, fmt.Errorf("job %s: invalid result: %w", id, err)
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// Delete removes a job and its results, or returns ErrNotFound.
+func (s *Store) Delete(id string) error {
+	n, err := s.delete(`id = ?`, id)
+	if err == nil && n == 0 {
+		return fmt.Errorf("%q: %w", id, ErrNotFound)
+	}
+	return err
+}
+
+// Prune removes the jobs finished before cutoff, with their results, and
+// returns how many were removed.
+func (s *Store) Prune(cutoff time.Time) (int, error) {
+	return s.delete(`state = ? AND finished_at < ?`, Done, cutoff.UnixNano())
+}
+
+// delete removes the jobs matching where, with their results.
+func (s *Store) delete(where string, args ...any) (int, error) {
+	db, err := s.handle()
+	if err != nil {
+		return 0, err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM results WHERE job_id IN (SELECT id FROM jobs WHERE `+where+`)`, args...); err != nil {
+		return 0, err
+	}
+	res, err := tx.Exec(`DELETE FROM jobs WHERE `+where, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return int(n), tx.Commit()
+}
+
+// ValidState reports whether state is one of States.
+func ValidState(state string) bool {
+	for _, s := range States {
+		if strings.EqualFold(s, state) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package jobqueue_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/jobqueue"
+)
+
+func TestStore_SaveResumeAndPrune(t *testing.T) {
+	s := jobqueue.New(t.TempDir())
+	defer s.Close()
+	created := time.Now().Add(-time.Hour).UTC()
+	j := &jobqueue.Job{ID: "a", State: jobqueue.Queued, Postcodes: []string{"SW1A1AA", "EC1A1BB", "LS11AA"}, Options: checker.CheckOptions{Year: "2023"}, CreatedAt: created}
+	if err := s.Create(j); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Create(&jobqueue.Job{ID: "b", State: jobqueue.Queued, Postcodes: []string{"SW1A1AA"}, CreatedAt: created.Add(time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+
+	j.State, j.StartedAt, j.Completed = jobqueue.Running, time.Now().UTC(), 2
+	saved := []checker.Indexed{{Index: 2, Result: checker.Result{Postcode: "LS11AA"}}, {Index: 0, Result: checker.Result{Postcode: "SW1A1AA"}}}
+	if err := s.Save(j, saved); err != nil {
+		t.Fatal(err)
+	}
+	unfinished, err := s.Unfinished()
+	if err != nil || len(unfinished) != 2 || unfinished[0].ID != "a" {
+		t.Fatalf("expected both jobs unfinished, oldest first, got %v (err %v)", unfinished, err)
+	}
+	got := unfinished[0]
+	if got.State != jobqueue.Running || got.Total != 3 || got.Completed != 2 || len(got.Postcodes) != 3 || got.Options.Year != "2023" {
+		t.Errorf("unexpected job %+v", got)
+	}
+	if checked, err := s.Checked("a"); err != nil || len(checked) != 2 || !checked[0] || !checked[2] {
+		t.Errorf("expected indexes 0 and 2 checked, got %v (err %v)", checked, err)
+	}
+	if results, err := s.Results("a", 1, 10); err != nil || len(results) != 1 || results[0].Postcode != "LS11AA" {
+		t.Errorf("expected LS11AA from offset 1, got %v (err %v)", results, err)
+	}
+
+	j.State, j.FinishedAt = jobqueue.Done, time.Now().Add(-time.Minute).UTC()
+	if err := s.Save(j, nil); err != nil {
+		t.Fatal(err)
+	}
+	if list, err := s.List(jobqueue.Done, 10); err != nil || len(list) != 1 || list[0].ID != "a" || list[0].Postcodes != nil {
+		t.Errorf("expected job a listed as done without postcodes, got %v (err %v)", list, err)
+	}
+	if list, err := s.List("", 10); err != nil || len(list) != 2 || list[0].ID != "b" {
+		t.Errorf("expected both jobs, newest first, got %v (err %v)", list, err)
+	}
+
+	if n, err := s.Prune(time.Now().Add(-time.Hour)); err != nil || n != 0 {
+		t.Errorf("expected nothing pruned within retention, got %d (err %v)", n, err)
+	}
+	if n, err := s.Prune(time.Now()); err != nil || n != 1 {
+		t.Errorf("expected the finished job pruned, got %d (err %v)", n, err)
+	}
+	if _, err := s.Get("a"); !errors.Is(err, jobqueue.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a pruned job, got %v", err)
+	}
+	if err := s.Save(j, saved); !errors.Is(err, jobqueue.ErrNotFound) {
+		t.Errorf("expected saving a pruned job to fail with ErrNotFound, got %v", err)
+	}
+	if results, _ := s.Results("a", 0, 10); len(results) != 0 {
+		t.Errorf("expected a pruned job's results gone, got %v", results)
+	}
+	if err := s.Delete("b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("b"); !errors.Is(err, jobqueue.ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+// Format is how a watch's notifications are written.
+type Format string
+
+const (
+	// FormatJSON POSTs the Notification itself, for custom receivers.
+	FormatJSON Format = "json"
+	// FormatSlack POSTs a Block Kit message to a Slack incoming webhook.
+	FormatSlack Format = "slack"
+	// FormatTeams POSTs an Adaptive Card to a Microsoft Teams incoming
+	// webhook or workflow.
+	FormatTeams Format = "teams"
+)
+
+// ParseFormat parses json, slack or teams. An empty s detects the format
+// from webhook with DetectFormat.
+func ParseFormat(s, webhook string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case "", "auto":
+		return DetectFormat(webhook), nil
+	case FormatJSON, FormatSlack, FormatTeams:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown webhook format %q: use json, slack or teams", s)
+}
+
+// DetectFormat recognises Slack and Teams incoming webhook URLs, and
+// returns FormatJSON for any other.
+func DetectFormat(webhook string) Format {
+	u, err := url.Parse(webhook)
+	if err != nil {
+		return FormatJSON
+	}
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case host == "hooks.slack.com":
+		return FormatSlack
+	case host == "outlook.office.com", strings.HasSuffix(host, ".webhook.office.com"), strings.HasSuffix(host, ".logic.azure.com"):
+		return FormatTeams
+	}
+	return FormatJSON
+}
+
+// encode writes n as f's payload.
+func (f Format) encode(n Notification) ([]byte, error) {
+	switch f {
+	case FormatSlack:
+		return json.Marshal(slackMessage(n))
+	case FormatTeams:
+		return json.Marshal(teamsMessage(n))
+	}
+	return json.Marshal(n)
+}
+
+// title summarises n in a line, e.g. "Coverage changed at SW1A1AA: 1
+// gained, 1 lost".
+func title(n Notification) string {
+	var gained, lost int
+	for _, c := range n.Changes {
+		if c.Gained {
+			gained++
+		}
+		if c.Lost {
+			lost++
+		}
+	}
+	t := "Coverage changed at " + n.Postcode
+	var parts []string
+	if gained > 0 {
+		parts = append(parts, fmt.Sprintf("%d gained", gained))
+	}
+	if lost > 0 {
+		parts = append(parts, fmt.Sprintf("%d lost", lost))
+	}
+	if len(parts) > 0 {
+		t += ": " + strings.Join(parts, ", ")
+	}
+	return t
+}
+
+// changeLine describes c, e.g. "EE 5G: 0% → 80% (gained)".
+func changeLine(c ofcom.Change) string {
+	return changeName(c) + ": " + changeValue(c)
+}
+
+// changeName is c's operator and technology, e.g. "EE 5G".
+func changeName(c ofcom.Change) string {
+	return c.Operator + " " + strings.ToUpper(c.Technology)
+}
+
+// changeValue describes c's coverage before and after.
+func changeValue(c ofcom.Change) string {
+	s := c.From + " → " + c.To
+	switch {
+	case c.Gained:
+		s += " (gained)"
+	case c.Lost:
+		s += " (lost)"
+	}
+	return s
+}
+
+// datasets names the datasets compared and when.
+func datasets(n Notification) string {
+	return fmt.Sprintf("Ofcom dataset %s → %s, checked %s", n.PreviousDataset, n.Dataset, n.CheckedAt)
+}
+
+// slackMessage is n as a Slack Block Kit message. Text is the fallback
+// shown in notifications.
+func slackMessage(n Notification) map[string]any {
+	lines := make([]string, len(n.Changes))
+	for i, c := range n.Changes {
+		lines[i] = "• " + changeLine(c)
+	}
+	return map[string]any{
+		"text": title(n),
+		"blocks": []any{
+			map[string]any{"type": "header", "text": map[string]any{"type": "plain_text", "text": title(n)}},
+			map[string]any{"type": "section", "text": map[string]any{"type": "mrkdwn", "text": strings.Join(lines, "\n")}},
+			map[string]any{"type": "context", "elements": []any{map[string]any{"type": "mrkdwn", "text": datasets(n)}}},
+		},
+	}
+}
+
+// teamsMessage is n as an Adaptive Card message for Teams.
+func teamsMessage(n Notification) map[string]any {
+	facts := make([]any, len(n.Changes))
+	for i, c := range n.Changes {
+		facts[i] = map[string]any{"title": changeName(c), "value": changeValue(c)}
+	}
+	return map[string]any{
+		"type": "message",
+		"attachments": []any{map[string]any{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]any{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body": []any{
+					map[string]any{"type": "TextBlock", "text": title(n), "size": "Large", "weight": "Bolder", "wrap": true},
+					map[string]any{"type": "FactSet", "facts": facts},
+					map[string]any{"type": "TextBlock", "text": datasets(n), "isSubtle": true, "size": "Small", "wrap": true},
+				},
+			},
+		}},
+	}
+}
@@ -0,0 +1,332 @@
+// Package monitor watches postcodes for coverage changes between Ofcom
+// dataset builds and notifies a webhook with the differences.
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+// Watch is a postcode monitored for coverage changes.
+type Watch struct {
+	ID        int64                `json:"id"`
+	Postcode  string               `json:"postcode"`
+	Webhook   string               `json:"webhook"`
+	Format    Format               `json:"format"`
+	Dataset   string               `json:"dataset,omitempty"` // dataset version of the stored snapshot
+	Summary   *ofcom.MobileSummary `json:"summary,omitempty"`
+	CreatedAt string               `json:"created_at"`
+}
+
+// Notification is the JSON payload POSTed to a webhook when coverage
+// changes, for watches in FormatJSON.
+type Notification struct {
+	Postcode        string         `json:"postcode"`
+	PreviousDataset string         `json:"previous_dataset"`
+	Dataset         string         `json:"dataset"`
+	Changes         []ofcom.Change `json:"changes"`
+	CheckedAt       string         `json:"checked_at"`
+}
+
+// Monitor manages watched postcodes stored in monitor.db.
+type Monitor struct {
+	DBPath string
+	ofcom  *ofcom.Manager
+	http   *http.Client
+	logger *slog.Logger
+
+	ofcomOpts []ofcom.Option // for the Ofcom database, after the logger
+}
+
+// Option configures a Monitor.
+type Option func(*Monitor)
+
+// WithLogger sets the logger used for run diagnostics.
+func WithLogger(l *slog.Logger) Option {
+	return func(m *Monitor) { m.logger = l }
+}
+
+// WithEncryptionKey reads an encrypted Ofcom database; see
+// ofcom.WithEncryptionKey.
+func WithEncryptionKey(passphrase string) Option {
+	return func(m *Monitor) { m.ofcomOpts = append(m.ofcomOpts, ofcom.WithEncryptionKey(passphrase)) }
+}
+
+// New creates a Monitor using the Ofcom database in dataDir.
+func New(dataDir string, opts ...Option) *Monitor {
+	m := &Monitor{
+		DBPath: filepath.Join(dataDir, "monitor.db"),
+		http:   &http.Client{Timeout: 10 * time.Second},
+		logger: slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	m.ofcom = ofcom.NewManager(dataDir, append([]ofcom.Option{ofcom.WithLogger(m.logger)}, m.ofcomOpts...)...)
+	return m
+}
+
+func (m *Monitor) open() (*sql.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(m.DBPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+	d := ofcom.DefaultDriver
+	db, err := sql.Open(d.Name, d.DSN(m.DBPath, false))
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS watches (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		postcode TEXT NOT NULL,
+		webhook TEXT NOT NULL,
+		dataset TEXT,
+		summary TEXT,
+		created_at TEXT NOT NULL,
+		UNIQUE (postcode, webhook)
+	)`)
+	if err == nil {
+		err = addFormatColumn(db)
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// addFormatColumn adds watches.format to a monitor.db from before
+// formats. Its watches get an empty format, detected from their webhooks.
+func addFormatColumn(db *sql.DB) error {
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('watches') WHERE name = 'format'`).Scan(&n); err != nil || n > 0 {
+		return err
+	}
+	_, err := db.Exec(`ALTER TABLE watches ADD COLUMN format TEXT NOT NULL DEFAULT ''`)
+	return err
+}
+
+// Add watches a postcode, snapshotting its current coverage as the
+// baseline. Notifications are written in format, or the format detected
+// from webhook when it is empty.
+func (m *Monitor) Add(pc, webhook string, format Format) (*Watch, error) {
+	if format == "" {
+		format = DetectFormat(webhook)
+	}
+	w := &Watch{
+		Postcode:  postcode.Normalise(pc),
+		Webhook:   webhook,
+		Format:    format,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	if version, err := m.datasetVersion(); err == nil {
+		if summary, err := m.snapshot(w.Postcode); err == nil {
+			w.Dataset, w.Summary = version, summary
+		}
+	}
+
+	db, err := m.open()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	summary, err := encodeSummary(w.Summary)
+	if err != nil {
+		return nil, err
+	}
+	res, err := db.Exec(`INSERT INTO watches (postcode, webhook, format, dataset, summary, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		w.Postcode, w.Webhook, string(w.Format), w.Dataset, summary, w.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add watch: %w", err)
+	}
+	w.ID, _ = res.LastInsertId()
+	return w, nil
+}
+
+// Remove stops watching a postcode and returns the number of watches removed.
+func (m *Monitor) Remove(pc string) (int64, error) {
+	db, err := m.open()
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	res, err := db.Exec(`DELETE FROM watches WHERE postcode = ?`, postcode.Normalise(pc))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// List returns all watches.
+func (m *Monitor) List() ([]Watch, error) {
+	db, err := m.open()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT id, postcode, webhook, format, COALESCE(dataset, ''), COALESCE(summary, ''), created_at FROM watches ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var watches []Watch
+	for rows.Next() {
+		var w Watch
+		var summary string
+		if err := rows.Scan(&w.ID, &w.Postcode, &w.Webhook, &w.Format, &w.Dataset, &summary, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		if w.Format == "" {
+			w.Format = DetectFormat(w.Webhook)
+		}
+		if summary != "" {
+			w.Summary = &ofcom.MobileSummary{}
+			if err := json.Unmarshal([]byte(summary), w.Summary); err != nil {
+				return nil, fmt.Errorf("corrupt snapshot for watch %d: %w", w.ID, err)
+			}
+		}
+		watches = append(watches, w)
+	}
+	return watches, rows.Err()
+}
+
+// RunOnce re-checks every watch whose snapshot predates the installed
+// dataset and notifies webhooks of any changes. It returns the number of
+// notifications sent.
+func (m *Monitor) RunOnce() (int, error) {
+	version, err := m.datasetVersion()
+	if err != nil {
+		return 0, err
+	}
+	watches, err := m.List()
+	if err != nil {
+		return 0, err
+	}
+
+	db, err := m.open()
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	sent := 0
+	for _, w := range watches {
+		if w.Dataset == version {
+			continue
+		}
+		current, err := m.snapshot(w.Postcode)
+		if err != nil {
+			m.logger.Warn("re-check failed", "postcode", w.Postcode, "err", err)
+			continue
+		}
+
+		if w.Summary != nil {
+			if changes := ofcom.Changes(*w.Summary, *current); len(changes) > 0 {
+				n := Notification{
+					Postcode:        w.Postcode,
+					PreviousDataset: w.Dataset,
+					Dataset:         version,
+					Changes:         changes,
+					CheckedAt:       time.Now().UTC().Format(time.RFC3339),
+				}
+				if err := m.notify(w.Webhook, w.Format, n); err != nil {
+					// Leave the snapshot untouched so the next run retries.
+					m.logger.Warn("webhook failed", "postcode", w.Postcode, "webhook", w.Webhook, "err", err)
+					continue
+				}
+				sent++
+			}
+		}
+
+		summary, err := encodeSummary(current)
+		if err != nil {
+			return sent, err
+		}
+		if _, err := db.Exec(`UPDATE watches SET dataset = ?, summary = ? WHERE id = ?`, version, summary, w.ID); err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// Run calls RunOnce every interval until ctx is cancelled.
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		sent, err := m.RunOnce()
+		if err != nil {
+			m.logger.Error("monitor check failed", "err", err)
+		} else if sent > 0 {
+			m.logger.Info("sent coverage change notifications", "count", sent)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// datasetVersion identifies the installed Ofcom build, changing whenever
+// setup ingests a new edition.
+func (m *Monitor) datasetVersion() (string, error) {
+	meta, err := m.ofcom.Meta()
+	if err != nil {
+		return "", err
+	}
+	return meta["dataset_year"] + "@" + meta["built_at"], nil
+}
+
+func (m *Monitor) snapshot(pc string) (*ofcom.MobileSummary, error) {
+	row, err := m.ofcom.QueryPostcode(pc)
+	if err != nil {
+		return nil, err
+	}
+	if row == nil {
+		row = map[string]string{"postcode": pc}
+	}
+	summary := ofcom.Interpret(row)
+	return &summary, nil
+}
+
+func (m *Monitor) notify(webhook string, format Format, n Notification) error {
+	body, err := format.encode(n)
+	if err != nil {
+		return err
+	}
+	resp, err := m.http.Post(webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("HTTP %d from webhook", resp.StatusCode)
+	}
+	return nil
+}
+
+func encodeSummary(s *ofcom.MobileSummary) (interface{}, error) {
+	if s == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
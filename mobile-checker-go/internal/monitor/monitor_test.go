@@ -0,0 +1,132 @@
+package monitor_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/internal/monitor"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+func buildDataset(t *testing.T, dir, csv string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(filepath.Join(dir, "mobile.db"))
+	if err := ofcom.NewManager(dir).Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+}
+
+func TestRunOnce_NotifiesOnChange(t *testing.T) {
+	var got []monitor.Notification
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n monitor.Notification
+		if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+			t.Errorf("bad payload: %v", err)
+		}
+		got = append(got, n)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	buildDataset(t, dir, "postcode,ee_4g,ee_5g\nSW1A1AA,1.0,0.0\n")
+
+	m := monitor.New(dir)
+	if _, err := m.Add("sw1a 1aa", srv.URL, ""); err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+
+	// Same dataset: nothing to report.
+	if sent, err := m.RunOnce(); err != nil || sent != 0 {
+		t.Fatalf("expected 0 notifications, got %d (err %v)", sent, err)
+	}
+
+	buildDataset(t, dir, "postcode,ee_4g,ee_5g\nSW1A1AA,1.0,0.8\n")
+	sent, err := m.RunOnce()
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if sent != 1 || len(got) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(got))
+	}
+	changes := got[0].Changes
+	if len(changes) != 1 || changes[0].Operator != "EE" || changes[0].Technology != "5g" || !changes[0].Gained {
+		t.Errorf("unexpected changes: %+v", changes)
+	}
+
+	// Snapshot was updated, so a further run is quiet.
+	if sent, err := m.RunOnce(); err != nil || sent != 0 {
+		t.Errorf("expected 0 notifications after update, got %d (err %v)", sent, err)
+	}
+}
+
+func TestRunOnce_FormatsSlackAndTeams(t *testing.T) {
+	bodies := map[string]map[string]any{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("bad payload: %v", err)
+		}
+		bodies[r.URL.Path] = body
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	buildDataset(t, dir, "postcode,ee_4g,ee_5g\nSW1A1AA,1.0,0.0\n")
+	m := monitor.New(dir)
+	for path, f := range map[string]monitor.Format{"/slack": monitor.FormatSlack, "/teams": monitor.FormatTeams} {
+		if _, err := m.Add("SW1A1AA", srv.URL+path, f); err != nil {
+			t.Fatalf("add failed: %v", err)
+		}
+	}
+	buildDataset(t, dir, "postcode,ee_4g,ee_5g\nSW1A1AA,1.0,0.8\n")
+	if sent, err := m.RunOnce(); err != nil || sent != 2 {
+		t.Fatalf("expected 2 notifications, got %d (err %v)", sent, err)
+	}
+
+	slack := bodies["/slack"]
+	if slack["text"] != "Coverage changed at SW1A1AA: 1 gained" || len(slack["blocks"].([]any)) != 3 {
+		t.Errorf("unexpected Slack message %v", slack)
+	}
+	section := slack["blocks"].([]any)[1].(map[string]any)["text"].(map[string]any)
+	if section["text"] != "• EE 5G: 0% → 80% (gained)" {
+		t.Errorf("unexpected Slack changes %q", section["text"])
+	}
+
+	teams := bodies["/teams"]
+	card := teams["attachments"].([]any)[0].(map[string]any)
+	if teams["type"] != "message" || card["contentType"] != "application/vnd.microsoft.card.adaptive" {
+		t.Fatalf("unexpected Teams message %v", teams)
+	}
+	facts := card["content"].(map[string]any)["body"].([]any)[1].(map[string]any)["facts"].([]any)
+	if fact := facts[0].(map[string]any); len(facts) != 1 || fact["title"] != "EE 5G" || fact["value"] != "0% → 80% (gained)" {
+		t.Errorf("unexpected Teams facts %v", facts)
+	}
+}
+
+func TestParseFormat_DetectsWebhooks(t *testing.T) {
+	for _, tc := range []struct {
+		format, webhook string
+		want            monitor.Format
+	}{
+		{"", "https://hooks.slack.com/services/T000/B000/XXXX", monitor.FormatSlack},
+		{"", "https://contoso.webhook.office.com/webhookb2/abc", monitor.FormatTeams},
+		{"auto", "https://prod-01.westeurope.logic.azure.com/workflows/abc", monitor.FormatTeams},
+		{"", "https://example.com/hooks/coverage", monitor.FormatJSON},
+		{"Slack", "https://example.com/relay", monitor.FormatSlack},
+		{"json", "https://hooks.slack.com/services/T000/B000/XXXX", monitor.FormatJSON},
+	} {
+		if got, err := monitor.ParseFormat(tc.format, tc.webhook); err != nil || got != tc.want {
+			t.Errorf("ParseFormat(%q, %q) = %q, %v; want %q", tc.format, tc.webhook, got, err, tc.want)
+		}
+	}
+	if _, err := monitor.ParseFormat("discord", ""); err == nil {
+		t.Error("expected an unknown format to be refused")
+	}
+}
@@ -0,0 +1,370 @@
+package ofcom
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Building the database is a pipeline: one goroutine reads the CSV in
+// batches, parser workers turn the records into insert arguments, and the
+// caller writes the parsed batches in file order, many rows to an INSERT
+// and many INSERTs to a transaction. File order matters: a postcode listed
+// twice keeps its last row, as it did when rows were inserted one by one.
+
+const (
+	// buildBatch is how many records pass through the pipeline together.
+	buildBatch = 4096
+	// rowsPerInsert is how many rows one INSERT carries. Even a full
+	// build's columns stay well under SQLite's 32766 parameters.
+	rowsPerInsert = 16
+	// commitEvery is how many rows are inserted per transaction.
+	commitEvery = 250000
+)
+
+// buildWorkers is the number of parser workers.
+var buildWorkers = runtime.GOMAXPROCS(0)
+
+// tuneForBuild sets pragmas for bulk loading a new database file. The file
+// is built beside the live database and renamed into place only once
+// complete, so a crash mid-build loses nothing worth a journal or fsyncs.
+// Pragmas apply per connection, so the build is held to one.
+func tuneForBuild(db *sql.DB) error {
+	db.SetMaxOpenConns(1)
+	for _, p := range []string{
+		"PRAGMA journal_mode=OFF",
+		"PRAGMA synchronous=OFF",
+		"PRAGMA cache_size=-65536", // 64 MiB
+		"PRAGMA temp_store=MEMORY",
+	} {
+		if _, err := db.Exec(p); err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// recordBatch is a run of CSV records, numbered in file order.
+type recordBatch struct {
+	seq     int
+	records [][]string
+}
+
+// rowBatch is a recordBatch parsed into insert arguments, less the rows
+// left out; skipped counts those outside the selected nations.
+type rowBatch struct {
+	seq     int
+	rows    [][]any
+	skipped int
+}
+
+// insertRows stores the rows of an Ofcom CSV in the mobile table, replacing
+// any already stored for the same postcodes. Rows of a nation layer are
+// kept only for postcodes in that nation and marked with it in the source
+// column; source is "" for the UK-wide file. It returns the rows stored and
+// those skipped as outside opts.Nations. Malformed records are skipped.
+func (m *Manager) insertRows(db *sql.DB, r io.Reader, edition Edition, opts SetupOptions, source string) (count, skipped int, err error) {
+	reader := csv.NewReader(r)
+	headers, err := reader.Read()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read CSV headers: %w", err)
+	}
+	for i, h := range headers {
+		headers[i] = normaliseHeader(strings.TrimPrefix(h, "\ufeff"))
+	}
+
+	detected, mapping, unknown, err := edition.Detect(headers)
+	if err != nil {
+		return 0, 0, err
+	}
+	if detected.Year != edition.Year {
+		m.Logger.Info("using the column layout of another edition", "year", edition.Year, "layout", detected.Year)
+	}
+	edition = detected
+	if len(unknown) > 0 {
+		m.Logger.Warn("ignoring unrecognised columns", "count", len(unknown), "columns", strings.Join(unknown, ", "))
+	}
+
+	stored, err := ColumnSet(opts.Columns)
+	if err != nil {
+		return 0, 0, err
+	}
+	p := rowParser{opts: opts, source: source, scale: 1}
+	for i, col := range mapping {
+		if col == "postcode" || slices.Contains(stored, col) {
+			p.cols = append(p.cols, col)
+			p.idx = append(p.idx, i)
+		}
+	}
+	if edition.Percent {
+		p.scale = 0.01
+	}
+	p.pcCol = slices.Index(p.cols, "postcode")
+	insertCols := p.cols
+	if source != "" {
+		insertCols = append(slices.Clip(p.cols), "source")
+	}
+
+	w, err := m.newBatchWriter(db, insertCols)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer w.abort()
+
+	// done stops the reader and workers if the writer fails.
+	done := make(chan struct{})
+	defer close(done)
+	records := make(chan recordBatch, buildWorkers)
+	parsed := make(chan rowBatch, buildWorkers)
+	var readErr error
+	go func() {
+		readErr = readBatches(reader, records, done)
+		close(records)
+	}()
+	var wg sync.WaitGroup
+	for i := 0; i < buildWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range records {
+				select {
+				case parsed <- p.parse(b):
+				case <-done:
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(parsed)
+	}()
+
+	// Workers finish out of order; hold batches back until their turn.
+	pending := map[int]rowBatch{}
+	next := 0
+	for b := range parsed {
+		pending[b.seq] = b
+		for {
+			b, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			skipped += b.skipped
+			for _, row := range b.rows {
+				if err := w.add(row); err != nil {
+					return w.count, skipped, err
+				}
+			}
+			opts.progress("build", int64(w.count), 0)
+		}
+	}
+	if readErr != nil {
+		return w.count, skipped, readErr
+	}
+	err = w.finish()
+	return w.count, skipped, err
+}
+
+// readBatches reads r's records into batches of buildBatch until the end
+// of the file or done is closed. Records that fail to parse, e.g. with the
+// wrong number of fields, are skipped; other errors end the read.
+func readBatches(r *csv.Reader, out chan<- recordBatch, done <-chan struct{}) error {
+	for seq := 0; ; seq++ {
+		batch := make([][]string, 0, buildBatch)
+		eof := false
+		for len(batch) < buildBatch {
+			rec, err := r.Read()
+			if err == io.EOF {
+				eof = true
+				break
+			}
+			if err != nil {
+				var pe *csv.ParseError
+				if errors.As(err, &pe) {
+					continue
+				}
+				return fmt.Errorf("failed to read CSV: %w", err)
+			}
+			batch = append(batch, rec)
+		}
+		if len(batch) > 0 {
+			select {
+			case out <- recordBatch{seq, batch}:
+			case <-done:
+				return nil
+			}
+		}
+		if eof {
+			return nil
+		}
+	}
+}
+
+// rowParser turns CSV records into insert arguments.
+type rowParser struct {
+	cols   []string // canonical columns stored, in insert order
+	idx    []int    // the CSV field holding each of cols
+	pcCol  int      // the index of postcode in cols
+	scale  float64  // 0.01 for editions giving percentages
+	source string
+	opts   SetupOptions
+}
+
+// parse converts a batch of records. Postcodes are normalised and values
+// converted to fractions; values that are not numbers are stored as NULL.
+func (p rowParser) parse(b recordBatch) rowBatch {
+	width := len(p.cols)
+	if p.source != "" {
+		width++
+	}
+	out := rowBatch{seq: b.seq, rows: make([][]any, 0, len(b.records))}
+	vals := make([]any, len(b.records)*width)
+	for _, record := range b.records {
+		args := vals[:width:width]
+		vals = vals[width:]
+		for j, i := range p.idx {
+			if i >= len(record) {
+				continue
+			}
+			v := strings.TrimSpace(record[i])
+			if p.cols[j] == "postcode" {
+				args[j] = strings.ToUpper(strings.ReplaceAll(v, " ", ""))
+				continue
+			}
+			f, err := strconv.ParseFloat(strings.TrimSuffix(v, "%"), 64)
+			if err != nil {
+				continue
+			}
+			args[j] = f * p.scale
+		}
+		pc, _ := args[p.pcCol].(string)
+		if p.source != "" {
+			if NationOf(pc) != p.source {
+				continue
+			}
+			args[len(p.cols)] = p.source
+		}
+		if !p.opts.keepsPostcode(pc) {
+			out.skipped++
+			continue
+		}
+		out.rows = append(out.rows, args)
+	}
+	return out
+}
+
+// batchWriter inserts rows into the mobile table rowsPerInsert at a time,
+// committing every commitEvery rows.
+type batchWriter struct {
+	m         *Manager
+	db        *sql.DB
+	tx        *sql.Tx
+	one, many *sql.Stmt // INSERTs of one row and of rowsPerInsert rows
+	oneSQL    string
+	manySQL   string
+	buf       [][]any
+	args      []any
+	count     int // rows inserted
+	inTx      int // rows inserted in the current transaction
+}
+
+func (m *Manager) newBatchWriter(db *sql.DB, cols []string) (*batchWriter, error) {
+	row := "(" + strings.TrimRight(strings.Repeat("?,", len(cols)), ",") + ")"
+	insert := fmt.Sprintf(`INSERT OR REPLACE INTO mobile (%s) VALUES `, strings.Join(cols, ", "))
+	w := &batchWriter{
+		m:       m,
+		db:      db,
+		oneSQL:  insert + row,
+		manySQL: insert + strings.TrimRight(strings.Repeat(row+",", rowsPerInsert), ","),
+		buf:     make([][]any, 0, rowsPerInsert),
+		args:    make([]any, 0, rowsPerInsert*len(cols)),
+	}
+	if err := w.begin(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *batchWriter) begin() error {
+	tx, err := w.db.Begin()
+	if err != nil {
+		return err
+	}
+	if w.one, err = tx.Prepare(w.oneSQL); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if w.many, err = tx.Prepare(w.manySQL); err != nil {
+		tx.Rollback()
+		return err
+	}
+	w.tx, w.inTx = tx, 0
+	return nil
+}
+
+// add queues a row, inserting the queue once it is full.
+func (w *batchWriter) add(row []any) error {
+	w.buf = append(w.buf, row)
+	if len(w.buf) < rowsPerInsert {
+		return nil
+	}
+	w.args = w.args[:0]
+	for _, r := range w.buf {
+		w.args = append(w.args, r...)
+	}
+	if _, err := w.many.Exec(w.args...); err == nil {
+		w.inserted(len(w.buf))
+	} else {
+		// Find the rows at fault: insert one at a time, skipping them.
+		w.flushEach()
+	}
+	w.buf = w.buf[:0]
+	if w.inTx < commitEvery {
+		return nil
+	}
+	if err := w.tx.Commit(); err != nil {
+		w.tx = nil
+		return err
+	}
+	w.m.Logger.Info("inserted rows", "count", w.count)
+	return w.begin()
+}
+
+// flushEach inserts the queued rows one at a time, skipping any that fail.
+func (w *batchWriter) flushEach() {
+	for _, r := range w.buf {
+		if _, err := w.one.Exec(r...); err == nil {
+			w.inserted(1)
+		}
+	}
+}
+
+func (w *batchWriter) inserted(n int) {
+	w.count += n
+	w.inTx += n
+}
+
+// finish inserts the rows still queued and commits.
+func (w *batchWriter) finish() error {
+	w.flushEach()
+	w.buf = w.buf[:0]
+	tx := w.tx
+	w.tx = nil
+	return tx.Commit()
+}
+
+// abort rolls back the open transaction, if any.
+func (w *batchWriter) abort() {
+	if w.tx != nil {
+		w.tx.Rollback()
+	}
+}
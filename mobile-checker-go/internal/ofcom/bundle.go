@@ -0,0 +1,98 @@
+package ofcom
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Bundle writes a compacted, self-contained copy of the installed database
+// to out, suitable for embedding in a binary (see package bundle). The
+// copy is never encrypted: an encrypted database is written from its
+// decrypted working copy (see Unsealed), and InstallBundle encrypts it
+// again with the installing Manager's key.
+func (m *Manager) Bundle(out string) error {
+	if _, err := os.Stat(m.DBPath); os.IsNotExist(err) {
+		return ErrDatabaseNotFound
+	}
+	if _, err := os.Stat(out); err == nil {
+		return fmt.Errorf("%s already exists", out)
+	}
+	if dir := filepath.Dir(out); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	if m.sealed != nil {
+		m.Logger.Warn("the bundle is not encrypted", "path", out)
+	}
+	return m.Unsealed(func(w *Manager) error {
+		// Encrypted databases are read through a read-only VFS, which
+		// VACUUM INTO cannot write from.
+		db, err := w.open(w.DBPath, true)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		if _, err := db.Exec(`VACUUM INTO ?`, out); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+		m.Logger.Info("wrote dataset bundle", "path", out)
+		return nil
+	})
+}
+
+func (m *Manager) bundleStampPath() string {
+	return filepath.Join(m.DataDir, "bundle.sha256")
+}
+
+// InstallBundle writes an embedded database into the data directory so it
+// can be opened like one built by Setup. It does nothing if a database built
+// by Setup is already present, and replaces one installed from a different
+// bundle. It reports whether the database was written.
+func (m *Manager) InstallBundle(data []byte) (bool, error) {
+	if len(data) == 0 {
+		return false, nil
+	}
+	sum := ""
+	if _, err := os.Stat(m.DBPath); err == nil {
+		stamp, err := os.ReadFile(m.bundleStampPath())
+		if err != nil {
+			return false, nil // built by Setup
+		}
+		if sum = sha256Hex(data); strings.TrimSpace(string(stamp)) == sum {
+			return false, nil
+		}
+	}
+	if !bytes.HasPrefix(data, []byte("SQLite format 3\x00")) {
+		return false, fmt.Errorf("embedded bundle is not an SQLite database")
+	}
+
+	if err := os.MkdirAll(m.DataDir, 0755); err != nil {
+		return false, fmt.Errorf("failed to create data directory: %w", err)
+	}
+	tmp := m.DBPath + ".partial"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return false, err
+	}
+	if m.sealed != nil {
+		if err := m.sealed.seal(tmp); err != nil {
+			os.Remove(tmp)
+			return false, err
+		}
+	}
+	if err := os.Rename(tmp, m.DBPath); err != nil {
+		os.Remove(tmp)
+		return false, err
+	}
+	if sum == "" {
+		sum = sha256Hex(data)
+	}
+	if err := os.WriteFile(m.bundleStampPath(), []byte(sum+"\n"), 0644); err != nil {
+		return false, err
+	}
+	m.Logger.Info("installed embedded dataset", "path", m.DBPath, "sha256", sum)
+	return true, nil
+}
@@ -0,0 +1,103 @@
+package ofcom
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrCurrentYear is returned by RemoveYear for the dataset year checks use
+// by default.
+var ErrCurrentYear = errors.New("the current dataset year cannot be removed — set up another year with --force first")
+
+// Dataset describes an installed dataset year.
+type Dataset struct {
+	Year     string `json:"year"`
+	Revision int    `json:"revision,omitempty"`
+	BuiltAt  string `json:"built_at,omitempty"`
+	// Current is true for the year checks use unless asked for another.
+	Current bool  `json:"current"`
+	Rows    int   `json:"rows"`
+	Size    int64 `json:"size_bytes"`
+}
+
+// Datasets lists the installed dataset years, oldest first.
+func (m *Manager) Datasets() ([]Dataset, error) {
+	current := m.installedYear(m.DBPath)
+	var out []Dataset
+	for _, year := range m.InstalledYears() {
+		path := m.DBPath
+		if year != current {
+			path = m.yearManager(year).DBPath
+		}
+		d, err := m.describe(path)
+		if err != nil {
+			return nil, err
+		}
+		d.Year, d.Current = year, year == current
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+// describe reads the size, row count and build metadata of the database
+// at path.
+func (m *Manager) describe(path string) (Dataset, error) {
+	var d Dataset
+	info, err := os.Stat(path)
+	if err != nil {
+		return d, err
+	}
+	d.Size = info.Size()
+	db, err := m.open(path, true)
+	if err != nil {
+		return d, err
+	}
+	defer db.Close()
+	if err := db.QueryRow(`SELECT COUNT(*) FROM mobile`).Scan(&d.Rows); err != nil {
+		return d, err
+	}
+	var rev string
+	if db.QueryRow(`SELECT value FROM meta WHERE key = 'dataset_revision'`).Scan(&rev) == nil {
+		d.Revision, _ = strconv.Atoi(rev)
+	}
+	db.QueryRow(`SELECT value FROM meta WHERE key = 'built_at'`).Scan(&d.BuiltAt)
+	return d, nil
+}
+
+// RemoveYear deletes an installed dataset year other than the current
+// one, with its downloaded CSV and any download left unfinished, to free
+// disk space.
+func (m *Manager) RemoveYear(year string) error {
+	if !ValidYear(year) {
+		return ErrYearNotInstalled
+	}
+	if m.installedYear(m.DBPath) == year {
+		return ErrCurrentYear
+	}
+	dir := m.yearDir(year)
+	if _, err := os.Stat(m.yearManager(year).DBPath); err != nil {
+		return ErrYearNotInstalled
+	}
+	m.yearsMu.Lock()
+	if ym, ok := m.years[year]; ok {
+		ym.Close()
+		delete(m.years, year)
+	}
+	m.yearsMu.Unlock()
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	// A year set up before another replaced it was downloaded into DataDir.
+	csvPath := filepath.Join(m.DataDir, fmt.Sprintf("ofcom_mobile_%s.csv", year))
+	for _, path := range []string{csvPath, csvPath + ".partial", strings.TrimSuffix(csvPath, ".csv") + ".zip.partial"} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	m.Logger.Info("removed dataset year", "year", year, "path", dir)
+	return nil
+}
@@ -0,0 +1,128 @@
+package ofcom
+
+import (
+	"math"
+	"strconv"
+)
+
+// Change describes a difference in one operator/technology between two summaries.
+type Change struct {
+	Operator   string `json:"operator"`
+	Technology string `json:"technology"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Gained     bool   `json:"gained,omitempty"`
+	Lost       bool   `json:"lost,omitempty"`
+}
+
+// Changes lists every operator/technology whose coverage differs between
+// old and new. Operators missing from either summary are ignored.
+func Changes(old, new MobileSummary) []Change {
+	prev := make(map[string]OperatorCoverage, len(old.Operators))
+	for _, op := range old.Operators {
+		prev[op.Name] = op
+	}
+
+	var changes []Change
+	for _, cur := range new.Operators {
+		was, ok := prev[cur.Name]
+		if !ok {
+			continue
+		}
+		techs := []struct {
+			name            string
+			from, to        string
+			hadIt, hasItNow bool
+		}{
+			{"voice", was.Voice, cur.Voice, was.HasVoice, cur.HasVoice},
+			{"4g", was.FourG, cur.FourG, was.HasFourG, cur.HasFourG},
+			{"5g", was.FiveG, cur.FiveG, was.HasFiveG, cur.HasFiveG},
+		}
+		for _, t := range techs {
+			if t.from == t.to && t.hadIt == t.hasItNow {
+				continue
+			}
+			changes = append(changes, Change{
+				Operator:   cur.Name,
+				Technology: t.name,
+				From:       t.from,
+				To:         t.to,
+				Gained:     !t.hadIt && t.hasItNow,
+				Lost:       t.hadIt && !t.hasItNow,
+			})
+		}
+	}
+	return changes
+}
+
+// CoverageDiff compares a postcode's coverage in two dataset years.
+type CoverageDiff struct {
+	Postcode  string         `json:"postcode" xml:"postcode"`
+	From      string         `json:"from" xml:"from"`
+	To        string         `json:"to" xml:"to"`
+	Indoor    bool           `json:"indoor" xml:"indoor"`
+	Operators []OperatorDiff `json:"operators" xml:"operators>operator"`
+}
+
+// OperatorDiff holds one operator's changes between two dataset years.
+type OperatorDiff struct {
+	Name         string     `json:"name" xml:"name"`
+	Technologies []TechDiff `json:"technologies" xml:"technologies>technology"`
+}
+
+// TechDiff is the change in one technology's coverage. Percentages are
+// 0–100 and a missing value counts as 0.
+type TechDiff struct {
+	Technology string  `json:"technology" xml:"technology"`
+	FromPct    float64 `json:"from_pct" xml:"from_pct"`
+	ToPct      float64 `json:"to_pct" xml:"to_pct"`
+	// ChangePP is ToPct − FromPct in percentage points.
+	ChangePP float64 `json:"change_pp" xml:"change_pp"`
+	Gained   bool    `json:"gained" xml:"gained"`
+	Lost     bool    `json:"lost" xml:"lost"`
+}
+
+// DiffRows compares two raw rows for the same postcode, one per dataset
+// year, for the operators and indoor/outdoor mode selected by opts.
+// Gained and Lost compare against opts.Threshold (CoverageThreshold when 0).
+func DiffRows(from, to map[string]string, opts InterpretOptions) []OperatorDiff {
+	threshold := opts.Threshold
+	if threshold <= 0 {
+		threshold = CoverageThreshold
+	}
+	value := func(row map[string]string, col string) float64 {
+		f, err := strconv.ParseFloat(row[col], 64)
+		if err != nil {
+			return 0
+		}
+		return f
+	}
+	round := func(f float64) float64 { return math.Round(f*1000) / 10 }
+
+	selected := Operators
+	if len(opts.Operators) > 0 {
+		selected = opts.Operators
+	}
+	suffix := ""
+	if opts.Indoor {
+		suffix = "_indoor"
+	}
+	diffs := make([]OperatorDiff, 0, len(selected))
+	for _, op := range selected {
+		d := OperatorDiff{Name: operatorNames[op]}
+		for _, tech := range []string{"voice", "4g", "5g"} {
+			col := op + "_" + tech + suffix
+			was, now := value(from, col), value(to, col)
+			d.Technologies = append(d.Technologies, TechDiff{
+				Technology: tech,
+				FromPct:    round(was),
+				ToPct:      round(now),
+				ChangePP:   round(now - was),
+				Gained:     was < threshold && now >= threshold,
+				Lost:       was >= threshold && now < threshold,
+			})
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs
+}
@@ -0,0 +1,162 @@
+package ofcom
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// LatestYear is the Setup year that selects the newest edition found by
+// Discover.
+const LatestYear = "latest"
+
+// ConnectedNationsIndexURL is the Ofcom page searched for mobile postcode
+// ZIPs when a year has no entry in MobileDataURLs.
+var ConnectedNationsIndexURL = "https://www.ofcom.org.uk/research-and-data/telecoms-research/connected-nations"
+
+var (
+	hrefPattern      = regexp.MustCompile(`(?i)href\s*=\s*["']([^"']+)["']`)
+	mobileZipPattern = regexp.MustCompile(`(?i)(\d{4})_mobile_pc_r(\d+)\.zip$`)
+	editionPattern   = regexp.MustCompile(`(?i)connected-nations-(\d{4})`)
+)
+
+// DiscoveredEdition is a mobile postcode ZIP linked from the Ofcom site.
+type DiscoveredEdition struct {
+	Year     string `json:"year"`
+	Revision int    `json:"revision"`
+	URL      string `json:"url"`
+}
+
+// Discover finds the download URL for year ("latest" for the newest) by
+// scanning indexURL for *_mobile_pc_r*.zip links. If the index has none it
+// follows links to per-year Connected Nations pages, newest first. When a
+// year has several revisions the highest wins.
+func Discover(indexURL, year string) (*DiscoveredEdition, error) {
+	if indexURL == "" {
+		indexURL = ConnectedNationsIndexURL
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	editions, pages, err := scanPage(client, indexURL)
+	if err != nil {
+		return nil, err
+	}
+	if len(editions) == 0 {
+		sort.Slice(pages, func(i, j int) bool { return pages[i].year > pages[j].year })
+		for _, p := range pages {
+			if year != LatestYear && p.year != year {
+				continue
+			}
+			found, _, err := scanPage(client, p.url)
+			if err != nil {
+				continue
+			}
+			editions = append(editions, found...)
+			if len(found) > 0 && year == LatestYear {
+				break
+			}
+		}
+	}
+
+	var best *DiscoveredEdition
+	for i, e := range editions {
+		if year != LatestYear && e.Year != year {
+			continue
+		}
+		if best == nil || e.Year > best.Year || (e.Year == best.Year && e.Revision > best.Revision) {
+			best = &editions[i]
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no mobile postcode ZIP for %s found at %s", year, indexURL)
+	}
+	return best, nil
+}
+
+// RevisionOf returns the revision in an Ofcom mobile ZIP's file name, e.g.
+// 2 for 2023_mobile_pc_r02.zip, or 0 if url does not name one.
+func RevisionOf(url string) int {
+	z := mobileZipPattern.FindStringSubmatch(url)
+	if z == nil {
+		return 0
+	}
+	rev, _ := strconv.Atoi(z[2])
+	return rev
+}
+
+// newestRevision returns the URL of the newest revision of year's dataset
+// on the Ofcom site when it is later than the one at url, which is
+// otherwise returned. Ofcom reissues files as r02, r03 and so on with
+// fixes. A year with a pinned checksum keeps url, since a reissue would not
+// match it, as does a url naming no revision; a failed search is not an
+// error.
+func (m *Manager) newestRevision(year, url string, opts SetupOptions) string {
+	if opts.SHA256 != "" || opts.ManifestURL != "" || MobileDataChecksums[year] != "" || RevisionOf(url) == 0 {
+		return url
+	}
+	found, err := Discover(opts.IndexURL, year)
+	if err != nil {
+		m.Logger.Debug("could not check for a newer dataset revision", "year", year, "err", err)
+		return url
+	}
+	if found.Revision <= RevisionOf(url) {
+		return url
+	}
+	m.Logger.Info("found a newer revision of the Ofcom mobile dataset", "year", year, "revision", found.Revision, "url", found.URL)
+	return found.URL
+}
+
+type editionPage struct {
+	year string
+	url  string
+}
+
+// scanPage returns the mobile ZIPs and per-year edition pages linked from
+// pageURL, with links resolved against it.
+func scanPage(client *http.Client, pageURL string) ([]DiscoveredEdition, []editionPage, error) {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := client.Get(pageURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch %s: %w", pageURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("HTTP %d from %s", resp.StatusCode, pageURL)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var editions []DiscoveredEdition
+	var pages []editionPage
+	seen := make(map[string]bool)
+	for _, m := range hrefPattern.FindAllSubmatch(body, -1) {
+		ref, err := url.Parse(string(m[1]))
+		if err != nil {
+			continue
+		}
+		link := base.ResolveReference(ref)
+		link.Fragment = ""
+		if seen[link.String()] {
+			continue
+		}
+		seen[link.String()] = true
+
+		if z := mobileZipPattern.FindStringSubmatch(link.Path); z != nil {
+			rev, _ := strconv.Atoi(z[2])
+			editions = append(editions, DiscoveredEdition{Year: z[1], Revision: rev, URL: link.String()})
+		} else if p := editionPattern.FindStringSubmatch(link.Path); p != nil && link.Host == base.Host {
+			pages = append(pages, editionPage{year: p[1], url: link.String()})
+		}
+	}
+	return editions, pages, nil
+}
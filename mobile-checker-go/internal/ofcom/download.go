@@ -0,0 +1,74 @@
+package ofcom
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// ErrNoSpace is returned when a download or extraction would not fit in
+// the free space of the data directory's file system.
+var ErrNoSpace = errors.New("not enough free disk space")
+
+// spaceMargin is kept free beyond what a download or extraction needs, so
+// the database build that follows is not the step that fills the disk.
+const spaceMargin = 64 << 20
+
+// checkSpace returns ErrNoSpace if dir's file system has fewer than need
+// bytes free, plus spaceMargin, for what. It does nothing where free
+// space cannot be measured.
+func (m *Manager) checkSpace(dir string, need int64, what string) error {
+	free, err := freeSpace(dir)
+	if err != nil {
+		m.Logger.Debug("cannot measure free disk space", "dir", dir, "err", err)
+		return nil
+	}
+	if need+spaceMargin > free {
+		return fmt.Errorf("%w for %s in %s: need %d MiB, have %d MiB", ErrNoSpace, what, dir, (need+spaceMargin)>>20, free>>20)
+	}
+	return nil
+}
+
+// downloadFile saves url to path, hashing it as it streams to disk so a
+// large file is never held in memory, and returns its SHA-256 and size.
+// It checks for space first when the server gives the size. path is
+// removed on failure.
+func (m *Manager) downloadFile(url, path string, opts SetupOptions) (sum string, size int64, err error) {
+	resp, err := opts.downloadClient().Get(url)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("HTTP %d from %s", resp.StatusCode, url)
+	}
+	if resp.ContentLength > 0 {
+		if err := m.checkSpace(filepath.Dir(path), resp.ContentLength, "the download"); err != nil {
+			return "", 0, err
+		}
+	}
+
+	body := io.Reader(resp.Body)
+	if opts.Progress != nil {
+		body = &progressReader{r: resp.Body, total: max(resp.ContentLength, 0), report: opts.progress}
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return "", 0, err
+	}
+	h := sha256.New()
+	size, err = io.Copy(io.MultiWriter(out, h), body)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
@@ -0,0 +1,54 @@
+package ofcom
+
+import (
+	"database/sql"
+	"os"
+	"runtime"
+	"time"
+)
+
+// Driver describes the SQLite implementation used to store the coverage
+// table. The default is chosen at build time: the CGO-based mattn/go-sqlite3
+// when CGO is enabled, or the pure-Go modernc.org/sqlite when it is not
+// (or when built with -tags purego).
+type Driver struct {
+	Name string                                  // database/sql driver name
+	DSN  func(path string, readOnly bool) string // builds a connection string
+}
+
+// DefaultDriver is the SQLite driver compiled into this binary.
+var DefaultDriver = defaultDriver
+
+// busyTimeout is how long a connection waits for another's lock, e.g. a
+// check while setup --geocode commits a batch, before failing with
+// "database is locked". It is set explicitly as the drivers differ:
+// mattn/go-sqlite3 waits 5s by default, modernc.org/sqlite not at all.
+const busyTimeout = 5 * time.Second
+
+// readConns bounds the shared read-only pool. The connections are kept
+// open between queries: opening one reads the schema, which under load
+// costs more than the lookup.
+var readConns = 4 * runtime.GOMAXPROCS(0)
+
+// open opens the database at path with the Manager's driver, or read-only
+// through its decrypting VFS if it is encrypted.
+func (m *Manager) open(path string, readOnly bool) (*sql.DB, error) {
+	if m.remoteDB() == nil {
+		if m.sealed != nil && m.unsealedFrom == "" {
+			m.cleanStale.Do(m.removeStaleCopy)
+		}
+		if ok, err := isSealed(path); err != nil {
+			return nil, err
+		} else if ok {
+			return m.openSealed(path)
+		}
+		if m.sealed != nil && m.unsealedFrom == "" {
+			if _, err := os.Stat(path); err == nil {
+				m.warnPlain.Do(func() {
+					m.Logger.Warn("database is NOT encrypted although an encryption key is set; it is encrypted when next written, e.g. by setup or maintain", "path", path)
+				})
+			}
+		}
+	}
+	return sql.Open(m.Driver.Name, m.Driver.DSN(path, readOnly))
+}
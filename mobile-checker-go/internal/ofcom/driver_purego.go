@@ -0,0 +1,22 @@
+//go:build !cgo || purego
+
+package ofcom
+
+import (
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// busy is the DSN parameter applying busyTimeout to every connection.
+var busy = fmt.Sprintf("_pragma=busy_timeout(%d)", busyTimeout.Milliseconds())
+
+var defaultDriver = Driver{
+	Name: "sqlite",
+	DSN: func(path string, readOnly bool) string {
+		if readOnly {
+			return fmt.Sprintf("file:%s?mode=ro&%s", path, busy)
+		}
+		return fmt.Sprintf("%s?%s", path, busy)
+	},
+}
@@ -0,0 +1,527 @@
+package ofcom
+
+import (
+	"container/list"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/yourusername/mobile-checker/internal/seal"
+
+	// Encrypted reads use the pure-Go driver, whichever is the default.
+	_ "modernc.org/sqlite"
+)
+
+// ErrEncrypted is returned opening an encrypted database without a key.
+var ErrEncrypted = errors.New("database is encrypted: pass its key with --encrypt-key-file or $MOBILE_CHECKER_ENCRYPT_KEY")
+
+// An encrypted database file is a header followed by the SQLite file in
+// chunks, each sealed with AES-256-GCM. The header's salt derives the key
+// from the passphrase, and is new each time the file is encrypted.
+const (
+	sealMagic      = "MCSEAL1\n"
+	sealHeaderSize = 64
+	sealChunkSize  = 64 << 10
+	// sealCacheSize is the bytes of decrypted chunks kept in memory,
+	// shared by every connection.
+	sealCacheSize = 32 << 20
+)
+
+// sealHeader is the start of an encrypted database file.
+type sealHeader struct {
+	salt      []byte
+	chunkSize int64
+	size      int64  // of the plaintext
+	raw       []byte // the authenticated part of the header
+}
+
+func (h *sealHeader) marshal() []byte {
+	b := make([]byte, sealHeaderSize)
+	copy(b, sealMagic)
+	copy(b[8:], h.salt)
+	binary.BigEndian.PutUint32(b[24:], uint32(h.chunkSize))
+	binary.BigEndian.PutUint64(b[28:], uint64(h.size))
+	h.raw = b[:36]
+	return b
+}
+
+func readSealHeader(r io.ReaderAt) (*sealHeader, error) {
+	b := make([]byte, sealHeaderSize)
+	if _, err := r.ReadAt(b, 0); err != nil {
+		return nil, fmt.Errorf("reading encrypted database header: %w", err)
+	}
+	if string(b[:8]) != sealMagic {
+		return nil, errors.New("not an encrypted database")
+	}
+	h := &sealHeader{
+		salt:      b[8:24],
+		chunkSize: int64(binary.BigEndian.Uint32(b[24:])),
+		size:      int64(binary.BigEndian.Uint64(b[28:])),
+		raw:       b[:36],
+	}
+	if h.chunkSize <= 0 || h.size < 0 {
+		return nil, errors.New("invalid encrypted database header")
+	}
+	return h, nil
+}
+
+// ad is the data authenticated with chunk i: the header, so chunks cannot
+// be moved between files or the file truncated, and i, so they cannot be
+// reordered.
+func (h *sealHeader) ad(i int64) []byte {
+	return binary.BigEndian.AppendUint64(append([]byte(nil), h.raw...), uint64(i))
+}
+
+// offset is where chunk i starts in the file.
+func (h *sealHeader) offset(i int64) int64 {
+	return sealHeaderSize + i*(h.chunkSize+seal.Overhead)
+}
+
+// isSealed reports whether the file at path is an encrypted database; a
+// missing file is not.
+func isSealed(path string) (bool, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	b := make([]byte, len(sealMagic))
+	if _, err := io.ReadFull(f, b); err != nil {
+		return false, nil
+	}
+	return string(b) == sealMagic, nil
+}
+
+// WithEncryptionKey keeps the database encrypted at rest with a key
+// derived from passphrase. The database is read through a decrypting
+// VFS; Setup, Update and the other methods that write it work on a
+// decrypted copy beside it and encrypt the result, along with any other
+// dataset years, over the database when they finish (see Unsealed). An
+// existing unencrypted database is read with a warning and encrypted the
+// first time one of them runs.
+//
+// Encrypted reads use the pure-Go SQLite driver, which is compiled in
+// whichever driver is the default.
+func WithEncryptionKey(passphrase string) Option {
+	return func(m *Manager) {
+		if passphrase == "" {
+			m.sealed = nil
+			return
+		}
+		m.sealed = &sealedFS{passphrase: passphrase}
+	}
+}
+
+// Encrypted reports whether the database at DBPath is encrypted.
+func (m *Manager) Encrypted() bool {
+	ok, _ := isSealed(m.DBPath)
+	return ok
+}
+
+// openSealed opens the encrypted database at path, which is always
+// read-only.
+func (m *Manager) openSealed(path string) (*sql.DB, error) {
+	if m.sealed == nil {
+		return nil, ErrEncrypted
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	// Fail on a wrong key here rather than with SQLite's "file is not a
+	// database".
+	f, err := m.sealed.open(abs)
+	if err != nil {
+		return nil, err
+	}
+	_, err = f.chunk(0)
+	f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	vfsName, prefix, err := m.sealedMnt.mount(m.sealed)
+	if err != nil {
+		return nil, err
+	}
+	return sql.Open("sqlite", "file:"+prefix+"/"+sealedName(abs)+"?vfs="+vfsName+"&mode=ro&immutable=1")
+}
+
+// unsealedSuffix names the decrypted working copy Unsealed writes beside
+// the database.
+const unsealedSuffix = ".unsealed"
+
+// Unsealed runs fn, which writes the database, on w: m itself without a
+// key, otherwise a Manager whose DBPath is a decrypted copy of the
+// database. When fn returns, even on failure, the copy is encrypted to a
+// temporary file and renamed over the database, so the database is only
+// ever replaced by an encrypted file and readers see the old data until
+// then; the copy and its journal are removed. Any other dataset years and
+// superseded builds, which fn may have kept, are encrypted too. Calls
+// may nest, on m or on w; only the outermost copies and encrypts.
+func (m *Manager) Unsealed(fn func(w *Manager) error) error {
+	if m.sealed == nil || m.unsealedFrom != "" {
+		return fn(m)
+	}
+	m.sealMu.Lock()
+	if m.unsealed == 0 {
+		w, err := m.workingCopy()
+		if err != nil {
+			m.sealMu.Unlock()
+			return err
+		}
+		m.work = w
+	}
+	m.unsealed++
+	w := m.work
+	m.sealMu.Unlock()
+
+	err := fn(w)
+
+	m.sealMu.Lock()
+	defer m.sealMu.Unlock()
+	if m.unsealed--; m.unsealed > 0 {
+		return err
+	}
+	m.work = nil
+	w.Close()
+	defer removeDB(w.DBPath)
+	if serr := m.sealed.encrypt(w.DBPath, m.DBPath); serr != nil && err == nil {
+		err = fmt.Errorf("encrypting %s: %w", m.DBPath, serr)
+	}
+	for _, path := range m.archivedPaths() {
+		if serr := m.sealed.seal(path); serr != nil && err == nil {
+			err = fmt.Errorf("encrypting %s: %w", path, serr)
+		}
+	}
+	return err
+}
+
+// workingCopy returns the Manager Unsealed gives fn, with the database
+// decrypted to its DBPath, or none there if there is no database yet.
+func (m *Manager) workingCopy() (*Manager, error) {
+	w := m.childManager(m.DataDir)
+	w.DBPath = m.DBPath + unsealedSuffix
+	w.unsealedFrom = m.DBPath
+	// A copy left by a crash is out of date, and should not be on disk.
+	removeDB(w.DBPath)
+	if err := m.sealed.decrypt(m.DBPath, w.DBPath); err != nil {
+		removeDB(w.DBPath)
+		return nil, fmt.Errorf("decrypting %s: %w", m.DBPath, err)
+	}
+	return w, nil
+}
+
+// removeStaleCopy removes a decrypted working copy that a crash left
+// beside the database, which would otherwise stay on disk in plain text
+// until the database is next written.
+func (m *Manager) removeStaleCopy() {
+	m.sealMu.Lock()
+	defer m.sealMu.Unlock()
+	path := m.DBPath + unsealedSuffix
+	if m.unsealed > 0 {
+		return
+	}
+	if _, err := os.Stat(path); err != nil {
+		return
+	}
+	m.Logger.Warn("removing a decrypted copy of the database left by an earlier run", "path", path)
+	removeDB(path)
+}
+
+// removeDB removes the database at path with its journal and WAL.
+func removeDB(path string) {
+	for _, suffix := range []string{"", "-journal", "-wal", "-shm"} {
+		os.Remove(path + suffix)
+	}
+}
+
+// sealedFS is an fs.FS of encrypted database files, named by their
+// absolute paths (see sealedName), that reads them decrypted.
+type sealedFS struct {
+	passphrase string
+
+	mu     sync.Mutex
+	chunks map[sealedChunkID]*list.Element
+	lru    list.List // of *sealedChunk, most recent first
+}
+
+// sealedChunkID identifies a chunk by its file's salt, which is unique to
+// each encryption of a file, and its index.
+type sealedChunkID struct {
+	salt  string
+	index int64
+}
+
+type sealedChunk struct {
+	id   sealedChunkID
+	data []byte
+}
+
+// sealedName is the name in a sealedFS of the file at the absolute path
+// abs: abs with forward slashes and no leading slash, as fs.FS requires.
+func sealedName(abs string) string {
+	return strings.TrimPrefix(filepath.ToSlash(abs), "/")
+}
+
+// Open implements fs.FS. SQLite also asks for journal and WAL files,
+// which do not exist.
+func (s *sealedFS) Open(name string) (fs.File, error) {
+	path := filepath.FromSlash(name)
+	if !filepath.IsAbs(path) {
+		path = string(filepath.Separator) + path
+	}
+	return s.open(path)
+}
+
+// open opens the encrypted database at path.
+func (s *sealedFS) open(path string) (*sealedFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	h, err := readSealHeader(f)
+	if err != nil {
+		f.Close()
+		return nil, &fs.PathError{Op: "open", Path: path, Err: err}
+	}
+	key, err := seal.Derive(s.passphrase, h.salt)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &sealedFile{fs: s, f: f, hdr: h, key: key}, nil
+}
+
+// cached returns a decrypted chunk, or nil.
+func (s *sealedFS) cached(id sealedChunkID) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.chunks[id]; ok {
+		s.lru.MoveToFront(e)
+		return e.Value.(*sealedChunk).data
+	}
+	return nil
+}
+
+// cache keeps a decrypted chunk, evicting the least recently used.
+func (s *sealedFS) cache(id sealedChunkID, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.chunks == nil {
+		s.chunks = make(map[sealedChunkID]*list.Element)
+	}
+	if _, ok := s.chunks[id]; ok {
+		return
+	}
+	s.chunks[id] = s.lru.PushFront(&sealedChunk{id: id, data: data})
+	for s.lru.Len()*sealChunkSize > sealCacheSize && s.lru.Len() > 1 {
+		oldest := s.lru.Remove(s.lru.Back()).(*sealedChunk)
+		delete(s.chunks, oldest.id)
+	}
+}
+
+// seal encrypts the database at path in place, unless it is missing or
+// already encrypted.
+func (s *sealedFS) seal(path string) error {
+	if ok, err := isSealed(path); err != nil || ok {
+		return err
+	}
+	return s.encrypt(path, path)
+}
+
+// encrypt writes the database at src, which is not encrypted, encrypted
+// to a temporary file and renames it to dst. It does nothing if src is
+// missing.
+func (s *sealedFS) encrypt(src, dst string) error {
+	in, err := os.Open(src)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	salt, err := seal.NewSalt()
+	if err != nil {
+		return err
+	}
+	key, err := seal.Derive(s.passphrase, salt)
+	if err != nil {
+		return err
+	}
+	h := &sealHeader{salt: salt, chunkSize: sealChunkSize, size: info.Size()}
+
+	tmp := dst + ".sealing"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	err = func() error {
+		if _, err := out.Write(h.marshal()); err != nil {
+			return err
+		}
+		buf := make([]byte, sealChunkSize)
+		var sealed []byte
+		for i := int64(0); ; i++ {
+			n, rerr := io.ReadFull(in, buf)
+			if n > 0 {
+				if sealed, err = key.Seal(sealed[:0], buf[:n], h.ad(i)); err != nil {
+					return err
+				}
+				if _, err := out.Write(sealed); err != nil {
+					return err
+				}
+			}
+			if rerr == io.EOF || rerr == io.ErrUnexpectedEOF {
+				return out.Sync()
+			}
+			if rerr != nil {
+				return rerr
+			}
+		}
+	}()
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
+
+// decrypt writes the database at src to dst decrypted, or as it is if it
+// is not encrypted. It does nothing if src is missing.
+func (s *sealedFS) decrypt(src, dst string) error {
+	info, err := os.Stat(src)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	encrypted, err := isSealed(src)
+	if err != nil {
+		return err
+	}
+	var in io.ReadCloser
+	if encrypted {
+		in, err = s.open(src)
+	} else {
+		in, err = os.Open(src)
+	}
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// sealedFile is an open encrypted database, read decrypted.
+type sealedFile struct {
+	fs  *sealedFS
+	f   *os.File
+	hdr *sealHeader
+	key *seal.Key
+	off int64
+}
+
+// chunk returns chunk i decrypted, from the cache or the file.
+func (f *sealedFile) chunk(i int64) ([]byte, error) {
+	id := sealedChunkID{salt: string(f.hdr.salt), index: i}
+	if data := f.fs.cached(id); data != nil {
+		return data, nil
+	}
+	n := min(f.hdr.chunkSize, f.hdr.size-i*f.hdr.chunkSize)
+	if n <= 0 {
+		return nil, io.EOF
+	}
+	buf := make([]byte, n+seal.Overhead)
+	if _, err := f.f.ReadAt(buf, f.hdr.offset(i)); err != nil {
+		return nil, fmt.Errorf("reading encrypted database: %w", err)
+	}
+	data, err := f.key.Open(nil, buf, f.hdr.ad(i))
+	if err != nil {
+		return nil, err
+	}
+	f.fs.cache(id, data)
+	return data, nil
+}
+
+// readAt fills b from offset off, or as much as the file holds.
+func (f *sealedFile) readAt(b []byte, off int64) (int, error) {
+	size := f.hdr.chunkSize
+	n := 0
+	for n < len(b) && off+int64(n) < f.hdr.size {
+		pos := off + int64(n)
+		data, err := f.chunk(pos / size)
+		if err != nil {
+			return n, err
+		}
+		n += copy(b[n:], data[pos%size:])
+	}
+	if n == 0 && len(b) > 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+func (f *sealedFile) Read(b []byte) (int, error) {
+	n, err := f.readAt(b, f.off)
+	f.off += int64(n)
+	return n, err
+}
+
+func (f *sealedFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.off
+	case io.SeekEnd:
+		offset += f.hdr.size
+	}
+	if offset < 0 {
+		return 0, errors.New("negative offset")
+	}
+	f.off = offset
+	return offset, nil
+}
+
+func (f *sealedFile) Stat() (fs.FileInfo, error) {
+	info, err := f.f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return sealedInfo{info, f.hdr.size}, nil
+}
+
+func (f *sealedFile) Close() error { return f.f.Close() }
+
+// sealedInfo is an encrypted file's info with the size of its plaintext.
+type sealedInfo struct {
+	fs.FileInfo
+	size int64
+}
+
+func (i sealedInfo) Size() int64 { return i.size }
@@ -0,0 +1,86 @@
+package ofcom
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// EstimateNeighbours is the number of nearby postcodes an estimate is
+// weighted from.
+const EstimateNeighbours = 5
+
+// EstimateMaxKm is the furthest a postcode can be from the one estimated
+// and still contribute; beyond it coverage says too little to be useful.
+const EstimateMaxKm = 2.0
+
+// Estimate returns a synthetic mobile row for a position on the British
+// National Grid, such as a new-build postcode not yet in the dataset, by
+// inverse-distance-weighting each column over the EstimateNeighbours
+// nearest geocoded postcodes within EstimateMaxKm. It also returns the
+// postcodes used, nearest first. With no postcodes in range the row is
+// nil. Searching needs the geographic data stored by Geocode or
+// ImportONSPD.
+func (m *Manager) Estimate(pc string, eastings, northings int) (map[string]string, []NearbyPostcode, error) {
+	db, release, err := m.acquire()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
+
+	r := int(EstimateMaxKm * 1000)
+	query := `SELECT m.*,
+		(g.eastings - ?) * (g.eastings - ?) + (g.northings - ?) * (g.northings - ?) AS estimate_d2
+		FROM mobile m JOIN geo g ON g.postcode = m.postcode
+		WHERE g.eastings > 0 AND g.northings > 0
+		AND g.eastings BETWEEN ? AND ? AND g.northings BETWEEN ? AND ?
+		ORDER BY estimate_d2 LIMIT ?`
+	var rows []map[string]string
+	err = scanRows(db, query, []any{eastings, eastings, northings, northings,
+		eastings - r, eastings + r, northings - r, northings + r, EstimateNeighbours}, func(row map[string]string) {
+		rows = append(rows, row)
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "no such table") {
+			return nil, nil, ErrNoGeoData
+		}
+		return nil, nil, err
+	}
+
+	var used []NearbyPostcode
+	weights := make([]float64, 0, len(rows))
+	for _, row := range rows {
+		d2, _ := strconv.ParseFloat(row["estimate_d2"], 64)
+		d := math.Sqrt(d2)
+		if d > EstimateMaxKm*1000 {
+			break // in the search box's corners; the rest are further still
+		}
+		// Postcodes sharing a centroid would divide by zero; treat them as
+		// 10 m away.
+		weights = append(weights, 1/math.Max(d, 10)/math.Max(d, 10))
+		used = append(used, NearbyPostcode{Postcode: row["postcode"], DistanceKm: math.Round(d/100) / 10})
+	}
+	if len(used) == 0 {
+		return nil, nil, nil
+	}
+
+	est := map[string]string{"postcode": normalisePostcode(pc)}
+	for col := range rows[0] {
+		if col == "postcode" || col == "estimate_d2" {
+			continue
+		}
+		var sum, total float64
+		for i, row := range rows[:len(weights)] {
+			f, err := strconv.ParseFloat(row[col], 64)
+			if err != nil {
+				continue // columns a neighbour lacks do not count against it
+			}
+			sum += f * weights[i]
+			total += weights[i]
+		}
+		if total > 0 {
+			est[col] = strconv.FormatFloat(sum/total, 'f', 4, 64)
+		}
+	}
+	return est, used, nil
+}
@@ -0,0 +1,143 @@
+package ofcom
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ExportFilter selects the postcodes copied by Export. Set fields are
+// combined with AND; District and Region need geographic data.
+type ExportFilter struct {
+	District string // admin district, case-insensitive
+	Region   string // region, case-insensitive
+	Outcode  string // e.g. "LS1"
+}
+
+// String describes the filter, e.g. `district="Leeds"`.
+func (f ExportFilter) String() string {
+	var parts []string
+	if f.District != "" {
+		parts = append(parts, fmt.Sprintf("district=%q", f.District))
+	}
+	if f.Region != "" {
+		parts = append(parts, fmt.Sprintf("region=%q", f.Region))
+	}
+	if f.Outcode != "" {
+		parts = append(parts, fmt.Sprintf("outcode=%q", strings.ToUpper(f.Outcode)))
+	}
+	return strings.Join(parts, " ")
+}
+
+// where returns the SQL condition selecting f's postcodes from mobile m
+// joined with geo g, and its arguments. It is empty when no field is set.
+func (f ExportFilter) where() (string, []any) {
+	var where []string
+	var args []any
+	if f.District != "" {
+		where = append(where, "g.admin_district = ? COLLATE NOCASE")
+		args = append(args, f.District)
+	}
+	if f.Region != "" {
+		where = append(where, "g.region = ? COLLATE NOCASE")
+		args = append(args, f.Region)
+	}
+	if f.Outcode != "" {
+		where = append(where, "substr(m.postcode, 1, length(m.postcode) - 3) = ?")
+		args = append(args, strings.ToUpper(strings.ReplaceAll(f.Outcode, " ", "")))
+	}
+	return strings.Join(where, " AND "), args
+}
+
+// Export writes a standalone database to out containing only the postcodes
+// matching f, with their geographic data and the source dataset's metadata.
+// It returns the number of postcodes exported.
+func (m *Manager) Export(out string, f ExportFilter) (int, error) {
+	where, args := f.where()
+	if where == "" {
+		return 0, fmt.Errorf("at least one filter is required")
+	}
+	if _, err := os.Stat(m.DBPath); os.IsNotExist(err) {
+		return 0, ErrDatabaseNotFound
+	}
+	if _, err := os.Stat(out); err == nil {
+		return 0, fmt.Errorf("%s already exists", out)
+	}
+
+	// Bring the source up to date so its columns match the new file's.
+	src, err := m.openMigrated()
+	if err != nil {
+		return 0, err
+	}
+	src.Close()
+
+	tmp := out + ".partial"
+	os.Remove(tmp)
+	n, err := m.exportInto(tmp, f, where, args)
+	if err != nil {
+		os.Remove(tmp)
+		return 0, err
+	}
+	if err := os.Rename(tmp, out); err != nil {
+		return 0, err
+	}
+	m.Logger.Info("exported postcode subset", "path", out, "filter", f.String(), "postcodes", n)
+	return n, nil
+}
+
+func (m *Manager) exportInto(path string, f ExportFilter, where string, args []any) (int, error) {
+	db, err := m.open(path, false)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+	if err := migrate(db); err != nil {
+		return 0, err
+	}
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `ATTACH DATABASE ? AS src`, m.DBPath); err != nil {
+		return 0, err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`INSERT INTO mobile SELECT m.* FROM src.mobile m
+		LEFT JOIN src.geo g ON g.postcode = m.postcode WHERE `+where, args...)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such") {
+			return 0, ErrNoGeoData
+		}
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	if _, err := tx.Exec(`INSERT INTO geo SELECT g.* FROM src.geo g JOIN mobile m ON m.postcode = g.postcode`); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`INSERT INTO meta SELECT key, value FROM src.meta`); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`INSERT OR REPLACE INTO meta (key, value) VALUES ('subset', ?)`, f.String()); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	if _, err := conn.ExecContext(ctx, `DETACH DATABASE src`); err != nil {
+		return 0, err
+	}
+	if _, err := conn.ExecContext(ctx, `VACUUM`); err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
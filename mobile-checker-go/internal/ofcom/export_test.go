@@ -0,0 +1,12 @@
+package ofcom
+
+// Exported for tests in package ofcom_test.
+var (
+	FreeSpace   = freeSpace
+	SpaceMargin = int64(spaceMargin)
+)
+
+// CheckSpace is Manager.checkSpace.
+func (m *Manager) CheckSpace(dir string, need int64) error {
+	return m.checkSpace(dir, need, "test")
+}
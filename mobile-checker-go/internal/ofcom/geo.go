@@ -0,0 +1,363 @@
+package ofcom
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// ErrNoGeoData is returned by queries that need the geo table before
+// setup has stored geographic data.
+var ErrNoGeoData = errors.New("no geographic data — run 'setup --geocode' first")
+
+// Place is the geographic data stored for a postcode in the geo table.
+type Place struct {
+	Postcode      string
+	Country       string
+	Region        string
+	AdminDistrict string
+	Constituency  string
+	Latitude      float64
+	Longitude     float64
+	Eastings      int
+	Northings     int
+}
+
+// AreaSummary aggregates coverage over every geocoded postcode in an area.
+type AreaSummary struct {
+	Level     string          `json:"level" xml:"level"`
+	Name      string          `json:"name" xml:"name"`
+	Postcodes int             `json:"postcodes" xml:"postcodes"`
+	Operators []OperatorStats `json:"operators" xml:"operators>operator"`
+	// AllFourG is the percentage of postcodes where all four operators have 4G.
+	AllFourG float64 `json:"all_operators_4g_pct" xml:"all_operators_4g_pct"`
+	// AnyFiveG is the percentage of postcodes where at least one operator has 5G.
+	AnyFiveG float64 `json:"any_operator_5g_pct" xml:"any_operator_5g_pct"`
+}
+
+// OperatorStats holds area-level coverage statistics for one operator.
+// Mean values are average coverage percentages; Pct values are the share of
+// postcodes meeting CoverageThreshold.
+type OperatorStats struct {
+	Name      string  `json:"name" xml:"name"`
+	MeanVoice float64 `json:"mean_voice_pct" xml:"mean_voice_pct"`
+	MeanFourG float64 `json:"mean_4g_pct" xml:"mean_4g_pct"`
+	MeanFiveG float64 `json:"mean_5g_pct" xml:"mean_5g_pct"`
+	PctVoice  float64 `json:"voice_covered_pct" xml:"voice_covered_pct"`
+	// XML names cannot start with a digit, so the covered percentages lead
+	// with "covered_" there.
+	PctFourG float64 `json:"4g_covered_pct" xml:"covered_4g_pct"`
+	PctFiveG float64 `json:"5g_covered_pct" xml:"covered_5g_pct"`
+}
+
+// AreaLevels maps an aggregation level to its geo table column.
+var AreaLevels = map[string]string{
+	"country":      "country",
+	"region":       "region",
+	"district":     "admin_district",
+	"constituency": "constituency",
+}
+
+// operatorNames maps canonical operator prefixes to display names.
+var operatorNames = map[string]string{
+	"ee":       "EE",
+	"o2":       "O2",
+	"three":    "Three",
+	"vodafone": "Vodafone",
+}
+
+// UngeocodedPostcodes returns postcodes in the mobile table with no geo row.
+func (m *Manager) UngeocodedPostcodes() ([]string, error) {
+	db, err := m.openMigrated()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT m.postcode FROM mobile m LEFT JOIN geo g ON g.postcode = m.postcode WHERE g.postcode IS NULL`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pcs []string
+	for rows.Next() {
+		var pc string
+		if err := rows.Scan(&pc); err != nil {
+			return nil, err
+		}
+		pcs = append(pcs, pc)
+	}
+	return pcs, rows.Err()
+}
+
+// SamplePostcodes returns up to n postcodes from the mobile table, chosen
+// at random.
+func (m *Manager) SamplePostcodes(n int) ([]string, error) {
+	db, release, err := m.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	rows, err := db.Query(`SELECT postcode FROM mobile ORDER BY RANDOM() LIMIT ?`, n)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pcs []string
+	for rows.Next() {
+		var pc string
+		if err := rows.Scan(&pc); err != nil {
+			return nil, err
+		}
+		pcs = append(pcs, pc)
+	}
+	return pcs, rows.Err()
+}
+
+// Place returns the stored geographic data for a postcode, or nil if it has
+// none or was recorded as unlocatable.
+func (m *Manager) Place(pc string) (*Place, error) {
+	db, release, err := m.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var country, region, district, constituency sql.NullString
+	var lat, lon sql.NullFloat64
+	var e, n sql.NullInt64
+	p := &Place{Postcode: normalisePostcode(pc)}
+	err = db.QueryRow(`SELECT country, region, admin_district, constituency, latitude, longitude, eastings, northings
+		FROM geo WHERE postcode = ?`, p.Postcode).Scan(&country, &region, &district, &constituency, &lat, &lon, &e, &n)
+	if err == sql.ErrNoRows || (err != nil && strings.Contains(err.Error(), "no such table")) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !country.Valid && !region.Valid {
+		return nil, nil
+	}
+	p.Country, p.Region, p.AdminDistrict, p.Constituency = country.String, region.String, district.String, constituency.String
+	p.Latitude, p.Longitude = lat.Float64, lon.Float64
+	p.Eastings, p.Northings = int(e.Int64), int(n.Int64)
+	return p, nil
+}
+
+// StoreGeo upserts geographic data for postcodes. Places with only a
+// Postcode set are stored as known-unlocatable so they are not retried.
+func (m *Manager) StoreGeo(places []Place) error {
+	return m.Unsealed(func(w *Manager) error { return w.storeGeo(places) })
+}
+
+func (m *Manager) storeGeo(places []Place) error {
+	db, err := m.openMigrated()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(`INSERT OR REPLACE INTO geo VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, p := range places {
+		_, err := stmt.Exec(p.Postcode, nullString(p.Country), nullString(p.Region),
+			nullString(p.AdminDistrict), nullString(p.Constituency),
+			p.Latitude, p.Longitude, p.Eastings, p.Northings)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ValidAreaName reports whether name could name an area: up to 100
+// letters, digits, spaces and the punctuation of names such as
+// "Kingston upon Hull, City of" or "St. Helens".
+func ValidAreaName(name string) bool {
+	if strings.TrimSpace(name) == "" || len(name) > 100 {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune(" '’-,.&()", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Aggregate computes coverage statistics over all postcodes whose geo
+// column for level (see AreaLevels) matches name, case-insensitively.
+// It returns nil if no postcodes match, and ErrNoGeoData if no postcode
+// has a value for the level.
+func (m *Manager) Aggregate(level, name string) (*AreaSummary, error) {
+	col, ok := AreaLevels[level]
+	if !ok {
+		return nil, fmt.Errorf("unknown area level %q", level)
+	}
+	db, release, err := m.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	exprs := summaryExprs()
+	query := fmt.Sprintf(`SELECT %s FROM mobile m JOIN geo g ON g.postcode = m.postcode WHERE g.%s = ? COLLATE NOCASE`,
+		strings.Join(exprs, ", "), col)
+
+	vals := make([]float64, len(exprs)-1)
+	var count int
+	if err := db.QueryRow(query, name).Scan(summaryDest(&count, vals)...); err != nil {
+		if strings.Contains(err.Error(), "no such table") {
+			return nil, ErrNoGeoData
+		}
+		return nil, err
+	}
+	if count == 0 {
+		var geocoded int
+		if err := db.QueryRow(fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM geo WHERE %s IS NOT NULL)`, col)).Scan(&geocoded); err == nil && geocoded == 0 {
+			return nil, ErrNoGeoData
+		}
+		return nil, nil
+	}
+	return newAreaSummary(level, name, count, vals), nil
+}
+
+// Stats computes coverage statistics for every area at level (see
+// AreaLevels), ordered by area name. Postcodes without a value for the
+// level are skipped.
+func (m *Manager) Stats(level string) ([]AreaSummary, error) {
+	col, ok := AreaLevels[level]
+	if !ok {
+		return nil, fmt.Errorf("unknown area level %q", level)
+	}
+	db, release, err := m.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	exprs := summaryExprs()
+	query := fmt.Sprintf(`SELECT g.%[1]s, %[2]s FROM mobile m JOIN geo g ON g.postcode = m.postcode
+		WHERE g.%[1]s IS NOT NULL GROUP BY g.%[1]s ORDER BY g.%[1]s`, col, strings.Join(exprs, ", "))
+	rows, err := db.Query(query)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such table") {
+			return nil, ErrNoGeoData
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []AreaSummary
+	for rows.Next() {
+		var name string
+		var count int
+		vals := make([]float64, len(exprs)-1)
+		if err := rows.Scan(append([]interface{}{&name}, summaryDest(&count, vals)...)...); err != nil {
+			return nil, err
+		}
+		stats = append(stats, *newAreaSummary(level, name, count, vals))
+	}
+	return stats, rows.Err()
+}
+
+// summaryExprs returns the SELECT expressions behind an AreaSummary over
+// "mobile m": a count followed by the values read by newAreaSummary.
+func summaryExprs() []string {
+	exprs := []string{"COUNT(*)"}
+	for _, op := range Operators {
+		for _, ms := range []string{"voice", "4g", "5g"} {
+			c := op + "_" + ms
+			exprs = append(exprs,
+				fmt.Sprintf("COALESCE(AVG(m.%s), 0)", c),
+				fmt.Sprintf("COALESCE(AVG(CASE WHEN m.%s >= %g THEN 1.0 ELSE 0 END), 0)", c, CoverageThreshold))
+		}
+	}
+	var all4G, any5G []string
+	for _, op := range Operators {
+		all4G = append(all4G, fmt.Sprintf("m.%s_4g >= %g", op, CoverageThreshold))
+		any5G = append(any5G, fmt.Sprintf("m.%s_5g >= %g", op, CoverageThreshold))
+	}
+	return append(exprs,
+		fmt.Sprintf("COALESCE(AVG(CASE WHEN %s THEN 1.0 ELSE 0 END), 0)", strings.Join(all4G, " AND ")),
+		fmt.Sprintf("COALESCE(AVG(CASE WHEN %s THEN 1.0 ELSE 0 END), 0)", strings.Join(any5G, " OR ")))
+}
+
+// summaryDest returns Scan destinations for summaryExprs.
+func summaryDest(count *int, vals []float64) []interface{} {
+	dest := []interface{}{count}
+	for i := range vals {
+		dest = append(dest, &vals[i])
+	}
+	return dest
+}
+
+func newAreaSummary(level, name string, count int, vals []float64) *AreaSummary {
+	pct := func(f float64) float64 { return float64(int(f*1000+0.5)) / 10 }
+	summary := &AreaSummary{Level: level, Name: name, Postcodes: count}
+	i := 0
+	for _, op := range Operators {
+		summary.Operators = append(summary.Operators, OperatorStats{
+			Name:      operatorNames[op],
+			MeanVoice: pct(vals[i]),
+			PctVoice:  pct(vals[i+1]),
+			MeanFourG: pct(vals[i+2]),
+			PctFourG:  pct(vals[i+3]),
+			MeanFiveG: pct(vals[i+4]),
+			PctFiveG:  pct(vals[i+5]),
+		})
+		i += 6
+	}
+	summary.AllFourG = pct(vals[i])
+	summary.AnyFiveG = pct(vals[i+1])
+	return summary
+}
+
+// openMigrated opens the database read-write, bringing its schema up to date.
+// An encrypted database is opened read-only as it is: it was migrated
+// before it was encrypted.
+func (m *Manager) openMigrated() (*sql.DB, error) {
+	if _, err := os.Stat(m.DBPath); os.IsNotExist(err) {
+		return nil, ErrDatabaseNotFound
+	}
+	if m.Encrypted() {
+		return m.open(m.DBPath, true)
+	}
+	db, err := m.open(m.DBPath, false)
+	if err != nil {
+		return nil, err
+	}
+	if v, err := schemaVersion(db); err != nil || v == 0 {
+		db.Close()
+		if err == nil {
+			err = ErrSchemaOutdated
+		}
+		return nil, err
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,223 @@
+package ofcom
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// GeoJSONOptions controls ExportGeoJSON.
+type GeoJSONOptions struct {
+	// BBox limits the export to postcode centroids inside it; nil means
+	// everywhere.
+	BBox *BBox
+	// GridSize aggregates postcodes into OSGB National Grid squares of
+	// this many metres, e.g. 1000, keeping a national export to tens of
+	// megabytes. 0 writes one point per postcode.
+	GridSize int
+}
+
+// geoFeature is one GeoJSON Feature.
+type geoFeature struct {
+	Type       string         `json:"type"`
+	Geometry   geoGeometry    `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+type geoGeometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// ExportGeoJSON writes the geocoded postcodes matching f to out as a GeoJSON
+// FeatureCollection for building choropleths in QGIS, Leaflet or kepler.gl.
+// Each feature is a postcode centroid with its coverage columns as
+// fractions 0–1, or with opts.GridSize a grid square polygon with the mean
+// of each column and its postcode count. Coordinates are WGS84, as GeoJSON
+// requires. Postcodes without geographic data are left out, so it needs
+// 'setup --geocode' or --onspd. It returns the number of features written.
+func (m *Manager) ExportGeoJSON(out string, f ExportFilter, opts GeoJSONOptions) (int, error) {
+	if opts.BBox != nil && !opts.BBox.Valid() {
+		return 0, fmt.Errorf("invalid bounding box")
+	}
+	if opts.GridSize < 0 {
+		return 0, fmt.Errorf("grid size must be positive")
+	}
+	if _, err := os.Stat(m.DBPath); os.IsNotExist(err) {
+		return 0, ErrDatabaseNotFound
+	}
+	if _, err := os.Stat(out); err == nil {
+		return 0, fmt.Errorf("%s already exists", out)
+	}
+	db, err := m.openMigrated()
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+	cols, err := storedColumns(db)
+	if err != nil {
+		return 0, err
+	}
+
+	where, args := f.where()
+	conds := []string{"g.latitude IS NOT NULL"}
+	if where != "" {
+		conds = append(conds, where)
+	}
+	if b := opts.BBox; b != nil {
+		conds = append(conds, "g.longitude BETWEEN ? AND ? AND g.latitude BETWEEN ? AND ?")
+		args = append(args, b.MinLon, b.MaxLon, b.MinLat, b.MaxLat)
+	}
+	var query string
+	if opts.GridSize > 0 {
+		means := make([]string, len(cols))
+		for i, c := range cols {
+			means[i] = fmt.Sprintf("AVG(m.%s) AS %[1]s", c)
+		}
+		query = fmt.Sprintf(`SELECT CAST(g.eastings / %d AS INTEGER) AS gx, CAST(g.northings / %[1]d AS INTEGER) AS gy,
+			COUNT(*) AS postcodes, %s
+			FROM mobile m JOIN geo g ON g.postcode = m.postcode
+			WHERE g.eastings IS NOT NULL AND %s GROUP BY gx, gy ORDER BY gy, gx`,
+			opts.GridSize, strings.Join(means, ", "), strings.Join(conds, " AND "))
+	} else {
+		query = fmt.Sprintf(`SELECT m.postcode, g.latitude, g.longitude, m.%s
+			FROM mobile m JOIN geo g ON g.postcode = m.postcode
+			WHERE %s ORDER BY m.postcode`, strings.Join(cols, ", m."), strings.Join(conds, " AND "))
+	}
+
+	tmp := out + ".partial"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp)
+	defer file.Close()
+	w := bufio.NewWriter(file)
+	w.WriteString(`{"type":"FeatureCollection",`)
+	if b := opts.BBox; b != nil {
+		fmt.Fprintf(w, `"bbox":[%g,%g,%g,%g],`, b.MinLon, b.MinLat, b.MaxLon, b.MaxLat)
+	}
+	w.WriteString(`"features":[`)
+
+	n := 0
+	var werr error
+	err = scanRows(db, query, args, func(row map[string]string) {
+		if werr != nil {
+			return
+		}
+		var feat geoFeature
+		if opts.GridSize > 0 {
+			feat = gridFeature(row, cols, opts.GridSize)
+		} else {
+			feat = pointFeature(row, cols)
+		}
+		b, err := json.Marshal(feat)
+		if err != nil {
+			werr = err
+			return
+		}
+		if n > 0 {
+			w.WriteByte(',')
+		}
+		w.WriteString("\n")
+		_, werr = w.Write(b)
+		n++
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "no such table") {
+			return 0, ErrNoGeoData
+		}
+		return 0, err
+	}
+	if werr != nil {
+		return 0, werr
+	}
+	w.WriteString("\n]}\n")
+	if err := w.Flush(); err != nil {
+		return 0, err
+	}
+	if err := file.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp, out); err != nil {
+		return 0, err
+	}
+	m.Logger.Info("exported geojson", "path", out, "filter", f.String(), "grid_size", opts.GridSize, "features", n)
+	return n, nil
+}
+
+// pointFeature is a postcode centroid carrying its coverage columns.
+func pointFeature(row map[string]string, cols []string) geoFeature {
+	lat, _ := strconv.ParseFloat(row["latitude"], 64)
+	lon, _ := strconv.ParseFloat(row["longitude"], 64)
+	props := map[string]any{"postcode": row["postcode"]}
+	addCoverage(props, row, cols)
+	return geoFeature{
+		Type:       "Feature",
+		Geometry:   geoGeometry{Type: "Point", Coordinates: [2]float64{round6(lon), round6(lat)}},
+		Properties: props,
+	}
+}
+
+// gridFeature is a National Grid square carrying the mean of each coverage
+// column over its postcodes. Its corners are converted to WGS84, so the
+// square is slightly skewed on the map.
+func gridFeature(row map[string]string, cols []string, size int) geoFeature {
+	gx, _ := strconv.Atoi(row["gx"])
+	gy, _ := strconv.Atoi(row["gy"])
+	postcodes, _ := strconv.Atoi(row["postcodes"])
+	e, n := float64(gx*size), float64(gy*size)
+	ring := make([][2]float64, 0, 5)
+	for _, c := range [][2]float64{{e, n}, {e + float64(size), n}, {e + float64(size), n + float64(size)}, {e, n + float64(size)}, {e, n}} {
+		lat, lon := FromGrid(c[0], c[1])
+		ring = append(ring, [2]float64{round6(lon), round6(lat)})
+	}
+	props := map[string]any{"eastings": gx * size, "northings": gy * size, "size_m": size, "postcodes": postcodes}
+	addCoverage(props, row, cols)
+	return geoFeature{
+		Type:       "Feature",
+		Geometry:   geoGeometry{Type: "Polygon", Coordinates: [][][2]float64{ring}},
+		Properties: props,
+	}
+}
+
+// addCoverage sets a property for each of cols holding a value in row,
+// rounded to three places.
+func addCoverage(props map[string]any, row map[string]string, cols []string) {
+	for _, c := range cols {
+		if v, err := strconv.ParseFloat(row[c], 64); err == nil {
+			props[c] = math.Round(v*1000) / 1000
+		}
+	}
+}
+
+// round6 rounds a coordinate to six decimal places, about 10cm.
+func round6(f float64) float64 {
+	return math.Round(f*1e6) / 1e6
+}
+
+// storedColumns returns the canonical coverage columns in db's mobile
+// table, in canonical order; databases built before a column was added
+// lack it.
+func storedColumns(db *sql.DB) ([]string, error) {
+	var have []string
+	err := scanRows(db, `SELECT name FROM pragma_table_info('mobile')`, nil, func(row map[string]string) {
+		have = append(have, row["name"])
+	})
+	if err != nil {
+		return nil, err
+	}
+	var cols []string
+	for _, c := range CanonicalColumns() {
+		if slices.Contains(have, c) {
+			cols = append(cols, c)
+		}
+	}
+	return cols, nil
+}
@@ -0,0 +1,165 @@
+package ofcom
+
+import (
+	"fmt"
+	"math"
+)
+
+// Ellipsoids and the National Grid projection, from the Ordnance Survey's
+// "A guide to coordinate systems in Great Britain".
+const (
+	wgs84A, wgs84B = 6378137.0, 6356752.314245
+	airyA, airyB   = 6377563.396, 6356256.909
+	gridF0         = 0.9996012717
+	gridE0, gridN0 = 400000.0, -100000.0
+)
+
+var gridLat0, gridLon0 = 49 * math.Pi / 180, -2 * math.Pi / 180
+
+// ToGrid converts a WGS84 latitude and longitude, as used by postcodes.io
+// and GPS, to British National Grid eastings and northings in metres. The
+// datum shift is the OS seven-parameter Helmert transformation, good to a
+// few metres — ample for postcode centroids. Points well outside Great
+// Britain are refused.
+func ToGrid(lat, lon float64) (eastings, northings int, err error) {
+	if lat < 49 || lat > 61 || lon < -9 || lon > 2 {
+		return 0, 0, fmt.Errorf("%.5f, %.5f is outside Great Britain", lat, lon)
+	}
+	x, y, z := toCartesian(lat*math.Pi/180, lon*math.Pi/180, wgs84A, wgs84B)
+	x, y, z = helmertToOSGB36(x, y, z)
+	phi, lambda := fromCartesian(x, y, z, airyA, airyB)
+	e, n := project(phi, lambda)
+	return int(math.Round(e)), int(math.Round(n)), nil
+}
+
+// FromGrid converts British National Grid eastings and northings in metres
+// to a WGS84 latitude and longitude, the inverse of ToGrid and as accurate,
+// e.g. for the corners of grid squares drawn on a web map.
+func FromGrid(eastings, northings float64) (lat, lon float64) {
+	phi, lambda := unproject(eastings, northings)
+	x, y, z := toCartesian(phi, lambda, airyA, airyB)
+	x, y, z = helmertToWGS84(x, y, z)
+	phi, lambda = fromCartesian(x, y, z, wgs84A, wgs84B)
+	return phi * 180 / math.Pi, lambda * 180 / math.Pi
+}
+
+func toCartesian(phi, lambda, a, b float64) (x, y, z float64) {
+	e2 := 1 - b*b/(a*a)
+	sinPhi := math.Sin(phi)
+	nu := a / math.Sqrt(1-e2*sinPhi*sinPhi)
+	return nu * math.Cos(phi) * math.Cos(lambda), nu * math.Cos(phi) * math.Sin(lambda), (1 - e2) * nu * sinPhi
+}
+
+func helmertToOSGB36(x, y, z float64) (float64, float64, float64) {
+	const tx, ty, tz = -446.448, 125.157, -542.060
+	const s = 1 + 20.4894e-6
+	arcsec := math.Pi / (180 * 3600)
+	rx, ry, rz := -0.1502*arcsec, -0.2470*arcsec, -0.8421*arcsec
+	return tx + x*s - y*rz + z*ry,
+		ty + x*rz + y*s - z*rx,
+		tz - x*ry + y*rx + z*s
+}
+
+// helmertToWGS84 reverses helmertToOSGB36 by negating its parameters, which
+// is accurate to well under a metre at these rotations.
+func helmertToWGS84(x, y, z float64) (float64, float64, float64) {
+	const tx, ty, tz = 446.448, -125.157, 542.060
+	const s = 1 - 20.4894e-6
+	arcsec := math.Pi / (180 * 3600)
+	rx, ry, rz := 0.1502*arcsec, 0.2470*arcsec, 0.8421*arcsec
+	return tx + x*s - y*rz + z*ry,
+		ty + x*rz + y*s - z*rx,
+		tz - x*ry + y*rx + z*s
+}
+
+func fromCartesian(x, y, z, a, b float64) (phi, lambda float64) {
+	e2 := 1 - b*b/(a*a)
+	p := math.Hypot(x, y)
+	phi = math.Atan2(z, p*(1-e2))
+	for i := 0; i < 10; i++ {
+		sinPhi := math.Sin(phi)
+		nu := a / math.Sqrt(1-e2*sinPhi*sinPhi)
+		next := math.Atan2(z+e2*nu*sinPhi, p)
+		if math.Abs(next-phi) < 1e-12 {
+			phi = next
+			break
+		}
+		phi = next
+	}
+	return phi, math.Atan2(y, x)
+}
+
+// project is the Transverse Mercator projection of an OSGB36 latitude and
+// longitude onto the National Grid.
+func project(phi, lambda float64) (e, n float64) {
+	a, b := airyA, airyB
+	e2 := 1 - b*b/(a*a)
+	sinPhi, cosPhi, tanPhi := math.Sin(phi), math.Cos(phi), math.Tan(phi)
+	nu := a * gridF0 / math.Sqrt(1-e2*sinPhi*sinPhi)
+	rho := a * gridF0 * (1 - e2) / math.Pow(1-e2*sinPhi*sinPhi, 1.5)
+	eta2 := nu/rho - 1
+
+	m := meridionalArc(phi)
+
+	cos3, cos5 := math.Pow(cosPhi, 3), math.Pow(cosPhi, 5)
+	tan2, tan4 := tanPhi*tanPhi, math.Pow(tanPhi, 4)
+	i := m + gridN0
+	ii := nu / 2 * sinPhi * cosPhi
+	iii := nu / 24 * sinPhi * cos3 * (5 - tan2 + 9*eta2)
+	iiia := nu / 720 * sinPhi * cos5 * (61 - 58*tan2 + tan4)
+	iv := nu * cosPhi
+	v := nu / 6 * cos3 * (nu/rho - tan2)
+	vi := nu / 120 * cos5 * (5 - 18*tan2 + tan4 + 14*eta2 - 58*tan2*eta2)
+
+	d := lambda - gridLon0
+	n = i + ii*d*d + iii*math.Pow(d, 4) + iiia*math.Pow(d, 6)
+	e = gridE0 + iv*d + v*math.Pow(d, 3) + vi*math.Pow(d, 5)
+	return e, n
+}
+
+// meridionalArc is the developed meridional arc from the true origin to
+// latitude phi on the Airy ellipsoid, scaled to the National Grid.
+func meridionalArc(phi float64) float64 {
+	nn := (airyA - airyB) / (airyA + airyB)
+	dPhi, sPhi := phi-gridLat0, phi+gridLat0
+	ma := (1 + nn + 1.25*nn*nn + 1.25*nn*nn*nn) * dPhi
+	mb := (3*nn + 3*nn*nn + 21.0/8*nn*nn*nn) * math.Sin(dPhi) * math.Cos(sPhi)
+	mc := (15.0 / 8 * (nn*nn + nn*nn*nn)) * math.Sin(2*dPhi) * math.Cos(2*sPhi)
+	md := 35.0 / 24 * nn * nn * nn * math.Sin(3*dPhi) * math.Cos(3*sPhi)
+	return airyB * gridF0 * (ma - mb + mc - md)
+}
+
+// unproject is the inverse of project: National Grid eastings and
+// northings to an OSGB36 latitude and longitude.
+func unproject(e, n float64) (phi, lambda float64) {
+	a, b := airyA, airyB
+	e2 := 1 - b*b/(a*a)
+	phi = (n-gridN0)/(a*gridF0) + gridLat0
+	for i := 0; i < 20; i++ {
+		d := n - gridN0 - meridionalArc(phi)
+		if math.Abs(d) < 0.00001 {
+			break
+		}
+		phi += d / (a * gridF0)
+	}
+
+	sinPhi, cosPhi, tanPhi := math.Sin(phi), math.Cos(phi), math.Tan(phi)
+	nu := a * gridF0 / math.Sqrt(1-e2*sinPhi*sinPhi)
+	rho := a * gridF0 * (1 - e2) / math.Pow(1-e2*sinPhi*sinPhi, 1.5)
+	eta2 := nu/rho - 1
+	tan2, tan4, tan6 := tanPhi*tanPhi, math.Pow(tanPhi, 4), math.Pow(tanPhi, 6)
+	sec := 1 / cosPhi
+
+	vii := tanPhi / (2 * rho * nu)
+	viii := tanPhi / (24 * rho * math.Pow(nu, 3)) * (5 + 3*tan2 + eta2 - 9*tan2*eta2)
+	ix := tanPhi / (720 * rho * math.Pow(nu, 5)) * (61 + 90*tan2 + 45*tan4)
+	x := sec / nu
+	xi := sec / (6 * math.Pow(nu, 3)) * (nu/rho + 2*tan2)
+	xii := sec / (120 * math.Pow(nu, 5)) * (5 + 28*tan2 + 24*tan4)
+	xiia := sec / (5040 * math.Pow(nu, 7)) * (61 + 662*tan2 + 1320*tan4 + 720*tan6)
+
+	d := e - gridE0
+	phi = phi - vii*d*d + viii*math.Pow(d, 4) - ix*math.Pow(d, 6)
+	lambda = gridLon0 + x*d - xi*math.Pow(d, 3) + xii*math.Pow(d, 5) - xiia*math.Pow(d, 7)
+	return phi, lambda
+}
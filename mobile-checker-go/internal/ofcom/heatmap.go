@@ -0,0 +1,117 @@
+package ofcom
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BBox is a WGS84 bounding box.
+type BBox struct {
+	MinLon, MinLat, MaxLon, MaxLat float64
+}
+
+// Valid reports whether the box is non-empty and within lon/lat ranges.
+func (b BBox) Valid() bool {
+	return b.MinLon < b.MaxLon && b.MinLat < b.MaxLat &&
+		b.MinLon >= -180 && b.MaxLon <= 180 && b.MinLat >= -90 && b.MaxLat <= 90
+}
+
+// ParseBBox parses a bounding box written minLon,minLat,maxLon,maxLat, as
+// in GeoJSON.
+func ParseBBox(s string) (BBox, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return BBox{}, fmt.Errorf("bbox must be minLon,minLat,maxLon,maxLat")
+	}
+	var v [4]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return BBox{}, fmt.Errorf("bbox must be minLon,minLat,maxLon,maxLat")
+		}
+		v[i] = f
+	}
+	box := BBox{MinLon: v[0], MinLat: v[1], MaxLon: v[2], MaxLat: v[3]}
+	if !box.Valid() {
+		return BBox{}, fmt.Errorf("bbox is empty or out of range")
+	}
+	return box, nil
+}
+
+// Grid is coverage averaged over a regular lon/lat grid. Cells are stored
+// row by row from the south-west corner.
+type Grid struct {
+	BBox   BBox
+	Column string
+	Cols   int
+	Rows   int
+	Cells  []GridCell
+}
+
+// GridCell holds the mean coverage (0–1) of the postcode centroids in one
+// cell. Cells with no postcodes have Postcodes == 0.
+type GridCell struct {
+	Mean      float64
+	Postcodes int
+}
+
+// Cell returns the cell at column x, row y.
+func (g *Grid) Cell(x, y int) GridCell {
+	return g.Cells[y*g.Cols+x]
+}
+
+// Heatmap averages column over the geocoded postcode centroids inside box,
+// binned into a cols × rows grid.
+func (m *Manager) Heatmap(box BBox, column string, cols, rows int) (*Grid, error) {
+	if !box.Valid() {
+		return nil, fmt.Errorf("invalid bounding box")
+	}
+	if cols < 1 || rows < 1 {
+		return nil, fmt.Errorf("grid must have at least one cell")
+	}
+	known := false
+	for _, c := range CanonicalColumns() {
+		known = known || c == column
+	}
+	if !known {
+		return nil, fmt.Errorf("unknown coverage column %q", column)
+	}
+	db, release, err := m.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	dx := (box.MaxLon - box.MinLon) / float64(cols)
+	dy := (box.MaxLat - box.MinLat) / float64(rows)
+	query := fmt.Sprintf(`SELECT
+		MIN(CAST((g.longitude - ?) / ? AS INTEGER), ?) AS cx,
+		MIN(CAST((g.latitude - ?) / ? AS INTEGER), ?) AS cy,
+		AVG(m.%s), COUNT(*)
+		FROM mobile m JOIN geo g ON g.postcode = m.postcode
+		WHERE g.longitude BETWEEN ? AND ? AND g.latitude BETWEEN ? AND ?
+		AND m.%[1]s IS NOT NULL
+		GROUP BY cx, cy`, column)
+
+	rs, err := db.Query(query, box.MinLon, dx, cols-1, box.MinLat, dy, rows-1,
+		box.MinLon, box.MaxLon, box.MinLat, box.MaxLat)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such table") {
+			return nil, ErrNoGeoData
+		}
+		return nil, err
+	}
+	defer rs.Close()
+
+	grid := &Grid{BBox: box, Column: column, Cols: cols, Rows: rows, Cells: make([]GridCell, cols*rows)}
+	for rs.Next() {
+		var x, y, n int
+		var mean float64
+		if err := rs.Scan(&x, &y, &mean, &n); err != nil {
+			return nil, err
+		}
+		grid.Cells[y*cols+x] = GridCell{Mean: mean, Postcodes: n}
+	}
+	return grid, rs.Err()
+}
@@ -0,0 +1,177 @@
+package ofcom
+
+import (
+	"database/sql"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// queryBatch is the number of postcodes looked up per SQL statement by
+// QueryPostcodes when no index is loaded.
+const queryBatch = 500
+
+// memIndex is an in-memory copy of the mobile table: a map from postcode to
+// row number over a flat array of coverage values. Values are held as
+// float32, which keeps the published precision at half the memory; NULLs
+// are NaN.
+type memIndex struct {
+	cols []string // coverage columns, excluding postcode
+	rows map[string]int32
+	vals []float32 // len(rows) × len(cols)
+	// sources holds the source column of rows from a nation layer.
+	sources map[string]string
+}
+
+// row returns the raw row for a normalised postcode in the form returned by
+// QueryPostcode, or nil if it is absent.
+func (x *memIndex) row(pc string) map[string]string {
+	i, ok := x.rows[pc]
+	if !ok {
+		return nil
+	}
+	vals := x.vals[int(i)*len(x.cols) : (int(i)+1)*len(x.cols)]
+	row := make(map[string]string, len(x.cols)+1)
+	row["postcode"] = pc
+	if src, ok := x.sources[pc]; ok {
+		row["source"] = src
+	}
+	for j, col := range x.cols {
+		if v := vals[j]; !math.IsNaN(float64(v)) {
+			row[col] = strconv.FormatFloat(float64(v), 'f', -1, 32)
+		}
+	}
+	return row
+}
+
+// LoadIndex reads the whole mobile table into memory so that QueryPostcode
+// and QueryPostcodes answer without touching SQLite, for bulk jobs of many
+// thousands of postcodes. The full UK dataset takes a few hundred MB. The
+// index belongs to the current database file: when Setup or Update replaces
+// it, queries fall back to SQLite until LoadIndex is called again.
+func (m *Manager) LoadIndex() error {
+	h, release, err := m.acquireHandle()
+	if err != nil {
+		return err
+	}
+	defer release()
+	if v, err := schemaVersion(h.db); err != nil {
+		return err
+	} else if v == 0 {
+		return ErrSchemaOutdated
+	}
+
+	var n int
+	if err := h.db.QueryRow("SELECT COUNT(*) FROM mobile").Scan(&n); err != nil {
+		return err
+	}
+	rows, err := h.db.Query("SELECT * FROM mobile")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	all, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	x := &memIndex{rows: make(map[string]int32, n)}
+	pcCol, srcCol := -1, -1
+	for i, col := range all {
+		switch col {
+		case "postcode":
+			pcCol = i
+		case "source":
+			srcCol = i
+		default:
+			x.cols = append(x.cols, col)
+		}
+	}
+	x.vals = make([]float32, 0, n*len(x.cols))
+
+	dest := make([]any, len(all))
+	var pc string
+	var src sql.NullString
+	nums := make([]*float64, len(all))
+	for i := range all {
+		switch i {
+		case pcCol:
+			dest[i] = &pc
+		case srcCol:
+			dest[i] = &src
+		default:
+			dest[i] = &nums[i]
+		}
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		x.rows[pc] = int32(len(x.rows))
+		if src.Valid {
+			if x.sources == nil {
+				x.sources = make(map[string]string)
+			}
+			x.sources[pc] = src.String
+		}
+		for i, v := range nums {
+			if i == pcCol || i == srcCol {
+				continue
+			}
+			if v == nil {
+				x.vals = append(x.vals, float32(math.NaN()))
+			} else {
+				x.vals = append(x.vals, float32(*v))
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	h.index.Store(x)
+	m.Logger.Info("loaded postcode index", "postcodes", len(x.rows), "columns", len(x.cols))
+	return nil
+}
+
+// QueryPostcodes returns the raw rows for several postcodes at once, in
+// input order, with nil for postcodes not in the dataset. It uses the index
+// from LoadIndex if loaded and batched SQL lookups otherwise.
+func (m *Manager) QueryPostcodes(postcodes []string) ([]map[string]string, error) {
+	h, release, err := m.acquireHandle()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	out := make([]map[string]string, len(postcodes))
+	if x := h.index.Load(); x != nil {
+		for i, pc := range postcodes {
+			out[i] = x.row(normalisePostcode(pc))
+		}
+		return out, nil
+	}
+
+	if v, err := schemaVersion(h.db); err != nil {
+		return nil, err
+	} else if v == 0 {
+		return nil, ErrSchemaOutdated
+	}
+	found := make(map[string]map[string]string, len(postcodes))
+	for start := 0; start < len(postcodes); start += queryBatch {
+		end := min(start+queryBatch, len(postcodes))
+		args := make([]any, 0, end-start)
+		for _, pc := range postcodes[start:end] {
+			args = append(args, normalisePostcode(pc))
+		}
+		query := "SELECT * FROM mobile WHERE postcode IN (?" + strings.Repeat(",?", len(args)-1) + ")"
+		if err := scanRows(h.db, query, args, func(row map[string]string) {
+			found[row["postcode"]] = row
+		}); err != nil {
+			return nil, err
+		}
+	}
+	for i, pc := range postcodes {
+		out[i] = found[normalisePostcode(pc)]
+	}
+	return out, nil
+}
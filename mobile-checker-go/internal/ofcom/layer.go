@@ -0,0 +1,130 @@
+package ofcom
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// NationDataURLs lists nation-specific editions Ofcom has published
+// alongside the UK-wide file, by year and then nation. A Layer naming only
+// a nation is downloaded from here. Ofcom has not split any of the
+// editions in MobileDataURLs, so it starts empty.
+var NationDataURLs = map[string]map[string]string{}
+
+// Layer is a nation-specific edition stored over the UK-wide file by
+// setup: its rows replace the UK-wide rows of postcodes in Nation, and
+// are marked with the nation in the mobile table's source column.
+type Layer struct {
+	Nation string
+	// Source is the edition's ZIP or CSV, a local path or an http(s) URL.
+	// Empty means the NationDataURLs entry for the year being set up.
+	Source string
+}
+
+// ParseLayer parses a --layer value, "NATION=SOURCE" or a bare nation
+// for one listed in NationDataURLs, e.g. "scotland=scotland_2024.zip".
+func ParseLayer(s string) (Layer, error) {
+	name, source, _ := strings.Cut(s, "=")
+	nations, err := ParseNations(name)
+	if err != nil {
+		return Layer{}, err
+	}
+	if len(nations) != 1 {
+		return Layer{}, fmt.Errorf("layer %q: want NATION or NATION=ZIP|CSV|URL", s)
+	}
+	return Layer{Nation: nations[0], Source: strings.TrimSpace(source)}, nil
+}
+
+// checkLayers validates the layers: a known nation, once each, and a
+// source for those without a NationDataURLs entry.
+func (o SetupOptions) checkLayers(year string) error {
+	var seen []string
+	for _, l := range o.Layers {
+		if !slices.Contains(Nations, l.Nation) {
+			return fmt.Errorf("unknown layer nation %q", l.Nation)
+		}
+		if slices.Contains(seen, l.Nation) {
+			return fmt.Errorf("more than one layer for %s", l.Nation)
+		}
+		seen = append(seen, l.Nation)
+		if l.Source == "" && NationDataURLs[year][l.Nation] == "" {
+			return fmt.Errorf("no known %s %s edition: give its ZIP, CSV or URL as %s=SOURCE", year, l.Nation, l.Nation)
+		}
+	}
+	return nil
+}
+
+// openLayer opens the CSV of a layer's edition for year, downloading it
+// if its source is a URL.
+func (m *Manager) openLayer(l Layer, year string, opts SetupOptions) (io.ReadCloser, error) {
+	src := l.Source
+	if src == "" {
+		src = NationDataURLs[year][l.Nation]
+	}
+	if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+		m.Logger.Info("downloading nation edition", "nation", l.Nation, "url", src)
+		// Saved to disk rather than memory, and removed once read.
+		path := filepath.Join(m.DataDir, fmt.Sprintf("ofcom_mobile_%s_%s.download", year, l.Nation))
+		if _, _, err := m.downloadFile(src, path, opts); err != nil {
+			return nil, err
+		}
+		rc, err := openZipOrCSV(path, l.Nation)
+		if err != nil {
+			os.Remove(path)
+			return nil, err
+		}
+		return struct {
+			io.Reader
+			io.Closer
+		}{rc, multiCloser{rc, removeFile(path)}}, nil
+	}
+	if !strings.EqualFold(filepath.Ext(src), ".zip") {
+		return os.Open(src)
+	}
+	return openZipOrCSV(src, l.Nation)
+}
+
+// openZipOrCSV opens the largest CSV in the ZIP at path or, if it is not
+// a ZIP, the file itself as a CSV.
+func openZipOrCSV(path, nation string) (io.ReadCloser, error) {
+	zr, err := zip.OpenReader(path)
+	if errors.Is(err, zip.ErrFormat) {
+		return os.Open(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s ZIP: %w", nation, err)
+	}
+	rc, err := openLargestCSV(&zr.Reader)
+	if err != nil {
+		zr.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{rc, multiCloser{rc, zr}}, nil
+}
+
+// removeFile is an io.Closer removing a temporary file.
+type removeFile string
+
+func (f removeFile) Close() error { return os.Remove(string(f)) }
+
+// multiCloser closes each of its closers in turn.
+type multiCloser []io.Closer
+
+func (mc multiCloser) Close() error {
+	var first error
+	for _, c := range mc {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,133 @@
+package ofcom
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUnknownLayout is wrapped by a LayoutError.
+var ErrUnknownLayout = errors.New("unrecognised CSV column layout")
+
+// LayoutError reports a CSV whose headers match no known Ofcom layout, so
+// a database built from it would hold no coverage Interpret could read.
+type LayoutError struct {
+	Year    string
+	Missing string // what was not found, e.g. "postcode column"
+	// Unrecognised are the normalised headers no column is mapped from.
+	Unrecognised []string
+}
+
+func (e *LayoutError) Error() string {
+	msg := fmt.Sprintf("the %s CSV has an unrecognised column layout: no %s found", e.Year, e.Missing)
+	if len(e.Unrecognised) > 0 {
+		msg += "; unrecognised headers: " + strings.Join(e.Unrecognised, ", ")
+	}
+	return msg + " — map them to canonical columns with a --column-map file"
+}
+
+func (e *LayoutError) Unwrap() error { return ErrUnknownLayout }
+
+// ColumnMap maps the headers of an edition this build does not know onto
+// canonical columns, read from a YAML file:
+//
+//	percent: true              # values are 0–100 rather than 0–1
+//	columns:
+//	  postcode: PC
+//	  ee_4g: EE 4G Outdoor
+//	  o2_4g: [O2 4G Outdoor, TF 4G Outdoor]
+//
+// Headers are matched as setup matches them: case-insensitively, with
+// spaces read as underscores. Unmapped columns keep the built-in names.
+type ColumnMap struct {
+	Percent *bool                 `yaml:"percent"`
+	Columns map[string]headerList `yaml:"columns"`
+}
+
+// headerList is one header name or a list of them.
+type headerList []string
+
+func (l *headerList) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*l = headerList{n.Value}
+		return nil
+	}
+	return n.Decode((*[]string)(l))
+}
+
+// LoadColumnMap reads and checks a column-map file.
+func LoadColumnMap(path string) (*ColumnMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read column map: %w", err)
+	}
+	var cm ColumnMap
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cm); err != nil {
+		return nil, fmt.Errorf("invalid column map %s: %w", path, err)
+	}
+	known := append([]string{"postcode"}, CanonicalColumns()...)
+	for col := range cm.Columns {
+		if !slices.Contains(known, col) {
+			return nil, fmt.Errorf("invalid column map %s: unknown column %q (want postcode or e.g. ee_4g, o2_voice_indoor)", path, col)
+		}
+	}
+	return &cm, nil
+}
+
+// WithColumnMap returns the edition with cm's headers checked before its
+// own and cm's percent setting, if given.
+func (e Edition) WithColumnMap(cm *ColumnMap) Edition {
+	if cm == nil {
+		return e
+	}
+	aliases := make(map[string][]string, len(e.Aliases)+len(cm.Columns))
+	for col, names := range e.Aliases {
+		aliases[col] = names
+	}
+	for col, names := range cm.Columns {
+		var norm []string
+		for _, n := range names {
+			norm = append(norm, normaliseHeader(n))
+		}
+		aliases[col] = append(norm, aliases[col]...)
+	}
+	e.Aliases = aliases
+	e.mapped = true
+	if cm.Percent != nil {
+		e.Percent = *cm.Percent
+	}
+	return e
+}
+
+// Detect maps headers with the edition or, if they do not fit it, with the
+// newest known edition whose layout they fit, which it returns, so a file
+// published in an earlier edition's layout still builds. An edition with a
+// column map is never swapped.
+func (e Edition) Detect(headers []string) (Edition, []string, []string, error) {
+	mapping, unknown, err := e.MapHeaders(headers)
+	if err == nil || e.mapped {
+		return e, mapping, unknown, err
+	}
+	years := make([]string, 0, len(Editions))
+	for y := range Editions {
+		years = append(years, y)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(years)))
+	for _, y := range years {
+		alt := Editions[y]
+		if y == e.Year || len(alt.Aliases) == 0 {
+			continue
+		}
+		if m, u, err := alt.MapHeaders(headers); err == nil {
+			return alt, m, u, nil
+		}
+	}
+	return e, nil, nil, err
+}
@@ -0,0 +1,31 @@
+package ofcom
+
+import (
+	"net/url"
+	"strings"
+)
+
+// detailURLs are the operators' own coverage checkers, keyed by prefix,
+// each with %s where the postcode goes. Operators model coverage in more
+// detail than Ofcom's postcode averages and update their maps as sites
+// go live, so the links let users cross-check a result.
+var detailURLs = map[string]string{
+	"ee":       "https://coverage.ee.co.uk/coverage/ee?q=%s",
+	"o2":       "https://www.o2.co.uk/coveragechecker?postcode=%s",
+	"three":    "https://www.three.co.uk/support/network-and-coverage/coverage?postcode=%s",
+	"vodafone": "https://www.vodafone.co.uk/network/status-checker?postcode=%s",
+}
+
+// DetailURL returns the coverage checker of the operator with prefix op,
+// pre-filled with pc, or "" for an unknown operator or empty postcode.
+func DetailURL(op, pc string) string {
+	tmpl, ok := detailURLs[op]
+	pc = normalisePostcode(pc)
+	if !ok || pc == "" {
+		return ""
+	}
+	if len(pc) >= 5 {
+		pc = pc[:len(pc)-3] + " " + pc[len(pc)-3:]
+	}
+	return strings.Replace(tmpl, "%s", url.QueryEscape(pc), 1)
+}
@@ -0,0 +1,145 @@
+package ofcom
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// MaintainOptions selects the work done by Maintain.
+type MaintainOptions struct {
+	Reindex    bool // rebuild every index before vacuuming
+	ReportOnly bool // measure fragmentation without changing the database
+}
+
+// MaintenanceReport describes the database before and after Maintain.
+type MaintenanceReport struct {
+	Path string `json:"path"`
+	// SizeBefore and SizeAfter include the WAL file.
+	SizeBefore int64 `json:"size_before_bytes"`
+	SizeAfter  int64 `json:"size_after_bytes"`
+	WALBefore  int64 `json:"wal_before_bytes"`
+	Pages      int   `json:"pages"`
+	FreePages  int   `json:"free_pages"`
+	// Fragmentation is the share of pages that were free, as a percentage.
+	Fragmentation float64 `json:"fragmentation_pct"`
+	Checkpointed  bool    `json:"checkpointed"`
+	Reindexed     bool    `json:"reindexed"`
+	Vacuumed      bool    `json:"vacuumed"`
+	Analyzed      bool    `json:"analyzed"`
+	DurationMs    int64   `json:"duration_ms"`
+}
+
+// Maintain optionally rebuilds the database's indexes, then runs VACUUM to
+// reclaim free pages and ANALYZE to refresh the query planner's statistics.
+// Setup leaves databases in rollback-journal mode, so there is normally no
+// WAL; one built before that, still in WAL mode, has its WAL checkpointed
+// and truncated first. It waits for running queries, e.g. a server's, to
+// finish, so is safe to run from cron alongside one. With ReportOnly the
+// database is opened read-only and left untouched.
+func (m *Manager) Maintain(opts MaintainOptions) (rep *MaintenanceReport, err error) {
+	if opts.ReportOnly {
+		return m.maintain(opts)
+	}
+	size, wal := m.fileSizes()
+	err = m.Unsealed(func(w *Manager) error {
+		rep, err = w.maintain(opts)
+		return err
+	})
+	if rep != nil && m.sealed != nil {
+		// Report the encrypted database, not the copy maintained.
+		rep.Path, rep.SizeBefore, rep.WALBefore = m.DBPath, size, wal
+		rep.SizeAfter, _ = m.fileSizes()
+	}
+	return rep, err
+}
+
+func (m *Manager) maintain(opts MaintainOptions) (*MaintenanceReport, error) {
+	start := time.Now()
+	rep := &MaintenanceReport{Path: m.DBPath}
+	rep.SizeBefore, rep.WALBefore = m.fileSizes()
+
+	var db *sql.DB
+	var err error
+	if opts.ReportOnly {
+		if _, err := os.Stat(m.DBPath); os.IsNotExist(err) {
+			return nil, ErrDatabaseNotFound
+		}
+		db, err = m.open(m.DBPath, true)
+	} else {
+		db, err = m.openMigrated()
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	// Only one connection, so the pragma applies to every statement.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec("PRAGMA busy_timeout = 30000"); err != nil {
+		return nil, err
+	}
+
+	if err := db.QueryRow("PRAGMA page_count").Scan(&rep.Pages); err != nil {
+		return nil, err
+	}
+	if err := db.QueryRow("PRAGMA freelist_count").Scan(&rep.FreePages); err != nil {
+		return nil, err
+	}
+	if rep.Pages > 0 {
+		rep.Fragmentation = float64(rep.FreePages) / float64(rep.Pages) * 100
+	}
+	if opts.ReportOnly {
+		rep.SizeAfter = rep.SizeBefore
+		rep.DurationMs = time.Since(start).Milliseconds()
+		return rep, nil
+	}
+
+	var journal string
+	if err := db.QueryRow("PRAGMA journal_mode").Scan(&journal); err != nil {
+		return nil, err
+	}
+	wal := strings.EqualFold(journal, "wal")
+
+	steps := []struct {
+		sql  string
+		run  bool
+		done *bool
+	}{
+		{"PRAGMA wal_checkpoint(TRUNCATE)", wal, &rep.Checkpointed},
+		{"REINDEX", opts.Reindex, &rep.Reindexed},
+		{"VACUUM", true, &rep.Vacuumed},
+		{"ANALYZE", true, &rep.Analyzed},
+	}
+	for _, step := range steps {
+		if !step.run {
+			continue
+		}
+		m.Logger.Info("database maintenance", "step", step.sql, "path", m.DBPath)
+		if _, err := db.Exec(step.sql); err != nil {
+			return rep, fmt.Errorf("%s failed: %w", step.sql, err)
+		}
+		*step.done = true
+	}
+	if wal {
+		// VACUUM wrote to the WAL again.
+		db.Exec("PRAGMA wal_checkpoint(TRUNCATE)")
+	}
+
+	rep.SizeAfter, _ = m.fileSizes()
+	rep.DurationMs = time.Since(start).Milliseconds()
+	return rep, nil
+}
+
+// fileSizes returns the size of the database and its WAL together, and of
+// the WAL alone.
+func (m *Manager) fileSizes() (total, wal int64) {
+	if info, err := os.Stat(m.DBPath); err == nil {
+		total = info.Size()
+	}
+	if info, err := os.Stat(m.DBPath + "-wal"); err == nil {
+		wal = info.Size()
+	}
+	return total + wal, wal
+}
@@ -0,0 +1,288 @@
+package ofcom
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MobileDataChecksums maps dataset year to the expected SHA-256 of the
+// Ofcom ZIP. Years without an entry are downloaded unverified, unless
+// SetupOptions.RequireChecksum is set, and their checksum recorded in the
+// local manifest for later comparison.
+var MobileDataChecksums = map[string]string{}
+
+// ManifestPublicKey is the base64 Ed25519 public key checksum manifests
+// must be signed with when SetupOptions.ManifestKey gives none. Release
+// builds set it with -ldflags "-X .../internal/ofcom.ManifestPublicKey=<key>".
+var ManifestPublicKey = ""
+
+// ErrManifestSignature is returned when a checksum manifest's signature is
+// missing or does not verify.
+var ErrManifestSignature = errors.New("checksum manifest signature invalid")
+
+// maxManifestSize bounds the manifest and signature read from ManifestURL.
+const maxManifestSize = 1 << 20
+
+// DefaultDownloadTimeout bounds a dataset download when
+// SetupOptions.DownloadTimeout is 0.
+const DefaultDownloadTimeout = 300 * time.Second
+
+// SetupOptions controls how Setup downloads and builds the dataset.
+type SetupOptions struct {
+	Force       bool   // re-download and rebuild even if data exists
+	SHA256      string // expected ZIP checksum, overriding the bundled one
+	ManifestURL string // JSON object mapping year to SHA-256, fetched before download
+	// ManifestKey is the base64 Ed25519 public key that signed ManifestURL;
+	// ManifestPublicKey when empty. The signature is fetched from
+	// ManifestURL + ".sig", and setup fails unless it verifies.
+	ManifestKey string
+	// RequireChecksum refuses downloads whose checksum is not known in
+	// advance, rather than recording it unverified.
+	RequireChecksum bool
+	IndexURL        string // page searched by Discover; ConnectedNationsIndexURL when empty
+	URL             string // download from this URL instead of the known or discovered one
+	// DownloadTimeout bounds each dataset download, from connecting to
+	// reading the last byte; DefaultDownloadTimeout when 0.
+	DownloadTimeout time.Duration
+	// Columns is the column set to store, ColumnsFull or ColumnsMinimal.
+	// Empty keeps the trimming of the database being replaced, if any.
+	Columns string
+	// Nations limits the rows stored to postcodes in these nations (see
+	// Nations and NationOf); empty means all of the UK.
+	Nations []string
+	// Layers are nation-specific editions stored over the UK-wide file,
+	// in order; see Layer.
+	Layers []Layer
+	// ColumnMap maps the headers of an edition this build does not
+	// recognise; see LoadColumnMap.
+	ColumnMap *ColumnMap
+	// Progress, if set, is called as setup moves through its stages.
+	Progress func(SetupProgress)
+}
+
+// SetupProgress reports how far Setup has got.
+type SetupProgress struct {
+	// Stage is "download", "build" or "ranks".
+	Stage string `json:"stage"`
+	// Done is the bytes downloaded or the rows stored so far.
+	Done int64 `json:"done"`
+	// Total is the bytes to download, when the server says; otherwise 0.
+	Total int64 `json:"total,omitempty"`
+}
+
+// downloadClient returns the HTTP client for dataset downloads.
+func (o SetupOptions) downloadClient() *http.Client {
+	timeout := o.DownloadTimeout
+	if timeout <= 0 {
+		timeout = DefaultDownloadTimeout
+	}
+	return &http.Client{Timeout: timeout}
+}
+
+// progress calls o.Progress, if set.
+func (o SetupOptions) progress(stage string, done, total int64) {
+	if o.Progress != nil {
+		o.Progress(SetupProgress{Stage: stage, Done: done, Total: total})
+	}
+}
+
+// Manifest records where and when the installed dataset was downloaded.
+// It is stored as manifest.json in the data directory.
+type Manifest struct {
+	Year         string    `json:"year"`
+	Revision     int       `json:"revision,omitempty"`
+	SourceURL    string    `json:"source_url"`
+	SHA256       string    `json:"sha256"`
+	Size         int64     `json:"size"`
+	Verified     bool      `json:"verified"`
+	DownloadedAt time.Time `json:"downloaded_at"`
+}
+
+// revision returns the downloaded revision, reading it from SourceURL for
+// manifests written before Revision was recorded.
+func (mf *Manifest) revision() int {
+	if mf.Revision > 0 {
+		return mf.Revision
+	}
+	return RevisionOf(mf.SourceURL)
+}
+
+func (m *Manager) manifestPath() string {
+	return filepath.Join(m.DataDir, "manifest.json")
+}
+
+// Manifest returns the manifest of the installed dataset, or nil if the
+// data was not downloaded by setup.
+func (m *Manager) Manifest() (*Manifest, error) {
+	data, err := os.ReadFile(m.manifestPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var mf Manifest
+	if err := json.Unmarshal(data, &mf); err != nil {
+		return nil, fmt.Errorf("corrupt manifest: %w", err)
+	}
+	return &mf, nil
+}
+
+// Release identifies the Ofcom release a database was built from.
+type Release struct {
+	Year string `json:"year" xml:"year"`
+	// Revision is the Ofcom reissue, e.g. 2 for r02; 0 when unknown.
+	Revision int `json:"revision,omitempty" xml:"revision,omitempty"`
+	// BuiltAt is when setup built the database, RFC 3339; it changes on
+	// every rebuild of the same release.
+	BuiltAt string `json:"built_at,omitempty" xml:"built_at,omitempty"`
+	// Version identifies the build, e.g. "2023@2024-03-01T09:30:12.5Z",
+	// for asking later what has changed since; see ForVersion.
+	Version string `json:"version,omitempty" xml:"version,omitempty"`
+}
+
+// String formats e as "2023 r02", or just the year without a revision.
+func (e Release) String() string {
+	if e.Revision == 0 {
+		return e.Year
+	}
+	return fmt.Sprintf("%s r%02d", e.Year, e.Revision)
+}
+
+// Release returns the release of the installed database. It is read once
+// per database file, so calling it for every check is cheap. Databases
+// built before revisions were recorded take theirs from the manifest.
+func (m *Manager) Release() (*Release, error) {
+	h, release, err := m.acquireHandle()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	if e := h.dataset.Load(); e != nil {
+		return e, nil
+	}
+
+	e := &Release{}
+	if err := h.db.QueryRow(`SELECT value FROM meta WHERE key = 'dataset_year'`).Scan(&e.Year); err != nil {
+		return nil, err
+	}
+	var rev string
+	if err := h.db.QueryRow(`SELECT value FROM meta WHERE key = 'dataset_revision'`).Scan(&rev); err == nil {
+		e.Revision, _ = strconv.Atoi(rev)
+	} else if mf, err := m.Manifest(); err == nil && mf != nil && mf.Year == e.Year {
+		e.Revision = mf.revision()
+	}
+	h.db.QueryRow(`SELECT value FROM meta WHERE key = 'built_at'`).Scan(&e.BuiltAt)
+	if e.BuiltAt != "" {
+		e.Version = e.Year + "@" + e.BuiltAt
+	}
+	h.dataset.Store(e)
+	return e, nil
+}
+
+func (m *Manager) writeManifest(mf Manifest) error {
+	data, err := json.MarshalIndent(mf, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := m.manifestPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, m.manifestPath())
+}
+
+// expectedChecksum resolves the checksum a download for year must match,
+// or "" when none is known. A manifest from opts.ManifestURL is only
+// trusted once its signature verifies, and must list year.
+func expectedChecksum(year string, opts SetupOptions) (string, error) {
+	if opts.SHA256 != "" {
+		return strings.ToLower(opts.SHA256), nil
+	}
+	if opts.ManifestURL != "" {
+		remote, err := fetchManifest(opts.ManifestURL, opts.ManifestKey)
+		if err != nil {
+			return "", err
+		}
+		sum, ok := remote[year]
+		if !ok {
+			return "", fmt.Errorf("checksum manifest has no entry for %s", year)
+		}
+		return strings.ToLower(sum), nil
+	}
+	return strings.ToLower(MobileDataChecksums[year]), nil
+}
+
+// fetchManifest downloads a {year: sha256} manifest and verifies its
+// detached signature, the base64 Ed25519 signature of the manifest's bytes
+// served at url + ".sig", against key (ManifestPublicKey when empty).
+func fetchManifest(url, key string) (map[string]string, error) {
+	if key == "" {
+		key = ManifestPublicKey
+	}
+	if key == "" {
+		return nil, fmt.Errorf("%w: no public key to verify it with — pass --manifest-key", ErrManifestSignature)
+	}
+	pub, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid manifest key: want a base64 Ed25519 public key")
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	data, err := fetchSmall(client, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch checksum manifest: %w", err)
+	}
+	sigText, err := fetchSmall(client, url+".sig")
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to fetch signature: %v", ErrManifestSignature, err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sigText)))
+	if err != nil || !ed25519.Verify(ed25519.PublicKey(pub), data, sig) {
+		return nil, ErrManifestSignature
+	}
+
+	var remote map[string]string
+	if err := json.Unmarshal(data, &remote); err != nil {
+		return nil, fmt.Errorf("failed to parse checksum manifest: %w", err)
+	}
+	return remote, nil
+}
+
+// fetchSmall GETs url, failing on a non-200 status or a body larger than
+// maxManifestSize.
+func fetchSmall(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxManifestSize {
+		return nil, fmt.Errorf("larger than %d bytes", maxManifestSize)
+	}
+	return data, nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,97 @@
+package ofcom
+
+import (
+	"io/fs"
+	"strconv"
+	"strings"
+	"sync"
+
+	"modernc.org/sqlite/vfs"
+)
+
+// mounts is the one VFS through which remote and encrypted databases are
+// read, each mounted under its own first path element. vfs.FS.Close
+// crashes in the modernc.org/sqlite release this module builds with, so a
+// VFS registered per database could never be removed and every Reload
+// would leak one; a mount is removed when the last handle reading it
+// closes.
+var mounts mountFS
+
+type mountFS struct {
+	once sync.Once
+	name string // the registered VFS
+	err  error  // from registering it
+
+	mu   sync.RWMutex
+	fss  map[string]fs.FS
+	next uint64
+}
+
+// mount makes fsys readable by SQLite as the files under prefix in the
+// VFS called name.
+func (m *mountFS) mount(fsys fs.FS) (name, prefix string, err error) {
+	m.once.Do(func() { m.name, _, m.err = vfs.New(m) })
+	if m.err != nil {
+		return "", "", m.err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.fss == nil {
+		m.fss = make(map[string]fs.FS)
+	}
+	m.next++
+	prefix = "m" + strconv.FormatUint(m.next, 10)
+	m.fss[prefix] = fsys
+	return m.name, prefix, nil
+}
+
+// unmount removes the files under prefix; SQLite can no longer open them.
+func (m *mountFS) unmount(prefix string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.fss, prefix)
+}
+
+// Open implements fs.FS.
+func (m *mountFS) Open(name string) (fs.File, error) {
+	prefix, rest, _ := strings.Cut(name, "/")
+	m.mu.RLock()
+	fsys := m.fss[prefix]
+	m.mu.RUnlock()
+	if fsys == nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return fsys.Open(rest)
+}
+
+// mountPoint is where one fs.FS is mounted, mounted lazily so a Manager
+// used again after Close mounts it afresh.
+type mountPoint struct {
+	mu     sync.Mutex
+	vfs    string // the VFS the files are in
+	prefix string // "" until mounted
+}
+
+// mount mounts fsys at p, if it is not already, returning the VFS and the
+// prefix of its files there.
+func (p *mountPoint) mount(fsys fs.FS) (vfsName, prefix string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.prefix == "" {
+		if p.vfs, p.prefix, err = mounts.mount(fsys); err != nil {
+			return "", "", err
+		}
+	}
+	return p.vfs, p.prefix, nil
+}
+
+// unmount makes the files at p unreadable by SQLite; call it once no
+// connection uses them.
+func (p *mountPoint) unmount() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.prefix != "" {
+		mounts.unmount(p.prefix)
+		p.prefix = ""
+	}
+}
@@ -0,0 +1,97 @@
+package ofcom
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// MVNO is a mobile brand without its own radio network, and the host
+// network whose coverage its customers get.
+type MVNO struct {
+	Brand string // display name, e.g. "giffgaff"
+	Host  string // operator prefix, e.g. "o2"
+}
+
+// mvnos maps brands, keyed by mvnoKey, to their host networks.
+var mvnos = map[string]MVNO{
+	"bt":               {"BT Mobile", "ee"},
+	"plusnet":          {"Plusnet Mobile", "ee"},
+	"1p":               {"1pMobile", "ee"},
+	"utilitywarehouse": {"Utility Warehouse", "ee"},
+	"giffgaff":         {"giffgaff", "o2"},
+	"tesco":            {"Tesco Mobile", "o2"},
+	"sky":              {"Sky Mobile", "o2"},
+	"virgin":           {"Virgin Mobile", "o2"},
+	"smarty":           {"SMARTY", "three"},
+	"id":               {"iD Mobile", "three"},
+	"superdrug":        {"Superdrug Mobile", "three"},
+	"voxi":             {"VOXI", "vodafone"},
+	"asda":             {"Asda Mobile", "vodafone"},
+	"lebara":           {"Lebara", "vodafone"},
+	"talk":             {"Talkmobile", "vodafone"},
+}
+
+// mvnoKey normalises a brand name: lower case, without spaces or a
+// trailing "mobile", so "Tesco Mobile", "tesco" and "TescoMobile" match.
+func mvnoKey(name string) string {
+	key := strings.ToLower(strings.Join(strings.Fields(name), ""))
+	if k := strings.TrimSuffix(key, "mobile"); k != "" {
+		key = k
+	}
+	return key
+}
+
+// LookupMVNO returns the MVNO a brand name refers to, case-insensitively.
+func LookupMVNO(name string) (MVNO, bool) {
+	m, ok := mvnos[mvnoKey(name)]
+	return m, ok
+}
+
+// MVNOs returns the known brands sorted by host network, then brand.
+func MVNOs() []MVNO {
+	out := make([]MVNO, 0, len(mvnos))
+	for _, m := range mvnos {
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Host != out[j].Host {
+			return out[i].Host < out[j].Host
+		}
+		return strings.ToLower(out[i].Brand) < strings.ToLower(out[j].Brand)
+	})
+	return out
+}
+
+// ParseOperatorList is ParseOperators that also reports which MVNO brands
+// were asked for, keyed by host prefix, e.g. {"o2": ["giffgaff"]} for
+// "giffgaff", so results can be labelled with them.
+func ParseOperatorList(list string) (ops []string, brands map[string][]string, err error) {
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		op, ok := operatorPrefix(name)
+		if !ok {
+			m, isMVNO := LookupMVNO(name)
+			if !isMVNO {
+				return nil, nil, fmt.Errorf("unknown operator %q (want ee, o2, three or vodafone, or a brand such as giffgaff)", name)
+			}
+			op = m.Host
+			if brands == nil {
+				brands = make(map[string][]string)
+			}
+			if !slices.Contains(brands[op], m.Brand) {
+				brands[op] = append(brands[op], m.Brand)
+			}
+		}
+		if !seen[op] {
+			seen[op] = true
+			ops = append(ops, op)
+		}
+	}
+	return ops, brands, nil
+}
@@ -0,0 +1,255 @@
+package ofcom
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// NearestOptions selects the coverage a nearest-postcode search looks for.
+type NearestOptions struct {
+	Operator string  // operator display name or prefix, e.g. "Three"
+	Tech     string  // "voice", "4g" or "5g"
+	Indoor   bool    // search indoor rather than outdoor coverage
+	Limit    int     // maximum number of postcodes to return
+	MaxKm    float64 // search radius limit
+}
+
+// NearbyPostcode is a covered postcode found by Nearest.
+type NearbyPostcode struct {
+	Postcode   string  `json:"postcode" xml:"postcode"`
+	DistanceKm float64 `json:"distance_km" xml:"distance_km"`
+	Coverage   string  `json:"coverage,omitempty" xml:"coverage,omitempty"`
+}
+
+// OperatorPrefix resolves an operator display name or column prefix, or
+// an MVNO brand such as giffgaff (see LookupMVNO), case-insensitively, to
+// its canonical prefix.
+func OperatorPrefix(name string) (string, bool) {
+	if op, ok := operatorPrefix(name); ok {
+		return op, true
+	}
+	if m, ok := LookupMVNO(name); ok {
+		return m.Host, true
+	}
+	return "", false
+}
+
+// operatorPrefix is OperatorPrefix for the four networks only.
+func operatorPrefix(name string) (string, bool) {
+	for prefix, display := range operatorNames {
+		if strings.EqualFold(name, prefix) || strings.EqualFold(name, display) {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// ParseOperators resolves a comma-separated list of operator names,
+// prefixes or MVNO brands, e.g. "ee,three" or "giffgaff", to canonical
+// prefixes in the order given. An empty list yields nil, meaning all
+// operators.
+func ParseOperators(list string) ([]string, error) {
+	ops, _, err := ParseOperatorList(list)
+	return ops, err
+}
+
+// Column returns the canonical mobile column the options search on.
+func (o NearestOptions) Column() (string, error) {
+	return CoverageColumn(o.Operator, o.Tech, o.Indoor)
+}
+
+// CoverageColumn resolves an operator, technology ("voice", "4g" or "5g")
+// and indoor flag to a canonical mobile column such as "three_5g_indoor".
+func CoverageColumn(operator, tech string, indoor bool) (string, error) {
+	op, ok := OperatorPrefix(operator)
+	if !ok {
+		return "", fmt.Errorf("unknown operator %q", operator)
+	}
+	tech = strings.ToLower(tech)
+	switch tech {
+	case "voice", "4g", "5g":
+	default:
+		return "", fmt.Errorf("unknown technology %q (want voice, 4g or 5g)", tech)
+	}
+	if indoor {
+		tech += "_indoor"
+	}
+	return op + "_" + tech, nil
+}
+
+// Nearest returns the closest geocoded postcodes to the given British
+// National Grid position that meet CoverageThreshold for the requested
+// operator and technology, nearest first. The search starts at 1 km and
+// doubles its radius until enough matches are found or MaxKm is reached.
+func (m *Manager) Nearest(eastings, northings int, opts NearestOptions) ([]NearbyPostcode, error) {
+	col, err := opts.Column()
+	if err != nil {
+		return nil, err
+	}
+	if opts.Limit < 1 {
+		opts.Limit = 5
+	}
+	if opts.MaxKm <= 0 {
+		opts.MaxKm = 20
+	}
+	db, release, err := m.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	query := fmt.Sprintf(`SELECT m.postcode, m.%[1]s,
+		(g.eastings - ?) * (g.eastings - ?) + (g.northings - ?) * (g.northings - ?) AS d2
+		FROM mobile m JOIN geo g ON g.postcode = m.postcode
+		WHERE m.%[1]s >= ? AND g.eastings > 0 AND g.northings > 0
+		AND g.eastings BETWEEN ? AND ? AND g.northings BETWEEN ? AND ?
+		ORDER BY d2 LIMIT ?`, col)
+
+	maxM := opts.MaxKm * 1000
+	for radius := math.Min(1000, maxM); ; radius = math.Min(radius*2, maxM) {
+		r := int(radius)
+		rows, err := db.Query(query, eastings, eastings, northings, northings, CoverageThreshold,
+			eastings-r, eastings+r, northings-r, northings+r, opts.Limit)
+		if err != nil {
+			if strings.Contains(err.Error(), "no such table") {
+				return nil, ErrNoGeoData
+			}
+			return nil, err
+		}
+
+		var found []NearbyPostcode
+		for rows.Next() {
+			var pc string
+			var value, d2 float64
+			if err := rows.Scan(&pc, &value, &d2); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			// The box's corners reach beyond the radius; only points inside
+			// the circle are guaranteed to be the nearest.
+			if d := math.Sqrt(d2); d <= radius {
+				found = append(found, NearbyPostcode{
+					Postcode:   pc,
+					DistanceKm: math.Round(d/100) / 10,
+					Coverage:   fmt.Sprintf("%.0f%%", value*100),
+				})
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		if len(found) >= opts.Limit || radius >= maxM {
+			return found, nil
+		}
+	}
+}
+
+// MaxWithinKm caps the radius searched by Within.
+const MaxWithinKm = 10
+
+// WithinOptions selects the coverage Within reports.
+type WithinOptions struct {
+	Operator string  // operator display name or prefix, e.g. "Vodafone"
+	Tech     string  // "voice", "4g" or "5g"
+	Indoor   bool    // report indoor rather than outdoor coverage
+	RadiusKm float64 // up to MaxWithinKm
+	// Limit is the most postcodes returned; 0 means 1000.
+	Limit int
+	// Threshold is the fraction that counts as covered; 0 means
+	// CoverageThreshold.
+	Threshold float64
+}
+
+// PostcodeCoverage is a postcode found by Within with its coverage.
+type PostcodeCoverage struct {
+	Postcode    string  `json:"postcode" xml:"postcode"`
+	DistanceKm  float64 `json:"distance_km" xml:"distance_km"`
+	Latitude    float64 `json:"latitude" xml:"latitude"`
+	Longitude   float64 `json:"longitude" xml:"longitude"`
+	CoveragePct float64 `json:"coverage_pct" xml:"coverage_pct"`
+	// Covered is whether CoveragePct meets the threshold.
+	Covered bool `json:"covered" xml:"covered"`
+}
+
+// WithinResult lists the postcodes around a point, nearest first.
+type WithinResult struct {
+	Eastings  int                `json:"eastings" xml:"eastings"`
+	Northings int                `json:"northings" xml:"northings"`
+	RadiusKm  float64            `json:"radius_km" xml:"radius_km"`
+	Column    string             `json:"column" xml:"column"`
+	Covered   int                `json:"covered" xml:"covered"`
+	Uncovered int                `json:"uncovered" xml:"uncovered"`
+	Postcodes []PostcodeCoverage `json:"postcodes" xml:"postcodes>postcode"`
+	// Truncated is set when more postcodes than Limit are in range; the
+	// counts cover only those returned.
+	Truncated bool `json:"truncated,omitempty" xml:"truncated,omitempty"`
+}
+
+// Within returns every geocoded postcode within opts.RadiusKm of a British
+// National Grid position, with its coverage for the requested operator and
+// technology, nearest first. Postcodes without a value for that coverage
+// are left out.
+func (m *Manager) Within(eastings, northings int, opts WithinOptions) (*WithinResult, error) {
+	col, err := CoverageColumn(opts.Operator, opts.Tech, opts.Indoor)
+	if err != nil {
+		return nil, err
+	}
+	if opts.RadiusKm <= 0 || opts.RadiusKm > MaxWithinKm {
+		return nil, fmt.Errorf("radius must be more than 0 and at most %d km", MaxWithinKm)
+	}
+	if opts.Limit < 1 {
+		opts.Limit = 1000
+	}
+	threshold := opts.Threshold
+	if threshold <= 0 {
+		threshold = CoverageThreshold
+	}
+	db, release, err := m.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	r := int(math.Ceil(opts.RadiusKm * 1000))
+	query := fmt.Sprintf(`SELECT m.postcode, m.%[1]s, g.latitude, g.longitude,
+		(g.eastings - ?) * (g.eastings - ?) + (g.northings - ?) * (g.northings - ?) AS d2
+		FROM mobile m JOIN geo g ON g.postcode = m.postcode
+		WHERE m.%[1]s IS NOT NULL AND g.eastings > 0 AND g.northings > 0
+		AND g.eastings BETWEEN ? AND ? AND g.northings BETWEEN ? AND ?
+		AND d2 <= ?
+		ORDER BY d2 LIMIT ?`, col)
+	rows, err := db.Query(query, eastings, eastings, northings, northings,
+		eastings-r, eastings+r, northings-r, northings+r, r*r, opts.Limit+1)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such table") {
+			return nil, ErrNoGeoData
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := &WithinResult{Eastings: eastings, Northings: northings, RadiusKm: opts.RadiusKm, Column: col, Postcodes: []PostcodeCoverage{}}
+	for rows.Next() {
+		var p PostcodeCoverage
+		var value, d2 float64
+		if err := rows.Scan(&p.Postcode, &value, &p.Latitude, &p.Longitude, &d2); err != nil {
+			return nil, err
+		}
+		if len(res.Postcodes) == opts.Limit {
+			res.Truncated = true
+			break
+		}
+		p.DistanceKm = math.Round(math.Sqrt(d2)/10) / 100
+		p.CoveragePct = math.Round(value*1000) / 10
+		p.Covered = value >= threshold
+		if p.Covered {
+			res.Covered++
+		} else {
+			res.Uncovered++
+		}
+		res.Postcodes = append(res.Postcodes, p)
+	}
+	return res, rows.Err()
+}
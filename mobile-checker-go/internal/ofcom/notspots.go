@@ -0,0 +1,125 @@
+package ofcom
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// NotSpotOptions selects the postcodes NotSpots lists.
+type NotSpotOptions struct {
+	// Area limits the search as for Export; --district and --region need
+	// geographic data. Empty searches the whole dataset.
+	Area   ExportFilter
+	Tech   string // "voice", "4g" or "5g"
+	Indoor bool   // judge indoor rather than outdoor coverage
+	// MinOperators lists postcodes where fewer than this many operators
+	// meet the threshold; 0 means 1, i.e. no operator at all.
+	MinOperators int
+	// Threshold is the fraction of a postcode an operator must cover; 0
+	// means CoverageThreshold.
+	Threshold float64
+	// Sort is "premises", the most premises first, or "postcode".
+	// Postcodes without premises data sort after those with.
+	Sort  string
+	Limit int // 0 means no limit
+}
+
+// NotSpot is a postcode with too few operators covering it.
+type NotSpot struct {
+	Postcode string `json:"postcode"`
+	// Operators are the networks that do meet the threshold, if any.
+	Operators []string `json:"operators"`
+	// Premises is the postcode's premises count from Ofcom's premises
+	// file (setup --premises), a proxy for the people affected; nil
+	// without it.
+	Premises *int `json:"premises,omitempty"`
+}
+
+// NotSpots lists postcodes where fewer than opts.MinOperators operators
+// cover opts.Tech, for finding the places worst served. Missing values
+// count as not covered.
+func (m *Manager) NotSpots(opts NotSpotOptions) ([]NotSpot, error) {
+	tech := strings.ToLower(opts.Tech)
+	switch tech {
+	case "voice", "4g", "5g":
+	default:
+		return nil, fmt.Errorf("unknown technology %q (want voice, 4g or 5g)", opts.Tech)
+	}
+	if opts.Indoor {
+		tech += "_indoor"
+	}
+	want := opts.MinOperators
+	if want == 0 {
+		want = 1
+	}
+	if want < 1 || want > len(Operators) {
+		return nil, fmt.Errorf("operators must be between 1 and %d", len(Operators))
+	}
+	threshold := opts.Threshold
+	if threshold == 0 {
+		threshold = CoverageThreshold
+	}
+	if err := CheckThreshold(threshold); err != nil {
+		return nil, err
+	}
+	order := "p.all_premises IS NULL, p.all_premises DESC, m.postcode"
+	switch opts.Sort {
+	case "", "premises":
+	case "postcode":
+		order = "m.postcode"
+	default:
+		return nil, fmt.Errorf("unknown sort %q (want premises or postcode)", opts.Sort)
+	}
+	if _, err := os.Stat(m.DBPath); os.IsNotExist(err) {
+		return nil, ErrDatabaseNotFound
+	}
+	db, err := m.openMigrated()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	cols := make([]string, len(Operators))
+	covered := make([]string, len(Operators))
+	for i, op := range Operators {
+		cols[i] = "m." + op + "_" + tech
+		covered[i] = fmt.Sprintf("(COALESCE(%s, 0) >= %g)", cols[i], threshold)
+	}
+	query := fmt.Sprintf(`SELECT m.postcode, p.all_premises, %s
+		FROM mobile m LEFT JOIN premises p ON p.postcode = m.postcode`, strings.Join(cols, ", "))
+	where, args := opts.Area.where()
+	if strings.Contains(where, "g.") {
+		query += " JOIN geo g ON g.postcode = m.postcode"
+	}
+	conds := []string{fmt.Sprintf("%s < %d", strings.Join(covered, " + "), want)}
+	if where != "" {
+		conds = append(conds, where)
+	}
+	query += " WHERE " + strings.Join(conds, " AND ") + " ORDER BY " + order
+	if opts.Limit > 0 {
+		query += " LIMIT " + strconv.Itoa(opts.Limit)
+	}
+
+	spots := []NotSpot{}
+	err = scanRows(db, query, args, func(row map[string]string) {
+		s := NotSpot{Postcode: row["postcode"], Operators: []string{}}
+		if n, err := strconv.Atoi(row["all_premises"]); err == nil {
+			s.Premises = &n
+		}
+		for _, op := range Operators {
+			if v, err := strconv.ParseFloat(row[op+"_"+tech], 64); err == nil && v >= threshold {
+				s.Operators = append(s.Operators, operatorNames[op])
+			}
+		}
+		spots = append(spots, s)
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "no such table: geo") {
+			return nil, ErrNoGeoData
+		}
+		return nil, err
+	}
+	return spots, nil
+}
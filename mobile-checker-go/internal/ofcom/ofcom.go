@@ -6,29 +6,49 @@ package ofcom
 
 import (
 	"archive/zip"
-	"bytes"
 	"database/sql"
-	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
+	"log/slog"
+	"math"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
-
-	_ "github.com/mattn/go-sqlite3"
 )
 
 // MobileDataURLs maps dataset year to Ofcom mobile coverage download URL.
-// Check https://www.ofcom.org.uk/research-and-data/telecoms-research/connected-nations
-// for updated URLs when a new edition is released.
+// Years without an entry, and "latest", are found with Discover.
 var MobileDataURLs = map[string]string{
 	"2023": "https://www.ofcom.org.uk/siteassets/resources/documents/research-and-data/telecoms-research/connected-nations/connected-nations-2023/interactive-report/2023_mobile_pc_r01.zip",
 	"2022": "https://www.ofcom.org.uk/siteassets/resources/documents/research-and-data/telecoms-research/connected-nations/connected-nations-2022/interactive-report/2022_mobile_pc_r03.zip",
 }
 
+var (
+	// ErrDatabaseNotFound is returned when setup has not been run.
+	ErrDatabaseNotFound = errors.New("database not found — run 'setup' first")
+	// ErrSchemaOutdated is returned for databases built before schema versioning.
+	ErrSchemaOutdated = errors.New("database was built by an older version — run 'setup --force'")
+)
+
+// CoverageThreshold is the fraction of a postcode that must be covered for
+// an operator/technology to count as available.
+const CoverageThreshold = 0.5
+
+// CheckThreshold returns an error unless t, given by a user, can be used
+// as a coverage threshold: a fraction above 0 and at most 1. Zero is
+// rejected rather than taken as CoverageThreshold, as it is in the option
+// structs, so that asking for it is never silently ignored.
+func CheckThreshold(t float64) error {
+	if t <= 0 || t > 1 {
+		return fmt.Errorf("invalid coverage threshold %g: must be above 0 and at most 1", t)
+	}
+	return nil
+}
+
 // MobileRow represents mobile coverage data for a postcode.
 type MobileRow struct {
 	Postcode        string
@@ -50,8 +70,28 @@ type MobileRow struct {
 // MobileSummary holds human-readable mobile coverage for a postcode.
 type MobileSummary struct {
 	Postcode  string
+	Indoor    bool
 	Operators []OperatorCoverage
 	Overall   OverallCoverage
+	// CoverageScore is a 0–100 weighted score across the reported operators;
+	// see ScoreWeights.
+	CoverageScore int
+	// Grade is CoverageScore as a letter from A (best) to E.
+	Grade string
+	// Estimated is set when the postcode is not in the dataset and its
+	// coverage was weighted from the nearby postcodes in EstimatedFrom; see
+	// Manager.Estimate.
+	Estimated     bool             `json:",omitempty" xml:",omitempty"`
+	EstimatedFrom []NearbyPostcode `json:",omitempty" xml:",omitempty"`
+	// Premises is the number of premises in the postcode, set when Ofcom's
+	// premises coverage was imported; see Manager.ImportPremises.
+	Premises int `json:",omitempty" xml:",omitempty"`
+	// Rank places the postcode's standard score nationally and locally;
+	// see Manager.RankOf.
+	Rank *Rank `json:",omitempty" xml:",omitempty"`
+	// Layer is the nation whose own edition the coverage came from, when
+	// setup layered one over the UK-wide file; see SetupOptions.Layers.
+	Layer string `json:",omitempty" xml:",omitempty"`
 }
 
 // OperatorCoverage holds coverage data for a single operator.
@@ -63,6 +103,55 @@ type OperatorCoverage struct {
 	HasVoice    bool
 	HasFourG    bool
 	HasFiveG    bool
+	// Tier grades the operator's 4G coverage, the technology most data
+	// relies on; Tiers grades each technology reported, keyed "voice",
+	// "4g", "5g" and, where published, "3g" and "2g".
+	Tier  Tier
+	Tiers Tiers
+	// ThreeG and TwoG are set only when the dataset edition publishes
+	// legacy-technology coverage; see HasLegacy.
+	ThreeG    string `json:",omitempty" xml:",omitempty"`
+	TwoG      string `json:",omitempty" xml:",omitempty"`
+	HasThreeG bool   `json:",omitempty" xml:",omitempty"`
+	HasTwoG   bool   `json:",omitempty" xml:",omitempty"`
+	// Brands are the MVNOs on this network that were asked about, e.g.
+	// giffgaff on O2.
+	Brands []string `json:",omitempty" xml:",omitempty"`
+	// Premises counts the postcode's premises this operator covers, where
+	// premises coverage was imported; see MobileSummary.AddPremises.
+	Premises *PremisesCoverage `json:",omitempty" xml:",omitempty"`
+	// DetailURL is the operator's own coverage checker for the postcode,
+	// to cross-check Ofcom's figures against its live predictions.
+	DetailURL string `json:",omitempty" xml:",omitempty"`
+	// Indoor is the operator's indoor coverage, reported alongside
+	// outdoor coverage when asked for with InterpretOptions.AddIndoor.
+	Indoor *IndoorCoverage `json:",omitempty" xml:",omitempty"`
+}
+
+// IndoorCoverage is an operator's indoor voice, 4G and 5G coverage.
+type IndoorCoverage struct {
+	Voice    string
+	FourG    string
+	FiveG    string
+	HasVoice bool
+	HasFourG bool
+	HasFiveG bool
+	Tiers    Tiers
+}
+
+// Label is the operator's name followed by any Brands, e.g.
+// "O2 (giffgaff)".
+func (o OperatorCoverage) Label() string {
+	if len(o.Brands) == 0 {
+		return o.Name
+	}
+	return o.Name + " (" + strings.Join(o.Brands, ", ") + ")"
+}
+
+// HasLegacy reports whether 2G or 3G coverage was published for the
+// operator.
+func (o OperatorCoverage) HasLegacy() bool {
+	return o.ThreeG != "" || o.TwoG != ""
 }
 
 // OverallCoverage summarises coverage across all operators.
@@ -76,70 +165,194 @@ type OverallCoverage struct {
 type Manager struct {
 	DataDir string
 	DBPath  string
+	Driver  Driver
+	Logger  *slog.Logger
+
+	mu     sync.RWMutex
+	reader *readHandle
+	remote *remoteDB // set by WithRemote
+	sealed *sealedFS // set by WithEncryptionKey
+
+	sealMu       sync.Mutex
+	unsealed     int        // depth of Unsealed calls
+	work         *Manager   // the decrypted copy Unsealed calls write
+	unsealedFrom string     // for work, the database it is a copy of
+	warnPlain    sync.Once  // about an unencrypted database despite a key
+	cleanStale   sync.Once  // removes a decrypted copy left by a crash
+	sealedMnt    mountPoint // where sealed is mounted for this Manager's reads
+
+	yearsMu sync.Mutex
+	years   map[string]*Manager // other dataset years, opened by ForYear
+}
+
+// Option configures a Manager.
+type Option func(*Manager)
+
+// WithLogger sets the logger used for progress and diagnostics.
+func WithLogger(l *slog.Logger) Option {
+	return func(m *Manager) { m.Logger = l }
+}
+
+// WithDBPath reads and builds the current dataset at path instead of
+// mobile.db in the data directory.
+func WithDBPath(path string) Option {
+	return func(m *Manager) { m.DBPath = path }
 }
 
 // NewManager creates a new Manager.
-func NewManager(dataDir string) *Manager {
-	return &Manager{
+func NewManager(dataDir string, opts ...Option) *Manager {
+	m := &Manager{
 		DataDir: dataDir,
 		DBPath:  filepath.Join(dataDir, "mobile.db"),
+		Driver:  DefaultDriver,
+		Logger:  slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(m)
 	}
+	return m
 }
 
 // Setup downloads and builds the local SQLite database.
-func (m *Manager) Setup(year string, force bool) error {
+func (m *Manager) Setup(year string, opts SetupOptions) error {
+	return m.Unsealed(func(w *Manager) error { return w.setup(year, opts) })
+}
+
+func (m *Manager) setup(year string, opts SetupOptions) error {
 	if err := os.MkdirAll(m.DataDir, 0755); err != nil {
 		return fmt.Errorf("failed to create data directory: %w", err)
 	}
+	if err := opts.checkTrim(); err != nil {
+		return err
+	}
 
+	url, ok := MobileDataURLs[year]
+	if opts.URL != "" {
+		url, ok = opts.URL, true
+	}
+	if !ok && year != LatestYear && !opts.Force {
+		// A CSV placed in the data directory needs no download.
+		if _, err := os.Stat(filepath.Join(m.DataDir, fmt.Sprintf("ofcom_mobile_%s.csv", year))); err == nil {
+			ok = true
+		}
+	}
+	if !opts.Force && year != LatestYear {
+		// Another year is installed: keep it current and add this one
+		// alongside for ForYear.
+		if cur := m.installedYear(m.DBPath); cur != "" && cur != year {
+			m.Logger.Info("installing dataset year alongside current dataset", "year", year, "current", cur)
+			return m.yearManager(year).Setup(year, opts)
+		}
+	}
+	discovered := !ok
+	if !ok {
+		m.Logger.Info("searching Ofcom site for mobile dataset", "year", year)
+		found, err := Discover(opts.IndexURL, year)
+		if err != nil {
+			return fmt.Errorf("no known URL for year %q and discovery failed: %w", year, err)
+		}
+		m.Logger.Info("found Ofcom mobile dataset", "year", found.Year, "revision", found.Revision, "url", found.URL)
+		year, url = found.Year, found.URL
+	}
+
+	if err := opts.checkLayers(year); err != nil {
+		return err
+	}
 	csvPath := filepath.Join(m.DataDir, fmt.Sprintf("ofcom_mobile_%s.csv", year))
 
-	if _, err := os.Stat(csvPath); os.IsNotExist(err) || force {
-		if err := m.downloadData(year, csvPath); err != nil {
+	if _, err := os.Stat(csvPath); os.IsNotExist(err) || opts.Force {
+		if !discovered && opts.URL == "" {
+			url = m.newestRevision(year, url, opts)
+		}
+		if err := m.downloadData(year, url, csvPath, opts); err != nil {
 			return fmt.Errorf("download failed: %w", err)
 		}
 	} else {
-		fmt.Printf("Mobile CSV already exists at %s, skipping download.\n", csvPath)
+		m.Logger.Info("mobile CSV already exists, skipping download", "path", csvPath)
 	}
 
-	if _, err := os.Stat(m.DBPath); os.IsNotExist(err) || force {
-		if err := m.buildDatabase(csvPath); err != nil {
+	if _, err := os.Stat(m.DBPath); os.IsNotExist(err) || opts.Force {
+		m.inheritTrim(m.DBPath, &opts)
+		if err := m.buildDatabase(csvPath, year, opts); err != nil {
 			return fmt.Errorf("database build failed: %w", err)
 		}
+		// The database is no longer the embedded one; keep it on upgrade.
+		os.Remove(m.bundleStampPath())
 	} else {
-		fmt.Printf("Mobile database already exists at %s.\n", m.DBPath)
+		m.Logger.Info("mobile database already exists", "path", m.DBPath)
+		if opts.Columns != "" || len(opts.Nations) > 0 || len(opts.Layers) > 0 {
+			m.Logger.Warn("database not rebuilt, so columns, nations and layers are unchanged: use --force to rebuild")
+		}
+		db, err := m.open(m.DBPath, false)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		if v, err := schemaVersion(db); err == nil && v > 0 {
+			if err := migrate(db); err != nil {
+				return fmt.Errorf("database migration failed: %w", err)
+			}
+			if err := m.ensureScoreDistribution(db); err != nil {
+				return fmt.Errorf("failed to build coverage ranks: %w", err)
+			}
+			if err := m.ensureOutcodeSummary(db); err != nil {
+				return fmt.Errorf("failed to build outcode summary: %w", err)
+			}
+		}
 	}
 
 	return nil
 }
 
-func (m *Manager) downloadData(year, csvPath string) error {
-	url, ok := MobileDataURLs[year]
-	if !ok {
-		return fmt.Errorf("no URL for year %q, available: 2022, 2023", year)
+// progressReader reports bytes read from r, at most once a MiB.
+type progressReader struct {
+	r        io.Reader
+	n, total int64
+	reported int64
+	report   func(stage string, done, total int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.n += int64(n)
+	if p.n-p.reported >= 1<<20 || (err == io.EOF && p.n > p.reported) {
+		p.reported = p.n
+		p.report("download", p.n, p.total)
 	}
+	return n, err
+}
 
-	fmt.Printf("Downloading Ofcom mobile %s dataset...\n", year)
-	client := &http.Client{Timeout: 300 * time.Second}
-	resp, err := client.Get(url)
+func (m *Manager) downloadData(year, url, csvPath string, opts SetupOptions) error {
+	want, err := expectedChecksum(year, opts)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("HTTP %d from Ofcom", resp.StatusCode)
+	if want == "" && opts.RequireChecksum {
+		return fmt.Errorf("no known checksum for %s dataset: pass --sha256 or --manifest-url, or drop --require-checksum", year)
 	}
 
-	data, err := io.ReadAll(resp.Body)
+	m.Logger.Info("downloading Ofcom mobile dataset", "year", year, "url", url)
+	// The ZIP is saved beside the CSV and extracted from disk, not memory,
+	// so setup runs on small hosts; it is removed once extracted.
+	zipPath := strings.TrimSuffix(csvPath, ".csv") + ".zip.partial"
+	got, size, err := m.downloadFile(url, zipPath, opts)
 	if err != nil {
 		return err
 	}
+	defer os.Remove(zipPath)
 
-	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if want != "" && got != want {
+		return fmt.Errorf("checksum mismatch for %s dataset: expected %s, got %s (corrupt or partial download?)", year, want, got)
+	}
+	if want == "" {
+		m.Logger.Warn("no known checksum for dataset, recording without verification", "year", year, "sha256", got)
+	}
+
+	zr, err := zip.OpenReader(zipPath)
 	if err != nil {
 		return fmt.Errorf("failed to open ZIP: %w", err)
 	}
+	defer zr.Close()
 
 	var csvFile *zip.File
 	for _, f := range zr.File {
@@ -151,6 +364,9 @@ func (m *Manager) downloadData(year, csvPath string) error {
 	if csvFile == nil {
 		return fmt.Errorf("no CSV found inside Ofcom ZIP")
 	}
+	if err := m.checkSpace(m.DataDir, int64(csvFile.UncompressedSize64), "the extracted CSV"); err != nil {
+		return err
+	}
 
 	rc, err := csvFile.Open()
 	if err != nil {
@@ -158,146 +374,276 @@ func (m *Manager) downloadData(year, csvPath string) error {
 	}
 	defer rc.Close()
 
-	out, err := os.Create(csvPath)
+	// Extract to a temporary file so a failed copy never leaves a partial
+	// CSV behind that a later setup would mistake for a complete one.
+	tmp := csvPath + ".partial"
+	out, err := os.Create(tmp)
 	if err != nil {
 		return err
 	}
-	defer out.Close()
+	if _, err := io.Copy(out, rc); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to extract CSV: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, csvPath); err != nil {
+		return err
+	}
 
-	_, err = io.Copy(out, rc)
-	fmt.Println("Download complete.")
-	return err
+	err = m.writeManifest(Manifest{
+		Year:         year,
+		Revision:     RevisionOf(url),
+		SourceURL:    url,
+		SHA256:       got,
+		Size:         size,
+		Verified:     want != "",
+		DownloadedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	m.Logger.Info("download complete", "path", csvPath, "sha256", got)
+	return nil
 }
 
-func (m *Manager) buildDatabase(csvPath string) error {
-	fmt.Println("Building mobile database from Ofcom data (one-time setup)...")
+func (m *Manager) buildDatabase(csvPath, year string, opts SetupOptions) error {
+	m.Logger.Info("building mobile database from Ofcom data", "csv", csvPath, "db", m.DBPath)
 
-	if _, err := os.Stat(m.DBPath); err == nil {
-		os.Remove(m.DBPath)
+	// Build beside the live database and rename it into place, so readers
+	// see either the old data or the complete new data, never a partial
+	// build.
+	tmp := m.DBPath + ".building"
+	os.Remove(tmp)
+	if err := m.buildInto(tmp, csvPath, year, opts); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := m.archiveCurrent(year); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to keep previous dataset: %w", err)
 	}
+	return os.Rename(tmp, m.DBPath)
+}
 
-	db, err := sql.Open("sqlite3", m.DBPath)
+func (m *Manager) buildInto(path, csvPath, year string, opts SetupOptions) error {
+	db, err := m.open(path, false)
 	if err != nil {
 		return err
 	}
 	defer db.Close()
 
-	db.Exec("PRAGMA journal_mode=WAL")
-	db.Exec("PRAGMA synchronous=NORMAL")
+	if err := tuneForBuild(db); err != nil {
+		return err
+	}
+
+	if err := migrate(db); err != nil {
+		return err
+	}
 
 	f, err := os.Open(csvPath)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
-
-	reader := csv.NewReader(f)
-	headers, err := reader.Read()
+	edition := EditionFor(year).WithColumnMap(opts.ColumnMap)
+	count, skipped, err := m.insertRows(db, f, edition, opts, "")
 	if err != nil {
-		return fmt.Errorf("failed to read CSV headers: %w", err)
+		return err
 	}
-
-	for i, h := range headers {
-		headers[i] = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(h, " ", "_")))
+	for _, l := range opts.Layers {
+		rc, err := m.openLayer(l, year, opts)
+		if err != nil {
+			return fmt.Errorf("%s layer: %w", l.Nation, err)
+		}
+		n, _, err := m.insertRows(db, rc, edition, opts, l.Nation)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("%s layer: %w", l.Nation, err)
+		}
+		m.Logger.Info("layered nation edition", "nation", l.Nation, "rows", n)
 	}
 
-	cols := make([]string, len(headers))
-	for i, h := range headers {
-		cols[i] = fmt.Sprintf(`"%s" TEXT`, h)
-	}
-	createSQL := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS mobile (%s)`, strings.Join(cols, ", "))
-	if _, err := db.Exec(createSQL); err != nil {
+	if err := setMeta(db, "dataset_year", year); err != nil {
 		return err
 	}
-
-	tx, _ := db.Begin()
-	placeholders := strings.TrimRight(strings.Repeat("?,", len(headers)), ",")
-	insertSQL := fmt.Sprintf(`INSERT INTO mobile VALUES (%s)`, placeholders)
-	stmt, err := tx.Prepare(insertSQL)
-	if err != nil {
+	if err := setMeta(db, "built_at", time.Now().UTC().Format(time.RFC3339Nano)); err != nil {
 		return err
 	}
-
-	count := 0
-	for {
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
+	if mf, err := m.Manifest(); err == nil && mf != nil && mf.Year == year && mf.revision() > 0 {
+		if err := setMeta(db, "dataset_revision", strconv.Itoa(mf.revision())); err != nil {
+			return err
 		}
-		if err != nil {
-			continue
-		}
-		for i, h := range headers {
-			if h == "postcode" {
-				record[i] = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(record[i]), " ", ""))
-			}
+	}
+	columns := opts.Columns
+	if columns == "" {
+		columns = ColumnsFull
+	}
+	if err := setMeta(db, "columns", columns); err != nil {
+		return err
+	}
+	if len(opts.Nations) > 0 {
+		if err := setMeta(db, "nations", strings.Join(opts.Nations, ",")); err != nil {
+			return err
 		}
-		args := make([]interface{}, len(record))
-		for i, v := range record {
-			args[i] = v
+	}
+	if len(opts.Layers) > 0 {
+		var layers []string
+		for _, l := range opts.Layers {
+			layers = append(layers, l.Nation)
 		}
-		stmt.Exec(args...)
-		count++
-		if count%50000 == 0 {
-			tx.Commit()
-			tx, _ = db.Begin()
-			stmt, _ = tx.Prepare(insertSQL)
-			fmt.Printf("  Inserted %d rows...\n", count)
+		if err := setMeta(db, "layers", strings.Join(layers, ",")); err != nil {
+			return err
 		}
 	}
-	tx.Commit()
-	db.Exec(`CREATE INDEX IF NOT EXISTS idx_postcode ON mobile(postcode)`)
-	fmt.Printf("Mobile database built with %d rows.\n", count)
+	opts.progress("ranks", 0, 0)
+	if err := m.buildScoreDistribution(db); err != nil {
+		return fmt.Errorf("failed to build coverage ranks: %w", err)
+	}
+	if err := m.buildOutcodeSummary(db); err != nil {
+		return fmt.Errorf("failed to build outcode summary: %w", err)
+	}
+	// Leave WAL mode so the finished file is self-contained and safe to
+	// rename over a database other processes are reading.
+	if _, err := db.Exec("PRAGMA journal_mode=DELETE"); err != nil {
+		return err
+	}
+	if skipped > 0 {
+		m.Logger.Info("skipped postcodes outside the selected nations", "count", skipped, "nations", strings.Join(opts.Nations, ","))
+	}
+	m.Logger.Info("mobile database built", "rows", count, "columns", columns)
 	return nil
 }
 
-// QueryPostcode returns the raw row for a postcode, or nil if not found.
+// QueryPostcode returns the row for a postcode keyed by canonical column
+// name, or nil if not found. Coverage values are fractions in 0–1.
 func (m *Manager) QueryPostcode(postcode string) (map[string]string, error) {
-	if _, err := os.Stat(m.DBPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("database not found — run 'setup' first")
+	h, release, err := m.acquireHandle()
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
-	db, err := sql.Open("sqlite3", m.DBPath+"?mode=ro")
-	if err != nil {
+	pc := normalisePostcode(postcode)
+	if x := h.index.Load(); x != nil {
+		return x.row(pc), nil
+	}
+	if v, err := schemaVersion(h.db); err != nil {
 		return nil, err
+	} else if v == 0 {
+		return nil, ErrSchemaOutdated
 	}
-	defer db.Close()
 
-	pc := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(postcode), " ", ""))
-	rows, err := db.Query("SELECT * FROM mobile WHERE postcode = ? LIMIT 1", pc)
+	var result map[string]string
+	err = scanRows(h.db, "SELECT * FROM mobile WHERE postcode = ? LIMIT 1", []any{pc}, func(row map[string]string) {
+		result = row
+	})
+	return result, err
+}
+
+// normalisePostcode strips spaces and uppercases a postcode to match the
+// mobile table.
+func normalisePostcode(pc string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(pc), " ", ""))
+}
+
+// scanRows runs query and calls fn with each mobile row keyed by column.
+func scanRows(db *sql.DB, query string, args []any, fn func(map[string]string)) error {
+	rows, err := db.Query(query, args...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
 	cols, err := rows.Columns()
 	if err != nil {
-		return nil, err
-	}
-	if !rows.Next() {
-		return nil, nil
+		return err
 	}
-
 	vals := make([]interface{}, len(cols))
 	ptrs := make([]interface{}, len(cols))
 	for i := range vals {
 		ptrs[i] = &vals[i]
 	}
-	if err := rows.Scan(ptrs...); err != nil {
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+		result := make(map[string]string, len(cols))
+		for i, col := range cols {
+			switch v := vals[i].(type) {
+			case nil:
+			case float64:
+				result[col] = strconv.FormatFloat(v, 'f', -1, 64)
+			case []byte:
+				result[col] = string(v)
+			default:
+				result[col] = fmt.Sprintf("%v", v)
+			}
+		}
+		fn(result)
+	}
+	return rows.Err()
+}
+
+// Meta returns the dataset metadata recorded at build time
+// (dataset_year, built_at).
+func (m *Manager) Meta() (map[string]string, error) {
+	db, release, err := m.acquire()
+	if err != nil {
 		return nil, err
 	}
+	defer release()
 
-	result := make(map[string]string, len(cols))
-	for i, col := range cols {
-		if vals[i] != nil {
-			result[col] = fmt.Sprintf("%v", vals[i])
+	rows, err := db.Query("SELECT key, value FROM meta")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	meta := make(map[string]string)
+	for rows.Next() {
+		var k, v string
+		if err := rows.Scan(&k, &v); err != nil {
+			return nil, err
 		}
+		meta[k] = v
 	}
-	return result, nil
+	return meta, rows.Err()
+}
+
+// InterpretOptions controls how a raw row is summarised.
+type InterpretOptions struct {
+	Indoor bool // use indoor rather than outdoor coverage columns
+	// AddIndoor also reports indoor coverage in OperatorCoverage.Indoor,
+	// where the row has it, when Indoor is false.
+	AddIndoor bool
+	// Operators restricts the summary to these operator prefixes (see
+	// ParseOperators); empty means all four. The overall counts cover only
+	// the selected operators, and AnyOperator is reported only when all
+	// four are included.
+	Operators []string
+	// Weights sets the CoverageScore weighting; zero means
+	// DefaultScoreWeights.
+	Weights ScoreWeights
+	// Threshold is the fraction that counts as covered; zero means
+	// CoverageThreshold.
+	Threshold float64
+	// Brands labels operators with the MVNO brands asked for, keyed by
+	// prefix; see ParseOperatorList.
+	Brands map[string][]string
 }
 
-// Interpret converts a raw Ofcom mobile row into a MobileSummary.
+// Interpret converts a raw Ofcom mobile row into a MobileSummary using
+// outdoor coverage.
 func Interpret(row map[string]string) MobileSummary {
+	return InterpretWith(row, InterpretOptions{})
+}
+
+// InterpretWith converts a raw Ofcom mobile row into a MobileSummary.
+func InterpretWith(row map[string]string, opts InterpretOptions) MobileSummary {
 	get := func(keys ...string) string {
 		for _, k := range keys {
 			if v, ok := row[k]; ok && v != "" {
@@ -307,6 +653,10 @@ func Interpret(row map[string]string) MobileSummary {
 		return ""
 	}
 
+	threshold := opts.Threshold
+	if threshold <= 0 {
+		threshold = CoverageThreshold
+	}
 	covered := func(keys ...string) bool {
 		v := get(keys...)
 		if v == "" {
@@ -316,7 +666,23 @@ func Interpret(row map[string]string) MobileSummary {
 		if err != nil {
 			return false
 		}
-		return f >= 0.5 // treat ≥50% as covered
+		return f >= threshold
+	}
+
+	frac := func(keys ...string) float64 {
+		f, err := strconv.ParseFloat(get(keys...), 64)
+		if err != nil {
+			return 0
+		}
+		return math.Max(0, math.Min(1, f))
+	}
+
+	tier := func(keys ...string) Tier {
+		f, err := strconv.ParseFloat(get(keys...), 64)
+		if err != nil {
+			return TierNone
+		}
+		return TierOf(f, threshold)
 	}
 
 	pct := func(keys ...string) string {
@@ -331,44 +697,65 @@ func Interpret(row map[string]string) MobileSummary {
 		return fmt.Sprintf("%.0f%%", f*100)
 	}
 
-	operators := []OperatorCoverage{
-		{
-			Name:     "EE",
-			Voice:    pct("ee_voice", "ee_voice_indoor"),
-			FourG:    pct("ee_4g", "ee4g"),
-			FiveG:    pct("ee_5g", "ee5g"),
-			HasVoice: covered("ee_voice", "ee_voice_indoor"),
-			HasFourG: covered("ee_4g", "ee4g"),
-			HasFiveG: covered("ee_5g", "ee5g"),
-		},
-		{
-			Name:     "O2",
-			Voice:    pct("o2_voice", "o2_voice_indoor"),
-			FourG:    pct("o2_4g", "o24g"),
-			FiveG:    pct("o2_5g", "o25g"),
-			HasVoice: covered("o2_voice", "o2_voice_indoor"),
-			HasFourG: covered("o2_4g", "o24g"),
-			HasFiveG: covered("o2_5g", "o25g"),
-		},
-		{
-			Name:     "Three",
-			Voice:    pct("three_voice", "three_voice_indoor"),
-			FourG:    pct("three_4g", "three4g"),
-			FiveG:    pct("three_5g", "three5g"),
-			HasVoice: covered("three_voice", "three_voice_indoor"),
-			HasFourG: covered("three_4g", "three4g"),
-			HasFiveG: covered("three_5g", "three5g"),
-		},
-		{
-			Name:     "Vodafone",
-			Voice:    pct("vodafone_voice", "vodafone_voice_indoor"),
-			FourG:    pct("vodafone_4g", "vodafone4g"),
-			FiveG:    pct("vodafone_5g", "vodafone5g"),
-			HasVoice: covered("vodafone_voice", "vodafone_voice_indoor"),
-			HasFourG: covered("vodafone_4g", "vodafone4g"),
-			HasFiveG: covered("vodafone_5g", "vodafone5g"),
-		},
+	selected := Operators
+	if len(opts.Operators) > 0 {
+		selected = opts.Operators
 	}
+	operators := make([]OperatorCoverage, 0, len(selected))
+	fractions := make([][3]float64, 0, len(selected))
+	for _, op := range selected {
+		keys := outdoorKeys(op)
+		voice, fourG, fiveG := keys[0], keys[1], keys[2]
+		if opts.Indoor {
+			voice = []string{op + "_voice_indoor"}
+			fourG = []string{op + "_4g_indoor"}
+			fiveG = []string{op + "_5g_indoor"}
+		}
+		threeG, twoG := []string{op + "_3g"}, []string{op + "_2g"}
+		if opts.Indoor {
+			threeG, twoG = []string{op + "_3g_indoor"}, []string{op + "_2g_indoor"}
+		}
+		oc := OperatorCoverage{
+			Name:      operatorNames[op],
+			Brands:    opts.Brands[op],
+			Voice:     pct(voice...),
+			FourG:     pct(fourG...),
+			FiveG:     pct(fiveG...),
+			HasVoice:  covered(voice...),
+			HasFourG:  covered(fourG...),
+			HasFiveG:  covered(fiveG...),
+			Tier:      tier(fourG...),
+			DetailURL: DetailURL(op, get("postcode")),
+			Tiers: Tiers{
+				"voice": tier(voice...),
+				"4g":    tier(fourG...),
+				"5g":    tier(fiveG...),
+			},
+		}
+		if indoor := []string{op + "_4g_indoor"}; opts.AddIndoor && !opts.Indoor && get(indoor...) != "" {
+			iv, i5 := []string{op + "_voice_indoor"}, []string{op + "_5g_indoor"}
+			oc.Indoor = &IndoorCoverage{
+				Voice:    pct(iv...),
+				FourG:    pct(indoor...),
+				FiveG:    pct(i5...),
+				HasVoice: covered(iv...),
+				HasFourG: covered(indoor...),
+				HasFiveG: covered(i5...),
+				Tiers:    Tiers{"voice": tier(iv...), "4g": tier(indoor...), "5g": tier(i5...)},
+			}
+		}
+		if get(threeG...) != "" {
+			oc.ThreeG, oc.HasThreeG = pct(threeG...), covered(threeG...)
+			oc.Tiers["3g"] = tier(threeG...)
+		}
+		if get(twoG...) != "" {
+			oc.TwoG, oc.HasTwoG = pct(twoG...), covered(twoG...)
+			oc.Tiers["2g"] = tier(twoG...)
+		}
+		operators = append(operators, oc)
+		fractions = append(fractions, [3]float64{frac(voice...), frac(fourG...), frac(fiveG...)})
+	}
+	score := opts.Weights.Score(fractions)
 
 	fourGCount := 0
 	fiveGCount := 0
@@ -381,13 +768,22 @@ func Interpret(row map[string]string) MobileSummary {
 		}
 	}
 
+	anyOperator := "N/A"
+	if len(selected) == len(Operators) {
+		anyOperator = pct("any_operator", "any_coverage")
+	}
+
 	return MobileSummary{
 		Postcode:  get("postcode"),
+		Indoor:    opts.Indoor,
 		Operators: operators,
 		Overall: OverallCoverage{
-			AnyOperator: pct("any_operator", "any_coverage"),
+			AnyOperator: anyOperator,
 			FourGCount:  fourGCount,
 			FiveGCount:  fiveGCount,
 		},
+		CoverageScore: score,
+		Grade:         Grade(score),
+		Layer:         get("source"),
 	}
 }
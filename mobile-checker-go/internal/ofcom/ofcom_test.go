@@ -1,9 +1,34 @@
 package ofcom_test
 
 import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/parquet-go/parquet-go"
 	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/seal"
 )
 
 func TestInterpret_FullRow(t *testing.T) {
@@ -74,3 +99,2216 @@ func TestInterpret_PartialCoverage(t *testing.T) {
 		t.Error("O2 4G at 80% should be marked as covered")
 	}
 }
+
+func TestInterpret_CoverageScore(t *testing.T) {
+	full := map[string]string{}
+	for _, op := range ofcom.Operators {
+		full[op+"_voice"], full[op+"_4g"], full[op+"_5g"] = "1", "1", "1"
+	}
+	if got := ofcom.Interpret(full); got.CoverageScore != 100 || got.Grade != "A" {
+		t.Errorf("expected 100/A for full coverage, got %d/%s", got.CoverageScore, got.Grade)
+	}
+	if got := ofcom.Interpret(map[string]string{}); got.CoverageScore != 0 || got.Grade != "E" {
+		t.Errorf("expected 0/E for an empty row, got %d/%s", got.CoverageScore, got.Grade)
+	}
+
+	// Every operator has full voice and 4G but no 5G.
+	no5G := map[string]string{}
+	for _, op := range ofcom.Operators {
+		no5G[op+"_voice"], no5G[op+"_4g"] = "1", "1"
+	}
+	if got := ofcom.Interpret(no5G); got.CoverageScore != 80 || got.Grade != "A" {
+		t.Errorf("expected 80/A with default weights, got %d/%s", got.CoverageScore, got.Grade)
+	}
+	w, err := ofcom.ParseScoreWeights("voice=1,4g=1,5g=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ofcom.InterpretWith(no5G, ofcom.InterpretOptions{Weights: w}); got.CoverageScore != 50 || got.Grade != "C" {
+		t.Errorf("expected 50/C with 5G-heavy weights, got %d/%s", got.CoverageScore, got.Grade)
+	}
+	if _, err := ofcom.ParseScoreWeights("6g=1"); err == nil {
+		t.Error("expected an error for an unknown technology")
+	}
+}
+
+func TestInterpret_Tiers(t *testing.T) {
+	row := map[string]string{
+		"postcode":    "LS11AA",
+		"ee_4g":       "0.99",
+		"o2_4g":       "0.51",
+		"three_4g":    "0.2",
+		"vodafone_4g": "0.0",
+		"ee_5g":       "0.6",
+	}
+	result := ofcom.Interpret(row)
+	want := []ofcom.Tier{ofcom.TierExcellent, ofcom.TierGood, ofcom.TierPartial, ofcom.TierNone}
+	for i, op := range result.Operators {
+		if op.Tier != want[i] || op.Tiers["4g"] != want[i] {
+			t.Errorf("%s: expected 4G tier %s, got %s (%s)", op.Name, want[i], op.Tier, op.Tiers["4g"])
+		}
+	}
+	if ee := result.Operators[0]; ee.Tiers["5g"] != ofcom.TierGood || ee.Tiers["voice"] != ofcom.TierNone {
+		t.Errorf("unexpected EE tiers: %v", ee.Tiers)
+	}
+
+	// A stricter threshold moves 51% down to Partial.
+	o2 := ofcom.InterpretWith(row, ofcom.InterpretOptions{Threshold: 0.8}).Operators[1]
+	if o2.Tier != ofcom.TierPartial {
+		t.Errorf("expected Partial at a 0.8 threshold, got %s", o2.Tier)
+	}
+}
+
+func TestInterpret_OperatorSubset(t *testing.T) {
+	row := map[string]string{
+		"postcode":     "LS11AA",
+		"ee_4g":        "0.9",
+		"o2_4g":        "0.9",
+		"three_4g":     "0.2",
+		"any_coverage": "1.0",
+	}
+	ops, err := ofcom.ParseOperators("Three, ee")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := ofcom.InterpretWith(row, ofcom.InterpretOptions{Operators: ops})
+	if len(result.Operators) != 2 || result.Operators[0].Name != "Three" || result.Operators[1].Name != "EE" {
+		t.Fatalf("expected Three and EE, got %+v", result.Operators)
+	}
+	if result.Overall.FourGCount != 1 {
+		t.Errorf("expected 4G count 1 over the subset, got %d", result.Overall.FourGCount)
+	}
+	if result.Overall.AnyOperator != "N/A" {
+		t.Errorf("expected no any-operator figure for a subset, got %s", result.Overall.AnyOperator)
+	}
+	if _, err := ofcom.ParseOperators("ee,acme"); err == nil {
+		t.Error("expected an error for an unknown operator")
+	}
+}
+
+func TestInterpret_MVNOBrands(t *testing.T) {
+	row := map[string]string{"postcode": "LS11AA", "o2_4g": "0.9", "three_4g": "0.2"}
+	ops, brands, err := ofcom.ParseOperatorList("giffgaff, Tesco Mobile, smarty, o2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 2 || ops[0] != "o2" || ops[1] != "three" {
+		t.Fatalf("expected o2 and three, got %v", ops)
+	}
+	result := ofcom.InterpretWith(row, ofcom.InterpretOptions{Operators: ops, Brands: brands})
+	if got := result.Operators[0].Label(); got != "O2 (giffgaff, Tesco Mobile)" {
+		t.Errorf("unexpected O2 label %q", got)
+	}
+	if got := result.Operators[1].Label(); got != "Three (SMARTY)" {
+		t.Errorf("unexpected Three label %q", got)
+	}
+	if op, ok := ofcom.OperatorPrefix("VOXI"); !ok || op != "vodafone" {
+		t.Errorf("expected VOXI to resolve to vodafone, got %q", op)
+	}
+}
+
+func TestInterpret_AddIndoor(t *testing.T) {
+	row := map[string]string{"postcode": "LS11AA", "ee_4g": "0.9", "ee_4g_indoor": "0.4", "ee_voice_indoor": "0.8"}
+	out := ofcom.InterpretWith(row, ofcom.InterpretOptions{Operators: []string{"ee", "o2"}, AddIndoor: true})
+	in := out.Operators[0].Indoor
+	if in == nil || in.FourG != "40%" || in.HasFourG || !in.HasVoice || in.FiveG != "N/A" {
+		t.Fatalf("expected EE indoor coverage alongside outdoor, got %+v", in)
+	}
+	if out.Operators[0].FourG != "90%" {
+		t.Errorf("expected outdoor 4G to stay 90%%, got %s", out.Operators[0].FourG)
+	}
+	if out.Operators[1].Indoor != nil {
+		t.Errorf("expected no indoor coverage for O2 without indoor columns, got %+v", out.Operators[1].Indoor)
+	}
+}
+
+func TestInterpret_DetailURLs(t *testing.T) {
+	result := ofcom.Interpret(map[string]string{"postcode": "SW1A1AA", "ee_4g": "0.9"})
+	for _, op := range result.Operators {
+		if !strings.HasPrefix(op.DetailURL, "https://") || !strings.Contains(op.DetailURL, "SW1A+1AA") {
+			t.Errorf("%s: expected a checker URL for SW1A 1AA, got %q", op.Name, op.DetailURL)
+		}
+	}
+	if got := ofcom.DetailURL("ee", ""); got != "" {
+		t.Errorf("expected no URL without a postcode, got %q", got)
+	}
+}
+
+func TestSetup_KeepsLegacyTechnologies(t *testing.T) {
+	dir := t.TempDir()
+	csv := "Postcode,EE 4G,EE 3G,O2 4G\nLS1 1AA,0.9,0.8,0.9\nLS1 1AB,0.9,0.2,0.9\n"
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir)
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	row, err := m.QueryPostcode("LS11AA")
+	if err != nil || row == nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	result := ofcom.Interpret(row)
+	ee, o2 := result.Operators[0], result.Operators[1]
+	if !ee.HasThreeG || ee.ThreeG != "80%" {
+		t.Errorf("expected EE 3G at 80%%, got %q", ee.ThreeG)
+	}
+	if o2.HasLegacy() || o2.ThreeG != "" {
+		t.Errorf("expected no 3G figure for O2, got %q", o2.ThreeG)
+	}
+
+	row, _ = m.QueryPostcode("LS11AB")
+	if ee := ofcom.Interpret(row).Operators[0]; ee.HasThreeG || ee.ThreeG == "" {
+		t.Errorf("expected EE 3G below threshold, got %+v", ee)
+	}
+}
+
+func TestSetup_TrimsColumnsAndNations(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	fw, _ := zw.Create("mobile_pc.csv")
+	fw.Write([]byte("postcode,ee_4g,ee_4g_indoor\nEH1 1AA,0.9,0.8\nSW1A 1AA,1.0,0.7\nTD15 1AA,0.5,0.5\n"))
+	zw.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(buf.Bytes())
+	}))
+	defer srv.Close()
+
+	m := ofcom.NewManager(t.TempDir())
+	opts := ofcom.SetupOptions{URL: srv.URL, Columns: ofcom.ColumnsMinimal, Nations: []string{"scotland"}}
+	if err := m.Setup("test", opts); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	check := func() {
+		t.Helper()
+		row, err := m.QueryPostcode("EH11AA")
+		if err != nil || row == nil || row["ee_4g"] != "0.9" || row["ee_4g_indoor"] != "" {
+			t.Errorf("expected outdoor coverage only for EH11AA, got %v (err %v)", row, err)
+		}
+		for _, pc := range []string{"SW1A1AA", "TD151AA"} {
+			if row, _ := m.QueryPostcode(pc); row != nil {
+				t.Errorf("expected %s, in England, to be left out, got %v", pc, row)
+			}
+		}
+		st, err := m.Status()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if st.ColumnSet != ofcom.ColumnsMinimal || len(st.Columns) != 13 || strings.Join(st.Nations, ",") != "scotland" {
+			t.Errorf("unexpected status %+v", st)
+		}
+	}
+	check()
+
+	// A forced rebuild without trimming options keeps the trimming.
+	if err := m.Setup("test", ofcom.SetupOptions{URL: srv.URL, Force: true}); err != nil {
+		t.Fatalf("rebuild failed: %v", err)
+	}
+	check()
+
+	if err := m.Setup("test", ofcom.SetupOptions{URL: srv.URL, Force: true, Columns: ofcom.ColumnsFull}); err != nil {
+		t.Fatalf("full rebuild failed: %v", err)
+	}
+	if row, _ := m.QueryPostcode("SW1A1AA"); row == nil || row["ee_4g_indoor"] != "0.7" {
+		t.Errorf("expected the full dataset after --columns full, got %v", row)
+	}
+}
+
+func TestSetup_LayersNationEdition(t *testing.T) {
+	dir := t.TempDir()
+	csv := "postcode,ee_4g,o2_4g\nEH11AA,0.2,0.2\nLS11AA,0.3,0.3\n"
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// The Scottish edition's English row is ignored.
+	layer := filepath.Join(t.TempDir(), "scotland.csv")
+	if err := os.WriteFile(layer, []byte("postcode,ee_4g\nEH1 1AA,0.9\nLS11AA,0.9\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	l, err := ofcom.ParseLayer("Scotland=" + layer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir)
+	defer m.Close()
+	if err := m.Setup("2023", ofcom.SetupOptions{Layers: []ofcom.Layer{l}}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	for _, indexed := range []bool{false, true} {
+		if indexed {
+			if err := m.LoadIndex(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		row, err := m.QueryPostcode("EH11AA")
+		if err != nil || row["ee_4g"] != "0.9" || row["o2_4g"] != "" || row["source"] != "scotland" {
+			t.Errorf("indexed %v: expected the Scottish row, got %v (err %v)", indexed, row, err)
+		}
+		if s := ofcom.Interpret(row); s.Layer != "scotland" {
+			t.Errorf("indexed %v: expected layer scotland, got %q", indexed, s.Layer)
+		}
+		row, err = m.QueryPostcode("LS11AA")
+		if err != nil || row["ee_4g"] != "0.3" || row["source"] != "" {
+			t.Errorf("indexed %v: expected the UK-wide row, got %v (err %v)", indexed, row, err)
+		}
+	}
+	if st, err := m.Status(); err != nil || len(st.Layers) != 1 || st.Layers[0] != "scotland" {
+		t.Errorf("expected status to list the layer, got %+v (err %v)", st, err)
+	}
+
+	if _, err := ofcom.ParseLayer("cornwall=x.csv"); err == nil {
+		t.Error("expected an unknown nation to be rejected")
+	}
+	err = ofcom.NewManager(t.TempDir()).Setup("2023", ofcom.SetupOptions{Layers: []ofcom.Layer{{Nation: "wales"}}})
+	if err == nil || !strings.Contains(err.Error(), "no known 2023 wales edition") {
+		t.Errorf("expected an error for a layer without a source, got %v", err)
+	}
+}
+
+func TestNationOf(t *testing.T) {
+	for pc, want := range map[string]string{
+		"SW1A1AA": "england", "G11AA": "scotland", "EH11AA": "scotland", "TD151AA": "england",
+		"CF101AA": "wales", "CH71AA": "wales", "CH11AA": "england", "SY231AA": "wales",
+		"BT11AA": "northern-ireland", "JE11AA": "",
+	} {
+		if got := ofcom.NationOf(pc); got != want {
+			t.Errorf("NationOf(%s) = %q, want %q", pc, got, want)
+		}
+	}
+}
+
+func TestSetup_BuildsQueryableDatabase(t *testing.T) {
+	dir := t.TempDir()
+	csv := "Postcode,EE 4G,O2 4G\nsw1a 1aa,1.0,0.4\nEC1A 1BB,0.2,0.9\n"
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := ofcom.NewManager(dir)
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	row, err := m.QueryPostcode("SW1A 1AA")
+	if err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if row == nil {
+		t.Fatal("expected row for SW1A1AA, got nil")
+	}
+	if row["ee_4g"] != "1" {
+		t.Errorf("expected ee_4g 1, got %q", row["ee_4g"])
+	}
+	if row["o2_4g"] != "0.4" {
+		t.Errorf("expected o2_4g 0.4, got %q", row["o2_4g"])
+	}
+
+	row, err = m.QueryPostcode("ZZ99ZZ")
+	if err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if row != nil {
+		t.Errorf("expected nil row for unknown postcode, got %v", row)
+	}
+}
+
+func TestSetup_BuildsLargeFileInOrder(t *testing.T) {
+	dir := t.TempDir()
+	var sb strings.Builder
+	sb.WriteString("postcode,ee_voice,ee_4g,o2_4g,three_4g,vodafone_5g\n")
+	const n = 10000 // several pipeline batches
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, "C%d %c%c,%.2f,%.2f,%.2f,%.2f,%.2f\n", i/676, 'A'+i%26, 'A'+(i/26)%26,
+			float64(i%7)/6, float64(i%11)/10, float64(i%3)/2, float64(i%5)/4, float64(i%13)/12)
+	}
+	sb.WriteString("C0 AA,0.5,0.5,0.5,0.5,0.5\n") // a repeat: the last row wins
+	sb.WriteString("C0 AB,1,1\n")                 // wrong field count: skipped
+	sb.WriteString("C0 AC,n/a,1,1,1,1\n")         // not a number: stored as NULL
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(sb.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir)
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	if row, _ := m.QueryPostcode("C0AA"); row["ee_4g"] != "0.5" {
+		t.Errorf("expected the repeated postcode's last row, got %v", row)
+	}
+	if row, _ := m.QueryPostcode("C0AB"); row["ee_4g"] != "0.4" { // row 26
+		t.Errorf("expected the malformed record skipped, got %v", row)
+	}
+	if row, _ := m.QueryPostcode("C0AC"); row["ee_voice"] != "" || row["ee_4g"] != "1" {
+		t.Errorf("expected a NULL for the value that is not a number, got %v", row)
+	}
+
+	// Ranks are counted in SQL; they must agree with Interpret's scores.
+	var scores []int
+	for i := 0; i < n; i++ {
+		row, err := m.QueryPostcode(fmt.Sprintf("C%d%c%c", i/676, 'A'+i%26, 'A'+(i/26)%26))
+		if err != nil || row == nil {
+			t.Fatalf("expected row %d, got %v (err %v)", i, row, err)
+		}
+		scores = append(scores, ofcom.Interpret(row).CoverageScore)
+	}
+	for _, score := range []int{scores[0], scores[1], scores[42], scores[n-1]} {
+		lower := 0
+		for _, s := range scores {
+			if s < score {
+				lower++
+			}
+		}
+		r, err := m.RankOf(score, "", "")
+		if err != nil || r == nil {
+			t.Fatalf("expected a rank, got %v (err %v)", r, err)
+		}
+		if r.National.Postcodes != n {
+			t.Errorf("expected %d postcodes ranked, got %d", n, r.National.Postcodes)
+		}
+		if want := math.Round(float64(lower)/n*1000) / 10; math.Abs(r.National.BetterThan-want) > 0.1 {
+			t.Errorf("score %d: expected better than %.1f%%, got %v", score, want, r.National.BetterThan)
+		}
+	}
+}
+
+func TestReady_RequiresRows(t *testing.T) {
+	dir := t.TempDir()
+	m := ofcom.NewManager(dir)
+	if err := m.Ready(); !errors.Is(err, ofcom.ErrDatabaseNotFound) {
+		t.Errorf("expected ErrDatabaseNotFound before setup, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte("postcode,ee_4g\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	if err := m.Ready(); !errors.Is(err, ofcom.ErrDatasetEmpty) {
+		t.Errorf("expected ErrDatasetEmpty for a dataset without rows, got %v", err)
+	}
+}
+
+func TestMaintain_VacuumsAndAnalyzes(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte("postcode,ee_4g\nLS11AA,0.9\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir)
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	before, err := os.ReadFile(m.DBPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep, err := m.Maintain(ofcom.MaintainOptions{ReportOnly: true})
+	if err != nil {
+		t.Fatalf("report failed: %v", err)
+	}
+	if rep.Pages == 0 || rep.Vacuumed || rep.Checkpointed {
+		t.Errorf("expected a report without changes, got %+v", rep)
+	}
+	if after, err := os.ReadFile(m.DBPath); err != nil || !bytes.Equal(before, after) {
+		t.Errorf("expected the report to leave the database untouched (err %v)", err)
+	}
+	if _, err := ofcom.NewManager(t.TempDir()).Maintain(ofcom.MaintainOptions{ReportOnly: true}); !errors.Is(err, ofcom.ErrDatabaseNotFound) {
+		t.Errorf("expected ErrDatabaseNotFound, got %v", err)
+	}
+
+	rep, err = m.Maintain(ofcom.MaintainOptions{Reindex: true})
+	if err != nil {
+		t.Fatalf("maintain failed: %v", err)
+	}
+	if !rep.Reindexed || !rep.Vacuumed || !rep.Analyzed {
+		t.Errorf("expected every step to run, got %+v", rep)
+	}
+	// Setup leaves the database out of WAL mode, so there is nothing to
+	// checkpoint.
+	if rep.Checkpointed {
+		t.Errorf("expected no checkpoint outside WAL mode, got %+v", rep)
+	}
+	if row, err := m.QueryPostcode("LS11AA"); err != nil || row == nil {
+		t.Errorf("expected data intact after maintenance, got %v (err %v)", row, err)
+	}
+}
+
+func TestSetup_InstallsSecondYearForDiff(t *testing.T) {
+	dir := t.TempDir()
+	// A second year is set up in years/<year> alongside the current one.
+	files := map[string]string{
+		"ofcom_mobile_2023.csv":            "postcode,ee_4g,ee_5g\nLS11AA,0.4,0.75\n",
+		"years/2022/ofcom_mobile_2022.csv": "postcode,ee_4g,ee_5g\nLS11AA,0.9,0.1\n",
+	}
+	for name, csv := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(csv), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := ofcom.NewManager(dir)
+	for _, year := range []string{"2023", "2022"} {
+		if err := m.Setup(year, ofcom.SetupOptions{}); err != nil {
+			t.Fatalf("setup %s failed: %v", year, err)
+		}
+	}
+
+	if got := m.InstalledYears(); len(got) != 2 || got[0] != "2022" || got[1] != "2023" {
+		t.Fatalf("expected [2022 2023] installed, got %v", got)
+	}
+	old, err := m.ForYear("2022")
+	if err != nil {
+		t.Fatalf("ForYear failed: %v", err)
+	}
+	from, _ := old.QueryPostcode("LS11AA")
+	to, _ := m.QueryPostcode("LS11AA")
+	if from == nil || to == nil {
+		t.Fatalf("expected rows in both years, got %v and %v", from, to)
+	}
+
+	diffs := ofcom.DiffRows(from, to, ofcom.InterpretOptions{Operators: []string{"ee"}})
+	fourG, fiveG := diffs[0].Technologies[1], diffs[0].Technologies[2]
+	if fourG.ChangePP != -50 || !fourG.Lost || fourG.Gained {
+		t.Errorf("expected EE 4G lost by 50pp, got %+v", fourG)
+	}
+	if fiveG.ChangePP != 65 || !fiveG.Gained {
+		t.Errorf("expected EE 5G gained by 65pp, got %+v", fiveG)
+	}
+
+	if _, err := m.ForYear("2019"); !errors.Is(err, ofcom.ErrYearNotInstalled) {
+		t.Errorf("expected ErrYearNotInstalled, got %v", err)
+	}
+}
+
+func TestQueryPostcodeYear_ReadsEachYear(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"ofcom_mobile_2023.csv":            "postcode,ee_4g\nLS11AA,0.4\n",
+		"years/2022/ofcom_mobile_2022.csv": "postcode,ee_4g\nLS11AA,0.9\n",
+	}
+	for name, csv := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(csv), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := ofcom.NewManager(dir)
+	defer m.Close()
+	for _, year := range []string{"2023", "2022"} {
+		if err := m.Setup(year, ofcom.SetupOptions{}); err != nil {
+			t.Fatalf("setup %s failed: %v", year, err)
+		}
+	}
+
+	for year, want := range map[string]string{"": "0.4", "2023": "0.4", "2022": "0.9"} {
+		row, err := m.QueryPostcodeYear("LS1 1AA", year)
+		if err != nil || row == nil || row["ee_4g"] != want {
+			t.Errorf("year %q: expected ee_4g %s, got %v (err %v)", year, want, row, err)
+		}
+	}
+	a, _ := m.ForYear("2022")
+	b, _ := m.ForYear("2022")
+	if a != b {
+		t.Error("expected ForYear to reuse the manager for a year")
+	}
+	for _, year := range []string{"2019", "../2022", "years"} {
+		if _, err := m.QueryPostcodeYear("LS11AA", year); !errors.Is(err, ofcom.ErrYearNotInstalled) {
+			t.Errorf("year %q: expected ErrYearNotInstalled, got %v", year, err)
+		}
+	}
+}
+
+func TestEdition_MapHeaders(t *testing.T) {
+	headers := []string{"pcds", "ee_voice_outdoor", "tf_4g_outdoor", "h3_5g", "vf_4g_indoor", "premises"}
+	mapping, unknown, err := ofcom.EditionFor("2023").MapHeaders(headers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"postcode", "ee_voice", "o2_4g", "three_5g", "vodafone_4g_indoor", ""}
+	for i := range want {
+		if mapping[i] != want[i] {
+			t.Errorf("header %q: expected %q, got %q", headers[i], want[i], mapping[i])
+		}
+	}
+	if len(unknown) != 1 || unknown[0] != "premises" {
+		t.Errorf("expected [premises] unrecognised, got %v", unknown)
+	}
+
+	if _, _, err := ofcom.EditionFor("2023").MapHeaders([]string{"ee_4g"}); !errors.Is(err, ofcom.ErrUnknownLayout) {
+		t.Errorf("expected ErrUnknownLayout when postcode column is missing, got %v", err)
+	}
+	if _, _, err := ofcom.EditionFor("2023").MapHeaders([]string{"postcode", "premises"}); !errors.Is(err, ofcom.ErrUnknownLayout) {
+		t.Errorf("expected ErrUnknownLayout without coverage columns, got %v", err)
+	}
+}
+
+func TestSetup_UnknownLayoutAndColumnMap(t *testing.T) {
+	dir := t.TempDir()
+	csv := "Postcode,EE Outdoor Data Pct,O2 Outdoor Data Pct,Notes\nLS11AA,50,100,x\n"
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2031.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir)
+	err := m.Setup("2031", ofcom.SetupOptions{})
+	var le *ofcom.LayoutError
+	if !errors.As(err, &le) || !errors.Is(err, ofcom.ErrUnknownLayout) {
+		t.Fatalf("expected a LayoutError, got %v", err)
+	}
+	if want := []string{"ee_outdoor_data_pct", "o2_outdoor_data_pct", "notes"}; !slices.Equal(le.Unrecognised, want) {
+		t.Errorf("expected unrecognised headers %v, got %v", want, le.Unrecognised)
+	}
+	if _, err := os.Stat(m.DBPath); !os.IsNotExist(err) {
+		t.Error("expected no database built from an unrecognised layout")
+	}
+
+	mapPath := filepath.Join(t.TempDir(), "columns.yaml")
+	yml := "percent: true\ncolumns:\n  ee_4g: EE Outdoor Data Pct\n  o2_4g: [TF Outdoor Data Pct, O2 Outdoor Data Pct]\n"
+	if err := os.WriteFile(mapPath, []byte(yml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cm, err := ofcom.LoadColumnMap(mapPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Setup("2031", ofcom.SetupOptions{ColumnMap: cm}); err != nil {
+		t.Fatalf("setup with column map failed: %v", err)
+	}
+	if row, _ := m.QueryPostcode("LS11AA"); row["ee_4g"] != "0.5" || row["o2_4g"] != "1" {
+		t.Errorf("expected mapped percentages as fractions, got %v", row)
+	}
+
+	if err := os.WriteFile(mapPath, []byte("columns:\n  ee_6g: EE 6G\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ofcom.LoadColumnMap(mapPath); err == nil || !strings.Contains(err.Error(), "ee_6g") {
+		t.Errorf("expected an unknown column to be refused, got %v", err)
+	}
+}
+
+func TestAggregate_District(t *testing.T) {
+	dir := t.TempDir()
+	csv := "postcode,ee_4g,o2_4g,three_4g,vodafone_4g,ee_5g\nLS11AA,1.0,1.0,1.0,1.0,0.8\nLS11AB,0.2,1.0,1.0,1.0,0.0\nYO17HH,1.0,1.0,1.0,1.0,1.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir)
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	err := m.StoreGeo([]ofcom.Place{
+		{Postcode: "LS11AA", Region: "Yorkshire and The Humber", AdminDistrict: "Leeds"},
+		{Postcode: "LS11AB", Region: "Yorkshire and The Humber", AdminDistrict: "Leeds"},
+		{Postcode: "YO17HH", Region: "Yorkshire and The Humber", AdminDistrict: "York"},
+	})
+	if err != nil {
+		t.Fatalf("store geo failed: %v", err)
+	}
+
+	s, err := m.Aggregate("district", "leeds")
+	if err != nil {
+		t.Fatalf("aggregate failed: %v", err)
+	}
+	if s == nil || s.Postcodes != 2 {
+		t.Fatalf("expected 2 postcodes in Leeds, got %+v", s)
+	}
+	if ee := s.Operators[0]; ee.MeanFourG != 60 || ee.PctFourG != 50 {
+		t.Errorf("expected EE mean 4G 60%% covered 50%%, got %v / %v", ee.MeanFourG, ee.PctFourG)
+	}
+	if s.AllFourG != 50 {
+		t.Errorf("expected 50%% with all-operator 4G, got %v", s.AllFourG)
+	}
+
+	if s, err := m.Aggregate("region", "Nowhere"); err != nil || s != nil {
+		t.Errorf("expected nil summary for unknown region, got %+v (err %v)", s, err)
+	}
+
+	out := filepath.Join(t.TempDir(), "leeds.db")
+	if n, err := m.Export(out, ofcom.ExportFilter{District: "leeds", Outcode: "ls1"}); err != nil || n != 2 {
+		t.Fatalf("expected 2 postcodes exported, got %d (err %v)", n, err)
+	}
+	sub := ofcom.Manager{DataDir: filepath.Dir(out), DBPath: out, Driver: ofcom.DefaultDriver, Logger: m.Logger}
+	if row, err := sub.QueryPostcode("YO17HH"); err != nil || row != nil {
+		t.Errorf("expected YO17HH to be excluded, got %v (err %v)", row, err)
+	}
+	if s, err := sub.Aggregate("district", "Leeds"); err != nil || s == nil || s.Postcodes != 2 {
+		t.Errorf("expected exported geo data for Leeds, got %+v (err %v)", s, err)
+	}
+
+	stats, err := m.Stats("district")
+	if err != nil {
+		t.Fatalf("stats failed: %v", err)
+	}
+	if len(stats) != 2 || stats[0].Name != "Leeds" || stats[0].Postcodes != 2 || stats[1].Name != "York" {
+		t.Fatalf("expected Leeds (2) and York, got %+v", stats)
+	}
+}
+
+func TestOutcodeSummary_Precomputed(t *testing.T) {
+	dir := t.TempDir()
+	csv := "postcode,ee_4g,o2_4g,three_4g,vodafone_4g,ee_5g\nLS11AA,1.0,1.0,1.0,1.0,0.8\nLS11AB,0.2,1.0,1.0,1.0,0.0\nLS27AA,1.0,1.0,1.0,1.0,1.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir)
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	s, err := m.OutcodeSummary("ls1")
+	if err != nil {
+		t.Fatalf("outcode summary failed: %v", err)
+	}
+	if s == nil || s.Level != "outcode" || s.Name != "LS1" || s.Postcodes != 2 {
+		t.Fatalf("expected 2 postcodes in LS1, got %+v", s)
+	}
+	if ee := s.Operators[0]; ee.MeanFourG != 60 || ee.PctFourG != 50 {
+		t.Errorf("expected EE mean 4G 60%% covered 50%%, got %v / %v", ee.MeanFourG, ee.PctFourG)
+	}
+	if s.AllFourG != 50 || s.AnyFiveG != 50 {
+		t.Errorf("expected 50%% all-operator 4G and any 5G, got %v / %v", s.AllFourG, s.AnyFiveG)
+	}
+	if s, err := m.OutcodeSummary("M1"); err != nil || s != nil {
+		t.Errorf("expected nil summary for an outcode without postcodes, got %+v (err %v)", s, err)
+	}
+
+	// The summary is read, not aggregated: rows changed behind setup's back
+	// are not seen until it is rebuilt.
+	db, err := sql.Open(ofcom.DefaultDriver.Name, m.DBPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`DELETE FROM mobile WHERE postcode = 'LS11AB'`); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := m.OutcodeSummary("LS1"); s == nil || s.Postcodes != 2 {
+		t.Errorf("expected the precomputed 2 postcodes, got %+v", s)
+	}
+	// Without a summary, e.g. in a database from before it existed, the
+	// outcode is aggregated on the fly.
+	if _, err := db.Exec(`DELETE FROM outcode_summary`); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := m.OutcodeSummary("LS1"); s == nil || s.Postcodes != 1 {
+		t.Errorf("expected 1 postcode aggregated on the fly, got %+v", s)
+	}
+}
+
+func TestExportParquet_JoinsGeo(t *testing.T) {
+	dir := t.TempDir()
+	csv := "postcode,ee_4g,o2_4g\nLS11AA,1.0,0.5\nLS11AB,0.2,\nYO17HH,1.0,1.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir)
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	if err := m.StoreGeo([]ofcom.Place{{Postcode: "LS11AA", AdminDistrict: "Leeds", Eastings: 430000, Northings: 433000}}); err != nil {
+		t.Fatalf("store geo failed: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "all.parquet")
+	if n, err := m.ExportParquet(out, ofcom.ExportFilter{}, true); err != nil || n != 3 {
+		t.Fatalf("expected 3 rows exported, got %d (err %v)", n, err)
+	}
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	info, _ := f.Stat()
+	pf, err := parquet.OpenFile(f, info.Size())
+	if err != nil {
+		t.Fatalf("open parquet failed: %v", err)
+	}
+	if year, _ := pf.Lookup("dataset_year"); year != "2023" {
+		t.Errorf("expected dataset_year metadata 2023, got %q", year)
+	}
+	col := func(name string) int {
+		leaf, ok := pf.Schema().Lookup(name)
+		if !ok {
+			t.Fatalf("no %s column", name)
+		}
+		return leaf.ColumnIndex
+	}
+	rows := make([]parquet.Row, 3)
+	r := parquet.NewReader(pf)
+	if n, _ := r.ReadRows(rows); n != 3 {
+		t.Fatalf("expected 3 rows, read %d", n)
+	}
+	first, second := rows[0], rows[1]
+	if pc := first[col("postcode")].String(); pc != "LS11AA" {
+		t.Errorf("expected rows ordered by postcode, got %s first", pc)
+	}
+	if d := first[col("admin_district")].String(); d != "Leeds" || first[col("eastings")].Int64() != 430000 {
+		t.Errorf("expected LS11AA geo columns, got district %q eastings %d", d, first[col("eastings")].Int64())
+	}
+	if first[col("o2_4g")].Double() != 0.5 || !second[col("o2_4g")].IsNull() || !second[col("admin_district")].IsNull() {
+		t.Errorf("expected nulls for missing coverage and geo, got %v / %v", second[col("o2_4g")], second[col("admin_district")])
+	}
+
+	leeds := filepath.Join(t.TempDir(), "leeds.parquet")
+	if n, err := m.ExportParquet(leeds, ofcom.ExportFilter{District: "leeds"}, false); err != nil || n != 1 {
+		t.Errorf("expected 1 row for Leeds without geo columns, got %d (err %v)", n, err)
+	}
+}
+
+func TestNearest_OrdersByDistance(t *testing.T) {
+	dir := t.TempDir()
+	csv := "postcode,three_5g\nAA11AA,0.0\nAA11AB,1.0\nAA11AC,0.9\nAA11AD,0.1\nAA11AE,1.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir)
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	err := m.StoreGeo([]ofcom.Place{
+		{Postcode: "AA11AA", Eastings: 300000, Northings: 300000},
+		{Postcode: "AA11AB", Eastings: 303000, Northings: 300000},
+		{Postcode: "AA11AC", Eastings: 300000, Northings: 301500},
+		{Postcode: "AA11AD", Eastings: 300100, Northings: 300000},
+		{Postcode: "AA11AE", Eastings: 330000, Northings: 300000},
+	})
+	if err != nil {
+		t.Fatalf("store geo failed: %v", err)
+	}
+
+	found, err := m.Nearest(300000, 300000, ofcom.NearestOptions{Operator: "three", Tech: "5G", Limit: 2, MaxKm: 10})
+	if err != nil {
+		t.Fatalf("nearest failed: %v", err)
+	}
+	if len(found) != 2 || found[0].Postcode != "AA11AC" || found[1].Postcode != "AA11AB" {
+		t.Fatalf("expected AA11AC then AA11AB, got %+v", found)
+	}
+	if found[0].DistanceKm != 1.5 || found[0].Coverage != "90%" {
+		t.Errorf("unexpected first match %+v", found[0])
+	}
+
+	if _, err := m.Nearest(0, 0, ofcom.NearestOptions{Operator: "Orange", Tech: "4g"}); err == nil {
+		t.Error("expected error for unknown operator")
+	}
+}
+
+func TestWithin_ListsPostcodesInRadius(t *testing.T) {
+	dir := t.TempDir()
+	csv := "postcode,vodafone_5g\nAA11AA,0.0\nAA11AB,1.0\nAA11AC,0.9\nAA11AD,\nAA11AE,1.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir)
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	err := m.StoreGeo([]ofcom.Place{
+		{Postcode: "AA11AA", Eastings: 300000, Northings: 300000},
+		{Postcode: "AA11AB", Eastings: 301400, Northings: 301400}, // 1.98 km, inside the box and circle
+		{Postcode: "AA11AC", Eastings: 300000, Northings: 301500},
+		{Postcode: "AA11AD", Eastings: 300100, Northings: 300000}, // no 5G figure
+		{Postcode: "AA11AE", Eastings: 301800, Northings: 301800}, // in the box, outside the circle
+	})
+	if err != nil {
+		t.Fatalf("store geo failed: %v", err)
+	}
+
+	res, err := m.Within(300000, 300000, ofcom.WithinOptions{Operator: "vodafone", Tech: "5g", RadiusKm: 2})
+	if err != nil {
+		t.Fatalf("within failed: %v", err)
+	}
+	var got []string
+	for _, p := range res.Postcodes {
+		got = append(got, p.Postcode)
+	}
+	if strings.Join(got, ",") != "AA11AA,AA11AC,AA11AB" {
+		t.Fatalf("expected AA11AA, AA11AC, AA11AB, got %v", got)
+	}
+	if p := res.Postcodes[1]; p.DistanceKm != 1.5 || p.CoveragePct != 90 || !p.Covered {
+		t.Errorf("unexpected AA11AC %+v", p)
+	}
+	if res.Column != "vodafone_5g" || res.Covered != 2 || res.Uncovered != 1 || res.Truncated {
+		t.Errorf("unexpected result %+v", res)
+	}
+
+	res, err = m.Within(300000, 300000, ofcom.WithinOptions{Operator: "vodafone", Tech: "5g", RadiusKm: 2, Limit: 1})
+	if err != nil || len(res.Postcodes) != 1 || !res.Truncated {
+		t.Errorf("expected one postcode and truncation, got %+v (err %v)", res, err)
+	}
+	if _, err := m.Within(300000, 300000, ofcom.WithinOptions{Operator: "vodafone", Tech: "5g", RadiusKm: 50}); err == nil {
+		t.Error("expected an error for a radius over the maximum")
+	}
+}
+
+func TestToGrid(t *testing.T) {
+	// SW1A 1AA, whose ONSPD grid reference is 529090, 179645.
+	e, n, err := ofcom.ToGrid(51.501009, -0.141588)
+	if err != nil || e < 529085 || e > 529095 || n < 179640 || n > 179650 {
+		t.Errorf("expected about 529090, 179645, got %d, %d (err %v)", e, n, err)
+	}
+	if _, _, err := ofcom.ToGrid(48.8566, 2.3522); err == nil {
+		t.Error("expected Paris to be refused")
+	}
+	if lat, lon := ofcom.FromGrid(529090, 179645); math.Abs(lat-51.501009) > 0.0001 || math.Abs(lon+0.141588) > 0.0001 {
+		t.Errorf("expected FromGrid to return about 51.501009, -0.141588, got %f, %f", lat, lon)
+	}
+}
+
+func TestExportGeoJSON_PointsAndGrid(t *testing.T) {
+	dir := t.TempDir()
+	csv := "postcode,ee_4g,o2_4g\nLS11AA,1.0,0.5\nLS11AB,0.5,\nYO17HH,1.0,1.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir)
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	err := m.StoreGeo([]ofcom.Place{
+		{Postcode: "LS11AA", Latitude: 53.7960, Longitude: -1.5480, Eastings: 430100, Northings: 433100},
+		{Postcode: "LS11AB", Latitude: 53.7965, Longitude: -1.5475, Eastings: 430900, Northings: 433900},
+		{Postcode: "YO17HH", Latitude: 53.9590, Longitude: -1.0815, Eastings: 460300, Northings: 452200},
+	})
+	if err != nil {
+		t.Fatalf("store geo failed: %v", err)
+	}
+	read := func(path string) (fc struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Geometry struct {
+				Type        string          `json:"type"`
+				Coordinates json.RawMessage `json:"coordinates"`
+			} `json:"geometry"`
+			Properties map[string]any `json:"properties"`
+		} `json:"features"`
+	}) {
+		t.Helper()
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(b, &fc); err != nil {
+			t.Fatalf("invalid GeoJSON: %v\n%s", err, b)
+		}
+		return fc
+	}
+
+	out := filepath.Join(t.TempDir(), "leeds.geojson")
+	box := ofcom.BBox{MinLon: -1.6, MinLat: 53.7, MaxLon: -1.4, MaxLat: 53.9}
+	if n, err := m.ExportGeoJSON(out, ofcom.ExportFilter{}, ofcom.GeoJSONOptions{BBox: &box}); err != nil || n != 2 {
+		t.Fatalf("expected the 2 Leeds postcodes in the bbox, got %d (err %v)", n, err)
+	}
+	fc := read(out)
+	if fc.Type != "FeatureCollection" || len(fc.Features) != 2 {
+		t.Fatalf("unexpected collection %+v", fc)
+	}
+	first := fc.Features[0]
+	if first.Geometry.Type != "Point" || string(first.Geometry.Coordinates) != "[-1.548,53.796]" {
+		t.Errorf("expected a lon,lat point, got %s %s", first.Geometry.Type, first.Geometry.Coordinates)
+	}
+	if p := first.Properties; p["postcode"] != "LS11AA" || p["ee_4g"] != 1.0 || p["o2_4g"] != 0.5 {
+		t.Errorf("unexpected properties %v", p)
+	}
+	if _, ok := fc.Features[1].Properties["o2_4g"]; ok {
+		t.Error("expected a missing value to be left out, not written as 0")
+	}
+
+	out = filepath.Join(t.TempDir(), "grid.geojson")
+	if n, err := m.ExportGeoJSON(out, ofcom.ExportFilter{}, ofcom.GeoJSONOptions{GridSize: 1000}); err != nil || n != 2 {
+		t.Fatalf("expected 2 grid squares, got %d (err %v)", n, err)
+	}
+	fc = read(out)
+	sq := fc.Features[0]
+	if sq.Geometry.Type != "Polygon" || sq.Properties["eastings"] != 430000.0 || sq.Properties["postcodes"] != 2.0 || sq.Properties["ee_4g"] != 0.75 {
+		t.Errorf("expected the Leeds square averaging two postcodes, got %s %v", sq.Geometry.Type, sq.Properties)
+	}
+
+	if _, err := m.ExportGeoJSON(out, ofcom.ExportFilter{}, ofcom.GeoJSONOptions{}); err == nil {
+		t.Error("expected an existing file to be refused")
+	}
+}
+
+func TestEstimate_WeightsByDistance(t *testing.T) {
+	dir := t.TempDir()
+	csv := "postcode,ee_4g\nAA11AA,1.0\nAA11AB,0.0\nAA11AC,0.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir)
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	err := m.StoreGeo([]ofcom.Place{
+		{Postcode: "AA11AA", Eastings: 300100, Northings: 300000},
+		{Postcode: "AA11AB", Eastings: 300300, Northings: 300000},
+		{Postcode: "AA11AC", Eastings: 309000, Northings: 300000}, // beyond EstimateMaxKm
+	})
+	if err != nil {
+		t.Fatalf("store geo failed: %v", err)
+	}
+
+	row, used, err := m.Estimate("aa1 1zz", 300000, 300000)
+	if err != nil {
+		t.Fatalf("estimate failed: %v", err)
+	}
+	if len(used) != 2 || used[0].Postcode != "AA11AA" || used[1].Postcode != "AA11AB" {
+		t.Fatalf("expected AA11AA then AA11AB, got %+v", used)
+	}
+	// Weights 1/100² and 1/300²: (1×9 + 0×1) / 10.
+	if row["postcode"] != "AA11ZZ" || row["ee_4g"] != "0.9000" {
+		t.Errorf("unexpected estimate %v", row)
+	}
+
+	if row, _, err := m.Estimate("ZZ11ZZ", 100000, 100000); err != nil || row != nil {
+		t.Errorf("expected no estimate far from any postcode, got %v (err %v)", row, err)
+	}
+}
+
+func TestHeatmap_BinsCentroids(t *testing.T) {
+	dir := t.TempDir()
+	csv := "postcode,ee_4g\nAA11AA,1.0\nAA11AB,0.5\nAA11AC,0.0\nAA11AD,1.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir)
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	err := m.StoreGeo([]ofcom.Place{
+		{Postcode: "AA11AA", Latitude: 51.1, Longitude: -1.9},
+		{Postcode: "AA11AB", Latitude: 51.2, Longitude: -1.8},
+		{Postcode: "AA11AC", Latitude: 51.9, Longitude: -1.1},
+		{Postcode: "AA11AD", Latitude: 55.0, Longitude: -3.0}, // outside the box
+	})
+	if err != nil {
+		t.Fatalf("store geo failed: %v", err)
+	}
+
+	g, err := m.Heatmap(ofcom.BBox{MinLon: -2, MinLat: 51, MaxLon: -1, MaxLat: 52}, "ee_4g", 2, 2)
+	if err != nil {
+		t.Fatalf("heatmap failed: %v", err)
+	}
+	if c := g.Cell(0, 0); c.Postcodes != 2 || c.Mean != 0.75 {
+		t.Errorf("expected south-west cell mean 0.75 over 2 postcodes, got %+v", c)
+	}
+	if c := g.Cell(1, 1); c.Postcodes != 1 || c.Mean != 0 {
+		t.Errorf("expected north-east cell mean 0 over 1 postcode, got %+v", c)
+	}
+	if c := g.Cell(1, 0); c.Postcodes != 0 {
+		t.Errorf("expected empty south-east cell, got %+v", c)
+	}
+}
+
+func TestImportONSPD_StoresNamedPlaces(t *testing.T) {
+	dir := t.TempDir()
+	csv := "postcode,ee_4g\nLS11AA,1.0\nLS11AB,1.0\nZE29XX,1.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir)
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	fw, _ := zw.Create("Data/ONSPD_MAY_2024_UK.csv")
+	fw.Write([]byte("pcd,pcds,doterm,oseast1m,osnrth1m,oslaua,ctry25cd,rgn25cd,pcon24cd,lat,long\n" +
+		"LS1  1AA,LS1 1AA,,429900,433500,E08000035,E92000001,E12000003,E14001331,53.796,-1.547\n" +
+		"LS1  1AB,LS1 1AB,202001,429900,433500,E08000035,E92000001,E12000003,E14001331,53.796,-1.547\n" +
+		"ZE2  9XX,ZE2 9XX,,,,S12000027,S92000003,S99999999,S14000051,99.999999,0.000000\n"))
+	fw, _ = zw.Create("Documents/LA_UA names and codes UK as at 04_24.csv")
+	fw.Write([]byte("\ufeffLAD24CD,LAD24NM,LAD24NMW\nE08000035,Leeds,\n"))
+	zw.Close()
+	path := filepath.Join(t.TempDir(), "onspd.zip")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := m.ImportONSPD(path)
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 postcode imported, got %d (err %v)", n, err)
+	}
+	p, err := m.Place("LS1 1AA")
+	if err != nil || p == nil {
+		t.Fatalf("expected a place for LS11AA, got %v (err %v)", p, err)
+	}
+	if p.Country != "England" || p.Region != "Yorkshire and The Humber" || p.AdminDistrict != "Leeds" || p.Constituency != "E14001331" || p.Eastings != 429900 {
+		t.Errorf("unexpected place %+v", p)
+	}
+	for _, pc := range []string{"LS11AB", "ZE29XX"} {
+		if p, err := m.Place(pc); err != nil || p != nil {
+			t.Errorf("expected no place for terminated or unlocated %s, got %+v (err %v)", pc, p, err)
+		}
+	}
+}
+
+func TestImportPremises_AddsCountsToSummary(t *testing.T) {
+	dir := t.TempDir()
+	csv := "postcode,ee_4g,ee_4g_indoor,o2_4g\nLS11AA,1.0,0.5,0.8\nLS11AB,1.0,1.0,1.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir)
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	premises := "\ufeffPostcode,All Premises,EE 4G Prem Out,EE 4G Prem In,EE 4G Prem Out %,O2 4G Prem Out\n" +
+		"LS1 1AA,8,8,3,100,6\n" +
+		"ZE2 9XX,4,4,4,100,4\n"
+	path := filepath.Join(t.TempDir(), "premises.csv")
+	if err := os.WriteFile(path, []byte(premises), 0644); err != nil {
+		t.Fatal(err)
+	}
+	n, err := m.ImportPremises(path)
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 postcode imported, got %d (err %v)", n, err)
+	}
+
+	p, err := m.QueryPremises("LS1 1AA")
+	if err != nil || p == nil {
+		t.Fatalf("expected premises for LS11AA, got %v (err %v)", p, err)
+	}
+	if p.Total != 8 || p.Covered["ee_4g"] != 8 || p.Covered["ee_4g_indoor"] != 3 || p.Covered["o2_4g"] != 6 {
+		t.Errorf("unexpected premises %+v", p)
+	}
+	if _, ok := p.Covered["ee_voice"]; ok {
+		t.Error("expected unpublished columns to be absent")
+	}
+
+	row, _ := m.QueryPostcode("LS11AA")
+	summary := ofcom.InterpretWith(row, ofcom.InterpretOptions{Indoor: true})
+	summary.AddPremises(p)
+	if summary.Premises != 8 {
+		t.Errorf("expected 8 premises, got %d", summary.Premises)
+	}
+	ee := summary.Operators[0].Premises
+	if ee == nil || ee.FourG != 3 || ee.FourGPct != 37.5 {
+		t.Errorf("expected 3 (37.5%%) EE premises with indoor 4G, got %+v", ee)
+	}
+	if o2 := summary.Operators[1].Premises; o2 != nil {
+		t.Errorf("expected no indoor O2 counts, got %+v", o2)
+	}
+
+	for _, pc := range []string{"LS11AB", "ZE29XX"} {
+		if p, err := m.QueryPremises(pc); err != nil || p != nil {
+			t.Errorf("expected no premises for %s, got %+v (err %v)", pc, p, err)
+		}
+	}
+}
+
+func TestRankOf_PlacesScoreNationally(t *testing.T) {
+	dir := t.TempDir()
+	csv := "postcode,ee_4g,o2_4g,three_4g,vodafone_4g\n" +
+		"AB11AA,0,0,0,0\nAB11AB,1.0,0,0,0\nAB11AD,1.0,1.0,0,0\nAB11AE,1.0,1.0,1.0,1.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir)
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	row, _ := m.QueryPostcode("AB11AD")
+	score := ofcom.Interpret(row).CoverageScore
+	r, err := m.RankOf(score, "Scotland", "")
+	if err != nil || r == nil {
+		t.Fatalf("expected a rank, got %v (err %v)", r, err)
+	}
+	if r.National.Area != ofcom.National || r.National.Postcodes != 4 || r.National.BetterThan != 50 {
+		t.Errorf("expected better than 50%% of 4 postcodes nationally, got %+v", r.National)
+	}
+	if r.Region != nil {
+		t.Errorf("expected no regional rank without geographic data, got %+v", r.Region)
+	}
+}
+
+func TestBundle_InstallsIntoEmptyDataDir(t *testing.T) {
+	dir := t.TempDir()
+	csv := "postcode,ee_4g\nSW1A1AA,1.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir)
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	out := filepath.Join(t.TempDir(), "bundle.db")
+	if err := m.Bundle(out); err != nil {
+		t.Fatalf("bundle failed: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := ofcom.NewManager(t.TempDir())
+	if ok, err := target.InstallBundle(data); err != nil || !ok {
+		t.Fatalf("expected bundle to install, got %v (err %v)", ok, err)
+	}
+	row, err := target.QueryPostcode("SW1A1AA")
+	if err != nil || row == nil || row["ee_4g"] != "1" {
+		t.Fatalf("expected bundled row, got %v (err %v)", row, err)
+	}
+	if ok, _ := target.InstallBundle(data); ok {
+		t.Error("expected identical bundle not to be reinstalled")
+	}
+	if ok, _ := m.InstallBundle(data); ok {
+		t.Error("expected bundle not to replace a database built by setup")
+	}
+}
+
+func TestBundle_FromEncryptedDataDir(t *testing.T) {
+	dir := t.TempDir()
+	csv := "postcode,ee_4g\nSW1A1AA,1.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir, ofcom.WithEncryptionKey("correct horse"))
+	defer m.Close()
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	out := filepath.Join(t.TempDir(), "bundle.db")
+	if err := m.Bundle(out); err != nil {
+		t.Fatalf("bundle from an encrypted database failed: %v", err)
+	}
+	if !m.Encrypted() {
+		t.Error("expected the database to stay encrypted")
+	}
+	if _, err := os.Stat(m.DBPath + ".unsealed"); !os.IsNotExist(err) {
+		t.Errorf("expected no decrypted copy left, got %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := ofcom.NewManager(t.TempDir(), ofcom.WithEncryptionKey("battery staple"))
+	defer target.Close()
+	if ok, err := target.InstallBundle(data); err != nil || !ok {
+		t.Fatalf("expected bundle to install, got %v (err %v)", ok, err)
+	}
+	if !target.Encrypted() {
+		t.Error("expected the installed bundle encrypted with the target's key")
+	}
+	if row, err := target.QueryPostcode("SW1A1AA"); err != nil || row == nil || row["ee_4g"] != "1" {
+		t.Fatalf("expected bundled row, got %v (err %v)", row, err)
+	}
+}
+
+func TestCheckSpace_RefusesWhatWouldNotFit(t *testing.T) {
+	dir := t.TempDir()
+	free, err := ofcom.FreeSpace(dir)
+	if err != nil {
+		t.Skipf("free space unknown here: %v", err)
+	}
+	m := ofcom.NewManager(dir)
+	if err := m.CheckSpace(dir, 0); free > ofcom.SpaceMargin && err != nil {
+		t.Errorf("expected an empty request to fit, got %v", err)
+	}
+	if err := m.CheckSpace(dir, free); !errors.Is(err, ofcom.ErrNoSpace) {
+		t.Errorf("expected ErrNoSpace for the whole disk plus the margin, got %v", err)
+	}
+	if err := m.CheckSpace(filepath.Join(dir, "missing"), 1<<62); err != nil {
+		t.Errorf("expected an unmeasurable directory not to block setup, got %v", err)
+	}
+}
+
+func TestSetup_VerifiesChecksum(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	fw, _ := zw.Create("mobile_pc.csv")
+	fw.Write([]byte("postcode,ee_4g\nSW1A1AA,1.0\n"))
+	zw.Close()
+	zipData := buf.Bytes()
+	sum := sha256.Sum256(zipData)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(zipData)
+	}))
+	defer srv.Close()
+	ofcom.MobileDataURLs["test"] = srv.URL
+	defer delete(ofcom.MobileDataURLs, "test")
+
+	dir := t.TempDir()
+	m := ofcom.NewManager(dir)
+	err := m.Setup("test", ofcom.SetupOptions{SHA256: strings.Repeat("0", 64)})
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ofcom_mobile_test.csv")); !os.IsNotExist(err) {
+		t.Error("CSV should not be written when the checksum does not match")
+	}
+
+	if err := m.Setup("test", ofcom.SetupOptions{SHA256: hex.EncodeToString(sum[:])}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	mf, err := m.Manifest()
+	if err != nil || mf == nil {
+		t.Fatalf("expected manifest, got %v (err %v)", mf, err)
+	}
+	if !mf.Verified || mf.SHA256 != hex.EncodeToString(sum[:]) || mf.SourceURL != srv.URL {
+		t.Errorf("unexpected manifest: %+v", mf)
+	}
+	if mf.Size != int64(len(zipData)) {
+		t.Errorf("expected manifest size %d, got %d", len(zipData), mf.Size)
+	}
+	partial, _ := filepath.Glob(filepath.Join(dir, "*.partial"))
+	if len(partial) != 0 {
+		t.Errorf("expected downloaded ZIP to be removed, found %v", partial)
+	}
+}
+
+func TestSetup_DiscoversLatestEdition(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	fw, _ := zw.Create("2024_mobile_pc_r02.csv")
+	fw.Write([]byte("postcode,ee_4g\nSW1A1AA,1.0\n"))
+	zw.Close()
+	zipData := buf.Bytes()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/connected-nations", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<a href="/connected-nations-2023">2023</a> <a href='/connected-nations-2024/report'>2024</a>`))
+	})
+	mux.HandleFunc("/connected-nations-2024/report", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<a href="files/2024_mobile_pc_r01.zip">r01</a> <a href="files/2024_mobile_pc_r02.zip">r02</a>`))
+	})
+	mux.HandleFunc("/connected-nations-2024/files/2024_mobile_pc_r02.zip", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(zipData)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	found, err := ofcom.Discover(srv.URL+"/connected-nations", ofcom.LatestYear)
+	if err != nil {
+		t.Fatalf("discover failed: %v", err)
+	}
+	if found.Year != "2024" || found.Revision != 2 || found.URL != srv.URL+"/connected-nations-2024/files/2024_mobile_pc_r02.zip" {
+		t.Fatalf("unexpected edition %+v", found)
+	}
+
+	m := ofcom.NewManager(t.TempDir())
+	if err := m.Setup(ofcom.LatestYear, ofcom.SetupOptions{IndexURL: srv.URL + "/connected-nations"}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	if meta, err := m.Meta(); err != nil || meta["dataset_year"] != "2024" {
+		t.Errorf("expected dataset_year 2024, got %v (err %v)", meta, err)
+	}
+}
+
+func TestUpdate_InstallsNewerRevision(t *testing.T) {
+	zipOf := func(csv string) []byte {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		fw, _ := zw.Create("mobile_pc.csv")
+		fw.Write([]byte(csv))
+		zw.Close()
+		return buf.Bytes()
+	}
+	r01 := zipOf("postcode,ee_4g\nSW1A1AA,0.0\n")
+	r02 := zipOf("postcode,ee_4g\nSW1A1AA,1.0\n")
+	links := `<a href="/2024_mobile_pc_r01.zip">r01</a>`
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/index", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(links)) })
+	mux.HandleFunc("/2024_mobile_pc_r01.zip", func(w http.ResponseWriter, r *http.Request) { w.Write(r01) })
+	mux.HandleFunc("/2024_mobile_pc_r02.zip", func(w http.ResponseWriter, r *http.Request) { w.Write(r02) })
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	opts := ofcom.SetupOptions{IndexURL: srv.URL + "/index"}
+
+	m := ofcom.NewManager(t.TempDir())
+	if err := m.Setup(ofcom.LatestYear, opts); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	if err := m.StoreGeo([]ofcom.Place{{Postcode: "SW1A1AA", AdminDistrict: "Westminster"}}); err != nil {
+		t.Fatal(err)
+	}
+	if st, err := m.CheckForUpdate(opts.IndexURL); err != nil || st.Available {
+		t.Fatalf("expected no update, got %+v (err %v)", st, err)
+	}
+
+	links += ` <a href="/2024_mobile_pc_r02.zip">r02</a>`
+	st, err := m.Update(opts)
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if !st.Available || st.InstalledRevision != 1 || st.Latest.Revision != 2 {
+		t.Fatalf("unexpected status %+v", st)
+	}
+	if row, err := m.QueryPostcode("SW1A1AA"); err != nil || row["ee_4g"] != "1" {
+		t.Errorf("expected r02 data, got %v (err %v)", row, err)
+	}
+	if s, err := m.Aggregate("district", "Westminster"); err != nil || s == nil {
+		t.Errorf("expected geographic data to survive the update, got %v (err %v)", s, err)
+	}
+	if st, err := m.CheckForUpdate(opts.IndexURL); err != nil || st.Available {
+		t.Errorf("expected no further update, got %+v (err %v)", st, err)
+	}
+	if _, err := os.Stat(filepath.Join(m.DataDir, "editions")); !os.IsNotExist(err) {
+		t.Errorf("expected the staging directory to be removed, got %v", err)
+	}
+}
+
+func TestSetup_PrefersNewestRevision(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	fw, _ := zw.Create("mobile_pc.csv")
+	fw.Write([]byte("postcode,ee_4g\nSW1A1AA,1.0\n"))
+	zw.Close()
+	zipData := buf.Bytes()
+
+	var fetched []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/index", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<a href="/2031_mobile_pc_r01.zip">r01</a> <a href="/2031_mobile_pc_r03.zip">r03</a>`))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fetched = append(fetched, r.URL.Path)
+		w.Write(zipData)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	ofcom.MobileDataURLs["2031"] = srv.URL + "/2031_mobile_pc_r01.zip"
+	defer delete(ofcom.MobileDataURLs, "2031")
+
+	m := ofcom.NewManager(t.TempDir())
+	if err := m.Setup("2031", ofcom.SetupOptions{IndexURL: srv.URL + "/index"}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	if len(fetched) != 1 || fetched[0] != "/2031_mobile_pc_r03.zip" {
+		t.Errorf("expected only r03 to be downloaded, got %v", fetched)
+	}
+	if mf, err := m.Manifest(); err != nil || mf.Revision != 3 {
+		t.Errorf("expected revision 3 in the manifest, got %+v (err %v)", mf, err)
+	}
+	rel, err := m.Release()
+	if err != nil || rel.String() != "2031 r03" {
+		t.Errorf("expected release 2031 r03, got %v (err %v)", rel, err)
+	}
+	if st, err := m.Status(); err != nil || st.Revision != 3 {
+		t.Errorf("expected status revision 3, got %+v (err %v)", st, err)
+	}
+	if got := ofcom.RevisionOf("https://example.com/2022_mobile_pc_r02.zip"); got != 2 {
+		t.Errorf("expected revision 2, got %d", got)
+	}
+}
+
+func TestQueryPostcode_FollowsReplacedDatabase(t *testing.T) {
+	build := func(value string) *ofcom.Manager {
+		dir := t.TempDir()
+		csv := "postcode,ee_4g\nSW1A1AA," + value + "\n"
+		if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+			t.Fatal(err)
+		}
+		m := ofcom.NewManager(dir)
+		if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+		return m
+	}
+	live, next := build("0.25"), build("0.75")
+	defer live.Close()
+
+	if row, err := live.QueryPostcode("SW1A1AA"); err != nil || row["ee_4g"] != "0.25" {
+		t.Fatalf("expected initial data, got %v (err %v)", row, err)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if _, err := live.QueryPostcode("SW1A1AA"); err != nil {
+					t.Errorf("query during swap failed: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	if err := os.Rename(next.DBPath, live.DBPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := live.Reload(); err != nil {
+		t.Errorf("reload failed: %v", err)
+	}
+	close(stop)
+	wg.Wait()
+
+	if row, err := live.QueryPostcode("SW1A1AA"); err != nil || row["ee_4g"] != "0.75" {
+		t.Errorf("expected replaced data, got %v (err %v)", row, err)
+	}
+}
+
+func TestQueryPostcode_ConcurrentWithWrites(t *testing.T) {
+	dir := t.TempDir()
+	var csv strings.Builder
+	csv.WriteString("postcode,ee_4g\n")
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&csv, "LS1%dAA,0.%d\n", i, i%10)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir, ofcom.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	defer m.Close()
+
+	// Rebuilding ranks takes the write lock repeatedly while the lookups
+	// run; they wait for it rather than fail with "database is locked".
+	stop := make(chan struct{})
+	writerDone := make(chan error, 1)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				writerDone <- nil
+				return
+			default:
+			}
+			if err := m.BuildRanks(); err != nil {
+				writerDone <- err
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	var failed sync.Once
+	for i := 0; i < 500; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			pc := fmt.Sprintf("LS1%dAA", i%100)
+			if row, err := m.QueryPostcode(pc); err != nil || row == nil {
+				failed.Do(func() { t.Errorf("lookup %d of %s failed: %v (err %v)", i, pc, row, err) })
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(stop)
+	if err := <-writerDone; err != nil {
+		t.Errorf("rebuilding ranks failed: %v", err)
+	}
+}
+
+func TestQueryPostcodes_IndexMatchesSQL(t *testing.T) {
+	dir := t.TempDir()
+	csv := "Postcode,EE 4G,O2 4G,Three 5G\nSW1A 1AA,1.0,0.4,0.25\nEC1A 1BB,0.2,,0.9\n"
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir)
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	defer m.Close()
+
+	pcs := []string{"ec1a 1bb", "ZZ99ZZ", "SW1A1AA"}
+	viaSQL, err := m.QueryPostcodes(pcs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if viaSQL[1] != nil || viaSQL[0]["three_5g"] != "0.9" || viaSQL[2]["o2_4g"] != "0.4" {
+		t.Fatalf("unexpected batch result %v", viaSQL)
+	}
+
+	if err := m.LoadIndex(); err != nil {
+		t.Fatal(err)
+	}
+	viaIndex, err := m.QueryPostcodes(pcs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range pcs {
+		if fmt.Sprint(viaIndex[i]) != fmt.Sprint(viaSQL[i]) {
+			t.Errorf("%s: index row %v differs from SQL row %v", pcs[i], viaIndex[i], viaSQL[i])
+		}
+	}
+	if row, _ := m.QueryPostcode("SW1A 1AA"); row["ee_4g"] != "1" {
+		t.Errorf("expected indexed ee_4g 1, got %v", row)
+	}
+}
+
+// benchDataset builds a database of n synthetic postcodes and returns its
+// manager with the postcodes in a shuffled lookup order.
+func benchDataset(b *testing.B, n int) (*ofcom.Manager, []string) {
+	b.Helper()
+	dir := b.TempDir()
+	pcs := writeBenchCSV(b, dir, n)
+	m := ofcom.NewManager(dir, ofcom.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { m.Close() })
+	rand.New(rand.NewSource(1)).Shuffle(n, func(i, j int) { pcs[i], pcs[j] = pcs[j], pcs[i] })
+	return m, pcs
+}
+
+// writeBenchCSV writes a 2023 edition CSV of n synthetic postcodes, with
+// every column a full build stores, to dir and returns the postcodes.
+func writeBenchCSV(b *testing.B, dir string, n int) []string {
+	b.Helper()
+	var sb strings.Builder
+	var cols []string
+	for _, op := range []string{"ee", "o2", "three", "vodafone"} {
+		for _, m := range []string{"voice", "4g", "5g", "voice_indoor", "4g_indoor", "5g_indoor"} {
+			cols = append(cols, op+"_"+m)
+		}
+	}
+	sb.WriteString("postcode," + strings.Join(cols, ",") + "\n")
+	pcs := make([]string, n)
+	for i := range pcs {
+		pcs[i] = fmt.Sprintf("B%d%c%c", i/676, 'A'+i%26, 'A'+(i/26)%26)
+		sb.WriteString(pcs[i])
+		for j := range cols {
+			fmt.Fprintf(&sb, ",%.2f", float64((i+j)%101)/100)
+		}
+		sb.WriteString("\n")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(sb.String()), 0644); err != nil {
+		b.Fatal(err)
+	}
+	return pcs
+}
+
+// BenchmarkSetup_Build measures building the database from a downloaded
+// CSV, the bulk of setup's time.
+func BenchmarkSetup_Build(b *testing.B) {
+	dir := b.TempDir()
+	writeBenchCSV(b, dir, benchPostcodes*3)
+	m := ofcom.NewManager(dir, ofcom.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		os.Remove(m.DBPath)
+		b.StartTimer()
+		if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+const benchPostcodes = 100000
+
+func BenchmarkQueryPostcode_PerRow(b *testing.B) {
+	m, pcs := benchDataset(b, benchPostcodes)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, pc := range pcs {
+			if _, err := m.QueryPostcode(pc); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkQueryPostcodes_Batched(b *testing.B) {
+	m, pcs := benchDataset(b, benchPostcodes)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := m.QueryPostcodes(pcs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkQueryPostcodes_Index(b *testing.B) {
+	m, pcs := benchDataset(b, benchPostcodes)
+	if err := m.LoadIndex(); err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := m.QueryPostcodes(pcs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestSetup_VerifiesSignedManifest(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	fw, _ := zw.Create("mobile_pc.csv")
+	fw.Write([]byte("postcode,ee_4g\nSW1A1AA,1.0\n"))
+	zw.Close()
+	zipData := buf.Bytes()
+	sum := sha256.Sum256(zipData)
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := base64.StdEncoding.EncodeToString(pub)
+	manifest := []byte(fmt.Sprintf(`{"test": %q}`, hex.EncodeToString(sum[:])))
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, manifest))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/manifest.json":
+			w.Write(manifest)
+		case "/manifest.json.sig":
+			w.Write([]byte(sig))
+		case "/tampered.json":
+			w.Write(bytes.Replace(manifest, []byte(`"test"`), []byte(`"test" `), 1))
+		case "/tampered.json.sig":
+			w.Write([]byte(sig))
+		case "/unsigned.json":
+			w.Write(manifest)
+		case "/data.zip":
+			w.Write(zipData)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	ofcom.MobileDataURLs["test"] = srv.URL + "/data.zip"
+	defer delete(ofcom.MobileDataURLs, "test")
+
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	for _, tc := range []struct {
+		name string
+		opts ofcom.SetupOptions
+	}{
+		{"no key", ofcom.SetupOptions{ManifestURL: srv.URL + "/manifest.json"}},
+		{"wrong key", ofcom.SetupOptions{ManifestURL: srv.URL + "/manifest.json", ManifestKey: base64.StdEncoding.EncodeToString(otherPub)}},
+		{"tampered", ofcom.SetupOptions{ManifestURL: srv.URL + "/tampered.json", ManifestKey: key}},
+		{"unsigned", ofcom.SetupOptions{ManifestURL: srv.URL + "/unsigned.json", ManifestKey: key}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			err := ofcom.NewManager(dir).Setup("test", tc.opts)
+			if !errors.Is(err, ofcom.ErrManifestSignature) {
+				t.Fatalf("expected ErrManifestSignature, got %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "ofcom_mobile_test.csv")); !os.IsNotExist(err) {
+				t.Error("nothing should be downloaded without a verified manifest")
+			}
+		})
+	}
+
+	m := ofcom.NewManager(t.TempDir())
+	if err := m.Setup("test", ofcom.SetupOptions{ManifestURL: srv.URL + "/manifest.json", ManifestKey: key}); err != nil {
+		t.Fatalf("setup with a signed manifest failed: %v", err)
+	}
+	if mf, err := m.Manifest(); err != nil || mf == nil || !mf.Verified {
+		t.Errorf("expected a verified download, got %+v (err %v)", mf, err)
+	}
+
+	err = ofcom.NewManager(t.TempDir()).Setup("test", ofcom.SetupOptions{RequireChecksum: true})
+	if err == nil || !strings.Contains(err.Error(), "no known checksum") {
+		t.Errorf("expected --require-checksum to refuse an unverified download, got %v", err)
+	}
+}
+
+func TestStatus_ReportsUnreadableDatabase(t *testing.T) {
+	for name, content := range map[string]string{
+		"corrupt":        "this is not a SQLite database, just text long enough to look like a file header",
+		"missing tables": "", // an empty file opens as a database with no tables
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "mobile.db"), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			st, err := ofcom.NewManager(dir).Status()
+			if err != nil {
+				t.Fatalf("expected the problem as a field, got error %v", err)
+			}
+			if !st.Exists || st.Error == "" {
+				t.Errorf("expected an existing database with Error set, got %+v", st)
+			}
+		})
+	}
+}
+
+func TestCheckThreshold(t *testing.T) {
+	for _, ok := range []float64{0.01, ofcom.CoverageThreshold, 1} {
+		if err := ofcom.CheckThreshold(ok); err != nil {
+			t.Errorf("%g: unexpected error %v", ok, err)
+		}
+	}
+	// 0 would otherwise be taken as the default, ignoring what was asked.
+	for _, bad := range []float64{0, -0.1, 1.5} {
+		if err := ofcom.CheckThreshold(bad); err == nil {
+			t.Errorf("%g: expected an error", bad)
+		}
+	}
+}
+
+func TestVerify_FindsHalfBuiltDatabase(t *testing.T) {
+	build := func(csv string) *ofcom.Manager {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+			t.Fatal(err)
+		}
+		m := ofcom.NewManager(dir)
+		if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+		return m
+	}
+	failed := func(rep *ofcom.VerifyReport) []string {
+		var names []string
+		for _, c := range rep.Checks {
+			if !c.OK {
+				names = append(names, c.Name)
+			}
+		}
+		return names
+	}
+	header := "postcode"
+	row := func(pc string, v string) string {
+		line := pc
+		for range ofcom.Operators {
+			line += strings.Repeat(","+v, 3)
+		}
+		return line + "\n"
+	}
+	for _, op := range ofcom.Operators {
+		header += fmt.Sprintf(",%[1]s_voice,%[1]s_4g,%[1]s_5g", op)
+	}
+
+	whole := build(header + "\n" + row("SW1A1AA", "1.0") + row("SW1A2AA", "0.9") + row("EC1A1BB", "1.0") + row("LS11AA", "0.5"))
+	rep, err := whole.Verify(ofcom.VerifyOptions{MinRows: 4})
+	if err != nil {
+		t.Fatalf("verify failed: %v", err)
+	}
+	if !rep.OK || len(rep.Checks) != 6 {
+		t.Fatalf("expected all six checks to pass, got %+v", rep.Checks)
+	}
+
+	// Too few rows for a UK-wide edition, and a fixture that does not match.
+	rep, err = whole.Verify(ofcom.VerifyOptions{Fixtures: []ofcom.Fixture{{Postcode: "LS1 1AA", Expect: map[string]float64{"ee_4g": 1}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := failed(rep); rep.OK || !slices.Equal(got, []string{"rows", "fixtures"}) {
+		t.Errorf("expected rows and fixtures to fail, got %v", got)
+	}
+
+	// O2's headers not recognised: rows are there but its columns are empty.
+	half := build(strings.ReplaceAll(header, "o2_", "xx_") + "\n" + row("SW1A1AA", "1.0"))
+	rep, err = half.Verify(ofcom.VerifyOptions{MinRows: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := failed(rep); !slices.Equal(got, []string{"columns", "fixtures"}) || !strings.Contains(rep.Checks[3].Detail, "o2_voice") {
+		t.Errorf("expected empty O2 columns and fixtures to fail, got %v: %+v", got, rep.Checks)
+	}
+}
+
+func TestNotSpots_FewerThanNOperators(t *testing.T) {
+	dir := t.TempDir()
+	csv := "postcode,ee_4g,o2_4g,three_4g,vodafone_4g\n" +
+		"LA221AA,0.0,0.2,,0.0\n" + // no operator
+		"LA221AB,0.9,0.1,0.0,0.0\n" + // EE only
+		"LA221AD,0.0,0.0,0.0,0.0\n" + // no operator, most premises
+		"LA221AE,1.0,1.0,1.0,1.0\n" +
+		"LS11AA,0.0,0.0,0.0,0.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir)
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	premises := "Postcode,All Premises,EE 4G Prem Out\nLA22 1AB,3,3\nLA22 1AD,40,0\n"
+	path := filepath.Join(t.TempDir(), "premises.csv")
+	if err := os.WriteFile(path, []byte(premises), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.ImportPremises(path); err != nil {
+		t.Fatal(err)
+	}
+	list := func(spots []ofcom.NotSpot) string {
+		var pcs []string
+		for _, s := range spots {
+			pcs = append(pcs, s.Postcode)
+		}
+		return strings.Join(pcs, ",")
+	}
+
+	spots, err := m.NotSpots(ofcom.NotSpotOptions{Area: ofcom.ExportFilter{Outcode: "LA22"}, Tech: "4g"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := list(spots); got != "LA221AD,LA221AA" {
+		t.Errorf("expected the uncovered LA22 postcodes, most premises first, got %s", got)
+	}
+	if p := spots[0].Premises; p == nil || *p != 40 || spots[1].Premises != nil {
+		t.Errorf("expected premises counts where imported, got %+v", spots)
+	}
+
+	spots, err = m.NotSpots(ofcom.NotSpotOptions{Area: ofcom.ExportFilter{Outcode: "LA22"}, Tech: "4g", MinOperators: 2, Sort: "postcode"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := list(spots); got != "LA221AA,LA221AB,LA221AD" {
+		t.Errorf("expected postcodes with fewer than two operators by postcode, got %s", got)
+	}
+	if ops := spots[1].Operators; len(ops) != 1 || ops[0] != "EE" {
+		t.Errorf("expected LA22 1AB covered by EE alone, got %v", ops)
+	}
+
+	if _, err := m.NotSpots(ofcom.NotSpotOptions{Tech: "4g", MinOperators: 5}); err == nil {
+		t.Error("expected more operators than exist to be refused")
+	}
+}
+
+func TestWithRemote_QueriesOverRangeRequests(t *testing.T) {
+	dir := t.TempDir()
+	var csv strings.Builder
+	csv.WriteString("postcode,ee_4g,ee_5g\n")
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&csv, "LS%d %dAA,0.9,0.%d\n", i/100, i%100, i%10)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	local := ofcom.NewManager(dir)
+	if err := local.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	local.Close()
+	db, err := os.ReadFile(local.DBPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var served int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") == "" {
+			t.Errorf("expected only range requests, got %v", r.Header)
+		}
+		cw := &countingWriter{ResponseWriter: w}
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(cw, r, "mobile.db", time.Time{}, bytes.NewReader(db))
+		mu.Lock()
+		served += cw.n
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	m := ofcom.NewManager(t.TempDir(), ofcom.WithRemote(srv.URL+"/mobile.db", ofcom.RemoteOptions{ChunkSize: 4096}))
+	defer m.Close()
+	row, err := m.QueryPostcode("LS12 34AA")
+	if err != nil || row == nil || row["ee_5g"] != "0.4" {
+		t.Fatalf("expected LS1234AA from the remote database, got %v (err %v)", row, err)
+	}
+	if meta, err := m.Meta(); err != nil || meta["dataset_year"] != "2023" {
+		t.Errorf("expected remote metadata, got %v (err %v)", meta, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if served == 0 || served >= len(db)/2 {
+		t.Errorf("expected a lookup to fetch a small part of the %d-byte database, fetched %d bytes", len(db), served)
+	}
+}
+
+func TestWithRemote_ReloadReadsTheReplacedFile(t *testing.T) {
+	build := func(ee5g string) []byte {
+		dir := t.TempDir()
+		csv := "postcode,ee_4g,ee_5g\nLS1 1AA,0.9," + ee5g + "\n"
+		if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+			t.Fatal(err)
+		}
+		local := ofcom.NewManager(dir)
+		if err := local.Setup("2023", ofcom.SetupOptions{}); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+		local.Close()
+		db, err := os.ReadFile(local.DBPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return db
+	}
+	var mu sync.Mutex
+	db, etag := build("0.1"), `"v1"`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		db, etag := db, etag
+		mu.Unlock()
+		w.Header().Set("ETag", etag)
+		http.ServeContent(w, r, "mobile.db", time.Time{}, bytes.NewReader(db))
+	}))
+	defer srv.Close()
+
+	m := ofcom.NewManager(t.TempDir(), ofcom.WithRemote(srv.URL+"/mobile.db", ofcom.RemoteOptions{}))
+	defer m.Close()
+	if row, err := m.QueryPostcode("LS1 1AA"); err != nil || row["ee_5g"] != "0.1" {
+		t.Fatalf("expected the first file, got %v (err %v)", row, err)
+	}
+
+	next := build("0.7")
+	mu.Lock()
+	db, etag = next, `"v2"`
+	mu.Unlock()
+	for i := 0; i < 3; i++ {
+		if err := m.Reload(); err != nil {
+			t.Fatalf("reload %d failed: %v", i, err)
+		}
+		if row, err := m.QueryPostcode("LS1 1AA"); err != nil || row["ee_5g"] != "0.7" {
+			t.Fatalf("expected the replaced file after reload %d, got %v (err %v)", i, row, err)
+		}
+	}
+
+	// Closing unmounts the remote database; using the Manager again
+	// mounts it afresh.
+	if err := m.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	if row, err := m.QueryPostcode("LS1 1AA"); err != nil || row["ee_5g"] != "0.7" {
+		t.Errorf("expected the Manager to work after Close, got %v (err %v)", row, err)
+	}
+}
+
+func TestWithRemote_RefusesServersWithoutRanges(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("SQLite format 3\x00"))
+	}))
+	defer srv.Close()
+	m := ofcom.NewManager(t.TempDir(), ofcom.WithRemote(srv.URL, ofcom.RemoteOptions{}))
+	if _, err := m.QueryPostcode("LS11AA"); !errors.Is(err, ofcom.ErrRangeNotSupported) {
+		t.Errorf("expected ErrRangeNotSupported, got %v", err)
+	}
+}
+
+// countingWriter counts the body bytes written to a response.
+type countingWriter struct {
+	http.ResponseWriter
+	n int
+}
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.n += n
+	return n, err
+}
+
+func TestWithEncryptionKey_EncryptsAtRest(t *testing.T) {
+	dir := t.TempDir()
+	var csv strings.Builder
+	csv.WriteString("postcode,ee_4g,ee_5g\n")
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&csv, "LS%d %dAA,0.9,0.%d\n", i/100, i%100, i%10)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir, ofcom.WithEncryptionKey("correct horse"))
+	defer m.Close()
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	b, err := os.ReadFile(m.DBPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Encrypted() || bytes.Contains(b, []byte("SQLite format 3")) || bytes.Contains(b, []byte("LS1234AA")) {
+		t.Fatal("expected the database to be encrypted on disk")
+	}
+
+	row, err := m.QueryPostcode("LS12 34AA")
+	if err != nil || row == nil || row["ee_5g"] != "0.4" {
+		t.Fatalf("expected LS1234AA from the encrypted database, got %v (err %v)", row, err)
+	}
+	if err := m.StoreGeo([]ofcom.Place{{Postcode: "LS1234AA", Country: "England", Latitude: 53.8, Longitude: -1.55}}); err != nil {
+		t.Fatalf("storing geographic data failed: %v", err)
+	}
+	if !m.Encrypted() {
+		t.Error("expected the database to be encrypted again after a write")
+	}
+	if p, err := m.Place("LS12 34AA"); err != nil || p == nil || p.Latitude != 53.8 {
+		t.Errorf("expected the stored place, got %+v (err %v)", p, err)
+	}
+
+	if _, err := ofcom.NewManager(dir).QueryPostcode("LS12 34AA"); !errors.Is(err, ofcom.ErrEncrypted) {
+		t.Errorf("expected ErrEncrypted without a key, got %v", err)
+	}
+	if _, err := ofcom.NewManager(dir, ofcom.WithEncryptionKey("wrong")).QueryPostcode("LS12 34AA"); !errors.Is(err, seal.ErrDecrypt) {
+		t.Errorf("expected a wrong key to be refused, got %v", err)
+	}
+}
+
+func TestWithEncryptionKey_WritesNeverLeavePlainTextOnDisk(t *testing.T) {
+	dir := t.TempDir()
+	csv := "postcode,ee_4g,ee_5g\nLS1 1AA,0.9,0.4\nLS1 1AB,0.8,0.3\n"
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ofcom.NewManager(dir).Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	// A key set on an unencrypted database is warned about, and the
+	// database encrypted by the next write.
+	var logs bytes.Buffer
+	m := ofcom.NewManager(dir, ofcom.WithEncryptionKey("correct horse"), ofcom.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	defer m.Close()
+	if row, err := m.QueryPostcode("LS11AA"); err != nil || row == nil {
+		t.Fatalf("expected the unencrypted database to be read, got %v (err %v)", row, err)
+	}
+	if !strings.Contains(logs.String(), "NOT encrypted") {
+		t.Errorf("expected a warning about the unencrypted database, got %q", logs.String())
+	}
+
+	for i := 0; i < 2; i++ {
+		err := m.Unsealed(func(w *ofcom.Manager) error {
+			if w.DBPath == m.DBPath {
+				t.Fatal("expected writes to go to a copy")
+			}
+			if i > 0 && !m.Encrypted() {
+				t.Error("expected the database to stay encrypted while it is written")
+			}
+			if row, err := m.QueryPostcode("LS11AB"); err != nil || row == nil {
+				t.Errorf("expected reads during a write, got %v (err %v)", row, err)
+			}
+			return w.StoreGeo([]ofcom.Place{{Postcode: "LS11AA", Country: "England", Latitude: 53.8, Longitude: -1.55}})
+		})
+		if err != nil {
+			t.Fatalf("write %d failed: %v", i, err)
+		}
+		if !m.Encrypted() {
+			t.Fatalf("expected the database encrypted after write %d", i)
+		}
+	}
+	if p, err := m.Place("LS11AA"); err != nil || p == nil || p.Latitude != 53.8 {
+		t.Errorf("expected the stored place, got %+v (err %v)", p, err)
+	}
+
+	// Rebuilding keeps the superseded build, encrypted too.
+	var zipped bytes.Buffer
+	zw := zip.NewWriter(&zipped)
+	fw, _ := zw.Create("mobile_pc.csv")
+	fw.Write([]byte(csv))
+	zw.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(zipped.Bytes())
+	}))
+	defer srv.Close()
+	if err := m.Setup("2023", ofcom.SetupOptions{URL: srv.URL, Force: true}); err != nil {
+		t.Fatalf("rebuild failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "superseded", "mobile.db")); err != nil {
+		t.Errorf("expected the superseded build kept: %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || strings.HasSuffix(path, ".csv") {
+			return err
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.Contains(b, []byte("SQLite format 3")) || bytes.Contains(b, []byte("LS11AB")) {
+			t.Errorf("expected no plain text left on disk, found it in %s", path)
+		}
+		return nil
+	})
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".unsealed") || strings.Contains(e.Name(), ".sealing") || strings.HasSuffix(e.Name(), "-journal") {
+			t.Errorf("expected no working files left, found %s", e.Name())
+		}
+	}
+}
+
+func TestWithEncryptionKey_RemovesCopyLeftByCrash(t *testing.T) {
+	dir := t.TempDir()
+	csv := "postcode,ee_4g\nLS1 1AA,0.9\n"
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir, ofcom.WithEncryptionKey("correct horse"))
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	m.Close()
+	// A run that died in the middle of a write leaves its decrypted copy.
+	for _, suffix := range []string{".unsealed", ".unsealed-journal"} {
+		if err := os.WriteFile(m.DBPath+suffix, []byte("SQLite format 3\x00LS11AA"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var logs bytes.Buffer
+	m = ofcom.NewManager(dir, ofcom.WithEncryptionKey("correct horse"), ofcom.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	defer m.Close()
+	if row, err := m.QueryPostcode("LS11AA"); err != nil || row == nil {
+		t.Fatalf("expected the encrypted database to be read, got %v (err %v)", row, err)
+	}
+	for _, suffix := range []string{".unsealed", ".unsealed-journal"} {
+		if _, err := os.Stat(m.DBPath + suffix); !os.IsNotExist(err) {
+			t.Errorf("expected %s removed, got %v", suffix, err)
+		}
+	}
+	if !strings.Contains(logs.String(), "decrypted copy") {
+		t.Errorf("expected a warning about the decrypted copy, got %q", logs.String())
+	}
+}
+
+func TestWithEncryptionKey_RefusesTamperedChunks(t *testing.T) {
+	dir := t.TempDir()
+	var csv strings.Builder
+	csv.WriteString("postcode,ee_4g,ee_5g\n")
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&csv, "LS%d %dAA,0.9,0.%d\n", i/100, i%100, i%10)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte(csv.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := ofcom.NewManager(dir, ofcom.WithEncryptionKey("correct horse"))
+	if err := m.Setup("2023", ofcom.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	m.Close()
+	b, err := os.ReadFile(m.DBPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Flip a byte in every chunk after the first, which holds the schema.
+	const chunk = 64<<10 + 28
+	for off := 64 + chunk + 100; off < len(b); off += chunk {
+		b[off] ^= 1
+	}
+	if err := os.WriteFile(m.DBPath, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	tampered := ofcom.NewManager(dir, ofcom.WithEncryptionKey("correct horse"))
+	defer tampered.Close()
+	var failed int
+	for i := 0; i < 5000; i += 250 {
+		if _, err := tampered.QueryPostcode(fmt.Sprintf("LS%d %dAA", i/100, i%100)); err != nil {
+			failed++
+		}
+	}
+	if failed == 0 {
+		t.Error("expected lookups reading tampered chunks to fail")
+	}
+	if err := tampered.StoreGeo([]ofcom.Place{{Postcode: "LS11AA"}}); !errors.Is(err, seal.ErrDecrypt) {
+		t.Errorf("expected writing a tampered database to be refused, got %v", err)
+	}
+}
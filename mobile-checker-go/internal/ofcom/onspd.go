@@ -0,0 +1,266 @@
+package ofcom
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// onspdBatch is the number of geo rows stored per transaction by
+// ImportONSPD.
+const onspdBatch = 50000
+
+// onspdColumns maps Place fields to the header prefixes used for them by
+// ONSPD and NSPL releases, which append a vintage to some names (e.g.
+// "pcon24cd", "lad25cd").
+var onspdColumns = map[string][]string{
+	"postcode":     {"pcds", "pcd"},
+	"country":      {"ctry"},
+	"region":       {"rgn"},
+	"district":     {"oslaua", "laua", "lad"},
+	"constituency": {"pcon"},
+	"latitude":     {"lat"},
+	"longitude":    {"long"},
+	"eastings":     {"oseast1m"},
+	"northings":    {"osnrth1m"},
+	"doterm":       {"doterm"},
+}
+
+// onspdNames are names for the area codes that ONSPD stores. Countries
+// and English regions are fixed; district and constituency names come from
+// the lookup files shipped in the ONSPD ZIP's Documents folder.
+var onspdNames = map[string]string{
+	"E92000001": "England",
+	"W92000004": "Wales",
+	"S92000003": "Scotland",
+	"N92000002": "Northern Ireland",
+	"E12000001": "North East",
+	"E12000002": "North West",
+	"E12000003": "Yorkshire and The Humber",
+	"E12000004": "East Midlands",
+	"E12000005": "West Midlands",
+	"E12000006": "East of England",
+	"E12000007": "London",
+	"E12000008": "South East",
+	"E12000009": "South West",
+}
+
+// ImportONSPD stores geographic data for the dataset's postcodes from an
+// ONS Postcode Directory (ONSPD) or National Statistics Postcode Lookup
+// (NSPL) extract, so checks can be answered without postcodes.io. path is
+// the release ZIP, or its main CSV; area codes are named using the ZIP's
+// lookup documents where available and left as codes otherwise. Only
+// postcodes without geographic data are written, so it can follow or
+// complete a postcodes.io geocode. It returns the number of postcodes
+// stored.
+func (m *Manager) ImportONSPD(path string) (n int, err error) {
+	err = m.Unsealed(func(w *Manager) error {
+		n, err = w.importONSPD(path)
+		return err
+	})
+	return n, err
+}
+
+func (m *Manager) importONSPD(path string) (int, error) {
+	pending, err := m.UngeocodedPostcodes()
+	if err != nil {
+		return 0, err
+	}
+	want := make(map[string]bool, len(pending))
+	for _, pc := range pending {
+		want[pc] = true
+	}
+	if len(want) == 0 {
+		m.Logger.Info("all postcodes already geocoded")
+		return 0, nil
+	}
+
+	names := make(map[string]string, len(onspdNames))
+	for k, v := range onspdNames {
+		names[k] = v
+	}
+	var data io.ReadCloser
+	if strings.EqualFold(filepath.Ext(path), ".zip") {
+		zr, err := zip.OpenReader(path)
+		if err != nil {
+			return 0, fmt.Errorf("failed to open ONSPD ZIP: %w", err)
+		}
+		defer zr.Close()
+		if data, err = openONSPDZip(&zr.Reader, names); err != nil {
+			return 0, err
+		}
+	} else if data, err = os.Open(path); err != nil {
+		return 0, err
+	}
+	defer data.Close()
+
+	m.Logger.Info("importing geographic data from ONSPD", "path", path, "postcodes", len(want))
+	r := csv.NewReader(data)
+	r.ReuseRecord = true
+	headers, err := r.Read()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read ONSPD headers: %w", err)
+	}
+	col := onspdIndex(headers)
+	if col["postcode"] < 0 || col["latitude"] < 0 || col["longitude"] < 0 {
+		return 0, fmt.Errorf("not an ONSPD/NSPL file: no postcode, lat and long columns")
+	}
+
+	field := func(rec []string, name string) string {
+		if i := col[name]; i >= 0 && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+	named := func(code string) string {
+		if n, ok := names[code]; ok {
+			return n
+		}
+		return code
+	}
+
+	stored := 0
+	batch := make([]Place, 0, onspdBatch)
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return stored, fmt.Errorf("failed to read ONSPD: %w", err)
+		}
+		pc := normalisePostcode(field(rec, "postcode"))
+		if !want[pc] || field(rec, "doterm") != "" {
+			continue
+		}
+		lat, err1 := strconv.ParseFloat(field(rec, "latitude"), 64)
+		lon, err2 := strconv.ParseFloat(field(rec, "longitude"), 64)
+		if err1 != nil || err2 != nil || lat > 90 {
+			continue // ONSPD marks postcodes with no grid reference as 99.999999
+		}
+		e, _ := strconv.Atoi(field(rec, "eastings"))
+		n, _ := strconv.Atoi(field(rec, "northings"))
+		batch = append(batch, Place{
+			Postcode:      pc,
+			Country:       named(field(rec, "country")),
+			Region:        named(field(rec, "region")),
+			AdminDistrict: named(field(rec, "district")),
+			Constituency:  named(field(rec, "constituency")),
+			Latitude:      lat,
+			Longitude:     lon,
+			Eastings:      e,
+			Northings:     n,
+		})
+		delete(want, pc)
+		if len(batch) == onspdBatch {
+			if err := m.StoreGeo(batch); err != nil {
+				return stored, err
+			}
+			stored += len(batch)
+			batch = batch[:0]
+			m.Logger.Info("imported postcodes", "count", stored)
+		}
+	}
+	if err := m.StoreGeo(batch); err != nil {
+		return stored, err
+	}
+	stored += len(batch)
+	m.Logger.Info("ONSPD import complete", "stored", stored, "not_found", len(want))
+	return stored, m.BuildRanks()
+}
+
+// onspdIndex returns the header index for each onspdColumns field, or -1.
+func onspdIndex(headers []string) map[string]int {
+	idx := make(map[string]int, len(onspdColumns))
+	for field, prefixes := range onspdColumns {
+		idx[field] = -1
+	hdr:
+		for i, h := range headers {
+			h = normaliseHeader(strings.TrimPrefix(h, "\ufeff"))
+			for _, p := range prefixes {
+				// Vintaged names only add digits and "cd", e.g. pcon24cd.
+				if h == p || (strings.HasPrefix(h, p) && strings.Trim(h[len(p):], "0123456789") == "cd") {
+					idx[field] = i
+					break hdr
+				}
+			}
+		}
+	}
+	return idx
+}
+
+// openONSPDZip opens the main CSV in an ONSPD or NSPL ZIP — the largest CSV
+// under Data/, ignoring the per-area split files — and adds every code/name
+// pair from the lookup CSVs under Documents/ to names.
+func openONSPDZip(zr *zip.Reader, names map[string]string) (io.ReadCloser, error) {
+	var main *zip.File
+	for _, f := range zr.File {
+		name := strings.ToLower(f.Name)
+		if !strings.HasSuffix(name, ".csv") {
+			continue
+		}
+		switch {
+		case strings.Contains(name, "documents/"):
+			if err := readNameLookup(f, names); err != nil {
+				return nil, err
+			}
+		case strings.Contains(name, "data/") && !strings.Contains(name, "multi_csv"):
+			if main == nil || f.UncompressedSize64 > main.UncompressedSize64 {
+				main = f
+			}
+		}
+	}
+	if main == nil {
+		return nil, fmt.Errorf("no postcode CSV found under Data/ in ONSPD ZIP")
+	}
+	return main.Open()
+}
+
+// readNameLookup adds the code and name columns of an ONS lookup CSV, such
+// as "LAD23CD,LAD23NM", to names. Files without such a pair are skipped.
+func readNameLookup(f *zip.File, names map[string]string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	r := csv.NewReader(rc)
+	r.FieldsPerRecord = -1
+	headers, err := r.Read()
+	if err != nil {
+		return nil
+	}
+	code, name := -1, -1
+	for i, h := range headers {
+		h = strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		switch {
+		case code < 0 && strings.HasSuffix(h, "CD"):
+			code = i
+		case name < 0 && strings.HasSuffix(h, "NM"):
+			name = i
+		}
+	}
+	if code < 0 || name < 0 {
+		return nil
+	}
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return nil // a damaged lookup only costs names
+		}
+		if len(rec) <= max(code, name) {
+			continue
+		}
+		if c := strings.TrimSpace(rec[code]); c != "" {
+			names[c] = strings.TrimSpace(rec[name])
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package ofcom
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// outcodeExpr is the outward code of a mobile row's postcode, which is
+// stored without its space.
+const outcodeExpr = "substr(m.postcode, 1, length(m.postcode) - 3)"
+
+// outcodeSummaryColumns are the outcode_summary columns holding the values
+// of summaryExprs after its count, in the same order.
+func outcodeSummaryColumns() []string {
+	var cols []string
+	for _, op := range Operators {
+		for _, ms := range []string{"voice", "4g", "5g"} {
+			cols = append(cols, op+"_"+ms+"_mean", op+"_"+ms+"_covered")
+		}
+	}
+	return append(cols, "all_4g", "any_5g")
+}
+
+// buildOutcodeSummary replaces the outcode_summary table with the
+// AreaSummary values of every outward code, so OutcodeSummary reads one
+// row rather than aggregating the outcode's postcodes.
+func (m *Manager) buildOutcodeSummary(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM outcode_summary`); err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`INSERT INTO outcode_summary (outcode, postcodes, %s)
+		SELECT %s AS outcode, %s FROM mobile m GROUP BY outcode`,
+		strings.Join(outcodeSummaryColumns(), ", "), outcodeExpr, strings.Join(summaryExprs(), ", "))
+	if _, err := tx.Exec(query); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ensureOutcodeSummary builds the outcode summary of a database migrated
+// from before it existed.
+func (m *Manager) ensureOutcodeSummary(db *sql.DB) error {
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM outcode_summary`).Scan(&n); err != nil || n > 0 {
+		return err
+	}
+	m.Logger.Info("building outcode summary")
+	return m.buildOutcodeSummary(db)
+}
+
+// OutcodeSummary returns coverage statistics over the postcodes of an
+// outward code, e.g. "LS1", as precomputed by setup. A database from
+// before the summary existed is aggregated on the fly instead. It returns
+// nil if the dataset has no postcodes in the outcode.
+func (m *Manager) OutcodeSummary(outcode string) (*AreaSummary, error) {
+	oc := normalisePostcode(outcode)
+	db, release, err := m.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	vals := make([]float64, len(outcodeSummaryColumns()))
+	var count int
+	err = db.QueryRow(fmt.Sprintf(`SELECT postcodes, %s FROM outcode_summary WHERE outcode = ?`,
+		strings.Join(outcodeSummaryColumns(), ", ")), oc).Scan(summaryDest(&count, vals)...)
+	switch {
+	case err == nil:
+		return newAreaSummary("outcode", oc, count, vals), nil
+	case err == sql.ErrNoRows:
+		var built int
+		if err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM outcode_summary)`).Scan(&built); err != nil {
+			return nil, err
+		}
+		if built == 1 {
+			return nil, nil
+		}
+	case !strings.Contains(err.Error(), "no such table"):
+		return nil, err
+	}
+
+	err = db.QueryRow(fmt.Sprintf(`SELECT %s FROM mobile m WHERE %s = ?`, strings.Join(summaryExprs(), ", "), outcodeExpr),
+		oc).Scan(summaryDest(&count, vals)...)
+	if err != nil || count == 0 {
+		return nil, err
+	}
+	return newAreaSummary("outcode", oc, count, vals), nil
+}
@@ -0,0 +1,186 @@
+package ofcom
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// parquetBatch is the number of rows buffered per WriteRows call.
+const parquetBatch = 1000
+
+// geoColumns are the geo table columns ExportParquet can add, in order.
+var geoColumns = []string{"country", "region", "admin_district", "constituency", "latitude", "longitude", "eastings", "northings"}
+
+// ExportParquet writes the mobile table to out as a Parquet file, one row
+// per postcode, for tools such as DuckDB and Spark. Coverage columns are
+// nullable doubles named as in the database; with withGeo each row also
+// carries the postcode's geographic columns, null where it has none. A
+// non-empty filter restricts the rows as for Export. The dataset's metadata
+// is stored in the file's key/value metadata. It returns the number of rows
+// written.
+func (m *Manager) ExportParquet(out string, f ExportFilter, withGeo bool) (int, error) {
+	where, args := f.where()
+	if where != "" && !withGeo {
+		// The filter may refer to geo columns, so join without selecting them.
+		where = "m.postcode IN (SELECT m.postcode FROM mobile m LEFT JOIN geo g ON g.postcode = m.postcode WHERE " + where + ")"
+	}
+	if _, err := os.Stat(m.DBPath); os.IsNotExist(err) {
+		return 0, ErrDatabaseNotFound
+	}
+	if _, err := os.Stat(out); err == nil {
+		return 0, fmt.Errorf("%s already exists", out)
+	}
+	db, err := m.openMigrated()
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	query := "SELECT m.* FROM mobile m"
+	if withGeo {
+		query = "SELECT m.*, g." + strings.Join(geoColumns, ", g.") + " FROM mobile m LEFT JOIN geo g ON g.postcode = m.postcode"
+	}
+	if where != "" {
+		query += " WHERE " + where
+	}
+	rows, err := db.Query(query+" ORDER BY m.postcode", args...)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such") {
+			return 0, ErrNoGeoData
+		}
+		return 0, err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+
+	group := parquet.Group{}
+	for _, col := range cols {
+		group[col] = parquetNode(col)
+	}
+	schema := parquet.NewSchema("mobile", group)
+	// The schema orders columns by name; index maps each back to the query.
+	index := make(map[string]int, len(cols))
+	for i, col := range cols {
+		index[col] = i
+	}
+	leaves := schema.Columns()
+
+	tmp := out + ".partial"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp)
+	defer file.Close()
+	w := parquet.NewWriter(file, schema, parquet.Compression(&parquet.Snappy))
+	if meta, err := m.Meta(); err == nil {
+		for k, v := range meta {
+			w.SetKeyValueMetadata(k, v)
+		}
+	}
+
+	vals := make([]any, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	batch := make([]parquet.Row, 0, parquetBatch)
+	n := 0
+	flush := func() error {
+		if _, err := w.WriteRows(batch); err != nil {
+			return err
+		}
+		n += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return 0, err
+		}
+		row := make(parquet.Row, len(leaves))
+		for i, path := range leaves {
+			v := parquetValue(path[0], vals[index[path[0]]])
+			if v.IsNull() || path[0] == "postcode" {
+				row[i] = v.Level(0, 0, i)
+			} else {
+				row[i] = v.Level(0, 1, i) // defined, for an optional column
+			}
+		}
+		if batch = append(batch, row); len(batch) == parquetBatch {
+			if err := flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if err := flush(); err != nil {
+		return 0, err
+	}
+	if err := w.Close(); err != nil {
+		return 0, err
+	}
+	if err := file.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp, out); err != nil {
+		return 0, err
+	}
+	m.Logger.Info("exported parquet", "path", out, "filter", f.String(), "geo", withGeo, "rows", n)
+	return n, nil
+}
+
+// parquetNode returns the Parquet type of a mobile or geo column. Every
+// column but the postcode is optional, as the database allows NULLs.
+func parquetNode(col string) parquet.Node {
+	switch col {
+	case "postcode":
+		return parquet.String()
+	case "source", "country", "region", "admin_district", "constituency":
+		return parquet.Optional(parquet.String())
+	case "eastings", "northings":
+		return parquet.Optional(parquet.Int(64))
+	default:
+		return parquet.Optional(parquet.Leaf(parquet.DoubleType))
+	}
+}
+
+// parquetValue converts a SQLite value of col to its Parquet type. NULLs,
+// and values that do not fit the column's type, become Parquet nulls.
+func parquetValue(col string, v any) parquet.Value {
+	if v == nil {
+		return parquet.NullValue()
+	}
+	switch n := parquetNode(col); {
+	case n.Type().Kind() == parquet.ByteArray:
+		switch s := v.(type) {
+		case []byte:
+			return parquet.ByteArrayValue(s)
+		default:
+			return parquet.ByteArrayValue([]byte(fmt.Sprint(s)))
+		}
+	case n.Type().Kind() == parquet.Int64:
+		switch i := v.(type) {
+		case int64:
+			return parquet.Int64Value(i)
+		case float64:
+			return parquet.Int64Value(int64(i))
+		}
+	default:
+		switch d := v.(type) {
+		case float64:
+			return parquet.DoubleValue(d)
+		case int64:
+			return parquet.DoubleValue(float64(d))
+		}
+	}
+	return parquet.NullValue()
+}
@@ -0,0 +1,329 @@
+package ofcom
+
+import (
+	"archive/zip"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// premisesBatch is the number of premises rows stored per transaction by
+// ImportPremises.
+const premisesBatch = 50000
+
+// premisesTotalHeaders are the normalised headers Ofcom has used for the
+// number of premises in a postcode.
+var premisesTotalHeaders = []string{"all_premises", "premises", "total_premises", "all_prem", "premises_total", "number_of_premises"}
+
+// Premises is one postcode's row from Ofcom's premises coverage file: the
+// number of premises and, per canonical column such as "ee_4g_indoor", how
+// many of them are covered. Columns the file did not publish are absent.
+type Premises struct {
+	Postcode string
+	Total    int
+	Covered  map[string]int
+}
+
+// PremisesCoverage is how many of a postcode's premises an operator
+// covers, and that count as a percentage of MobileSummary.Premises.
+type PremisesCoverage struct {
+	Voice    int
+	FourG    int
+	FiveG    int
+	VoicePct float64
+	FourGPct float64
+	FiveGPct float64
+}
+
+// ImportPremises stores Ofcom's premises-level coverage, published
+// separately from the postcode file, from path: the release ZIP or its
+// CSV. It replaces any premises data already stored and keeps only
+// postcodes in the mobile table, so it follows a trimmed setup. The data
+// belongs to one edition and is not carried over by Update. It returns the
+// number of postcodes stored.
+func (m *Manager) ImportPremises(path string) (n int, err error) {
+	err = m.Unsealed(func(w *Manager) error {
+		n, err = w.importPremises(path)
+		return err
+	})
+	return n, err
+}
+
+func (m *Manager) importPremises(path string) (int, error) {
+	var data io.ReadCloser
+	if strings.EqualFold(filepath.Ext(path), ".zip") {
+		zr, err := zip.OpenReader(path)
+		if err != nil {
+			return 0, fmt.Errorf("failed to open premises ZIP: %w", err)
+		}
+		defer zr.Close()
+		if data, err = openLargestCSV(&zr.Reader); err != nil {
+			return 0, err
+		}
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return 0, err
+		}
+		data = f
+	}
+	defer data.Close()
+
+	r := csv.NewReader(data)
+	r.ReuseRecord = true
+	headers, err := r.Read()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read premises headers: %w", err)
+	}
+	pcCol, totalCol := -1, -1
+	mapping := make([]string, len(headers))
+	var cols []string
+	for i, h := range headers {
+		h = normaliseHeader(strings.TrimPrefix(h, "\ufeff"))
+		switch {
+		case pcCol < 0 && slices.Contains(postcodeHeaders, h):
+			pcCol = i
+		case totalCol < 0 && slices.Contains(premisesTotalHeaders, h):
+			totalCol = i
+		default:
+			if col := premisesColumn(h); col != "" && !slices.Contains(cols, col) {
+				mapping[i] = col
+				cols = append(cols, col)
+			}
+		}
+	}
+	if pcCol < 0 || totalCol < 0 || len(cols) == 0 {
+		return 0, fmt.Errorf("not an Ofcom premises file: no postcode, premises and operator premises columns")
+	}
+
+	db, err := m.openMigrated()
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+	if _, err := db.Exec(`DELETE FROM premises`); err != nil {
+		return 0, err
+	}
+
+	m.Logger.Info("importing premises coverage", "path", path, "columns", len(cols))
+	placeholders := strings.Repeat(", ?", len(cols))
+	insert := fmt.Sprintf(`INSERT OR REPLACE INTO premises (postcode, all_premises, %s)
+		SELECT ?, ?%s WHERE EXISTS (SELECT 1 FROM mobile WHERE postcode = ?)`,
+		strings.Join(cols, ", "), placeholders)
+	count := func(s string) any {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil || f < 0 {
+			return nil
+		}
+		return int64(math.Round(f))
+	}
+
+	stored := 0
+	batch := make([][]any, 0, premisesBatch)
+	flush := func() error {
+		n, err := storePremises(db, insert, batch)
+		stored += n
+		batch = batch[:0]
+		return err
+	}
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return stored, fmt.Errorf("failed to read premises file: %w", err)
+		}
+		if pcCol >= len(rec) || totalCol >= len(rec) {
+			continue
+		}
+		pc := normalisePostcode(rec[pcCol])
+		args := []any{pc, count(rec[totalCol])}
+		for i, col := range mapping {
+			if col != "" {
+				var v any
+				if i < len(rec) {
+					v = count(rec[i])
+				}
+				args = append(args, v)
+			}
+		}
+		batch = append(batch, append(args, pc))
+		if len(batch) == premisesBatch {
+			if err := flush(); err != nil {
+				return stored, err
+			}
+			m.Logger.Info("imported premises rows", "stored", stored)
+		}
+	}
+	if err := flush(); err != nil {
+		return stored, err
+	}
+	m.Logger.Info("premises import complete", "stored", stored)
+	return stored, nil
+}
+
+// storePremises runs insert for each row in one transaction and returns
+// the number of rows written.
+func storePremises(db *sql.DB, insert string, rows [][]any) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	stmt, err := tx.Prepare(insert)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	stored := 0
+	for _, args := range rows {
+		res, err := stmt.Exec(args...)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		n, _ := res.RowsAffected()
+		stored += int(n)
+	}
+	return stored, tx.Commit()
+}
+
+// premisesColumn maps a normalised premises header such as
+// "ee_4g_prem_in" or "vodafone_voice_premises_outdoor" to its canonical
+// column, e.g. "ee_4g_indoor", or "" when it is not an operator's count
+// of covered premises.
+func premisesColumn(h string) string {
+	tokens := strings.Split(h, "_")
+	op := ""
+	for prefix, aliases := range operatorPrefixes {
+		if slices.Contains(aliases, tokens[0]) {
+			op = prefix
+		}
+	}
+	var measure, place string
+	premises := false
+	for _, t := range tokens[1:] {
+		switch {
+		case t == "voice":
+			measure = "voice"
+		case t == "4g" || t == "data":
+			measure = "4g"
+		case t == "5g":
+			measure = "5g"
+		case t == "in" || t == "indoor":
+			place = "_indoor"
+		case t == "out" || t == "outdoor":
+		case strings.HasPrefix(t, "prem"):
+			premises = true
+		case t == "pct" || t == "percent" || t == "%":
+			return "" // a share of premises, not a count
+		}
+	}
+	if op == "" || measure == "" || !premises {
+		return ""
+	}
+	return op + "_" + measure + place
+}
+
+// openLargestCSV opens the largest CSV in a ZIP.
+func openLargestCSV(zr *zip.Reader) (io.ReadCloser, error) {
+	var largest *zip.File
+	for _, f := range zr.File {
+		if strings.HasSuffix(strings.ToLower(f.Name), ".csv") &&
+			(largest == nil || f.UncompressedSize64 > largest.UncompressedSize64) {
+			largest = f
+		}
+	}
+	if largest == nil {
+		return nil, fmt.Errorf("no CSV file found in ZIP")
+	}
+	return largest.Open()
+}
+
+// QueryPremises returns a postcode's premises coverage, or nil when none
+// was imported for it.
+func (m *Manager) QueryPremises(postcode string) (*Premises, error) {
+	db, release, err := m.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	rows, err := db.Query(`SELECT * FROM premises WHERE postcode = ?`, normalisePostcode(postcode))
+	if err != nil {
+		if strings.Contains(err.Error(), "no such table") {
+			return nil, nil // built before premises data existed
+		}
+		return nil, err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	vals := make([]sql.NullInt64, len(cols))
+	ptrs := make([]any, len(cols))
+	var pc string
+	for i := range cols {
+		ptrs[i] = &vals[i]
+		if cols[i] == "postcode" {
+			ptrs[i] = &pc
+		}
+	}
+	if err := rows.Scan(ptrs...); err != nil {
+		return nil, err
+	}
+	p := &Premises{Postcode: pc, Covered: make(map[string]int)}
+	for i, col := range cols {
+		switch {
+		case col == "postcode" || !vals[i].Valid:
+		case col == "all_premises":
+			p.Total = int(vals[i].Int64)
+		default:
+			p.Covered[col] = int(vals[i].Int64)
+		}
+	}
+	return p, nil
+}
+
+// AddPremises sets s.Premises and each operator's Premises from p, indoor
+// or outdoor as s is. Operators without published counts are left nil.
+func (s *MobileSummary) AddPremises(p *Premises) {
+	if p == nil || p.Total <= 0 {
+		return
+	}
+	suffix := ""
+	if s.Indoor {
+		suffix = "_indoor"
+	}
+	s.Premises = p.Total
+	for i := range s.Operators {
+		op, ok := operatorPrefix(s.Operators[i].Name)
+		if !ok {
+			continue
+		}
+		var pc PremisesCoverage
+		found := false
+		count := func(measure string) (int, float64) {
+			n, ok := p.Covered[op+"_"+measure+suffix]
+			found = found || ok
+			return n, math.Round(float64(n)/float64(p.Total)*1000) / 10
+		}
+		pc.Voice, pc.VoicePct = count("voice")
+		pc.FourG, pc.FourGPct = count("4g")
+		pc.FiveG, pc.FiveGPct = count("5g")
+		if found {
+			s.Operators[i].Premises = &pc
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package ofcom
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Rank places a postcode's coverage among all postcodes nationally and in
+// its region and district. It ranks the standard score — default weights,
+// all four operators, outdoor — whatever options the check used, so ranks
+// are comparable between postcodes.
+type Rank struct {
+	// Score is the standard score ranked.
+	Score    int
+	National Percentile
+	Region   *Percentile `json:",omitempty" xml:",omitempty"`
+	District *Percentile `json:",omitempty" xml:",omitempty"`
+}
+
+// Percentile is a postcode's standing within an area.
+type Percentile struct {
+	Area string
+	// BetterThan is the percentage of the area's postcodes with a lower
+	// standard score.
+	BetterThan float64
+	Postcodes  int
+}
+
+// National is the Area of national percentiles.
+const National = "United Kingdom"
+
+// buildScoreDistribution replaces the score_distribution table: for the
+// whole dataset and each region and district with geographic data, the
+// number of postcodes with each standard score. Regions are countries
+// outside England, as in postcodes.io. Ranks are read from it.
+func (m *Manager) buildScoreDistribution(db *sql.DB) error {
+	type area struct{ level, name string }
+	counts := map[area]*[101]int{}
+	add := func(level, name string, score, n int) {
+		a := area{level, name}
+		c := counts[a]
+		if c == nil {
+			c = new([101]int)
+			counts[a] = c
+		}
+		c[score] += n
+	}
+	cols, err := scoreColumns(db)
+	if err != nil {
+		return err
+	}
+	// Scores are computed and counted in SQL: scanning every row into Go
+	// would take most of a build's time.
+	rows, err := db.Query(`SELECT score, region, district, COUNT(*) FROM (
+			SELECT ` + scoreExpr(cols) + ` AS score, COALESCE(g.region, g.country) AS region, g.admin_district AS district
+			FROM mobile m LEFT JOIN geo g ON g.postcode = m.postcode)
+		GROUP BY score, region, district`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var score, n int
+		var region, district sql.NullString
+		if err := rows.Scan(&score, &region, &district, &n); err != nil {
+			return err
+		}
+		score = max(0, min(100, score))
+		add("national", National, score, n)
+		if region.String != "" {
+			add("region", region.String, score, n)
+		}
+		if district.String != "" {
+			add("district", district.String, score, n)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM score_distribution`); err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(`INSERT INTO score_distribution (level, area, score, postcodes) VALUES (?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for a, c := range counts {
+		for score, n := range c {
+			if n == 0 {
+				continue
+			}
+			if _, err := stmt.Exec(a.level, a.name, score, n); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// scoreColumns returns, for each operator in turn, expressions for its
+// outdoor voice, 4G and 5G coverage over "mobile m", as Interpret reads
+// them from db's columns. The standard score is computed from these rather
+// than from Interpret's summary, which keeps scoring the whole dataset
+// quick.
+func scoreColumns(db *sql.DB) ([]string, error) {
+	have := map[string]bool{}
+	err := scanRows(db, `SELECT name FROM pragma_table_info('mobile')`, nil, func(row map[string]string) {
+		have[row["name"]] = true
+	})
+	if err != nil {
+		return nil, err
+	}
+	var cols []string
+	for _, op := range Operators {
+		for _, keys := range outdoorKeys(op) {
+			var exprs []string
+			for _, k := range keys {
+				if have[k] {
+					exprs = append(exprs, "m."+k)
+				}
+			}
+			switch len(exprs) {
+			case 0:
+				cols = append(cols, "NULL")
+			case 1:
+				cols = append(cols, exprs[0])
+			default:
+				cols = append(cols, "COALESCE("+strings.Join(exprs, ", ")+")")
+			}
+		}
+	}
+	return cols, nil
+}
+
+// scoreExpr is ScoreWeights.Score with DefaultScoreWeights as SQL over the
+// expressions from scoreColumns, adding in the same order so the result is
+// the same.
+func scoreExpr(cols []string) string {
+	w := DefaultScoreWeights
+	weights := [3]float64{w.Voice, w.FourG, w.FiveG}
+	ops := make([]string, len(Operators))
+	for i := range ops {
+		var terms []string
+		for t, weight := range weights {
+			terms = append(terms, fmt.Sprintf("%v * MAX(0, MIN(1, COALESCE(%s, 0)))", weight, cols[i*3+t]))
+		}
+		ops[i] = fmt.Sprintf("(%s) / %v", strings.Join(terms, " + "), w.total())
+	}
+	return fmt.Sprintf("CAST(ROUND((%s) / %d * 100) AS INTEGER)", strings.Join(ops, " + "), len(ops))
+}
+
+// BuildRanks recomputes the score distribution ranks are read from. Setup
+// builds it nationally; it is rebuilt after geographic data is stored, by
+// ImportONSPD and the checker's Geocode, to add regions and districts.
+func (m *Manager) BuildRanks() error {
+	return m.Unsealed((*Manager).buildRanks)
+}
+
+func (m *Manager) buildRanks() error {
+	db, err := m.openMigrated()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if err := m.buildScoreDistribution(db); err != nil {
+		return err
+	}
+	m.Logger.Info("coverage ranks built")
+	return nil
+}
+
+// ensureScoreDistribution builds the score distribution of a database
+// migrated from before ranks existed.
+func (m *Manager) ensureScoreDistribution(db *sql.DB) error {
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM score_distribution`).Scan(&n); err != nil || n > 0 {
+		return err
+	}
+	m.Logger.Info("building coverage ranks")
+	return m.buildScoreDistribution(db)
+}
+
+// RankOf ranks a standard score nationally and, when given, within region
+// and district. It returns nil if the distribution has not been built,
+// e.g. for a database from before ranks existed (run setup again).
+func (m *Manager) RankOf(score int, region, district string) (*Rank, error) {
+	db, release, err := m.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	percentile := func(level, name string) (*Percentile, error) {
+		var below, total sql.NullInt64
+		var stored sql.NullString
+		err := db.QueryRow(`SELECT SUM(CASE WHEN score < ? THEN postcodes ELSE 0 END), SUM(postcodes), MIN(area)
+			FROM score_distribution WHERE level = ? AND area = ? COLLATE NOCASE`, score, level, name).Scan(&below, &total, &stored)
+		if err != nil || total.Int64 == 0 {
+			return nil, err
+		}
+		pct := float64(below.Int64) / float64(total.Int64) * 100
+		return &Percentile{Area: stored.String, BetterThan: math.Round(pct*10) / 10, Postcodes: int(total.Int64)}, nil
+	}
+
+	national, err := percentile("national", National)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such table") {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if national == nil {
+		return nil, nil
+	}
+	r := &Rank{Score: score, National: *national}
+	if region != "" {
+		if r.Region, err = percentile("region", region); err != nil {
+			return nil, err
+		}
+	}
+	if district != "" {
+		if r.District, err = percentile("district", district); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,202 @@
+package ofcom
+
+import (
+	"database/sql"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// readHandle is a shared read-only connection pool to one database file.
+type readHandle struct {
+	db     *sql.DB
+	info   os.FileInfo
+	remote *remoteDB      // the remote database db reads, for WithRemote
+	refs   sync.WaitGroup // queries in flight
+
+	index   atomic.Pointer[memIndex] // set by LoadIndex
+	dataset atomic.Pointer[Release]  // cached by Release
+}
+
+// acquire returns the shared read-only handle, reopening it first if the
+// database file has been replaced (e.g. by Setup or Update). Callers must
+// call release when their query is finished.
+func (m *Manager) acquire() (db *sql.DB, release func(), err error) {
+	h, release, err := m.acquireHandle()
+	if err != nil {
+		return nil, nil, err
+	}
+	return h.db, release, nil
+}
+
+// acquireHandle is acquire returning the whole handle, including any
+// in-memory index.
+func (m *Manager) acquireHandle() (h *readHandle, release func(), err error) {
+	if m.remoteDB() != nil {
+		return m.acquireRemote()
+	}
+	info, err := os.Stat(m.DBPath)
+	if os.IsNotExist(err) {
+		return nil, nil, ErrDatabaseNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m.mu.RLock()
+	h = m.reader
+	if h != nil && os.SameFile(h.info, info) {
+		h.refs.Add(1)
+		m.mu.RUnlock()
+		return h, h.refs.Done, nil
+	}
+	m.mu.RUnlock()
+
+	if err := m.swap(info, false); err != nil {
+		return nil, nil, err
+	}
+	return m.acquireHandle()
+}
+
+// acquireRemote is acquireHandle for a database set by WithRemote. It is
+// opened once; Reload picks up a replaced file.
+func (m *Manager) acquireRemote() (h *readHandle, release func(), err error) {
+	m.mu.RLock()
+	if h := m.reader; h != nil {
+		h.refs.Add(1)
+		m.mu.RUnlock()
+		return h, h.refs.Done, nil
+	}
+	r := m.remote
+	m.mu.RUnlock()
+
+	if err := m.swapRemote(r, false); err != nil {
+		return nil, nil, err
+	}
+	return m.acquireRemote()
+}
+
+// Reload reopens the database, switching new queries to the file currently
+// at DBPath. Queries already running on the previous handle finish before
+// it is closed. Replaced files are also picked up automatically on the next
+// query; Reload forces it, e.g. after restoring a backup in place.
+func (m *Manager) Reload() error {
+	if r := m.remoteDB(); r != nil {
+		// A new remoteDB, with its own cache and mount, so the old handle's
+		// queries finish on the file they started on.
+		next := &remoteDB{url: r.url, opts: r.opts}
+		if err := m.swapRemote(next, true); err != nil {
+			next.unmount()
+			return err
+		}
+		return nil
+	}
+	info, err := os.Stat(m.DBPath)
+	if os.IsNotExist(err) {
+		return ErrDatabaseNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return m.swap(info, true)
+}
+
+// swap opens the file described by info and makes it the shared handle.
+// Unless force is set, it keeps an existing handle that already points at
+// the same file (another caller won the race).
+func (m *Manager) swap(info os.FileInfo, force bool) error {
+	db, err := m.open(m.DBPath, true)
+	if err != nil {
+		return err
+	}
+	return m.install(&readHandle{db: db, info: info}, force)
+}
+
+// swapRemote is swap for r, a database set by WithRemote.
+func (m *Manager) swapRemote(r *remoteDB, force bool) error {
+	info, err := r.stat()
+	if err != nil {
+		return err
+	}
+	if _, _, err := r.mount(); err != nil {
+		return err
+	}
+	db, err := sql.Open(m.Driver.Name, r.dsn())
+	if err != nil {
+		return err
+	}
+	return m.install(&readHandle{db: db, info: info, remote: r}, force)
+}
+
+// install makes h the shared handle once it connects, closing the one it
+// replaces when that one's queries finish.
+func (m *Manager) install(h *readHandle, force bool) error {
+	h.db.SetMaxOpenConns(readConns)
+	h.db.SetMaxIdleConns(readConns)
+	if err := h.db.Ping(); err != nil {
+		h.db.Close()
+		return err
+	}
+
+	m.mu.Lock()
+	old := m.reader
+	if !force && old != nil && old.sameFile(h) {
+		m.mu.Unlock()
+		h.db.Close()
+		return nil
+	}
+	m.reader = h
+	if h.remote != nil {
+		m.remote = h.remote
+	}
+	m.mu.Unlock()
+
+	if old != nil {
+		m.Logger.Debug("reopened database", "path", m.DBPath)
+		go func() {
+			old.refs.Wait()
+			old.db.Close()
+			if old.remote != nil && old.remote != h.remote {
+				old.remote.unmount()
+			}
+		}()
+	}
+	return nil
+}
+
+// sameFile reports whether h and o read the same database file.
+func (h *readHandle) sameFile(o *readHandle) bool {
+	if h.remote != nil || o.remote != nil {
+		return h.remote == o.remote
+	}
+	return os.SameFile(h.info, o.info)
+}
+
+// Close releases the shared read handle, and those of any other dataset
+// years opened by ForYear. The Manager reopens it if used again.
+func (m *Manager) Close() error {
+	m.yearsMu.Lock()
+	years := m.years
+	m.years = nil
+	m.yearsMu.Unlock()
+	for _, ym := range years {
+		ym.Close()
+	}
+
+	m.mu.Lock()
+	old := m.reader
+	m.reader = nil
+	r := m.remote
+	m.mu.Unlock()
+	var err error
+	if old != nil {
+		old.refs.Wait()
+		err = old.db.Close()
+	}
+	// Mounted again if the Manager is used after all.
+	if r != nil {
+		r.unmount()
+	}
+	m.sealedMnt.unmount()
+	return err
+}
@@ -0,0 +1,329 @@
+package ofcom
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	// The remote VFS needs the pure-Go driver, whichever is the default.
+	_ "modernc.org/sqlite"
+)
+
+const (
+	// DefaultRemoteChunkSize is the bytes fetched by each range request
+	// for a remote database: a few SQLite pages, so the pages a lookup
+	// needs usually arrive together.
+	DefaultRemoteChunkSize = 64 << 10
+	// DefaultRemoteCacheSize is the bytes of a remote database kept in
+	// memory, shared by every connection.
+	DefaultRemoteCacheSize = 64 << 20
+)
+
+// ErrRangeNotSupported is returned for a remote database whose server
+// ignores HTTP range requests.
+var ErrRangeNotSupported = errors.New("server does not support HTTP range requests")
+
+// RemoteOptions tune reading a remote database; the zero value is ready
+// to use.
+type RemoteOptions struct {
+	// ChunkSize is DefaultRemoteChunkSize when 0.
+	ChunkSize int64
+	// CacheSize is DefaultRemoteCacheSize when 0.
+	CacheSize int64
+	// Client is http.DefaultClient when nil.
+	Client *http.Client
+}
+
+// WithRemote reads the database from url, a mobile.db served over HTTP(S)
+// by any server or object store that honours range requests (S3, GCS, a
+// CDN or nginx), instead of from the data directory. Only the pages
+// queries touch are downloaded, in chunks cached in memory, so a client
+// can check postcodes without fetching the whole database. The database
+// is read-only: Setup, Update and the commands that write or export it
+// still work on the data directory.
+//
+// Remote reads use the pure-Go SQLite driver, which is compiled in
+// whichever driver is the default.
+func WithRemote(url string, opts RemoteOptions) Option {
+	return func(m *Manager) {
+		m.remote = &remoteDB{url: url, opts: opts}
+		m.Driver = Driver{
+			Name: "sqlite",
+			DSN: func(string, bool) string {
+				return m.remoteDB().dsn()
+			},
+		}
+	}
+}
+
+// Remote returns the URL of the database set by WithRemote, or "".
+func (m *Manager) Remote() string {
+	r := m.remoteDB()
+	if r == nil {
+		return ""
+	}
+	return r.url
+}
+
+// remoteDB returns the remote database queries currently read, or nil
+// without WithRemote.
+func (m *Manager) remoteDB() *remoteDB {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.remote
+}
+
+// remoteFileName is the name the remote database has in its VFS.
+const remoteFileName = "mobile.db"
+
+// remoteDB is an fs.FS holding one file, the database at url, read with
+// range requests. Chunks are cached in least-recently-used order. Each
+// Reload reads the file through a new remoteDB, so queries still running
+// on the old one never see pages of the new file.
+type remoteDB struct {
+	url  string
+	opts RemoteOptions
+
+	mnt mountPoint
+
+	mu     sync.Mutex
+	info   *remoteInfo // nil until the first request
+	chunks map[int64]*list.Element
+	lru    list.List // of *remoteChunk, most recent first
+}
+
+type remoteChunk struct {
+	index int64
+	data  []byte
+}
+
+func (r *remoteDB) chunkSize() int64 {
+	if r.opts.ChunkSize > 0 {
+		return r.opts.ChunkSize
+	}
+	return DefaultRemoteChunkSize
+}
+
+// mount makes r readable by SQLite, if it is not already, returning the
+// VFS and the name of the database in it.
+func (r *remoteDB) mount() (vfsName, file string, err error) {
+	vfsName, prefix, err := r.mnt.mount(r)
+	return vfsName, prefix + "/" + remoteFileName, err
+}
+
+// unmount makes r unreadable by SQLite, once no connection uses it.
+func (r *remoteDB) unmount() { r.mnt.unmount() }
+
+// dsn returns the data source name opening r read-only, mounting it
+// first. A failed mount gives an unknown VFS, which SQLite reports when
+// the connection is made.
+func (r *remoteDB) dsn() string {
+	name, file, _ := r.mount()
+	return "file:" + file + "?vfs=" + name + "&mode=ro&immutable=1"
+}
+
+func (r *remoteDB) client() *http.Client {
+	if r.opts.Client != nil {
+		return r.opts.Client
+	}
+	return http.DefaultClient
+}
+
+// stat fetches the first chunk, if it has not been, learning the
+// database's size and validator.
+func (r *remoteDB) stat() (*remoteInfo, error) {
+	r.mu.Lock()
+	info := r.info
+	r.mu.Unlock()
+	if info != nil {
+		return info, nil
+	}
+	if _, err := r.chunk(0); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.info, nil
+}
+
+// chunk returns chunk i, from the cache or the server.
+func (r *remoteDB) chunk(i int64) ([]byte, error) {
+	r.mu.Lock()
+	if e, ok := r.chunks[i]; ok {
+		r.lru.MoveToFront(e)
+		r.mu.Unlock()
+		return e.Value.(*remoteChunk).data, nil
+	}
+	info := r.info
+	r.mu.Unlock()
+
+	size := r.chunkSize()
+	req, err := http.NewRequest(http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", i*size, (i+1)*size-1))
+	if info != nil && info.etag != "" {
+		// A changed file is answered in full rather than mixing pages of
+		// two versions.
+		req.Header.Set("If-Range", info.etag)
+	}
+	resp, err := r.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("remote database: %w", err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+	case resp.StatusCode == http.StatusOK && info != nil && info.etag != "":
+		return nil, fmt.Errorf("remote database %s changed while in use; reload to read the new version", r.url)
+	case resp.StatusCode == http.StatusOK:
+		return nil, fmt.Errorf("remote database %s: %w", r.url, ErrRangeNotSupported)
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("remote database %s: %w", r.url, ErrDatabaseNotFound)
+	default:
+		return nil, fmt.Errorf("remote database %s: HTTP %d", r.url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, size))
+	if err != nil {
+		return nil, fmt.Errorf("remote database: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.info == nil {
+		total, err := contentRangeSize(resp.Header.Get("Content-Range"))
+		if err != nil {
+			return nil, fmt.Errorf("remote database %s: %w", r.url, err)
+		}
+		r.info = &remoteInfo{size: total, etag: resp.Header.Get("ETag")}
+		if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+			r.info.modTime = t
+		}
+	}
+	if r.chunks == nil {
+		r.chunks = make(map[int64]*list.Element)
+	}
+	if _, ok := r.chunks[i]; !ok {
+		r.chunks[i] = r.lru.PushFront(&remoteChunk{index: i, data: data})
+		limit := r.opts.CacheSize
+		if limit <= 0 {
+			limit = DefaultRemoteCacheSize
+		}
+		for int64(r.lru.Len())*size > limit && r.lru.Len() > 1 {
+			oldest := r.lru.Remove(r.lru.Back()).(*remoteChunk)
+			delete(r.chunks, oldest.index)
+		}
+	}
+	return data, nil
+}
+
+// contentRangeSize parses the complete length from a Content-Range header
+// such as "bytes 0-65535/1048576".
+func contentRangeSize(h string) (int64, error) {
+	_, total, ok := strings.Cut(h, "/")
+	if !ok || !strings.HasPrefix(h, "bytes ") {
+		return 0, fmt.Errorf("invalid Content-Range %q", h)
+	}
+	n, err := strconv.ParseInt(total, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Content-Range %q has no length", h)
+	}
+	return n, nil
+}
+
+// readAt fills b from offset off, or as much as the file holds.
+func (r *remoteDB) readAt(b []byte, off int64) (int, error) {
+	info, err := r.stat()
+	if err != nil {
+		return 0, err
+	}
+	size := r.chunkSize()
+	n := 0
+	for n < len(b) && off+int64(n) < info.size {
+		pos := off + int64(n)
+		data, err := r.chunk(pos / size)
+		if err != nil {
+			return n, err
+		}
+		start := pos % size
+		if start >= int64(len(data)) {
+			break
+		}
+		n += copy(b[n:], data[start:])
+	}
+	if n == 0 && len(b) > 0 {
+		return 0, io.EOF
+	}
+	// A short read is not an error, so SQLite's VFS treats it as reading
+	// past the end of the file.
+	return n, nil
+}
+
+// Open implements fs.FS. SQLite also asks for journal and WAL files,
+// which do not exist.
+func (r *remoteDB) Open(name string) (fs.File, error) {
+	if name != remoteFileName && !strings.HasSuffix(name, "/"+remoteFileName) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if _, err := r.stat(); err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &remoteFile{db: r}, nil
+}
+
+// remoteFile is an open handle on a remoteDB, with its own offset.
+type remoteFile struct {
+	db  *remoteDB
+	off int64
+}
+
+func (f *remoteFile) Stat() (fs.FileInfo, error) { return f.db.stat() }
+
+func (f *remoteFile) Read(b []byte) (int, error) {
+	n, err := f.db.readAt(b, f.off)
+	f.off += int64(n)
+	return n, err
+}
+
+func (f *remoteFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.off
+	case io.SeekEnd:
+		info, err := f.db.stat()
+		if err != nil {
+			return 0, err
+		}
+		offset += info.size
+	}
+	if offset < 0 {
+		return 0, errors.New("negative offset")
+	}
+	f.off = offset
+	return offset, nil
+}
+
+func (f *remoteFile) Close() error { return nil }
+
+// remoteInfo describes the remote database as an fs.FileInfo.
+type remoteInfo struct {
+	size    int64
+	etag    string
+	modTime time.Time
+}
+
+func (i *remoteInfo) Name() string       { return remoteFileName }
+func (i *remoteInfo) Size() int64        { return i.size }
+func (i *remoteInfo) Mode() fs.FileMode  { return 0444 }
+func (i *remoteInfo) ModTime() time.Time { return i.modTime }
+func (i *remoteInfo) IsDir() bool        { return false }
+func (i *remoteInfo) Sys() any           { return nil }
@@ -0,0 +1,287 @@
+package ofcom
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SchemaVersion is the version of the canonical database schema written by
+// this build. Databases with an older version are migrated on setup.
+const SchemaVersion = 9
+
+// Operators lists the canonical operator column prefixes in display order.
+var Operators = []string{"ee", "o2", "three", "vodafone"}
+
+// Measures lists the canonical per-operator measure suffixes.
+var Measures = append(baseMeasures, legacyMeasures...)
+
+// baseMeasures are the measures of the original schema.
+var baseMeasures = []string{"voice", "voice_indoor", "4g", "4g_indoor", "5g", "5g_indoor"}
+
+// legacyMeasures are 2G and 3G data coverage, published by some editions
+// and added in schema version 5. Rows from editions without them hold NULL.
+var legacyMeasures = []string{"3g", "3g_indoor", "2g", "2g_indoor"}
+
+// CanonicalColumns returns the REAL coverage columns of the mobile table,
+// e.g. "ee_voice", "o2_4g_indoor", followed by "any_coverage".
+func CanonicalColumns() []string {
+	return append(measureColumns(Measures), "any_coverage")
+}
+
+// measureColumns returns the column for every operator and measure.
+func measureColumns(measures []string) []string {
+	cols := make([]string, 0, len(Operators)*len(measures))
+	for _, op := range Operators {
+		for _, ms := range measures {
+			cols = append(cols, op+"_"+ms)
+		}
+	}
+	return cols
+}
+
+// Edition maps the CSV layout of one Ofcom release onto the canonical schema.
+type Edition struct {
+	Year string
+	// Percent is true when coverage values are 0–100 rather than 0–1.
+	Percent bool
+	// Aliases maps a canonical column to extra normalised header names used
+	// by this edition, checked after the built-in defaults.
+	Aliases map[string][]string
+	// Rows is the range of rows Verify expects of the UK-wide file; zero
+	// means DefaultRows.
+	Rows [2]int
+
+	mapped bool // Aliases include a user's ColumnMap
+}
+
+// Editions holds the known Ofcom editions. Unknown years use the defaults.
+var Editions = map[string]Edition{
+	"2022": {Year: "2022"},
+	"2023": {Year: "2023"},
+	"2024": {Year: "2024"},
+}
+
+// EditionFor returns the mapping for a dataset year.
+func EditionFor(year string) Edition {
+	if e, ok := Editions[year]; ok {
+		return e
+	}
+	return Edition{Year: year}
+}
+
+// operatorPrefixes are the header prefixes Ofcom has used for each operator.
+var operatorPrefixes = map[string][]string{
+	"ee":       {"ee"},
+	"o2":       {"o2", "tf"},
+	"three":    {"three", "h3"},
+	"vodafone": {"vodafone", "vf", "vod"},
+}
+
+// measureSuffixes are the header suffixes Ofcom has used for each measure.
+var measureSuffixes = map[string][]string{
+	"voice":        {"voice", "voice_outdoor"},
+	"voice_indoor": {"voice_indoor"},
+	"4g":           {"4g", "4g_outdoor", "4g_data_outdoor", "data_outdoor"},
+	"4g_indoor":    {"4g_indoor", "4g_data_indoor", "data_indoor"},
+	"5g":           {"5g", "5g_outdoor", "5g_data_outdoor"},
+	"5g_indoor":    {"5g_indoor", "5g_data_indoor"},
+	"3g":           {"3g", "3g_outdoor", "3g_data_outdoor"},
+	"3g_indoor":    {"3g_indoor", "3g_data_indoor"},
+	"2g":           {"2g", "2g_outdoor", "2g_data_outdoor"},
+	"2g_indoor":    {"2g_indoor", "2g_data_indoor"},
+}
+
+var postcodeHeaders = []string{"postcode", "pcds", "pcd", "pcd2", "pcd_nospaces", "postcode_space"}
+
+var anyCoverageHeaders = []string{"any_coverage", "any_operator", "any_operator_coverage"}
+
+// aliases returns every accepted header name for a canonical column.
+func (e Edition) aliases(col string) []string {
+	var names []string
+	switch col {
+	case "postcode":
+		names = append(names, postcodeHeaders...)
+	case "any_coverage":
+		names = append(names, anyCoverageHeaders...)
+	default:
+		op, ms, _ := strings.Cut(col, "_")
+		for _, p := range operatorPrefixes[op] {
+			for _, s := range measureSuffixes[ms] {
+				names = append(names, p+"_"+s, p+strings.ReplaceAll(s, "_", ""))
+			}
+		}
+	}
+	return append(names, e.Aliases[col]...)
+}
+
+// MapHeaders resolves normalised CSV headers to canonical columns. It returns
+// the canonical column for each header index ("" when unmapped) and the list
+// of headers that were not recognised. It fails with a *LayoutError when
+// there is no postcode column or no operator coverage column.
+func (e Edition) MapHeaders(headers []string) (mapping []string, unknown []string, err error) {
+	index := make(map[string]string)
+	for _, col := range append([]string{"postcode"}, CanonicalColumns()...) {
+		for _, alias := range e.aliases(col) {
+			if _, taken := index[alias]; !taken {
+				index[alias] = col
+			}
+		}
+	}
+
+	mapping = make([]string, len(headers))
+	seen := make(map[string]bool)
+	for i, h := range headers {
+		col, ok := index[h]
+		if !ok || seen[col] {
+			unknown = append(unknown, h)
+			continue
+		}
+		mapping[i] = col
+		seen[col] = true
+	}
+	if !seen["postcode"] {
+		return nil, nil, &LayoutError{Year: e.Year, Missing: "postcode column", Unrecognised: unknown}
+	}
+	coverage := false
+	for col := range seen {
+		if col != "postcode" && col != "any_coverage" {
+			coverage = true
+		}
+	}
+	if !coverage {
+		return nil, nil, &LayoutError{Year: e.Year, Missing: "operator coverage columns", Unrecognised: unknown}
+	}
+	return mapping, unknown, nil
+}
+
+// normaliseHeader converts a raw CSV header to lower_snake_case.
+func normaliseHeader(h string) string {
+	return strings.ToLower(strings.TrimSpace(strings.ReplaceAll(h, " ", "_")))
+}
+
+type migration struct {
+	version int
+	stmts   []string
+}
+
+// migrations brings a database up to SchemaVersion, one step at a time.
+func migrations() []migration {
+	cols := []string{"postcode TEXT PRIMARY KEY"}
+	for _, c := range append(measureColumns(baseMeasures), "any_coverage") {
+		cols = append(cols, fmt.Sprintf("%s REAL", c))
+	}
+	var legacy []string
+	premises := []string{"postcode TEXT PRIMARY KEY", "all_premises INTEGER"}
+	for _, c := range measureColumns(baseMeasures) {
+		premises = append(premises, fmt.Sprintf("%s INTEGER", c))
+	}
+	for _, c := range measureColumns(legacyMeasures) {
+		legacy = append(legacy, fmt.Sprintf("ALTER TABLE mobile ADD COLUMN %s REAL", c))
+	}
+	outcodes := []string{"outcode TEXT PRIMARY KEY", "postcodes INTEGER NOT NULL"}
+	for _, c := range outcodeSummaryColumns() {
+		outcodes = append(outcodes, fmt.Sprintf("%s REAL", c))
+	}
+	return []migration{
+		{1, []string{
+			fmt.Sprintf("CREATE TABLE IF NOT EXISTS mobile (%s)", strings.Join(cols, ", ")),
+			"CREATE TABLE IF NOT EXISTS meta (key TEXT PRIMARY KEY, value TEXT)",
+		}},
+		{2, []string{
+			`CREATE TABLE IF NOT EXISTS geo (
+				postcode TEXT PRIMARY KEY,
+				country TEXT,
+				region TEXT,
+				admin_district TEXT,
+				constituency TEXT,
+				latitude REAL,
+				longitude REAL,
+				eastings INTEGER,
+				northings INTEGER
+			)`,
+			"CREATE INDEX IF NOT EXISTS idx_geo_region ON geo(region COLLATE NOCASE)",
+			"CREATE INDEX IF NOT EXISTS idx_geo_district ON geo(admin_district COLLATE NOCASE)",
+		}},
+		{3, []string{
+			"CREATE INDEX IF NOT EXISTS idx_geo_grid ON geo(eastings, northings)",
+		}},
+		{4, []string{
+			"CREATE INDEX IF NOT EXISTS idx_geo_latlon ON geo(latitude, longitude)",
+		}},
+		{5, legacy},
+		{6, []string{
+			fmt.Sprintf("CREATE TABLE IF NOT EXISTS premises (%s)", strings.Join(premises, ", ")),
+		}},
+		{7, []string{
+			`CREATE TABLE IF NOT EXISTS score_distribution (
+				level TEXT NOT NULL,
+				area TEXT NOT NULL,
+				score INTEGER NOT NULL,
+				postcodes INTEGER NOT NULL,
+				PRIMARY KEY (level, area, score)
+			)`,
+		}},
+		// The nation layer a row came from; NULL for the UK-wide file.
+		{8, []string{"ALTER TABLE mobile ADD COLUMN source TEXT"}},
+		{9, []string{
+			fmt.Sprintf("CREATE TABLE IF NOT EXISTS outcode_summary (%s)", strings.Join(outcodes, ", ")),
+		}},
+	}
+}
+
+// migrate applies any pending migrations and records them in schema_version.
+func migrate(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_version (version INTEGER PRIMARY KEY, applied_at TEXT)`); err != nil {
+		return err
+	}
+	current, err := schemaVersion(db)
+	if err != nil {
+		return err
+	}
+	for _, mg := range migrations() {
+		if mg.version <= current {
+			continue
+		}
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		for _, stmt := range mg.stmts {
+			if _, err := tx.Exec(stmt); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d: %w", mg.version, err)
+			}
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_version VALUES (?, ?)`,
+			mg.version, time.Now().UTC().Format(time.RFC3339)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schemaVersion returns the highest applied migration, or 0 for a database
+// built before versioning was introduced.
+func schemaVersion(db *sql.DB) (int, error) {
+	var v sql.NullInt64
+	err := db.QueryRow(`SELECT MAX(version) FROM schema_version`).Scan(&v)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such table") {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return int(v.Int64), nil
+}
+
+// setMeta stores a dataset metadata value.
+func setMeta(db *sql.DB, key, value string) error {
+	_, err := db.Exec(`INSERT OR REPLACE INTO meta (key, value) VALUES (?, ?)`, key, value)
+	return err
+}
@@ -0,0 +1,99 @@
+package ofcom
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ScoreWeights sets how much each technology contributes to a
+// CoverageScore. Weights are relative: {1, 2, 1} and {25, 50, 25} score the
+// same.
+type ScoreWeights struct {
+	Voice float64
+	FourG float64
+	FiveG float64
+}
+
+// DefaultScoreWeights favours 4G, which carries most data traffic, over
+// voice and 5G.
+var DefaultScoreWeights = ScoreWeights{Voice: 0.3, FourG: 0.5, FiveG: 0.2}
+
+// gradeBands are the lowest scores earning each grade; anything lower is E.
+var gradeBands = []struct {
+	min   int
+	grade string
+}{{80, "A"}, {65, "B"}, {50, "C"}, {35, "D"}}
+
+// ParseScoreWeights parses weights written as "voice=0.3,4g=0.5,5g=0.2".
+// Technologies left out get weight 0.
+func ParseScoreWeights(s string) (ScoreWeights, error) {
+	var w ScoreWeights
+	for _, part := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return w, fmt.Errorf("invalid weight %q (want tech=number)", part)
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil || f < 0 {
+			return w, fmt.Errorf("invalid weight %q: must be a non-negative number", part)
+		}
+		switch strings.ToLower(strings.TrimSpace(k)) {
+		case "voice":
+			w.Voice = f
+		case "4g":
+			w.FourG = f
+		case "5g":
+			w.FiveG = f
+		default:
+			return w, fmt.Errorf("unknown technology %q (want voice, 4g or 5g)", k)
+		}
+	}
+	if w.total() == 0 {
+		return w, fmt.Errorf("at least one weight must be positive")
+	}
+	return w, nil
+}
+
+func (w ScoreWeights) total() float64 {
+	return w.Voice + w.FourG + w.FiveG
+}
+
+// Score combines per-operator coverage fractions (voice, 4G, 5G, each 0–1)
+// into a 0–100 score: the weighted average across technologies, averaged
+// over operators. Zero weights fall back to DefaultScoreWeights.
+func (w ScoreWeights) Score(coverage [][3]float64) int {
+	if w.total() == 0 {
+		w = DefaultScoreWeights
+	}
+	if len(coverage) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, c := range coverage {
+		sum += (w.Voice*c[0] + w.FourG*c[1] + w.FiveG*c[2]) / w.total()
+	}
+	return int(math.Round(sum / float64(len(coverage)) * 100))
+}
+
+// outdoorKeys are the columns an operator's outdoor voice, 4G and 5G
+// coverage are read from, most preferred first.
+func outdoorKeys(op string) [3][]string {
+	return [3][]string{
+		{op + "_voice", op + "_voice_indoor"},
+		{op + "_4g", op + "4g"},
+		{op + "_5g", op + "5g"},
+	}
+}
+
+// Grade converts a 0–100 score to a letter: A (80+), B (65+), C (50+),
+// D (35+) or E.
+func Grade(score int) string {
+	for _, b := range gradeBands {
+		if score >= b.min {
+			return b.grade
+		}
+	}
+	return "E"
+}
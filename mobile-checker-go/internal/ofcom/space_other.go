@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package ofcom
+
+import "errors"
+
+// freeSpace cannot measure free space on this platform.
+func freeSpace(string) (int64, error) {
+	return 0, errors.New("free space unknown on this platform")
+}
@@ -0,0 +1,137 @@
+package ofcom
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrDatasetEmpty is returned by Ready for a database with no coverage rows.
+var ErrDatasetEmpty = errors.New("database contains no rows — run 'setup --force'")
+
+// DatasetStatus describes the local database for diagnostics.
+type DatasetStatus struct {
+	Path          string `json:"path"`
+	Exists        bool   `json:"exists"`
+	SizeBytes     int64  `json:"size_bytes"`
+	SchemaVersion int    `json:"schema_version"`
+	Year          string `json:"year,omitempty"`
+	Revision      int    `json:"revision,omitempty"`
+	BuiltAt       string `json:"built_at,omitempty"`
+	Rows          int    `json:"rows"`
+	GeocodedRows  int    `json:"geocoded_rows"`
+	// ColumnSet is the column set chosen at setup (see SetupOptions) and
+	// Columns the coverage columns it stores.
+	ColumnSet string   `json:"column_set,omitempty"`
+	Columns   []string `json:"columns,omitempty"`
+	// Nations lists the nations stored when setup was limited to some.
+	Nations []string `json:"nations,omitempty"`
+	// Layers lists the nations whose own editions were layered over the
+	// UK-wide file.
+	Layers []string `json:"layers,omitempty"`
+	// Error is set when the database exists but cannot be read, e.g. it is
+	// corrupt or a table is missing; the fields after it are then unset.
+	Error string `json:"error,omitempty"`
+}
+
+// Status inspects the local database. A missing database is reported with
+// Exists false, and one that cannot be read with Error, rather than as an
+// error, so diagnostics still work when they are needed most.
+func (m *Manager) Status() (*DatasetStatus, error) {
+	st := &DatasetStatus{Path: m.DBPath}
+	info, err := os.Stat(m.DBPath)
+	if os.IsNotExist(err) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	st.Exists = true
+	st.SizeBytes = info.Size()
+	if wal, err := os.Stat(m.DBPath + "-wal"); err == nil {
+		st.SizeBytes += wal.Size()
+	}
+	if err := m.inspect(st); err != nil {
+		st.Error = err.Error()
+	}
+	return st, nil
+}
+
+// inspect fills in st from the database's tables.
+func (m *Manager) inspect(st *DatasetStatus) error {
+	db, err := m.open(m.DBPath, true)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if st.SchemaVersion, err = schemaVersion(db); err != nil {
+		return err
+	}
+	if err := db.QueryRow(`SELECT COUNT(*) FROM mobile`).Scan(&st.Rows); err != nil {
+		return err
+	}
+	if st.SchemaVersion == 0 {
+		return nil
+	}
+	if st.SchemaVersion >= 2 {
+		if err := db.QueryRow(`SELECT COUNT(*) FROM geo WHERE region IS NOT NULL`).Scan(&st.GeocodedRows); err != nil {
+			return err
+		}
+	}
+	db.QueryRow(`SELECT value FROM meta WHERE key = 'dataset_year'`).Scan(&st.Year)
+	db.QueryRow(`SELECT value FROM meta WHERE key = 'built_at'`).Scan(&st.BuiltAt)
+	var rev string
+	if db.QueryRow(`SELECT value FROM meta WHERE key = 'dataset_revision'`).Scan(&rev) == nil {
+		st.Revision, _ = strconv.Atoi(rev)
+	} else if mf, err := m.Manifest(); err == nil && mf != nil && mf.Year == st.Year {
+		st.Revision = mf.revision()
+	}
+	var nations, layers string
+	db.QueryRow(`SELECT value FROM meta WHERE key = 'columns'`).Scan(&st.ColumnSet)
+	db.QueryRow(`SELECT value FROM meta WHERE key = 'nations'`).Scan(&nations)
+	db.QueryRow(`SELECT value FROM meta WHERE key = 'layers'`).Scan(&layers)
+	if st.ColumnSet == "" {
+		st.ColumnSet = ColumnsFull // built before column sets existed
+	}
+	if st.Columns, err = ColumnSet(st.ColumnSet); err != nil {
+		return err
+	}
+	if nations != "" {
+		st.Nations = strings.Split(nations, ",")
+	}
+	if layers != "" {
+		st.Layers = strings.Split(layers, ",")
+	}
+	return nil
+}
+
+// LatestKnownYear returns the newest dataset year in MobileDataURLs.
+func LatestKnownYear() string {
+	latest := ""
+	for y := range MobileDataURLs {
+		if ValidYear(y) && y > latest {
+			latest = y
+		}
+	}
+	return latest
+}
+
+// Ready checks that the database opens and holds coverage rows. Unlike
+// Status it reads a single row, so it is cheap enough for a readiness probe.
+func (m *Manager) Ready() error {
+	db, release, err := m.acquire()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	var one int
+	err = db.QueryRow(`SELECT 1 FROM mobile LIMIT 1`).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrDatasetEmpty
+	}
+	return err
+}
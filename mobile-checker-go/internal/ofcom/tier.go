@@ -0,0 +1,63 @@
+package ofcom
+
+import "encoding/xml"
+
+// Tier grades how much of a postcode an operator/technology covers, so
+// that 51% and 99% coverage, both "available", can be told apart.
+type Tier string
+
+const (
+	// TierNone means no coverage, or none published.
+	TierNone Tier = "None"
+	// TierPartial means some coverage, but below the coverage threshold.
+	TierPartial Tier = "Partial"
+	// TierGood means coverage at or above the threshold.
+	TierGood Tier = "Good"
+	// TierExcellent means coverage at or above ExcellentThreshold.
+	TierExcellent Tier = "Excellent"
+)
+
+// ExcellentThreshold is the fraction of a postcode that must be covered
+// for TierExcellent.
+const ExcellentThreshold = 0.95
+
+// TierOf returns the tier of coverage fraction f against a coverage
+// threshold; 0 means CoverageThreshold. A threshold above
+// ExcellentThreshold leaves no room for TierGood.
+func TierOf(f, threshold float64) Tier {
+	if threshold <= 0 {
+		threshold = CoverageThreshold
+	}
+	switch {
+	case f >= ExcellentThreshold && f >= threshold:
+		return TierExcellent
+	case f >= threshold:
+		return TierGood
+	case f > 0:
+		return TierPartial
+	default:
+		return TierNone
+	}
+}
+
+// Tiers maps technologies ("voice", "4g", ...) to their tier.
+type Tiers map[string]Tier
+
+// MarshalXML writes t as <Tier technology="4g">Good</Tier> elements, in a
+// fixed order, since technology names are not valid element names.
+func (t Tiers) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, tech := range []string{"voice", "4g", "5g", "3g", "2g"} {
+		tier, ok := t[tech]
+		if !ok {
+			continue
+		}
+		el := xml.StartElement{Name: xml.Name{Local: "Tier"}, Attr: []xml.Attr{{Name: xml.Name{Local: "technology"}, Value: tech}}}
+		if err := e.EncodeElement(tier, el); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
@@ -0,0 +1,161 @@
+package ofcom
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// TrendTarget is the coverage percentage a trend forecasts reaching.
+const TrendTarget = 50.0
+
+// trendHorizon is the furthest ahead, in years after the latest edition, a
+// trend forecasts; straight lines drawn further than that say little.
+const trendHorizon = 10
+
+// TrendNote marks every trend as indicative.
+const TrendNote = "Indicative only: a straight line through past Ofcom editions, not an operator roll-out plan."
+
+// Trend is a postcode's coverage across installed dataset years, with a
+// naive straight-line extrapolation of each operator's rollout.
+type Trend struct {
+	// Years are the dataset years holding a row for the postcode, oldest
+	// first.
+	Years     []string        `json:"years" xml:"years>year"`
+	Indoor    bool            `json:"indoor" xml:"indoor"`
+	Note      string          `json:"note" xml:"note"`
+	Operators []OperatorTrend `json:"operators" xml:"operators>operator"`
+}
+
+// OperatorTrend holds one operator's trend in each technology.
+type OperatorTrend struct {
+	Name         string      `json:"name" xml:"name"`
+	Technologies []TechTrend `json:"technologies" xml:"technologies>technology"`
+}
+
+// TechTrend is the trend in one technology's coverage. Percentages are
+// 0–100 and a missing value counts as 0.
+type TechTrend struct {
+	Technology string `json:"technology" xml:"technology"`
+	// Pct is the coverage in each of Trend.Years.
+	Pct []float64 `json:"pct" xml:"pct"`
+	// ChangePP is the change from the first year to the last in
+	// percentage points.
+	ChangePP float64 `json:"change_pp" xml:"change_pp"`
+	// PerYearPP is the least-squares slope in percentage points a year.
+	PerYearPP float64 `json:"per_year_pp" xml:"per_year_pp"`
+	// ReachesTargetBy is the year coverage would reach TrendTarget at
+	// PerYearPP, when it is below it now, rising and would get there
+	// within ten years; otherwise 0.
+	ReachesTargetBy int `json:"reaches_target_by,omitempty" xml:"reaches_target_by,omitempty"`
+	// Forecast describes ReachesTargetBy, e.g. "Three 5G likely ≥50% by
+	// 2025 at current rate".
+	Forecast string `json:"forecast,omitempty" xml:"forecast,omitempty"`
+}
+
+// TrendRows builds a trend from a postcode's rows in several dataset years,
+// oldest first, for the operators and indoor/outdoor mode selected by
+// opts. Years whose row is nil are left out; it returns nil when fewer
+// than two remain.
+func TrendRows(years []string, rows []map[string]string, opts InterpretOptions) *Trend {
+	var ys []string
+	var xs []float64
+	var used []map[string]string
+	for i, year := range years {
+		n, err := strconv.Atoi(year)
+		if err != nil || rows[i] == nil {
+			continue
+		}
+		ys = append(ys, year)
+		xs = append(xs, float64(n))
+		used = append(used, rows[i])
+	}
+	if len(used) < 2 {
+		return nil
+	}
+	selected := Operators
+	if len(opts.Operators) > 0 {
+		selected = opts.Operators
+	}
+	suffix := ""
+	if opts.Indoor {
+		suffix = "_indoor"
+	}
+	round := func(f float64) float64 { return math.Round(f*10) / 10 }
+	last := int(xs[len(xs)-1])
+
+	t := &Trend{Years: ys, Indoor: opts.Indoor, Note: TrendNote, Operators: make([]OperatorTrend, 0, len(selected))}
+	for _, op := range selected {
+		ot := OperatorTrend{Name: operatorNames[op]}
+		for _, tech := range []string{"voice", "4g", "5g"} {
+			col := op + "_" + tech + suffix
+			pct := make([]float64, len(used))
+			for i, row := range used {
+				f, err := strconv.ParseFloat(row[col], 64)
+				if err != nil {
+					f = 0
+				}
+				pct[i] = f * 100
+			}
+			slope := fitSlope(xs, pct)
+			tt := TechTrend{
+				Technology: tech,
+				Pct:        make([]float64, len(pct)),
+				ChangePP:   round(pct[len(pct)-1] - pct[0]),
+				PerYearPP:  round(slope),
+			}
+			for i, p := range pct {
+				tt.Pct[i] = round(p)
+			}
+			if now := pct[len(pct)-1]; now < TrendTarget && slope > 0 {
+				by := last + int(math.Ceil((TrendTarget-now)/slope))
+				if by-last <= trendHorizon {
+					tt.ReachesTargetBy = by
+					label := strings.ToUpper(tech)
+					if tech == "voice" {
+						label = tech
+					}
+					tt.Forecast = fmt.Sprintf("%s %s likely ≥%.0f%% by %d at current rate", ot.Name, label, TrendTarget, by)
+				}
+			}
+			ot.Technologies = append(ot.Technologies, tt)
+		}
+		t.Operators = append(t.Operators, ot)
+	}
+	return t
+}
+
+// Forecasts lists the forecast of every operator and technology that has
+// one.
+func (t *Trend) Forecasts() []string {
+	var out []string
+	for _, op := range t.Operators {
+		for _, tech := range op.Technologies {
+			if tech.Forecast != "" {
+				out = append(out, tech.Forecast)
+			}
+		}
+	}
+	return out
+}
+
+// fitSlope is the least-squares slope of ys against xs.
+func fitSlope(xs, ys []float64) float64 {
+	var mx, my float64
+	for i := range xs {
+		mx += xs[i]
+		my += ys[i]
+	}
+	mx /= float64(len(xs))
+	my /= float64(len(ys))
+	var num, den float64
+	for i := range xs {
+		num += (xs[i] - mx) * (ys[i] - my)
+		den += (xs[i] - mx) * (xs[i] - mx)
+	}
+	if den == 0 {
+		return 0
+	}
+	return num / den
+}
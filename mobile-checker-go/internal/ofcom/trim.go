@@ -0,0 +1,178 @@
+package ofcom
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Column sets for SetupOptions.Columns.
+const (
+	// ColumnsFull stores every coverage column the edition publishes.
+	ColumnsFull = "full"
+	// ColumnsMinimal stores only outdoor voice, 4G and 5G coverage and
+	// any_coverage: what a default check reads. Indoor and 2G/3G figures
+	// are reported as N/A.
+	ColumnsMinimal = "minimal"
+)
+
+// Nations lists the UK nations accepted by SetupOptions.Nations.
+var Nations = []string{"england", "northern-ireland", "scotland", "wales"}
+
+// ErrNationNotStored is returned for a postcode in a nation left out of a
+// dataset built with SetupOptions.Nations.
+var ErrNationNotStored = errors.New("nation not stored in this dataset — run 'setup --force --nations' listing it")
+
+// minimalMeasures are the measures stored by ColumnsMinimal.
+var minimalMeasures = []string{"voice", "4g", "5g"}
+
+// ColumnSet returns the coverage columns stored by a column set: all of
+// CanonicalColumns for ColumnsFull (or ""), fewer for ColumnsMinimal.
+func ColumnSet(name string) ([]string, error) {
+	switch name {
+	case "", ColumnsFull:
+		return CanonicalColumns(), nil
+	case ColumnsMinimal:
+		return append(measureColumns(minimalMeasures), "any_coverage"), nil
+	}
+	return nil, fmt.Errorf("unknown column set %q (want full or minimal)", name)
+}
+
+// ParseNations resolves a comma-separated list such as "england,wales" to
+// names from Nations. "ni" and "northern ireland" are accepted for
+// Northern Ireland. An empty list yields nil, meaning all of the UK.
+func ParseNations(list string) ([]string, error) {
+	var out []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.Join(strings.Fields(name), "-"))
+		if name == "" {
+			continue
+		}
+		if name == "ni" {
+			name = "northern-ireland"
+		}
+		if !slices.Contains(Nations, name) {
+			return nil, fmt.Errorf("unknown nation %q (want england, northern-ireland, scotland or wales)", name)
+		}
+		if !slices.Contains(out, name) {
+			out = append(out, name)
+		}
+	}
+	return out, nil
+}
+
+// nationAreas maps postcode areas outside England to their nation.
+var nationAreas = map[string]string{
+	"AB": "scotland", "DD": "scotland", "DG": "scotland", "EH": "scotland",
+	"FK": "scotland", "G": "scotland", "HS": "scotland", "IV": "scotland",
+	"KA": "scotland", "KW": "scotland", "KY": "scotland", "ML": "scotland",
+	"PA": "scotland", "PH": "scotland", "TD": "scotland", "ZE": "scotland",
+	"CF": "wales", "LD": "wales", "LL": "wales", "NP": "wales", "SA": "wales",
+	"BT": "northern-ireland",
+	// Crown dependencies are not part of the UK.
+	"GY": "", "JE": "", "IM": "",
+}
+
+// nationDistricts overrides nationAreas for districts across a border,
+// assigning each to the nation most of its postcodes are in.
+var nationDistricts = map[string]string{
+	"TD15": "england",
+	"CH5":  "wales", "CH6": "wales", "CH7": "wales", "CH8": "wales",
+	"SY15": "wales", "SY16": "wales", "SY17": "wales", "SY18": "wales", "SY19": "wales",
+	"SY20": "wales", "SY21": "wales", "SY22": "wales", "SY23": "wales", "SY24": "wales", "SY25": "wales",
+}
+
+// NationOf returns the UK nation of a normalised postcode from its area
+// and district, or "" for the Channel Islands and Isle of Man. A few
+// postcodes in districts straddling a border are assigned to the
+// neighbouring nation.
+func NationOf(pc string) string {
+	if len(pc) < 5 {
+		return ""
+	}
+	district := pc[:len(pc)-3]
+	if n, ok := nationDistricts[district]; ok {
+		return n
+	}
+	area := district // the leading letters, e.g. "SW" of "SW1A"
+	if i := strings.IndexAny(district, "0123456789"); i >= 0 {
+		area = district[:i]
+	}
+	if n, ok := nationAreas[area]; ok {
+		return n
+	}
+	return "england"
+}
+
+// ExcludedNation returns the nation of pc, as NationOf, and true when the
+// installed dataset was built with SetupOptions.Nations that leave it out.
+// It returns false for a dataset of the whole UK or one that cannot be read.
+func (m *Manager) ExcludedNation(pc string) (string, bool) {
+	meta, err := m.Meta()
+	if err != nil || meta["nations"] == "" {
+		return "", false
+	}
+	nation := NationOf(pc)
+	return nation, !slices.Contains(strings.Split(meta["nations"], ","), nation)
+}
+
+// keepsPostcode reports whether a build with these options stores pc.
+func (o SetupOptions) keepsPostcode(pc string) bool {
+	return len(o.Nations) == 0 || slices.Contains(o.Nations, NationOf(pc))
+}
+
+// checkTrim validates the trimming options.
+func (o SetupOptions) checkTrim() error {
+	if _, err := ColumnSet(o.Columns); err != nil {
+		return err
+	}
+	for _, n := range o.Nations {
+		if !slices.Contains(Nations, n) {
+			return fmt.Errorf("unknown nation %q", n)
+		}
+	}
+	return nil
+}
+
+// inheritTrim copies the trimming of the database at path into opts when
+// opts sets none, so rebuilds and updates keep a trimmed dataset trimmed.
+// Pass Columns ColumnsFull to rebuild everything.
+func (m *Manager) inheritTrim(path string, opts *SetupOptions) {
+	if opts.Columns != "" || len(opts.Nations) > 0 {
+		return
+	}
+	meta, err := m.metaAt(path)
+	if err != nil {
+		return
+	}
+	opts.Columns = meta["columns"]
+	if meta["nations"] != "" {
+		opts.Nations = strings.Split(meta["nations"], ",")
+	}
+}
+
+// metaAt reads the meta table of the database at path.
+func (m *Manager) metaAt(path string) (map[string]string, error) {
+	db, err := m.open(path, true)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	meta := make(map[string]string)
+	rows, err := db.Query(`SELECT key, value FROM meta`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var k string
+		var v sql.NullString
+		if err := rows.Scan(&k, &v); err != nil {
+			return nil, err
+		}
+		meta[k] = v.String
+	}
+	return meta, rows.Err()
+}
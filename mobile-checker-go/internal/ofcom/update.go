@@ -0,0 +1,158 @@
+package ofcom
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// UpdateStatus compares the installed dataset with the newest edition on
+// the Ofcom site.
+type UpdateStatus struct {
+	InstalledYear     string             `json:"installed_year"`
+	InstalledRevision int                `json:"installed_revision,omitempty"`
+	Latest            *DiscoveredEdition `json:"latest"`
+	Available         bool               `json:"available"`
+}
+
+// CheckForUpdate reports whether a newer edition or revision than the
+// installed dataset is published at indexURL ("" for the Ofcom site).
+// Without a download manifest only the year can be compared.
+func (m *Manager) CheckForUpdate(indexURL string) (*UpdateStatus, error) {
+	latest, err := Discover(indexURL, LatestYear)
+	if err != nil {
+		return nil, err
+	}
+	st := &UpdateStatus{Latest: latest}
+
+	mf, err := m.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	if mf != nil {
+		st.InstalledYear = mf.Year
+		st.InstalledRevision = mf.revision()
+	} else if meta, err := m.Meta(); err == nil {
+		st.InstalledYear = meta["dataset_year"]
+	}
+
+	switch {
+	case st.InstalledYear == "":
+		st.Available = true
+	case latest.Year != st.InstalledYear:
+		st.Available = latest.Year > st.InstalledYear
+	default:
+		st.Available = mf != nil && latest.Revision > st.InstalledRevision
+	}
+	return st, nil
+}
+
+// Update installs the newest edition if CheckForUpdate finds one. The
+// edition is downloaded and built in its own directory under
+// DataDir/editions, geographic data is carried over from the current
+// database, and the result is renamed over DBPath; the staging directory is
+// removed whether or not the update succeeds. Readers share one handle,
+// which the next query after the rename swaps for the new file, so they
+// switch to the new data without downtime; an index held by LoadIndex is
+// not rebuilt until Reload and LoadIndex are called again.
+func (m *Manager) Update(opts SetupOptions) (*UpdateStatus, error) {
+	st, err := m.CheckForUpdate(opts.IndexURL)
+	if err != nil || !st.Available {
+		return st, err
+	}
+	err = m.Unsealed(func(w *Manager) error { return w.update(st, opts) })
+	return st, err
+}
+
+func (m *Manager) update(st *UpdateStatus, opts SetupOptions) error {
+	latest := st.Latest
+	m.Logger.Info("installing Ofcom dataset update", "year", latest.Year, "revision", latest.Revision,
+		"installed_year", st.InstalledYear, "installed_revision", st.InstalledRevision)
+
+	dir := filepath.Join(m.DataDir, "editions", fmt.Sprintf("%s-r%02d", latest.Year, latest.Revision))
+	staging := NewManager(dir, WithLogger(m.Logger))
+	staging.Driver = m.Driver
+	// Whether installed or abandoned, nothing in the staging directory is
+	// needed afterwards; editions itself goes once no update is using it.
+	defer func() {
+		staging.Close()
+		os.RemoveAll(dir)
+		os.Remove(filepath.Dir(dir))
+	}()
+	opts.URL, opts.Force = latest.URL, true
+	m.inheritTrim(m.DBPath, &opts)
+	if err := staging.Setup(latest.Year, opts); err != nil {
+		return err
+	}
+	if err := staging.copyGeo(m.DBPath); err != nil {
+		return fmt.Errorf("failed to carry over geographic data: %w", err)
+	}
+	if err := staging.BuildRanks(); err != nil {
+		return err
+	}
+
+	if err := m.archiveCurrent(latest.Year); err != nil {
+		return fmt.Errorf("failed to keep previous dataset: %w", err)
+	}
+	if err := os.Rename(staging.DBPath, m.DBPath); err != nil {
+		return err
+	}
+	if err := os.Rename(staging.manifestPath(), m.manifestPath()); err != nil {
+		return err
+	}
+	os.Remove(m.bundleStampPath())
+	m.Logger.Info("dataset updated", "year", latest.Year, "revision", latest.Revision, "db", m.DBPath)
+	return nil
+}
+
+// WatchUpdates calls Update every interval until ctx is cancelled.
+func (m *Manager) WatchUpdates(ctx context.Context, interval time.Duration, opts SetupOptions) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if st, err := m.Update(opts); err != nil {
+			m.Logger.Error("dataset update failed", "err", err)
+		} else if !st.Available {
+			m.Logger.Debug("dataset is up to date", "year", st.InstalledYear, "revision", st.InstalledRevision)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// copyGeo copies geographic rows for postcodes in this database from the
+// database at path, if it exists and has any.
+func (m *Manager) copyGeo(path string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	db, err := m.openMigrated()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	ctx := context.Background()
+	if _, err := conn.ExecContext(ctx, `ATTACH DATABASE ? AS old`, path); err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, `DETACH DATABASE old`)
+
+	var n int
+	if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM old.sqlite_master WHERE type = 'table' AND name = 'geo'`).Scan(&n); err != nil || n == 0 {
+		return err
+	}
+	_, err = conn.ExecContext(ctx, `INSERT OR IGNORE INTO geo
+		SELECT g.* FROM old.geo g JOIN mobile m ON m.postcode = g.postcode`)
+	return err
+}
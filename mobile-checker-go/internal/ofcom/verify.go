@@ -0,0 +1,287 @@
+package ofcom
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+// DefaultRows is the range of rows expected of a UK-wide edition without
+// its own Edition.Rows: one per live postcode, give or take.
+var DefaultRows = [2]int{1_500_000, 2_000_000}
+
+// Fixture is a postcode whose coverage is known, for Verify to compare
+// against the database.
+type Fixture struct {
+	Postcode string `json:"postcode"`
+	// Expect maps coverage columns, e.g. "ee_4g", to their known value,
+	// a fraction 0–1.
+	Expect map[string]float64 `json:"expect"`
+	// Tolerance is how far a stored value may be from Expect; 0 means
+	// 0.01.
+	Tolerance float64 `json:"tolerance,omitempty"`
+}
+
+// DefaultFixtures are central London postcodes every edition covers with
+// 4G from all four operators. They catch a build whose rows are present
+// but whose coverage columns were mapped wrongly or left empty.
+var DefaultFixtures = []Fixture{
+	{Postcode: "SW1A1AA", Expect: allFourG(), Tolerance: 1 - CoverageThreshold},
+	{Postcode: "SW1A2AA", Expect: allFourG(), Tolerance: 1 - CoverageThreshold},
+	{Postcode: "EC1A1BB", Expect: allFourG(), Tolerance: 1 - CoverageThreshold},
+}
+
+func allFourG() map[string]float64 {
+	m := make(map[string]float64, len(Operators))
+	for _, op := range Operators {
+		m[op+"_4g"] = 1
+	}
+	return m
+}
+
+// VerifyOptions controls Verify.
+type VerifyOptions struct {
+	// MinRows and MaxRows override the expected row range; 0 keeps the
+	// edition's (see Edition.Rows). A dataset limited to some nations, or
+	// exported as a subset, has no expected range of its own.
+	MinRows, MaxRows int
+	// Fixtures replace DefaultFixtures, which are not used for a subset.
+	// Fixtures in nations the dataset does not store are skipped.
+	Fixtures []Fixture
+	// Sample is the number of random rows checked for a well-formed
+	// postcode and coverage values between 0 and 1; 0 means 1000.
+	Sample int
+}
+
+// VerifyCheck is the outcome of one of Verify's checks.
+type VerifyCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail"`
+}
+
+// VerifyReport is the outcome of Verify.
+type VerifyReport struct {
+	Path   string        `json:"path"`
+	Year   string        `json:"year,omitempty"`
+	OK     bool          `json:"ok"`
+	Checks []VerifyCheck `json:"checks"`
+}
+
+func (r *VerifyReport) add(name string, ok bool, format string, args ...any) {
+	r.Checks = append(r.Checks, VerifyCheck{Name: name, OK: ok, Detail: fmt.Sprintf(format, args...)})
+	r.OK = r.OK && ok
+}
+
+// keyColumns are the columns every edition of year populates: outdoor
+// voice and 4G for each operator, and 5G from 2021, the first edition to
+// report it.
+func keyColumns(year string) []string {
+	measures := []string{"voice", "4g"}
+	if y, err := strconv.Atoi(year); err != nil || y >= 2021 {
+		measures = append(measures, "5g")
+	}
+	return measureColumns(measures)
+}
+
+// Verify checks that the database is whole: SQLite's integrity check, its
+// row count against the range expected of the edition, that the key
+// columns of the edition exist and hold values, that fixtures match, and
+// that a random sample of rows is well-formed. A half-built database
+// otherwise answers "no coverage" without complaint. Problems are reported
+// in the VerifyReport; an error means it could not be checked at all.
+func (m *Manager) Verify(opts VerifyOptions) (*VerifyReport, error) {
+	if _, err := os.Stat(m.DBPath); os.IsNotExist(err) {
+		return nil, ErrDatabaseNotFound
+	}
+	st := &DatasetStatus{Path: m.DBPath}
+	rep := &VerifyReport{Path: m.DBPath, OK: true}
+	db, err := m.open(m.DBPath, true)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var integrity []string
+	rows, err := db.Query(`PRAGMA integrity_check(20)`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		integrity = append(integrity, line)
+	}
+	rows.Close()
+	if len(integrity) == 1 && integrity[0] == "ok" {
+		rep.add("integrity", true, "PRAGMA integrity_check: ok")
+	} else {
+		rep.add("integrity", false, "PRAGMA integrity_check: %s", strings.Join(integrity, "; "))
+		return rep, nil // the rest would read a corrupt file
+	}
+
+	if err := m.inspect(st); err != nil {
+		rep.add("schema", false, "%v", err)
+		return rep, nil
+	}
+	rep.Year = st.Year
+	if st.SchemaVersion < SchemaVersion {
+		rep.add("schema", false, "schema v%d, current v%d: run 'setup' to migrate", st.SchemaVersion, SchemaVersion)
+	} else {
+		rep.add("schema", true, "schema v%d", st.SchemaVersion)
+	}
+
+	// Rows.
+	subset := ""
+	db.QueryRow(`SELECT value FROM meta WHERE key = 'subset'`).Scan(&subset)
+	want := EditionFor(st.Year).Rows
+	if want == [2]int{} {
+		want = DefaultRows
+	}
+	scope := "a UK-wide " + st.Year + " edition"
+	if len(st.Nations) > 0 || subset != "" {
+		want, scope = [2]int{1, 0}, "a dataset limited to "+strings.Join(append(st.Nations, subset), " ")
+	}
+	if opts.MinRows > 0 {
+		want[0], scope = opts.MinRows, "--min-rows"
+	}
+	if opts.MaxRows > 0 {
+		want[1], scope = opts.MaxRows, "--max-rows"
+	}
+	switch {
+	case st.Rows < want[0]:
+		rep.add("rows", false, "%d rows, fewer than the %d expected of %s: the build may have stopped early, run 'setup --force'", st.Rows, want[0], scope)
+	case want[1] > 0 && st.Rows > want[1]:
+		rep.add("rows", false, "%d rows, more than the %d expected of %s", st.Rows, want[1], scope)
+	case want[1] > 0:
+		rep.add("rows", true, "%d rows, within %d–%d", st.Rows, want[0], want[1])
+	default:
+		rep.add("rows", true, "%d rows", st.Rows)
+	}
+
+	// Key columns: present, and populated in at least one row.
+	have := map[string]bool{}
+	cols, err := db.Query(`SELECT name FROM pragma_table_info('mobile')`)
+	if err != nil {
+		return nil, err
+	}
+	for cols.Next() {
+		var name string
+		if err := cols.Scan(&name); err != nil {
+			cols.Close()
+			return nil, err
+		}
+		have[name] = true
+	}
+	cols.Close()
+	var missing, empty []string
+	for _, c := range keyColumns(st.Year) {
+		if !have[c] {
+			missing = append(missing, c)
+			continue
+		}
+		var populated int
+		if err := db.QueryRow(fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM mobile WHERE %s IS NOT NULL)`, c)).Scan(&populated); err != nil {
+			return nil, err
+		}
+		if populated == 0 {
+			empty = append(empty, c)
+		}
+	}
+	switch {
+	case len(missing) > 0:
+		rep.add("columns", false, "missing %s", strings.Join(missing, ", "))
+	case len(empty) > 0:
+		rep.add("columns", false, "no values in %s: the edition's headers may not have been recognised", strings.Join(empty, ", "))
+	default:
+		rep.add("columns", true, "%d key columns of the %s edition hold values", len(keyColumns(st.Year)), st.Year)
+	}
+
+	// Fixtures.
+	fixtures := opts.Fixtures
+	if fixtures == nil && subset == "" {
+		fixtures = DefaultFixtures
+	}
+	var checked int
+	var wrong []string
+	for _, f := range fixtures {
+		pc := normalisePostcode(f.Postcode)
+		if len(st.Nations) > 0 && !slices.Contains(st.Nations, NationOf(pc)) {
+			continue
+		}
+		checked++
+		var row map[string]string
+		if err := scanRows(db, `SELECT * FROM mobile WHERE postcode = ?`, []any{pc}, func(r map[string]string) { row = r }); err != nil {
+			return nil, err
+		}
+		if row == nil {
+			wrong = append(wrong, pc+" is missing")
+			continue
+		}
+		tol := f.Tolerance
+		if tol == 0 {
+			tol = 0.01
+		}
+		for _, c := range sortedKeys(f.Expect) {
+			got, err := strconv.ParseFloat(row[c], 64)
+			if err != nil || math.Abs(got-f.Expect[c]) > tol {
+				wrong = append(wrong, fmt.Sprintf("%s %s is %q, want %g", pc, c, row[c], f.Expect[c]))
+			}
+		}
+	}
+	if len(wrong) > 0 {
+		rep.add("fixtures", false, "%s", strings.Join(wrong, "; "))
+	} else {
+		rep.add("fixtures", true, "%d of %d fixtures checked, all match", checked, len(fixtures))
+	}
+
+	// Random sample.
+	n := opts.Sample
+	if n <= 0 {
+		n = 1000
+	}
+	var sampled int
+	var bad []string
+	err = scanRows(db, `SELECT * FROM mobile ORDER BY RANDOM() LIMIT ?`, []any{n}, func(row map[string]string) {
+		sampled++
+		if err := postcode.Validate(row["postcode"]); err != nil {
+			bad = append(bad, fmt.Sprintf("%q: %v", row["postcode"], err))
+			return
+		}
+		for _, c := range CanonicalColumns() {
+			if v, err := strconv.ParseFloat(row[c], 64); row[c] != "" && (err != nil || v < 0 || v > 1) {
+				bad = append(bad, fmt.Sprintf("%s %s is %q, outside 0–1", row["postcode"], c, row[c]))
+				return
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if n := len(bad); n > 0 {
+		if n > 5 {
+			bad = append(bad[:5], fmt.Sprintf("and %d more", n-5))
+		}
+		rep.add("sample", false, "%d of %d random rows malformed: %s", n, sampled, strings.Join(bad, "; "))
+	} else {
+		rep.add("sample", true, "%d random rows well-formed", sampled)
+	}
+	return rep, nil
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,253 @@
+package ofcom
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrYearNotInstalled is returned by ForYear for a dataset year with no
+// local database.
+var ErrYearNotInstalled = errors.New("dataset year not installed — run 'setup --year <year>'")
+
+// ErrVersionNotInstalled is returned by ForVersion for a build that is no
+// longer installed: its year was removed, or rebuilt or reissued more than
+// once since.
+var ErrVersionNotInstalled = errors.New("dataset version not installed — re-check every postcode")
+
+// yearDir is the data directory holding the database for a dataset year
+// other than the current one.
+func (m *Manager) yearDir(year string) string {
+	return filepath.Join(m.DataDir, "years", year)
+}
+
+// supersededDir is the data directory holding the build that the last
+// rebuild or reissue of the same dataset year replaced.
+func (m *Manager) supersededDir() string {
+	return filepath.Join(m.DataDir, "superseded")
+}
+
+// archivedPaths returns the databases kept beside DBPath: other dataset
+// years and superseded builds.
+func (m *Manager) archivedPaths() []string {
+	var paths []string
+	for _, pattern := range []string{
+		filepath.Join(m.supersededDir(), "mobile.db"),
+		filepath.Join(m.DataDir, "years", "*", "mobile.db"),
+		filepath.Join(m.DataDir, "years", "*", "superseded", "mobile.db"),
+	} {
+		matches, _ := filepath.Glob(pattern)
+		paths = append(paths, matches...)
+	}
+	return paths
+}
+
+// installedYear returns the dataset year of the database at path, or "" if
+// there is none.
+func (m *Manager) installedYear(path string) string {
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	db, err := m.open(path, true)
+	if err != nil {
+		return ""
+	}
+	defer db.Close()
+	var year string
+	db.QueryRow(`SELECT value FROM meta WHERE key = 'dataset_year'`).Scan(&year)
+	return year
+}
+
+// archiveCurrent keeps the database at DBPath under years/<year> before it
+// is replaced by a database for newYear, so earlier years stay available
+// to ForYear. A database for the same year is kept as the superseded
+// build instead, replacing the one kept before, so ForVersion can still
+// compare against it.
+func (m *Manager) archiveCurrent(newYear string) error {
+	year := m.installedYear(m.DBPath)
+	if year == "" {
+		return nil
+	}
+	dir, msg := m.yearDir(year), "kept previous dataset year"
+	if year == newYear {
+		dir, msg = m.supersededDir(), "kept superseded build"
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if year != newYear {
+		// The superseded build goes with its year.
+		moved := filepath.Join(dir, "superseded")
+		os.RemoveAll(moved)
+		if err := os.Rename(m.supersededDir(), moved); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	dst := filepath.Join(dir, "mobile.db")
+	os.Remove(dst)
+	if err := os.Link(m.DBPath, dst); err == nil {
+		m.Logger.Info(msg, "year", year, "path", dst)
+		return nil
+	}
+
+	// Hard links can fail across file systems; fall back to a copy.
+	db, err := m.open(m.DBPath, true)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if _, err := db.Exec(`VACUUM INTO ?`, dst); err != nil {
+		return err
+	}
+	m.Logger.Info(msg, "year", year, "path", dst)
+	return nil
+}
+
+// ForYear returns a Manager reading the database for a dataset year: m
+// itself if year is the current dataset, otherwise the copy kept when a
+// later year replaced it or installed alongside it by Setup. Managers for
+// other years are kept open for reuse until m is closed.
+func (m *Manager) ForYear(year string) (*Manager, error) {
+	if !ValidYear(year) {
+		// Also keeps user-supplied years from naming paths outside DataDir.
+		return nil, ErrYearNotInstalled
+	}
+	if meta, err := m.Meta(); err == nil && meta["dataset_year"] == year {
+		return m, nil
+	}
+	m.yearsMu.Lock()
+	defer m.yearsMu.Unlock()
+	if ym, ok := m.years[year]; ok {
+		return ym, nil
+	}
+	ym := m.yearManager(year)
+	if _, err := os.Stat(ym.DBPath); err != nil {
+		return nil, ErrYearNotInstalled
+	}
+	if m.years == nil {
+		m.years = make(map[string]*Manager)
+	}
+	m.years[year] = ym
+	return ym, nil
+}
+
+// ForVersion returns a Manager reading the build identified by version, a
+// Release.Version, as ForYear does for its year. The build of each year
+// installed now can be read, and the one its last rebuild or reissue
+// replaced.
+func (m *Manager) ForVersion(version string) (*Manager, error) {
+	year, _, ok := strings.Cut(version, "@")
+	if !ok {
+		return nil, ErrVersionNotInstalled
+	}
+	ym, err := m.ForYear(year)
+	if errors.Is(err, ErrYearNotInstalled) {
+		return nil, ErrVersionNotInstalled
+	} else if err != nil {
+		return nil, err
+	}
+	rel, err := ym.Release()
+	if err != nil {
+		return nil, err
+	}
+	if rel.Version == version {
+		return ym, nil
+	}
+	return ym.superseded(version)
+}
+
+// supersededKey keeps the Manager for the superseded build among those for
+// other years; it is not a valid year.
+const supersededKey = "superseded"
+
+// superseded returns a Manager reading the superseded build if it is
+// version. It is kept open for reuse until m is closed.
+func (m *Manager) superseded(version string) (*Manager, error) {
+	m.yearsMu.Lock()
+	sm, ok := m.years[supersededKey]
+	if !ok {
+		sm = m.childManager(m.supersededDir())
+		if _, err := os.Stat(sm.DBPath); err != nil {
+			m.yearsMu.Unlock()
+			return nil, ErrVersionNotInstalled
+		}
+		if m.years == nil {
+			m.years = make(map[string]*Manager)
+		}
+		m.years[supersededKey] = sm
+	}
+	m.yearsMu.Unlock()
+	rel, err := sm.Release()
+	if errors.Is(err, ErrDatabaseNotFound) {
+		return nil, ErrVersionNotInstalled
+	} else if err != nil {
+		return nil, err
+	}
+	if rel.Version != version {
+		return nil, ErrVersionNotInstalled
+	}
+	return sm, nil
+}
+
+// ValidYear reports whether year looks like a dataset year, e.g. 2023.
+func ValidYear(year string) bool {
+	if len(year) != 4 {
+		return false
+	}
+	for _, r := range year {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// QueryPostcodeYear is QueryPostcode against the database for a dataset
+// year (see ForYear); an empty year means the current dataset.
+func (m *Manager) QueryPostcodeYear(postcode, year string) (map[string]string, error) {
+	if year == "" {
+		return m.QueryPostcode(postcode)
+	}
+	ym, err := m.ForYear(year)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", year, err)
+	}
+	return ym.QueryPostcode(postcode)
+}
+
+func (m *Manager) yearManager(year string) *Manager {
+	return m.childManager(m.yearDir(year))
+}
+
+// childManager returns a Manager for a database kept under dir, read and
+// written as m's is.
+func (m *Manager) childManager(dir string) *Manager {
+	cm := NewManager(dir, WithLogger(m.Logger))
+	cm.Driver = m.Driver
+	cm.sealed = m.sealed
+	return cm
+}
+
+// InstalledYears returns the dataset years available locally, oldest
+// first.
+func (m *Manager) InstalledYears() []string {
+	seen := make(map[string]bool)
+	if y := m.installedYear(m.DBPath); y != "" {
+		seen[y] = true
+	}
+	dirs, _ := filepath.Glob(filepath.Join(m.DataDir, "years", "*", "mobile.db"))
+	for _, path := range dirs {
+		if y := m.installedYear(path); y != "" {
+			seen[y] = true
+		}
+	}
+	years := make([]string, 0, len(seen))
+	for y := range seen {
+		years = append(years, y)
+	}
+	sort.Strings(years)
+	return years
+}
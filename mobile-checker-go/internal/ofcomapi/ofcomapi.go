@@ -0,0 +1,222 @@
+// Package ofcomapi checks coverage with Ofcom's Connected Nations API
+// (api.ofcom.org.uk) instead of a local copy of the dataset. The API needs a
+// free subscription key and answers one postcode per request, so it suits
+// occasional checks on machines without room for the database.
+package ofcomapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+)
+
+// DefaultBaseURL is the gateway serving Ofcom's APIs.
+const DefaultBaseURL = "https://api-proxy.ofcom.org.uk"
+
+// DefaultInterval spaces requests so bulk checks stay within a free
+// subscription's quota. Keys with a larger quota can lower it with
+// WithInterval.
+const DefaultInterval = 2 * time.Second
+
+// maxRetries is how many times a 429 answer is retried after waiting.
+const maxRetries = 3
+
+// ErrInvalidKey is returned when the API refuses the subscription key.
+var ErrInvalidKey = errors.New("Ofcom API key missing or invalid — get a free key at https://api.ofcom.org.uk")
+
+// Option configures a Source.
+type Option func(*Source)
+
+// WithBaseURL sets the API gateway, e.g. a test server.
+func WithBaseURL(u string) Option {
+	return func(s *Source) { s.baseURL = strings.TrimRight(u, "/") }
+}
+
+// WithInterval sets the least time between requests; 0 sends them as fast
+// as the API answers.
+func WithInterval(d time.Duration) Option {
+	return func(s *Source) { s.interval = d }
+}
+
+// WithHTTPClient sets the HTTP client, e.g. one with a proxy.
+func WithHTTPClient(c *http.Client) Option {
+	return func(s *Source) { s.http = c }
+}
+
+// Source is Ofcom's coverage API as a checker.CoverageSource. Use it as
+// the primary source with checker.WithPrimarySource, or beside the local
+// dataset with checker.WithSource.
+type Source struct {
+	key      string
+	baseURL  string
+	interval time.Duration
+	http     *http.Client
+
+	mu   sync.Mutex
+	next time.Time // earliest time the next request may be sent
+}
+
+// New returns a Source that authenticates with key.
+func New(key string, opts ...Option) *Source {
+	s := &Source{
+		key:      strings.TrimSpace(key),
+		baseURL:  DefaultBaseURL,
+		interval: DefaultInterval,
+		http:     &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+var _ checker.CoverageSource = (*Source)(nil)
+
+func (s *Source) Name() string { return "ofcom-api" }
+
+// Setup only checks that a key has been given: there is nothing to
+// download.
+func (s *Source) Setup(year string, opts ofcom.SetupOptions) error {
+	if s.key == "" {
+		return ErrInvalidKey
+	}
+	return nil
+}
+
+// Interpret reads a row from Query as the local dataset's rows are read.
+func (s *Source) Interpret(row map[string]string, opts checker.CheckOptions) ofcom.MobileSummary {
+	return ofcom.InterpretWith(row, ofcom.InterpretOptions{Indoor: opts.Indoor, AddIndoor: opts.AddIndoor, Operators: opts.Operators, Brands: opts.Brands, Weights: opts.Weights, Threshold: opts.Threshold})
+}
+
+// Query asks the API about a normalised postcode and maps its answer to a
+// row of canonical columns (see ofcom.CanonicalColumns). It returns a nil
+// row for a postcode the API does not know.
+func (s *Source) Query(pc string) (map[string]string, error) {
+	if s.key == "" {
+		return nil, ErrInvalidKey
+	}
+	for attempt := 0; ; attempt++ {
+		s.wait()
+		req, err := http.NewRequest(http.MethodGet, s.baseURL+"/mobile/coverage/"+url.PathEscape(pc), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Ocp-Apim-Subscription-Key", s.key)
+		req.Header.Set("Accept", "application/json")
+		resp, err := s.http.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("Ofcom API: %w", err)
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("Ofcom API: %w", err)
+		}
+		switch {
+		case resp.StatusCode == http.StatusOK:
+			var r response
+			if err := json.Unmarshal(body, &r); err != nil {
+				return nil, fmt.Errorf("Ofcom API: invalid response: %w", err)
+			}
+			return r.row(pc), nil
+		case resp.StatusCode == http.StatusNotFound:
+			return nil, nil
+		case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+			return nil, ErrInvalidKey
+		case resp.StatusCode == http.StatusTooManyRequests && attempt < maxRetries:
+			s.backOff(resp.Header.Get("Retry-After"))
+		default:
+			return nil, fmt.Errorf("Ofcom API: %s", resp.Status)
+		}
+	}
+}
+
+// wait blocks until the next request may be sent.
+func (s *Source) wait() {
+	s.mu.Lock()
+	now := time.Now()
+	at := s.next
+	if at.Before(now) {
+		at = now
+	}
+	s.next = at.Add(s.interval)
+	s.mu.Unlock()
+	time.Sleep(time.Until(at))
+}
+
+// backOff delays the next request by the Retry-After of a 429 answer, in
+// seconds, or by a minute when it gives none.
+func (s *Source) backOff(retryAfter string) {
+	d := time.Minute
+	if secs, err := strconv.Atoi(retryAfter); err == nil && secs >= 0 {
+		d = time.Duration(secs) * time.Second
+	}
+	s.mu.Lock()
+	if next := time.Now().Add(d); next.After(s.next) {
+		s.next = next
+	}
+	s.mu.Unlock()
+}
+
+// response is the API's answer for a postcode: one entry per premises,
+// each rating every operator's coverage 0–3.
+type response struct {
+	PostCode     string           `json:"PostCode"`
+	Availability []map[string]any `json:"Availability"`
+}
+
+// operatorCodes are the API's operator prefixes.
+var operatorCodes = map[string]string{"EE": "ee", "TF": "o2", "H3": "three", "VO": "vodafone"}
+
+// measureFields are the API's measures and the canonical measures they
+// map to. Data covers 4G where it is available, as the dataset's 4G
+// columns do; the API reports no 5G.
+var measureFields = map[string]string{
+	"VoiceOutdoor": "voice",
+	"VoiceIndoor":  "voice_indoor",
+	"DataOutdoor":  "4g",
+	"DataIndoor":   "4g_indoor",
+}
+
+// coveredRating is the least rating counted as covered: 2 (likely) or 3
+// (good), as against 0 (none) and 1 (limited).
+const coveredRating = 2
+
+// row turns the per-premises ratings into the fraction of premises covered
+// for each column, matching the dataset's values. Columns the API did not
+// rate are left out. It is nil when the API lists no premises.
+func (r response) row(pc string) map[string]string {
+	if len(r.Availability) == 0 {
+		return nil
+	}
+	row := map[string]string{"postcode": pc}
+	for code, op := range operatorCodes {
+		for field, measure := range measureFields {
+			var rated, covered int
+			for _, a := range r.Availability {
+				v, ok := a[code+field].(float64)
+				if !ok {
+					continue
+				}
+				rated++
+				if v >= coveredRating {
+					covered++
+				}
+			}
+			if rated > 0 {
+				row[op+"_"+measure] = strconv.FormatFloat(float64(covered)/float64(rated), 'f', -1, 64)
+			}
+		}
+	}
+	return row
+}
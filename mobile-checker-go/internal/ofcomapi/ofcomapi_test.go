@@ -0,0 +1,98 @@
+package ofcomapi_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcomapi"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+// sw1a1aa rates two premises: EE covers both outdoors but only one
+// indoors; Three covers neither.
+const sw1a1aa = `{"PostCode": "SW1A1AA", "Availability": [
+	{"UPRN": 1, "EEVoiceOutdoor": 3, "EEDataOutdoor": 3, "EEDataIndoor": 2, "H3DataOutdoor": 1, "TFDataOutdoor": 3, "VODataOutdoor": 2},
+	{"UPRN": 2, "EEVoiceOutdoor": 3, "EEDataOutdoor": 2, "EEDataIndoor": 0, "H3DataOutdoor": 0, "TFDataOutdoor": 3, "VODataOutdoor": 3}
+]}`
+
+// newAPI starts a fake API and returns its URL and a Source using it.
+func newAPI(t *testing.T, handler http.HandlerFunc) (string, *ofcomapi.Source) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv.URL, ofcomapi.New("secret", ofcomapi.WithBaseURL(srv.URL), ofcomapi.WithInterval(0))
+}
+
+func TestQuery_MapsRatingsToCoverage(t *testing.T) {
+	base, src := newAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Ocp-Apim-Subscription-Key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/mobile/coverage/SW1A1AA" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(sw1a1aa))
+	})
+	row, err := src.Query("SW1A1AA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for col, want := range map[string]string{"ee_voice": "1", "ee_4g": "1", "ee_4g_indoor": "0.5", "three_4g": "0", "o2_4g": "1", "vodafone_4g": "1"} {
+		if row[col] != want {
+			t.Errorf("expected %s %s, got %q", col, want, row[col])
+		}
+	}
+	if _, ok := row["three_voice"]; ok {
+		t.Error("expected a measure the API did not rate to be left out")
+	}
+
+	if row, err := src.Query("ZZ99ZZ"); err != nil || row != nil {
+		t.Errorf("expected no row for an unknown postcode, got %v (err %v)", row, err)
+	}
+	bad := ofcomapi.New("wrong", ofcomapi.WithBaseURL(base), ofcomapi.WithInterval(0))
+	if _, err := bad.Query("SW1A1AA"); !errors.Is(err, ofcomapi.ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey, got %v", err)
+	}
+}
+
+func TestQuery_RetriesTooManyRequests(t *testing.T) {
+	var calls atomic.Int32
+	_, src := newAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(sw1a1aa))
+	})
+	if row, err := src.Query("SW1A1AA"); err != nil || row == nil {
+		t.Fatalf("expected the retry to succeed, got %v (err %v)", row, err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("expected 2 requests, got %d", n)
+	}
+}
+
+func TestChecker_AnswersFromAPIWithoutDatabase(t *testing.T) {
+	_, src := newAPI(t, func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(sw1a1aa)) })
+	postcodes := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":200,"result":{"postcode":"SW1A 1AA","country":"England"}}`))
+	}))
+	defer postcodes.Close()
+
+	c := checker.New(t.TempDir(), checker.WithPrimarySource(src),
+		checker.WithPostcodeClient(postcode.NewClient(postcode.WithBaseURL(postcodes.URL))))
+	res := c.Check("SW1A1AA")
+	if res.Mobile == nil {
+		t.Fatalf("expected coverage from the API, got %+v", res)
+	}
+	if res.Mobile.Overall.FourGCount != 3 {
+		t.Errorf("expected 4G from 3 operators, got %d", res.Mobile.Overall.FourGCount)
+	}
+}
@@ -0,0 +1,83 @@
+// Package osrm provides a minimal client for the OSRM routing API.
+// Any OSRM-compatible server works, e.g. https://router.project-osrm.org
+package osrm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Point is a WGS84 coordinate.
+type Point struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Client is an HTTP client for an OSRM server.
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+// NewClient returns a new OSRM Client for the given server URL.
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type routeResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Routes  []struct {
+		Distance float64 `json:"distance"`
+		Geometry struct {
+			Coordinates [][]float64 `json:"coordinates"`
+		} `json:"geometry"`
+	} `json:"routes"`
+}
+
+// Route returns the driving route geometry between two points.
+func (c *Client) Route(from, to Point) ([]Point, error) {
+	url := fmt.Sprintf("%s/route/v1/driving/%f,%f;%f,%f?overview=full&geometries=geojson",
+		c.baseURL, from.Longitude, from.Latitude, to.Longitude, to.Latitude)
+	resp, err := c.http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed routeResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if parsed.Code != "Ok" {
+		return nil, fmt.Errorf("OSRM returned %s: %s", parsed.Code, parsed.Message)
+	}
+	if len(parsed.Routes) == 0 {
+		return nil, fmt.Errorf("OSRM returned no route")
+	}
+
+	coords := parsed.Routes[0].Geometry.Coordinates
+	points := make([]Point, 0, len(coords))
+	for _, c := range coords {
+		if len(c) < 2 {
+			continue
+		}
+		points = append(points, Point{Latitude: c[1], Longitude: c[0]})
+	}
+	if len(points) < 2 {
+		return nil, fmt.Errorf("OSRM route has too few points")
+	}
+	return points, nil
+}
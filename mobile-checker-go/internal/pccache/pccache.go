@@ -0,0 +1,198 @@
+// Package pccache keeps postcodes.io lookup results in postcodes-cache.db in
+// the data directory, so the CLI and server share them between runs and
+// repeated bulk checks make almost no requests. Postcode geography changes
+// rarely, so entries are kept for weeks rather than minutes.
+package pccache
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+// DefaultTTL is how long a lookup is reused when no TTL is given.
+const DefaultTTL = 30 * 24 * time.Hour
+
+// pruneInterval is how often Put removes expired entries.
+const pruneInterval = time.Hour
+
+// Store is a postcode.Cache in a SQLite database. The database is opened
+// on first use and held until Close.
+type Store struct {
+	DBPath string
+	// TTL is how long an entry is fresh; older ones are ignored and
+	// pruned as new ones are stored.
+	TTL time.Duration
+	// Logger reports failures; nil uses slog.Default() at the time.
+	Logger *slog.Logger
+
+	mu        sync.Mutex // serialises writes from concurrent lookups
+	db        *sql.DB
+	lastPrune time.Time
+	warned    bool // a failure has been logged; later ones are not
+}
+
+var _ postcode.Cache = (*Store)(nil)
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithLogger sets the logger failures are reported to.
+func WithLogger(l *slog.Logger) Option {
+	return func(s *Store) { s.Logger = l }
+}
+
+// New creates a Store using postcodes-cache.db in dataDir, keeping entries
+// for ttl, or DefaultTTL when it is 0.
+func New(dataDir string, ttl time.Duration, opts ...Option) *Store {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	s := &Store{DBPath: filepath.Join(dataDir, "postcodes-cache.db"), TTL: ttl}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Close closes the database. A later call reopens it.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.db == nil {
+		return nil
+	}
+	err := s.db.Close()
+	s.db = nil
+	return err
+}
+
+// handle returns the open database, opening it if needed. s.mu must be
+// held.
+func (s *Store) handle() (*sql.DB, error) {
+	if s.db != nil {
+		return s.db, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.DBPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+	d := ofcom.DefaultDriver
+	db, err := sql.Open(d.Name, d.DSN(s.DBPath, false))
+	if err != nil {
+		return nil, err
+	}
+	// WAL lets the CLI read while the server writes, and the reverse.
+	_, err = db.Exec(`PRAGMA journal_mode = WAL;
+	CREATE TABLE IF NOT EXISTS lookups (
+		postcode TEXT PRIMARY KEY,
+		result TEXT NOT NULL,
+		fetched_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	s.db = db
+	return db, nil
+}
+
+// Get returns pc's result if it was stored within the TTL.
+func (s *Store) Get(pc string) (*postcode.Result, bool) {
+	s.mu.Lock()
+	db, err := s.handle()
+	s.mu.Unlock()
+	if err != nil {
+		s.warn("failed to open postcode cache", err)
+		return nil, false
+	}
+	var body string
+	err = db.QueryRow(`SELECT result FROM lookups WHERE postcode = ? AND fetched_at >= ?`,
+		postcode.Normalise(pc), time.Now().Add(-s.TTL).Unix()).Scan(&body)
+	if err == sql.ErrNoRows {
+		return nil, false
+	}
+	if err != nil {
+		s.warn("failed to read postcode cache", err)
+		return nil, false
+	}
+	var r postcode.Result
+	if err := json.Unmarshal([]byte(body), &r); err != nil {
+		return nil, false
+	}
+	return &r, true
+}
+
+// Put stores r as pc's result.
+func (s *Store) Put(pc string, r *postcode.Result) {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	db, err := s.handle()
+	if err != nil {
+		s.warnLocked("failed to open postcode cache", err)
+		return
+	}
+	_, err = db.Exec(`INSERT OR REPLACE INTO lookups (postcode, result, fetched_at) VALUES (?, ?, ?)`,
+		postcode.Normalise(pc), string(body), time.Now().Unix())
+	if err != nil {
+		s.warnLocked("failed to write postcode cache", err)
+		return
+	}
+	if time.Since(s.lastPrune) >= pruneInterval {
+		s.lastPrune = time.Now()
+		if _, err := prune(db, time.Now().Add(-s.TTL)); err != nil {
+			s.warnLocked("failed to prune postcode cache", err)
+		}
+	}
+}
+
+// Prune removes entries fetched before cutoff and returns how many were
+// removed.
+func (s *Store) Prune(cutoff time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	db, err := s.handle()
+	if err != nil {
+		return 0, err
+	}
+	return prune(db, cutoff)
+}
+
+func prune(db *sql.DB, cutoff time.Time) (int64, error) {
+	res, err := db.Exec(`DELETE FROM lookups WHERE fetched_at < ?`, cutoff.Unix())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// warn logs the first failure, so a broken cache is noticed without
+// filling the log on every lookup.
+func (s *Store) warn(msg string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.warnLocked(msg, err)
+}
+
+func (s *Store) warnLocked(msg string, err error) {
+	if s.warned {
+		return
+	}
+	s.warned = true
+	l := s.Logger
+	if l == nil {
+		l = slog.Default()
+	}
+	l.Warn(msg, "path", s.DBPath, "err", err)
+}
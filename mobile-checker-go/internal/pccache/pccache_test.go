@@ -0,0 +1,81 @@
+package pccache_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/pccache"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+func TestStore_AnswersRepeatLookupsAcrossRuns(t *testing.T) {
+	var calls, looked int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.Method == http.MethodPost {
+			var body struct{ Postcodes []string }
+			json.NewDecoder(r.Body).Decode(&body)
+			atomic.AddInt32(&looked, int32(len(body.Postcodes)))
+			w.Write([]byte(`{"status":200,"result":[{"query":"LS11AB","result":{"postcode":"LS1 1AB","region":"Yorkshire"}}]}`))
+			return
+		}
+		w.Write([]byte(`{"status":200,"result":{"postcode":"LS1 1AA","region":"Yorkshire","latitude":53.8}}`))
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+	client := func(s *pccache.Store) *postcode.Client {
+		return postcode.NewClient(postcode.WithBaseURL(srv.URL), postcode.WithCache(s))
+	}
+
+	store := pccache.New(dir, time.Hour)
+	c := client(store)
+	for i := 0; i < 2; i++ {
+		res, err := c.Lookup("LS1 1AA")
+		if err != nil || res.Latitude != 53.8 {
+			t.Fatalf("lookup %d: got %+v, %v", i, res, err)
+		}
+	}
+	if calls != 1 || c.Stats().CacheHits != 1 {
+		t.Errorf("expected one request and one cache hit, got %d requests and %+v", calls, c.Stats())
+	}
+	store.Close()
+
+	// A later run with its own Store reads the same file.
+	store = pccache.New(dir, time.Hour)
+	defer store.Close()
+	c = client(store)
+	found, err := c.BulkLookup([]string{"LS11AA", "LS11AB"})
+	if err != nil || len(found) != 2 || found["LS11AA"].Region != "Yorkshire" {
+		t.Fatalf("bulk lookup: got %v, %v", found, err)
+	}
+	if calls != 2 || looked != 1 {
+		t.Errorf("expected only the uncached postcode to be sent, got %d requests for %d postcodes", calls, looked)
+	}
+	if _, err := c.Lookup("LS11AB"); err != nil || calls != 2 {
+		t.Errorf("expected the bulk result to be cached, got %d requests (err %v)", calls, err)
+	}
+
+	if n, err := store.Prune(time.Now().Add(time.Minute)); err != nil || n != 2 {
+		t.Errorf("expected both entries pruned, got %d (err %v)", n, err)
+	}
+	if _, ok := store.Get("LS11AA"); ok {
+		t.Error("expected a pruned entry to be gone")
+	}
+}
+
+func TestStore_IgnoresExpiredEntries(t *testing.T) {
+	store := pccache.New(t.TempDir(), time.Hour)
+	defer store.Close()
+	store.Put("LS11AA", &postcode.Result{Postcode: "LS1 1AA"})
+	if _, ok := store.Get("ls1 1aa"); !ok {
+		t.Fatal("expected a fresh entry to be found by its unnormalised postcode")
+	}
+	store.TTL = -time.Minute
+	if _, ok := store.Get("LS11AA"); ok {
+		t.Error("expected an entry older than the TTL to be ignored")
+	}
+}
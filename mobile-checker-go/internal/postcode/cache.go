@@ -0,0 +1,39 @@
+package postcode
+
+// Cache keeps lookup results between runs, so repeated checks of the same
+// postcodes need no requests. Keys are normalised postcodes. Implementations
+// must be safe for concurrent use and decide for themselves how long an
+// entry stays fresh; see internal/pccache for one on disk.
+type Cache interface {
+	// Get returns a fresh result for pc, if there is one.
+	Get(pc string) (*Result, bool)
+	// Put stores a result found by postcodes.io. Failures are the
+	// cache's to report: a lookup never fails because of its cache.
+	Put(pc string, r *Result)
+}
+
+// WithCache answers Lookup and BulkLookup from cache where it can and
+// stores what postcodes.io returns. Postcodes that are not found are not
+// cached, as new ones are added every month.
+func WithCache(cache Cache) Option {
+	return func(c *Client) { c.cache = cache }
+}
+
+// cached returns pc's cached result, counting the hit.
+func (c *Client) cached(pc string) (*Result, bool) {
+	if c.cache == nil {
+		return nil, false
+	}
+	r, ok := c.cache.Get(pc)
+	if ok {
+		c.stats.cacheHits.Add(1)
+	}
+	return r, ok
+}
+
+// store caches a result postcodes.io returned for pc.
+func (c *Client) store(pc string, r *Result) {
+	if c.cache != nil && r != nil {
+		c.cache.Put(pc, r)
+	}
+}
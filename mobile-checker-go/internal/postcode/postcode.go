@@ -3,37 +3,90 @@
 package postcode
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
 
 const baseURL = "https://api.postcodes.io"
 
+var (
+	// ErrInvalid is returned for strings that are not shaped like a UK
+	// postcode; no request is made for them.
+	ErrInvalid = errors.New("not a valid UK postcode")
+	// ErrNotFound is returned when postcodes.io does not recognise a postcode.
+	ErrNotFound = errors.New("not found or invalid")
+	// ErrUnavailable is returned when postcodes.io cannot be reached or fails.
+	ErrUnavailable = errors.New("postcodes.io unavailable")
+)
+
 // Client is an HTTP client for postcodes.io.
 type Client struct {
-	http *http.Client
+	http    *http.Client
+	baseURL string
+	header  http.Header
+	retry   RetryPolicy
+	breaker *breaker
+	limiter *limiter
+	cache   Cache
+	stats   counters
+	sleep   func(time.Duration)
+}
+
+// NewClient returns a new postcodes.io Client. By default requests are
+// retried with DefaultRetryPolicy, a circuit breaker opens for 30 seconds
+// after 5 consecutive failures, and requests are held to DefaultRateLimit.
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		http:    &http.Client{Timeout: 10 * time.Second},
+		baseURL: baseURL,
+		header:  http.Header{},
+		retry:   DefaultRetryPolicy,
+		breaker: &breaker{threshold: 5, cooldown: 30 * time.Second},
+		limiter: newLimiter(DefaultRateLimit),
+		sleep:   time.Sleep,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *Client) get(ctx context.Context, url string) (*http.Response, error) {
+	return c.do(ctx, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	})
 }
 
-// NewClient returns a new postcodes.io Client.
-func NewClient() *Client {
-	return &Client{http: &http.Client{Timeout: 10 * time.Second}}
+func (c *Client) post(url string, payload []byte) (*http.Response, error) {
+	return c.do(context.Background(), func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
 }
 
 // Result holds geographic data for a postcode.
 type Result struct {
-	Postcode                  string  `json:"postcode"`
-	Country                   string  `json:"country"`
-	Region                    string  `json:"region"`
-	AdminDistrict             string  `json:"admin_district"`
-	ParliamentaryConstituency string  `json:"parliamentary_constituency"`
-	Latitude                  float64 `json:"latitude"`
-	Longitude                 float64 `json:"longitude"`
-	Eastings                  int     `json:"eastings"`
-	Northings                 int     `json:"northings"`
+	Postcode                  string  `json:"postcode" xml:"postcode"`
+	Country                   string  `json:"country" xml:"country"`
+	Region                    string  `json:"region" xml:"region"`
+	AdminDistrict             string  `json:"admin_district" xml:"admin_district"`
+	ParliamentaryConstituency string  `json:"parliamentary_constituency" xml:"parliamentary_constituency"`
+	Latitude                  float64 `json:"latitude" xml:"latitude"`
+	Longitude                 float64 `json:"longitude" xml:"longitude"`
+	Eastings                  int     `json:"eastings" xml:"eastings"`
+	Northings                 int     `json:"northings" xml:"northings"`
 }
 
 type apiResponse struct {
@@ -46,20 +99,47 @@ func Normalise(pc string) string {
 	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(pc), " ", ""))
 }
 
+// Format returns a postcode in its usual written form, with a space before
+// the inward code, e.g. SW1A 1AA.
+func Format(pc string) string {
+	n := Normalise(pc)
+	if len(n) < 5 {
+		return n
+	}
+	return n[:len(n)-3] + " " + n[len(n)-3:]
+}
+
+// Valid reports whether pc is shaped like a UK postcode once normalised; see
+// Validate. It does not check that the postcode exists.
+func Valid(pc string) bool {
+	return Validate(pc) == nil
+}
+
 // Lookup returns geographic data for a UK postcode.
 func (c *Client) Lookup(postcode string) (*Result, error) {
+	return c.LookupContext(context.Background(), postcode)
+}
+
+// LookupContext is Lookup, giving up when ctx is done.
+func (c *Client) LookupContext(ctx context.Context, postcode string) (*Result, error) {
 	pc := Normalise(postcode)
-	resp, err := c.http.Get(fmt.Sprintf("%s/postcodes/%s", baseURL, pc))
+	if err := Validate(pc); err != nil {
+		return nil, fmt.Errorf("postcode %q: %w", postcode, err)
+	}
+	if r, ok := c.cached(pc); ok {
+		return r, nil
+	}
+	resp, err := c.get(ctx, fmt.Sprintf("%s/postcodes/%s", c.baseURL, pc))
 	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("postcode %q not found or invalid", postcode)
+		return nil, fmt.Errorf("postcode %q %w", postcode, ErrNotFound)
 	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("postcodes.io returned status %d", resp.StatusCode)
+		return nil, fmt.Errorf("%w: returned status %d", ErrUnavailable, resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -74,5 +154,188 @@ func (c *Client) Lookup(postcode string) (*Result, error) {
 	if parsed.Result == nil {
 		return nil, fmt.Errorf("postcode %q returned no data", postcode)
 	}
+	c.store(pc, parsed.Result)
+	return parsed.Result, nil
+}
+
+type reverseResponse struct {
+	Status int       `json:"status"`
+	Result []*Result `json:"result"`
+}
+
+// Reverse returns the nearest postcode to a latitude/longitude.
+func (c *Client) Reverse(lat, lon float64) (*Result, error) {
+	return c.ReverseContext(context.Background(), lat, lon)
+}
+
+// ReverseContext is Reverse, giving up when ctx is done.
+func (c *Client) ReverseContext(ctx context.Context, lat, lon float64) (*Result, error) {
+	url := fmt.Sprintf("%s/postcodes?lon=%f&lat=%f&limit=1&radius=2000&widesearch=true", c.baseURL, lon, lat)
+	resp, err := c.get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: returned status %d", ErrUnavailable, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed reverseResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(parsed.Result) == 0 || parsed.Result[0] == nil {
+		return nil, fmt.Errorf("no postcode near %.5f, %.5f", lat, lon)
+	}
+	return parsed.Result[0], nil
+}
+
+// MaxAutocomplete is the most suggestions postcodes.io returns.
+const MaxAutocomplete = 100
+
+// Autocomplete returns up to limit postcodes starting with partial, e.g.
+// "SW1A". It returns an empty slice when nothing matches.
+func (c *Client) Autocomplete(partial string, limit int) ([]string, error) {
+	pc := Normalise(partial)
+	if pc == "" {
+		return nil, fmt.Errorf("partial postcode %q: %w", partial, ErrInvalid)
+	}
+	if limit <= 0 || limit > MaxAutocomplete {
+		limit = MaxAutocomplete
+	}
+	resp, err := c.get(context.Background(), fmt.Sprintf("%s/postcodes/%s/autocomplete?limit=%d", c.baseURL, url.PathEscape(pc), limit))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: returned status %d", ErrUnavailable, resp.StatusCode)
+	}
+	var parsed struct {
+		Result []string `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if parsed.Result == nil {
+		return []string{}, nil
+	}
+	return parsed.Result, nil
+}
+
+// Terminated describes a postcode that is no longer in use.
+type Terminated struct {
+	Postcode        string  `json:"postcode"`
+	YearTerminated  int     `json:"year_terminated"`
+	MonthTerminated int     `json:"month_terminated"`
+	Latitude        float64 `json:"latitude"`
+	Longitude       float64 `json:"longitude"`
+}
+
+// Terminated returns termination details for a postcode that has been
+// retired, or an error wrapping ErrNotFound if it was never terminated.
+func (c *Client) Terminated(postcode string) (*Terminated, error) {
+	return c.TerminatedContext(context.Background(), postcode)
+}
+
+// TerminatedContext is Terminated, giving up when ctx is done.
+func (c *Client) TerminatedContext(ctx context.Context, postcode string) (*Terminated, error) {
+	pc := Normalise(postcode)
+	resp, err := c.get(ctx, fmt.Sprintf("%s/terminated_postcodes/%s", c.baseURL, pc))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("terminated postcode %q: %w", postcode, ErrNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: returned status %d", ErrUnavailable, resp.StatusCode)
+	}
+
+	var parsed struct {
+		Result *Terminated `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if parsed.Result == nil {
+		return nil, fmt.Errorf("terminated postcode %q returned no data", postcode)
+	}
 	return parsed.Result, nil
 }
+
+type bulkResponse struct {
+	Status int `json:"status"`
+	Result []struct {
+		Query  string  `json:"query"`
+		Result *Result `json:"result"`
+	} `json:"result"`
+}
+
+// MaxBulk is the most postcodes postcodes.io accepts in one bulk lookup.
+const MaxBulk = 100
+
+// BulkLookup returns geographic data for up to MaxBulk postcodes, keyed by
+// normalised postcode. Postcodes that are not found are omitted.
+func (c *Client) BulkLookup(postcodes []string) (map[string]*Result, error) {
+	if len(postcodes) > MaxBulk {
+		return nil, fmt.Errorf("at most %d postcodes per bulk lookup", MaxBulk)
+	}
+	results := make(map[string]*Result, len(postcodes))
+	var missing []string
+	for _, pc := range postcodes {
+		if r, ok := c.cached(Normalise(pc)); ok {
+			results[Normalise(pc)] = r
+		} else {
+			missing = append(missing, pc)
+		}
+	}
+	if len(missing) == 0 {
+		return results, nil
+	}
+	payload, err := json.Marshal(map[string][]string{"postcodes": missing})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.post(c.baseURL+"/postcodes", payload)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: returned status %d", ErrUnavailable, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed bulkResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	for _, r := range parsed.Result {
+		if r.Result != nil {
+			results[Normalise(r.Query)] = r.Result
+			c.store(Normalise(r.Query), r.Result)
+		}
+	}
+	return results, nil
+}
+
+// Ping checks that postcodes.io is reachable and answering lookups.
+func (c *Client) Ping() error {
+	_, err := c.Lookup("SW1A1AA")
+	return err
+}
@@ -0,0 +1,241 @@
+package postcode_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+// newTestClient returns a client for the test server at url that retries
+// without waiting.
+func newTestClient(url string, opts ...postcode.Option) *postcode.Client {
+	return postcode.NewClient(append([]postcode.Option{postcode.WithBaseURL(url), postcode.WithRetry(postcode.RetryPolicy{MaxAttempts: 3})}, opts...)...)
+}
+
+func TestLookup_RetriesServerErrors(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"status":200,"result":{"postcode":"SW1A 1AA","country":"England"}}`))
+	}))
+	defer srv.Close()
+
+	res, err := newTestClient(srv.URL).Lookup("SW1A1AA")
+	if err != nil {
+		t.Fatalf("lookup failed: %v", err)
+	}
+	if res.Country != "England" || calls != 3 {
+		t.Errorf("expected success on third attempt, got %+v after %d calls", res, calls)
+	}
+}
+
+func TestLookup_DoesNotRetryNotFound(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	_, err := newTestClient(srv.URL).Lookup("ZY999ZZ")
+	if !errors.Is(err, postcode.ErrNotFound) || calls != 1 {
+		t.Errorf("expected one call returning postcode.ErrNotFound, got %v after %d calls", err, calls)
+	}
+}
+
+func TestLookup_RejectsMalformedPostcode(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer srv.Close()
+
+	_, err := newTestClient(srv.URL).Lookup("SW1A")
+	if !errors.Is(err, postcode.ErrInvalid) || calls != 0 {
+		t.Errorf("expected postcode.ErrInvalid without a request, got %v after %d calls", err, calls)
+	}
+	if !postcode.Valid("sw1a 1aa") || !postcode.Valid("M1 1AE") || postcode.Valid("12345") {
+		t.Error("Valid misclassified a postcode")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := []string{"SW1A 1AA", "m1 1ae", "B338TH", "CR2 6XH", "DN55 1PT", "W1A 0AX", "EC1A 1BB", "GIR 0AA"}
+	for _, pc := range valid {
+		if err := postcode.Validate(pc); err != nil {
+			t.Errorf("Validate(%q) = %v, want nil", pc, err)
+		}
+	}
+	invalid := map[string]string{
+		"HELLO":    "inward code",
+		"":         "empty",
+		"SW1A1AAA": "too long",
+		"QA1 1AA":  "cannot start with Q, V or X",
+		"AZ1 1AA":  "second letter",
+		"W1L 1AA":  "cannot end in L",
+		"SW1C 1AA": "cannot end in C",
+		"SW1A 1AC": "cannot contain",
+		"1A1 1AA":  "must start with a letter",
+		"ABC1 1AA": "one or two letters",
+	}
+	for pc, want := range invalid {
+		err := postcode.Validate(pc)
+		if !errors.Is(err, postcode.ErrInvalid) || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate(%q) = %v, want postcode.ErrInvalid mentioning %q", pc, err, want)
+		}
+	}
+
+	for _, oc := range []string{"SW1A", "m1", "B33", "ls 1"} {
+		if err := postcode.ValidateOutcode(oc); err != nil {
+			t.Errorf("ValidateOutcode(%q) = %v, want nil", oc, err)
+		}
+	}
+	for _, oc := range []string{"", "QA1", "SW1A1", "LS1 1AA"} {
+		if err := postcode.ValidateOutcode(oc); !errors.Is(err, postcode.ErrInvalid) {
+			t.Errorf("ValidateOutcode(%q) = %v, want postcode.ErrInvalid", oc, err)
+		}
+	}
+}
+
+func TestTerminated_ParsesResult(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/terminated_postcodes/AB11AA" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"status":200,"result":{"postcode":"AB1 1AA","year_terminated":1996,"month_terminated":6,"longitude":-2.24,"latitude":57.10}}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(srv.URL)
+	got, err := c.Terminated("ab1 1aa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.YearTerminated != 1996 || got.MonthTerminated != 6 || got.Latitude != 57.10 {
+		t.Errorf("unexpected result %+v", got)
+	}
+	if _, err := c.Terminated("SW1A1AA"); !errors.Is(err, postcode.ErrNotFound) {
+		t.Errorf("expected postcode.ErrNotFound for an active postcode, got %v", err)
+	}
+}
+
+func TestAutocomplete_EmptyResult(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/postcodes/SW1A/autocomplete" && r.URL.Query().Get("limit") == "2" {
+			w.Write([]byte(`{"status":200,"result":["SW1A 0AA","SW1A 0PW"]}`))
+			return
+		}
+		w.Write([]byte(`{"status":200,"result":null}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(srv.URL)
+	got, err := c.Autocomplete("sw1a", 2)
+	if err != nil || len(got) != 2 || got[0] != "SW1A 0AA" {
+		t.Fatalf("unexpected suggestions %v, %v", got, err)
+	}
+	got, err = c.Autocomplete("ZZ9", 2)
+	if err != nil || got == nil || len(got) != 0 {
+		t.Errorf("expected an empty, non-nil slice, got %#v, %v", got, err)
+	}
+}
+
+func TestLookup_CircuitBreakerOpens(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(srv.URL, postcode.WithRetry(postcode.RetryPolicy{MaxAttempts: 2}), postcode.WithCircuitBreaker(2, time.Hour))
+	for i := 0; i < 2; i++ {
+		if _, err := c.Lookup("SW1A1AA"); !errors.Is(err, postcode.ErrUnavailable) {
+			t.Fatalf("attempt %d: expected postcode.ErrUnavailable, got %v", i, err)
+		}
+	}
+	_, err := c.Lookup("SW1A1AA")
+	if !errors.Is(err, postcode.ErrCircuitOpen) || !errors.Is(err, postcode.ErrUnavailable) {
+		t.Errorf("expected open circuit, got %v", err)
+	}
+	if calls != 4 {
+		t.Errorf("expected 4 upstream calls before the circuit opened, got %d", calls)
+	}
+}
+
+func TestLookup_RateLimitHoldsBackBursts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":200,"result":{"postcode":"SW1A 1AA","country":"England"}}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(srv.URL, postcode.WithRateLimit(postcode.RateLimit{PerSecond: 50, Burst: 2}))
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if _, err := c.Lookup("SW1A1AA"); err != nil {
+			t.Fatalf("lookup %d failed: %v", i, err)
+		}
+	}
+	// Two go at once; the other four wait 20ms each for a token.
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+		t.Errorf("expected six lookups at 50/s with a burst of 2 to take at least 80ms, took %v", elapsed)
+	}
+	st := c.Stats()
+	if st.Requests != 6 || st.Throttled != 4 || st.Waited <= 0 {
+		t.Errorf("expected 6 requests with 4 throttled, got %+v", st)
+	}
+}
+
+func TestLookup_RateLimitCountsTooManyRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(srv.URL, postcode.WithRateLimit(postcode.RateLimit{}))
+	if _, err := c.Lookup("SW1A1AA"); !errors.Is(err, postcode.ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+	if st := c.Stats(); st.RateLimited != 3 || st.Throttled != 0 {
+		t.Errorf("expected 3 rate-limited responses and no throttling, got %+v", st)
+	}
+}
+
+func TestLookup_SelfHostedHeadersAndTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/postcodes/LS11AA" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte(`{"status":200,"result":{"postcode":"SW1A 1AA","country":"England"}}`))
+	}))
+	defer srv.Close()
+
+	k, v, err := postcode.ParseHeader("Authorization: Bearer s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newTestClient(srv.URL+"/", postcode.WithHeader(k, v), postcode.WithTimeout(50*time.Millisecond))
+	if res, err := c.Lookup("SW1A1AA"); err != nil || res.Country != "England" {
+		t.Fatalf("expected the header to authorise the lookup, got %+v (err %v)", res, err)
+	}
+	if _, err := c.Lookup("LS11AA"); !errors.Is(err, postcode.ErrUnavailable) {
+		t.Errorf("expected a slow answer to time out as ErrUnavailable, got %v", err)
+	}
+	if _, _, err := postcode.ParseHeader("no colon"); err == nil {
+		t.Error("expected a header without a colon to be rejected")
+	}
+}
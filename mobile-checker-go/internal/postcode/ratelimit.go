@@ -0,0 +1,127 @@
+package postcode
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimit caps the requests a Client sends: PerSecond on average, with
+// up to Burst sent at once after a quiet spell. Requests over the limit
+// wait their turn rather than fail.
+type RateLimit struct {
+	PerSecond float64
+	Burst     int
+}
+
+// DefaultRateLimit keeps bulk jobs within postcodes.io's fair use, which
+// blocks clients for a while after sustained bursts.
+var DefaultRateLimit = RateLimit{PerSecond: 10, Burst: 20}
+
+// WithRateLimit sets the client-side rate limit. A PerSecond of 0 disables
+// it, e.g. for a self-hosted mirror.
+func WithRateLimit(l RateLimit) Option {
+	return func(c *Client) { c.limiter = newLimiter(l) }
+}
+
+// Stats counts a Client's requests since it was created.
+type Stats struct {
+	Requests    int64         // HTTP attempts sent, including retries
+	Throttled   int64         // attempts held back by the rate limit
+	Waited      time.Duration // total time attempts were held back
+	RateLimited int64         // 429 responses from postcodes.io
+	CacheHits   int64         // lookups answered by the cache; see WithCache
+}
+
+// Stats returns the client's request counts, e.g. to log after a bulk job.
+func (c *Client) Stats() Stats {
+	return Stats{
+		Requests:    c.stats.requests.Load(),
+		Throttled:   c.stats.throttled.Load(),
+		Waited:      time.Duration(c.stats.wait.Load()),
+		RateLimited: c.stats.rateLimited.Load(),
+		CacheHits:   c.stats.cacheHits.Load(),
+	}
+}
+
+// counters are the atomics behind Stats.
+type counters struct {
+	requests, throttled, rateLimited atomic.Int64
+	cacheHits                        atomic.Int64
+	wait                             atomic.Int64 // nanoseconds
+}
+
+// limiter is a token bucket: tokens accrue at rate per second up to burst,
+// and each request takes one, waiting for it if the bucket is empty.
+type limiter struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(l RateLimit) *limiter {
+	if l.PerSecond <= 0 {
+		return nil
+	}
+	burst := float64(l.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &limiter{rate: l.PerSecond, burst: burst, tokens: burst}
+}
+
+// reserve takes a token and returns how long the caller must wait before
+// using it. Tokens are handed out in order, so waiters queue fairly.
+func (l *limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel returns a token reserved by a caller that gave up waiting.
+func (l *limiter) cancel() {
+	l.mu.Lock()
+	l.tokens++
+	l.mu.Unlock()
+}
+
+// wait blocks until the client may send a request, recording the delay in
+// its stats. It returns early with ctx's error if ctx is done first.
+func (c *Client) wait(ctx context.Context) error {
+	if c.limiter == nil {
+		return nil
+	}
+	d := c.limiter.reserve()
+	if d <= 0 {
+		return nil
+	}
+	c.stats.throttled.Add(1)
+	t := time.NewTimer(d)
+	defer t.Stop()
+	start := time.Now()
+	select {
+	case <-t.C:
+		c.stats.wait.Add(int64(d))
+		return nil
+	case <-ctx.Done():
+		c.limiter.cancel()
+		c.stats.wait.Add(int64(time.Since(start)))
+		return context.Cause(ctx)
+	}
+}
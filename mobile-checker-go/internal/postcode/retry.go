@@ -0,0 +1,187 @@
+package postcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned, wrapped in ErrUnavailable, while the circuit
+// breaker is refusing requests after repeated postcodes.io failures.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// RetryPolicy controls how failed requests are retried. Transport errors
+// (including timeouts), 429 and 5xx responses are retried with exponential
+// backoff and full jitter.
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts per request, including the first
+	BaseDelay   time.Duration // backoff before the first retry
+	MaxDelay    time.Duration // cap on any single backoff
+}
+
+// DefaultRetryPolicy makes up to three attempts.
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: 200 * time.Millisecond, MaxDelay: 2 * time.Second}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithRetry sets the retry policy. MaxAttempts of 1 disables retries.
+func WithRetry(p RetryPolicy) Option {
+	return func(c *Client) { c.retry = p }
+}
+
+// WithCircuitBreaker opens the circuit after threshold consecutive failed
+// requests, failing fast with ErrCircuitOpen for cooldown before letting a
+// trial request through. A threshold of 0 disables the breaker.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *Client) { c.breaker = &breaker{threshold: threshold, cooldown: cooldown} }
+}
+
+// WithBaseURL sends requests to a postcodes.io-compatible service at url
+// instead of the public API, e.g. a self-hosted mirror or a test server.
+func WithBaseURL(url string) Option {
+	return func(c *Client) { c.baseURL = strings.TrimRight(url, "/") }
+}
+
+// WithHeader adds a header to every request, e.g. credentials for a
+// self-hosted instance behind an authenticating proxy.
+func WithHeader(key, value string) Option {
+	return func(c *Client) { c.header.Add(key, value) }
+}
+
+// WithTimeout limits each request attempt, including reading the
+// response; the default is 10 seconds. Retries get their own timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) { c.http.Timeout = d }
+}
+
+// ParseHeader parses a header given as "Name: value", as curl's -H takes.
+func ParseHeader(s string) (key, value string, err error) {
+	key, value, ok := strings.Cut(s, ":")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" || strings.ContainsAny(key, " \t") {
+		return "", "", fmt.Errorf("header %q: want \"Name: value\"", s)
+	}
+	return key, strings.TrimSpace(value), nil
+}
+
+// breaker is a consecutive-failure circuit breaker.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// allow reports whether a request may be attempted.
+func (b *breaker) allow() bool {
+	if b == nil || b.threshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return true
+	}
+	if time.Now().Before(b.openUntil) {
+		return false
+	}
+	// Half-open: let one trial through and hold the rest for another cooldown.
+	b.openUntil = time.Now().Add(b.cooldown)
+	return true
+}
+
+func (b *breaker) record(ok bool) {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ok {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+// do sends the request built by newReq, retrying according to the client's
+// policy until ctx is done. The final response is returned even if its
+// status is retryable, so callers map status codes as usual.
+func (c *Client) do(ctx context.Context, newReq func() (*http.Request, error)) (*http.Response, error) {
+	if !c.breaker.allow() {
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, ErrCircuitOpen)
+	}
+	attempts := c.retry.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var resp *http.Response
+	var err error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			c.sleep(c.backoff(i))
+		}
+		if ctx.Err() != nil {
+			// Given up on by the caller, which says nothing about
+			// postcodes.io, so the breaker is left alone.
+			return nil, fmt.Errorf("%w: %w", ErrUnavailable, context.Cause(ctx))
+		}
+		if err := c.wait(ctx); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+		}
+		var req *http.Request
+		if req, err = newReq(); err != nil {
+			return nil, err
+		}
+		for k, v := range c.header {
+			req.Header[k] = v
+		}
+		c.stats.requests.Add(1)
+		resp, err = c.http.Do(req)
+		if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+			c.stats.rateLimited.Add(1)
+		}
+		if err == nil && !retryable(resp.StatusCode) {
+			c.breaker.record(true)
+			return resp, nil
+		}
+		if err == nil && i < attempts-1 {
+			resp.Body.Close()
+		}
+	}
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, context.Cause(ctx))
+	}
+	c.breaker.record(false)
+	if err != nil {
+		return nil, fmt.Errorf("%w: HTTP request failed: %w", ErrUnavailable, err)
+	}
+	return resp, nil
+}
+
+// backoff returns a random delay in [0, min(MaxDelay, BaseDelay*2^(retry-1))].
+func (c *Client) backoff(retry int) time.Duration {
+	d := c.retry.BaseDelay << (retry - 1)
+	if c.retry.MaxDelay > 0 && (d > c.retry.MaxDelay || d <= 0) {
+		d = c.retry.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
@@ -0,0 +1,98 @@
+package postcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Letters allowed in each restricted position of a UK postcode, per the
+// Royal Mail / BS 7666 format rules.
+const (
+	notFirst     = "QVX"             // never the first letter of the area
+	notSecond    = "IJZ"             // never the second letter of the area
+	thirdA9A     = "ABCDEFGHJKPSTUW" // the letter in A9A outward codes, e.g. W1A
+	fourthAA9A   = "ABEHMNPRVWXY"    // the letter in AA9A outward codes, e.g. SW1A
+	notInwardEnd = "CIKMOV"          // never in the inward code's last two letters
+)
+
+// Validate checks that pc, in any case and with or without its space, is
+// a well-formed UK postcode: an outward code in one of the formats A9, A99,
+// AA9, AA99, A9A or AA9A and an inward code of a digit and two letters,
+// using only the letters allowed in each position. It makes no request and
+// does not check that the postcode exists. The error wraps ErrInvalid and
+// says what is wrong.
+func Validate(pc string) error {
+	n := Normalise(pc)
+	if n == "GIR0AA" {
+		return nil // Girobank, the one historic exception
+	}
+	switch {
+	case n == "":
+		return fmt.Errorf("%w: it is empty", ErrInvalid)
+	case len(n) < 5:
+		return fmt.Errorf("%w: too short", ErrInvalid)
+	case len(n) > 7:
+		return fmt.Errorf("%w: too long", ErrInvalid)
+	}
+
+	outward, inward := n[:len(n)-3], n[len(n)-3:]
+	if !isDigit(inward[0]) || !isLetter(inward[1]) || !isLetter(inward[2]) {
+		return fmt.Errorf("%w: inward code %q must be a digit and two letters, e.g. 1AA", ErrInvalid, inward)
+	}
+	if strings.ContainsAny(inward[1:], notInwardEnd) {
+		return fmt.Errorf("%w: inward code %q cannot contain %s", ErrInvalid, inward, strings.Join(strings.Split(notInwardEnd, ""), ", "))
+	}
+	return validateOutward(outward)
+}
+
+// ValidateOutcode checks that oc, in any case and ignoring spaces, is a
+// well-formed outward code, e.g. "SW1A" or "m1", by the rules of Validate.
+// The error wraps ErrInvalid.
+func ValidateOutcode(oc string) error {
+	n := Normalise(oc)
+	switch {
+	case n == "":
+		return fmt.Errorf("%w: outward code is empty", ErrInvalid)
+	case len(n) > 4:
+		return fmt.Errorf("%w: outward code %q is too long", ErrInvalid, n)
+	}
+	return validateOutward(n)
+}
+
+func validateOutward(o string) error {
+	bad := func(why string) error {
+		return fmt.Errorf("%w: outward code %q %s", ErrInvalid, o, why)
+	}
+	if !isLetter(o[0]) {
+		return bad("must start with a letter")
+	}
+	if strings.IndexByte(notFirst, o[0]) >= 0 {
+		return bad("cannot start with Q, V or X")
+	}
+	area := 1
+	if len(o) > 1 && isLetter(o[1]) {
+		if strings.IndexByte(notSecond, o[1]) >= 0 {
+			return bad("cannot have I, J or Z as its second letter")
+		}
+		area = 2
+	}
+	district := o[area:]
+	switch {
+	case len(district) == 1 && isDigit(district[0]),
+		len(district) == 2 && isDigit(district[0]) && isDigit(district[1]):
+		return nil
+	case len(district) == 2 && isDigit(district[0]) && isLetter(district[1]):
+		allowed := thirdA9A
+		if area == 2 {
+			allowed = fourthAA9A
+		}
+		if strings.IndexByte(allowed, district[1]) < 0 {
+			return bad(fmt.Sprintf("cannot end in %c", district[1]))
+		}
+		return nil
+	}
+	return bad("must be one or two letters then a district, e.g. M1, B33, SW1A")
+}
+
+func isDigit(b byte) bool  { return b >= '0' && b <= '9' }
+func isLetter(b byte) bool { return b >= 'A' && b <= 'Z' }
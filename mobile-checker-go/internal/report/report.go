@@ -0,0 +1,126 @@
+// Package report renders coverage check results as a self-contained HTML page.
+package report
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+)
+
+//go:embed report.html.tmpl
+var pageTemplate string
+
+var page = template.Must(template.New("report").Parse(pageTemplate))
+
+// Report is the content of an HTML coverage report.
+type Report struct {
+	Title     string
+	Dataset   string // Ofcom dataset year the results came from
+	Generated time.Time
+	Entries   []Entry
+}
+
+// Entry is one postcode in a report.
+type Entry struct {
+	checker.Result
+	// Years holds coverage from each installed dataset, oldest first. A
+	// comparison table is rendered when it has more than one year.
+	Years []YearCoverage
+}
+
+// YearCoverage is a postcode's coverage in one dataset year; see
+// checker.Checker.CoverageByYear.
+type YearCoverage = checker.YearCoverage
+
+// Map size in SVG user units, and the margin kept clear around markers.
+const (
+	mapWidth  = 640
+	mapHeight = 360
+	mapMargin = 40
+)
+
+// mapView is the inline SVG map of the report's postcodes. It is drawn
+// from the coordinates alone, without map tiles or scripts, so the report
+// opens offline.
+type mapView struct {
+	Width, Height int
+	Markers       []marker
+}
+
+// marker is a postcode plotted on the map, classed by how many operators
+// have 4G there.
+type marker struct {
+	Postcode     string
+	X, Y         float64
+	FourG, FiveG int
+	Class        string // good, fair or poor
+}
+
+// Write renders r as HTML to w.
+func Write(w io.Writer, r Report) error {
+	if r.Title == "" {
+		r.Title = "UK Mobile Coverage Report"
+	}
+	if r.Generated.IsZero() {
+		r.Generated = time.Now()
+	}
+	return page.Execute(w, struct {
+		Report
+		Map *mapView
+	}{r, newMap(r.Entries)})
+}
+
+// newMap projects the entries with coordinates onto the map, scaling
+// longitude by the cosine of the mean latitude so distances look right.
+// It returns nil when no entry has coordinates.
+func newMap(entries []Entry) *mapView {
+	var ms []marker
+	var lats, lons []float64
+	for _, e := range entries {
+		g := e.Geographic
+		if g == nil || (g.Latitude == 0 && g.Longitude == 0) {
+			continue
+		}
+		mk := marker{Postcode: e.Postcode, Class: "poor"}
+		if e.Mobile != nil {
+			mk.FourG, mk.FiveG = e.Mobile.Overall.FourGCount, e.Mobile.Overall.FiveGCount
+		}
+		switch {
+		case mk.FourG >= 4:
+			mk.Class = "good"
+		case mk.FourG >= 2:
+			mk.Class = "fair"
+		}
+		ms = append(ms, mk)
+		lats = append(lats, g.Latitude)
+		lons = append(lons, g.Longitude)
+	}
+	if len(ms) == 0 {
+		return nil
+	}
+
+	minLat, maxLat := slices.Min(lats), slices.Max(lats)
+	minLon, maxLon := slices.Min(lons), slices.Max(lons)
+	k := math.Cos((minLat + maxLat) / 2 * math.Pi / 180)
+	spanX, spanY := (maxLon-minLon)*k, maxLat-minLat
+	scale := math.Inf(1)
+	if spanX > 0 {
+		scale = float64(mapWidth-2*mapMargin) / spanX
+	}
+	if spanY > 0 {
+		scale = math.Min(scale, float64(mapHeight-2*mapMargin)/spanY)
+	}
+	if math.IsInf(scale, 1) {
+		scale = 0 // a single place: centre it
+	}
+	for i := range ms {
+		ms[i].X = math.Round((mapWidth/2+((lons[i]-minLon)*k-spanX/2)*scale)*10) / 10
+		ms[i].Y = math.Round((mapHeight/2-((lats[i]-minLat)-spanY/2)*scale)*10) / 10
+	}
+	return &mapView{Width: mapWidth, Height: mapHeight, Markers: ms}
+}
@@ -0,0 +1,94 @@
+package report_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+	"github.com/yourusername/mobile-checker/internal/report"
+)
+
+func TestWrite_RendersEntriesAndMap(t *testing.T) {
+	summary := ofcom.Interpret(map[string]string{"postcode": "SW1A1AA", "ee_4g": "1", "three_5g": "0.2"})
+	r := report.Report{
+		Dataset: "2023",
+		Entries: []report.Entry{
+			{Result: checker.Result{
+				Postcode:   "SW1A1AA",
+				Valid:      true,
+				Geographic: &postcode.Result{AdminDistrict: "Westminster", Latitude: 51.501, Longitude: -0.141},
+				Mobile:     &summary,
+			}},
+			{Result: checker.Result{Postcode: "<XX>", Error: "Postcode lookup failed"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := report.Write(&buf, r); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	html := buf.String()
+	for _, want := range []string{"<h2>SW1A1AA</h2>", "Westminster", "Ofcom Connected Nations 2023", `<svg id="map"`,
+		`<circle class="poor" cx="320" cy="180"`, "<title>SW1A1AA: 4G 1/4", "&lt;XX&gt;"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report missing %q", want)
+		}
+	}
+	for _, external := range []string{"http://", "https://unpkg", "<script", "<link"} {
+		if strings.Contains(html, external) {
+			t.Errorf("self-contained report references %q", external)
+		}
+	}
+	if strings.Contains(html, "Change between datasets") {
+		t.Error("year comparison rendered with a single dataset")
+	}
+}
+
+func TestWrite_ComparesYears(t *testing.T) {
+	old := ofcom.Interpret(map[string]string{"postcode": "LS11AA", "ee_4g": "1"})
+	cur := ofcom.Interpret(map[string]string{"postcode": "LS11AA", "ee_4g": "1", "o2_4g": "1", "three_5g": "1"})
+	r := report.Report{Entries: []report.Entry{{
+		Result: checker.Result{Postcode: "LS11AA", Valid: true, Mobile: &cur},
+		Years: []report.YearCoverage{
+			{Year: "2021"},
+			{Year: "2022", Mobile: &old},
+			{Year: "2023", Mobile: &cur},
+		},
+	}}}
+
+	var buf bytes.Buffer
+	if err := report.Write(&buf, r); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	html := buf.String()
+	for _, want := range []string{"Change between datasets", "<td>2021</td><td colspan=\"3\">no data</td>",
+		"<td>2022</td><td>1/4</td><td>0/4</td>", "<td>2023</td><td>2/4</td><td>1/4</td>"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report missing %q", want)
+		}
+	}
+	if strings.Contains(html, "<svg") {
+		t.Error("map rendered without coordinates")
+	}
+}
+
+func TestWrite_MapFitsMarkers(t *testing.T) {
+	entry := func(pc string, lat, lon float64) report.Entry {
+		return report.Entry{Result: checker.Result{Postcode: pc, Geographic: &postcode.Result{Latitude: lat, Longitude: lon}}}
+	}
+	// Leeds eastwards: wider than tall, so the width sets the scale.
+	r := report.Report{Entries: []report.Entry{entry("LS11AA", 53.80, -1.55), entry("LS250AA", 53.82, -1.08)}}
+	var buf bytes.Buffer
+	if err := report.Write(&buf, r); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	html := buf.String()
+	for _, want := range []string{`cx="40" cy="`, `cx="600" cy="`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected a marker at %s within the margins", want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Package seal encrypts data at rest with AES-256-GCM, under keys derived
+// from a passphrase with scrypt. The Ofcom database and check history use
+// it when deployments treat them as sensitive.
+package seal
+
+import (
+	"container/list"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// SaltSize is the length of the salt stored with encrypted data.
+const SaltSize = 16
+
+// Overhead is the bytes Seal adds to its input: a nonce and a tag.
+const Overhead = 12 + 16
+
+// ErrDecrypt is returned for data that fails authentication: the key is
+// wrong or the data has been altered.
+var ErrDecrypt = errors.New("cannot decrypt: wrong encryption key or corrupt data")
+
+// Key encrypts and authenticates data. It is safe for concurrent use.
+type Key struct {
+	aead cipher.AEAD
+	mac  []byte
+}
+
+// maxKeys bounds the derived keys cached. Each encryption of a database
+// has a new salt and so a new key, and only the latest is used after.
+const maxKeys = 16
+
+// keys caches derived keys, least recently used first out, as scrypt is
+// deliberately slow and every connection to an encrypted database derives
+// its key.
+var keys struct {
+	mu  sync.Mutex
+	ids map[[32]byte]*list.Element
+	lru list.List // of *cachedKey, most recent first
+}
+
+type cachedKey struct {
+	id  [32]byte
+	key *Key
+}
+
+func cached(id [32]byte) *Key {
+	keys.mu.Lock()
+	defer keys.mu.Unlock()
+	if e, ok := keys.ids[id]; ok {
+		keys.lru.MoveToFront(e)
+		return e.Value.(*cachedKey).key
+	}
+	return nil
+}
+
+func cache(id [32]byte, k *Key) {
+	keys.mu.Lock()
+	defer keys.mu.Unlock()
+	if keys.ids == nil {
+		keys.ids = make(map[[32]byte]*list.Element)
+	}
+	if _, ok := keys.ids[id]; ok {
+		return
+	}
+	keys.ids[id] = keys.lru.PushFront(&cachedKey{id: id, key: k})
+	for keys.lru.Len() > maxKeys {
+		oldest := keys.lru.Remove(keys.lru.Back()).(*cachedKey)
+		delete(keys.ids, oldest.id)
+	}
+}
+
+// Derive returns the key for passphrase and salt.
+func Derive(passphrase string, salt []byte) (*Key, error) {
+	if passphrase == "" {
+		return nil, errors.New("empty encryption key")
+	}
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(passphrase))
+	var id [32]byte
+	copy(id[:], h.Sum(nil))
+	if k := cached(id); k != nil {
+		return k, nil
+	}
+
+	b, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 64)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(b[:32])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	k := &Key{aead: aead, mac: b[32:]}
+	cache(id, k)
+	return k, nil
+}
+
+// NewSalt returns a random salt for Derive.
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generating salt: %w", err)
+	}
+	return salt, nil
+}
+
+// Seal encrypts plain, authenticating ad with it, and appends the result
+// to dst. Each call uses a fresh random nonce.
+func (k *Key) Seal(dst, plain, ad []byte) ([]byte, error) {
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	dst = append(dst, nonce...)
+	return k.aead.Seal(dst, nonce, plain, ad), nil
+}
+
+// Open decrypts data sealed with the same key and ad, appending the
+// plaintext to dst.
+func (k *Key) Open(dst, sealed, ad []byte) ([]byte, error) {
+	n := k.aead.NonceSize()
+	if len(sealed) < n+k.aead.Overhead() {
+		return nil, ErrDecrypt
+	}
+	out, err := k.aead.Open(dst, sealed[:n], sealed[n:], ad)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return out, nil
+}
+
+// MAC returns a keyed hash of data, for looking up encrypted values by an
+// equal plaintext without storing it.
+func (k *Key) MAC(data []byte) []byte {
+	h := hmac.New(sha256.New, k.mac)
+	h.Write(data)
+	return h.Sum(nil)
+}
@@ -0,0 +1,107 @@
+package seal_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/internal/seal"
+)
+
+func TestKey_SealOpenRoundTrip(t *testing.T) {
+	salt, err := seal.NewSalt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := seal.Derive("correct horse", salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain := []byte("LS1 1AA,0.9,0.4")
+	box, err := k.Seal(nil, plain, []byte("chunk 0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(box) != len(plain)+seal.Overhead || bytes.Contains(box, plain) {
+		t.Fatalf("expected %d sealed bytes without the plain text, got %q", len(plain)+seal.Overhead, box)
+	}
+	again, _ := k.Seal(nil, plain, []byte("chunk 0"))
+	if bytes.Equal(box, again) {
+		t.Error("expected a fresh nonce for each seal")
+	}
+
+	// A key derived again, as each connection does, opens it.
+	k2, err := seal.Derive("correct horse", salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := k2.Open(nil, box, []byte("chunk 0"))
+	if err != nil || !bytes.Equal(got, plain) {
+		t.Errorf("expected %q back, got %q (err %v)", plain, got, err)
+	}
+	if _, err := seal.Derive("", salt); err == nil {
+		t.Error("expected an empty passphrase to be refused")
+	}
+}
+
+func TestKey_OpenRefusesWrongKey(t *testing.T) {
+	salt, _ := seal.NewSalt()
+	k, _ := seal.Derive("correct horse", salt)
+	box, err := k.Seal(nil, []byte("secret"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSalt, _ := seal.NewSalt()
+	for name, passphrase := range map[string]string{"passphrase": "battery staple", "salt": "correct horse"} {
+		s := salt
+		if name == "salt" {
+			s = otherSalt
+		}
+		wrong, err := seal.Derive(passphrase, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := wrong.Open(nil, box, nil); !errors.Is(err, seal.ErrDecrypt) {
+			t.Errorf("wrong %s: expected ErrDecrypt, got %v", name, err)
+		}
+	}
+}
+
+func TestKey_OpenRefusesTamperedChunks(t *testing.T) {
+	salt, _ := seal.NewSalt()
+	k, _ := seal.Derive("correct horse", salt)
+	box, err := k.Seal(nil, bytes.Repeat([]byte("page"), 1024), []byte("chunk 3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range []int{0, len(box) / 2, len(box) - 1} {
+		tampered := append([]byte(nil), box...)
+		tampered[i] ^= 1
+		if _, err := k.Open(nil, tampered, []byte("chunk 3")); !errors.Is(err, seal.ErrDecrypt) {
+			t.Errorf("byte %d flipped: expected ErrDecrypt, got %v", i, err)
+		}
+	}
+	if _, err := k.Open(nil, box[:len(box)-1], []byte("chunk 3")); !errors.Is(err, seal.ErrDecrypt) {
+		t.Errorf("truncated: expected ErrDecrypt, got %v", err)
+	}
+	if _, err := k.Open(nil, box[:seal.Overhead-1], []byte("chunk 3")); !errors.Is(err, seal.ErrDecrypt) {
+		t.Errorf("shorter than the overhead: expected ErrDecrypt, got %v", err)
+	}
+	// A chunk moved to another position fails its authenticated data.
+	if _, err := k.Open(nil, box, []byte("chunk 4")); !errors.Is(err, seal.ErrDecrypt) {
+		t.Errorf("moved: expected ErrDecrypt, got %v", err)
+	}
+}
+
+func TestKey_MACIsKeyed(t *testing.T) {
+	salt, _ := seal.NewSalt()
+	k, _ := seal.Derive("correct horse", salt)
+	other, _ := seal.Derive("battery staple", salt)
+	pc := []byte("SW1A1AA")
+	if !bytes.Equal(k.MAC(pc), k.MAC(pc)) {
+		t.Error("expected the same MAC for the same data")
+	}
+	if bytes.Equal(k.MAC(pc), other.MAC(pc)) || bytes.Equal(k.MAC(pc), k.MAC([]byte("SW1A1AB"))) {
+		t.Error("expected MACs to differ by key and data")
+	}
+}
@@ -0,0 +1,134 @@
+// Package sites keeps named postcodes — home, office, a holiday cottage —
+// in sites.db in the data directory, so the places someone checks again
+// and again can be checked together by name.
+package sites
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+// ErrNotFound is returned when no site has the given name.
+var ErrNotFound = errors.New("no such site")
+
+// Site is a saved, named postcode.
+type Site struct {
+	Name     string `json:"name"`
+	Postcode string `json:"postcode"`
+	AddedAt  string `json:"added_at"`
+}
+
+// Store manages the sites saved in sites.db. Names are unique, ignoring
+// case.
+type Store struct {
+	DBPath string
+}
+
+// New creates a Store using sites.db in dataDir.
+func New(dataDir string) *Store {
+	return &Store{DBPath: filepath.Join(dataDir, "sites.db")}
+}
+
+func (s *Store) open() (*sql.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(s.DBPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+	d := ofcom.DefaultDriver
+	db, err := sql.Open(d.Name, d.DSN(s.DBPath, false))
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS sites (
+		name TEXT PRIMARY KEY COLLATE NOCASE,
+		postcode TEXT NOT NULL,
+		added_at TEXT NOT NULL
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Add saves pc under name, replacing the postcode of a site already saved
+// under that name, which keeps its place in List. It reports whether one
+// was replaced.
+func (s *Store) Add(name, pc string) (*Site, bool, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, false, fmt.Errorf("site name must not be empty")
+	}
+	if err := postcode.Validate(pc); err != nil {
+		return nil, false, fmt.Errorf("postcode %q: %w", pc, err)
+	}
+	site := &Site{Name: name, Postcode: postcode.Normalise(pc), AddedAt: time.Now().UTC().Format(time.RFC3339)}
+
+	db, err := s.open()
+	if err != nil {
+		return nil, false, err
+	}
+	defer db.Close()
+	var existing int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sites WHERE name = ?`, name).Scan(&existing); err != nil {
+		return nil, false, err
+	}
+	_, err = db.Exec(`INSERT INTO sites (name, postcode, added_at) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET postcode = excluded.postcode, added_at = excluded.added_at`,
+		site.Name, site.Postcode, site.AddedAt)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to save site: %w", err)
+	}
+	return site, existing > 0, nil
+}
+
+// Remove deletes the site saved under name, or returns ErrNotFound.
+func (s *Store) Remove(name string) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	res, err := db.Exec(`DELETE FROM sites WHERE name = ?`, strings.TrimSpace(name))
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%q: %w", name, ErrNotFound)
+	}
+	return nil
+}
+
+// List returns the saved sites in the order they were first added. It
+// returns none, without creating sites.db, when nothing has been saved.
+func (s *Store) List() ([]Site, error) {
+	if _, err := os.Stat(s.DBPath); os.IsNotExist(err) {
+		return []Site{}, nil
+	}
+	db, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	rows, err := db.Query(`SELECT name, postcode, added_at FROM sites ORDER BY rowid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := []Site{}
+	for rows.Next() {
+		var site Site
+		if err := rows.Scan(&site.Name, &site.Postcode, &site.AddedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, site)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,38 @@
+package sites_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/internal/sites"
+)
+
+func TestStore_AddReplaceRemove(t *testing.T) {
+	s := sites.New(t.TempDir())
+	if list, err := s.List(); err != nil || len(list) != 0 {
+		t.Fatalf("expected no sites yet, got %v (err %v)", list, err)
+	}
+	for _, add := range [][2]string{{"home", "SW1A 1AA"}, {"office", "EC1A1BB"}} {
+		if _, replaced, err := s.Add(add[0], add[1]); err != nil || replaced {
+			t.Fatalf("add %s: replaced %v, err %v", add[0], replaced, err)
+		}
+	}
+	site, replaced, err := s.Add("Home", "ls11aa")
+	if err != nil || !replaced || site.Postcode != "LS11AA" {
+		t.Fatalf("expected Home to replace home, got %+v, %v, %v", site, replaced, err)
+	}
+	list, err := s.List()
+	if err != nil || len(list) != 2 || list[0].Name != "home" || list[0].Postcode != "LS11AA" || list[1].Name != "office" {
+		t.Errorf("expected home then office with home's new postcode, got %+v (err %v)", list, err)
+	}
+
+	if _, _, err := s.Add("garage", "not a postcode"); err == nil {
+		t.Error("expected an invalid postcode to be refused")
+	}
+	if err := s.Remove("office"); err != nil {
+		t.Errorf("remove failed: %v", err)
+	}
+	if err := s.Remove("office"); !errors.Is(err, sites.ErrNotFound) {
+		t.Errorf("expected ErrNotFound removing it again, got %v", err)
+	}
+}
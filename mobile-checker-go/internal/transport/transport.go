@@ -0,0 +1,61 @@
+// Package transport configures the HTTP transport behind every outbound
+// request — dataset downloads, postcodes.io, geocoders, webhooks — for
+// networks that reach the internet through a proxy with a private CA.
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// Options configures outbound HTTPS.
+type Options struct {
+	// CACert is a PEM file of certificate authorities to trust alongside
+	// the system's, e.g. the one a TLS-inspecting proxy signs with.
+	CACert string
+	// InsecureSkipVerify accepts any server certificate. It is for
+	// diagnosing certificate problems, never for regular use.
+	InsecureSkipVerify bool
+}
+
+// New returns a copy of http.DefaultTransport that trusts opts.CACert. Like
+// the default, it sends requests through the proxy named by HTTPS_PROXY or
+// HTTP_PROXY unless the host matches NO_PROXY.
+func New(opts Options) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyFromEnvironment
+	if opts.CACert == "" && !opts.InsecureSkipVerify {
+		return t, nil
+	}
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: opts.InsecureSkipVerify}
+	if opts.CACert != "" {
+		pem, err := os.ReadFile(opts.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in %s", opts.CACert)
+		}
+		cfg.RootCAs = pool
+	}
+	t.TLSClientConfig = cfg
+	return t, nil
+}
+
+// Install makes New(opts) http.DefaultTransport, which every HTTP client in
+// this module uses. Call it once at start-up, before any request.
+func Install(opts Options) error {
+	t, err := New(opts)
+	if err != nil {
+		return err
+	}
+	http.DefaultTransport = t
+	return nil
+}
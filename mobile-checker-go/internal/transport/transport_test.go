@@ -0,0 +1,50 @@
+package transport_test
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/internal/transport"
+)
+
+func TestNew_TrustsCACert(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	get := func(opts transport.Options) error {
+		tr, err := transport.New(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := (&http.Client{Transport: tr}).Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	if err := get(transport.Options{}); err == nil {
+		t.Error("expected the test server's certificate to be refused by default")
+	}
+	ca := filepath.Join(t.TempDir(), "ca.pem")
+	block := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(ca, block, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := get(transport.Options{CACert: ca}); err != nil {
+		t.Errorf("expected the CA certificate to be trusted, got %v", err)
+	}
+	if err := get(transport.Options{InsecureSkipVerify: true}); err != nil {
+		t.Errorf("expected verification to be skipped, got %v", err)
+	}
+
+	if err := os.WriteFile(ca, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := transport.New(transport.Options{CACert: ca}); err == nil {
+		t.Error("expected a file without certificates to be refused")
+	}
+}
@@ -0,0 +1,11 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/yourusername/mobile-checker/internal/checker"
+)
+
+// NewModel exposes the UI model to tests in package tui_test.
+func NewModel(c *checker.Checker) tea.Model {
+	return newModel(c)
+}
@@ -0,0 +1,252 @@
+// Package tui implements the interactive terminal UI for the mobile checker.
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+const maxHistory = 10
+
+var (
+	titleStyle   = lipgloss.NewStyle().Bold(true)
+	dimStyle     = lipgloss.NewStyle().Faint(true)
+	errorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	goodStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	badStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	selectedItem = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+)
+
+type resultMsg struct {
+	postcode string
+	result   checker.Result
+}
+
+type model struct {
+	checker   *checker.Checker
+	input     textinput.Model
+	history   []string // most recent first
+	cursor    int      // selected history entry, -1 when none
+	current   *checker.Result
+	loading   string
+	indoor    bool
+	years     []string
+	yearIdx   int
+	installed map[string]bool
+}
+
+// Run starts the interactive UI and blocks until the user quits.
+func Run(c *checker.Checker) error {
+	_, err := tea.NewProgram(newModel(c), tea.WithAltScreen()).Run()
+	return err
+}
+
+func newModel(c *checker.Checker) model {
+	in := textinput.New()
+	in.Placeholder = "Enter a postcode, e.g. SW1A1AA"
+	in.CharLimit = 10
+	in.Focus()
+
+	installed := make(map[string]bool)
+	for _, y := range c.InstalledYears() {
+		installed[y] = true
+	}
+	var years []string
+	for y := range ofcom.MobileDataURLs {
+		years = append(years, y)
+	}
+	for y := range installed {
+		if _, ok := ofcom.MobileDataURLs[y]; !ok {
+			years = append(years, y)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(years)))
+	yearIdx := 0
+	for i, y := range years {
+		if installed[y] {
+			yearIdx = i
+			break
+		}
+	}
+
+	return model{
+		checker:   c,
+		input:     in,
+		cursor:    -1,
+		years:     years,
+		yearIdx:   yearIdx,
+		installed: installed,
+	}
+}
+
+func (m model) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			return m, tea.Quit
+		case tea.KeyEnter:
+			pc := postcode.Normalise(m.input.Value())
+			if pc == "" {
+				return m, nil
+			}
+			m.input.SetValue("")
+			m.addHistory(pc)
+			m.cursor = 0
+			return m.lookup(pc)
+		case tea.KeyTab:
+			m.indoor = !m.indoor
+			return m.refresh()
+		case tea.KeyCtrlY:
+			if len(m.years) > 0 {
+				m.yearIdx = (m.yearIdx + 1) % len(m.years)
+			}
+			return m.refresh()
+		case tea.KeyUp:
+			if m.cursor < len(m.history)-1 {
+				m.cursor++
+				return m.lookup(m.history[m.cursor])
+			}
+			return m, nil
+		case tea.KeyDown:
+			if m.cursor > 0 {
+				m.cursor--
+				return m.lookup(m.history[m.cursor])
+			}
+			return m, nil
+		}
+	case resultMsg:
+		if msg.postcode == m.loading {
+			res := msg.result
+			m.current = &res
+			m.loading = ""
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *model) addHistory(pc string) {
+	for i, h := range m.history {
+		if h == pc {
+			m.history = append(m.history[:i], m.history[i+1:]...)
+			break
+		}
+	}
+	m.history = append([]string{pc}, m.history...)
+	if len(m.history) > maxHistory {
+		m.history = m.history[:maxHistory]
+	}
+}
+
+// refresh re-runs the selected lookup after a mode change.
+func (m model) refresh() (tea.Model, tea.Cmd) {
+	if m.cursor < 0 || m.cursor >= len(m.history) {
+		return m, nil
+	}
+	return m.lookup(m.history[m.cursor])
+}
+
+func (m model) lookup(pc string) (tea.Model, tea.Cmd) {
+	m.loading = pc
+	c, opts := m.checker, checker.CheckOptions{Indoor: m.indoor, Year: m.year()}
+	return m, func() tea.Msg {
+		return resultMsg{postcode: pc, result: c.CheckWith(pc, opts)}
+	}
+}
+
+func (m model) year() string {
+	if len(m.years) == 0 {
+		return ""
+	}
+	return m.years[m.yearIdx]
+}
+
+func (m model) View() string {
+	var b strings.Builder
+
+	mode := "outdoor"
+	if m.indoor {
+		mode = "indoor"
+	}
+	b.WriteString(titleStyle.Render("UK Mobile Coverage Checker"))
+	b.WriteString(dimStyle.Render(fmt.Sprintf("   year: %s   mode: %s", m.year(), mode)))
+	b.WriteString("\n\n")
+	b.WriteString(m.input.View())
+	b.WriteString("\n\n")
+
+	switch {
+	case m.year() != "" && !m.installed[m.year()]:
+		b.WriteString(errorStyle.Render(fmt.Sprintf("Dataset %s is not installed — run: mobile-checker setup --year %s", m.year(), m.year())))
+		b.WriteString("\n")
+	case m.loading != "":
+		b.WriteString(dimStyle.Render("Looking up " + m.loading + "..."))
+		b.WriteString("\n")
+	case m.current != nil:
+		b.WriteString(renderResult(*m.current))
+	}
+
+	if len(m.history) > 0 {
+		b.WriteString("\n" + titleStyle.Render("Recent") + "\n")
+		for i, pc := range m.history {
+			if i == m.cursor {
+				b.WriteString(selectedItem.Render("› "+pc) + "\n")
+			} else {
+				b.WriteString("  " + pc + "\n")
+			}
+		}
+	}
+
+	b.WriteString("\n" + dimStyle.Render("enter: look up • ↑/↓: history • tab: indoor/outdoor • ctrl+y: year • esc: quit"))
+	return b.String()
+}
+
+func renderResult(r checker.Result) string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(r.Postcode) + "\n")
+	if r.Error != "" {
+		return b.String() + errorStyle.Render(r.Error) + "\n"
+	}
+	if g := r.Geographic; g != nil {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("%s, %s, %s", g.AdminDistrict, g.Region, g.Country)) + "\n")
+	}
+	if r.Note != "" {
+		b.WriteString(errorStyle.Render(r.Note) + "\n")
+	}
+	if r.Mobile == nil {
+		return b.String()
+	}
+
+	b.WriteString(fmt.Sprintf("\n%-10s %-10s %-10s %-10s\n", "Operator", "Voice", "4G", "5G"))
+	for _, op := range r.Mobile.Operators {
+		b.WriteString(fmt.Sprintf("%-10s %s %s %s\n", op.Name,
+			cell(op.HasVoice, op.Voice), cell(op.HasFourG, op.FourG), cell(op.HasFiveG, op.FiveG)))
+	}
+	b.WriteString(fmt.Sprintf("\n4G operators: %d/4   5G operators: %d/4\n",
+		r.Mobile.Overall.FourGCount, r.Mobile.Overall.FiveGCount))
+	b.WriteString(fmt.Sprintf("Coverage score: %d/100 (grade %s)\n", r.Mobile.CoverageScore, r.Mobile.Grade))
+	return b.String()
+}
+
+func cell(ok bool, pct string) string {
+	s := fmt.Sprintf("%-10s", "✗ "+pct)
+	if ok {
+		return goodStyle.Render(fmt.Sprintf("%-10s", "✓ "+pct))
+	}
+	return badStyle.Render(s)
+}
@@ -0,0 +1,63 @@
+package tui_test
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/tui"
+)
+
+// press sends msg to m and runs any command it returns, feeding the
+// resulting message back, as the bubbletea runtime would.
+func press(t *testing.T, m tea.Model, msg tea.Msg) tea.Model {
+	t.Helper()
+	m, cmd := m.Update(msg)
+	if cmd != nil {
+		if next := cmd(); next != nil {
+			m, _ = m.Update(next)
+		}
+	}
+	return m
+}
+
+func TestYearSwitchChecksThatYear(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"ofcom_mobile_2023.csv":            "postcode,ee_4g\nLS11AA,0.4\n",
+		"years/2022/ofcom_mobile_2022.csv": "postcode,ee_4g\nLS11AA,0.9\n",
+	}
+	for name, csv := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(csv), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	m := ofcom.NewManager(dir, ofcom.WithLogger(logger))
+	for _, year := range []string{"2023", "2022"} {
+		if err := m.Setup(year, ofcom.SetupOptions{}); err != nil {
+			t.Fatalf("setup %s failed: %v", year, err)
+		}
+	}
+
+	// Offline without geographic data, checks use the dataset alone.
+	var model tea.Model = tui.NewModel(checker.New(dir, checker.WithOffline(), checker.WithLogger(logger)))
+	model = press(t, model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("LS11AA")})
+	model = press(t, model, tea.KeyMsg{Type: tea.KeyEnter})
+	if view := model.View(); !strings.Contains(view, "year: 2023") || !strings.Contains(view, "40%") {
+		t.Fatalf("expected 2023 coverage of 40%%, got:\n%s", view)
+	}
+
+	model = press(t, model, tea.KeyMsg{Type: tea.KeyCtrlY})
+	if view := model.View(); !strings.Contains(view, "year: 2022") || !strings.Contains(view, "90%") {
+		t.Fatalf("expected 2022 coverage of 90%% after ctrl+y, got:\n%s", view)
+	}
+}
@@ -0,0 +1,300 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+)
+
+// Result is the server's check result for one postcode. Err is set from
+// Code for failed checks and checks with a caveat, e.g. NOT_IN_DATASET.
+type Result = checker.Result
+
+// MaxBulk is how many postcodes the server checks per bulk request;
+// CheckBulk sends larger lists in batches of this size.
+const MaxBulk = 50
+
+// CheckOptions are the query options of a check.
+type CheckOptions struct {
+	// Operators limits results to these operators or MVNO brands, e.g.
+	// "ee" or "giffgaff".
+	Operators []string
+	// NoEstimate disables estimates from nearby postcodes for postcodes
+	// missing from the dataset.
+	NoEstimate bool
+	// Year checks an older dataset year installed on the server.
+	Year string
+}
+
+func (o CheckOptions) query() url.Values {
+	q := url.Values{}
+	if len(o.Operators) > 0 {
+		q.Set("operators", strings.Join(o.Operators, ","))
+	}
+	if o.NoEstimate {
+		q.Set("estimate", "false")
+	}
+	if o.Year != "" {
+		q.Set("year", o.Year)
+	}
+	return q
+}
+
+// RetryPolicy controls how failed requests are retried. Transport errors,
+// 429 and 5xx responses are retried with exponential backoff and full
+// jitter; a Retry-After header, up to MaxDelay, is honoured instead.
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts per request, including the first
+	BaseDelay   time.Duration // backoff before the first retry
+	MaxDelay    time.Duration // cap on any single backoff
+}
+
+// DefaultRetryPolicy makes up to three attempts.
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: 200 * time.Millisecond, MaxDelay: 5 * time.Second}
+
+// Client calls a mobile-checker server. It is safe for concurrent use.
+type Client struct {
+	baseURL string
+	http    *http.Client
+	retry   RetryPolicy
+	header  http.Header
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client, e.g. one with a custom transport.
+// The default has a 30 second timeout.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// WithRetry sets the retry policy. MaxAttempts of 1 disables retries.
+func WithRetry(p RetryPolicy) Option {
+	return func(c *Client) { c.retry = p }
+}
+
+// WithHeader adds a header to every request, e.g. credentials for a proxy
+// in front of the server.
+func WithHeader(key, value string) Option {
+	return func(c *Client) { c.header.Add(key, value) }
+}
+
+// New creates a Client for the server at baseURL, e.g.
+// https://coverage.example.com.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: 30 * time.Second},
+		retry:   DefaultRetryPolicy,
+		header:  http.Header{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Check returns coverage for a UK postcode. A failed check returns an
+// *Error. A result with a caveat is returned together with its error: a
+// terminated postcode (coverage.ErrTerminated, with Result.Terminated
+// describing the nearest active postcode), one missing from the dataset
+// (coverage.ErrNotInDataset) or one checked while postcodes.io was down
+// (coverage.ErrUpstream).
+func (c *Client) Check(ctx context.Context, pc string) (*Result, error) {
+	return c.CheckWith(ctx, pc, CheckOptions{})
+}
+
+// CheckWith is Check with options.
+func (c *Client) CheckWith(ctx context.Context, pc string, opts CheckOptions) (*Result, error) {
+	u := c.baseURL + "/api/mobile/" + url.PathEscape(pc)
+	if q := opts.query(); len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	var body struct {
+		Result *Result `json:"result"`
+	}
+	err := c.do(ctx, pc, http.MethodGet, u, nil, &body)
+	if body.Result == nil {
+		return nil, err
+	}
+	res := body.Result
+	if err == nil {
+		setErr(res, http.StatusOK)
+		if res.Err != nil {
+			err = res.Err
+		}
+	} else {
+		res.Err = err
+	}
+	return res, err
+}
+
+// CheckBulk checks postcodes, in batches of MaxBulk. Results are in input
+// order, with each failed check's error in its Err and Code; the error
+// returned is for a batch request that failed as a whole, and the results
+// of earlier batches are returned with it.
+func (c *Client) CheckBulk(ctx context.Context, postcodes []string) ([]Result, error) {
+	return c.CheckBulkWith(ctx, postcodes, CheckOptions{})
+}
+
+// CheckBulkWith is CheckBulk with options.
+func (c *Client) CheckBulkWith(ctx context.Context, postcodes []string, opts CheckOptions) ([]Result, error) {
+	u := c.baseURL + "/api/mobile/bulk"
+	if q := opts.query(); len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	results := make([]Result, 0, len(postcodes))
+	for start := 0; start < len(postcodes); start += MaxBulk {
+		batch := postcodes[start:min(start+MaxBulk, len(postcodes))]
+		req, err := json.Marshal(map[string][]string{"postcodes": batch})
+		if err != nil {
+			return results, err
+		}
+		var body struct {
+			Results []Result `json:"results"`
+		}
+		if err := c.do(ctx, "", http.MethodPost, u, req, &body); err != nil {
+			return results, err
+		}
+		if len(body.Results) != len(batch) {
+			return results, &Error{StatusCode: http.StatusOK, Message: fmt.Sprintf("expected %d results, got %d", len(batch), len(body.Results))}
+		}
+		for i := range body.Results {
+			setErr(&body.Results[i], 0)
+		}
+		results = append(results, body.Results...)
+	}
+	return results, nil
+}
+
+// setErr sets r.Err, which JSON does not carry, to an *Error for r.Code.
+func setErr(r *Result, status int) {
+	if r.Code == "" {
+		return
+	}
+	msg := r.Error
+	if msg == "" {
+		msg = r.Note
+	}
+	r.Err = codedError(r.Postcode, status, r.Code, msg)
+}
+
+// do sends a request, retrying according to the client's policy, and
+// decodes the response envelope into out. A response with an error status
+// is an *Error; out is still decoded, since some error responses carry a
+// result.
+func (c *Client) do(ctx context.Context, pc, method, u string, body []byte, out any) error {
+	attempts := max(c.retry.MaxAttempts, 1)
+	var lastErr *Error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			if err := c.wait(ctx, i, lastErr); err != nil {
+				return err
+			}
+		}
+		req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		for k, v := range c.header {
+			req.Header[k] = v
+		}
+		req.Header.Set("Accept", "application/json")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			lastErr = &Error{Postcode: pc, Message: err.Error(), Err: ErrUnavailable}
+			continue
+		}
+		lastErr = decode(resp, pc, out)
+		if lastErr == nil || !retryable(resp.StatusCode) {
+			break
+		}
+	}
+	if lastErr == nil {
+		return nil
+	}
+	return lastErr
+}
+
+// decode reads resp's envelope into out, returning an *Error unless the
+// request succeeded.
+func decode(resp *http.Response, pc string, out any) *Error {
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<20))
+	if err != nil {
+		return &Error{Postcode: pc, StatusCode: resp.StatusCode, Message: err.Error(), Err: ErrUnavailable}
+	}
+	var env struct {
+		Status  string `json:"status"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	if json.Unmarshal(data, &env) != nil {
+		e := codedError(pc, resp.StatusCode, "", http.StatusText(resp.StatusCode))
+		e.Message = fmt.Sprintf("unexpected %s response", resp.Status)
+		return e
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return &Error{Postcode: pc, StatusCode: resp.StatusCode, Message: err.Error()}
+	}
+	retryAfter := resp.Header.Get("Retry-After")
+	if resp.StatusCode < 300 && env.Status != "error" {
+		return nil
+	}
+	msg := env.Message
+	if msg == "" {
+		msg = env.Error // plain error bodies, e.g. 413 and 405
+	}
+	e := codedError(pc, resp.StatusCode, checker.ErrorCode(env.Code), msg)
+	if secs, err := strconv.Atoi(retryAfter); err == nil {
+		e.retryAfter = time.Duration(secs) * time.Second
+	}
+	return e
+}
+
+// wait sleeps before retry number retry, returning early with ctx's error.
+func (c *Client) wait(ctx context.Context, retry int, last *Error) error {
+	d := c.retry.BaseDelay << (retry - 1)
+	if last != nil && last.retryAfter > 0 {
+		d = last.retryAfter
+	}
+	if c.retry.MaxDelay > 0 && (d > c.retry.MaxDelay || d < 0) {
+		d = c.retry.MaxDelay
+	}
+	if last == nil || last.retryAfter == 0 {
+		if d > 0 {
+			d = time.Duration(rand.Int63n(int64(d) + 1))
+		}
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
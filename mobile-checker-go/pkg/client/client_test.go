@@ -0,0 +1,98 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yourusername/mobile-checker/api"
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/pkg/client"
+	"github.com/yourusername/mobile-checker/pkg/coverage"
+)
+
+func TestClient_AgainstServer(t *testing.T) {
+	fixtures := checker.Fixtures{
+		"SW1A1AA": {Postcode: "SW1A1AA", Valid: true, Mobile: &ofcom.MobileSummary{Postcode: "SW1A1AA", CoverageScore: 90}},
+		"LS11AB": {
+			Postcode: "LS11AB", Valid: true, Note: "Postcode not found in Ofcom mobile dataset",
+			Code: checker.CodeNotInDataset,
+		},
+	}
+	srv := api.NewServer(t.TempDir(), api.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))), api.WithFixtures(fixtures))
+	defer srv.Close()
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+	c := client.New(ts.URL + "/")
+	ctx := context.Background()
+
+	res, err := c.Check(ctx, "sw1a 1aa")
+	if err != nil || res.Mobile == nil || res.Mobile.CoverageScore != 90 {
+		t.Fatalf("expected the server's result, got %+v (err %v)", res, err)
+	}
+
+	_, err = c.Check(ctx, "EC1A 1BB")
+	var ce *client.Error
+	if !errors.As(err, &ce) || ce.Code != checker.CodePostcodeNotFound || ce.StatusCode != http.StatusNotFound || !errors.Is(err, coverage.ErrPostcodeNotFound) {
+		t.Errorf("expected a typed POSTCODE_NOT_FOUND error, got %#v", err)
+	}
+
+	res, err = c.Check(ctx, "LS1 1AB")
+	if res == nil || !errors.Is(err, coverage.ErrNotInDataset) || !errors.Is(res.Err, coverage.ErrNotInDataset) {
+		t.Errorf("expected a result with ErrNotInDataset, got %+v (err %v)", res, err)
+	}
+
+	postcodes := make([]string, client.MaxBulk+2)
+	for i := range postcodes {
+		postcodes[i] = "SW1A1AA"
+	}
+	postcodes[client.MaxBulk+1] = "not a postcode"
+	results, err := c.CheckBulk(ctx, postcodes)
+	if err != nil || len(results) != len(postcodes) {
+		t.Fatalf("expected %d results, got %d (err %v)", len(postcodes), len(results), err)
+	}
+	if last := results[len(results)-1]; !errors.Is(last.Err, coverage.ErrInvalidPostcode) || results[0].Err != nil {
+		t.Errorf("expected only the last result to fail, got %+v and %+v", results[0], last)
+	}
+}
+
+func TestClient_Retries(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"status":"error","message":"busy"}`)
+			return
+		}
+		fmt.Fprint(w, `{"status":"ok","result":{"postcode":"SW1A1AA","valid":true}}`)
+	}))
+	defer ts.Close()
+
+	policy := client.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+	res, err := client.New(ts.URL, client.WithRetry(policy)).Check(context.Background(), "SW1A1AA")
+	if err != nil || res.Postcode != "SW1A1AA" || calls.Load() != 3 {
+		t.Fatalf("expected success on the third attempt, got %+v (err %v, %d calls)", res, err, calls.Load())
+	}
+
+	calls.Store(0)
+	policy.MaxAttempts = 2
+	_, err = client.New(ts.URL, client.WithRetry(policy)).Check(context.Background(), "SW1A1AA")
+	if !errors.Is(err, client.ErrUnavailable) || calls.Load() != 2 {
+		t.Errorf("expected ErrUnavailable after 2 attempts, got %v (%d calls)", err, calls.Load())
+	}
+
+	ts.Close()
+	_, err = client.New(ts.URL, client.WithRetry(policy)).CheckBulk(context.Background(), []string{"SW1A1AA"})
+	if !errors.Is(err, client.ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable from a stopped server, got %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+// Package client is a Go client for the mobile-checker REST API, for
+// services that call a mobile-checker server rather than holding the Ofcom
+// dataset themselves (for that, see package coverage).
+//
+//	c := client.New("https://coverage.example.com")
+//	res, err := c.Check(ctx, "SW1A 1AA")
+//	switch {
+//	case errors.Is(err, coverage.ErrPostcodeNotFound):
+//		// unknown postcode
+//	case errors.Is(err, client.ErrUnavailable):
+//		// the server could not be reached, even after retries
+//	case err != nil && res == nil:
+//		return err
+//	}
+//	fmt.Println(res.Mobile.CoverageScore)
+//
+// Results are the server's own result type, so fields cannot drift from
+// what it sends. Failed checks return an *Error carrying the server's error
+// code; it wraps the matching package coverage error, so errors.Is works
+// the same against a server as against a local dataset.
+//
+// # Stability
+//
+// This package follows the same semantic versioning promise as package
+// coverage.
+package client
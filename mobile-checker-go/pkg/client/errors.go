@@ -0,0 +1,58 @@
+package client
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/pkg/coverage"
+)
+
+// ErrUnavailable means the server could not be reached, or kept answering
+// 429 or 5xx until retries ran out.
+var ErrUnavailable = errors.New("mobile-checker server unavailable")
+
+// Error is returned for a failed check or request. It wraps the package
+// coverage error matching Code, e.g. coverage.ErrPostcodeNotFound, or
+// ErrUnavailable.
+type Error struct {
+	// Postcode is the postcode checked, or empty for a whole request.
+	Postcode string
+	// StatusCode is the HTTP status, or 0 if no response was received.
+	StatusCode int
+	// Code is the server's error code, e.g. "POSTCODE_NOT_FOUND".
+	Code    coverage.ErrorCode
+	Message string
+	Err     error
+
+	retryAfter time.Duration // from a Retry-After header
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" && e.Err != nil {
+		msg = e.Err.Error()
+	}
+	if msg == "" {
+		msg = "HTTP " + strconv.Itoa(e.StatusCode)
+	}
+	if e.Postcode == "" {
+		return msg
+	}
+	return e.Postcode + ": " + msg
+}
+
+// Unwrap returns the underlying cause.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// codedError builds the *Error for a server error code.
+func codedError(pc string, status int, code coverage.ErrorCode, msg string) *Error {
+	e := &Error{Postcode: pc, StatusCode: status, Code: code, Message: msg, Err: checker.ErrorFor(code)}
+	if e.Err == nil && retryable(status) {
+		e.Err = ErrUnavailable
+	}
+	return e
+}
@@ -0,0 +1,279 @@
+package coverage
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/ofcomapi"
+	"github.com/yourusername/mobile-checker/internal/pccache"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+type (
+	// Geographic is postcodes.io location data for a postcode.
+	Geographic = postcode.Result
+	// MobileSummary is per-operator coverage for a postcode.
+	MobileSummary = ofcom.MobileSummary
+	// OperatorCoverage is the coverage of a single operator.
+	OperatorCoverage = ofcom.OperatorCoverage
+	// AreaSummary aggregates coverage over a district, region or country.
+	AreaSummary = ofcom.AreaSummary
+	// SetupOptions controls dataset download and build.
+	SetupOptions = ofcom.SetupOptions
+	// CheckOptions controls how coverage is interpreted.
+	CheckOptions = checker.CheckOptions
+	// Source is an additional coverage dataset; see WithSource.
+	Source = checker.CoverageSource
+	// SourceResult is the coverage reported by an additional Source.
+	SourceResult = checker.SourceResult
+	// Recommender suggests tariffs for a postcode's coverage; see
+	// WithRecommender.
+	Recommender = checker.Recommender
+	// Tariff is one recommended SIM or tariff.
+	Tariff = checker.Tariff
+	// Recommendations are one Recommender's tariffs for a postcode.
+	Recommendations = checker.Recommendations
+)
+
+// Result is the outcome of a successful coverage check.
+type Result struct {
+	Postcode   string         `json:"postcode"`
+	Geographic *Geographic    `json:"geographic,omitempty"`
+	Mobile     *MobileSummary `json:"mobile,omitempty"`
+	// Sources holds coverage from sources added with WithSource.
+	Sources []SourceResult `json:"sources,omitempty"`
+	// Recommendations holds tariffs from recommenders added with
+	// WithRecommender.
+	Recommendations []Recommendations `json:"recommendations,omitempty"`
+}
+
+// Client checks mobile coverage. It is safe for concurrent use.
+type Client struct {
+	checker *checker.Checker
+}
+
+type config struct {
+	dataDir string
+	logger  *slog.Logger
+	sources []Source
+	// recommenders suggest tariffs after each check.
+	recommenders []Recommender
+	// fallbackURL is the server checks go to while the dataset is missing.
+	fallbackURL string
+	remoteDB    string
+	encryptKey  string
+	// postcodeOpts configure the postcodes.io client, e.g. its URL.
+	postcodeOpts []postcode.Option
+	// postcodesCacheTTL keeps postcodes.io lookups on disk; 0 disables it.
+	postcodesCacheTTL time.Duration
+	// ofcomAPIKey, when set, makes Ofcom's API the source of coverage.
+	ofcomAPIKey string
+}
+
+// Option configures a Client.
+type Option func(*config)
+
+// WithDataDir sets the directory holding the Ofcom database.
+// The default is ~/.mobile-checker/data, shared with the CLI.
+func WithDataDir(dir string) Option {
+	return func(c *config) { c.dataDir = dir }
+}
+
+// WithLogger sets the logger for setup progress and diagnostics.
+// The default is slog.Default().
+func WithLogger(l *slog.Logger) Option {
+	return func(c *config) { c.logger = l }
+}
+
+// WithSource adds a coverage source consulted on every check alongside the
+// Ofcom dataset. Its results appear in Result.Sources.
+func WithSource(src Source) Option {
+	return func(c *config) { c.sources = append(c.sources, src) }
+}
+
+// WithRecommender adds a recommender asked for tariffs suited to every
+// postcode with coverage, e.g. from a comparison site's own data. Its
+// tariffs appear in Result.Recommendations; a failure leaves a note there
+// rather than failing the check.
+func WithRecommender(r Recommender) Option {
+	return func(c *config) { c.recommenders = append(c.recommenders, r) }
+}
+
+// WithFallbackURL sends checks to the mobile-checker server at url while
+// the local dataset has not been set up, so a Client needs no database of
+// its own.
+func WithFallbackURL(url string) Option {
+	return func(c *config) { c.fallbackURL = url }
+}
+
+// WithRemoteDataset reads the Ofcom database from url, a mobile.db
+// published on S3 or any HTTP server that supports range requests,
+// instead of from the data directory. Only the pages a check needs are
+// downloaded, so a Client needs no setup.
+func WithRemoteDataset(url string) Option {
+	return func(c *config) { c.remoteDB = url }
+}
+
+// WithEncryptionKey reads and writes a dataset encrypted at rest with a
+// key derived from passphrase, as built by 'mobile-checker setup
+// --encrypt-key-file'.
+func WithEncryptionKey(passphrase string) Option {
+	return func(c *config) { c.encryptKey = passphrase }
+}
+
+// WithOfcomAPIKey answers checks from Ofcom's coverage API
+// (api.ofcom.org.uk) with key instead of the local dataset, so a Client
+// needs no setup. Requests are spaced to stay within the key's quota.
+func WithOfcomAPIKey(key string) Option {
+	return func(c *config) { c.ofcomAPIKey = key }
+}
+
+// WithPostcodesURL sends postcode lookups to a postcodes.io-compatible
+// service at url, e.g. a self-hosted mirror, instead of the public API.
+func WithPostcodesURL(url string) Option {
+	return func(c *config) { c.postcodeOpts = append(c.postcodeOpts, postcode.WithBaseURL(url)) }
+}
+
+// WithPostcodesHeader adds a header to every postcodes.io request, e.g.
+// credentials for a self-hosted instance.
+func WithPostcodesHeader(key, value string) Option {
+	return func(c *config) { c.postcodeOpts = append(c.postcodeOpts, postcode.WithHeader(key, value)) }
+}
+
+// WithPostcodesTimeout limits each postcodes.io request attempt. The
+// default is 10 seconds.
+func WithPostcodesTimeout(d time.Duration) Option {
+	return func(c *config) { c.postcodeOpts = append(c.postcodeOpts, postcode.WithTimeout(d)) }
+}
+
+// WithPostcodesCache keeps postcodes.io lookups in postcodes-cache.db in
+// the data directory for ttl, shared with the CLI and server, so repeated
+// checks of the same postcodes make no requests.
+func WithPostcodesCache(ttl time.Duration) Option {
+	return func(c *config) { c.postcodesCacheTTL = ttl }
+}
+
+// New creates a Client.
+func New(opts ...Option) *Client {
+	home, _ := os.UserHomeDir()
+	cfg := config{
+		dataDir: filepath.Join(home, ".mobile-checker", "data"),
+		logger:  slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	copts := []checker.Option{checker.WithLogger(cfg.logger)}
+	for _, src := range cfg.sources {
+		copts = append(copts, checker.WithSource(src))
+	}
+	for _, r := range cfg.recommenders {
+		copts = append(copts, checker.WithRecommender(r))
+	}
+	if cfg.remoteDB != "" {
+		copts = append(copts, checker.WithRemoteDataset(cfg.remoteDB, ofcom.RemoteOptions{}))
+	}
+	if cfg.encryptKey != "" {
+		copts = append(copts, checker.WithEncryptionKey(cfg.encryptKey))
+	}
+	if cfg.fallbackURL != "" {
+		copts = append(copts, checker.WithFallback(cfg.fallbackURL))
+	}
+	if cfg.ofcomAPIKey != "" {
+		copts = append(copts, checker.WithPrimarySource(ofcomapi.New(cfg.ofcomAPIKey)))
+	}
+	if cfg.postcodesCacheTTL > 0 {
+		cfg.postcodeOpts = append(cfg.postcodeOpts, postcode.WithCache(pccache.New(cfg.dataDir, cfg.postcodesCacheTTL, pccache.WithLogger(cfg.logger))))
+	}
+	if len(cfg.postcodeOpts) > 0 {
+		copts = append(copts, checker.WithPostcodeClient(postcode.NewClient(cfg.postcodeOpts...)))
+	}
+	return &Client{checker: checker.New(cfg.dataDir, copts...)}
+}
+
+// Setup downloads and builds the Ofcom dataset for year if needed.
+func (c *Client) Setup(ctx context.Context, year string, opts SetupOptions) error {
+	return run(ctx, func() error { return c.checker.Setup(year, opts) })
+}
+
+// Check returns coverage for a UK postcode.
+//
+// When the postcode is valid but coverage cannot be determined, Check
+// returns a Result with Geographic set together with an error matching
+// ErrNotInDataset, ErrDatasetMissing or ErrDatasetOutdated. When the
+// postcode has been terminated, it returns the Result for the nearest active
+// postcode, if any, with an error matching ErrTerminated.
+func (c *Client) Check(ctx context.Context, pc string) (*Result, error) {
+	var r checker.Result
+	if err := run(ctx, func() error { r = c.checker.CheckContext(ctx, pc, checker.CheckOptions{}); return nil }); err != nil {
+		return nil, wrap(pc, err)
+	}
+	return convert(r)
+}
+
+// CheckMany checks several postcodes concurrently. Results and errors are
+// in input order; each error, if any, is an *Error.
+func (c *Client) CheckMany(ctx context.Context, postcodes []string) ([]*Result, []error) {
+	results := make([]*Result, len(postcodes))
+	errs := make([]error, len(postcodes))
+	done := make([]bool, len(postcodes))
+	for item := range c.checker.Stream(ctx, postcodes, checker.DefaultWorkers) {
+		results[item.Index], errs[item.Index] = convert(item.Result)
+		done[item.Index] = true
+	}
+	for i, ok := range done {
+		if !ok {
+			errs[i] = wrap(postcodes[i], ctx.Err())
+		}
+	}
+	return results, errs
+}
+
+// Aggregate returns coverage statistics for an area. level is one of
+// "country", "region", "district" or "constituency". It returns nil when no
+// postcodes match, and ErrNoGeoData if the dataset has not been geocoded.
+func (c *Client) Aggregate(ctx context.Context, level, name string) (*AreaSummary, error) {
+	var s *AreaSummary
+	err := run(ctx, func() (err error) { s, err = c.checker.Aggregate(level, name); return err })
+	return s, err
+}
+
+func convert(r checker.Result) (*Result, error) {
+	if t := r.Terminated; t != nil && t.Nearest != nil && t.Nearest.Error == "" {
+		n := t.Nearest
+		return &Result{Postcode: n.Postcode, Geographic: n.Geographic, Mobile: n.Mobile, Sources: n.Sources, Recommendations: n.Recommendations}, wrap(r.Postcode, r.Err)
+	}
+	if r.Error != "" {
+		if r.Err == nil {
+			r.Err = errors.New(r.Error)
+		}
+		return nil, wrap(r.Postcode, r.Err)
+	}
+	res := &Result{Postcode: r.Postcode, Geographic: r.Geographic, Mobile: r.Mobile, Sources: r.Sources, Recommendations: r.Recommendations}
+	if r.Err != nil {
+		return res, wrap(r.Postcode, r.Err)
+	}
+	return res, nil
+}
+
+// run calls fn, returning early with ctx.Err() if ctx is cancelled first.
+// fn keeps running in the background until it completes.
+func run(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,105 @@
+package coverage_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yourusername/mobile-checker/pkg/coverage"
+)
+
+func TestAggregate_DatasetMissing(t *testing.T) {
+	c := coverage.New(coverage.WithDataDir(t.TempDir()))
+	_, err := c.Aggregate(context.Background(), "region", "London")
+	if !errors.Is(err, coverage.ErrDatasetMissing) {
+		t.Errorf("expected ErrDatasetMissing, got %v", err)
+	}
+}
+
+func TestSetup_CancelledContext(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte("postcode,ee_4g\nSW1A1AA,1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c := coverage.New(coverage.WithDataDir(dir))
+	if err := c.Setup(ctx, "2023", coverage.SetupOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if err := c.Setup(context.Background(), "2023", coverage.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	if _, err := c.Aggregate(context.Background(), "region", "London"); !errors.Is(err, coverage.ErrNoGeoData) {
+		t.Errorf("expected ErrNoGeoData once installed without geographic data, got %v", err)
+	}
+}
+
+func TestCheck_InvalidPostcodeCode(t *testing.T) {
+	c := coverage.New(coverage.WithDataDir(t.TempDir()))
+	_, err := c.Check(context.Background(), "not a postcode")
+	var ce *coverage.Error
+	if !errors.As(err, &ce) || !errors.Is(err, coverage.ErrInvalidPostcode) {
+		t.Fatalf("expected *Error wrapping ErrInvalidPostcode, got %v", err)
+	}
+	if ce.Code() != "INVALID_POSTCODE" {
+		t.Errorf("expected INVALID_POSTCODE, got %s", ce.Code())
+	}
+}
+
+type fakeSource struct{ setup bool }
+
+func (f *fakeSource) Name() string { return "fake" }
+
+func (f *fakeSource) Setup(string, coverage.SetupOptions) error { f.setup = true; return nil }
+
+func (f *fakeSource) Query(pc string) (map[string]string, error) {
+	if pc != "SW1A1AA" {
+		return nil, nil
+	}
+	return map[string]string{"ee_5g": "1"}, nil
+}
+
+func (f *fakeSource) Interpret(row map[string]string, _ coverage.CheckOptions) coverage.MobileSummary {
+	return coverage.MobileSummary{Operators: []coverage.OperatorCoverage{{Name: "EE", FiveG: "100%", HasFiveG: row["ee_5g"] == "1"}}}
+}
+
+func TestCheck_AdditionalSource(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ofcom_mobile_2023.csv"), []byte("postcode,ee_4g\nSW1A1AA,1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	postcodes := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/postcodes/SW1A1AA" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"status":200,"result":{"postcode":"SW1A 1AA","country":"England","latitude":51.501,"longitude":-0.1416}}`))
+	}))
+	defer postcodes.Close()
+
+	src := &fakeSource{}
+	c := coverage.New(coverage.WithDataDir(dir), coverage.WithSource(src), coverage.WithPostcodesURL(postcodes.URL))
+	if err := c.Setup(context.Background(), "2023", coverage.SetupOptions{}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	if !src.setup {
+		t.Error("expected Setup to reach the additional source")
+	}
+
+	res, err := c.Check(context.Background(), "SW1A 1AA")
+	if err != nil || res.Geographic == nil || res.Geographic.Country != "England" {
+		t.Fatalf("expected a check with geographic data, got %+v (err %v)", res, err)
+	}
+	if len(res.Sources) != 1 {
+		t.Fatalf("expected one source section, got %+v", res.Sources)
+	}
+	if s := res.Sources[0]; s.Source != "fake" || s.Mobile == nil || !s.Mobile.Operators[0].HasFiveG {
+		t.Errorf("unexpected source section %+v", s)
+	}
+}
@@ -0,0 +1,30 @@
+// Package coverage is the public Go API for checking UK mobile coverage by
+// postcode using Ofcom Connected Nations open data and postcodes.io.
+//
+// Create a Client, make sure the Ofcom dataset is installed (Setup), then
+// call Check:
+//
+//	c := coverage.New(coverage.WithDataDir("/var/lib/mobile-checker"))
+//	if err := c.Setup(ctx, "2023", coverage.SetupOptions{}); err != nil {
+//		return err
+//	}
+//	res, err := c.Check(ctx, "SW1A 1AA")
+//	switch {
+//	case errors.Is(err, coverage.ErrPostcodeNotFound):
+//		// unknown postcode
+//	case err != nil:
+//		return err
+//	}
+//	for _, op := range res.Mobile.Operators {
+//		fmt.Println(op.Name, op.FourG)
+//	}
+//
+// # Stability
+//
+// This package follows semantic versioning. Within a major version,
+// exported identifiers are not removed or renamed, function signatures do
+// not change, and the documented meaning of error values is kept. New
+// options, fields and functions may be added in minor releases, so do not
+// rely on struct literal positions or exhaustive switches over types
+// defined here. Everything under internal/ may change at any time.
+package coverage
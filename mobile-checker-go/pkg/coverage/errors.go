@@ -0,0 +1,66 @@
+package coverage
+
+import (
+	"errors"
+
+	"github.com/yourusername/mobile-checker/internal/checker"
+	"github.com/yourusername/mobile-checker/internal/ofcom"
+	"github.com/yourusername/mobile-checker/internal/postcode"
+)
+
+// Errors returned by Client methods, wrapped in *Error. Use errors.Is.
+var (
+	// ErrInvalidPostcode means the input is not shaped like a UK postcode.
+	ErrInvalidPostcode = postcode.ErrInvalid
+	// ErrPostcodeNotFound means postcodes.io does not recognise the postcode.
+	ErrPostcodeNotFound = postcode.ErrNotFound
+	// ErrTerminated means the postcode has been retired.
+	ErrTerminated = checker.ErrTerminated
+	// ErrUpstream means postcodes.io could not be reached or failed.
+	ErrUpstream = postcode.ErrUnavailable
+	// ErrDatasetMissing means the Ofcom dataset is not installed (run Setup).
+	ErrDatasetMissing = ofcom.ErrDatabaseNotFound
+	// ErrDatasetOutdated means the dataset must be rebuilt with Setup{Force: true}.
+	ErrDatasetOutdated = ofcom.ErrSchemaOutdated
+	// ErrNotInDataset means the postcode is valid but absent from the dataset.
+	ErrNotInDataset = checker.ErrNotInDataset
+	// ErrNoGeoData means Aggregate needs geographic data the dataset lacks;
+	// build it with the mobile-checker CLI's setup --geocode or --onspd.
+	ErrNoGeoData = ofcom.ErrNoGeoData
+)
+
+// ErrorCode is a machine-readable failure classification such as
+// "POSTCODE_NOT_FOUND"; the same codes appear in HTTP API error bodies.
+type ErrorCode = checker.ErrorCode
+
+// Error is returned by Check for a failed postcode.
+type Error struct {
+	Postcode string
+	Err      error
+}
+
+func (e *Error) Error() string {
+	return e.Postcode + ": " + e.Err.Error()
+}
+
+// Code classifies the failure.
+func (e *Error) Code() ErrorCode {
+	return checker.CodeOf(e.Err)
+}
+
+// Unwrap returns the underlying cause.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// wrap converts an internal error into the public form.
+func wrap(pc string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var ce *Error
+	if errors.As(err, &ce) {
+		return err
+	}
+	return &Error{Postcode: pc, Err: err}
+}